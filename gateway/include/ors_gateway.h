@@ -1,6 +1,7 @@
 #pragma once
 
 #include <atomic>
+#include <map>
 #include <memory>
 #include <string>
 #include <unordered_map>
@@ -156,6 +157,12 @@ private:
         std::string symbol;
         OrderStatus status;
         uint64_t timestamp;
+        // 重启恢复用（QueryOrders 返回在途订单的方向/价格/剩余量）
+        OrderSide side = OrderSide::SIDE_UNKNOWN;
+        double price = 0.0;
+        int64_t quantity = 0;
+        int64_t filled_qty = 0;
+        std::map<std::string, std::string> metadata;
     };
 
     void UpdateOrderBook(const OrderUpdate& update);
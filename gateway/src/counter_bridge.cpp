@@ -30,6 +30,7 @@
 #include <sstream>
 #include <fstream>
 #include <chrono>
+#include <algorithm>
 
 #include "plugin/td_plugin_interface.h"
 #include "hftbase_shm.h"
@@ -84,6 +85,7 @@ struct CachedOrderInfo {
     unsigned char side;        // 'B'/'S'
     std::string client_order_id; // ITDPlugin string order ID
     int openCloseFlag;         // OPEN_ORDER / CLOSE_TODAY_FLAG / CLOSE_YESTD_FLAG
+    std::string broker;        // g_brokers key that accepted the order (撤单走同一账户)
 };
 
 // ============================================================
@@ -108,6 +110,7 @@ static std::mutex g_orders_mutex;
 // Broker plugin registry
 static std::map<std::string, std::unique_ptr<ITDPlugin>> g_brokers;
 static std::map<std::string, std::string> g_symbol_to_broker;
+static std::map<std::string, std::string> g_account_to_broker;  // AccountID -> g_brokers key (多账户)
 
 // Statistics
 struct Statistics {
@@ -209,6 +212,51 @@ void SetCombOffsetFlag(
     }
 }
 
+// ============================================================
+// ApplyPosDirection — honour an explicit PosDirection from the client
+// Go 扩展：tbsrc-golang 的开平仓规划器（pkg/offset）填写 PosDirection 时
+// 直接使用其选择并同样扣减可平量；未填写（0）时返回 false，仍走 SetCombOffsetFlag
+// ============================================================
+bool ApplyPosDirection(
+    const RequestMsg* request,
+    int& openCloseFlag,
+    unsigned char exchangeType)
+{
+    int qty = request->Quantity;
+    std::string symbol(request->Contract_Description.Symbol);
+    bool isBuy = (request->Transaction_Type == SIDE_BUY);
+
+    switch (request->PosDirection) {
+        case POS_OPEN:
+            openCloseFlag = OPEN_ORDER;
+            return true;
+
+        case POS_CLOSE_INTRADAY: {
+            std::lock_guard<std::mutex> lock(g_posLock);
+            auto& pos = g_mapContractPos[symbol];
+            int& today = isBuy ? pos.todayShortPos : pos.todayLongPos;
+            today -= qty;
+            openCloseFlag = (exchangeType == CHINA_SHFE) ? CLOSE_TODAY_FLAG : CLOSE_YESTD_FLAG;
+            return true;
+        }
+
+        case POS_CLOSE: {
+            std::lock_guard<std::mutex> lock(g_posLock);
+            auto& pos = g_mapContractPos[symbol];
+            int& overnight = isBuy ? pos.ONShortPos : pos.ONLongPos;
+            int& today = isBuy ? pos.todayShortPos : pos.todayLongPos;
+            int fromON = std::min(qty, std::max(overnight, 0));
+            overnight -= fromON;
+            today -= qty - fromON;
+            openCloseFlag = CLOSE_YESTD_FLAG;
+            return true;
+        }
+
+        default:
+            return false;
+    }
+}
+
 // ============================================================
 // updatePosition — update position tracking on trade/reject/cancel
 // C++ source: ors/China/src/ORSServer.cpp:1186-1281
@@ -317,6 +365,30 @@ ITDPlugin* GetBrokerForSymbol(const std::string& symbol) {
     return nullptr;
 }
 
+// ============================================================
+// Resolve broker for an order: AccountID first, then symbol
+// 多账户：同一券商类型按账户加载多个实例（ctp@<account>:<config>），
+// 请求的 AccountID 命中时路由到该账户，否则按品种/默认券商路由
+// ============================================================
+std::string ResolveBrokerKey(const std::string& symbol, const std::string& account) {
+    if (!account.empty()) {
+        auto it = g_account_to_broker.find(account);
+        if (it != g_account_to_broker.end() && g_brokers.count(it->second)) {
+            return it->second;
+        }
+    }
+    auto it = g_symbol_to_broker.find(symbol);
+    if (it != g_symbol_to_broker.end() && g_brokers.count(it->second)) {
+        return it->second;
+    }
+    for (auto& [name, broker] : g_brokers) {
+        if (broker && broker->IsLoggedIn()) {
+            return name;
+        }
+    }
+    return "";
+}
+
 // ============================================================
 // HTTP server (kept for health check and simulator stats only)
 // /positions endpoint REMOVED — Go tracks positions via TRADE_CONFIRM
@@ -539,6 +611,120 @@ void OnBrokerErrorCallback(int error_id, const std::string& error_msg) {
     std::cerr << "[Bridge] Broker Error: [" << error_id << "] " << error_msg << std::endl;
 }
 
+// ============================================================
+// ORDERSTATUS — 在途订单查询（Go 扩展，策略重启时认领/撤销遗留订单）
+// 协议：
+//   请求  Request_Type=ORDERSTATUS, StrategyID, OrderID=查询ID（请求方当前 clientID 区间）
+//   应答  每个在途订单一条 NULL_RESPONSE / NULL_RESPONSE_MIDDLE：
+//         OrderID=原订单ID, Quantity=未成交量, Price=委托价, Side, Symbol, TimeStamp=报单时间
+//   结束  NULL_RESPONSE, OrderID=查询ID, Quantity=在途订单数
+// ============================================================
+static bool IsLiveStatus(hft::plugin::OrderStatus status) {
+    switch (status) {
+        case hft::plugin::OrderStatus::SUBMITTING:
+        case hft::plugin::OrderStatus::SUBMITTED:
+        case hft::plugin::OrderStatus::ACCEPTED:
+        case hft::plugin::OrderStatus::PARTIAL_FILLED:
+        case hft::plugin::OrderStatus::CANCELING:
+            return true;
+        default:
+            return false;
+    }
+}
+
+void HandleOrderStatusQuery(const RequestMsg& req) {
+    int count = 0;
+    for (auto& [name, broker] : g_brokers) {
+        if (!broker || !broker->IsLoggedIn()) continue;
+
+        std::vector<OrderInfo> orders;
+        if (!broker->QueryOrders(orders)) {
+            std::cerr << "[Processor] " << name << " QueryOrders failed" << std::endl;
+            continue;
+        }
+        for (const auto& order : orders) {
+            if (!IsLiveStatus(order.status)) continue;
+
+            CachedOrderInfo cached;
+            {
+                std::lock_guard<std::mutex> lock(g_orders_mutex);
+                auto it = g_order_map.find(order.order_id);
+                if (it == g_order_map.end()) continue;  // 非本 bridge 报出的订单（手工单等）
+                cached = it->second;
+            }
+            if (cached.strategy_id != req.StrategyID) continue;
+
+            ResponseMsg resp;
+            std::memset(&resp, 0, sizeof(resp));
+            resp.Response_Type = NULL_RESPONSE;
+            resp.Child_Response = NULL_RESPONSE_MIDDLE;
+            resp.OrderID = cached.order_id;
+            resp.StrategyID = cached.strategy_id;
+            resp.Side = cached.side;
+            resp.Quantity = static_cast<int32_t>(order.volume - order.traded_volume);
+            resp.Price = order.price;
+            resp.TimeStamp = order.insert_time;
+            std::strncpy(resp.Symbol, cached.symbol.c_str(), sizeof(resp.Symbol) - 1);
+            g_response_queue->enqueue(resp);
+            count++;
+        }
+    }
+
+    ResponseMsg done;
+    std::memset(&done, 0, sizeof(done));
+    done.Response_Type = NULL_RESPONSE;
+    done.OrderID = req.OrderID;
+    done.StrategyID = req.StrategyID;
+    done.Quantity = count;
+    g_response_queue->enqueue(done);
+
+    std::cout << "[Processor] ORDERSTATUS strategy=" << req.StrategyID
+              << " live=" << count << std::endl;
+}
+
+// ============================================================
+// CANCELORDER — 按 hftbase OrderID 找到券商订单号撤单
+// 撤单确认由 OnBrokerOrderCallback 以 CANCEL_ORDER_CONFIRM 推送
+// ============================================================
+void HandleCancelRequest(const RequestMsg& req) {
+    std::string broker_order_id;
+    std::string symbol;
+    std::string broker_key;
+    {
+        std::lock_guard<std::mutex> lock(g_orders_mutex);
+        for (const auto& [id, info] : g_order_map) {
+            if (info.order_id == req.OrderID) {
+                broker_order_id = id;
+                symbol = info.symbol;
+                broker_key = info.broker;
+                break;
+            }
+        }
+    }
+
+    ITDPlugin* broker = nullptr;
+    if (!broker_order_id.empty()) {
+        auto it = g_brokers.find(broker_key);
+        broker = (it != g_brokers.end()) ? it->second.get() : GetBrokerForSymbol(symbol);
+    }
+    if (broker && broker->CancelOrder(broker_order_id)) {
+        std::cout << "[Processor] Cancel OID=" << req.OrderID
+                  << " broker_order_id=" << broker_order_id << std::endl;
+        return;
+    }
+
+    std::cerr << "[Processor] Cancel failed OID=" << req.OrderID << std::endl;
+    ResponseMsg resp;
+    std::memset(&resp, 0, sizeof(resp));
+    resp.Response_Type = CANCEL_ORDER_REJECT;
+    resp.OrderID = req.OrderID;
+    resp.ErrorCode = 1;
+    resp.StrategyID = req.StrategyID;
+    resp.Side = req.Transaction_Type;
+    std::strncpy(resp.Symbol, req.Contract_Description.Symbol, sizeof(resp.Symbol) - 1);
+    g_response_queue->enqueue(resp);
+}
+
 // ============================================================
 // Order request processor — reads RequestMsg from MWMR queue
 // ============================================================
@@ -550,15 +736,27 @@ void OrderRequestProcessor(ReqQueue* req_queue) {
     while (g_running.load()) {
         if (!req_queue->isEmpty()) {
             req_queue->dequeuePtr(&req);
+
+            if (req.Request_Type == ORDERSTATUS) {
+                HandleOrderStatusQuery(req);
+                continue;
+            }
+            if (req.Request_Type == CANCELORDER) {
+                HandleCancelRequest(req);
+                continue;
+            }
             g_stats.total_orders++;
 
             // Extract symbol
             std::string symbol(req.Contract_Description.Symbol);
 
-            // Get broker for symbol
-            ITDPlugin* broker = GetBrokerForSymbol(symbol);
+            // Get broker for account / symbol
+            std::string account(req.AccountID, strnlen(req.AccountID, sizeof(req.AccountID)));
+            std::string broker_key = ResolveBrokerKey(symbol, account);
+            ITDPlugin* broker = broker_key.empty() ? nullptr : g_brokers[broker_key].get();
             if (!broker) {
-                std::cerr << "[Processor] No broker for: " << symbol << std::endl;
+                std::cerr << "[Processor] No broker for: " << symbol
+                          << (account.empty() ? "" : " account=" + account) << std::endl;
                 g_stats.failed_orders++;
 
                 // Send ORS_REJECT
@@ -576,7 +774,9 @@ void OrderRequestProcessor(ReqQueue* req_queue) {
             // Auto-determine open/close flag
             // C++ source: ors/China/src/ORSServer.cpp:488-605
             int openCloseFlag = OPEN_ORDER;
-            SetCombOffsetFlag(&req, openCloseFlag, req.Exchange_Type);
+            if (!ApplyPosDirection(&req, openCloseFlag, req.Exchange_Type)) {
+                SetCombOffsetFlag(&req, openCloseFlag, req.Exchange_Type);
+            }
 
             // Convert to ITDPlugin unified format
             hft::plugin::OrderRequest unified_req;
@@ -643,6 +843,7 @@ void OrderRequestProcessor(ReqQueue* req_queue) {
                     info.side = req.Transaction_Type;
                     info.client_order_id = unified_req.client_order_id;
                     info.openCloseFlag = openCloseFlag;
+                    info.broker = broker_key;
                     g_order_map[broker_order_id] = info;
                 } else {
                     g_stats.failed_orders++;
@@ -697,6 +898,7 @@ int main(int argc, char** argv) {
         std::cerr << "\nExamples:" << std::endl;
         std::cerr << "  " << argv[0] << " ctp:/path/to/ctp_td.yaml" << std::endl;
         std::cerr << "  " << argv[0] << " simulator:/path/to/sim.yaml --position-file positions.csv" << std::endl;
+        std::cerr << "  " << argv[0] << " ctp@8001:/path/to/ctp_8001.yaml ctp@8002:/path/to/ctp_8002.yaml  (one instance per account)" << std::endl;
         std::cerr << "\nSupported brokers: ctp, simulator" << std::endl;
         return 1;
     }
@@ -769,8 +971,16 @@ int main(int argc, char** argv) {
             continue;
         }
 
-        std::string broker_name = arg.substr(0, separator);
+        // <broker>[@<account>]:<config_file> — 带账户时注册为独立实例，按 RequestMsg.AccountID 路由
+        std::string broker_key = arg.substr(0, separator);
         std::string config_file = arg.substr(separator + 1);
+        std::string broker_name = broker_key;
+        std::string account;
+        size_t at = broker_key.find('@');
+        if (at != std::string::npos) {
+            broker_name = broker_key.substr(0, at);
+            account = broker_key.substr(at + 1);
+        }
 
         std::cout << "[Main] Loading broker: " << broker_name << std::endl;
         std::cout << "[Main]   Config: " << config_file << std::endl;
@@ -796,7 +1006,7 @@ int main(int argc, char** argv) {
 
             std::cout << "[Main] CTP plugin initialized and logged in" << std::endl;
             plugin = ctp_plugin.get();
-            g_brokers["ctp"] = std::move(ctp_plugin);
+            g_brokers[broker_key] = std::move(ctp_plugin);
         }
 #endif
 
@@ -819,7 +1029,7 @@ int main(int argc, char** argv) {
 
             std::cout << "[Main] Simulator plugin initialized (immediate matching mode)" << std::endl;
             plugin = sim_plugin.get();
-            g_brokers["simulator"] = std::move(sim_plugin);
+            g_brokers[broker_key] = std::move(sim_plugin);
         }
 #endif
 
@@ -834,6 +1044,10 @@ int main(int argc, char** argv) {
 #endif
             std::cerr << std::endl;
         }
+        if (plugin && !account.empty()) {
+            g_account_to_broker[account] = broker_key;
+            std::cout << "[Main]   Account " << account << " -> " << broker_key << std::endl;
+        }
     }
 
     if (g_brokers.empty()) {
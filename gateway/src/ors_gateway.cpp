@@ -1,5 +1,6 @@
 #include "ors_gateway.h"
 
+#include <algorithm>
 #include <chrono>
 #include <cstring>
 #include <iostream>
@@ -178,6 +179,10 @@ grpc::Status ORSGatewayImpl::SendOrder(
         info.symbol = request->symbol();
         info.status = OrderStatus::SUBMITTED;
         info.timestamp = raw_req.timestamp;
+        info.side = request->side();
+        info.price = request->price();
+        info.quantity = request->quantity();
+        info.metadata.insert(request->metadata().begin(), request->metadata().end());
         m_orders[order_id] = info;
     }
 
@@ -261,6 +266,11 @@ grpc::Status ORSGatewayImpl::QueryOrders(
         if (!request->symbol().empty() && info.symbol != request->symbol()) {
             continue;
         }
+        if (request->status_size() > 0 &&
+            std::find(request->status().begin(), request->status().end(), info.status) ==
+                request->status().end()) {
+            continue;
+        }
 
         // 构造返回数据
         OrderData data;
@@ -271,6 +281,12 @@ grpc::Status ORSGatewayImpl::QueryOrders(
         update->set_symbol(info.symbol);
         update->set_status(info.status);
         update->set_timestamp(info.timestamp);
+        update->set_side(info.side);
+        update->set_price(info.price);
+        update->set_quantity(info.quantity);
+        update->set_filled_qty(info.filled_qty);
+        update->set_remaining_qty(info.quantity - info.filled_qty);
+        update->mutable_metadata()->insert(info.metadata.begin(), info.metadata.end());
 
         writer->Write(data);
     }
@@ -447,6 +463,9 @@ void ORSGatewayImpl::UpdateOrderBook(const OrderUpdate& update) {
     if (it != m_orders.end()) {
         it->second.status = update.status();
         it->second.timestamp = update.timestamp();
+        if (update.filled_qty() > it->second.filled_qty) {
+            it->second.filled_qty = update.filled_qty();
+        }
 
         // 更新统计
         if (update.status() == OrderStatus::ACCEPTED) {
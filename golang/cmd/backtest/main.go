@@ -115,6 +115,10 @@ func main() {
 				log.Printf("[Main] Failed to generate JSON report: %v", err)
 			}
 
+			if err := reportGen.GenerateTCA(); err != nil {
+				log.Printf("[Main] Failed to generate TCA report: %v", err)
+			}
+
 			log.Printf("[Main] Report saved to: %s", config.Backtest.Output.ResultDir)
 		}
 
@@ -0,0 +1,168 @@
+// journal 写前日志查看/导出工具
+// 运行:
+//
+//	go run ./cmd/journal inspect data/journal/orders.journal
+//	go run ./cmd/journal export -format csv data/journal/orders.journal > orders.csv
+//	go run ./cmd/journal export -after-checkpoint data/journal/orders.journal
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/journal"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage:
+  journal inspect <file>
+  journal export [-format jsonl|csv] [-after-checkpoint] <file>
+`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "inspect":
+		err = inspect(os.Args[2:])
+	case "export":
+		err = export(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "journal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func inspect(args []string) error {
+	if len(args) != 1 {
+		usage()
+	}
+	recs, res, err := journal.ReadAll(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("file:        %s\n", args[0])
+	fmt.Printf("records:     %d (%d bytes)\n", len(recs), res.ValidSize)
+	if res.TailBytes > 0 {
+		fmt.Printf("torn tail:   %d bytes (%s), truncated on next open\n", res.TailBytes, res.TailError)
+	}
+	if len(recs) == 0 {
+		return nil
+	}
+	fmt.Printf("time range:  %s — %s\n",
+		recs[0].Time.Format("2006-01-02 15:04:05.000"), recs[len(recs)-1].Time.Format("2006-01-02 15:04:05.000"))
+
+	byKind := make(map[string]int)
+	var lastCP *journal.Entry
+	for _, rec := range recs {
+		e, err := journal.Decode(rec)
+		if err != nil {
+			byKind["UNDECODABLE"]++
+			continue
+		}
+		byKind[e.Kind+" "+e.Type]++
+		if rec.Kind == journal.KindCheckpoint {
+			cp := e
+			lastCP = &cp
+		}
+	}
+	keys := make([]string, 0, len(byKind))
+	for k := range byKind {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %-36s %d\n", k, byKind[k])
+	}
+
+	if lastCP != nil {
+		fmt.Printf("last checkpoint: %s day=%s at %s (offset %d)\n",
+			lastCP.Type, lastCP.TradingDay, lastCP.Time.Format("2006-01-02 15:04:05.000"), lastCP.Offset)
+	}
+	if n := len(journal.AfterCheckpoint(recs)); n > 0 {
+		fmt.Printf("after checkpoint: %d records\n", n)
+	}
+
+	replay := journal.ReplayRecords(recs, nil)
+	sids := make([]string, 0, len(replay.Fills))
+	for sid := range replay.Fills {
+		sids = append(sids, sid)
+	}
+	sort.Strings(sids)
+	fmt.Println("net filled:")
+	for _, sid := range sids {
+		syms := make([]string, 0, len(replay.Fills[sid]))
+		for s := range replay.Fills[sid] {
+			syms = append(syms, s)
+		}
+		sort.Strings(syms)
+		for _, s := range syms {
+			fmt.Printf("  %-20s %-12s %+d\n", sid, s, replay.Fills[sid][s])
+		}
+	}
+
+	fmt.Printf("open orders: %d\n", len(replay.OpenOrders))
+	for _, o := range replay.OpenOrders {
+		fmt.Printf("  %-20s %-20s %-10s %-4s %d@%.2f filled=%d %s\n",
+			o.OrderID, o.StrategyID, o.Symbol, o.Side, o.Qty, o.Price, o.FilledQty, o.Status)
+	}
+	return nil
+}
+
+func export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "jsonl", "输出格式: jsonl | csv")
+	afterCP := fs.Bool("after-checkpoint", false, "只导出最后一个 checkpoint 之后的记录")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	recs, _, err := journal.ReadAll(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *afterCP {
+		recs = journal.AfterCheckpoint(recs)
+	}
+
+	switch *format {
+	case "jsonl":
+		enc := json.NewEncoder(os.Stdout)
+		for _, rec := range recs {
+			e, err := journal.Decode(rec)
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(journal.EntryHeader)
+		for _, rec := range recs {
+			e, err := journal.Decode(rec)
+			if err != nil {
+				return err
+			}
+			w.Write(e.Fields())
+		}
+		w.Flush()
+		return w.Error()
+	}
+	return fmt.Errorf("unknown format %q", *format)
+}
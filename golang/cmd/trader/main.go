@@ -121,6 +121,12 @@ func main() {
 	}
 	log.Println("[Main] ✓ Trader initialized successfully")
 
+	// Shadow mode (live-vs-backtest comparison, optional)
+	shadowRunner, err := setupShadow(t)
+	if err != nil {
+		log.Printf("[Main] Warning: Shadow mode disabled: %v", err)
+	}
+
 	// Start config watcher (if enabled)
 	if *watchConfig {
 		go watchConfigFile(*configFile, t)
@@ -131,6 +137,9 @@ func main() {
 	if err := t.Start(); err != nil {
 		log.Fatalf("[Main] Failed to start trader: %v", err)
 	}
+	if shadowRunner != nil {
+		shadowRunner.Start()
+	}
 
 	// Print status periodically
 	go printStatusPeriodically(t, 30*time.Second)
@@ -153,6 +162,11 @@ func main() {
 		log.Printf("[Main] Error during shutdown: %v", err)
 		os.Exit(1)
 	}
+	if shadowRunner != nil {
+		if err := shadowRunner.Stop(); err != nil {
+			log.Printf("[Main] Warning: Failed to write shadow report: %v", err)
+		}
+	}
 
 	// Print final status
 	log.Println("[Main] ════════════════════════════════════════════════════════════")
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/backtest"
+	"github.com/yourusername/quantlink-trade-system/pkg/shadow"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+	"github.com/yourusername/quantlink-trade-system/pkg/trader"
+
+	"tbsrc-golang/pkg/calendar"
+)
+
+// setupShadow 创建影子模式并挂到策略引擎（shadow.enabled）
+// 须在 t.Initialize 之后（策略已创建）、t.Start 之前调用
+// trader 包被 backtest 引用，影子模式只能在这里组装
+func setupShadow(t *trader.Trader) (*shadow.Runner, error) {
+	cfg := t.Config.Shadow
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if t.StrategyMgr == nil {
+		return nil, fmt.Errorf("shadow mode requires strategies")
+	}
+
+	var live []strategy.Strategy
+	t.StrategyMgr.ForEach(func(id string, s strategy.Strategy) {
+		live = append(live, s)
+	})
+
+	reportDir := cfg.ReportDir
+	if reportDir == "" {
+		reportDir = filepath.Join(strategy.GetDataDir(), "shadow")
+	}
+
+	runner, err := shadow.New(shadow.Config{
+		Options: shadow.Options{
+			DecisionWindow:  time.Duration(cfg.DecisionWindowMs) * time.Millisecond,
+			FillWindow:      time.Duration(cfg.FillWindowMs) * time.Millisecond,
+			PositionGrace:   time.Duration(cfg.PositionGraceMs) * time.Millisecond,
+			PriceTolerance:  cfg.PriceTolerance,
+			SignalTolerance: cfg.SignalTolerance,
+		},
+		ReportDir:     reportDir,
+		QueueSize:     cfg.QueueSize,
+		TimerInterval: t.Config.Engine.TimerInterval,
+		Sim: backtest.OrderSimSettings{
+			FillDelayMs:    cfg.FillDelayMs,
+			SlippageBps:    cfg.SlippageBps,
+			CommissionRate: cfg.CommissionRate,
+		},
+		TradingDay: func(ts time.Time) string {
+			if t.SessionMgr != nil {
+				if cal := t.SessionMgr.Calendar(); cal != nil {
+					return calendar.FormatDay(cal.TradingDay(ts))
+				}
+			}
+			return ts.Format("20060102")
+		},
+	}, live)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Engine.SetShadowTap(runner)
+	log.Printf("[Main] ✓ Shadow mode enabled for %v", runner.StrategyIDs())
+	return runner, nil
+}
@@ -0,0 +1,115 @@
+// volume_curve 从录制行情学习 VWAP 成交量曲线
+// 输入与回测相同的目录结构: <data>/<YYYYMMDD>/<symbol>.csv（第 1 列纳秒时间戳，第 5 列成交量）
+// 运行:
+//
+//	go run ./cmd/volume_curve -data ./data/market_data -symbol ag2502 -days 20 -out data/live/volume_curves
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/algo"
+)
+
+var dayDir = regexp.MustCompile(`^\d{8}$`)
+
+func main() {
+	dataPath := flag.String("data", "./data/market_data", "recorded market data directory")
+	symbol := flag.String("symbol", "", "symbol (required)")
+	days := flag.Int("days", 20, "use the most recent N trading days (0 = all)")
+	bucket := flag.Int("bucket", algo.DefaultBucketSec, "bucket width in seconds")
+	out := flag.String("out", "data/live/volume_curves", "output directory (<out>/<symbol>.json)")
+	flag.Parse()
+
+	if *symbol == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*dataPath, *symbol, *days, *bucket, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "volume_curve: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dataPath, symbol string, days, bucket int, out string) error {
+	entries, err := os.ReadDir(dataPath)
+	if err != nil {
+		return err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() && dayDir.MatchString(e.Name()) {
+			if _, err := os.Stat(filepath.Join(dataPath, e.Name(), symbol+".csv")); err == nil {
+				dirs = append(dirs, e.Name())
+			}
+		}
+	}
+	sort.Strings(dirs)
+	if days > 0 && len(dirs) > days {
+		dirs = dirs[len(dirs)-days:]
+	}
+	if len(dirs) == 0 {
+		return fmt.Errorf("no data for %s under %s", symbol, dataPath)
+	}
+
+	b := algo.NewCurveBuilder(symbol, bucket)
+	for _, day := range dirs {
+		n, err := addFile(b, day, filepath.Join(dataPath, day, symbol+".csv"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", day, err)
+		}
+		fmt.Printf("%s: %d ticks\n", day, n)
+	}
+	curve, err := b.Build()
+	if err != nil {
+		return err
+	}
+	path := algo.CurvePath(out, symbol)
+	if err := algo.SaveCurve(path, curve); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s (%d days, %d buckets, avg daily volume %.0f)\n", path, curve.Days, len(curve.Buckets), curve.AvgDailyVolume)
+	return nil
+}
+
+func addFile(b *algo.CurveBuilder, day, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	if _, err := r.Read(); err != nil { // header
+		return 0, err
+	}
+	n := 0
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if len(rec) < 5 {
+			continue
+		}
+		ts, err1 := strconv.ParseInt(rec[0], 10, 64)
+		qty, err2 := strconv.ParseInt(rec[4], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		b.Add(day, time.Unix(0, ts), qty)
+		n++
+	}
+}
@@ -0,0 +1,609 @@
+// Package account provides multi-account order fan-out, per-account position / P&L
+// tracking and per-account + global risk limits (Go 扩展)
+//
+// 同一策略按比例在多个资金账户下单：每个策略配置一个或多个账户及 scale，第一个账户为主账户（lead）。
+// 策略只看到主账户的订单与回报（数量不缩放），跟随账户（follower）的订单由 Fanout 按
+// round(qty*scale) 生成，StrategyId 记为 "<strategy>@<account>"，回报不分发给策略，
+// 因此写前日志重放、成交流水对账都只把主账户成交计入策略持仓。
+package account
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/offset"
+	commonpb "github.com/yourusername/quantlink-trade-system/pkg/proto/common"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// Limits 风控限额，0 = 不限
+type Limits struct {
+	MaxOrderQty  int64   `json:"max_order_qty,omitempty"`  // 单笔委托上限（手）
+	MaxPosition  int64   `json:"max_position,omitempty"`   // 单合约净持仓上限（含在途同向委托）
+	MaxDailyLoss float64 `json:"max_daily_loss,omitempty"` // 当日亏损上限（已实现 + 浮动），达到后只允许减仓
+}
+
+// Allocation 策略在某账户上的下单比例
+type Allocation struct {
+	Account string  `json:"account"`
+	Scale   float64 `json:"scale"`
+}
+
+// Position 账户单合约持仓（均价法计算盈亏）
+type Position struct {
+	Symbol        string  `json:"symbol"`
+	NetQty        int64   `json:"net_qty"` // 买为正
+	AvgPrice      float64 `json:"avg_price"`
+	LastPrice     float64 `json:"last_price,omitempty"`
+	BuyQty        int64   `json:"buy_qty"`  // 当日买成交
+	SellQty       int64   `json:"sell_qty"` // 当日卖成交
+	PendingBuy    int64   `json:"pending_buy"`
+	PendingSell   int64   `json:"pending_sell"`
+	RealizedPnL   float64 `json:"realized_pnl"` // 当日已实现
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+}
+
+// Snapshot 账户快照（API / 持久化）
+type Snapshot struct {
+	Account       string     `json:"account"`
+	Limits        Limits     `json:"limits"`
+	Positions     []Position `json:"positions"`
+	RealizedPnL   float64    `json:"realized_pnl"`
+	UnrealizedPnL float64    `json:"unrealized_pnl"`
+	DailyPnL      float64    `json:"daily_pnl"`
+	LossLimitHit  bool       `json:"loss_limit_hit"` // 达到 MaxDailyLoss，只允许减仓
+}
+
+// book 单账户台账
+type book struct {
+	id        string
+	limits    Limits
+	positions map[string]*Position
+}
+
+// order 经 Fanout 发出的订单
+type order struct {
+	clientOrderID string
+	orderID       string
+	account       string
+	strategyID    string // 原策略ID（跟随账户订单的 StrategyId 带 @account 后缀）
+	symbol        string
+	side          orspb.OrderSide
+	qty           int64
+	filled        int64
+	lead          bool
+	leadOrderID   string
+	piece         *offset.Piece // 开平规划子订单（nil = 未经规划）
+}
+
+// Manager 多账户管理器
+type Manager struct {
+	mu          sync.Mutex
+	ids         []string
+	books       map[string]*book
+	global      Limits
+	allocations map[string][]Allocation // strategy → 账户，第一个为主账户
+
+	orders    map[string]*order   // client_order_id → order
+	byOrderID map[string]*order   // order_id → order
+	followers map[string][]string // 主账户 order_id → 跟随账户 order_id
+
+	multiplier func(symbol string) float64
+	planner    *offset.Planner // 开平仓规划器（nil = 各账户沿用策略订单的开平标志）
+	prefix     string
+	seq        uint64
+}
+
+// NewManager 创建多账户管理器，global 为跨账户汇总限额
+func NewManager(global Limits) *Manager {
+	return &Manager{
+		books:       make(map[string]*book),
+		global:      global,
+		allocations: make(map[string][]Allocation),
+		orders:      make(map[string]*order),
+		byOrderID:   make(map[string]*order),
+		followers:   make(map[string][]string),
+		multiplier:  func(string) float64 { return 1 },
+		prefix:      fmt.Sprintf("ACC_%d_", time.Now().Unix()),
+	}
+}
+
+// SetMultiplier 设置合约乘数查询（盈亏计算），默认 1
+func (m *Manager) SetMultiplier(fn func(symbol string) float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if fn != nil {
+		m.multiplier = fn
+	}
+}
+
+// SetOffsetPlanner 设置开平仓规划器：未指定开平的策略订单在 Fanout 中按各账户自身的今/昨持仓拆分
+func (m *Manager) SetOffsetPlanner(p *offset.Planner) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.planner = p
+}
+
+// AddAccount 登记账户及其限额
+func (m *Manager) AddAccount(id string, limits Limits) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id == "" {
+		return fmt.Errorf("account id is required")
+	}
+	if _, ok := m.books[id]; ok {
+		return fmt.Errorf("duplicate account %s", id)
+	}
+	m.books[id] = &book{id: id, limits: limits, positions: make(map[string]*Position)}
+	m.ids = append(m.ids, id)
+	return nil
+}
+
+// Accounts 返回已登记的账户（按登记顺序）
+func (m *Manager) Accounts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.ids...)
+}
+
+// Assign 设置策略的账户分配，第一个为主账户，其 scale 必须为 1（策略持仓即主账户持仓）
+func (m *Manager) Assign(strategyID string, allocs []Allocation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(allocs) == 0 {
+		delete(m.allocations, strategyID)
+		return nil
+	}
+	seen := make(map[string]bool, len(allocs))
+	for i, a := range allocs {
+		if _, ok := m.books[a.Account]; !ok {
+			return fmt.Errorf("strategy %s: unknown account %s", strategyID, a.Account)
+		}
+		if seen[a.Account] {
+			return fmt.Errorf("strategy %s: duplicate account %s", strategyID, a.Account)
+		}
+		seen[a.Account] = true
+		if a.Scale <= 0 {
+			return fmt.Errorf("strategy %s: account %s scale must be > 0", strategyID, a.Account)
+		}
+		if i == 0 && a.Scale != 1 {
+			return fmt.Errorf("strategy %s: lead account %s scale must be 1", strategyID, a.Account)
+		}
+	}
+	m.allocations[strategyID] = append([]Allocation(nil), allocs...)
+	return nil
+}
+
+// Assigned 策略是否分配了账户（其订单经 Fanout 展开并按账户规划开平）
+func (m *Manager) Assigned(strategyID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.allocations[strategyID]) > 0
+}
+
+// Allocations 返回策略的账户分配
+func (m *Manager) Allocations(strategyID string) []Allocation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Allocation(nil), m.allocations[strategyID]...)
+}
+
+// FollowerStrategyID 跟随账户订单使用的 StrategyId
+func FollowerStrategyID(strategyID, account string) string {
+	return strategyID + "@" + account
+}
+
+// FollowerStrategyIDs 返回策略各跟随账户订单的 StrategyId
+func (m *Manager) FollowerStrategyIDs(strategyID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	allocs := m.allocations[strategyID]
+	if len(allocs) < 2 {
+		return nil
+	}
+	ids := make([]string, 0, len(allocs)-1)
+	for _, a := range allocs[1:] {
+		ids = append(ids, FollowerStrategyID(strategyID, a.Account))
+	}
+	return ids
+}
+
+// Fanout 把策略订单展开为各账户订单，第一个为主账户订单
+// 未分配账户的策略原样返回（不跟踪）。主账户或全局限额不通过时整单拒绝；
+// 跟随账户限额不通过或缩放后数量为 0 时跳过该账户。
+// 设置了规划器且策略订单未指定开平时，各账户订单按该账户台账拆分为平昨/平今/开仓，
+// 主账户可能对应多笔订单（均排在跟随账户订单之前）。
+// 返回的订单已占用在途数量（及可平量），发送失败须调用 Abort
+func (m *Manager) Fanout(req *orspb.OrderRequest) ([]*orspb.OrderRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	allocs := m.allocations[req.StrategyId]
+	if len(allocs) == 0 {
+		return []*orspb.OrderRequest{req}, nil
+	}
+
+	lead := allocs[0]
+	if err := m.checkLocked(m.books[lead.Account], req.Symbol, req.Side, req.Quantity); err != nil {
+		return nil, fmt.Errorf("account %s: %w", lead.Account, err)
+	}
+	out := []*orspb.OrderRequest{m.childLocked(req, lead.Account, req.Quantity, true)}
+
+	for _, a := range allocs[1:] {
+		qty := int64(math.Round(float64(req.Quantity) * a.Scale))
+		if qty <= 0 {
+			continue
+		}
+		if err := m.checkLocked(m.books[a.Account], req.Symbol, req.Side, qty); err != nil {
+			log.Printf("[Account] Skip follower %s for %s %s %d: %v", a.Account, req.StrategyId, req.Symbol, qty, err)
+			continue
+		}
+		out = append(out, m.childLocked(req, a.Account, qty, false))
+	}
+
+	if err := m.checkGlobalLocked(out); err != nil {
+		return nil, fmt.Errorf("global: %w", err)
+	}
+
+	var pieces []*offset.Piece
+	if m.planner != nil && req.OpenClose == orspb.OpenClose_OC_UNKNOWN {
+		out, pieces = m.planLocked(req, out)
+	}
+
+	for i, child := range out {
+		o := &order{
+			clientOrderID: child.ClientOrderId,
+			account:       child.Account,
+			strategyID:    req.StrategyId,
+			symbol:        child.Symbol,
+			side:          child.Side,
+			qty:           child.Quantity,
+			lead:          child.StrategyId == req.StrategyId,
+		}
+		if pieces != nil {
+			o.piece = pieces[i]
+		}
+		m.orders[o.clientOrderID] = o
+		m.reserveLocked(o, o.qty)
+	}
+	return out, nil
+}
+
+// planLocked 按各账户自身的今/昨持仓为每个账户订单选择开平，可能拆成多笔；返回拆分后的订单及对应子订单
+func (m *Manager) planLocked(req *orspb.OrderRequest, children []*orspb.OrderRequest) ([]*orspb.OrderRequest, []*offset.Piece) {
+	side := offset.Buy
+	if req.Side == orspb.OrderSide_SELL {
+		side = offset.Sell
+	}
+	exchange := ""
+	if req.Exchange != commonpb.Exchange_UNKNOWN_EXCHANGE {
+		exchange = req.Exchange.String()
+	}
+
+	var out []*orspb.OrderRequest
+	var pieces []*offset.Piece
+	for _, child := range children {
+		lead := child.StrategyId == req.StrategyId
+		planned := m.planner.Plan(offset.Request{
+			Account:  child.Account,
+			Symbol:   child.Symbol,
+			Exchange: exchange,
+			Side:     side,
+			Qty:      child.Quantity,
+			Price:    child.Price,
+		})
+		for i := range planned {
+			pc := &planned[i]
+			c := child
+			if i > 0 {
+				c = m.childLocked(req, child.Account, pc.Qty, lead)
+			}
+			c.Quantity = pc.Qty
+			c.OpenClose = openCloseOf(pc.Offset)
+			out = append(out, c)
+			pieces = append(pieces, pc)
+		}
+	}
+	return out, pieces
+}
+
+// openCloseOf 规划器开平标志 → 订单开平标志
+func openCloseOf(o offset.Offset) orspb.OpenClose {
+	switch o {
+	case offset.Close:
+		return orspb.OpenClose_CLOSE
+	case offset.CloseToday:
+		return orspb.OpenClose_CLOSE_TODAY
+	case offset.CloseYesterday:
+		return orspb.OpenClose_CLOSE_YESTERDAY
+	default:
+		return orspb.OpenClose_OPEN
+	}
+}
+
+// childLocked 复制订单并设置账户/数量/client_order_id
+func (m *Manager) childLocked(req *orspb.OrderRequest, account string, qty int64, lead bool) *orspb.OrderRequest {
+	m.seq++
+	child := &orspb.OrderRequest{
+		StrategyId:    req.StrategyId,
+		Symbol:        req.Symbol,
+		Exchange:      req.Exchange,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		TimeInForce:   req.TimeInForce,
+		Price:         req.Price,
+		Quantity:      qty,
+		OpenClose:     req.OpenClose,
+		ClientOrderId: fmt.Sprintf("%s%d", m.prefix, m.seq),
+		Account:       account,
+	}
+	if !lead {
+		child.StrategyId = FollowerStrategyID(req.StrategyId, account)
+	}
+	if len(req.Metadata) > 0 {
+		child.Metadata = make(map[string]string, len(req.Metadata)+1)
+		for k, v := range req.Metadata {
+			child.Metadata[k] = v
+		}
+	} else {
+		child.Metadata = make(map[string]string, 1)
+	}
+	child.Metadata["account"] = account
+	return child
+}
+
+// Bind 记录订单应答的 order_id；leadOrderID 非空表示跟随账户订单（撤单时随主账户订单一起撤）
+func (m *Manager) Bind(req *orspb.OrderRequest, orderID, leadOrderID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[req.ClientOrderId]
+	if !ok || orderID == "" {
+		return
+	}
+	o.orderID = orderID
+	o.leadOrderID = leadOrderID
+	m.byOrderID[orderID] = o
+	if o.piece != nil && m.planner != nil {
+		m.planner.Bind(orderID, *o.piece)
+	}
+	if leadOrderID != "" {
+		m.followers[leadOrderID] = append(m.followers[leadOrderID], orderID)
+	}
+}
+
+// Abort 释放发送失败/被拒订单占用的在途数量及可平量
+func (m *Manager) Abort(req *orspb.OrderRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o, ok := m.orders[req.ClientOrderId]; ok {
+		if o.piece != nil && o.orderID == "" && m.planner != nil {
+			m.planner.Abort(*o.piece)
+			o.piece = nil
+		}
+		m.finishLocked(o)
+	}
+}
+
+// Followers 返回主账户订单对应的跟随账户在途订单
+func (m *Manager) Followers(leadOrderID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.followers[leadOrderID]...)
+}
+
+// OnOrderUpdate 按订单回报更新账户持仓/盈亏，返回是否应分发给策略
+// 跟随账户订单返回 false；未经 Fanout 的订单返回 true 且不计入任何账户
+func (m *Manager) OnOrderUpdate(update *orspb.OrderUpdate) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, ok := m.orders[update.ClientOrderId]
+	if !ok {
+		o, ok = m.byOrderID[update.OrderId]
+	}
+	if !ok {
+		// 上一进程的跟随账户订单（重启后无法归属），不交给策略
+		return !strings.Contains(update.StrategyId, "@")
+	}
+	if o.orderID == "" && update.OrderId != "" {
+		o.orderID = update.OrderId
+		m.byOrderID[update.OrderId] = o
+	}
+
+	if delta := update.FilledQty - o.filled; delta > 0 {
+		price := update.LastFillPrice
+		if price == 0 || update.LastFillQty != delta {
+			price = update.AvgPrice
+		}
+		o.filled = update.FilledQty
+		m.reserveLocked(o, -delta)
+		m.fillLocked(m.books[o.account], o.symbol, o.side, delta, price)
+	}
+
+	switch update.Status {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_CANCELED,
+		orspb.OrderStatus_REJECTED, orspb.OrderStatus_EXPIRED:
+		m.finishLocked(o)
+	}
+	return o.lead
+}
+
+// MarkPrice 更新合约最新价（浮动盈亏）
+func (m *Manager) MarkPrice(symbol string, price float64) {
+	if price <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range m.books {
+		if p, ok := b.positions[symbol]; ok {
+			p.LastPrice = price
+		}
+	}
+}
+
+// SetPosition 设置账户持仓（启动时从快照恢复）
+func (m *Manager) SetPosition(account string, pos Position) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.books[account]
+	if !ok {
+		return fmt.Errorf("unknown account %s", account)
+	}
+	p := b.position(pos.Symbol)
+	p.NetQty, p.AvgPrice, p.LastPrice = pos.NetQty, pos.AvgPrice, pos.LastPrice
+	p.BuyQty, p.SellQty, p.RealizedPnL = pos.BuyQty, pos.SellQty, pos.RealizedPnL
+	return nil
+}
+
+// Rollover 交易日切换：清零当日成交量和已实现盈亏，保留净持仓与均价
+func (m *Manager) Rollover() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range m.books {
+		for _, p := range b.positions {
+			p.BuyQty, p.SellQty, p.RealizedPnL = 0, 0, 0
+		}
+	}
+}
+
+// Snapshot 返回全部账户快照（按登记顺序）
+func (m *Manager) Snapshot() []Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Snapshot, 0, len(m.ids))
+	for _, id := range m.ids {
+		out = append(out, m.snapshotLocked(m.books[id]))
+	}
+	return out
+}
+
+// AccountSnapshot 返回单个账户快照
+func (m *Manager) AccountSnapshot(account string) (Snapshot, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.books[account]
+	if !ok {
+		return Snapshot{}, false
+	}
+	return m.snapshotLocked(b), true
+}
+
+func (m *Manager) snapshotLocked(b *book) Snapshot {
+	s := Snapshot{Account: b.id, Limits: b.limits}
+	for _, p := range b.positions {
+		pos := *p
+		pos.UnrealizedPnL = m.unrealizedLocked(p)
+		s.RealizedPnL += pos.RealizedPnL
+		s.UnrealizedPnL += pos.UnrealizedPnL
+		s.Positions = append(s.Positions, pos)
+	}
+	sort.Slice(s.Positions, func(i, j int) bool { return s.Positions[i].Symbol < s.Positions[j].Symbol })
+	s.DailyPnL = s.RealizedPnL + s.UnrealizedPnL
+	s.LossLimitHit = b.limits.MaxDailyLoss > 0 && -s.DailyPnL >= b.limits.MaxDailyLoss
+	return s
+}
+
+func (b *book) position(symbol string) *Position {
+	p, ok := b.positions[symbol]
+	if !ok {
+		p = &Position{Symbol: symbol}
+		b.positions[symbol] = p
+	}
+	return p
+}
+
+// reserveLocked 调整在途委托数量
+func (m *Manager) reserveLocked(o *order, qty int64) {
+	p := m.books[o.account].position(o.symbol)
+	if o.side == orspb.OrderSide_BUY {
+		p.PendingBuy = max(p.PendingBuy+qty, 0)
+	} else {
+		p.PendingSell = max(p.PendingSell+qty, 0)
+	}
+}
+
+// finishLocked 订单终结：释放剩余在途数量并移除索引
+func (m *Manager) finishLocked(o *order) {
+	if rest := o.qty - o.filled; rest > 0 {
+		m.reserveLocked(o, -rest)
+		o.filled = o.qty
+	}
+	delete(m.orders, o.clientOrderID)
+	if o.orderID != "" {
+		delete(m.byOrderID, o.orderID)
+		delete(m.followers, o.orderID)
+	}
+	if o.leadOrderID != "" {
+		ids := m.followers[o.leadOrderID]
+		for i, id := range ids {
+			if id == o.orderID {
+				m.followers[o.leadOrderID] = append(ids[:i:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(m.followers[o.leadOrderID]) == 0 {
+			delete(m.followers, o.leadOrderID)
+		}
+	}
+}
+
+// fillLocked 均价法更新持仓：同向加仓更新均价，反向先平仓计已实现盈亏，剩余按成交价反向开仓
+func (m *Manager) fillLocked(b *book, symbol string, side orspb.OrderSide, qty int64, price float64) {
+	p := b.position(symbol)
+	sign := int64(1)
+	if side == orspb.OrderSide_SELL {
+		sign = -1
+		p.SellQty += qty
+	} else {
+		p.BuyQty += qty
+	}
+	if p.LastPrice == 0 {
+		p.LastPrice = price
+	}
+
+	if p.NetQty == 0 || (p.NetQty > 0) == (sign > 0) {
+		abs := absQty(p.NetQty)
+		p.AvgPrice = (p.AvgPrice*float64(abs) + price*float64(qty)) / float64(abs+qty)
+		p.NetQty += sign * qty
+		return
+	}
+
+	closeQty := min(qty, absQty(p.NetQty))
+	dir := float64(p.NetQty / absQty(p.NetQty))
+	p.RealizedPnL += (price - p.AvgPrice) * float64(closeQty) * dir * m.multiplier(symbol)
+	p.NetQty += sign * closeQty
+	if rest := qty - closeQty; rest > 0 {
+		p.NetQty += sign * rest
+		p.AvgPrice = price
+	} else if p.NetQty == 0 {
+		p.AvgPrice = 0
+	}
+}
+
+func (m *Manager) unrealizedLocked(p *Position) float64 {
+	if p.NetQty == 0 || p.LastPrice == 0 {
+		return 0
+	}
+	return (p.LastPrice - p.AvgPrice) * float64(p.NetQty) * m.multiplier(p.Symbol)
+}
+
+func (m *Manager) dailyPnLLocked(b *book) float64 {
+	pnl := 0.0
+	for _, p := range b.positions {
+		pnl += p.RealizedPnL + m.unrealizedLocked(p)
+	}
+	return pnl
+}
+
+func absQty(q int64) int64 {
+	if q < 0 {
+		return -q
+	}
+	return q
+}
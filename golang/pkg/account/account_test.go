@@ -0,0 +1,270 @@
+package account
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/offset"
+	commonpb "github.com/yourusername/quantlink-trade-system/pkg/proto/common"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+func newTestManager(t *testing.T, global Limits) *Manager {
+	t.Helper()
+	m := NewManager(global)
+	m.SetMultiplier(func(string) float64 { return 15 })
+	for id, lim := range map[string]Limits{
+		"A": {},
+		"B": {MaxOrderQty: 5},
+		"C": {MaxPosition: 2},
+	} {
+		if err := m.AddAccount(id, lim); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Assign("pair", []Allocation{{"A", 1}, {"B", 2.5}, {"C", 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func buy(qty int64) *orspb.OrderRequest {
+	return &orspb.OrderRequest{StrategyId: "pair", Symbol: "ag2506", Side: orspb.OrderSide_BUY, Price: 8000, Quantity: qty}
+}
+
+func fill(req *orspb.OrderRequest, orderID string, filled int64, price float64, status orspb.OrderStatus) *orspb.OrderUpdate {
+	return &orspb.OrderUpdate{
+		OrderId:       orderID,
+		ClientOrderId: req.ClientOrderId,
+		StrategyId:    req.StrategyId,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Status:        status,
+		Quantity:      req.Quantity,
+		FilledQty:     filled,
+		AvgPrice:      price,
+	}
+}
+
+// TestFanout 验证按 scale 展开、跟随账户风控跳过、StrategyId/Account/client_order_id 设置
+func TestFanout(t *testing.T) {
+	m := newTestManager(t, Limits{})
+
+	reqs, err := m.Fanout(buy(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A=2, B=5, C=round(1)=1
+	want := map[string]int64{"A": 2, "B": 5, "C": 1}
+	if len(reqs) != 3 {
+		t.Fatalf("fanout = %d orders, want 3", len(reqs))
+	}
+	for i, r := range reqs {
+		if r.Quantity != want[r.Account] {
+			t.Errorf("%s qty = %d, want %d", r.Account, r.Quantity, want[r.Account])
+		}
+		if r.ClientOrderId == "" || r.Metadata["account"] != r.Account {
+			t.Errorf("%s client_order_id=%q metadata=%v", r.Account, r.ClientOrderId, r.Metadata)
+		}
+		if wantID := "pair"; i > 0 {
+			wantID = FollowerStrategyID("pair", r.Account)
+			if r.StrategyId != wantID {
+				t.Errorf("follower strategy id = %s, want %s", r.StrategyId, wantID)
+			}
+		} else if r.Account != "A" || r.StrategyId != wantID {
+			t.Errorf("lead = %s/%s", r.Account, r.StrategyId)
+		}
+	}
+
+	// 3 手：B 缩放后 8 手超过单笔上限，C 在途 1 + 2 超过持仓上限 → 只发主账户
+	reqs, err = m.Fanout(buy(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 1 || reqs[0].Account != "A" {
+		t.Fatalf("fanout = %+v, want lead only", reqs)
+	}
+
+	// 未分配账户的策略原样返回
+	other := &orspb.OrderRequest{StrategyId: "other", Symbol: "ag2506", Quantity: 1}
+	if reqs, _ := m.Fanout(other); len(reqs) != 1 || reqs[0] != other {
+		t.Errorf("unassigned strategy should pass through")
+	}
+}
+
+// TestFanoutGlobalAndLeadLimits 验证主账户/全局限额整单拒绝
+func TestFanoutGlobalAndLeadLimits(t *testing.T) {
+	m := newTestManager(t, Limits{MaxPosition: 6})
+	if _, err := m.Fanout(buy(2)); err == nil { // 2+5+1 = 8 > 6
+		t.Fatal("expected global max_position rejection")
+	}
+	if _, err := m.Fanout(buy(1)); err != nil { // 1+3+1 = 5
+		t.Fatalf("small order rejected: %v", err)
+	}
+
+	m2 := newTestManager(t, Limits{})
+	if err := m2.AddAccount("L", Limits{MaxOrderQty: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Assign("pair", []Allocation{{"L", 1}, {"A", 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m2.Fanout(buy(2)); err == nil {
+		t.Fatal("expected lead max_order_qty rejection")
+	}
+	if err := m2.Assign("pair", []Allocation{{"A", 2}}); err == nil {
+		t.Fatal("expected error for lead scale != 1")
+	}
+}
+
+// TestOrderUpdates 验证回报分类、持仓/盈亏、跟随账户撤单索引与亏损上限
+func TestOrderUpdates(t *testing.T) {
+	m := newTestManager(t, Limits{})
+	reqs, err := m.Fanout(buy(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lead, fB, fC := reqs[0], reqs[1], reqs[2]
+	m.Bind(lead, "L1", "")
+	m.Bind(fB, "B1", "L1")
+	m.Bind(fC, "C1", "L1")
+
+	if got := m.Followers("L1"); len(got) != 2 {
+		t.Fatalf("followers = %v", got)
+	}
+	if !m.OnOrderUpdate(fill(lead, "L1", 2, 8000, orspb.OrderStatus_FILLED)) {
+		t.Error("lead update should dispatch to strategy")
+	}
+	if m.OnOrderUpdate(fill(fB, "B1", 5, 8000, orspb.OrderStatus_FILLED)) {
+		t.Error("follower update should not dispatch to strategy")
+	}
+	if m.OnOrderUpdate(fill(fC, "C1", 0, 0, orspb.OrderStatus_CANCELED)) {
+		t.Error("follower update should not dispatch to strategy")
+	}
+	if got := m.Followers("L1"); len(got) != 0 {
+		t.Errorf("followers after B1 filled / C1 canceled = %v, want none", got)
+	}
+	if !m.OnOrderUpdate(&orspb.OrderUpdate{OrderId: "X", StrategyId: "other"}) {
+		t.Error("unknown order should dispatch to strategy")
+	}
+	if m.OnOrderUpdate(&orspb.OrderUpdate{OrderId: "Y", StrategyId: "pair@B"}) {
+		t.Error("stale follower order should not dispatch to strategy")
+	}
+
+	// B 卖出 3 手 @7990：已实现 (7990-8000)*3*15 = -450
+	sell := &orspb.OrderRequest{StrategyId: "pair@B", Symbol: "ag2506", Side: orspb.OrderSide_SELL, Quantity: 3}
+	m.mu.Lock()
+	m.fillLocked(m.books["B"], "ag2506", orspb.OrderSide_SELL, sell.Quantity, 7990)
+	m.mu.Unlock()
+	m.MarkPrice("ag2506", 7980)
+
+	snap, ok := m.AccountSnapshot("B")
+	if !ok || len(snap.Positions) != 1 {
+		t.Fatalf("snapshot B = %+v", snap)
+	}
+	p := snap.Positions[0]
+	if p.NetQty != 2 || p.AvgPrice != 8000 || p.PendingBuy != 0 || p.BuyQty != 5 || p.SellQty != 3 {
+		t.Errorf("position B = %+v", p)
+	}
+	if math.Abs(snap.RealizedPnL+450) > 1e-9 || math.Abs(snap.UnrealizedPnL+600) > 1e-9 {
+		t.Errorf("pnl B realized=%v unrealized=%v, want -450/-600", snap.RealizedPnL, snap.UnrealizedPnL)
+	}
+	if a, _ := m.AccountSnapshot("A"); a.Positions[0].NetQty != 2 {
+		t.Errorf("position A = %+v", a.Positions)
+	}
+
+	// 亏损上限：B 只允许减仓
+	m.books["B"].limits.MaxDailyLoss = 1000
+	m.mu.Lock()
+	errOpen := m.checkLocked(m.books["B"], "ag2506", orspb.OrderSide_BUY, 1)
+	errClose := m.checkLocked(m.books["B"], "ag2506", orspb.OrderSide_SELL, 1)
+	m.mu.Unlock()
+	if errOpen == nil || errClose != nil {
+		t.Errorf("loss limit: open err=%v close err=%v", errOpen, errClose)
+	}
+
+	// 持久化：跨交易日只保留净持仓与均价
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	if err := m.Save(path, "20260105"); err != nil {
+		t.Fatal(err)
+	}
+	m2 := newTestManager(t, Limits{})
+	if n, err := m2.Load(path, "20260106"); err != nil || n != 2 {
+		t.Fatalf("Load = %d, %v", n, err)
+	}
+	b, _ := m2.AccountSnapshot("B")
+	if b.Positions[0].NetQty != 2 || b.Positions[0].AvgPrice != 8000 || b.RealizedPnL != 0 {
+		t.Errorf("restored B = %+v", b)
+	}
+}
+
+// TestFanoutPlansOffsetsPerAccount 验证各账户按自身台账规划开平：
+// 主账户有昨仓全部平昨，跟随账户昨仓不足时拆成平昨 + 开仓，空仓账户直接开仓
+func TestFanoutPlansOffsetsPerAccount(t *testing.T) {
+	m := newTestManager(t, Limits{})
+	planner := offset.NewPlanner(offset.LockNever)
+	planner.SetAccountPosition("A", "ag2506", offset.Position{LongYd: 2})
+	planner.SetAccountPosition("B", "ag2506", offset.Position{LongYd: 3})
+	m.SetOffsetPlanner(planner)
+
+	sell := buy(2)
+	sell.Side = orspb.OrderSide_SELL
+	sell.Exchange = commonpb.Exchange_SHFE
+	reqs, err := m.Fanout(sell)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type leg struct {
+		account string
+		oc      orspb.OpenClose
+		qty     int64
+	}
+	want := []leg{
+		{"A", orspb.OpenClose_CLOSE_YESTERDAY, 2},
+		{"B", orspb.OpenClose_CLOSE_YESTERDAY, 3},
+		{"B", orspb.OpenClose_OPEN, 2},
+		{"C", orspb.OpenClose_OPEN, 1},
+	}
+	if len(reqs) != len(want) {
+		t.Fatalf("fanout = %d orders, want %d: %v", len(reqs), len(want), reqs)
+	}
+	seen := make(map[string]bool)
+	for i, w := range want {
+		r := reqs[i]
+		if r.Account != w.account || r.OpenClose != w.oc || r.Quantity != w.qty {
+			t.Errorf("order %d = %s %v %d, want %s %v %d", i, r.Account, r.OpenClose, r.Quantity, w.account, w.oc, w.qty)
+		}
+		if seen[r.ClientOrderId] {
+			t.Errorf("duplicate client_order_id %s", r.ClientOrderId)
+		}
+		seen[r.ClientOrderId] = true
+	}
+	if pos := planner.AccountPosition("B", "ag2506"); pos.LongYd != 0 {
+		t.Errorf("B ledger after plan = %+v, want yesterday fully reserved", pos)
+	}
+
+	// 跟随账户平仓单报单失败：恢复该账户可平量；已报出的订单按订单号跟踪开平
+	m.Bind(reqs[0], "L1", "")
+	m.Abort(reqs[1])
+	if pos := planner.AccountPosition("B", "ag2506"); pos.LongYd != 3 {
+		t.Errorf("B ledger after abort = %+v, want LongYd 3", pos)
+	}
+	if pos := planner.AccountPosition("A", "ag2506"); pos.LongYd != 0 {
+		t.Errorf("A ledger = %+v, want LongYd 0", pos)
+	}
+	if oc, ok := planner.OffsetOf("L1"); !ok || oc != offset.CloseYesterday {
+		t.Errorf("OffsetOf(L1) = %v %v, want CLOSE_YESTERDAY", oc, ok)
+	}
+
+	// 指定了开平的策略订单不再规划
+	open := buy(1)
+	open.OpenClose = orspb.OpenClose_OPEN
+	reqs, _ = m.Fanout(open)
+	for _, r := range reqs {
+		if r.OpenClose != orspb.OpenClose_OPEN {
+			t.Errorf("%s open_close = %v, want OPEN", r.Account, r.OpenClose)
+		}
+	}
+}
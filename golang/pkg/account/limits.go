@@ -0,0 +1,94 @@
+package account
+
+import (
+	"fmt"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// checkLocked 单账户事前风控
+// 持仓限额按"净持仓 + 在途同向委托 + 本单"计算（假设全部成交）；
+// 达到当日亏损上限后只允许减少 |净持仓| 的委托
+func (m *Manager) checkLocked(b *book, symbol string, side orspb.OrderSide, qty int64) error {
+	if qty <= 0 {
+		return fmt.Errorf("invalid quantity %d", qty)
+	}
+	lim := b.limits
+	if lim.MaxOrderQty > 0 && qty > lim.MaxOrderQty {
+		return fmt.Errorf("order qty %d exceeds max_order_qty %d", qty, lim.MaxOrderQty)
+	}
+
+	var net, pendingBuy, pendingSell int64
+	if p, ok := b.positions[symbol]; ok {
+		net, pendingBuy, pendingSell = p.NetQty, p.PendingBuy, p.PendingSell
+	}
+	if err := checkPosition(lim.MaxPosition, symbol, side, qty, net, pendingBuy, pendingSell); err != nil {
+		return err
+	}
+	if lim.MaxDailyLoss > 0 && increases(net, side, qty) {
+		if pnl := m.dailyPnLLocked(b); -pnl >= lim.MaxDailyLoss {
+			return fmt.Errorf("daily loss %.2f reached max_daily_loss %.2f, only reducing orders allowed",
+				-pnl, lim.MaxDailyLoss)
+		}
+	}
+	return nil
+}
+
+// checkGlobalLocked 跨账户汇总风控：orders 为同一策略订单展开后的全部账户订单
+func (m *Manager) checkGlobalLocked(orders []*orspb.OrderRequest) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	lim := m.global
+	symbol, side := orders[0].Symbol, orders[0].Side
+
+	var qty int64
+	for _, o := range orders {
+		qty += o.Quantity
+	}
+	if lim.MaxOrderQty > 0 && qty > lim.MaxOrderQty {
+		return fmt.Errorf("total order qty %d exceeds max_order_qty %d", qty, lim.MaxOrderQty)
+	}
+
+	var net, pendingBuy, pendingSell int64
+	pnl := 0.0
+	for _, b := range m.books {
+		if p, ok := b.positions[symbol]; ok {
+			net += p.NetQty
+			pendingBuy += p.PendingBuy
+			pendingSell += p.PendingSell
+		}
+		pnl += m.dailyPnLLocked(b)
+	}
+	if err := checkPosition(lim.MaxPosition, symbol, side, qty, net, pendingBuy, pendingSell); err != nil {
+		return err
+	}
+	if lim.MaxDailyLoss > 0 && increases(net, side, qty) && -pnl >= lim.MaxDailyLoss {
+		return fmt.Errorf("total daily loss %.2f reached max_daily_loss %.2f, only reducing orders allowed",
+			-pnl, lim.MaxDailyLoss)
+	}
+	return nil
+}
+
+// checkPosition 持仓限额：买单检查 net+pendingBuy+qty，卖单检查 net-pendingSell-qty
+func checkPosition(max int64, symbol string, side orspb.OrderSide, qty, net, pendingBuy, pendingSell int64) error {
+	if max <= 0 {
+		return nil
+	}
+	projected := net - pendingSell - qty
+	if side == orspb.OrderSide_BUY {
+		projected = net + pendingBuy + qty
+	}
+	if absQty(projected) > max && absQty(projected) > absQty(net) {
+		return fmt.Errorf("%s projected position %d exceeds max_position %d", symbol, projected, max)
+	}
+	return nil
+}
+
+// increases 委托成交后 |净持仓| 是否增加
+func increases(net int64, side orspb.OrderSide, qty int64) bool {
+	if side == orspb.OrderSide_SELL {
+		qty = -qty
+	}
+	return absQty(net+qty) > absQty(net)
+}
@@ -0,0 +1,69 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateFile 账户持仓快照文件
+type stateFile struct {
+	TradingDay string     `json:"trading_day"`
+	SavedAt    time.Time  `json:"saved_at"`
+	Accounts   []Snapshot `json:"accounts"`
+}
+
+// Save 保存全部账户持仓（先写临时文件再改名）
+func (m *Manager) Save(path, tradingDay string) error {
+	st := stateFile{TradingDay: tradingDay, SavedAt: time.Now(), Accounts: m.Snapshot()}
+	data, err := json.MarshalIndent(&st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load 恢复账户持仓，返回恢复的持仓条数；文件不存在时返回 0, nil
+// 快照交易日与 tradingDay 不同时只恢复净持仓与均价（当日成交量/已实现盈亏清零）。
+// 未登记的账户跳过
+func (m *Manager) Load(path, tradingDay string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var st stateFile
+	if err := json.Unmarshal(data, &st); err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	n := 0
+	for _, s := range st.Accounts {
+		for _, p := range s.Positions {
+			if st.TradingDay != tradingDay {
+				p.BuyQty, p.SellQty, p.RealizedPnL = 0, 0, 0
+			}
+			if p.NetQty == 0 && p.RealizedPnL == 0 {
+				continue
+			}
+			if err := m.SetPosition(s.Account, p); err != nil {
+				continue
+			}
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,596 @@
+// Package algo 提供母单执行算法（TWAP / VWAP / POV / Implementation Shortfall）
+//
+// 算法本身是纯状态机：调用方喂入行情（Quote）、定时器和子单成交/结束事件，
+// 算法返回下单/撤单动作（Action）。子单先按己方一档被动挂单，
+// 超时未成交、落后计划过多、低于最低参与率（计划量按下限抬高）或临近结束时升级为对价追单；
+// 超过最高参与率或领先计划时暂停。时间全部由调用方传入，便于回放和测试。
+// （Go 扩展）
+package algo
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Type 执行算法类型
+type Type string
+
+const (
+	TypeTWAP Type = "twap" // 时间均匀
+	TypeVWAP Type = "vwap" // 按历史成交量曲线，实际成交量偏离历史时自适应加速/放慢
+	TypePOV  Type = "pov"  // 按市场成交量的固定比例
+	TypeIS   Type = "is"   // Implementation Shortfall：前置执行，降低相对到达价的风险
+)
+
+// Side 母单方向
+type Side string
+
+const (
+	Buy  Side = "buy"
+	Sell Side = "sell"
+)
+
+// State 母单状态
+type State string
+
+const (
+	StatePending   State = "pending"   // 未到开始时间或尚无行情
+	StateWorking   State = "working"   // 执行中
+	StateCompleted State = "completed" // 全部成交
+	StateCanceled  State = "canceled"  // 用户撤销
+	StateExpired   State = "expired"   // 到结束时间未成交完
+	StateFailed    State = "failed"    // 子单连续被拒
+)
+
+// Terminal 是否为终态
+func (s State) Terminal() bool {
+	return s == StateCompleted || s == StateCanceled || s == StateExpired || s == StateFailed
+}
+
+// 默认参数
+const (
+	DefaultPassiveWait = 10 * time.Second
+	DefaultCatchUpPct  = 0.05
+	DefaultSlices      = 20
+	MinSlice           = 5 * time.Second
+	maxRejects         = 5
+)
+
+// Spec 母单参数
+type Spec struct {
+	ID       string `json:"id"`
+	Symbol   string `json:"symbol"`
+	Exchange string `json:"exchange,omitempty"`
+	Side     Side   `json:"side"`
+	Qty      int64  `json:"qty"`
+	Type     Type   `json:"type"`
+
+	Start time.Time `json:"start"` // 零值 = 创建时
+	End   time.Time `json:"end"`   // TWAP/VWAP/IS 必填；POV 为空时成交完为止
+
+	LimitPrice       float64 `json:"limit_price,omitempty"`       // 0 = 不限价；买单不高于、卖单不低于该价
+	Participation    float64 `json:"participation,omitempty"`     // POV 目标参与率 (0, 1]
+	MinParticipation float64 `json:"min_participation,omitempty"` // 低于该参与率时追单追赶
+	MaxParticipation float64 `json:"max_participation,omitempty"` // 达到该参与率时暂停
+	Urgency          float64 `json:"urgency,omitempty"`           // IS 前置程度 κT，默认 1；越大越前置
+
+	SliceSec      int     `json:"slice_sec,omitempty"`       // 调度粒度，默认窗口的 1/20（不少于 5s）
+	PassiveWaitMs int     `json:"passive_wait_ms,omitempty"` // 被动子单最长等待，默认 10000
+	CatchUpPct    float64 `json:"catch_up_pct,omitempty"`    // 落后计划超过母单该比例时直接追单，默认 0.05
+	MaxChildQty   int64   `json:"max_child_qty,omitempty"`   // 单笔子单上限，0 = 不限
+}
+
+// Validate 检查母单参数
+func (s *Spec) Validate() error {
+	if s.ID == "" || s.Symbol == "" {
+		return fmt.Errorf("id and symbol are required")
+	}
+	if s.Qty <= 0 {
+		return fmt.Errorf("qty must be positive")
+	}
+	if s.Side != Buy && s.Side != Sell {
+		return fmt.Errorf("invalid side %q", s.Side)
+	}
+	switch s.Type {
+	case TypeTWAP, TypeVWAP, TypeIS:
+		if s.End.IsZero() {
+			return fmt.Errorf("%s requires end time", s.Type)
+		}
+	case TypePOV:
+		if s.Participation <= 0 || s.Participation > 1 {
+			return fmt.Errorf("pov participation must be in (0, 1]")
+		}
+	default:
+		return fmt.Errorf("unknown algo type %q", s.Type)
+	}
+	if !s.End.IsZero() && !s.Start.IsZero() && !s.End.After(s.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	for _, p := range []float64{s.MinParticipation, s.MaxParticipation} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("participation bounds must be in [0, 1]")
+		}
+	}
+	if s.MinParticipation > 0 && s.MaxParticipation > 0 && s.MinParticipation > s.MaxParticipation {
+		return fmt.Errorf("min_participation > max_participation")
+	}
+	if s.LimitPrice < 0 || s.MaxChildQty < 0 || s.CatchUpPct < 0 || s.Urgency < 0 {
+		return fmt.Errorf("negative limit_price/max_child_qty/catch_up_pct/urgency")
+	}
+	return nil
+}
+
+// Quote 行情快照
+type Quote struct {
+	Time        time.Time
+	Bid, Ask    float64
+	TotalVolume int64 // 交易所累计成交量（优先使用）
+	LastQty     int64 // 最新成交量（无累计量时使用，如回放的录制行情）
+}
+
+func (q Quote) mid() float64 {
+	if q.Bid > 0 && q.Ask > 0 {
+		return (q.Bid + q.Ask) / 2
+	}
+	return math.Max(q.Bid, q.Ask)
+}
+
+// ActionKind 动作类型
+type ActionKind int
+
+const (
+	ActionPlace ActionKind = iota
+	ActionCancel
+)
+
+// Action 算法输出的子单动作
+type Action struct {
+	Kind       ActionKind
+	ChildID    string
+	Price      float64
+	Qty        int64
+	Aggressive bool
+}
+
+type child struct {
+	id         string
+	price      float64
+	qty        int64
+	filled     int64
+	aggressive bool
+	placed     time.Time
+	mktVol     int64 // 下单时的市场成交量
+	canceling  bool
+}
+
+// Progress 母单进度
+type Progress struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Side   Side   `json:"side"`
+	Type   Type   `json:"type"`
+	State  State  `json:"state"`
+	Note   string `json:"note,omitempty"` // 最近一次决策说明（暂停/限价/追单原因）
+
+	Qty      int64   `json:"qty"`
+	Filled   int64   `json:"filled"`
+	Working  int64   `json:"working"`
+	Target   int64   `json:"target"`   // 按计划当前应完成量
+	Percent  float64 `json:"percent"`  // 成交进度 %
+	Schedule float64 `json:"schedule"` // 计划进度 %
+
+	AvgPrice     float64 `json:"avg_price"`
+	ArrivalPrice float64 `json:"arrival_price"`
+	SlippageBps  float64 `json:"slippage_bps"` // 相对到达价的执行成本（正 = 不利）
+
+	MarketVolume     int64   `json:"market_volume"` // 开始以来市场成交量
+	Participation    float64 `json:"participation"`
+	Children         int     `json:"children"`
+	PassiveFilled    int64   `json:"passive_filled"`
+	AggressiveFilled int64   `json:"aggressive_filled"`
+	Curve            string  `json:"curve,omitempty"` // VWAP: learned / linear
+
+	Start      time.Time  `json:"start"`
+	End        time.Time  `json:"end,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Algo 一个母单的执行状态机（非并发安全，由宿主加锁）
+type Algo struct {
+	spec        Spec
+	curve       *VolumeCurve
+	slice       time.Duration
+	passiveWait time.Duration
+
+	state      State
+	note       string
+	startedAt  time.Time
+	finishedAt time.Time
+
+	quote     Quote
+	haveQuote bool
+	arrival   float64
+	lastTotal int64
+	volSeen   bool
+	mktVol    int64
+
+	filled        int64
+	notional      float64
+	passiveFilled int64
+	aggrFilled    int64
+	target        int64
+	child         *child
+	seq           int
+	rejects       int
+	escalate      bool
+}
+
+// New 创建母单；curve 仅 VWAP 使用（nil 时退化为时间均匀）
+func New(spec Spec, curve *VolumeCurve, now time.Time) (*Algo, error) {
+	if spec.Start.IsZero() {
+		spec.Start = now
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	if spec.Type == TypeIS && spec.Urgency == 0 {
+		spec.Urgency = 1
+	}
+	if spec.CatchUpPct == 0 {
+		spec.CatchUpPct = DefaultCatchUpPct
+	}
+
+	a := &Algo{
+		spec:        spec,
+		curve:       curve,
+		state:       StatePending,
+		passiveWait: DefaultPassiveWait,
+	}
+	if spec.PassiveWaitMs > 0 {
+		a.passiveWait = time.Duration(spec.PassiveWaitMs) * time.Millisecond
+	}
+	if spec.SliceSec > 0 {
+		a.slice = time.Duration(spec.SliceSec) * time.Second
+	} else if !spec.End.IsZero() {
+		a.slice = spec.End.Sub(spec.Start) / DefaultSlices
+	}
+	if a.slice < MinSlice {
+		a.slice = MinSlice
+	}
+	return a, nil
+}
+
+// Spec 返回母单参数
+func (a *Algo) Spec() Spec {
+	return a.spec
+}
+
+// State 返回母单状态
+func (a *Algo) State() State {
+	return a.state
+}
+
+// OnQuote 处理行情：更新市场成交量后重新评估
+func (a *Algo) OnQuote(q Quote) []Action {
+	a.updateVolume(q)
+	if q.Bid > 0 || q.Ask > 0 {
+		a.quote = q
+		a.haveQuote = true
+	}
+	return a.step(q.Time)
+}
+
+// OnTimer 无行情时按时间重新评估（被动子单超时、结束时间）
+func (a *Algo) OnTimer(now time.Time) []Action {
+	return a.step(now)
+}
+
+// OnFill 子单成交（qty 为本次增量）
+func (a *Algo) OnFill(childID string, qty int64, price float64) {
+	if qty <= 0 {
+		return
+	}
+	a.filled += qty
+	a.notional += float64(qty) * price
+	a.rejects = 0
+
+	aggressive := false
+	if c := a.child; c != nil && c.id == childID {
+		c.filled += qty
+		aggressive = c.aggressive
+		if c.filled >= c.qty {
+			a.child = nil
+		}
+	}
+	if aggressive {
+		a.aggrFilled += qty
+	} else {
+		a.passiveFilled += qty
+	}
+	if a.filled >= a.spec.Qty && !a.state.Terminal() {
+		a.finish(StateCompleted, "filled", a.quote.Time)
+	}
+}
+
+// OnChildDone 子单结束（撤单完成/拒单/过期）；rejected 表示未被交易所接受
+func (a *Algo) OnChildDone(childID string, rejected bool) {
+	c := a.child
+	if c == nil || c.id != childID {
+		return
+	}
+	a.child = nil
+	if !rejected {
+		return
+	}
+	a.rejects++
+	if a.rejects >= maxRejects && !a.state.Terminal() {
+		a.finish(StateFailed, fmt.Sprintf("%d consecutive child rejects", a.rejects), a.quote.Time)
+	}
+}
+
+// Cancel 撤销母单（撤掉在途子单）
+func (a *Algo) Cancel(now time.Time) []Action {
+	if a.state.Terminal() {
+		return nil
+	}
+	a.finish(StateCanceled, "canceled by user", now)
+	return a.cancelChild()
+}
+
+// Progress 返回进度快照
+func (a *Algo) Progress() Progress {
+	p := Progress{
+		ID:               a.spec.ID,
+		Symbol:           a.spec.Symbol,
+		Side:             a.spec.Side,
+		Type:             a.spec.Type,
+		State:            a.state,
+		Note:             a.note,
+		Qty:              a.spec.Qty,
+		Filled:           a.filled,
+		Target:           a.target,
+		Percent:          100 * float64(a.filled) / float64(a.spec.Qty),
+		Schedule:         100 * float64(a.target) / float64(a.spec.Qty),
+		ArrivalPrice:     a.arrival,
+		MarketVolume:     a.mktVol,
+		Children:         a.seq,
+		PassiveFilled:    a.passiveFilled,
+		AggressiveFilled: a.aggrFilled,
+		Start:            a.spec.Start,
+		End:              a.spec.End,
+	}
+	if c := a.child; c != nil {
+		p.Working = c.qty - c.filled
+	}
+	if a.filled > 0 {
+		p.AvgPrice = a.notional / float64(a.filled)
+		if a.arrival > 0 {
+			p.SlippageBps = (p.AvgPrice - a.arrival) / a.arrival * 1e4
+			if a.spec.Side == Sell {
+				p.SlippageBps = -p.SlippageBps
+			}
+		}
+	}
+	if a.mktVol > 0 {
+		p.Participation = float64(a.filled) / float64(a.mktVol)
+	}
+	if a.spec.Type == TypeVWAP {
+		p.Curve = "linear"
+		if a.curveUsable() {
+			p.Curve = "learned"
+		}
+	}
+	if !a.startedAt.IsZero() {
+		t := a.startedAt
+		p.StartedAt = &t
+	}
+	if !a.finishedAt.IsZero() {
+		t := a.finishedAt
+		p.FinishedAt = &t
+	}
+	return p
+}
+
+// updateVolume 累计开始以来的市场成交量
+func (a *Algo) updateVolume(q Quote) {
+	var delta int64
+	switch {
+	case q.TotalVolume > 0:
+		if a.lastTotal > 0 && q.TotalVolume >= a.lastTotal {
+			delta = q.TotalVolume - a.lastTotal
+		}
+		a.lastTotal = q.TotalVolume
+		a.volSeen = true
+	case q.LastQty > 0:
+		delta = q.LastQty
+		a.volSeen = true
+	}
+	if a.state == StateWorking {
+		a.mktVol += delta
+	}
+}
+
+func (a *Algo) step(now time.Time) []Action {
+	if a.state.Terminal() {
+		return nil
+	}
+	if now.Before(a.spec.Start) {
+		a.note = "waiting for start"
+		return nil
+	}
+	if a.state == StatePending {
+		if !a.haveQuote {
+			a.note = "waiting for market data"
+			return nil
+		}
+		a.state = StateWorking
+		a.startedAt = now
+		a.arrival = a.quote.mid()
+	}
+
+	remaining := a.spec.Qty - a.filled
+	if remaining <= 0 {
+		a.finish(StateCompleted, "filled", now)
+		return a.cancelChild()
+	}
+	if !a.spec.End.IsZero() && !now.Before(a.spec.End) {
+		a.finish(StateExpired, "end time reached", now)
+		return a.cancelChild()
+	}
+
+	a.target = a.targetQty(now)
+	behind := a.target - a.filled
+	lag := a.dueQty(now) - a.filled
+	if a.child != nil {
+		return a.manageChild(now, lag)
+	}
+	return a.placeChild(now, behind, lag, remaining)
+}
+
+// manageChild 在途子单：超参与率撤单暂停、被动超时/落后过多升级追单、行情走开后重挂
+// lag 为相对当前时间计划的落后量
+func (a *Algo) manageChild(now time.Time, lag int64) []Action {
+	c := a.child
+	if c.canceling {
+		return nil
+	}
+	if a.overMaxParticipation() {
+		a.note = "max participation reached, paused"
+		return a.cancelChild()
+	}
+
+	touch, capped := a.price(c.aggressive)
+	if c.aggressive {
+		// 追单未成交且对价已走开：撤单按新对价重下（限价封顶时保持挂单）
+		if !capped && touch > 0 && touch != c.price {
+			a.note = "market moved, repricing"
+			return a.cancelChild()
+		}
+		return nil
+	}
+
+	// 低于最低参与率且下单后市场已有成交（被动单未跟上）：升级追单
+	underMin := a.underMinParticipation() && a.mktVol > c.mktVol
+	if now.Sub(c.placed) >= a.passiveWait || lag >= a.catchUpQty() || a.nearEnd(now) || underMin {
+		a.escalate = true
+		a.note = "passive child escalated"
+		return a.cancelChild()
+	}
+	if !capped && touch > 0 && a.better(touch, c.price) {
+		a.note = "touch moved, requoting"
+		return a.cancelChild()
+	}
+	return nil
+}
+
+// placeChild 无在途子单时按计划下单：数量取到时间片末尾的缺口，落后过多时追单
+func (a *Algo) placeChild(now time.Time, behind, lag, remaining int64) []Action {
+	if a.overMaxParticipation() {
+		a.note = "max participation reached, paused"
+		return nil
+	}
+	if behind <= 0 {
+		a.note = "ahead of schedule"
+		return nil
+	}
+	qty := behind
+	if qty > remaining {
+		qty = remaining
+	}
+	if a.spec.MaxChildQty > 0 && qty > a.spec.MaxChildQty {
+		qty = a.spec.MaxChildQty
+	}
+
+	aggressive := a.escalate || lag >= a.catchUpQty() || a.nearEnd(now)
+	price, capped := a.price(aggressive)
+	if price <= 0 {
+		a.note = "no quote"
+		return nil
+	}
+	a.escalate = false
+	a.note = "passive"
+	if aggressive {
+		a.note = "aggressive"
+	}
+	if capped {
+		a.note = "capped at limit price"
+	}
+
+	a.seq++
+	c := &child{
+		id:         fmt.Sprintf("%s-%d", a.spec.ID, a.seq),
+		price:      price,
+		qty:        qty,
+		aggressive: aggressive,
+		placed:     now,
+		mktVol:     a.mktVol,
+	}
+	a.child = c
+	return []Action{{Kind: ActionPlace, ChildID: c.id, Price: price, Qty: qty, Aggressive: aggressive}}
+}
+
+func (a *Algo) cancelChild() []Action {
+	c := a.child
+	if c == nil || c.canceling {
+		return nil
+	}
+	c.canceling = true
+	return []Action{{Kind: ActionCancel, ChildID: c.id}}
+}
+
+func (a *Algo) finish(s State, note string, now time.Time) {
+	a.state = s
+	a.note = note
+	a.finishedAt = now
+}
+
+// price 被动取己方一档、追单取对方一档，按限价封顶；capped 表示价格被限价截断
+func (a *Algo) price(aggressive bool) (float64, bool) {
+	q := a.quote
+	p := q.Bid
+	if (a.spec.Side == Buy) == aggressive {
+		p = q.Ask
+	}
+	if p <= 0 || a.spec.LimitPrice <= 0 {
+		return p, false
+	}
+	if a.spec.Side == Buy && p > a.spec.LimitPrice {
+		return a.spec.LimitPrice, true
+	}
+	if a.spec.Side == Sell && p < a.spec.LimitPrice {
+		return a.spec.LimitPrice, true
+	}
+	return p, false
+}
+
+// better 新价格对本方是否更积极（买单更高 / 卖单更低）
+func (a *Algo) better(p, than float64) bool {
+	if a.spec.Side == Buy {
+		return p > than
+	}
+	return p < than
+}
+
+func (a *Algo) catchUpQty() int64 {
+	n := int64(math.Ceil(a.spec.CatchUpPct * float64(a.spec.Qty)))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (a *Algo) nearEnd(now time.Time) bool {
+	return !a.spec.End.IsZero() && a.spec.End.Sub(now) <= a.passiveWait
+}
+
+func (a *Algo) overMaxParticipation() bool {
+	return a.volSeen && a.spec.MaxParticipation > 0 &&
+		float64(a.filled) >= a.spec.MaxParticipation*float64(a.mktVol)
+}
+
+func (a *Algo) underMinParticipation() bool {
+	return a.volSeen && a.spec.MinParticipation > 0 && a.mktVol > 0 &&
+		float64(a.filled) < a.spec.MinParticipation*float64(a.mktVol)
+}
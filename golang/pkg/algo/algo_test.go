@@ -0,0 +1,191 @@
+package algo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+
+func quote(sec int, bid, ask float64, total int64) Quote {
+	return Quote{Time: t0.Add(time.Duration(sec) * time.Second), Bid: bid, Ask: ask, TotalVolume: total}
+}
+
+func mustNew(t *testing.T, spec Spec, curve *VolumeCurve) *Algo {
+	t.Helper()
+	a, err := New(spec, curve, t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func expectOne(t *testing.T, acts []Action, kind ActionKind) Action {
+	t.Helper()
+	if len(acts) != 1 || acts[0].Kind != kind {
+		t.Fatalf("actions = %+v, want one kind %d", acts, kind)
+	}
+	return acts[0]
+}
+
+func TestTWAP_PassiveThenEscalate(t *testing.T) {
+	a := mustNew(t, Spec{
+		ID: "p1", Symbol: "ag2502", Side: Buy, Qty: 100, Type: TypeTWAP,
+		End: t0.Add(100 * time.Second), SliceSec: 10, PassiveWaitMs: 3000,
+	}, nil)
+
+	// 第一个时间片：10 手按买一被动挂单
+	act := expectOne(t, a.OnQuote(quote(0, 100, 101, 0)), ActionPlace)
+	if act.Price != 100 || act.Qty != 10 || act.Aggressive {
+		t.Fatalf("first child = %+v", act)
+	}
+	if acts := a.OnTimer(t0.Add(2 * time.Second)); len(acts) != 0 {
+		t.Fatalf("unexpected actions before passive wait: %+v", acts)
+	}
+
+	// 被动等待超时：撤单后按卖一追单
+	cancel := expectOne(t, a.OnTimer(t0.Add(3*time.Second)), ActionCancel)
+	if cancel.ChildID != act.ChildID {
+		t.Fatalf("cancel %s, want %s", cancel.ChildID, act.ChildID)
+	}
+	if acts := a.OnTimer(t0.Add(3 * time.Second)); len(acts) != 0 {
+		t.Fatalf("placed while cancel pending: %+v", acts)
+	}
+	a.OnChildDone(act.ChildID, false)
+	aggr := expectOne(t, a.OnTimer(t0.Add(3*time.Second)), ActionPlace)
+	if aggr.Price != 101 || aggr.Qty != 10 || !aggr.Aggressive {
+		t.Fatalf("escalated child = %+v", aggr)
+	}
+	a.OnFill(aggr.ChildID, 10, 101)
+
+	// 已完成本时间片计划：暂停到下一时间片
+	if acts := a.OnTimer(t0.Add(5 * time.Second)); len(acts) != 0 {
+		t.Fatalf("ahead of schedule but placed: %+v", acts)
+	}
+	next := expectOne(t, a.OnQuote(quote(10, 100, 101, 0)), ActionPlace)
+	if next.Qty != 10 || next.Aggressive {
+		t.Fatalf("second slice child = %+v", next)
+	}
+
+	p := a.Progress()
+	if p.State != StateWorking || p.Filled != 10 || p.Working != 10 || p.Target != 20 || p.AggressiveFilled != 10 || p.Children != 3 {
+		t.Errorf("progress = %+v", p)
+	}
+	if want := 0.5 / 100.5 * 1e4; math.Abs(p.SlippageBps-want) > 1e-9 || p.ArrivalPrice != 100.5 {
+		t.Errorf("slippage = %v (arrival %v), want %v", p.SlippageBps, p.ArrivalPrice, want)
+	}
+}
+
+func TestPOV_ParticipationBounds(t *testing.T) {
+	a := mustNew(t, Spec{
+		ID: "p2", Symbol: "ag2502", Side: Sell, Qty: 1000, Type: TypePOV,
+		Participation: 0.1, MinParticipation: 0.05,
+	}, nil)
+
+	// 开始时的累计量只作基准
+	if acts := a.OnQuote(quote(0, 100, 101, 5000)); len(acts) != 0 {
+		t.Fatalf("placed with no market volume: %+v", acts)
+	}
+	act := expectOne(t, a.OnQuote(quote(1, 100, 101, 5100)), ActionPlace)
+	if act.Qty != 10 || act.Price != 101 || act.Aggressive {
+		t.Fatalf("pov child = %+v", act)
+	}
+	a.OnFill(act.ChildID, 10, 101)
+
+	// 市场放量，参与率跌破下限：追单按买一卖出
+	act = expectOne(t, a.OnQuote(quote(2, 100, 101, 5600)), ActionPlace)
+	if act.Qty != 50 || act.Price != 100 || !act.Aggressive {
+		t.Fatalf("catch-up child = %+v", act)
+	}
+	a.OnFill(act.ChildID, 50, 100)
+	if p := a.Progress(); p.MarketVolume != 600 || math.Abs(p.Participation-0.1) > 1e-9 {
+		t.Errorf("progress = %+v", p)
+	}
+
+	// 最高参与率：参与率达到上限即暂停
+	b := mustNew(t, Spec{
+		ID: "p3", Symbol: "ag2502", Side: Buy, Qty: 100, Type: TypeTWAP,
+		End: t0.Add(100 * time.Second), SliceSec: 10, MaxParticipation: 0.1,
+	}, nil)
+	if acts := b.OnQuote(quote(0, 100, 101, 5000)); len(acts) != 0 {
+		t.Fatalf("placed above max participation: %+v", acts)
+	}
+	if p := b.Progress(); p.Note != "max participation reached, paused" {
+		t.Errorf("note = %q", p.Note)
+	}
+	act = expectOne(t, b.OnQuote(quote(1, 100, 101, 5050)), ActionPlace)
+	if act.Qty != 5 {
+		t.Fatalf("capped child = %+v", act)
+	}
+}
+
+func TestLimitPriceAndExpiry(t *testing.T) {
+	a := mustNew(t, Spec{
+		ID: "p4", Symbol: "ag2502", Side: Sell, Qty: 10, Type: TypeTWAP,
+		End: t0.Add(60 * time.Second), SliceSec: 60, LimitPrice: 101, PassiveWaitMs: 1000,
+	}, nil)
+
+	act := expectOne(t, a.OnQuote(quote(0, 100, 101.5, 0)), ActionPlace)
+	if act.Price != 101.5 || act.Aggressive {
+		t.Fatalf("passive child = %+v", act)
+	}
+	expectOne(t, a.OnTimer(t0.Add(time.Second)), ActionCancel)
+	a.OnChildDone(act.ChildID, false)
+
+	// 追单价格（买一 100）低于限价：按限价挂单，不再跟随行情改价
+	act = expectOne(t, a.OnTimer(t0.Add(time.Second)), ActionPlace)
+	if act.Price != 101 || !act.Aggressive {
+		t.Fatalf("capped child = %+v", act)
+	}
+	if acts := a.OnQuote(quote(2, 99, 100, 0)); len(acts) != 0 {
+		t.Fatalf("capped child repriced: %+v", acts)
+	}
+	a.OnFill(act.ChildID, 4, 101)
+
+	expectOne(t, a.OnTimer(t0.Add(60*time.Second)), ActionCancel)
+	if p := a.Progress(); p.State != StateExpired || p.Filled != 4 || p.FinishedAt == nil {
+		t.Errorf("progress = %+v", p)
+	}
+	if acts := a.OnTimer(t0.Add(61 * time.Second)); len(acts) != 0 {
+		t.Errorf("actions after expiry: %+v", acts)
+	}
+}
+
+func TestISFrontLoadedAndValidation(t *testing.T) {
+	if f := isFraction(0.5, 2); math.Abs(f-(1-math.Sinh(1)/math.Sinh(2))) > 1e-12 || f <= 0.5 {
+		t.Errorf("isFraction(0.5, 2) = %v", f)
+	}
+	if isFraction(0.5, 0) != 0.5 || isFraction(1, 3) != 1 {
+		t.Error("isFraction endpoints")
+	}
+
+	is := mustNew(t, Spec{ID: "p5", Symbol: "ag2502", Side: Buy, Qty: 100, Type: TypeIS, End: t0.Add(100 * time.Second), SliceSec: 50, Urgency: 2}, nil)
+	twap := mustNew(t, Spec{ID: "p6", Symbol: "ag2502", Side: Buy, Qty: 100, Type: TypeTWAP, End: t0.Add(100 * time.Second), SliceSec: 50}, nil)
+	if is.targetQty(t0) != 68 || twap.targetQty(t0) != 50 {
+		t.Errorf("first slice target: is=%d twap=%d", is.targetQty(t0), twap.targetQty(t0))
+	}
+
+	for _, bad := range []Spec{
+		{ID: "x", Symbol: "ag2502", Side: Buy, Qty: 10, Type: TypeTWAP},
+		{ID: "x", Symbol: "ag2502", Side: Buy, Qty: 10, Type: TypePOV},
+		{ID: "x", Symbol: "ag2502", Side: "hold", Qty: 10, Type: TypePOV, Participation: 0.1},
+		{ID: "x", Symbol: "ag2502", Side: Buy, Qty: 10, Type: TypePOV, Participation: 0.1, MinParticipation: 0.3, MaxParticipation: 0.2},
+	} {
+		if _, err := New(bad, nil, t0); err == nil {
+			t.Errorf("spec %+v should be rejected", bad)
+		}
+	}
+}
+
+func TestRejectsFailParent(t *testing.T) {
+	a := mustNew(t, Spec{ID: "p7", Symbol: "ag2502", Side: Buy, Qty: 10, Type: TypePOV, Participation: 1}, nil)
+	a.OnQuote(quote(0, 100, 101, 100))
+	for i := 1; i <= maxRejects; i++ {
+		act := expectOne(t, a.OnQuote(quote(i, 100, 101, int64(100+i))), ActionPlace)
+		a.OnChildDone(act.ChildID, true)
+	}
+	if a.State() != StateFailed {
+		t.Errorf("state = %s, want failed", a.State())
+	}
+}
@@ -0,0 +1,181 @@
+package algo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// tradingDayOrigin 交易时钟起点：18:00 起算，夜盘（21:00-02:30）排在日盘之前，
+// 跨零点的执行窗口仍是单调的
+const tradingDayOrigin = 18 * 3600
+
+// DefaultBucketSec 成交量曲线默认桶宽（秒）
+const DefaultBucketSec = 60
+
+// CurveBucket 成交量曲线的一个时间桶
+type CurveBucket struct {
+	Offset int     `json:"offset"` // 交易时钟秒数（18:00 起算）
+	Share  float64 `json:"share"`  // 该桶成交量占全天的平均比例
+}
+
+// VolumeCurve 日内成交量分布（由历史行情学习）
+// Share 按交易日分别归一化后取平均，AvgDailyVolume 用于把实际成交量与历史比较
+type VolumeCurve struct {
+	Symbol         string        `json:"symbol"`
+	BucketSec      int           `json:"bucket_sec"`
+	Days           int           `json:"days"`
+	AvgDailyVolume float64       `json:"avg_daily_volume"`
+	Buckets        []CurveBucket `json:"buckets"`
+}
+
+// clockOffset 将时间映射到交易时钟秒数
+func clockOffset(t time.Time) int {
+	sec := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	return (sec - tradingDayOrigin + 86400) % 86400
+}
+
+// Share 返回 [from, to) 内的预期成交量占全天比例（桶内按时间线性插值）
+// 窗口须在同一交易日内
+func (c *VolumeCurve) Share(from, to time.Time) float64 {
+	if c == nil || len(c.Buckets) == 0 || !to.After(from) {
+		return 0
+	}
+	a := float64(clockOffset(from)) + float64(from.Nanosecond())/1e9
+	b := a + to.Sub(from).Seconds()
+	width := float64(c.BucketSec)
+	if width <= 0 {
+		width = DefaultBucketSec
+	}
+
+	var share float64
+	for _, bk := range c.Buckets {
+		lo, hi := float64(bk.Offset), float64(bk.Offset)+width
+		if hi <= a || lo >= b {
+			continue
+		}
+		overlap := minFloat(hi, b) - maxFloat(lo, a)
+		share += bk.Share * overlap / width
+	}
+	return share
+}
+
+// CurveBuilder 从逐笔成交量学习成交量曲线
+type CurveBuilder struct {
+	symbol    string
+	bucketSec int
+	days      map[string]map[int]float64 // 交易日 -> 桶 -> 成交量
+}
+
+// NewCurveBuilder 创建曲线学习器；bucketSec <= 0 时使用 DefaultBucketSec
+func NewCurveBuilder(symbol string, bucketSec int) *CurveBuilder {
+	if bucketSec <= 0 {
+		bucketSec = DefaultBucketSec
+	}
+	return &CurveBuilder{
+		symbol:    symbol,
+		bucketSec: bucketSec,
+		days:      make(map[string]map[int]float64),
+	}
+}
+
+// Add 累加一笔成交量（day 为交易日，夜盘归属次一交易日由调用方决定）
+func (b *CurveBuilder) Add(day string, ts time.Time, qty int64) {
+	if qty <= 0 {
+		return
+	}
+	buckets, ok := b.days[day]
+	if !ok {
+		buckets = make(map[int]float64)
+		b.days[day] = buckets
+	}
+	off := clockOffset(ts) / b.bucketSec * b.bucketSec
+	buckets[off] += float64(qty)
+}
+
+// Build 生成曲线：每个交易日单独归一化后等权平均
+func (b *CurveBuilder) Build() (*VolumeCurve, error) {
+	shares := make(map[int]float64)
+	var total float64
+	days := 0
+	for _, buckets := range b.days {
+		var dayVol float64
+		for _, v := range buckets {
+			dayVol += v
+		}
+		if dayVol <= 0 {
+			continue
+		}
+		days++
+		total += dayVol
+		for off, v := range buckets {
+			shares[off] += v / dayVol
+		}
+	}
+	if days == 0 {
+		return nil, fmt.Errorf("no volume for %s", b.symbol)
+	}
+
+	c := &VolumeCurve{
+		Symbol:         b.symbol,
+		BucketSec:      b.bucketSec,
+		Days:           days,
+		AvgDailyVolume: total / float64(days),
+		Buckets:        make([]CurveBucket, 0, len(shares)),
+	}
+	for off, s := range shares {
+		c.Buckets = append(c.Buckets, CurveBucket{Offset: off, Share: s / float64(days)})
+	}
+	sort.Slice(c.Buckets, func(i, j int) bool { return c.Buckets[i].Offset < c.Buckets[j].Offset })
+	return c, nil
+}
+
+// CurvePath 曲线文件路径：<dir>/<symbol>.json
+func CurvePath(dir, symbol string) string {
+	return filepath.Join(dir, symbol+".json")
+}
+
+// SaveCurve 写出曲线 JSON
+func SaveCurve(path string, c *VolumeCurve) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadCurve 读取曲线 JSON
+func LoadCurve(path string) (*VolumeCurve, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c VolumeCurve
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse volume curve %s: %w", path, err)
+	}
+	if c.BucketSec <= 0 {
+		c.BucketSec = DefaultBucketSec
+	}
+	return &c, nil
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
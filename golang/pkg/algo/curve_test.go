@@ -0,0 +1,92 @@
+package algo
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func at(hhmm string) time.Time {
+	t, _ := time.ParseInLocation("15:04", hhmm, time.Local)
+	return time.Date(2025, 1, 2, t.Hour(), t.Minute(), 0, 0, time.Local)
+}
+
+func TestCurveBuilder(t *testing.T) {
+	b := NewCurveBuilder("ag2502", 600)
+	// 两个交易日：09:00 桶占 3/4 和 1/4，09:10 桶占 1/4 和 3/4
+	b.Add("20250102", at("09:01"), 300)
+	b.Add("20250102", at("09:12"), 100)
+	b.Add("20250103", at("09:05"), 100)
+	b.Add("20250103", at("09:15"), 300)
+	b.Add("20250103", at("09:16"), 0)
+	c, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Days != 2 || c.AvgDailyVolume != 400 || len(c.Buckets) != 2 {
+		t.Fatalf("curve = %+v", c)
+	}
+	if got := c.Share(at("09:00"), at("09:20")); math.Abs(got-1) > 1e-9 {
+		t.Errorf("full share = %v", got)
+	}
+	// 桶内线性插值：09:05-09:15 = 半个 09:00 桶 + 半个 09:10 桶
+	if got := c.Share(at("09:05"), at("09:15")); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("half share = %v", got)
+	}
+
+	// 夜盘跨零点：交易时钟单调
+	night := NewCurveBuilder("ag2502", 3600)
+	night.Add("20250103", time.Date(2025, 1, 2, 23, 30, 0, 0, time.Local), 100)
+	night.Add("20250103", time.Date(2025, 1, 3, 0, 30, 0, 0, time.Local), 100)
+	nc, _ := night.Build()
+	if got := nc.Share(time.Date(2025, 1, 2, 23, 0, 0, 0, time.Local), time.Date(2025, 1, 3, 1, 0, 0, 0, time.Local)); math.Abs(got-1) > 1e-9 {
+		t.Errorf("night share = %v", got)
+	}
+
+	path := CurvePath(t.TempDir(), "ag2502")
+	if err := SaveCurve(path, c); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCurve(path)
+	if err != nil || loaded.Days != 2 || len(loaded.Buckets) != 2 || filepath.Base(path) != "ag2502.json" {
+		t.Fatalf("load = %+v, %v", loaded, err)
+	}
+
+	if _, err := NewCurveBuilder("x", 0).Build(); err == nil {
+		t.Error("empty builder should fail")
+	}
+}
+
+func TestVWAPAdaptsToLiveVolume(t *testing.T) {
+	// 09:00-09:20：前 10 分钟占 80% 成交量，日均 1000 手
+	curve := &VolumeCurve{
+		Symbol: "ag2502", BucketSec: 600, Days: 1, AvgDailyVolume: 1000,
+		Buckets: []CurveBucket{
+			{Offset: clockOffset(at("09:00")), Share: 0.8},
+			{Offset: clockOffset(at("09:10")), Share: 0.2},
+		},
+	}
+	spec := Spec{ID: "v1", Symbol: "ag2502", Side: Buy, Qty: 100, Type: TypeVWAP, Start: at("09:00"), End: at("09:20"), SliceSec: 600}
+
+	planned := mustNew(t, spec, curve)
+	if got := planned.targetQty(at("09:00")); got != 80 {
+		t.Errorf("curve target = %d, want 80", got)
+	}
+	if p := planned.Progress(); p.Curve != "learned" {
+		t.Errorf("curve source = %q", p.Curve)
+	}
+
+	// 09:10 时市场只成交 200 手（历史 800）：剩余时段按历史 200 手估计，进度 50% 而非 80%
+	live := mustNew(t, spec, curve)
+	live.OnQuote(Quote{Time: at("09:00"), Bid: 100, Ask: 101, TotalVolume: 1000})
+	live.OnQuote(Quote{Time: at("09:10").Add(-time.Second), Bid: 100, Ask: 101, TotalVolume: 1200})
+	if got := live.dueQty(at("09:10")); got != 50 {
+		t.Errorf("adaptive due = %d, want 50", got)
+	}
+
+	linear := mustNew(t, spec, nil)
+	if got := linear.targetQty(at("09:00")); got != 50 || linear.Progress().Curve != "linear" {
+		t.Errorf("linear fallback target = %d", got)
+	}
+}
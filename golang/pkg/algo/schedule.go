@@ -0,0 +1,103 @@
+package algo
+
+import (
+	"math"
+	"time"
+)
+
+// scheduled 按计划到 t 时应完成的累计数量（now 为当前时间，t >= now，未取整）
+//
+//   - TWAP: 按时间线性
+//   - VWAP: 按历史成交量曲线；有实际成交量时用"已成交 + 预期剩余"重新估计进度，
+//     市场比历史活跃时加快、清淡时放慢
+//   - IS:   Almgren-Chriss 剩余比例 sinh(κ(1-τ))/sinh(κ)，κ = Urgency
+//   - POV:  参与率 × 开始以来市场成交量
+//
+// 最低/最高参与率对所有算法生效
+func (a *Algo) scheduled(now, t time.Time) float64 {
+	qty := float64(a.spec.Qty)
+	var target float64
+	switch a.spec.Type {
+	case TypePOV:
+		target = a.spec.Participation * float64(a.mktVol)
+	case TypeVWAP:
+		target = qty * a.vwapFraction(now, t)
+	case TypeIS:
+		target = qty * isFraction(a.timeFraction(t), a.spec.Urgency)
+	default:
+		target = qty * a.timeFraction(t)
+	}
+
+	if a.volSeen {
+		if a.spec.MinParticipation > 0 {
+			target = math.Max(target, a.spec.MinParticipation*float64(a.mktVol))
+		}
+		if a.spec.MaxParticipation > 0 {
+			target = math.Min(target, a.spec.MaxParticipation*float64(a.mktVol))
+		}
+	}
+	return math.Min(qty, math.Max(0, target))
+}
+
+// targetQty 下单目标：取当前时间片末尾的计划量（向上取整），子单在时间片开始时即可挂出
+func (a *Algo) targetQty(now time.Time) int64 {
+	return int64(math.Ceil(a.scheduled(now, a.sliceEnd(now)) - 1e-9))
+}
+
+// dueQty 按当前时间应完成的数量（向下取整），落后程度以此衡量
+func (a *Algo) dueQty(now time.Time) int64 {
+	return int64(math.Floor(a.scheduled(now, now) + 1e-9))
+}
+
+// sliceEnd 当前时间片的结束时间（不超过结束时间）
+func (a *Algo) sliceEnd(now time.Time) time.Time {
+	elapsed := now.Sub(a.spec.Start)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	t := a.spec.Start.Add((elapsed/a.slice + 1) * a.slice)
+	if !a.spec.End.IsZero() && t.After(a.spec.End) {
+		t = a.spec.End
+	}
+	return t
+}
+
+// timeFraction 时间进度 [0, 1]
+func (a *Algo) timeFraction(t time.Time) float64 {
+	total := a.spec.End.Sub(a.spec.Start)
+	if total <= 0 {
+		return 1
+	}
+	f := float64(t.Sub(a.spec.Start)) / float64(total)
+	return math.Min(1, math.Max(0, f))
+}
+
+// isFraction IS 前置轨迹的累计完成比例
+func isFraction(tau, kappa float64) float64 {
+	if kappa <= 1e-6 {
+		return tau
+	}
+	return 1 - math.Sinh(kappa*(1-tau))/math.Sinh(kappa)
+}
+
+func (a *Algo) curveUsable() bool {
+	return a.curve != nil && a.curve.Share(a.spec.Start, a.spec.End) > 0
+}
+
+// vwapFraction VWAP 到 end 时的累计完成比例
+func (a *Algo) vwapFraction(now, end time.Time) float64 {
+	if !a.curveUsable() {
+		return a.timeFraction(end)
+	}
+	window := a.curve.Share(a.spec.Start, a.spec.End)
+
+	if a.volSeen && a.mktVol > 0 && a.curve.AvgDailyVolume > 0 {
+		adv := a.curve.AvgDailyVolume
+		done := float64(a.mktVol) + adv*a.curve.Share(now, end)
+		left := adv * a.curve.Share(end, a.spec.End)
+		if done+left > 0 {
+			return math.Min(1, done/(done+left))
+		}
+	}
+	return math.Min(1, a.curve.Share(a.spec.Start, end)/window)
+}
@@ -0,0 +1,71 @@
+package backtest
+
+import (
+	"fmt"
+	"strconv"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+
+	"tbsrc-golang/pkg/attribution"
+)
+
+// attributionLegs 归因的两腿品种（策略的前两个品种）
+type attributionLegs struct {
+	leg1, leg2 string
+}
+
+// newAttributionLegs 策略品种少于两个时不做归因
+func newAttributionLegs(cfg *BacktestConfig) *attributionLegs {
+	if len(cfg.Strategy.Symbols) < 2 {
+		return nil
+	}
+	return &attributionLegs{leg1: cfg.Strategy.Symbols[0], leg2: cfg.Strategy.Symbols[1]}
+}
+
+// recordAttribution 把回测成交送入归因引擎（Go 扩展）
+// 回测撮合不区分订单类型：以成交价是否优于 mid 判断被动成交；
+// 公允价差和开仓档位取自订单携带的信号元数据（策略发出信号时的价差均值和 z-score 阈值），
+// 没有元数据的订单公允价差按成交时价差计（开仓优势为 0）
+func (s *BacktestStatistics) recordAttribution(fill *Fill, side orspb.OrderSide, symbol string, commission float64) {
+	if s.attribution == nil || s.legs == nil {
+		return
+	}
+	leg := 0
+	switch symbol {
+	case s.legs.leg1:
+		leg = 1
+	case s.legs.leg2:
+		leg = 2
+	default:
+		return
+	}
+	buy := side == orspb.OrderSide_BUY
+	mid, ok := s.lastPrices[symbol]
+	if !ok {
+		mid = fill.Price
+	}
+	f := attribution.Fill{
+		Time:       fill.Timestamp,
+		Strategy:   s.config.Strategy.Type,
+		Leg:        leg,
+		Buy:        buy,
+		Qty:        fill.Volume,
+		Price:      fill.Price,
+		Mid:        mid,
+		Multiplier: strategy.GetContractMultiplier(symbol),
+		Fee:        commission,
+		Passive:    (buy && fill.Price < mid) || (!buy && fill.Price > mid),
+	}
+	if leg == 1 {
+		f.Spread = s.lastPrices[s.legs.leg1] - s.lastPrices[s.legs.leg2]
+		f.FairSpread = f.Spread
+		if v, err := strconv.ParseFloat(fill.Metadata[strategy.MetaFairSpread], 64); err == nil {
+			f.FairSpread = v
+		}
+		if v, err := strconv.ParseFloat(fill.Metadata[strategy.MetaEntryThreshold], 64); err == nil {
+			f.Level = fmt.Sprintf("%.2f", v)
+		}
+	}
+	s.attribution.OnFill(f)
+}
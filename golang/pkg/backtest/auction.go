@@ -0,0 +1,55 @@
+package backtest
+
+import (
+	"time"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	"tbsrc-golang/pkg/calendar"
+)
+
+// 集合竞价模拟（Go 扩展）
+//
+// data.auction 开启时，DataReader 按内置时段模板把集合竞价时段（开盘前 5 分钟）内的行情标记为
+// feed_type=AUCTION，策略引擎把它们路由到 OnAuctionData。撮合引擎在竞价期间不做连续撮合，
+// 只记录指示性均衡价；收到该品种第一笔连续交易行情时，把竞价期间的挂单按竞价价格统一撮合。
+
+// markAuctionTicks 标记集合竞价时段内的行情（未加载节假日，周一至周五均视为交易日）
+func markAuctionTicks(ticks []*MarketDataTick, symbol string) {
+	name, ok := calendar.DefaultProductTemplates[calendar.ProductOf(symbol)]
+	if !ok {
+		name = "day"
+	}
+	tmpl := calendar.DefaultTemplates[name]
+	cal := calendar.New(nil)
+	for _, tick := range ticks {
+		t := time.Unix(0, tick.TimestampNs)
+		tick.Auction = cal.Phase(tmpl, t, 0) == calendar.PhaseAuction
+	}
+}
+
+// uncrossAuction 按竞价价格统一撮合：买价 >= 竞价价格、卖价 <= 竞价价格的挂单全部以竞价价格成交
+// 回测按价格接受者处理，不考虑本方订单对均衡价的影响，也不加滑点；at 为开盘行情时间
+func (r *BacktestOrderRouter) uncrossAuction(symbol string, price float64, at time.Time) {
+	if price <= 0 {
+		return
+	}
+	r.matchEngine.mu.Lock()
+	defer r.matchEngine.mu.Unlock()
+
+	for orderID, order := range r.matchEngine.openOrders {
+		if order.Symbol != symbol {
+			continue
+		}
+		if (order.Side == orspb.OrderSide_BUY && order.Price >= price) ||
+			(order.Side == orspb.OrderSide_SELL && order.Price <= price) {
+			r.fillOpenOrderUnsafe(orderID, order, &Fill{
+				OrderID:   order.OrderID,
+				Price:     price,
+				Volume:    order.Volume,
+				Timestamp: at.Add(r.matchEngine.fillDelay),
+				Metadata:  order.Metadata,
+			})
+		}
+	}
+}
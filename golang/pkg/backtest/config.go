@@ -36,6 +36,7 @@ type DataSettings struct {
 	SourceType string   `yaml:"source_type"` // csv, parquet, database
 	DataPath   string   `yaml:"data_path"`
 	Symbols    []string `yaml:"symbols"`
+	Auction    bool     `yaml:"auction"` // 按交易日历把集合竞价时段内的行情标记为竞价行情（Go 扩展）
 }
 
 // ReplaySettings contains replay behavior settings
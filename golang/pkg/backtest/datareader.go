@@ -84,6 +84,9 @@ func (r *HistoricalDataReader) LoadData() error {
 				continue
 			}
 
+			if r.config.Backtest.Data.Auction {
+				markAuctionTicks(ticks, symbol)
+			}
 			r.ticks = append(r.ticks, ticks...)
 			log.Printf("[DataReader] Loaded %d ticks from %s", len(ticks), filePath)
 		}
@@ -10,7 +10,10 @@ import (
 
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
 	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
 	"google.golang.org/grpc"
+
+	"tbsrc-golang/pkg/tca"
 )
 
 // BacktestOrderRouter handles order routing and matching in backtest mode
@@ -27,6 +30,9 @@ type BacktestOrderRouter struct {
 
 	// Order callback
 	onOrderUpdate func(*orspb.OrderUpdate)
+
+	// 成交质量分析（Go 扩展，见 tca.go）
+	tca *tca.Engine
 }
 
 // SimpleMatchEngine provides simple order matching logic
@@ -37,6 +43,9 @@ type SimpleMatchEngine struct {
 	slippageBps       float64
 	commissionRate    float64
 	mu                sync.RWMutex
+
+	// 集合竞价（Go 扩展）：竞价行情期间不撮合，收到第一笔连续交易行情时按竞价价格统一撮合
+	auctionPrice map[string]float64 // symbol -> 最近一次指示性均衡价（竞价中的品种才有）
 }
 
 // NewBacktestOrderRouter creates a new order router
@@ -52,6 +61,7 @@ func NewBacktestOrderRouter(config *BacktestConfig, port int) (*BacktestOrderRou
 	router.matchEngine = &SimpleMatchEngine{
 		currentMarketData: make(map[string]*mdpb.MarketDataUpdate),
 		openOrders:        make(map[string]*Order),
+		auctionPrice:      make(map[string]float64),
 		fillDelay:         config.GetFillDelay(),
 		slippageBps:       config.GetSlippage(),
 		commissionRate:    config.GetCommissionRate(),
@@ -110,10 +120,39 @@ func (r *BacktestOrderRouter) Stop() error {
 }
 
 // UpdateMarketData updates the current market data for matching
+// 集合竞价行情只记录指示性均衡价；竞价结束后的第一笔行情先按竞价价格撮合竞价期间的挂单
 func (r *BacktestOrderRouter) UpdateMarketData(md *mdpb.MarketDataUpdate) {
-	r.matchEngine.mu.Lock()
-	r.matchEngine.currentMarketData[md.Symbol] = md
-	r.matchEngine.mu.Unlock()
+	e := r.matchEngine
+	if md.FeedType == mdpb.FeedType_AUCTION {
+		price := md.LastPrice
+		if eq := strategy.IndicativeAuction(md); eq.Valid() {
+			price = eq.Price
+		}
+		e.mu.Lock()
+		e.currentMarketData[md.Symbol] = md
+		if price > 0 || e.auctionPrice[md.Symbol] == 0 {
+			e.auctionPrice[md.Symbol] = price
+		}
+		e.mu.Unlock()
+		return
+	}
+
+	e.mu.Lock()
+	e.currentMarketData[md.Symbol] = md
+	price, uncross := e.auctionPrice[md.Symbol]
+	delete(e.auctionPrice, md.Symbol)
+	e.mu.Unlock()
+	r.tcaQuote(md)
+
+	if uncross {
+		if price <= 0 {
+			price = md.OpenPrice
+		}
+		if price <= 0 {
+			price = md.LastPrice
+		}
+		r.uncrossAuction(md.Symbol, price, mdTime(md))
+	}
 
 	// Try to match open orders
 	r.tryMatchOpenOrders(md.Symbol)
@@ -132,18 +171,22 @@ func (r *BacktestOrderRouter) SubmitOrder(req *orspb.OrderRequest) error {
 
 	// Create order
 	order := &Order{
-		OrderID:   orderID,
-		Symbol:    req.Symbol,
-		Side:      req.Side,
-		Price:     req.Price,
-		Volume:    int32(req.Quantity),
-		Filled:    0,
-		Status:    orspb.OrderStatus_PENDING,
-		Timestamp: time.Now(),
+		OrderID:       orderID,
+		ClientOrderID: req.ClientOrderId,
+		StrategyID:    req.StrategyId,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Price:         req.Price,
+		Volume:        int32(req.Quantity),
+		Filled:        0,
+		Status:        orspb.OrderStatus_PENDING,
+		Timestamp:     time.Now(),
+		Metadata:      req.Metadata,
 	}
 
 	// Add to history
 	r.orderHistory = append(r.orderHistory, order)
+	r.tcaOrder(order, req)
 
 	// Send order acknowledgment
 	r.sendOrderUpdate(&orspb.OrderUpdate{
@@ -166,6 +209,9 @@ func (r *BacktestOrderRouter) SubmitOrder(req *orspb.OrderRequest) error {
 
 		// Add to fill history
 		r.fillHistory = append(r.fillHistory, fill)
+		r.matchEngine.mu.RLock()
+		r.tcaFill(order, fill, r.matchEngine.currentMarketData[order.Symbol])
+		r.matchEngine.mu.RUnlock()
 
 		// Send fill update
 		r.sendOrderUpdate(&orspb.OrderUpdate{
@@ -214,41 +260,49 @@ func (r *BacktestOrderRouter) tryMatchOpenOrders(symbol string) {
 
 		fill := r.matchEngine.TryMatchUnsafe(order)
 		if fill != nil {
-			// Order filled
-			order.Filled = fill.Volume
-			order.Status = orspb.OrderStatus_FILLED
-
-			// Add to fill history
-			r.mu.Lock()
-			r.fillHistory = append(r.fillHistory, fill)
-			r.mu.Unlock()
-
-			// Send fill update
-			r.sendOrderUpdate(&orspb.OrderUpdate{
-				OrderId:       orderID,
-				Symbol:        order.Symbol,
-				Side:          order.Side,
-				Status:        orspb.OrderStatus_FILLED,
-				Price:         order.Price,
-				Quantity:      int64(order.Volume),
-				FilledQty:     int64(fill.Volume),
-				RemainingQty:  0,
-				AvgPrice:      fill.Price,
-				LastFillPrice: fill.Price,
-				LastFillQty:   int64(fill.Volume),
-				Timestamp:     uint64(fill.Timestamp.UnixNano()),
-				ErrorCode:     orspb.ErrorCode_SUCCESS,
-			})
-
-			// Remove from open orders
-			delete(r.matchEngine.openOrders, orderID)
-
-			log.Printf("[OrderRouter] Order filled: %s %s %d@%.2f",
-				orderID, order.Symbol, fill.Volume, fill.Price)
+			r.fillOpenOrderUnsafe(orderID, order, fill)
 		}
 	}
 }
 
+// fillOpenOrderUnsafe fills an open order completely (caller must hold matchEngine.mu)
+func (r *BacktestOrderRouter) fillOpenOrderUnsafe(orderID string, order *Order, fill *Fill) {
+	// Order filled
+	order.Filled = fill.Volume
+	order.Status = orspb.OrderStatus_FILLED
+
+	// Add to fill history
+	r.mu.Lock()
+	r.fillHistory = append(r.fillHistory, fill)
+	r.mu.Unlock()
+	r.tcaFill(order, fill, r.matchEngine.currentMarketData[order.Symbol])
+
+	// Send fill update
+	r.sendOrderUpdate(&orspb.OrderUpdate{
+		OrderId:       orderID,
+		ClientOrderId: order.ClientOrderID,
+		StrategyId:    order.StrategyID,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		Status:        orspb.OrderStatus_FILLED,
+		Price:         order.Price,
+		Quantity:      int64(order.Volume),
+		FilledQty:     int64(fill.Volume),
+		RemainingQty:  0,
+		AvgPrice:      fill.Price,
+		LastFillPrice: fill.Price,
+		LastFillQty:   int64(fill.Volume),
+		Timestamp:     uint64(fill.Timestamp.UnixNano()),
+		ErrorCode:     orspb.ErrorCode_SUCCESS,
+	})
+
+	// Remove from open orders
+	delete(r.matchEngine.openOrders, orderID)
+
+	log.Printf("[OrderRouter] Order filled: %s %s %d@%.2f",
+		orderID, order.Symbol, fill.Volume, fill.Price)
+}
+
 // sendOrderUpdate sends order update to callback
 func (r *BacktestOrderRouter) sendOrderUpdate(update *orspb.OrderUpdate) {
 	if r.onOrderUpdate != nil {
@@ -270,6 +324,11 @@ func (e *SimpleMatchEngine) TryMatchUnsafe(order *Order) *Fill {
 		return nil
 	}
 
+	// 集合竞价期间不连续撮合，等待开盘统一撮合（uncrossAuction）
+	if _, auction := e.auctionPrice[order.Symbol]; auction {
+		return nil
+	}
+
 	// Check if we have valid market data
 	if len(md.BidPrice) == 0 || len(md.AskPrice) == 0 {
 		return nil
@@ -329,7 +388,8 @@ func (e *SimpleMatchEngine) TryMatchUnsafe(order *Order) *Fill {
 		OrderID:   order.OrderID,
 		Price:     fillPrice,
 		Volume:    order.Volume,
-		Timestamp: time.Now().Add(e.fillDelay),
+		Timestamp: mdTime(md).Add(e.fillDelay),
+		Metadata:  order.Metadata,
 	}
 
 	return fill
@@ -376,15 +436,23 @@ func (r *BacktestOrderRouter) CancelOrder(orderID string) error {
 
 	// Update status
 	order.Status = orspb.OrderStatus_CANCELED
+	if r.tca != nil {
+		r.tca.OnOrderDone(orderID)
+	}
 
 	// Send cancel update
 	r.sendOrderUpdate(&orspb.OrderUpdate{
-		OrderId:   orderID,
-		Symbol:    order.Symbol,
-		Side:      order.Side,
-		Status:    orspb.OrderStatus_CANCELED,
-		Timestamp: uint64(time.Now().UnixNano()),
-		ErrorCode: orspb.ErrorCode_SUCCESS,
+		OrderId:       orderID,
+		ClientOrderId: order.ClientOrderID,
+		StrategyId:    order.StrategyID,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		Status:        orspb.OrderStatus_CANCELED,
+		Price:         order.Price,
+		Quantity:      int64(order.Volume),
+		RemainingQty:  int64(order.Volume - order.Filled),
+		Timestamp:     uint64(time.Now().UnixNano()),
+		ErrorCode:     orspb.ErrorCode_SUCCESS,
 	})
 
 	log.Printf("[OrderRouter] Order cancelled: %s", orderID)
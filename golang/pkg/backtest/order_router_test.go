@@ -0,0 +1,150 @@
+package backtest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// 做市策略经 BacktestOrderRouter 回放：报单/撤单/成交回报全部走撮合器，
+// 检查成交双边发生、策略持仓与撮合器成交一致、持仓不超上限，以及保留价和报单量随库存偏斜
+func TestBacktestOrderRouter_MarketMakingReplay(t *testing.T) {
+	cfg := &BacktestConfig{}
+	cfg.Backtest.OrderSim = OrderSimSettings{FillDelayMs: 1}
+	router, err := NewBacktestOrderRouter(cfg, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := strategy.NewStrategy(strategy.MarketMakingStrategyType, "mm_bt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm := s.(*strategy.MarketMakingStrategy)
+	err = mm.Initialize(&strategy.StrategyConfig{
+		StrategyID: "mm_bt",
+		Symbols:    []string{"ag2603"},
+		Exchanges:  []string{"SHFE"},
+		Parameters: map[string]interface{}{
+			"order_size":           2.0,
+			"max_inventory":        6.0,
+			"tick_size":            1.0,
+			"order_refresh_ms":     0.0,
+			"toxic_flow_threshold": 1.0, // 不按订单流加宽/撤价，只看库存偏斜
+			"pull_flow_threshold":  0.0,
+			"adverse_widen":        0.0,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	router.SetOrderUpdateCallback(mm.OnOrderUpdate)
+
+	var (
+		seq        int
+		buys, sels int64
+		skewed     int
+		reduced    int
+	)
+	rng := rand.New(rand.NewSource(7))
+	ts := time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local)
+	bid := 5000.0
+	for i := 0; i < 3000; i++ {
+		bid += float64(rng.Intn(5) - 2) // 每笔行情 -2..+2 tick，跳两档时吃掉未及撤的挂单
+		ts = ts.Add(500 * time.Millisecond)
+		md := &mdpb.MarketDataUpdate{
+			Symbol: "ag2603", Timestamp: uint64(ts.UnixNano()), LastPrice: bid,
+			BidPrice: []float64{bid}, AskPrice: []float64{bid + 1}, BidQty: []uint32{20}, AskQty: []uint32{20},
+		}
+
+		// 与 runner 相同：先撮合在途挂单，再交给策略
+		router.UpdateMarketData(md)
+		mm.OnMarketData(md)
+
+		pos := mm.GetEstimatedPosition().NetQty
+		if pos > 6 || pos < -6 {
+			t.Fatalf("tick %d: position %d exceeds max_inventory 6", i, pos)
+		}
+		if ind := mm.GetControlState().Indicators; ind != nil && ind["mm_volatility"] > 0 {
+			mid := bid + 0.5
+			r := ind["mm_reservation"]
+			q := int64(ind["mm_position"])
+			switch {
+			case q > 0 && r >= mid, q < 0 && r <= mid, q == 0 && r != mid:
+				t.Fatalf("tick %d: reservation %.4f not skewed against position %d (mid %.1f)", i, r, q, mid)
+			case q != 0:
+				skewed++
+			}
+		}
+
+		for _, c := range mm.GetPendingCancels() {
+			router.CancelOrder(c.OrderId)
+		}
+		for _, sig := range mm.GetSignals() {
+			q := sig.Metadata["position"].(int64)
+			if (sig.Side == strategy.OrderSideBuy && q > 0) || (sig.Side == strategy.OrderSideSell && q < 0) {
+				if sig.Quantity > 2 || abs64(q)+sig.Quantity > 6 {
+					t.Fatalf("tick %d: adding quote %d at position %d", i, sig.Quantity, q)
+				}
+				if sig.Quantity < 2 {
+					reduced++
+				}
+			}
+			seq++
+			req := sig.ToOrderRequest()
+			req.ClientOrderId = fmt.Sprintf("MM_%d", seq)
+			if err := router.SubmitOrder(req); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, o := range router.GetOrderHistory() {
+		if o.Status != orspb.OrderStatus_FILLED {
+			continue
+		}
+		if o.Side == orspb.OrderSide_BUY {
+			buys += int64(o.Filled)
+		} else {
+			sels += int64(o.Filled)
+		}
+	}
+	if buys == 0 || sels == 0 {
+		t.Fatalf("fills buy=%d sell=%d, want both sides", buys, sels)
+	}
+	if len(router.GetFillHistory()) == 0 {
+		t.Fatal("no fills recorded")
+	}
+	pos := mm.GetEstimatedPosition()
+	if pos.NetQty != buys-sels || pos.BuyTotalQty != buys || pos.SellTotalQty != sels {
+		t.Errorf("strategy position %+v, router fills buy=%d sell=%d", pos, buys, sels)
+	}
+	if skewed == 0 || reduced == 0 {
+		t.Errorf("inventory skew not exercised: skewed ticks=%d reduced quotes=%d", skewed, reduced)
+	}
+
+	// 停止后策略撤销全部报价，撮合器不再有挂单
+	mm.Stop()
+	for _, c := range mm.GetPendingCancels() {
+		router.CancelOrder(c.OrderId)
+	}
+	if open := router.GetOpenOrders(); len(open) != 0 {
+		t.Errorf("open orders after stop = %d", len(open))
+	}
+	t.Logf("fills buy=%d sell=%d position=%d skewed ticks=%d reduced quotes=%d", buys, sels, pos.NetQty, skewed, reduced)
+}
+
+func abs64(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
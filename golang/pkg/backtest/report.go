@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"tbsrc-golang/pkg/attribution"
 )
 
 // ReportGenerator generates backtest reports in various formats
@@ -110,6 +112,43 @@ func (g *ReportGenerator) writeMarkdownReport(file *os.File) {
 		}
 	}
 
+	// P&L Attribution
+	if a := g.result.Attribution; a != nil && a.Total.RoundTrips > 0 {
+		fmt.Fprintf(file, "## P&L 归因\n\n")
+		fmt.Fprintf(file, "按 round trip（leg1 开仓 + 对冲 + leg1 平仓 + 对冲）分解：PNL = 价差捕获 + 对冲滑点 + 持仓漂移 − 手续费；开仓优势不计入 PNL。\n\n")
+		writeAttributionTable(file, "汇总", []attribution.Bucket{a.Total})
+		writeAttributionTable(file, "开仓阈值", a.ByLevel)
+		writeAttributionTable(file, "开仓小时", a.ByHour)
+		if a.OpenQty > 0 || a.UnpairedQty > 0 {
+			fmt.Fprintf(file, "*未完成配对 %d 手，未匹配对冲 %d 手*\n\n", a.OpenQty, a.UnpairedQty)
+		}
+	}
+
+	// Transaction Cost Analysis
+	if t := g.result.TCA; t != nil && len(t.Total) > 0 {
+		fmt.Fprintf(file, "## 成交质量（TCA）\n\n")
+		fmt.Fprintf(file, "数量加权平均，单位为每手价格；执行差额 / 有效价差 / 实现价差正数为成本，markout 正数为有利。\n\n")
+		fmt.Fprintf(file, "| 流动性 | 成交笔数 | 手数 | 成交耗时(ms) | 执行差额 | 执行差额(bps) | 有效价差 | 实现价差 |")
+		for _, h := range t.Horizons {
+			fmt.Fprintf(file, " markout %s |", h)
+		}
+		fmt.Fprintf(file, "\n|--------|---------|------|-------------|---------|--------------|---------|---------|")
+		for range t.Horizons {
+			fmt.Fprintf(file, "------|")
+		}
+		fmt.Fprintf(file, "\n")
+		for _, s := range t.Total {
+			fmt.Fprintf(file, "| %s | %d | %d | %.1f | %.4f | %.2f | %.4f | %.4f |",
+				s.Key, s.Fills, s.Qty, s.AvgTimeToFillMs, s.AvgShortfall, s.AvgShortfallBps,
+				s.AvgEffectiveSpread, s.AvgRealizedSpread)
+			for _, h := range t.Horizons {
+				fmt.Fprintf(file, " %.4f |", s.Markouts[h])
+			}
+			fmt.Fprintf(file, "\n")
+		}
+		fmt.Fprintf(file, "\n*按品种、策略、订单类型的明细见 JSON 结果和 tca_summary CSV*\n\n")
+	}
+
 	// Risk Analysis
 	fmt.Fprintf(file, "## 风险分析\n\n")
 	fmt.Fprintf(file, "- **Sharpe Ratio**: %.2f %s\n", g.result.SharpeRatio, evaluateSharpe(g.result.SharpeRatio))
@@ -135,6 +174,17 @@ func (g *ReportGenerator) writeMarkdownReport(file *os.File) {
 	fmt.Fprintf(file, "**回测耗时**: %v\n", g.result.Duration)
 }
 
+// writeAttributionTable writes one attribution breakdown table
+func writeAttributionTable(file *os.File, title string, buckets []attribution.Bucket) {
+	fmt.Fprintf(file, "| %s | 笔数 | 手数 | 开仓优势 | 价差捕获 | 对冲滑点 | 持仓漂移 | 手续费 | PNL |\n", title)
+	fmt.Fprintf(file, "|------|------|------|---------|---------|---------|---------|-------|-----|\n")
+	for _, b := range buckets {
+		fmt.Fprintf(file, "| %s | %d | %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+			b.Key, b.RoundTrips, b.Qty, b.EntryEdge, b.SpreadCapture, b.HedgeSlippage, b.Drift, b.Fees, b.PnL)
+	}
+	fmt.Fprintf(file, "\n")
+}
+
 // GenerateJSON generates a JSON report
 func (g *ReportGenerator) GenerateJSON() error {
 	// Ensure output directory exists
@@ -12,6 +12,8 @@ import (
 	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
 	"github.com/yourusername/quantlink-trade-system/pkg/trader"
 	"google.golang.org/protobuf/proto"
+
+	"tbsrc-golang/pkg/tca"
 )
 
 // BacktestRunner coordinates all components and runs the backtest
@@ -23,6 +25,7 @@ type BacktestRunner struct {
 	trader      TraderInterface // 策略引擎接口
 	natsConn    *nats.Conn
 	mdSub       *nats.Subscription
+	tca         *tca.Engine // 成交质量分析（Go 扩展）
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -103,6 +106,7 @@ func (r *BacktestRunner) Run() (*BacktestResult, error) {
 	// 8. Generate statistics
 	log.Println("[Backtest] [8/8] Generating statistics...")
 	result := r.statistics.GenerateReport()
+	result.TCA = r.tca.Report()
 
 	// 9. Cleanup
 	r.cleanup()
@@ -153,6 +157,10 @@ func (r *BacktestRunner) initialize() error {
 	// Set order update callback
 	r.orderRouter.SetOrderUpdateCallback(r.onOrderUpdate)
 
+	// Transaction cost analysis (same engine as live tbsrc)
+	r.tca = tca.NewEngine(tca.Config{})
+	r.orderRouter.SetTCA(r.tca)
+
 	// Create Trader (strategy engine) if enabled
 	if r.config.Backtest.EnableTrader {
 		log.Println("[Backtest] Creating Trader (strategy engine)...")
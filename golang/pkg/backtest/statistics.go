@@ -8,6 +8,8 @@ import (
 	"time"
 
 	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	"tbsrc-golang/pkg/attribution"
 )
 
 // BacktestStatistics collects and calculates backtest statistics
@@ -21,6 +23,10 @@ type BacktestStatistics struct {
 	cashBalance float64
 	peakCash    float64
 	startTime   time.Time
+
+	// 逐笔 P&L 归因（Go 扩展，见 attribution.go）
+	attribution *attribution.Engine
+	legs        *attributionLegs
 }
 
 // NewBacktestStatistics creates a new statistics collector
@@ -34,6 +40,8 @@ func NewBacktestStatistics(config *BacktestConfig) *BacktestStatistics {
 		cashBalance: config.Backtest.Initial.Capital,
 		peakCash:    config.Backtest.Initial.Capital,
 		startTime:   time.Now(),
+		attribution: attribution.NewEngine(0),
+		legs:        newAttributionLegs(config),
 	}
 }
 
@@ -62,6 +70,9 @@ func (s *BacktestStatistics) OnTrade(fill *Fill, side orspb.OrderSide, symbol st
 	// Subtract commission
 	s.cashBalance -= commission
 
+	// P&L attribution (uses mids at fill time)
+	s.recordAttribution(fill, side, symbol, commission)
+
 	// Calculate PNL (for closed positions)
 	trade.PNL = s.calculateTradePNL(trade)
 
@@ -109,6 +120,7 @@ func (s *BacktestStatistics) GenerateReport() *BacktestResult {
 		InitialCash: s.config.Backtest.Initial.Capital,
 		FinalCash:   s.cashBalance,
 		Trades:      s.trades,
+		Attribution: s.attribution.Report(),
 	}
 
 	// Calculate unrealized PNL
@@ -0,0 +1,114 @@
+package backtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	"tbsrc-golang/pkg/tca"
+)
+
+// SetTCA 开启成交质量分析（Go 扩展），与实盘 tbsrc 使用同一个 TCA 引擎和口径
+// 时间取回放行情的时间戳，markout 按行情时间结算
+func (r *BacktestOrderRouter) SetTCA(e *tca.Engine) {
+	r.tca = e
+}
+
+// mdTime 回放行情时间（无时间戳时取本地时间）
+func mdTime(md *mdpb.MarketDataUpdate) time.Time {
+	if md != nil && md.Timestamp > 0 {
+		return time.Unix(0, int64(md.Timestamp))
+	}
+	return time.Now()
+}
+
+// mdMid 一档 mid，无双边报价时返回 0
+func mdMid(md *mdpb.MarketDataUpdate) float64 {
+	if md == nil || len(md.BidPrice) == 0 || len(md.AskPrice) == 0 || md.BidPrice[0] <= 0 || md.AskPrice[0] <= 0 {
+		return 0
+	}
+	return (md.BidPrice[0] + md.AskPrice[0]) / 2
+}
+
+// tcaOrder 记录下单时的到达价和盘口
+func (r *BacktestOrderRouter) tcaOrder(order *Order, req *orspb.OrderRequest) {
+	if r.tca == nil {
+		return
+	}
+	r.matchEngine.mu.RLock()
+	md := r.matchEngine.currentMarketData[order.Symbol]
+	r.matchEngine.mu.RUnlock()
+
+	o := tca.Order{
+		ID:        order.OrderID,
+		Strategy:  req.StrategyId,
+		Symbol:    order.Symbol,
+		OrderType: req.OrderType.String(),
+		Buy:       order.Side == orspb.OrderSide_BUY,
+		Qty:       order.Volume,
+		Price:     order.Price,
+		Time:      mdTime(md),
+	}
+	if md != nil && len(md.BidPrice) > 0 && len(md.AskPrice) > 0 {
+		o.Bid, o.Ask = md.BidPrice[0], md.AskPrice[0]
+	}
+	if md != nil && len(md.BidQty) > 0 && len(md.AskQty) > 0 {
+		o.BidQty, o.AskQty = float64(md.BidQty[0]), float64(md.AskQty[0])
+	}
+	r.tca.OnOrder(o)
+}
+
+// tcaFill 记录成交（回测一次全部成交，随后释放到达记录）
+// md 为触发成交的行情，调用方负责加锁
+func (r *BacktestOrderRouter) tcaFill(order *Order, fill *Fill, md *mdpb.MarketDataUpdate) {
+	if r.tca == nil {
+		return
+	}
+	r.tca.OnFill(order.OrderID, mdTime(md), fill.Volume, fill.Price, mdMid(md))
+	r.tca.OnOrderDone(order.OrderID)
+}
+
+// tcaQuote 行情更新，结算到期的 markout
+func (r *BacktestOrderRouter) tcaQuote(md *mdpb.MarketDataUpdate) {
+	if r.tca == nil {
+		return
+	}
+	r.tca.OnQuote(md.Symbol, mdTime(md), mdMid(md))
+}
+
+// GenerateTCA 写出 TCA 汇总和逐笔 CSV（JSON 已包含在 GenerateJSON 结果中）
+func (g *ReportGenerator) GenerateTCA() error {
+	if g.result.TCA == nil {
+		return nil
+	}
+	outputDir := g.config.Backtest.Output.ResultDir
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	timestamp := time.Now().Format("20060102_150405")
+	for _, out := range []struct {
+		name  string
+		write func(*os.File) error
+	}{
+		{"tca_summary", func(f *os.File) error { return tca.WriteSummaryCSV(f, g.result.TCA) }},
+		{"tca_fills", func(f *os.File) error { return tca.WriteFillsCSV(f, g.result.TCA) }},
+	} {
+		filename := filepath.Join(outputDir, fmt.Sprintf("%s_%s.csv", out.name, timestamp))
+		file, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("failed to create %s file: %w", out.name, err)
+		}
+		err = out.write(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s file: %w", out.name, err)
+		}
+		fmt.Printf("[Report] TCA saved: %s\n", filename)
+	}
+	return nil
+}
@@ -5,6 +5,9 @@ import (
 
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
 	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	"tbsrc-golang/pkg/attribution"
+	"tbsrc-golang/pkg/tca"
 )
 
 // ReplayMode defines the data replay mode
@@ -80,18 +83,27 @@ type BacktestResult struct {
 	MaxLoss         float64
 	AvgTradeSize    float64
 	TotalCommission float64
+
+	// P&L Attribution (round trips, Go 扩展)
+	Attribution *attribution.Report
+
+	// Transaction Cost Analysis (Go 扩展)
+	TCA *tca.Report
 }
 
 // Order represents an order in backtest
 type Order struct {
-	OrderID   string
-	Symbol    string
-	Side      orspb.OrderSide
-	Price     float64
-	Volume    int32
-	Filled    int32
-	Status    orspb.OrderStatus
-	Timestamp time.Time
+	OrderID       string
+	ClientOrderID string
+	StrategyID    string // 挂单成交/撤单回报按此分发给策略
+	Symbol        string
+	Side          orspb.OrderSide
+	Price         float64
+	Volume        int32
+	Filled        int32
+	Status        orspb.OrderStatus
+	Timestamp     time.Time
+	Metadata      map[string]string // 下单请求的信号元数据（归因用）
 }
 
 // Fill represents an order fill
@@ -99,7 +111,8 @@ type Fill struct {
 	OrderID   string
 	Price     float64
 	Volume    int32
-	Timestamp time.Time
+	Timestamp time.Time         // 回放行情时间 + fill_delay
+	Metadata  map[string]string // 所属订单的信号元数据
 }
 
 // MarketDataTick represents a single market data tick from CSV
@@ -129,6 +142,8 @@ type MarketDataTick struct {
 	BidVolume5  int32
 	AskPrice5   float64
 	AskVolume5  int32
+
+	Auction bool // 集合竞价时段内的行情（data.auction 开启时由 DataReader 标记）
 }
 
 // ToProtobuf converts MarketDataTick to protobuf MarketDataUpdate
@@ -144,6 +159,9 @@ func (tick *MarketDataTick) ToProtobuf() *mdpb.MarketDataUpdate {
 		AskPrice:  make([]float64, 0, 5),
 		AskQty:    make([]uint32, 0, 5),
 	}
+	if tick.Auction {
+		md.FeedType = mdpb.FeedType_AUCTION
+	}
 
 	// Add bid levels
 	if tick.BidVolume1 > 0 {
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -16,7 +17,15 @@ type TraderConfig struct {
 	Session   SessionConfig   `yaml:"session"`
 	Risk      RiskConfig      `yaml:"risk"`
 	Engine    EngineConfig    `yaml:"engine"`
+	Offset    OffsetConfig    `yaml:"offset"`
+	Reconcile ReconcileConfig `yaml:"reconcile"`
+	Journal   JournalConfig   `yaml:"journal"`
+	OrphanOrders OrphanOrdersConfig `yaml:"orphan_orders"`
+	Accounts  AccountsConfig  `yaml:"accounts"`
+	Params    ParamsConfig    `yaml:"params"`
 	Portfolio PortfolioConfig `yaml:"portfolio"`
+	Shadow    ShadowConfig    `yaml:"shadow"`
+	Algos     AlgosConfig     `yaml:"algos"`
 	API       APIConfig       `yaml:"api"`
 	Logging   LoggingConfig   `yaml:"logging"`
 }
@@ -34,6 +43,14 @@ type StrategyItemConfig struct {
 	Parameters      map[string]interface{} `yaml:"parameters"`        // 策略参数
 	ModelFile       string                 `yaml:"model_file"`        // 模型文件路径
 	HotReload       HotReloadConfig        `yaml:"hot_reload"`        // 热加载配置
+	Accounts        []StrategyAccountConfig `yaml:"accounts,omitempty"` // 资金账户分配（第一个为主账户，空 = 单账户）
+}
+
+// StrategyAccountConfig 策略在某资金账户上的下单比例
+// 主账户 scale 固定为 1；跟随账户数量为 round(策略数量 * scale)
+type StrategyAccountConfig struct {
+	Account string  `yaml:"account"`
+	Scale   float64 `yaml:"scale"`
 }
 
 // SystemConfig contains system-level configuration
@@ -46,7 +63,7 @@ type SystemConfig struct {
 
 // StrategyConfig contains strategy-specific configuration
 type StrategyConfig struct {
-	Type            string                 `yaml:"type"` // passive, aggressive, hedging, pairwise_arb
+	Type            string                 `yaml:"type"` // passive, aggressive, hedging, pairwise_arb, basket_arb
 	Symbols         []string               `yaml:"symbols"`
 	Exchanges       []string               `yaml:"exchanges"`
 	MaxPositionSize int64                  `yaml:"max_position_size"`
@@ -56,6 +73,8 @@ type StrategyConfig struct {
 	// Model hot reload configuration
 	ModelFile string           `yaml:"model_file"` // Path to model file for hot reload
 	HotReload HotReloadConfig  `yaml:"hot_reload"` // Hot reload settings
+
+	Accounts []StrategyAccountConfig `yaml:"accounts,omitempty"` // 资金账户分配（多账户）
 }
 
 // HotReloadConfig contains model hot reload configuration
@@ -71,6 +90,19 @@ type SessionConfig struct {
 	AutoStart    bool   `yaml:"auto_start"`    // Auto-start session manager
 	AutoStop     bool   `yaml:"auto_stop"`     // Auto-stop at end time
 	AutoActivate bool   `yaml:"auto_activate"` // Auto-activate strategy (if false, wait for manual activation)
+
+	Calendar CalendarConfig `yaml:"calendar"` // 交易日历（启用后忽略 StartTime/EndTime，按品种时段自动启停）
+}
+
+// CalendarConfig contains trading calendar configuration
+// 交易所节假日 + 按品种的交易时段模板（夜盘、小节休息、午休）
+type CalendarConfig struct {
+	Enabled         bool                `yaml:"enabled"`
+	HolidayFile     string              `yaml:"holiday_file"`     // 节假日文件，每行 YYYYMMDD，# 注释
+	PreOpenMinutes  int                 `yaml:"pre_open_minutes"` // 集合竞价前的盘前准备（分钟），默认 5
+	DefaultTemplate string              `yaml:"default_template"` // 未配置品种使用的模板，默认 day；也用于交易日收盘（日终重置）
+	Products        map[string]string   `yaml:"products"`         // 品种 → 模板，覆盖内置映射，如 ag: night_0230
+	Templates       map[string][]string `yaml:"templates"`        // 自定义模板，如 my_night: ["21:00-23:00", "09:00-11:30"]
 }
 
 // RiskConfig contains risk management configuration
@@ -91,6 +123,120 @@ type EngineConfig struct {
 	OrderQueueSize      int           `yaml:"order_queue_size"`
 	TimerInterval       time.Duration `yaml:"timer_interval"`
 	MaxConcurrentOrders int           `yaml:"max_concurrent_orders"`
+	Transport           string        `yaml:"transport"` // nats（默认，NATS + gRPC ORS Gateway）/ shm（hftbase SysV 共享内存队列）
+	SHM                 SHMConfig     `yaml:"shm"`       // transport: shm 时的队列配置
+}
+
+// SHMConfig contains hftbase SHM queue keys and sizes (Go 扩展)
+// 与 tbsrc-golang ors 段相同，key 值需与 hftbase ORS 侧配置一致
+type SHMConfig struct {
+	MDShmKey          int `yaml:"md_shm_key"`
+	MDQueueSize       int `yaml:"md_queue_size"`
+	ReqShmKey         int `yaml:"req_shm_key"`
+	ReqQueueSize      int `yaml:"req_queue_size"`
+	RespShmKey        int `yaml:"resp_shm_key"`
+	RespQueueSize     int `yaml:"resp_queue_size"`
+	ClientStoreShmKey int `yaml:"client_store_shm_key"`
+}
+
+// OffsetConfig contains open/close offset planning configuration
+// 未指定开平的订单按持仓拆分为平昨/平今/开仓（SHFE/INE 显式平今，其他交易所平仓先平昨）
+type OffsetConfig struct {
+	Enabled  bool                           `yaml:"enabled"`
+	Lock     string                         `yaml:"lock"`     // 平今偏好：never（默认，直接平今）/ auto（平今费高于开仓时锁仓）/ always（今仓一律锁仓）
+	Products map[string]OffsetProductConfig `yaml:"products"` // 品种 → 交易所/乘数/手续费，覆盖内置费率表
+}
+
+// OffsetProductConfig 单品种手续费配置：ratio 为成交金额比例，per_lot 为每手固定金额
+type OffsetProductConfig struct {
+	Exchange      string  `yaml:"exchange"`
+	Multiplier    float64 `yaml:"multiplier"`
+	OpenRatio     float64 `yaml:"open_ratio"`
+	OpenPerLot    float64 `yaml:"open_per_lot"`
+	CloseYdRatio  float64 `yaml:"close_yd_ratio"`
+	CloseYdPerLot float64 `yaml:"close_yd_per_lot"`
+	CloseTdRatio  float64 `yaml:"close_td_ratio"`
+	CloseTdPerLot float64 `yaml:"close_td_per_lot"`
+}
+
+// ReconcileConfig contains position reconciliation configuration
+// 策略持仓 / 成交流水重建的台账 / 柜台持仓 三方对账
+type ReconcileConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	IntervalSec int               `yaml:"interval_sec"` // 定期对账间隔（秒），默认 300
+	FillDir     string            `yaml:"fill_dir"`     // 成交流水与昨仓基线目录，默认 <data_dir>/fills
+	ReportDir   string            `yaml:"report_dir"`   // 对账报告目录，默认 <data_dir>/reconcile
+	Policies    map[string]string `yaml:"policies"`     // 差异类型 → alert / auto_heal / freeze，覆盖默认策略
+}
+
+// JournalConfig contains write-ahead order journal configuration
+// 记录全部下单请求/应答/回报，崩溃重启时重放快照之后的成交（Go 扩展）
+type JournalConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	Path           string `yaml:"path"`             // 日志文件，默认 <data_dir>/journal/orders.journal
+	SyncIntervalMs int    `yaml:"sync_interval_ms"` // 定时 fsync 间隔（毫秒），默认 5
+	SyncBatch      int    `yaml:"sync_batch"`       // 未 fsync 记录数达到该值时立即 fsync，默认 64
+}
+
+// OrphanOrdersConfig contains startup open-order recovery configuration
+// 重启后查询柜台上本策略仍在途的订单，接管到策略订单表或撤单（Go 扩展）
+type OrphanOrdersConfig struct {
+	Policy string `yaml:"policy"` // adopt（默认）/ cancel / ignore / off（不查询）
+}
+
+// ParamsConfig contains staged parameter change configuration
+// 参数变更先提交预览（diff/校验/风险提示），审批后原子生效或定时生效，版本持久化可回滚（Go 扩展）
+type ParamsConfig struct {
+	Dir             string  `yaml:"dir"`              // 变更与版本目录，默认 <data_dir>/params
+	RequireApproval bool    `yaml:"require_approval"` // 变更须由提交人以外的用户审批后才能生效
+	ReapplyOnStart  bool    `yaml:"reapply_on_start"` // 启动时重新应用最新版本（配置/model 文件中的值与版本不一致时）
+	MaxChangePct    float64 `yaml:"max_change_pct"`   // 单个参数相对变化超过该百分比时提示，默认 50
+}
+
+// AlgosConfig contains execution algo (parent order) configuration
+// 通过 API 下达 TWAP/VWAP/POV/IS 母单，子单先被动挂单、按计划升级追单（Go 扩展）
+type AlgosConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	CurveDir      string `yaml:"curve_dir"`       // VWAP 成交量曲线目录（<symbol>.json），默认 <data_dir>/volume_curves
+	PassiveWaitMs int    `yaml:"passive_wait_ms"` // 母单未指定时的被动子单等待，默认 10000
+	MaxActive     int    `yaml:"max_active"`      // 同时执行的母单上限，默认 20
+}
+
+// ShadowConfig contains live-vs-backtest shadow comparison configuration
+// 实盘行情同时驱动一份接回测撮合的策略副本，持续对比信号/下单/成交/持仓，差异记日志并按交易日出报告（Go 扩展）
+type ShadowConfig struct {
+	Enabled          bool    `yaml:"enabled"`
+	ReportDir        string  `yaml:"report_dir"`         // 日报目录，默认 <data_dir>/shadow
+	DecisionWindowMs int     `yaml:"decision_window_ms"` // 下单对齐窗口（行情时间），默认 2000
+	FillWindowMs     int     `yaml:"fill_window_ms"`     // 成交对齐窗口，默认 5000
+	PositionGraceMs  int     `yaml:"position_grace_ms"`  // 持仓不一致持续超过该时长才记差异，默认 5000
+	PriceTolerance   float64 `yaml:"price_tolerance"`    // 委托价允许差异（价格单位），默认 0
+	SignalTolerance  float64 `yaml:"signal_tolerance"`   // z_score 允许差异，默认 0.1
+	QueueSize        int     `yaml:"queue_size"`         // 事件队列长度，满时丢弃并计数，默认 65536
+	FillDelayMs      int     `yaml:"fill_delay_ms"`      // 撮合参数，同回测 order_simulation
+	SlippageBps      float64 `yaml:"slippage_bps"`
+	CommissionRate   float64 `yaml:"commission_rate"`
+}
+
+// AccountsConfig contains multi-account configuration
+// 同一策略按比例在多个资金账户下单，持仓/盈亏按账户统计，风控按账户和全局两级检查（Go 扩展）
+type AccountsConfig struct {
+	List   []AccountConfig     `yaml:"list"`
+	Global AccountLimitsConfig `yaml:"global"` // 跨账户汇总限额
+}
+
+// AccountConfig 单个资金账户
+// id 须与 counter_bridge 启动参数 <broker>@<account> 中的账户一致
+type AccountConfig struct {
+	ID                  string `yaml:"id"`
+	AccountLimitsConfig `yaml:",inline"`
+}
+
+// AccountLimitsConfig 账户风控限额，0 = 不限
+type AccountLimitsConfig struct {
+	MaxOrderQty  int64   `yaml:"max_order_qty"`  // 单笔委托上限（手）
+	MaxPosition  int64   `yaml:"max_position"`   // 单合约净持仓上限（含在途同向委托）
+	MaxDailyLoss float64 `yaml:"max_daily_loss"` // 当日亏损上限（已实现 + 浮动），达到后只允许减仓
 }
 
 // PortfolioConfig contains portfolio management configuration
@@ -102,13 +248,73 @@ type PortfolioConfig struct {
 	MaxAllocation        float64            `yaml:"max_allocation"`
 	EnableAutoRebalance  bool               `yaml:"enable_auto_rebalance"`
 	EnableCorrelation    bool               `yaml:"enable_correlation_calc"`
+	Policy               PortfolioPolicyConfig `yaml:"policy"` // 资金分配策略（默认等权）
+	Sizing               PortfolioSizingConfig `yaml:"sizing"` // 分配资金 → 策略下单量转换
+}
+
+// PortfolioPolicyConfig contains allocation policy configuration (Go 扩展)
+// 参数含义见 portfolio.PolicyConfig
+type PortfolioPolicyConfig struct {
+	Name               string   `yaml:"name"` // equal / inverse_vol / risk_parity / vol_target / kelly
+	Lookback           int      `yaml:"lookback"`
+	MinHistory         int      `yaml:"min_history"`
+	SampleIntervalSec  int      `yaml:"sample_interval_sec"`
+	PeriodsPerYear     float64  `yaml:"periods_per_year"`
+	TargetVol          float64  `yaml:"target_vol"`
+	MaxGross           float64  `yaml:"max_gross"`
+	KellyFraction      float64  `yaml:"kelly_fraction"`
+	KellyCap           float64  `yaml:"kelly_cap"`
+	Damping            float64  `yaml:"damping"`
+	MaxTurnover        float64  `yaml:"max_turnover"`
+	RebalanceThreshold float64  `yaml:"rebalance_threshold"`
+	SizeParams         []string `yaml:"size_params"`
+}
+
+// PortfolioSizingConfig contains allocation-to-size translation configuration (Go 扩展)
+// 参数含义见 portfolio.SizingConfig
+type PortfolioSizingConfig struct {
+	Mode            string  `yaml:"mode"` // proportional / capital
+	MarginUsage     float64 `yaml:"margin_usage"`
+	ShrinkStep      float64 `yaml:"shrink_step"`
+	StepIntervalSec int     `yaml:"step_interval_sec"`
+	ForceFlatten    bool    `yaml:"force_flatten"`
+	MaxEvents       int     `yaml:"max_events"`
 }
 
 // APIConfig contains HTTP REST API configuration
 type APIConfig struct {
-	Enabled bool   `yaml:"enabled"` // enable HTTP API server
-	Port    int    `yaml:"port"`    // API server port
-	Host    string `yaml:"host"`    // API server host (default: localhost)
+	Enabled bool          `yaml:"enabled"` // enable HTTP API server
+	Port    int           `yaml:"port"`    // API server port
+	Host    string        `yaml:"host"`    // API server host（空 = 所有网卡；认证关闭时固定 127.0.0.1）
+	Auth    APIAuthConfig `yaml:"auth"`    // 认证/授权与审计日志
+}
+
+// APIAuthConfig contains control API authentication and audit configuration
+// 角色: viewer / trader / risk_officer / admin，每个端点对应一个权限（Go 扩展）
+type APIAuthConfig struct {
+	Enabled        bool                `yaml:"enabled"`         // false = 不认证，只监听 127.0.0.1（本机请求按 admin 处理），仍记录审计日志
+	Users          []APIUserConfig     `yaml:"users"`
+	Permissions    map[string][]string `yaml:"permissions"`     // 权限 → 角色，覆盖默认权限表
+	AllowedOrigins []string            `yaml:"allowed_origins"` // CORS 白名单，为空时不允许跨域
+	AuditFile      string              `yaml:"audit_file"`      // 默认 <data_dir>/audit/api_audit.jsonl
+	TLS            APITLSConfig        `yaml:"tls"`
+}
+
+// APIUserConfig 控制 API 用户；token_sha256 / token_env / cert_cn 至少一个
+type APIUserConfig struct {
+	Name        string `yaml:"name"`
+	Role        string `yaml:"role"`
+	TokenSHA256 string `yaml:"token_sha256"` // hex(sha256(token))，配置文件中不保存明文
+	TokenEnv    string `yaml:"token_env"`    // 从环境变量读取明文 token
+	CertCN      string `yaml:"cert_cn"`      // mTLS 客户端证书 CommonName
+}
+
+// APITLSConfig HTTPS / mTLS 配置（cert_file 为空时使用 HTTP）
+type APITLSConfig struct {
+	CertFile          string `yaml:"cert_file"`
+	KeyFile           string `yaml:"key_file"`
+	ClientCAFile      string `yaml:"client_ca_file"`      // 非空时校验客户端证书
+	RequireClientCert bool   `yaml:"require_client_cert"` // true = 只接受 mTLS
 }
 
 // LoggingConfig contains logging configuration
@@ -178,6 +384,54 @@ func (c *TraderConfig) Validate() error {
 	if c.Engine.MaxConcurrentOrders == 0 {
 		c.Engine.MaxConcurrentOrders = 10
 	}
+	c.Engine.Transport = strings.ToLower(c.Engine.Transport)
+	switch c.Engine.Transport {
+	case "":
+		c.Engine.Transport = "nats"
+	case "nats":
+	case "shm":
+		s := c.Engine.SHM
+		if s.MDShmKey == 0 || s.ReqShmKey == 0 || s.RespShmKey == 0 || s.ClientStoreShmKey == 0 {
+			return fmt.Errorf("engine.shm: md_shm_key, req_shm_key, resp_shm_key and client_store_shm_key are required for transport shm")
+		}
+	default:
+		return fmt.Errorf("engine.transport must be 'nats' or 'shm'")
+	}
+
+	if c.Offset.Enabled {
+		switch strings.ToLower(c.Offset.Lock) {
+		case "", "never", "auto", "always":
+		default:
+			return fmt.Errorf("offset.lock must be 'never', 'auto', or 'always'")
+		}
+	}
+
+	switch strings.ToLower(c.OrphanOrders.Policy) {
+	case "":
+		c.OrphanOrders.Policy = "adopt"
+		if c.Engine.Transport == "shm" {
+			c.OrphanOrders.Policy = "off"
+		}
+	case "adopt", "cancel", "ignore", "off":
+	default:
+		return fmt.Errorf("orphan_orders.policy must be 'adopt', 'cancel', 'ignore', or 'off'")
+	}
+
+	if err := c.validateSHMFeatures(); err != nil {
+		return err
+	}
+
+	if err := c.validateAccounts(); err != nil {
+		return err
+	}
+
+	if err := c.validateShadow(); err != nil {
+		return err
+	}
+
+	if c.Reconcile.IntervalSec == 0 {
+		c.Reconcile.IntervalSec = 300
+	}
 
 	if c.Risk.CheckIntervalMs == 0 {
 		c.Risk.CheckIntervalMs = 100
@@ -218,8 +472,8 @@ func (c *TraderConfig) validateSingleStrategy() error {
 		return fmt.Errorf("strategy.symbols cannot be empty")
 	}
 
-	// Hedging and pairwise_arb strategies require at least 2 symbols
-	if (c.Strategy.Type == "hedging" || c.Strategy.Type == "pairwise_arb") && len(c.Strategy.Symbols) < 2 {
+	// Hedging, pairwise_arb and basket_arb strategies require at least 2 symbols
+	if (c.Strategy.Type == "hedging" || c.Strategy.Type == "pairwise_arb" || c.Strategy.Type == "basket_arb") && len(c.Strategy.Symbols) < 2 {
 		return fmt.Errorf("%s strategy requires at least 2 symbols", c.Strategy.Type)
 	}
 
@@ -260,7 +514,7 @@ func (c *TraderConfig) validateMultiStrategy() error {
 		}
 
 		// 验证需要多品种的策略
-		if (s.Type == "hedging" || s.Type == "pairwise_arb") && len(s.Symbols) < 2 {
+		if (s.Type == "hedging" || s.Type == "pairwise_arb" || s.Type == "basket_arb") && len(s.Symbols) < 2 {
 			return fmt.Errorf("strategies[%d]: %s strategy requires at least 2 symbols", i, s.Type)
 		}
 
@@ -290,9 +544,84 @@ func (c *TraderConfig) validateMultiStrategy() error {
 	return nil
 }
 
+// validateSHMFeatures SHM 链路没有 ORS Gateway / Counter Bridge 查询接口（柜台持仓、在途订单），
+// 依赖这些查询的功能在 SHM 下无法工作，启用时直接拒绝启动而不是静默跳过
+func (c *TraderConfig) validateSHMFeatures() error {
+	if c.Engine.Transport != "shm" {
+		return nil
+	}
+	var unsupported []string
+	if c.Reconcile.Enabled {
+		unsupported = append(unsupported, "reconcile.enabled (needs counter positions)")
+	}
+	if p := strings.ToLower(c.OrphanOrders.Policy); p != "off" {
+		unsupported = append(unsupported, fmt.Sprintf("orphan_orders.policy=%s (needs open-order query, use 'off')", p))
+	}
+	if c.Offset.Enabled {
+		unsupported = append(unsupported, "offset.enabled (ledger is seeded from counter positions)")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("engine.transport shm does not support: %s", strings.Join(unsupported, "; "))
+	}
+	return nil
+}
+
+// validateShadow 原生 tbsrc 策略直接收发 SHM，影子模式无法为其建立接回测撮合的副本，
+// 启用影子模式时拒绝启动而不是静默跳过该策略
+func (c *TraderConfig) validateShadow() error {
+	if !c.Shadow.Enabled {
+		return nil
+	}
+	var native []string
+	for _, s := range c.GetStrategyConfigs() {
+		if s.Enabled && s.Type == "tbsrc_pairwise" {
+			native = append(native, s.ID)
+		}
+	}
+	if len(native) > 0 {
+		return fmt.Errorf("shadow.enabled does not support native tbsrc strategies (tbsrc_pairwise): %s",
+			strings.Join(native, ", "))
+	}
+	return nil
+}
+
+// validateAccounts 验证资金账户及策略账户分配
+func (c *TraderConfig) validateAccounts() error {
+	known := make(map[string]bool, len(c.Accounts.List))
+	for i, a := range c.Accounts.List {
+		if a.ID == "" {
+			return fmt.Errorf("accounts.list[%d].id is required", i)
+		}
+		if known[a.ID] {
+			return fmt.Errorf("duplicate account id: %s", a.ID)
+		}
+		known[a.ID] = true
+	}
+
+	for _, s := range c.GetStrategyConfigs() {
+		seen := make(map[string]bool, len(s.Accounts))
+		for i, a := range s.Accounts {
+			if !known[a.Account] {
+				return fmt.Errorf("strategy %s: account %q is not defined in accounts.list", s.ID, a.Account)
+			}
+			if seen[a.Account] {
+				return fmt.Errorf("strategy %s: duplicate account %s", s.ID, a.Account)
+			}
+			seen[a.Account] = true
+			switch {
+			case i == 0 && a.Scale != 0 && a.Scale != 1:
+				return fmt.Errorf("strategy %s: lead account %s scale must be 1", s.ID, a.Account)
+			case i > 0 && a.Scale <= 0:
+				return fmt.Errorf("strategy %s: account %s scale must be > 0", s.ID, a.Account)
+			}
+		}
+	}
+	return nil
+}
+
 // validateStrategyType 验证策略类型
 func validateStrategyType(strategyType string) error {
-	validTypes := []string{"passive", "aggressive", "hedging", "pairwise_arb", "trend_following", "grid", "vwap"}
+	validTypes := []string{"passive", "aggressive", "hedging", "pairwise_arb", "trend_following", "grid", "vwap", "market_making", "basket_arb", "tbsrc_pairwise"}
 	for _, t := range validTypes {
 		if strategyType == t {
 			return nil
@@ -322,6 +651,7 @@ func (c *TraderConfig) GetStrategyConfigs() []StrategyItemConfig {
 			Parameters:      c.Strategy.Parameters,
 			ModelFile:       c.Strategy.ModelFile,
 			HotReload:       c.Strategy.HotReload,
+			Accounts:        c.Strategy.Accounts,
 		},
 	}
 }
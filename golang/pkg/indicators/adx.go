@@ -41,18 +41,18 @@ import (
 type ADX struct {
 	*BaseIndicator
 	period         int
-	highs          []float64
-	lows           []float64
-	closes         []float64
-	trueRanges     []float64
-	plusDM         []float64
-	minusDM        []float64
+	highs          window[float64]
+	lows           window[float64]
+	closes         window[float64]
+	trueRanges     window[float64]
+	plusDM         window[float64]
+	minusDM        window[float64]
 	smoothedTR     float64
 	smoothedPlusDM float64
 	smoothedMinusDM float64
 	plusDI         float64
 	minusDI        float64
-	dxValues       []float64
+	dxValues       window[float64]
 	adx            float64
 	isFirstSmooth  bool
 }
@@ -69,13 +69,13 @@ func NewADX(period int, maxHistory int) *ADX {
 	return &ADX{
 		BaseIndicator: NewBaseIndicator("ADX", maxHistory),
 		period:        period,
-		highs:         make([]float64, 0, period+1),
-		lows:          make([]float64, 0, period+1),
-		closes:        make([]float64, 0, period+1),
-		trueRanges:    make([]float64, 0, period),
-		plusDM:        make([]float64, 0, period),
-		minusDM:       make([]float64, 0, period),
-		dxValues:      make([]float64, 0, period),
+		highs:         newWindow[float64](period+1),
+		lows:          newWindow[float64](period+1),
+		closes:        newWindow[float64](period+1),
+		trueRanges:    newWindow[float64](period),
+		plusDM:        newWindow[float64](period),
+		minusDM:       newWindow[float64](period),
+		dxValues:      newWindow[float64](period),
 		isFirstSmooth: true,
 	}
 }
@@ -117,29 +117,29 @@ func (a *ADX) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store prices
-	a.highs = append(a.highs, high)
-	a.lows = append(a.lows, low)
-	a.closes = append(a.closes, close)
+	a.highs.push(high)
+	a.lows.push(low)
+	a.closes.push(close)
 
 	// Keep period+1 for calculations
-	if len(a.highs) > a.period+1 {
-		a.highs = a.highs[1:]
-		a.lows = a.lows[1:]
-		a.closes = a.closes[1:]
+	if a.highs.len() > a.period+1 {
+		a.highs.dropOldest()
+		a.lows.dropOldest()
+		a.closes.dropOldest()
 	}
 
 	// Need at least 2 bars
-	if len(a.highs) < 2 {
+	if a.highs.len() < 2 {
 		return
 	}
 
 	// Calculate True Range (TR)
-	prevClose := a.closes[len(a.closes)-2]
+	prevClose := a.closes.at(a.closes.len()-2)
 	tr := math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
 
 	// Calculate +DM and -DM
-	prevHigh := a.highs[len(a.highs)-2]
-	prevLow := a.lows[len(a.lows)-2]
+	prevHigh := a.highs.at(a.highs.len()-2)
+	prevLow := a.lows.at(a.lows.len()-2)
 
 	upMove := high - prevHigh
 	downMove := prevLow - low
@@ -154,19 +154,19 @@ func (a *ADX) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store values
-	a.trueRanges = append(a.trueRanges, tr)
-	a.plusDM = append(a.plusDM, plusDMVal)
-	a.minusDM = append(a.minusDM, minusDMVal)
+	a.trueRanges.push(tr)
+	a.plusDM.push(plusDMVal)
+	a.minusDM.push(minusDMVal)
 
 	// Keep only period values
-	if len(a.trueRanges) > a.period {
-		a.trueRanges = a.trueRanges[1:]
-		a.plusDM = a.plusDM[1:]
-		a.minusDM = a.minusDM[1:]
+	if a.trueRanges.len() > a.period {
+		a.trueRanges.dropOldest()
+		a.plusDM.dropOldest()
+		a.minusDM.dropOldest()
 	}
 
 	// Need at least period values for initial smoothing
-	if len(a.trueRanges) < a.period {
+	if a.trueRanges.len() < a.period {
 		return
 	}
 
@@ -178,9 +178,9 @@ func (a *ADX) Update(md *mdpb.MarketDataUpdate) {
 		a.smoothedMinusDM = 0
 
 		for i := 0; i < a.period; i++ {
-			a.smoothedTR += a.trueRanges[i]
-			a.smoothedPlusDM += a.plusDM[i]
-			a.smoothedMinusDM += a.minusDM[i]
+			a.smoothedTR += a.trueRanges.at(i)
+			a.smoothedPlusDM += a.plusDM.at(i)
+			a.smoothedMinusDM += a.minusDM.at(i)
 		}
 
 		a.isFirstSmooth = false
@@ -215,23 +215,23 @@ func (a *ADX) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store DX
-	a.dxValues = append(a.dxValues, dx)
+	a.dxValues.push(dx)
 
 	// Keep only period DX values for ADX calculation
-	if len(a.dxValues) > a.period {
-		a.dxValues = a.dxValues[len(a.dxValues)-a.period:]
+	if a.dxValues.len() > a.period {
+		a.dxValues.keepLast(a.period)
 	}
 
 	// Calculate ADX (smoothed DX)
 	// Need at least period DX values
-	if len(a.dxValues) >= a.period {
+	if a.dxValues.len() >= a.period {
 		if a.adx == 0 {
 			// Initial ADX: simple average
 			sum := 0.0
-			for _, dxVal := range a.dxValues {
+			for _, dxVal := range a.dxValues.values() {
 				sum += dxVal
 			}
-			a.adx = sum / float64(len(a.dxValues))
+			a.adx = sum / float64(a.dxValues.len())
 		} else {
 			// Subsequent ADX: Wilder's smoothing
 			a.adx = (a.adx*(float64(a.period)-1) + dx) / float64(a.period)
@@ -259,13 +259,13 @@ func (a *ADX) GetMinusDI() float64 {
 // Reset resets the indicator
 func (a *ADX) Reset() {
 	a.BaseIndicator.Reset()
-	a.highs = a.highs[:0]
-	a.lows = a.lows[:0]
-	a.closes = a.closes[:0]
-	a.trueRanges = a.trueRanges[:0]
-	a.plusDM = a.plusDM[:0]
-	a.minusDM = a.minusDM[:0]
-	a.dxValues = a.dxValues[:0]
+	a.highs.reset()
+	a.lows.reset()
+	a.closes.reset()
+	a.trueRanges.reset()
+	a.plusDM.reset()
+	a.minusDM.reset()
+	a.dxValues.reset()
 	a.smoothedTR = 0
 	a.smoothedPlusDM = 0
 	a.smoothedMinusDM = 0
@@ -277,7 +277,7 @@ func (a *ADX) Reset() {
 
 // IsReady returns true if the indicator has enough data
 func (a *ADX) IsReady() bool {
-	return len(a.dxValues) >= a.period && a.adx > 0
+	return a.dxValues.len() >= a.period && a.adx > 0
 }
 
 // GetPeriod returns the period
@@ -215,7 +215,7 @@ func TestADX_Reset(t *testing.T) {
 			adx.GetValue(), adx.GetPlusDI(), adx.GetMinusDI())
 	}
 
-	if len(adx.highs) != 0 || len(adx.lows) != 0 || len(adx.closes) != 0 {
+	if adx.highs.len() != 0 || adx.lows.len() != 0 || adx.closes.len() != 0 {
 		t.Error("Price windows should be empty after reset")
 	}
 
@@ -30,8 +30,8 @@ type AggressiveTrade struct {
 	totalTrades         int // Total trades in window
 
 	// Rolling window
-	isAggressiveBuy  []bool // Track if each trade was aggressive buy
-	isAggressiveSell []bool // Track if each trade was aggressive sell
+	isAggressiveBuy  window[bool] // Track if each trade was aggressive buy
+	isAggressiveSell window[bool] // Track if each trade was aggressive sell
 
 	// Current state
 	aggressiveRatio     float64 // Ratio of aggressive trades
@@ -49,8 +49,8 @@ func NewAggressiveTrade(name string, windowSize int, maxHistory int) *Aggressive
 		BaseIndicator:    NewBaseIndicator(name, maxHistory),
 		windowSize:       windowSize,
 		spreadBuffer:     0.1, // 10% buffer
-		isAggressiveBuy:  make([]bool, 0, windowSize),
-		isAggressiveSell: make([]bool, 0, windowSize),
+		isAggressiveBuy:  newWindow[bool](windowSize),
+		isAggressiveSell: newWindow[bool](windowSize),
 	}
 
 	return at
@@ -130,20 +130,20 @@ func (at *AggressiveTrade) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add to rolling window
-	at.isAggressiveBuy = append(at.isAggressiveBuy, isAggBuy)
-	at.isAggressiveSell = append(at.isAggressiveSell, isAggSell)
+	at.isAggressiveBuy.push(isAggBuy)
+	at.isAggressiveSell.push(isAggSell)
 	at.totalTrades++
 
 	// Remove oldest trade if window is full
-	if len(at.isAggressiveBuy) > at.windowSize {
-		if at.isAggressiveBuy[0] {
+	if at.isAggressiveBuy.len() > at.windowSize {
+		if at.isAggressiveBuy.at(0) {
 			at.aggressiveBuyCount--
 		}
-		if at.isAggressiveSell[0] {
+		if at.isAggressiveSell.at(0) {
 			at.aggressiveSellCount--
 		}
-		at.isAggressiveBuy = at.isAggressiveBuy[1:]
-		at.isAggressiveSell = at.isAggressiveSell[1:]
+		at.isAggressiveBuy.dropOldest()
+		at.isAggressiveSell.dropOldest()
 		at.totalTrades--
 	}
 
@@ -10,8 +10,8 @@ import (
 type AlphaIndicator struct {
 	*BaseIndicator
 	period           int
-	portfolioReturns []float64
-	benchmarkReturns []float64
+	portfolioReturns window[float64]
+	benchmarkReturns window[float64]
 	prevPortfolio    float64
 	prevBenchmark    float64
 	riskFreeRate     float64 // annualized risk-free rate
@@ -34,8 +34,8 @@ func NewAlphaIndicator(period int, benchmarkSymbol string, riskFreeRate float64,
 	return &AlphaIndicator{
 		BaseIndicator:    NewBaseIndicator("Alpha", maxHistory),
 		period:           period,
-		portfolioReturns: make([]float64, 0, period),
-		benchmarkReturns: make([]float64, 0, period),
+		portfolioReturns: newWindow[float64](period),
+		benchmarkReturns: newWindow[float64](period),
 		riskFreeRate:     riskFreeRate,
 		benchmarkSymbol:  benchmarkSymbol,
 		priceCache:       make(map[string]float64),
@@ -96,9 +96,9 @@ func (a *AlphaIndicator) Update(md *mdpb.MarketDataUpdate) {
 		// Calculate portfolio return
 		if a.prevPortfolio > 0 {
 			ret := (price - a.prevPortfolio) / a.prevPortfolio
-			a.portfolioReturns = append(a.portfolioReturns, ret)
-			if len(a.portfolioReturns) > a.period {
-				a.portfolioReturns = a.portfolioReturns[1:]
+			a.portfolioReturns.push(ret)
+			if a.portfolioReturns.len() > a.period {
+				a.portfolioReturns.dropOldest()
 			}
 		}
 		a.prevPortfolio = price
@@ -106,16 +106,16 @@ func (a *AlphaIndicator) Update(md *mdpb.MarketDataUpdate) {
 		// Calculate benchmark return
 		if a.prevBenchmark > 0 {
 			ret := (price - a.prevBenchmark) / a.prevBenchmark
-			a.benchmarkReturns = append(a.benchmarkReturns, ret)
-			if len(a.benchmarkReturns) > a.period {
-				a.benchmarkReturns = a.benchmarkReturns[1:]
+			a.benchmarkReturns.push(ret)
+			if a.benchmarkReturns.len() > a.period {
+				a.benchmarkReturns.dropOldest()
 			}
 		}
 		a.prevBenchmark = price
 	}
 
 	// Need enough data for both series
-	if len(a.portfolioReturns) < a.period || len(a.benchmarkReturns) < a.period {
+	if a.portfolioReturns.len() < a.period || a.benchmarkReturns.len() < a.period {
 		return
 	}
 
@@ -125,28 +125,28 @@ func (a *AlphaIndicator) Update(md *mdpb.MarketDataUpdate) {
 
 // UpdateWithReturns updates with pre-calculated returns (useful for portfolio-level calculation)
 func (a *AlphaIndicator) UpdateWithReturns(portfolioReturn, benchmarkReturn float64) {
-	a.portfolioReturns = append(a.portfolioReturns, portfolioReturn)
-	if len(a.portfolioReturns) > a.period {
-		a.portfolioReturns = a.portfolioReturns[1:]
+	a.portfolioReturns.push(portfolioReturn)
+	if a.portfolioReturns.len() > a.period {
+		a.portfolioReturns.dropOldest()
 	}
 
-	a.benchmarkReturns = append(a.benchmarkReturns, benchmarkReturn)
-	if len(a.benchmarkReturns) > a.period {
-		a.benchmarkReturns = a.benchmarkReturns[1:]
+	a.benchmarkReturns.push(benchmarkReturn)
+	if a.benchmarkReturns.len() > a.period {
+		a.benchmarkReturns.dropOldest()
 	}
 
-	if len(a.portfolioReturns) >= a.period && len(a.benchmarkReturns) >= a.period {
+	if a.portfolioReturns.len() >= a.period && a.benchmarkReturns.len() >= a.period {
 		a.calculateAlpha()
 	}
 }
 
 func (a *AlphaIndicator) calculateAlpha() {
 	// Calculate mean returns
-	meanPortfolio := stats.Mean(a.portfolioReturns)
-	meanBenchmark := stats.Mean(a.benchmarkReturns)
+	meanPortfolio := stats.Mean(a.portfolioReturns.values())
+	meanBenchmark := stats.Mean(a.benchmarkReturns.values())
 
 	// Calculate beta
-	beta := stats.Beta(a.portfolioReturns, a.benchmarkReturns)
+	beta := stats.Beta(a.portfolioReturns.values(), a.benchmarkReturns.values())
 
 	// Daily risk-free rate (assuming 252 trading days)
 	dailyRF := a.riskFreeRate / 252.0
@@ -171,8 +171,8 @@ func (a *AlphaIndicator) GetValue() float64 {
 // Reset resets the indicator
 func (a *AlphaIndicator) Reset() {
 	a.BaseIndicator.Reset()
-	a.portfolioReturns = a.portfolioReturns[:0]
-	a.benchmarkReturns = a.benchmarkReturns[:0]
+	a.portfolioReturns.reset()
+	a.benchmarkReturns.reset()
 	a.prevPortfolio = 0
 	a.prevBenchmark = 0
 	a.priceCache = make(map[string]float64)
@@ -181,5 +181,5 @@ func (a *AlphaIndicator) Reset() {
 
 // IsReady returns true if we have enough data
 func (a *AlphaIndicator) IsReady() bool {
-	return len(a.portfolioReturns) >= a.period && len(a.benchmarkReturns) >= a.period
+	return a.portfolioReturns.len() >= a.period && a.benchmarkReturns.len() >= a.period
 }
@@ -33,8 +33,8 @@ import (
 type Aroon struct {
 	*BaseIndicator
 	period         int
-	highs          []float64
-	lows           []float64
+	highs          window[float64]
+	lows           window[float64]
 	aroonUp        float64
 	aroonDown      float64
 	oscillator     float64
@@ -54,8 +54,8 @@ func NewAroon(period int, maxHistory int) *Aroon {
 	return &Aroon{
 		BaseIndicator: NewBaseIndicator("Aroon", maxHistory),
 		period:        period,
-		highs:         make([]float64, 0, period),
-		lows:          make([]float64, 0, period),
+		highs:         newWindow[float64](period),
+		lows:          newWindow[float64](period),
 	}
 }
 
@@ -96,41 +96,41 @@ func (a *Aroon) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store prices
-	a.highs = append(a.highs, high)
-	a.lows = append(a.lows, low)
+	a.highs.push(high)
+	a.lows.push(low)
 
 	// Keep only period elements
-	if len(a.highs) > a.period {
-		a.highs = a.highs[1:]
-		a.lows = a.lows[1:]
+	if a.highs.len() > a.period {
+		a.highs.dropOldest()
+		a.lows.dropOldest()
 	}
 
 	// Need at least period values
-	if len(a.highs) < a.period {
+	if a.highs.len() < a.period {
 		return
 	}
 
 	// Find periods since highest high
 	highestIdx := 0
-	highestHigh := a.highs[0]
-	for i := 1; i < len(a.highs); i++ {
-		if a.highs[i] >= highestHigh {
-			highestHigh = a.highs[i]
+	highestHigh := a.highs.at(0)
+	for i := 1; i < a.highs.len(); i++ {
+		if a.highs.at(i) >= highestHigh {
+			highestHigh = a.highs.at(i)
 			highestIdx = i
 		}
 	}
-	periodsSinceHigh := len(a.highs) - 1 - highestIdx
+	periodsSinceHigh := a.highs.len() - 1 - highestIdx
 
 	// Find periods since lowest low
 	lowestIdx := 0
-	lowestLow := a.lows[0]
-	for i := 1; i < len(a.lows); i++ {
-		if a.lows[i] <= lowestLow {
-			lowestLow = a.lows[i]
+	lowestLow := a.lows.at(0)
+	for i := 1; i < a.lows.len(); i++ {
+		if a.lows.at(i) <= lowestLow {
+			lowestLow = a.lows.at(i)
 			lowestIdx = i
 		}
 	}
-	periodsSinceLow := len(a.lows) - 1 - lowestIdx
+	periodsSinceLow := a.lows.len() - 1 - lowestIdx
 
 	// Calculate Aroon Up and Aroon Down
 	// Aroon Up = 100 × (period - periods since highest high) / period
@@ -170,8 +170,8 @@ func (a *Aroon) GetOscillator() float64 {
 // Reset resets the indicator
 func (a *Aroon) Reset() {
 	a.BaseIndicator.Reset()
-	a.highs = a.highs[:0]
-	a.lows = a.lows[:0]
+	a.highs.reset()
+	a.lows.reset()
 	a.aroonUp = 0
 	a.aroonDown = 0
 	a.oscillator = 0
@@ -181,7 +181,7 @@ func (a *Aroon) Reset() {
 
 // IsReady returns true if the indicator has enough data
 func (a *Aroon) IsReady() bool {
-	return len(a.highs) >= a.period
+	return a.highs.len() >= a.period
 }
 
 // GetPeriod returns the period
@@ -252,7 +252,7 @@ func TestAroon_Reset(t *testing.T) {
 			aroon.GetAroonUp(), aroon.GetAroonDown(), aroon.GetOscillator())
 	}
 
-	if len(aroon.highs) != 0 || len(aroon.lows) != 0 {
+	if aroon.highs.len() != 0 || aroon.lows.len() != 0 {
 		t.Error("Price windows should be empty after reset")
 	}
 }
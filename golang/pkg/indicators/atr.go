@@ -15,23 +15,20 @@ type ATR struct {
 	prevClose   float64
 	hasPrevious bool
 	dataPoints  int
-	trValues    []float64 // Store TR values for calculation
+	trValues    window[float64] // Store TR values for calculation
 }
 
 // NewATR creates a new ATR indicator
 func NewATR(period float64, maxHistory int) *ATR {
 	p := int(period)
 	return &ATR{
-		BaseIndicator: &BaseIndicator{
-			name:       "ATR",
-			maxHistory: maxHistory,
-		},
+		BaseIndicator: NewBaseIndicator("ATR", maxHistory),
 		period:      p,
 		atr:         0.0,
 		prevClose:   0.0,
 		hasPrevious: false,
 		dataPoints:  0,
-		trValues:    make([]float64, 0, p),
+		trValues:    newWindow[float64](p),
 	}
 }
 
@@ -50,9 +47,9 @@ func (atr *ATR) Update(md *mdpb.MarketDataUpdate) {
 	tr := atr.calculateTrueRange(high, low, close)
 
 	// Store TR value
-	atr.trValues = append(atr.trValues, tr)
-	if len(atr.trValues) > atr.period {
-		atr.trValues = atr.trValues[1:]
+	atr.trValues.push(tr)
+	if atr.trValues.len() > atr.period {
+		atr.trValues.dropOldest()
 	}
 
 	atr.dataPoints++
@@ -61,14 +58,14 @@ func (atr *ATR) Update(md *mdpb.MarketDataUpdate) {
 	if atr.dataPoints < atr.period {
 		// Initial period: use simple average of TR
 		sum := 0.0
-		for _, trVal := range atr.trValues {
+		for _, trVal := range atr.trValues.values() {
 			sum += trVal
 		}
-		atr.atr = sum / float64(len(atr.trValues))
+		atr.atr = sum / float64(atr.trValues.len())
 	} else if atr.dataPoints == atr.period {
 		// First ATR: simple average
 		sum := 0.0
-		for _, trVal := range atr.trValues {
+		for _, trVal := range atr.trValues.values() {
 			sum += trVal
 		}
 		atr.atr = sum / float64(atr.period)
@@ -129,7 +126,7 @@ func (atr *ATR) Reset() {
 	atr.prevClose = 0.0
 	atr.hasPrevious = false
 	atr.dataPoints = 0
-	atr.trValues = make([]float64, 0, atr.period)
+	atr.trValues.reset()
 	atr.BaseIndicator.Reset()
 }
 
@@ -145,8 +142,8 @@ func (atr *ATR) GetDataPoints() int {
 
 // GetTRValues returns the current TR values (for testing)
 func (atr *ATR) GetTRValues() []float64 {
-	result := make([]float64, len(atr.trValues))
-	copy(result, atr.trValues)
+	result := make([]float64, atr.trValues.len())
+	copy(result, atr.trValues.values())
 	return result
 }
 
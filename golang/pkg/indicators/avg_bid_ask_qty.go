@@ -8,7 +8,7 @@ import (
 type AvgBidQty struct {
 	*BaseIndicator
 	period    int
-	bidQties  []float64
+	bidQties  window[float64]
 	sum       float64
 	avgValue  float64
 	numLevels int // number of orderbook levels to consider
@@ -29,7 +29,7 @@ func NewAvgBidQty(period int, numLevels int, maxHistory int) *AvgBidQty {
 	return &AvgBidQty{
 		BaseIndicator: NewBaseIndicator("AvgBidQty", maxHistory),
 		period:        period,
-		bidQties:      make([]float64, 0, period),
+		bidQties:      newWindow[float64](period),
 		numLevels:     numLevels,
 	}
 }
@@ -75,16 +75,16 @@ func (a *AvgBidQty) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	a.sum += totalBidQty
-	a.bidQties = append(a.bidQties, totalBidQty)
+	a.bidQties.push(totalBidQty)
 
-	if len(a.bidQties) > a.period {
-		oldest := a.bidQties[0]
-		a.bidQties = a.bidQties[1:]
+	if a.bidQties.len() > a.period {
+		oldest := a.bidQties.at(0)
+		a.bidQties.dropOldest()
 		a.sum -= oldest
 	}
 
-	if len(a.bidQties) > 0 {
-		a.avgValue = a.sum / float64(len(a.bidQties))
+	if a.bidQties.len() > 0 {
+		a.avgValue = a.sum / float64(a.bidQties.len())
 		a.AddValue(a.avgValue)
 	}
 }
@@ -97,21 +97,21 @@ func (a *AvgBidQty) GetValue() float64 {
 // Reset resets the indicator
 func (a *AvgBidQty) Reset() {
 	a.BaseIndicator.Reset()
-	a.bidQties = a.bidQties[:0]
+	a.bidQties.reset()
 	a.sum = 0
 	a.avgValue = 0
 }
 
 // IsReady returns true if we have at least one full period
 func (a *AvgBidQty) IsReady() bool {
-	return len(a.bidQties) >= a.period
+	return a.bidQties.len() >= a.period
 }
 
 // AvgAskQty calculates the rolling average of total ask quantities
 type AvgAskQty struct {
 	*BaseIndicator
 	period    int
-	askQties  []float64
+	askQties  window[float64]
 	sum       float64
 	avgValue  float64
 	numLevels int
@@ -132,7 +132,7 @@ func NewAvgAskQty(period int, numLevels int, maxHistory int) *AvgAskQty {
 	return &AvgAskQty{
 		BaseIndicator: NewBaseIndicator("AvgAskQty", maxHistory),
 		period:        period,
-		askQties:      make([]float64, 0, period),
+		askQties:      newWindow[float64](period),
 		numLevels:     numLevels,
 	}
 }
@@ -178,16 +178,16 @@ func (a *AvgAskQty) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	a.sum += totalAskQty
-	a.askQties = append(a.askQties, totalAskQty)
+	a.askQties.push(totalAskQty)
 
-	if len(a.askQties) > a.period {
-		oldest := a.askQties[0]
-		a.askQties = a.askQties[1:]
+	if a.askQties.len() > a.period {
+		oldest := a.askQties.at(0)
+		a.askQties.dropOldest()
 		a.sum -= oldest
 	}
 
-	if len(a.askQties) > 0 {
-		a.avgValue = a.sum / float64(len(a.askQties))
+	if a.askQties.len() > 0 {
+		a.avgValue = a.sum / float64(a.askQties.len())
 		a.AddValue(a.avgValue)
 	}
 }
@@ -200,12 +200,12 @@ func (a *AvgAskQty) GetValue() float64 {
 // Reset resets the indicator
 func (a *AvgAskQty) Reset() {
 	a.BaseIndicator.Reset()
-	a.askQties = a.askQties[:0]
+	a.askQties.reset()
 	a.sum = 0
 	a.avgValue = 0
 }
 
 // IsReady returns true if we have at least one full period
 func (a *AvgAskQty) IsReady() bool {
-	return len(a.askQties) >= a.period
+	return a.askQties.len() >= a.period
 }
@@ -9,7 +9,7 @@ import (
 type AvgBookSize struct {
 	*BaseIndicator
 	period      int
-	bookSizes   []float64
+	bookSizes   window[float64]
 	sum         float64
 	avgValue    float64
 	numLevels   int // number of orderbook levels to consider
@@ -32,7 +32,7 @@ func NewAvgBookSize(period int, numLevels int, maxHistory int) *AvgBookSize {
 	return &AvgBookSize{
 		BaseIndicator: NewBaseIndicator("AvgBookSize", maxHistory),
 		period:        period,
-		bookSizes:     make([]float64, 0, period),
+		bookSizes:     newWindow[float64](period),
 		numLevels:     numLevels,
 	}
 }
@@ -97,18 +97,18 @@ func (a *AvgBookSize) Update(md *mdpb.MarketDataUpdate) {
 
 	// Update rolling average
 	a.sum += bookSize
-	a.bookSizes = append(a.bookSizes, bookSize)
+	a.bookSizes.push(bookSize)
 
-	if len(a.bookSizes) > a.period {
+	if a.bookSizes.len() > a.period {
 		// Remove oldest value from sum
-		oldest := a.bookSizes[0]
-		a.bookSizes = a.bookSizes[1:]
+		oldest := a.bookSizes.at(0)
+		a.bookSizes.dropOldest()
 		a.sum -= oldest
 	}
 
 	// Calculate average
-	if len(a.bookSizes) > 0 {
-		a.avgValue = a.sum / float64(len(a.bookSizes))
+	if a.bookSizes.len() > 0 {
+		a.avgValue = a.sum / float64(a.bookSizes.len())
 		a.AddValue(a.avgValue)
 	}
 }
@@ -131,12 +131,12 @@ func (a *AvgBookSize) GetNumLevels() int {
 // Reset resets the indicator
 func (a *AvgBookSize) Reset() {
 	a.BaseIndicator.Reset()
-	a.bookSizes = a.bookSizes[:0]
+	a.bookSizes.reset()
 	a.sum = 0
 	a.avgValue = 0
 }
 
 // IsReady returns true if we have at least one full period
 func (a *AvgBookSize) IsReady() bool {
-	return len(a.bookSizes) >= a.period
+	return a.bookSizes.len() >= a.period
 }
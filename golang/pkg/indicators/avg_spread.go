@@ -9,7 +9,7 @@ import (
 type AvgSpread struct {
 	*BaseIndicator
 	period    int
-	spreads   []float64
+	spreads   window[float64]
 	sum       float64
 	avgValue  float64
 	spreadType string // "absolute", "percentage", or "bps" (basis points)
@@ -31,7 +31,7 @@ func NewAvgSpread(period int, spreadType string, maxHistory int) *AvgSpread {
 	return &AvgSpread{
 		BaseIndicator: NewBaseIndicator("AvgSpread", maxHistory),
 		period:        period,
-		spreads:       make([]float64, 0, period),
+		spreads:       newWindow[float64](period),
 		spreadType:    spreadType,
 	}
 }
@@ -94,16 +94,16 @@ func (a *AvgSpread) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	a.sum += spread
-	a.spreads = append(a.spreads, spread)
+	a.spreads.push(spread)
 
-	if len(a.spreads) > a.period {
-		oldest := a.spreads[0]
-		a.spreads = a.spreads[1:]
+	if a.spreads.len() > a.period {
+		oldest := a.spreads.at(0)
+		a.spreads.dropOldest()
 		a.sum -= oldest
 	}
 
-	if len(a.spreads) > 0 {
-		a.avgValue = a.sum / float64(len(a.spreads))
+	if a.spreads.len() > 0 {
+		a.avgValue = a.sum / float64(a.spreads.len())
 		a.AddValue(a.avgValue)
 	}
 }
@@ -126,20 +126,20 @@ func (a *AvgSpread) GetPeriod() int {
 // Reset resets the indicator
 func (a *AvgSpread) Reset() {
 	a.BaseIndicator.Reset()
-	a.spreads = a.spreads[:0]
+	a.spreads.reset()
 	a.sum = 0
 	a.avgValue = 0
 }
 
 // IsReady returns true if we have at least one full period
 func (a *AvgSpread) IsReady() bool {
-	return len(a.spreads) >= a.period
+	return a.spreads.len() >= a.period
 }
 
 // GetCurrentSpread returns the most recent spread value (before averaging)
 func (a *AvgSpread) GetCurrentSpread() float64 {
-	if len(a.spreads) == 0 {
+	if a.spreads.len() == 0 {
 		return 0.0
 	}
-	return a.spreads[len(a.spreads)-1]
+	return a.spreads.at(a.spreads.len()-1)
 }
@@ -12,7 +12,7 @@ type BollingerBands struct {
 	*BaseIndicator
 	period      int
 	stdDevMult  float64
-	prices      []float64
+	prices      window[float64]
 	sma         float64
 	upperBand   float64
 	middleBand  float64
@@ -26,13 +26,10 @@ type BollingerBands struct {
 func NewBollingerBands(period float64, stdDevMult float64, maxHistory int) *BollingerBands {
 	p := int(period)
 	return &BollingerBands{
-		BaseIndicator: &BaseIndicator{
-			name:       "BollingerBands",
-			maxHistory: maxHistory,
-		},
+		BaseIndicator: NewBaseIndicator("BollingerBands", maxHistory),
 		period:      p,
 		stdDevMult:  stdDevMult,
-		prices:      make([]float64, 0, p),
+		prices:      newWindow[float64](p),
 		initialized: false,
 	}
 }
@@ -46,15 +43,15 @@ func (bb *BollingerBands) Update(md *mdpb.MarketDataUpdate) {
 	price := (md.BidPrice[0] + md.AskPrice[0]) / 2.0
 
 	// Add new price
-	bb.prices = append(bb.prices, price)
+	bb.prices.push(price)
 
 	// Remove oldest price if we exceed the period
-	if len(bb.prices) > bb.period {
-		bb.prices = bb.prices[1:]
+	if bb.prices.len() > bb.period {
+		bb.prices.dropOldest()
 	}
 
 	// Calculate only when we have enough data
-	if len(bb.prices) == bb.period {
+	if bb.prices.len() == bb.period {
 		bb.initialized = true
 		bb.calculate()
 	}
@@ -64,7 +61,7 @@ func (bb *BollingerBands) Update(md *mdpb.MarketDataUpdate) {
 func (bb *BollingerBands) calculate() {
 	// Calculate SMA (middle band)
 	sum := 0.0
-	for _, price := range bb.prices {
+	for _, price := range bb.prices.values() {
 		sum += price
 	}
 	bb.sma = sum / float64(bb.period)
@@ -72,7 +69,7 @@ func (bb *BollingerBands) calculate() {
 
 	// Calculate standard deviation
 	variance := 0.0
-	for _, price := range bb.prices {
+	for _, price := range bb.prices.values() {
 		diff := price - bb.sma
 		variance += diff * diff
 	}
@@ -89,7 +86,7 @@ func (bb *BollingerBands) calculate() {
 	}
 
 	// Calculate %B (position within bands)
-	currentPrice := bb.prices[len(bb.prices)-1]
+	currentPrice := bb.prices.at(bb.prices.len()-1)
 	bandRange := bb.upperBand - bb.lowerBand
 	if bandRange != 0 {
 		bb.percentB = (currentPrice - bb.lowerBand) / bandRange
@@ -139,12 +136,12 @@ func (bb *BollingerBands) GetPercentB() float64 {
 
 // IsReady returns true if the indicator has enough data
 func (bb *BollingerBands) IsReady() bool {
-	return bb.initialized && len(bb.prices) == bb.period
+	return bb.initialized && bb.prices.len() == bb.period
 }
 
 // Reset resets the indicator state
 func (bb *BollingerBands) Reset() {
-	bb.prices = make([]float64, 0, bb.period)
+	bb.prices.reset()
 	bb.sma = 0
 	bb.upperBand = 0
 	bb.middleBand = 0
@@ -167,19 +164,19 @@ func (bb *BollingerBands) GetStdDevMult() float64 {
 
 // IsOverbought returns true if price is above upper band
 func (bb *BollingerBands) IsOverbought() bool {
-	if !bb.IsReady() || len(bb.prices) == 0 {
+	if !bb.IsReady() || bb.prices.len() == 0 {
 		return false
 	}
-	currentPrice := bb.prices[len(bb.prices)-1]
+	currentPrice := bb.prices.at(bb.prices.len()-1)
 	return currentPrice > bb.upperBand
 }
 
 // IsOversold returns true if price is below lower band
 func (bb *BollingerBands) IsOversold() bool {
-	if !bb.IsReady() || len(bb.prices) == 0 {
+	if !bb.IsReady() || bb.prices.len() == 0 {
 		return false
 	}
-	currentPrice := bb.prices[len(bb.prices)-1]
+	currentPrice := bb.prices.at(bb.prices.len()-1)
 	return currentPrice < bb.lowerBand
 }
 
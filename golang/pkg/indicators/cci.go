@@ -28,7 +28,7 @@ import (
 type CCI struct {
 	*BaseIndicator
 	period            int
-	typicalPrices     []float64
+	typicalPrices     window[float64]
 	cci               float64
 	constant          float64 // Usually 0.015
 }
@@ -45,7 +45,7 @@ func NewCCI(period int, maxHistory int) *CCI {
 	return &CCI{
 		BaseIndicator: NewBaseIndicator("CCI", maxHistory),
 		period:        period,
-		typicalPrices: make([]float64, 0, period),
+		typicalPrices: newWindow[float64](period),
 		constant:      0.015,
 	}
 }
@@ -90,21 +90,21 @@ func (c *CCI) Update(md *mdpb.MarketDataUpdate) {
 	typicalPrice := (high + low + close) / 3.0
 
 	// Add to window
-	c.typicalPrices = append(c.typicalPrices, typicalPrice)
+	c.typicalPrices.push(typicalPrice)
 
 	// Keep only period elements
-	if len(c.typicalPrices) > c.period {
-		c.typicalPrices = c.typicalPrices[1:]
+	if c.typicalPrices.len() > c.period {
+		c.typicalPrices.dropOldest()
 	}
 
 	// Need at least period values
-	if len(c.typicalPrices) < c.period {
+	if c.typicalPrices.len() < c.period {
 		return
 	}
 
 	// Calculate SMA of typical prices
 	sum := 0.0
-	for _, tp := range c.typicalPrices {
+	for _, tp := range c.typicalPrices.values() {
 		sum += tp
 	}
 	smaTp := sum / float64(c.period)
@@ -112,14 +112,14 @@ func (c *CCI) Update(md *mdpb.MarketDataUpdate) {
 	// Calculate Mean Deviation
 	// Mean Deviation = Average of |TP - SMA(TP)|
 	sumDeviation := 0.0
-	for _, tp := range c.typicalPrices {
+	for _, tp := range c.typicalPrices.values() {
 		sumDeviation += math.Abs(tp - smaTp)
 	}
 	meanDeviation := sumDeviation / float64(c.period)
 
 	// Calculate CCI
 	// CCI = (TP - SMA(TP)) / (constant × Mean Deviation)
-	currentTp := c.typicalPrices[len(c.typicalPrices)-1]
+	currentTp := c.typicalPrices.at(c.typicalPrices.len()-1)
 
 	if meanDeviation == 0 {
 		c.cci = 0.0 // No deviation, CCI is 0
@@ -138,13 +138,13 @@ func (c *CCI) GetValue() float64 {
 // Reset resets the indicator
 func (c *CCI) Reset() {
 	c.BaseIndicator.Reset()
-	c.typicalPrices = c.typicalPrices[:0]
+	c.typicalPrices.reset()
 	c.cci = 0
 }
 
 // IsReady returns true if the indicator has enough data
 func (c *CCI) IsReady() bool {
-	return len(c.typicalPrices) >= c.period
+	return c.typicalPrices.len() >= c.period
 }
 
 // GetPeriod returns the period
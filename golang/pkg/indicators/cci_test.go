@@ -230,7 +230,7 @@ func TestCCI_Reset(t *testing.T) {
 		t.Errorf("CCI value should be 0 after reset, got %.2f", cci.GetValue())
 	}
 
-	if len(cci.typicalPrices) != 0 {
+	if cci.typicalPrices.len() != 0 {
 		t.Error("Typical prices window should be empty after reset")
 	}
 }
@@ -29,9 +29,9 @@ import (
 type CMO struct {
 	*BaseIndicator
 	period       int
-	prices       []float64
-	upChanges    []float64
-	downChanges  []float64
+	prices       window[float64]
+	upChanges    window[float64]
+	downChanges  window[float64]
 	cmo          float64
 	prevCMO      float64
 }
@@ -48,9 +48,9 @@ func NewCMO(period int, maxHistory int) *CMO {
 	return &CMO{
 		BaseIndicator: NewBaseIndicator("CMO", maxHistory),
 		period:        period,
-		prices:        make([]float64, 0, period+1),
-		upChanges:     make([]float64, 0, period),
-		downChanges:   make([]float64, 0, period),
+		prices:        newWindow[float64](period+1),
+		upChanges:     newWindow[float64](period),
+		downChanges:   newWindow[float64](period),
 	}
 }
 
@@ -82,41 +82,41 @@ func (c *CMO) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add current price
-	c.prices = append(c.prices, price)
+	c.prices.push(price)
 
 	// Keep period+1 prices (need previous price to calculate change)
-	if len(c.prices) > c.period+1 {
-		c.prices = c.prices[1:]
+	if c.prices.len() > c.period+1 {
+		c.prices.dropOldest()
 	}
 
 	// Need at least 2 prices to calculate change
-	if len(c.prices) < 2 {
+	if c.prices.len() < 2 {
 		return
 	}
 
 	// Calculate price change
-	change := c.prices[len(c.prices)-1] - c.prices[len(c.prices)-2]
+	change := c.prices.at(c.prices.len()-1) - c.prices.at(c.prices.len()-2)
 
 	// Store upward and downward changes
 	if change > 0 {
-		c.upChanges = append(c.upChanges, change)
-		c.downChanges = append(c.downChanges, 0.0)
+		c.upChanges.push(change)
+		c.downChanges.push(0.0)
 	} else if change < 0 {
-		c.upChanges = append(c.upChanges, 0.0)
-		c.downChanges = append(c.downChanges, -change) // Store as positive
+		c.upChanges.push(0.0)
+		c.downChanges.push(-change) // Store as positive
 	} else {
-		c.upChanges = append(c.upChanges, 0.0)
-		c.downChanges = append(c.downChanges, 0.0)
+		c.upChanges.push(0.0)
+		c.downChanges.push(0.0)
 	}
 
 	// Keep only period elements
-	if len(c.upChanges) > c.period {
-		c.upChanges = c.upChanges[1:]
-		c.downChanges = c.downChanges[1:]
+	if c.upChanges.len() > c.period {
+		c.upChanges.dropOldest()
+		c.downChanges.dropOldest()
 	}
 
 	// Need at least period values
-	if len(c.upChanges) < c.period {
+	if c.upChanges.len() < c.period {
 		return
 	}
 
@@ -125,8 +125,8 @@ func (c *CMO) Update(md *mdpb.MarketDataUpdate) {
 	sumDown := 0.0
 
 	for i := 0; i < c.period; i++ {
-		sumUp += c.upChanges[i]
-		sumDown += c.downChanges[i]
+		sumUp += c.upChanges.at(i)
+		sumDown += c.downChanges.at(i)
 	}
 
 	// Calculate CMO
@@ -152,16 +152,16 @@ func (c *CMO) GetValue() float64 {
 // Reset resets the indicator
 func (c *CMO) Reset() {
 	c.BaseIndicator.Reset()
-	c.prices = c.prices[:0]
-	c.upChanges = c.upChanges[:0]
-	c.downChanges = c.downChanges[:0]
+	c.prices.reset()
+	c.upChanges.reset()
+	c.downChanges.reset()
 	c.cmo = 0
 	c.prevCMO = 0
 }
 
 // IsReady returns true if the indicator has enough data
 func (c *CMO) IsReady() bool {
-	return len(c.upChanges) >= c.period
+	return c.upChanges.len() >= c.period
 }
 
 // GetPeriod returns the period
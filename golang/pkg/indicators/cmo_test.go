@@ -223,7 +223,7 @@ func TestCMO_Reset(t *testing.T) {
 		t.Errorf("CMO value should be 0 after reset, got %.2f", cmo.GetValue())
 	}
 
-	if len(cmo.prices) != 0 || len(cmo.upChanges) != 0 || len(cmo.downChanges) != 0 {
+	if cmo.prices.len() != 0 || cmo.upChanges.len() != 0 || cmo.downChanges.len() != 0 {
 		t.Error("All windows should be empty after reset")
 	}
 }
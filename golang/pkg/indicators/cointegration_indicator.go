@@ -13,13 +13,15 @@ import (
 type CointegrationIndicator struct {
 	*BaseIndicator
 	period      int
-	series1     []float64 // dependent variable (Y)
-	series2     []float64 // independent variable (X)
+	series1     window[float64] // dependent variable (Y)
+	series2     window[float64] // independent variable (X)
 	symbol2     string
 	lastValue   float64 // cointegration score (lower = more cointegrated)
 	priceCache  map[string]float64
 	beta        float64 // hedge ratio from linear regression
 	residuals   []float64
+	diffBuf     []float64 // scratch for half-life regression (Δy_t)
+	lagBuf      []float64 // scratch for half-life regression (y_{t-1})
 }
 
 // NewCointegrationIndicator creates a new Cointegration indicator
@@ -34,8 +36,8 @@ func NewCointegrationIndicator(period int, symbol2 string, maxHistory int) *Coin
 	return &CointegrationIndicator{
 		BaseIndicator: NewBaseIndicator("Cointegration", maxHistory),
 		period:        period,
-		series1:       make([]float64, 0, period),
-		series2:       make([]float64, 0, period),
+		series1:       newWindow[float64](period),
+		series2:       newWindow[float64](period),
 		symbol2:       symbol2,
 		priceCache:    make(map[string]float64),
 		residuals:     make([]float64, 0, period),
@@ -77,26 +79,26 @@ func (c *CointegrationIndicator) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	c.priceCache[md.Symbol] = price
-	c.series1 = append(c.series1, price)
-	if len(c.series1) > c.period {
-		c.series1 = c.series1[1:]
+	c.series1.push(price)
+	if c.series1.len() > c.period {
+		c.series1.dropOldest()
 	}
 
 	if c.symbol2 != "" {
 		if price2, ok := c.priceCache[c.symbol2]; ok {
-			c.series2 = append(c.series2, price2)
-			if len(c.series2) > c.period {
-				c.series2 = c.series2[1:]
+			c.series2.push(price2)
+			if c.series2.len() > c.period {
+				c.series2.dropOldest()
 			}
 		}
 	} else {
-		c.series2 = append(c.series2, price)
-		if len(c.series2) > c.period {
-			c.series2 = c.series2[1:]
+		c.series2.push(price)
+		if c.series2.len() > c.period {
+			c.series2.dropOldest()
 		}
 	}
 
-	if len(c.series1) >= c.period && len(c.series2) >= c.period {
+	if c.series1.len() >= c.period && c.series2.len() >= c.period {
 		c.calculateCointegration()
 	}
 }
@@ -107,31 +109,31 @@ func (c *CointegrationIndicator) UpdateWithPair(price1, price2 float64) {
 		return
 	}
 
-	c.series1 = append(c.series1, price1)
-	if len(c.series1) > c.period {
-		c.series1 = c.series1[1:]
+	c.series1.push(price1)
+	if c.series1.len() > c.period {
+		c.series1.dropOldest()
 	}
 
-	c.series2 = append(c.series2, price2)
-	if len(c.series2) > c.period {
-		c.series2 = c.series2[1:]
+	c.series2.push(price2)
+	if c.series2.len() > c.period {
+		c.series2.dropOldest()
 	}
 
-	if len(c.series1) >= c.period && len(c.series2) >= c.period {
+	if c.series1.len() >= c.period && c.series2.len() >= c.period {
 		c.calculateCointegration()
 	}
 }
 
 func (c *CointegrationIndicator) calculateCointegration() {
 	// Step 1: Perform linear regression Y = α + βX
-	slope, intercept := stats.LinearRegression(c.series2, c.series1)
+	slope, intercept := stats.LinearRegression(c.series2.values(), c.series1.values())
 	c.beta = slope
 
 	// Step 2: Calculate residuals
-	c.residuals = make([]float64, len(c.series1))
-	for i := range c.series1 {
-		predicted := intercept + slope*c.series2[i]
-		c.residuals[i] = c.series1[i] - predicted
+	c.residuals = c.residuals[:0]
+	for i := range c.series1.values() {
+		predicted := intercept + slope*c.series2.at(i)
+		c.residuals = append(c.residuals, c.series1.at(i)-predicted)
 	}
 
 	// Step 3: Simplified stationarity test
@@ -158,13 +160,14 @@ func (c *CointegrationIndicator) calculateHalfLife() float64 {
 
 	// Calculate first differences
 	n := len(c.residuals)
-	y := make([]float64, n-1)      // Δy_t
-	yLag := make([]float64, n-1)   // y_{t-1}
+	y := c.diffBuf[:0]     // Δy_t
+	yLag := c.lagBuf[:0]   // y_{t-1}
 
 	for i := 1; i < n; i++ {
-		y[i-1] = c.residuals[i] - c.residuals[i-1]  // Δy_t
-		yLag[i-1] = c.residuals[i-1]                // y_{t-1}
+		y = append(y, c.residuals[i]-c.residuals[i-1]) // Δy_t
+		yLag = append(yLag, c.residuals[i-1])          // y_{t-1}
 	}
+	c.diffBuf, c.lagBuf = y, yLag // keep scratch buffers for the next update
 
 	// Perform regression: Δy_t = λ * y_{t-1}
 	// This gives us λ (the mean reversion speed)
@@ -176,6 +179,11 @@ func (c *CointegrationIndicator) calculateHalfLife() float64 {
 		return math.Inf(1)
 	}
 
+	// λ <= -1: residuals fully revert (or overshoot) within one step
+	if lambda <= -1 {
+		return 0
+	}
+
 	// Half-life = -ln(2) / ln(1 + λ)
 	// For negative λ, (1 + λ) is between 0 and 1
 	halfLife := -math.Log(2) / math.Log(1+lambda)
@@ -221,8 +229,8 @@ func (c *CointegrationIndicator) IsCointegrated(threshold float64) bool {
 // Reset resets the indicator
 func (c *CointegrationIndicator) Reset() {
 	c.BaseIndicator.Reset()
-	c.series1 = c.series1[:0]
-	c.series2 = c.series2[:0]
+	c.series1.reset()
+	c.series2.reset()
 	c.residuals = c.residuals[:0]
 	c.priceCache = make(map[string]float64)
 	c.lastValue = 0
@@ -231,5 +239,5 @@ func (c *CointegrationIndicator) Reset() {
 
 // IsReady returns true if we have enough data
 func (c *CointegrationIndicator) IsReady() bool {
-	return len(c.series1) >= c.period && len(c.series2) >= c.period
+	return c.series1.len() >= c.period && c.series2.len() >= c.period
 }
@@ -10,8 +10,8 @@ import (
 type CorrelationIndicator struct {
 	*BaseIndicator
 	period      int
-	series1     []float64
-	series2     []float64
+	series1     window[float64]
+	series2     window[float64]
 	symbol2     string // symbol to correlate with
 	lastValue   float64
 	priceCache  map[string]float64 // cache latest prices by symbol
@@ -30,8 +30,8 @@ func NewCorrelationIndicator(period int, symbol2 string, maxHistory int) *Correl
 	return &CorrelationIndicator{
 		BaseIndicator: NewBaseIndicator("Correlation", maxHistory),
 		period:        period,
-		series1:       make([]float64, 0, period),
-		series2:       make([]float64, 0, period),
+		series1:       newWindow[float64](period),
+		series2:       newWindow[float64](period),
 		symbol2:       symbol2,
 		priceCache:    make(map[string]float64),
 	}
@@ -75,35 +75,35 @@ func (c *CorrelationIndicator) Update(md *mdpb.MarketDataUpdate) {
 	c.priceCache[md.Symbol] = price
 
 	// Add to series1 (always add current symbol's price)
-	c.series1 = append(c.series1, price)
-	if len(c.series1) > c.period {
-		c.series1 = c.series1[1:]
+	c.series1.push(price)
+	if c.series1.len() > c.period {
+		c.series1.dropOldest()
 	}
 
 	// For series2, check if we have the other symbol's price
 	if c.symbol2 != "" {
 		// If correlating with another symbol, use cached price
 		if price2, ok := c.priceCache[c.symbol2]; ok {
-			c.series2 = append(c.series2, price2)
-			if len(c.series2) > c.period {
-				c.series2 = c.series2[1:]
+			c.series2.push(price2)
+			if c.series2.len() > c.period {
+				c.series2.dropOldest()
 			}
 		}
 	} else {
 		// Self-correlation: use same series
-		c.series2 = append(c.series2, price)
-		if len(c.series2) > c.period {
-			c.series2 = c.series2[1:]
+		c.series2.push(price)
+		if c.series2.len() > c.period {
+			c.series2.dropOldest()
 		}
 	}
 
 	// Need at least period data points for both series
-	if len(c.series1) < c.period || len(c.series2) < c.period {
+	if c.series1.len() < c.period || c.series2.len() < c.period {
 		return
 	}
 
 	// Calculate correlation using stats package
-	corr := stats.Correlation(c.series1, c.series2)
+	corr := stats.Correlation(c.series1.values(), c.series2.values())
 	c.lastValue = corr
 	c.AddValue(corr)
 }
@@ -114,18 +114,18 @@ func (c *CorrelationIndicator) UpdateWithPair(price1, price2 float64) {
 		return
 	}
 
-	c.series1 = append(c.series1, price1)
-	if len(c.series1) > c.period {
-		c.series1 = c.series1[1:]
+	c.series1.push(price1)
+	if c.series1.len() > c.period {
+		c.series1.dropOldest()
 	}
 
-	c.series2 = append(c.series2, price2)
-	if len(c.series2) > c.period {
-		c.series2 = c.series2[1:]
+	c.series2.push(price2)
+	if c.series2.len() > c.period {
+		c.series2.dropOldest()
 	}
 
-	if len(c.series1) >= c.period && len(c.series2) >= c.period {
-		corr := stats.Correlation(c.series1, c.series2)
+	if c.series1.len() >= c.period && c.series2.len() >= c.period {
+		corr := stats.Correlation(c.series1.values(), c.series2.values())
 		c.lastValue = corr
 		c.AddValue(corr)
 	}
@@ -144,13 +144,13 @@ func (c *CorrelationIndicator) GetPeriod() int {
 // Reset resets the indicator
 func (c *CorrelationIndicator) Reset() {
 	c.BaseIndicator.Reset()
-	c.series1 = c.series1[:0]
-	c.series2 = c.series2[:0]
+	c.series1.reset()
+	c.series2.reset()
 	c.priceCache = make(map[string]float64)
 	c.lastValue = 0
 }
 
 // IsReady returns true if we have enough data
 func (c *CorrelationIndicator) IsReady() bool {
-	return len(c.series1) >= c.period && len(c.series2) >= c.period
+	return c.series1.len() >= c.period && c.series2.len() >= c.period
 }
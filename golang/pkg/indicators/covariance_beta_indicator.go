@@ -9,8 +9,8 @@ import (
 type CovarianceIndicator struct {
 	*BaseIndicator
 	period      int
-	series1     []float64
-	series2     []float64
+	series1     window[float64]
+	series2     window[float64]
 	symbol2     string
 	lastValue   float64
 	priceCache  map[string]float64
@@ -28,8 +28,8 @@ func NewCovarianceIndicator(period int, symbol2 string, maxHistory int) *Covaria
 	return &CovarianceIndicator{
 		BaseIndicator: NewBaseIndicator("Covariance", maxHistory),
 		period:        period,
-		series1:       make([]float64, 0, period),
-		series2:       make([]float64, 0, period),
+		series1:       newWindow[float64](period),
+		series2:       newWindow[float64](period),
 		symbol2:       symbol2,
 		priceCache:    make(map[string]float64),
 	}
@@ -70,27 +70,27 @@ func (c *CovarianceIndicator) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	c.priceCache[md.Symbol] = price
-	c.series1 = append(c.series1, price)
-	if len(c.series1) > c.period {
-		c.series1 = c.series1[1:]
+	c.series1.push(price)
+	if c.series1.len() > c.period {
+		c.series1.dropOldest()
 	}
 
 	if c.symbol2 != "" {
 		if price2, ok := c.priceCache[c.symbol2]; ok {
-			c.series2 = append(c.series2, price2)
-			if len(c.series2) > c.period {
-				c.series2 = c.series2[1:]
+			c.series2.push(price2)
+			if c.series2.len() > c.period {
+				c.series2.dropOldest()
 			}
 		}
 	} else {
-		c.series2 = append(c.series2, price)
-		if len(c.series2) > c.period {
-			c.series2 = c.series2[1:]
+		c.series2.push(price)
+		if c.series2.len() > c.period {
+			c.series2.dropOldest()
 		}
 	}
 
-	if len(c.series1) >= c.period && len(c.series2) >= c.period {
-		cov := stats.Covariance(c.series1, c.series2)
+	if c.series1.len() >= c.period && c.series2.len() >= c.period {
+		cov := stats.Covariance(c.series1.values(), c.series2.values())
 		c.lastValue = cov
 		c.AddValue(cov)
 	}
@@ -102,18 +102,18 @@ func (c *CovarianceIndicator) UpdateWithPair(price1, price2 float64) {
 		return
 	}
 
-	c.series1 = append(c.series1, price1)
-	if len(c.series1) > c.period {
-		c.series1 = c.series1[1:]
+	c.series1.push(price1)
+	if c.series1.len() > c.period {
+		c.series1.dropOldest()
 	}
 
-	c.series2 = append(c.series2, price2)
-	if len(c.series2) > c.period {
-		c.series2 = c.series2[1:]
+	c.series2.push(price2)
+	if c.series2.len() > c.period {
+		c.series2.dropOldest()
 	}
 
-	if len(c.series1) >= c.period && len(c.series2) >= c.period {
-		cov := stats.Covariance(c.series1, c.series2)
+	if c.series1.len() >= c.period && c.series2.len() >= c.period {
+		cov := stats.Covariance(c.series1.values(), c.series2.values())
 		c.lastValue = cov
 		c.AddValue(cov)
 	}
@@ -127,23 +127,23 @@ func (c *CovarianceIndicator) GetValue() float64 {
 // Reset resets the indicator
 func (c *CovarianceIndicator) Reset() {
 	c.BaseIndicator.Reset()
-	c.series1 = c.series1[:0]
-	c.series2 = c.series2[:0]
+	c.series1.reset()
+	c.series2.reset()
 	c.priceCache = make(map[string]float64)
 	c.lastValue = 0
 }
 
 // IsReady returns true if we have enough data
 func (c *CovarianceIndicator) IsReady() bool {
-	return len(c.series1) >= c.period && len(c.series2) >= c.period
+	return c.series1.len() >= c.period && c.series2.len() >= c.period
 }
 
 // BetaIndicator calculates rolling beta coefficient (hedge ratio)
 type BetaIndicator struct {
 	*BaseIndicator
 	period      int
-	series1     []float64 // dependent variable (Y)
-	series2     []float64 // independent variable (X)
+	series1     window[float64] // dependent variable (Y)
+	series2     window[float64] // independent variable (X)
 	symbol2     string
 	lastValue   float64
 	priceCache  map[string]float64
@@ -161,8 +161,8 @@ func NewBetaIndicator(period int, symbol2 string, maxHistory int) *BetaIndicator
 	return &BetaIndicator{
 		BaseIndicator: NewBaseIndicator("Beta", maxHistory),
 		period:        period,
-		series1:       make([]float64, 0, period),
-		series2:       make([]float64, 0, period),
+		series1:       newWindow[float64](period),
+		series2:       newWindow[float64](period),
 		symbol2:       symbol2,
 		priceCache:    make(map[string]float64),
 	}
@@ -203,27 +203,27 @@ func (b *BetaIndicator) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	b.priceCache[md.Symbol] = price
-	b.series1 = append(b.series1, price)
-	if len(b.series1) > b.period {
-		b.series1 = b.series1[1:]
+	b.series1.push(price)
+	if b.series1.len() > b.period {
+		b.series1.dropOldest()
 	}
 
 	if b.symbol2 != "" {
 		if price2, ok := b.priceCache[b.symbol2]; ok {
-			b.series2 = append(b.series2, price2)
-			if len(b.series2) > b.period {
-				b.series2 = b.series2[1:]
+			b.series2.push(price2)
+			if b.series2.len() > b.period {
+				b.series2.dropOldest()
 			}
 		}
 	} else {
-		b.series2 = append(b.series2, price)
-		if len(b.series2) > b.period {
-			b.series2 = b.series2[1:]
+		b.series2.push(price)
+		if b.series2.len() > b.period {
+			b.series2.dropOldest()
 		}
 	}
 
-	if len(b.series1) >= b.period && len(b.series2) >= b.period {
-		beta := stats.Beta(b.series1, b.series2)
+	if b.series1.len() >= b.period && b.series2.len() >= b.period {
+		beta := stats.Beta(b.series1.values(), b.series2.values())
 		b.lastValue = beta
 		b.AddValue(beta)
 	}
@@ -235,18 +235,18 @@ func (b *BetaIndicator) UpdateWithPair(price1, price2 float64) {
 		return
 	}
 
-	b.series1 = append(b.series1, price1)
-	if len(b.series1) > b.period {
-		b.series1 = b.series1[1:]
+	b.series1.push(price1)
+	if b.series1.len() > b.period {
+		b.series1.dropOldest()
 	}
 
-	b.series2 = append(b.series2, price2)
-	if len(b.series2) > b.period {
-		b.series2 = b.series2[1:]
+	b.series2.push(price2)
+	if b.series2.len() > b.period {
+		b.series2.dropOldest()
 	}
 
-	if len(b.series1) >= b.period && len(b.series2) >= b.period {
-		beta := stats.Beta(b.series1, b.series2)
+	if b.series1.len() >= b.period && b.series2.len() >= b.period {
+		beta := stats.Beta(b.series1.values(), b.series2.values())
 		b.lastValue = beta
 		b.AddValue(beta)
 	}
@@ -260,13 +260,13 @@ func (b *BetaIndicator) GetValue() float64 {
 // Reset resets the indicator
 func (b *BetaIndicator) Reset() {
 	b.BaseIndicator.Reset()
-	b.series1 = b.series1[:0]
-	b.series2 = b.series2[:0]
+	b.series1.reset()
+	b.series2.reset()
 	b.priceCache = make(map[string]float64)
 	b.lastValue = 0
 }
 
 // IsReady returns true if we have enough data
 func (b *BetaIndicator) IsReady() bool {
-	return len(b.series1) >= b.period && len(b.series2) >= b.period
+	return b.series1.len() >= b.period && b.series2.len() >= b.period
 }
@@ -0,0 +1,134 @@
+package indicators
+
+import (
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+
+	"tbsrc-golang/pkg/regime"
+)
+
+// CUSUM is a two-sided cumulative-sum change-point detector
+// 累积和变点检测：识别序列均值的结构性突变（例如价差均值漂移）
+//
+// Observations are standardized against an EWMA reference mean/std:
+//
+//	z_t  = (x_t - mean) / std
+//	S+_t = max(0, S+_{t-1} + z_t - drift)
+//	S-_t = max(0, S-_{t-1} - z_t - drift)
+//
+// A change is signalled when S+ or S- exceeds threshold; the sums are then
+// cleared and the reference mean re-anchored to the new level.
+//
+// The value is (S+ - S-) / threshold, so it reaches ±1 at an alarm.
+//
+// The detector itself is tbsrc-golang/pkg/regime.CUSUM, shared with the tbsrc
+// PairwiseArb regime detector.
+type CUSUM struct {
+	*BaseIndicator
+	c *regime.CUSUM
+}
+
+// NewCUSUM creates a new CUSUM change-point detector
+func NewCUSUM(name string, threshold, drift float64, period int, maxHistory int) *CUSUM {
+	return &CUSUM{
+		BaseIndicator: NewBaseIndicator(name, maxHistory),
+		c:             regime.NewCUSUM(threshold, drift, period),
+	}
+}
+
+// NewCUSUMFromConfig creates a CUSUM from configuration
+func NewCUSUMFromConfig(config map[string]interface{}) (Indicator, error) {
+	name := "CUSUM"
+	if v, ok := config["name"]; ok {
+		if s, ok := v.(string); ok {
+			name = s
+		}
+	}
+
+	threshold := 5.0
+	if v, ok := config["threshold"]; ok {
+		if f, ok := v.(float64); ok {
+			threshold = f
+		}
+	}
+
+	drift := 0.5
+	if v, ok := config["drift"]; ok {
+		if f, ok := v.(float64); ok {
+			drift = f
+		}
+	}
+
+	period := 100
+	if v, ok := config["period"]; ok {
+		if f, ok := v.(float64); ok {
+			period = int(f)
+		}
+	}
+
+	maxHistory := 1000
+	if v, ok := config["max_history"]; ok {
+		if f, ok := v.(float64); ok {
+			maxHistory = int(f)
+		}
+	}
+
+	return NewCUSUM(name, threshold, drift, period, maxHistory), nil
+}
+
+// Update feeds the mid price
+func (c *CUSUM) Update(md *mdpb.MarketDataUpdate) {
+	mid := GetMidPrice(md)
+	if mid <= 0 {
+		return
+	}
+	c.UpdateWithValue(mid)
+}
+
+// UpdateWithValue feeds one observation of the monitored series
+func (c *CUSUM) UpdateWithValue(x float64) {
+	c.c.Update(x)
+	if c.c.Ready() {
+		c.AddValue(c.c.Value())
+	}
+}
+
+// GetValue returns (S+ - S-) / threshold, or ±1 on the observation that signalled
+func (c *CUSUM) GetValue() float64 {
+	return c.c.Value()
+}
+
+// Signal returns +1/-1 if a change was detected on the latest observation, else 0
+func (c *CUSUM) Signal() int {
+	return c.c.Signal()
+}
+
+// LastChange returns the direction of the most recent change (0 if none yet)
+func (c *CUSUM) LastChange() int {
+	return c.c.LastChange()
+}
+
+// BarsSinceChange returns observations since the last change, or -1 if none
+func (c *CUSUM) BarsSinceChange() int {
+	return c.c.BarsSinceChange()
+}
+
+// ChangedWithin returns true if a change was detected in the last n observations
+func (c *CUSUM) ChangedWithin(n int) bool {
+	return c.c.ChangedWithin(n)
+}
+
+// GetSums returns the current positive and negative cumulative sums
+func (c *CUSUM) GetSums() (float64, float64) {
+	return c.c.Sums()
+}
+
+// Reset resets the indicator
+func (c *CUSUM) Reset() {
+	c.BaseIndicator.Reset()
+	c.c.Reset()
+}
+
+// IsReady returns true once the reference window is filled
+func (c *CUSUM) IsReady() bool {
+	return c.c.Ready()
+}
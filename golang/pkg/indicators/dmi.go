@@ -35,12 +35,12 @@ import (
 type DMI struct {
 	*BaseIndicator
 	period          int
-	highs           []float64
-	lows            []float64
-	closes          []float64
-	trueRanges      []float64
-	plusDM          []float64
-	minusDM         []float64
+	highs           window[float64]
+	lows            window[float64]
+	closes          window[float64]
+	trueRanges      window[float64]
+	plusDM          window[float64]
+	minusDM         window[float64]
 	smoothedTR      float64
 	smoothedPlusDM  float64
 	smoothedMinusDM float64
@@ -63,12 +63,12 @@ func NewDMI(period int, maxHistory int) *DMI {
 	return &DMI{
 		BaseIndicator: NewBaseIndicator("DMI", maxHistory),
 		period:        period,
-		highs:         make([]float64, 0, period+1),
-		lows:          make([]float64, 0, period+1),
-		closes:        make([]float64, 0, period+1),
-		trueRanges:    make([]float64, 0, period),
-		plusDM:        make([]float64, 0, period),
-		minusDM:       make([]float64, 0, period),
+		highs:         newWindow[float64](period+1),
+		lows:          newWindow[float64](period+1),
+		closes:        newWindow[float64](period+1),
+		trueRanges:    newWindow[float64](period),
+		plusDM:        newWindow[float64](period),
+		minusDM:       newWindow[float64](period),
 		isFirstSmooth: true,
 	}
 }
@@ -110,29 +110,29 @@ func (d *DMI) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store prices
-	d.highs = append(d.highs, high)
-	d.lows = append(d.lows, low)
-	d.closes = append(d.closes, close)
+	d.highs.push(high)
+	d.lows.push(low)
+	d.closes.push(close)
 
 	// Keep period+1 for calculations
-	if len(d.highs) > d.period+1 {
-		d.highs = d.highs[1:]
-		d.lows = d.lows[1:]
-		d.closes = d.closes[1:]
+	if d.highs.len() > d.period+1 {
+		d.highs.dropOldest()
+		d.lows.dropOldest()
+		d.closes.dropOldest()
 	}
 
 	// Need at least 2 bars
-	if len(d.highs) < 2 {
+	if d.highs.len() < 2 {
 		return
 	}
 
 	// Calculate True Range (TR)
-	prevClose := d.closes[len(d.closes)-2]
+	prevClose := d.closes.at(d.closes.len()-2)
 	tr := math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
 
 	// Calculate +DM and -DM
-	prevHigh := d.highs[len(d.highs)-2]
-	prevLow := d.lows[len(d.lows)-2]
+	prevHigh := d.highs.at(d.highs.len()-2)
+	prevLow := d.lows.at(d.lows.len()-2)
 
 	upMove := high - prevHigh
 	downMove := prevLow - low
@@ -147,19 +147,19 @@ func (d *DMI) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store values
-	d.trueRanges = append(d.trueRanges, tr)
-	d.plusDM = append(d.plusDM, plusDMVal)
-	d.minusDM = append(d.minusDM, minusDMVal)
+	d.trueRanges.push(tr)
+	d.plusDM.push(plusDMVal)
+	d.minusDM.push(minusDMVal)
 
 	// Keep only period values
-	if len(d.trueRanges) > d.period {
-		d.trueRanges = d.trueRanges[1:]
-		d.plusDM = d.plusDM[1:]
-		d.minusDM = d.minusDM[1:]
+	if d.trueRanges.len() > d.period {
+		d.trueRanges.dropOldest()
+		d.plusDM.dropOldest()
+		d.minusDM.dropOldest()
 	}
 
 	// Need at least period values for initial smoothing
-	if len(d.trueRanges) < d.period {
+	if d.trueRanges.len() < d.period {
 		return
 	}
 
@@ -171,9 +171,9 @@ func (d *DMI) Update(md *mdpb.MarketDataUpdate) {
 		d.smoothedMinusDM = 0
 
 		for i := 0; i < d.period; i++ {
-			d.smoothedTR += d.trueRanges[i]
-			d.smoothedPlusDM += d.plusDM[i]
-			d.smoothedMinusDM += d.minusDM[i]
+			d.smoothedTR += d.trueRanges.at(i)
+			d.smoothedPlusDM += d.plusDM.at(i)
+			d.smoothedMinusDM += d.minusDM.at(i)
 		}
 
 		d.isFirstSmooth = false
@@ -219,12 +219,12 @@ func (d *DMI) GetMinusDI() float64 {
 // Reset resets the indicator
 func (d *DMI) Reset() {
 	d.BaseIndicator.Reset()
-	d.highs = d.highs[:0]
-	d.lows = d.lows[:0]
-	d.closes = d.closes[:0]
-	d.trueRanges = d.trueRanges[:0]
-	d.plusDM = d.plusDM[:0]
-	d.minusDM = d.minusDM[:0]
+	d.highs.reset()
+	d.lows.reset()
+	d.closes.reset()
+	d.trueRanges.reset()
+	d.plusDM.reset()
+	d.minusDM.reset()
 	d.smoothedTR = 0
 	d.smoothedPlusDM = 0
 	d.smoothedMinusDM = 0
@@ -237,7 +237,7 @@ func (d *DMI) Reset() {
 
 // IsReady returns true if the indicator has enough data
 func (d *DMI) IsReady() bool {
-	return len(d.trueRanges) >= d.period && !d.isFirstSmooth
+	return d.trueRanges.len() >= d.period && !d.isFirstSmooth
 }
 
 // GetPeriod returns the period
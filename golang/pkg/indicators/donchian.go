@@ -29,8 +29,8 @@ import (
 type DonchianChannels struct {
 	*BaseIndicator
 	period       int
-	highs        []float64
-	lows         []float64
+	highs        window[float64]
+	lows         window[float64]
 	upperChannel float64
 	lowerChannel float64
 	middleLine   float64
@@ -48,8 +48,8 @@ func NewDonchianChannels(period int, maxHistory int) *DonchianChannels {
 	return &DonchianChannels{
 		BaseIndicator: NewBaseIndicator("Donchian Channels", maxHistory),
 		period:        period,
-		highs:         make([]float64, 0, period),
-		lows:          make([]float64, 0, period),
+		highs:         newWindow[float64](period),
+		lows:          newWindow[float64](period),
 	}
 }
 
@@ -91,33 +91,33 @@ func (d *DonchianChannels) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store high and low
-	d.highs = append(d.highs, high)
-	d.lows = append(d.lows, low)
+	d.highs.push(high)
+	d.lows.push(low)
 
 	// Keep only period values
-	if len(d.highs) > d.period {
-		d.highs = d.highs[1:]
-		d.lows = d.lows[1:]
+	if d.highs.len() > d.period {
+		d.highs.dropOldest()
+		d.lows.dropOldest()
 	}
 
 	// Need at least period values
-	if len(d.highs) < d.period {
+	if d.highs.len() < d.period {
 		return
 	}
 
 	// Calculate upper channel (highest high)
-	d.upperChannel = d.highs[0]
-	for i := 1; i < len(d.highs); i++ {
-		if d.highs[i] > d.upperChannel {
-			d.upperChannel = d.highs[i]
+	d.upperChannel = d.highs.at(0)
+	for i := 1; i < d.highs.len(); i++ {
+		if d.highs.at(i) > d.upperChannel {
+			d.upperChannel = d.highs.at(i)
 		}
 	}
 
 	// Calculate lower channel (lowest low)
-	d.lowerChannel = d.lows[0]
-	for i := 1; i < len(d.lows); i++ {
-		if d.lows[i] < d.lowerChannel {
-			d.lowerChannel = d.lows[i]
+	d.lowerChannel = d.lows.at(0)
+	for i := 1; i < d.lows.len(); i++ {
+		if d.lows.at(i) < d.lowerChannel {
+			d.lowerChannel = d.lows.at(i)
 		}
 	}
 
@@ -164,8 +164,8 @@ func (d *DonchianChannels) GetChannelWidthPercentage() float64 {
 // Reset resets the indicator
 func (d *DonchianChannels) Reset() {
 	d.BaseIndicator.Reset()
-	d.highs = d.highs[:0]
-	d.lows = d.lows[:0]
+	d.highs.reset()
+	d.lows.reset()
 	d.upperChannel = 0
 	d.lowerChannel = 0
 	d.middleLine = 0
@@ -173,7 +173,7 @@ func (d *DonchianChannels) Reset() {
 
 // IsReady returns true if the indicator has enough data
 func (d *DonchianChannels) IsReady() bool {
-	return len(d.highs) >= d.period
+	return d.highs.len() >= d.period
 }
 
 // GetPeriod returns the period
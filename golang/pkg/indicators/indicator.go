@@ -2,7 +2,10 @@
 package indicators
 
 import (
+	"math"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
@@ -30,20 +33,30 @@ type Indicator interface {
 }
 
 // BaseIndicator provides common functionality for indicators
+//
+// History is kept in a fixed ring buffer allocated once at construction, so
+// AddValue never allocates. The indicator is updated by a single writer (the
+// market data dispatch goroutine); readers (API, WebSocket, other strategies)
+// never take a lock and retry via a sequence counter if they race with a write.
 type BaseIndicator struct {
 	name       string
-	values     []float64
 	maxHistory int
-	mu         sync.RWMutex
-	initialized bool
+	ring       []uint64      // math.Float64bits of each value
+	count      atomic.Uint64 // total number of values written since Reset
+	last       atomic.Uint64 // math.Float64bits of the most recent value
+	seq        atomic.Uint64 // odd while a write is in progress
+	ready      atomic.Bool
 }
 
 // NewBaseIndicator creates a new base indicator
 func NewBaseIndicator(name string, maxHistory int) *BaseIndicator {
+	if maxHistory <= 0 {
+		maxHistory = 1
+	}
 	return &BaseIndicator{
 		name:       name,
-		values:     make([]float64, 0, maxHistory),
 		maxHistory: maxHistory,
+		ring:       make([]uint64, maxHistory),
 	}
 }
 
@@ -54,51 +67,108 @@ func (b *BaseIndicator) GetName() string {
 
 // GetValue returns the most recent value
 func (b *BaseIndicator) GetValue() float64 {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+	return math.Float64frombits(b.last.Load())
+}
 
-	if len(b.values) == 0 {
-		return 0.0
+// GetValues returns all historical values (oldest first).
+// It allocates a new slice; hot paths should use AppendValues with a reused buffer.
+func (b *BaseIndicator) GetValues() []float64 {
+	return b.AppendValues(make([]float64, 0, b.Len()))
+}
+
+// AppendValues appends the historical values (oldest first) to dst and returns
+// the extended slice. It does not allocate when dst has enough capacity.
+func (b *BaseIndicator) AppendValues(dst []float64) []float64 {
+	base := len(dst)
+	for {
+		s1 := b.seq.Load()
+		if s1&1 == 1 {
+			runtime.Gosched()
+			continue
+		}
+		dst = dst[:base]
+		n := b.count.Load()
+		size := uint64(len(b.ring))
+		start := uint64(0)
+		if n > size {
+			start = n - size
+		}
+		for i := start; i < n; i++ {
+			dst = append(dst, math.Float64frombits(atomic.LoadUint64(&b.ring[i%size])))
+		}
+		if b.seq.Load() == s1 {
+			return dst
+		}
 	}
-	return b.values[len(b.values)-1]
 }
 
-// GetValues returns all historical values
-func (b *BaseIndicator) GetValues() []float64 {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+// Len returns the number of values currently held in history
+func (b *BaseIndicator) Len() int {
+	n := b.count.Load()
+	if n > uint64(b.maxHistory) {
+		return b.maxHistory
+	}
+	return int(n)
+}
 
-	result := make([]float64, len(b.values))
-	copy(result, b.values)
-	return result
+// ValueAgo returns the value n steps back from the latest (0 = latest),
+// or 0 if history does not reach that far. Does not allocate.
+func (b *BaseIndicator) ValueAgo(n int) float64 {
+	for {
+		s1 := b.seq.Load()
+		if s1&1 == 1 {
+			runtime.Gosched()
+			continue
+		}
+		count := b.count.Load()
+		size := uint64(len(b.ring))
+		var v float64
+		if n >= 0 && uint64(n) < count && uint64(n) < size {
+			v = math.Float64frombits(atomic.LoadUint64(&b.ring[(count-1-uint64(n))%size]))
+		}
+		if b.seq.Load() == s1 {
+			return v
+		}
+	}
 }
 
-// AddValue adds a new value to the time series
+// AddValue adds a new value to the time series.
+// Must only be called from the indicator's single writer goroutine.
 func (b *BaseIndicator) AddValue(value float64) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	b.values = append(b.values, value)
-	if len(b.values) > b.maxHistory {
-		b.values = b.values[1:]
+	if b.ring == nil {
+		// Zero-value BaseIndicator: allocate once on first write
+		if b.maxHistory <= 0 {
+			b.maxHistory = 1
+		}
+		b.ring = make([]uint64, b.maxHistory)
 	}
-	b.initialized = true
+
+	bits := math.Float64bits(value)
+	n := b.count.Load()
+
+	b.seq.Add(1)
+	atomic.StoreUint64(&b.ring[n%uint64(len(b.ring))], bits)
+	b.count.Store(n + 1)
+	b.last.Store(bits)
+	b.seq.Add(1)
+
+	b.ready.Store(true)
 }
 
-// Reset clears all values
+// Reset clears all values.
+// Like AddValue it must only be called from the indicator's single writer
+// goroutine; IndicatorLibrary.ResetAll serializes with UpdateAll for this.
 func (b *BaseIndicator) Reset() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	b.values = b.values[:0]
-	b.initialized = false
+	b.seq.Add(1)
+	b.count.Store(0)
+	b.last.Store(0)
+	b.seq.Add(1)
+	b.ready.Store(false)
 }
 
 // IsReady returns true if initialized
 func (b *BaseIndicator) IsReady() bool {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-	return b.initialized
+	return b.ready.Load()
 }
 
 // GetMidPrice calculates the mid price from market data
@@ -139,7 +209,8 @@ func GetWeightedMidPrice(md *mdpb.MarketDataUpdate) float64 {
 type IndicatorLibrary struct {
 	indicators map[string]Indicator
 	factories  map[string]IndicatorFactory
-	mu         sync.RWMutex
+	mu         sync.RWMutex // guards the maps
+	writeMu    sync.Mutex   // held by UpdateAll/ResetAll: indicators have a single writer
 }
 
 // IndicatorFactory creates indicators with configuration
@@ -267,6 +338,14 @@ func NewIndicatorLibrary() *IndicatorLibrary {
 	lib.RegisterFactory("price_channel", NewPriceChannelFromConfig)
 	lib.RegisterFactory("envelopes", NewEnvelopesFromConfig)
 
+	// Regime classification
+	lib.RegisterFactory("volatility_regime", NewVolatilityRegimeFromConfig)
+	lib.RegisterFactory("variance_ratio", NewVarianceRatioFromConfig)
+	lib.RegisterFactory("cusum", NewCUSUMFromConfig)
+
+	// Dynamic hedge ratio
+	lib.RegisterFactory("kalman_hedge_ratio", NewKalmanHedgeRatioFromConfig)
+
 	return lib
 }
 
@@ -309,6 +388,8 @@ func (lib *IndicatorLibrary) Get(name string) (Indicator, bool) {
 
 // UpdateAll updates all indicators with new market data
 func (lib *IndicatorLibrary) UpdateAll(md *mdpb.MarketDataUpdate) {
+	lib.writeMu.Lock()
+	defer lib.writeMu.Unlock()
 	lib.mu.RLock()
 	defer lib.mu.RUnlock()
 
@@ -317,8 +398,12 @@ func (lib *IndicatorLibrary) UpdateAll(md *mdpb.MarketDataUpdate) {
 	}
 }
 
-// ResetAll resets all indicators
+// ResetAll resets all indicators.
+// It takes the writer role like UpdateAll, so a reset never interleaves with an
+// update; lock-free readers retry through the BaseIndicator sequence counter.
 func (lib *IndicatorLibrary) ResetAll() {
+	lib.writeMu.Lock()
+	defer lib.writeMu.Unlock()
 	lib.mu.RLock()
 	defer lib.mu.RUnlock()
 
@@ -329,14 +414,22 @@ func (lib *IndicatorLibrary) ResetAll() {
 
 // GetAllValues returns current values of all indicators
 func (lib *IndicatorLibrary) GetAllValues() map[string]float64 {
+	result := make(map[string]float64)
+	lib.VisitValues(func(name string, value float64) {
+		result[name] = value
+	})
+	return result
+}
+
+// VisitValues calls fn with the current value of every indicator.
+// Unlike GetAllValues it does not allocate; fn must not call back into the library.
+func (lib *IndicatorLibrary) VisitValues(fn func(name string, value float64)) {
 	lib.mu.RLock()
 	defer lib.mu.RUnlock()
 
-	result := make(map[string]float64)
 	for name, indicator := range lib.indicators {
-		result[name] = indicator.GetValue()
+		fn(name, indicator.GetValue())
 	}
-	return result
 }
 
 // MarketDataSnapshot represents a point-in-time snapshot of market data
@@ -0,0 +1,210 @@
+package indicators
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestIndicatorUpdate_ZeroAlloc is the allocation regression test for the
+// market data hot path: once warmed up, Update must not allocate for any
+// registered indicator type.
+func TestIndicatorUpdate_ZeroAlloc(t *testing.T) {
+	lib := NewIndicatorLibrary()
+	mds := benchMarketData(1024)
+
+	for _, indicatorType := range registeredIndicatorTypes(lib) {
+		ind := newWarmIndicator(t, lib, indicatorType, mds)
+		i := 0
+		allocs := testing.AllocsPerRun(1000, func() {
+			ind.Update(mds[i%len(mds)])
+			i++
+		})
+		if allocs > 0 {
+			t.Errorf("%s: Update allocates %.1f times per call, expected 0", indicatorType, allocs)
+		}
+	}
+}
+
+func TestIndicatorLibrary_UpdateAllZeroAlloc(t *testing.T) {
+	lib := NewIndicatorLibrary()
+	mds := benchMarketData(1024)
+	for _, indicatorType := range registeredIndicatorTypes(lib) {
+		newWarmIndicator(t, lib, indicatorType, mds)
+	}
+
+	i := 0
+	allocs := testing.AllocsPerRun(200, func() {
+		lib.UpdateAll(mds[i%len(mds)])
+		i++
+	})
+	if allocs > 0 {
+		t.Errorf("UpdateAll allocates %.1f times per call, expected 0", allocs)
+	}
+
+	sum := 0.0
+	allocs = testing.AllocsPerRun(200, func() {
+		lib.VisitValues(func(name string, value float64) {
+			sum += value
+		})
+	})
+	if allocs > 0 {
+		t.Errorf("VisitValues allocates %.1f times per call, expected 0", allocs)
+	}
+}
+
+func TestBaseIndicator_RingBuffer(t *testing.T) {
+	b := NewBaseIndicator("ring", 3)
+
+	if b.IsReady() || b.Len() != 0 || b.GetValue() != 0 {
+		t.Fatal("New indicator should be empty")
+	}
+
+	for i := 1; i <= 5; i++ {
+		b.AddValue(float64(i))
+	}
+
+	if !b.IsReady() {
+		t.Error("Indicator should be ready after AddValue")
+	}
+	if b.Len() != 3 {
+		t.Errorf("Expected Len 3, got %d", b.Len())
+	}
+	if b.GetValue() != 5 {
+		t.Errorf("Expected last value 5, got %v", b.GetValue())
+	}
+
+	values := b.GetValues()
+	expected := []float64{3, 4, 5}
+	if len(values) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, values)
+	}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Errorf("values[%d] = %v, expected %v", i, values[i], expected[i])
+		}
+	}
+
+	if b.ValueAgo(0) != 5 || b.ValueAgo(2) != 3 {
+		t.Errorf("ValueAgo mismatch: %v %v", b.ValueAgo(0), b.ValueAgo(2))
+	}
+	if b.ValueAgo(3) != 0 || b.ValueAgo(-1) != 0 {
+		t.Error("ValueAgo outside history should return 0")
+	}
+
+	b.Reset()
+	if b.IsReady() || b.Len() != 0 || b.GetValue() != 0 || len(b.GetValues()) != 0 {
+		t.Error("Reset should clear history")
+	}
+
+	b.AddValue(7)
+	if values := b.GetValues(); len(values) != 1 || values[0] != 7 {
+		t.Errorf("Expected [7] after reset, got %v", values)
+	}
+}
+
+func TestBaseIndicator_ZeroValue(t *testing.T) {
+	b := &BaseIndicator{name: "zero"}
+	b.AddValue(1)
+	b.AddValue(2)
+	if b.GetValue() != 2 || b.Len() != 1 {
+		t.Errorf("Zero-value indicator should keep latest value, got %v (len %d)", b.GetValue(), b.Len())
+	}
+}
+
+func TestBaseIndicator_ZeroAlloc(t *testing.T) {
+	b := NewBaseIndicator("alloc", 100)
+	for i := 0; i < 150; i++ {
+		b.AddValue(float64(i))
+	}
+
+	v := 0.0
+	if allocs := testing.AllocsPerRun(1000, func() { b.AddValue(v); v++ }); allocs > 0 {
+		t.Errorf("AddValue allocates %.1f times per call", allocs)
+	}
+
+	buf := make([]float64, 0, 100)
+	if allocs := testing.AllocsPerRun(1000, func() { buf = b.AppendValues(buf[:0]) }); allocs > 0 {
+		t.Errorf("AppendValues allocates %.1f times per call", allocs)
+	}
+	if len(buf) != 100 {
+		t.Errorf("Expected 100 values, got %d", len(buf))
+	}
+
+	if allocs := testing.AllocsPerRun(1000, func() { _ = b.GetValue() + b.ValueAgo(10) }); allocs > 0 {
+		t.Errorf("GetValue/ValueAgo allocate %.1f times per call", allocs)
+	}
+}
+
+// TestBaseIndicator_ConcurrentReaders checks that lock-free readers always see
+// a consistent snapshot while a single writer keeps appending.
+func TestBaseIndicator_ConcurrentReaders(t *testing.T) {
+	b := NewBaseIndicator("concurrent", 64)
+	const writes = 20000
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]float64, 0, 64)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				buf = b.AppendValues(buf[:0])
+				// Writer appends 1, 2, 3, ... so any snapshot must be consecutive
+				for i := 1; i < len(buf); i++ {
+					if buf[i] != buf[i-1]+1 {
+						t.Errorf("Torn snapshot: %v followed by %v", buf[i-1], buf[i])
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= writes; i++ {
+		b.AddValue(float64(i))
+	}
+	close(done)
+	wg.Wait()
+
+	if b.GetValue() != writes {
+		t.Errorf("Expected last value %d, got %v", writes, b.GetValue())
+	}
+}
+
+// TestIndicatorLibrary_ResetAllDuringUpdates checks that ResetAll from another
+// goroutine never interleaves with UpdateAll (run with -race).
+func TestIndicatorLibrary_ResetAllDuringUpdates(t *testing.T) {
+	lib := NewIndicatorLibrary()
+	mds := benchMarketData(256)
+	for _, indicatorType := range []string{"sma", "ewma", "variance_ratio"} {
+		newWarmIndicator(t, lib, indicatorType, mds)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			lib.ResetAll()
+		}
+	}()
+	for i := 0; i < 2000; i++ {
+		lib.UpdateAll(mds[i%len(mds)])
+	}
+	wg.Wait()
+
+	for _, md := range mds {
+		lib.UpdateAll(md)
+	}
+	ind, _ := lib.Get("sma")
+	if !ind.IsReady() {
+		t.Error("sma should be ready after a full replay following the resets")
+	}
+}
@@ -0,0 +1,136 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+)
+
+// benchMarketData builds a deterministic random-walk order book sequence with
+// 5 levels and trades, so every indicator type sees realistic input.
+func benchMarketData(n int) []*mdpb.MarketDataUpdate {
+	mds := make([]*mdpb.MarketDataUpdate, n)
+	mid := 5000.0
+	volume := uint64(10000)
+	turnover := 0.0
+	seed := uint32(12345)
+	next := func() float64 {
+		// xorshift32 - stable across Go versions, unlike math/rand
+		seed ^= seed << 13
+		seed ^= seed >> 17
+		seed ^= seed << 5
+		return float64(seed)/float64(math.MaxUint32)*2 - 1
+	}
+
+	for i := 0; i < n; i++ {
+		mid += math.Round(next()*2) * 0.5
+		qty := uint32(1 + (i*7)%20)
+		volume += uint64(qty)
+		last := mid + 0.5*math.Copysign(1, next())
+		turnover += last * float64(qty)
+
+		md := &mdpb.MarketDataUpdate{
+			Symbol:      "ag2603",
+			Exchange:    "SHFE",
+			Timestamp:   uint64(1700000000000000000 + int64(i)*500000000),
+			LastPrice:   last,
+			LastQty:     qty,
+			TotalVolume: volume,
+			Turnover:    turnover,
+			HighPrice:   mid + 20,
+			LowPrice:    mid - 20,
+			OpenPrice:   5000,
+		}
+		for lvl := 0; lvl < 5; lvl++ {
+			md.BidPrice = append(md.BidPrice, mid-0.5-float64(lvl))
+			md.AskPrice = append(md.AskPrice, mid+0.5+float64(lvl))
+			md.BidQty = append(md.BidQty, uint32(10+(i+lvl*3)%40))
+			md.AskQty = append(md.AskQty, uint32(10+(i*3+lvl)%40))
+		}
+		mds[i] = md
+	}
+	return mds
+}
+
+// registeredIndicatorTypes returns all factory names registered in the library, sorted
+func registeredIndicatorTypes(lib *IndicatorLibrary) []string {
+	lib.mu.RLock()
+	defer lib.mu.RUnlock()
+
+	names := make([]string, 0, len(lib.factories))
+	for name := range lib.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newWarmIndicator creates an indicator with default config and feeds it
+// enough data to reach steady state (windows full, history ring wrapped).
+func newWarmIndicator(tb testing.TB, lib *IndicatorLibrary, indicatorType string, mds []*mdpb.MarketDataUpdate) Indicator {
+	ind, err := lib.Create(indicatorType, indicatorType, map[string]interface{}{})
+	if err != nil {
+		tb.Fatalf("Failed to create %s: %v", indicatorType, err)
+	}
+	for i := 0; i < 3000; i++ {
+		ind.Update(mds[i%len(mds)])
+	}
+	return ind
+}
+
+// BenchmarkIndicatorUpdate benchmarks steady-state Update for every registered indicator
+func BenchmarkIndicatorUpdate(b *testing.B) {
+	lib := NewIndicatorLibrary()
+	mds := benchMarketData(1024)
+
+	for _, indicatorType := range registeredIndicatorTypes(lib) {
+		b.Run(indicatorType, func(b *testing.B) {
+			ind := newWarmIndicator(b, lib, indicatorType, mds)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ind.Update(mds[i%len(mds)])
+			}
+		})
+	}
+}
+
+// BenchmarkIndicatorLibraryUpdateAll benchmarks one tick through a library holding every indicator
+func BenchmarkIndicatorLibraryUpdateAll(b *testing.B) {
+	lib := NewIndicatorLibrary()
+	mds := benchMarketData(1024)
+	for _, indicatorType := range registeredIndicatorTypes(lib) {
+		newWarmIndicator(b, lib, indicatorType, mds)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lib.UpdateAll(mds[i%len(mds)])
+	}
+}
+
+// BenchmarkBaseIndicatorAddValue benchmarks the history ring buffer write
+func BenchmarkBaseIndicatorAddValue(b *testing.B) {
+	base := NewBaseIndicator("bench", 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		base.AddValue(float64(i))
+	}
+}
+
+// BenchmarkBaseIndicatorAppendValues benchmarks reading the full history into a reused buffer
+func BenchmarkBaseIndicatorAppendValues(b *testing.B) {
+	base := NewBaseIndicator("bench", 1000)
+	for i := 0; i < 1500; i++ {
+		base.AddValue(float64(i))
+	}
+	buf := make([]float64, 0, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = base.AppendValues(buf[:0])
+	}
+}
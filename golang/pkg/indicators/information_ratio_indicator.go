@@ -14,9 +14,9 @@ import (
 type InformationRatioIndicator struct {
 	*BaseIndicator
 	period           int
-	portfolioReturns []float64
-	benchmarkReturns []float64
-	activeReturns    []float64
+	portfolioReturns window[float64]
+	benchmarkReturns window[float64]
+	activeReturns    window[float64]
 	prevPortfolio    float64
 	prevBenchmark    float64
 	lastValue        float64
@@ -37,9 +37,9 @@ func NewInformationRatioIndicator(period int, benchmarkSymbol string, annualize
 	return &InformationRatioIndicator{
 		BaseIndicator:    NewBaseIndicator("InformationRatio", maxHistory),
 		period:           period,
-		portfolioReturns: make([]float64, 0, period),
-		benchmarkReturns: make([]float64, 0, period),
-		activeReturns:    make([]float64, 0, period),
+		portfolioReturns: newWindow[float64](period),
+		benchmarkReturns: newWindow[float64](period),
+		activeReturns:    newWindow[float64](period),
 		benchmarkSymbol:  benchmarkSymbol,
 		priceCache:       make(map[string]float64),
 		annualize:        annualize,
@@ -98,40 +98,39 @@ func (ir *InformationRatioIndicator) Update(md *mdpb.MarketDataUpdate) {
 	if isPortfolio {
 		if ir.prevPortfolio > 0 {
 			ret := (price - ir.prevPortfolio) / ir.prevPortfolio
-			ir.portfolioReturns = append(ir.portfolioReturns, ret)
-			if len(ir.portfolioReturns) > ir.period {
-				ir.portfolioReturns = ir.portfolioReturns[1:]
+			ir.portfolioReturns.push(ret)
+			if ir.portfolioReturns.len() > ir.period {
+				ir.portfolioReturns.dropOldest()
 			}
 		}
 		ir.prevPortfolio = price
 	} else {
 		if ir.prevBenchmark > 0 {
 			ret := (price - ir.prevBenchmark) / ir.prevBenchmark
-			ir.benchmarkReturns = append(ir.benchmarkReturns, ret)
-			if len(ir.benchmarkReturns) > ir.period {
-				ir.benchmarkReturns = ir.benchmarkReturns[1:]
+			ir.benchmarkReturns.push(ret)
+			if ir.benchmarkReturns.len() > ir.period {
+				ir.benchmarkReturns.dropOldest()
 			}
 		}
 		ir.prevBenchmark = price
 	}
 
 	// Calculate active returns when we have both
-	if len(ir.portfolioReturns) > 0 && len(ir.benchmarkReturns) > 0 {
-		minLen := len(ir.portfolioReturns)
-		if len(ir.benchmarkReturns) < minLen {
-			minLen = len(ir.benchmarkReturns)
+	if ir.portfolioReturns.len() > 0 && ir.benchmarkReturns.len() > 0 {
+		minLen := ir.portfolioReturns.len()
+		if ir.benchmarkReturns.len() < minLen {
+			minLen = ir.benchmarkReturns.len()
 		}
 
 		// Recalculate active returns from the available data
-		ir.activeReturns = make([]float64, minLen)
+		port, bench := ir.portfolioReturns.values(), ir.benchmarkReturns.values()
+		ir.activeReturns.reset()
 		for i := 0; i < minLen; i++ {
-			portIdx := len(ir.portfolioReturns) - minLen + i
-			benchIdx := len(ir.benchmarkReturns) - minLen + i
-			ir.activeReturns[i] = ir.portfolioReturns[portIdx] - ir.benchmarkReturns[benchIdx]
+			ir.activeReturns.push(port[len(port)-minLen+i] - bench[len(bench)-minLen+i])
 		}
 	}
 
-	if len(ir.activeReturns) < ir.period {
+	if ir.activeReturns.len() < ir.period {
 		return
 	}
 
@@ -140,33 +139,33 @@ func (ir *InformationRatioIndicator) Update(md *mdpb.MarketDataUpdate) {
 
 // UpdateWithReturns updates with pre-calculated returns
 func (ir *InformationRatioIndicator) UpdateWithReturns(portfolioReturn, benchmarkReturn float64) {
-	ir.portfolioReturns = append(ir.portfolioReturns, portfolioReturn)
-	if len(ir.portfolioReturns) > ir.period {
-		ir.portfolioReturns = ir.portfolioReturns[1:]
+	ir.portfolioReturns.push(portfolioReturn)
+	if ir.portfolioReturns.len() > ir.period {
+		ir.portfolioReturns.dropOldest()
 	}
 
-	ir.benchmarkReturns = append(ir.benchmarkReturns, benchmarkReturn)
-	if len(ir.benchmarkReturns) > ir.period {
-		ir.benchmarkReturns = ir.benchmarkReturns[1:]
+	ir.benchmarkReturns.push(benchmarkReturn)
+	if ir.benchmarkReturns.len() > ir.period {
+		ir.benchmarkReturns.dropOldest()
 	}
 
 	activeReturn := portfolioReturn - benchmarkReturn
-	ir.activeReturns = append(ir.activeReturns, activeReturn)
-	if len(ir.activeReturns) > ir.period {
-		ir.activeReturns = ir.activeReturns[1:]
+	ir.activeReturns.push(activeReturn)
+	if ir.activeReturns.len() > ir.period {
+		ir.activeReturns.dropOldest()
 	}
 
-	if len(ir.activeReturns) >= ir.period {
+	if ir.activeReturns.len() >= ir.period {
 		ir.calculateIR()
 	}
 }
 
 func (ir *InformationRatioIndicator) calculateIR() {
 	// Calculate mean active return
-	meanActive := stats.Mean(ir.activeReturns)
+	meanActive := stats.Mean(ir.activeReturns.values())
 
 	// Calculate tracking error (std dev of active returns)
-	trackingError := stats.StdDev(ir.activeReturns)
+	trackingError := stats.StdDev(ir.activeReturns.values())
 
 	if trackingError == 0 {
 		ir.lastValue = 0
@@ -193,10 +192,10 @@ func (ir *InformationRatioIndicator) GetValue() float64 {
 
 // GetTrackingError returns the current tracking error
 func (ir *InformationRatioIndicator) GetTrackingError() float64 {
-	if len(ir.activeReturns) < 2 {
+	if ir.activeReturns.len() < 2 {
 		return 0
 	}
-	te := stats.StdDev(ir.activeReturns)
+	te := stats.StdDev(ir.activeReturns.values())
 	if ir.annualize {
 		te *= math.Sqrt(252.0)
 	}
@@ -205,10 +204,10 @@ func (ir *InformationRatioIndicator) GetTrackingError() float64 {
 
 // GetActiveReturn returns the mean active return
 func (ir *InformationRatioIndicator) GetActiveReturn() float64 {
-	if len(ir.activeReturns) == 0 {
+	if ir.activeReturns.len() == 0 {
 		return 0
 	}
-	ar := stats.Mean(ir.activeReturns)
+	ar := stats.Mean(ir.activeReturns.values())
 	if ir.annualize {
 		ar *= 252.0
 	}
@@ -218,9 +217,9 @@ func (ir *InformationRatioIndicator) GetActiveReturn() float64 {
 // Reset resets the indicator
 func (ir *InformationRatioIndicator) Reset() {
 	ir.BaseIndicator.Reset()
-	ir.portfolioReturns = ir.portfolioReturns[:0]
-	ir.benchmarkReturns = ir.benchmarkReturns[:0]
-	ir.activeReturns = ir.activeReturns[:0]
+	ir.portfolioReturns.reset()
+	ir.benchmarkReturns.reset()
+	ir.activeReturns.reset()
 	ir.prevPortfolio = 0
 	ir.prevBenchmark = 0
 	ir.priceCache = make(map[string]float64)
@@ -229,5 +228,5 @@ func (ir *InformationRatioIndicator) Reset() {
 
 // IsReady returns true if we have enough data
 func (ir *InformationRatioIndicator) IsReady() bool {
-	return len(ir.activeReturns) >= ir.period
+	return ir.activeReturns.len() >= ir.period
 }
@@ -0,0 +1,167 @@
+package indicators
+
+import (
+	"fmt"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	"github.com/yourusername/quantlink-trade-system/pkg/stats"
+)
+
+// KalmanHedgeRatio estimates a time-varying hedge ratio and intercept with a Kalman filter
+// 卡尔曼滤波动态对冲比率：price1 = alpha + beta * price2 + e
+//
+// Unlike BetaIndicator (rolling OLS) the estimate adapts continuously with no
+// window edge effects. Process noise (per-update variance of alpha/beta) controls
+// how fast the ratio may drift; observation noise is the spread noise variance.
+//
+// The value is beta; GetAlpha, GetResidual, GetResidualVariance and GetZScore
+// expose the intercept and the spread residual (innovation) statistics.
+type KalmanHedgeRatio struct {
+	*BaseIndicator
+	symbol2 string // independent leg (X); updates from any other symbol are leg 1 (Y)
+	kf      *stats.KalmanRegression
+	price1  float64
+	price2  float64
+}
+
+// NewKalmanHedgeRatio creates a new KalmanHedgeRatio indicator
+func NewKalmanHedgeRatio(name, symbol2 string, qAlpha, qBeta, r float64, maxHistory int) *KalmanHedgeRatio {
+	return &KalmanHedgeRatio{
+		BaseIndicator: NewBaseIndicator(name, maxHistory),
+		symbol2:       symbol2,
+		kf:            stats.NewKalmanRegression(qAlpha, qBeta, r, 1.0),
+	}
+}
+
+// NewKalmanHedgeRatioFromConfig creates a KalmanHedgeRatio from configuration
+func NewKalmanHedgeRatioFromConfig(config map[string]interface{}) (Indicator, error) {
+	name := "KalmanHedgeRatio"
+	if v, ok := config["name"]; ok {
+		if s, ok := v.(string); ok {
+			name = s
+		}
+	}
+
+	symbol2 := ""
+	if v, ok := config["symbol2"]; ok {
+		if s, ok := v.(string); ok {
+			symbol2 = s
+		}
+	}
+
+	qAlpha := 1e-4
+	if v, ok := config["process_noise_alpha"]; ok {
+		if f, ok := v.(float64); ok {
+			qAlpha = f
+		}
+	}
+
+	qBeta := 1e-8
+	if v, ok := config["process_noise_beta"]; ok {
+		if f, ok := v.(float64); ok {
+			qBeta = f
+		}
+	}
+
+	r := 1.0
+	if v, ok := config["observation_noise"]; ok {
+		if f, ok := v.(float64); ok {
+			r = f
+		}
+	}
+
+	maxHistory := 1000
+	if v, ok := config["max_history"]; ok {
+		if f, ok := v.(float64); ok {
+			maxHistory = int(f)
+		}
+	}
+
+	if qAlpha < 0 || qBeta < 0 {
+		return nil, fmt.Errorf("%w: process noise must be >= 0", ErrInvalidParameter)
+	}
+	if r <= 0 {
+		return nil, fmt.Errorf("%w: observation_noise must be > 0, got %f", ErrInvalidParameter, r)
+	}
+
+	return NewKalmanHedgeRatio(name, symbol2, qAlpha, qBeta, r, maxHistory), nil
+}
+
+// Update caches the leg price and runs a filter step when both legs are known
+func (k *KalmanHedgeRatio) Update(md *mdpb.MarketDataUpdate) {
+	mid := GetMidPrice(md)
+	if mid <= 0 {
+		return
+	}
+	if md.Symbol == k.symbol2 {
+		k.price2 = mid
+		return
+	}
+	k.price1 = mid
+	if k.price2 > 0 {
+		k.step()
+	}
+}
+
+// UpdateWithPair runs a filter step on (price1, price2)
+func (k *KalmanHedgeRatio) UpdateWithPair(price1, price2 float64) {
+	if price1 <= 0 || price2 <= 0 {
+		return
+	}
+	k.price1 = price1
+	k.price2 = price2
+	k.step()
+}
+
+func (k *KalmanHedgeRatio) step() {
+	k.kf.Update(k.price2, k.price1)
+	k.AddValue(k.kf.Beta)
+}
+
+// GetValue returns the hedge ratio beta
+func (k *KalmanHedgeRatio) GetValue() float64 {
+	return k.kf.Beta
+}
+
+// GetBeta returns the hedge ratio beta
+func (k *KalmanHedgeRatio) GetBeta() float64 {
+	return k.kf.Beta
+}
+
+// GetAlpha returns the intercept
+func (k *KalmanHedgeRatio) GetAlpha() float64 {
+	return k.kf.Alpha
+}
+
+// GetResidual returns the latest spread residual (innovation) price1 - alpha - beta*price2
+func (k *KalmanHedgeRatio) GetResidual() float64 {
+	return k.kf.Residual
+}
+
+// GetResidualVariance returns the innovation variance of the latest residual
+func (k *KalmanHedgeRatio) GetResidualVariance() float64 {
+	return k.kf.ResidualVar
+}
+
+// GetZScore returns residual / sqrt(residual variance)
+func (k *KalmanHedgeRatio) GetZScore() float64 {
+	return k.kf.ZScore()
+}
+
+// GetSpread returns price1 - beta*price2 on the latest prices (intercept not removed)
+func (k *KalmanHedgeRatio) GetSpread() float64 {
+	return k.price1 - k.kf.Beta*k.price2
+}
+
+// Reset resets the indicator
+func (k *KalmanHedgeRatio) Reset() {
+	k.BaseIndicator.Reset()
+	k.kf.Reset(1.0)
+	k.price1 = 0
+	k.price2 = 0
+}
+
+// IsReady returns true once the filter has seen a few observations
+func (k *KalmanHedgeRatio) IsReady() bool {
+	return k.kf.Count >= 2
+}
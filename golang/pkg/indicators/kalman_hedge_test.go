@@ -0,0 +1,70 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+)
+
+func TestKalmanHedgeRatio_TracksDrift(t *testing.T) {
+	ind, err := NewKalmanHedgeRatioFromConfig(map[string]interface{}{
+		"symbol2":             "ag2605",
+		"process_noise_alpha": 1e-4,
+		"process_noise_beta":  1e-8,
+		"observation_noise":   1.0,
+	})
+	if err != nil {
+		t.Fatalf("NewKalmanHedgeRatioFromConfig: %v", err)
+	}
+	k := ind.(*KalmanHedgeRatio)
+
+	walk := gaussSeq(4000, 13)
+	noise := gaussSeq(4000, 17)
+	x := 5000.0
+	beta := 1.0
+	for i := range walk {
+		beta = 1.0 + 0.02*float64(i)/float64(len(walk))
+		x += 5 * walk[i]
+		k.UpdateWithPair(beta*x+30+noise[i], x)
+	}
+
+	if math.Abs(k.GetBeta()-beta) > 0.005 {
+		t.Errorf("beta = %f, want %f", k.GetBeta(), beta)
+	}
+	if k.GetValue() != k.GetBeta() {
+		t.Errorf("GetValue = %f, want beta %f", k.GetValue(), k.GetBeta())
+	}
+	if k.GetResidualVariance() <= 0 || math.Abs(k.GetZScore()) > 5 {
+		t.Errorf("residual var = %f, z = %f", k.GetResidualVariance(), k.GetZScore())
+	}
+}
+
+func TestKalmanHedgeRatio_UpdateBySymbol(t *testing.T) {
+	k := NewKalmanHedgeRatio("kf", "ag2605", 1e-4, 1e-8, 1.0, 100)
+	md := func(sym string, px float64) *mdpb.MarketDataUpdate {
+		return &mdpb.MarketDataUpdate{Symbol: sym, BidPrice: []float64{px}, AskPrice: []float64{px}}
+	}
+
+	k.Update(md("ag2603", 5010))
+	if k.IsReady() {
+		t.Fatal("should not update before leg 2 is known")
+	}
+	k.Update(md("ag2605", 5000))
+	k.Update(md("ag2603", 5011))
+	k.Update(md("ag2603", 5012))
+	if !k.IsReady() {
+		t.Fatal("expected ready after two paired updates")
+	}
+	if math.Abs(k.GetSpread()-(5012-k.GetBeta()*5000)) > 1e-9 {
+		t.Errorf("spread = %f", k.GetSpread())
+	}
+}
+
+func TestKalmanHedgeRatio_InvalidConfig(t *testing.T) {
+	_, err := NewKalmanHedgeRatioFromConfig(map[string]interface{}{"observation_noise": 0.0})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("observation_noise=0: err = %v, want ErrInvalidParameter", err)
+	}
+}
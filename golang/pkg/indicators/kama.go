@@ -32,7 +32,7 @@ type KAMA struct {
 	period       int
 	fastPeriod   int
 	slowPeriod   int
-	prices       []float64
+	prices       window[float64]
 	kama         float64
 	fastSC       float64 // Fastest smoothing constant
 	slowSC       float64 // Slowest smoothing constant
@@ -63,7 +63,7 @@ func NewKAMA(period int, fastPeriod int, slowPeriod int, maxHistory int) *KAMA {
 		period:        period,
 		fastPeriod:    fastPeriod,
 		slowPeriod:    slowPeriod,
-		prices:        make([]float64, 0, period+1),
+		prices:        newWindow[float64](period+1),
 		fastSC:        fastSC,
 		slowSC:        slowSC,
 	}
@@ -111,17 +111,17 @@ func (k *KAMA) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store price
-	k.prices = append(k.prices, price)
+	k.prices.push(price)
 
 	// Keep only period+1 values
-	if len(k.prices) > k.period+1 {
-		k.prices = k.prices[1:]
+	if k.prices.len() > k.period+1 {
+		k.prices.dropOldest()
 	}
 
 	// Initialize KAMA with first price
 	if !k.initialized {
-		if len(k.prices) >= k.period+1 {
-			k.kama = k.prices[0]
+		if k.prices.len() >= k.period+1 {
+			k.kama = k.prices.at(0)
 			k.initialized = true
 		} else {
 			return
@@ -129,17 +129,17 @@ func (k *KAMA) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Need at least period+1 values
-	if len(k.prices) < k.period+1 {
+	if k.prices.len() < k.period+1 {
 		return
 	}
 
 	// Calculate Efficiency Ratio (ER)
-	change := math.Abs(k.prices[len(k.prices)-1] - k.prices[0])
+	change := math.Abs(k.prices.at(k.prices.len()-1) - k.prices.at(0))
 
 	// Calculate Volatility (sum of absolute price changes)
 	volatility := 0.0
-	for i := 1; i < len(k.prices); i++ {
-		volatility += math.Abs(k.prices[i] - k.prices[i-1])
+	for i := 1; i < k.prices.len(); i++ {
+		volatility += math.Abs(k.prices.at(i) - k.prices.at(i-1))
 	}
 
 	// Calculate ER (avoid division by zero)
@@ -170,14 +170,14 @@ func (k *KAMA) GetValue() float64 {
 // Reset resets the indicator
 func (k *KAMA) Reset() {
 	k.BaseIndicator.Reset()
-	k.prices = k.prices[:0]
+	k.prices.reset()
 	k.kama = 0
 	k.initialized = false
 }
 
 // IsReady returns true if the indicator has enough data
 func (k *KAMA) IsReady() bool {
-	return k.initialized && len(k.prices) >= k.period+1
+	return k.initialized && k.prices.len() >= k.period+1
 }
 
 // GetPeriod returns the period
@@ -198,15 +198,15 @@ func (k *KAMA) GetSlowPeriod() int {
 // GetEfficiencyRatio returns the current efficiency ratio
 // Useful for understanding market conditions
 func (k *KAMA) GetEfficiencyRatio() float64 {
-	if len(k.prices) < k.period+1 {
+	if k.prices.len() < k.period+1 {
 		return 0
 	}
 
-	change := math.Abs(k.prices[len(k.prices)-1] - k.prices[0])
+	change := math.Abs(k.prices.at(k.prices.len()-1) - k.prices.at(0))
 
 	volatility := 0.0
-	for i := 1; i < len(k.prices); i++ {
-		volatility += math.Abs(k.prices[i] - k.prices[i-1])
+	for i := 1; i < k.prices.len(); i++ {
+		volatility += math.Abs(k.prices.at(i) - k.prices.at(i-1))
 	}
 
 	if volatility > 0 {
@@ -230,12 +230,12 @@ func (k *KAMA) IsRangingMarket() bool {
 // GetTrend returns the trend direction
 // Returns 1 for uptrend, -1 for downtrend, 0 for neutral
 func (k *KAMA) GetTrend() int {
-	if len(k.values) < 2 {
+	if k.Len() < 2 {
 		return 0
 	}
 
-	current := k.values[len(k.values)-1]
-	previous := k.values[len(k.values)-2]
+	current := k.ValueAgo(0)
+	previous := k.ValueAgo(1)
 
 	if current > previous {
 		return 1
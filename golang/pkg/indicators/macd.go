@@ -39,10 +39,7 @@ func NewMACD(fastPeriod, slowPeriod, signalPeriod float64, maxHistory int) *MACD
 	signal := int(signalPeriod)
 
 	return &MACD{
-		BaseIndicator: &BaseIndicator{
-			name:       "MACD",
-			maxHistory: maxHistory,
-		},
+		BaseIndicator: NewBaseIndicator("MACD", maxHistory),
 		fastPeriod:   fast,
 		slowPeriod:   slow,
 		signalPeriod: signal,
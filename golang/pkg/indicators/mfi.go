@@ -27,10 +27,10 @@ import (
 type MFI struct {
 	*BaseIndicator
 	period           int
-	typicalPrices    []float64
-	rawMoneyFlows    []float64
-	positiveFlows    []float64
-	negativeFlows    []float64
+	typicalPrices    window[float64]
+	rawMoneyFlows    window[float64]
+	positiveFlows    window[float64]
+	negativeFlows    window[float64]
 	mfi              float64
 	prevTypicalPrice float64
 }
@@ -47,10 +47,10 @@ func NewMFI(period int, maxHistory int) *MFI {
 	return &MFI{
 		BaseIndicator:    NewBaseIndicator("MFI", maxHistory),
 		period:           period,
-		typicalPrices:    make([]float64, 0, period+1),
-		rawMoneyFlows:    make([]float64, 0, period+1),
-		positiveFlows:    make([]float64, 0, period),
-		negativeFlows:    make([]float64, 0, period),
+		typicalPrices:    newWindow[float64](period+1),
+		rawMoneyFlows:    newWindow[float64](period+1),
+		positiveFlows:    newWindow[float64](period),
+		negativeFlows:    newWindow[float64](period),
 	}
 }
 
@@ -109,55 +109,55 @@ func (m *MFI) Update(md *mdpb.MarketDataUpdate) {
 	rawMoneyFlow := typicalPrice * volume
 
 	// Store values
-	m.typicalPrices = append(m.typicalPrices, typicalPrice)
-	m.rawMoneyFlows = append(m.rawMoneyFlows, rawMoneyFlow)
+	m.typicalPrices.push(typicalPrice)
+	m.rawMoneyFlows.push(rawMoneyFlow)
 
 	// Keep only period+1 values
-	if len(m.typicalPrices) > m.period+1 {
-		m.typicalPrices = m.typicalPrices[1:]
-		m.rawMoneyFlows = m.rawMoneyFlows[1:]
+	if m.typicalPrices.len() > m.period+1 {
+		m.typicalPrices.dropOldest()
+		m.rawMoneyFlows.dropOldest()
 	}
 
 	// Need at least 2 values to calculate flow direction
-	if len(m.typicalPrices) < 2 {
+	if m.typicalPrices.len() < 2 {
 		return
 	}
 
 	// Determine if positive or negative money flow
-	prevTP := m.typicalPrices[len(m.typicalPrices)-2]
-	currentTP := m.typicalPrices[len(m.typicalPrices)-1]
-	currentRMF := m.rawMoneyFlows[len(m.rawMoneyFlows)-1]
+	prevTP := m.typicalPrices.at(m.typicalPrices.len()-2)
+	currentTP := m.typicalPrices.at(m.typicalPrices.len()-1)
+	currentRMF := m.rawMoneyFlows.at(m.rawMoneyFlows.len()-1)
 
 	if currentTP > prevTP {
 		// Positive money flow
-		m.positiveFlows = append(m.positiveFlows, currentRMF)
-		m.negativeFlows = append(m.negativeFlows, 0)
+		m.positiveFlows.push(currentRMF)
+		m.negativeFlows.push(0)
 	} else if currentTP < prevTP {
 		// Negative money flow
-		m.positiveFlows = append(m.positiveFlows, 0)
-		m.negativeFlows = append(m.negativeFlows, currentRMF)
+		m.positiveFlows.push(0)
+		m.negativeFlows.push(currentRMF)
 	} else {
 		// No change - neutral
-		m.positiveFlows = append(m.positiveFlows, 0)
-		m.negativeFlows = append(m.negativeFlows, 0)
+		m.positiveFlows.push(0)
+		m.negativeFlows.push(0)
 	}
 
 	// Keep only period values
-	if len(m.positiveFlows) > m.period {
-		m.positiveFlows = m.positiveFlows[1:]
-		m.negativeFlows = m.negativeFlows[1:]
+	if m.positiveFlows.len() > m.period {
+		m.positiveFlows.dropOldest()
+		m.negativeFlows.dropOldest()
 	}
 
 	// Need at least period values to calculate MFI
-	if len(m.positiveFlows) < m.period {
+	if m.positiveFlows.len() < m.period {
 		return
 	}
 
 	// Calculate sums
 	var sumPositive, sumNegative float64
 	for i := 0; i < m.period; i++ {
-		sumPositive += m.positiveFlows[i]
-		sumNegative += m.negativeFlows[i]
+		sumPositive += m.positiveFlows.at(i)
+		sumNegative += m.negativeFlows.at(i)
 	}
 
 	// Calculate Money Flow Ratio and MFI
@@ -180,17 +180,17 @@ func (m *MFI) GetValue() float64 {
 // Reset resets the indicator
 func (m *MFI) Reset() {
 	m.BaseIndicator.Reset()
-	m.typicalPrices = m.typicalPrices[:0]
-	m.rawMoneyFlows = m.rawMoneyFlows[:0]
-	m.positiveFlows = m.positiveFlows[:0]
-	m.negativeFlows = m.negativeFlows[:0]
+	m.typicalPrices.reset()
+	m.rawMoneyFlows.reset()
+	m.positiveFlows.reset()
+	m.negativeFlows.reset()
 	m.mfi = 0
 	m.prevTypicalPrice = 0
 }
 
 // IsReady returns true if the indicator has enough data
 func (m *MFI) IsReady() bool {
-	return len(m.positiveFlows) >= m.period
+	return m.positiveFlows.len() >= m.period
 }
 
 // GetPeriod returns the period
@@ -222,16 +222,15 @@ func (m *MFI) GetSignal() int {
 // IsBullishDivergence checks for bullish divergence
 // Price making lower lows but MFI making higher lows
 func (m *MFI) IsBullishDivergence(prices []float64) bool {
-	if len(m.values) < 5 || len(prices) < 5 {
+	if m.Len() < 5 || len(prices) < 5 {
 		return false
 	}
 
-	n := len(m.values)
 	pn := len(prices)
 
 	// Check recent trend
-	mfiRecentLow := m.values[n-5]
-	mfiCurrentLow := m.values[n-1]
+	mfiRecentLow := m.ValueAgo(4)
+	mfiCurrentLow := m.ValueAgo(0)
 	priceRecentLow := prices[pn-5]
 	priceCurrentLow := prices[pn-1]
 
@@ -242,16 +241,15 @@ func (m *MFI) IsBullishDivergence(prices []float64) bool {
 // IsBearishDivergence checks for bearish divergence
 // Price making higher highs but MFI making lower highs
 func (m *MFI) IsBearishDivergence(prices []float64) bool {
-	if len(m.values) < 5 || len(prices) < 5 {
+	if m.Len() < 5 || len(prices) < 5 {
 		return false
 	}
 
-	n := len(m.values)
 	pn := len(prices)
 
 	// Check recent trend
-	mfiRecentHigh := m.values[n-5]
-	mfiCurrentHigh := m.values[n-1]
+	mfiRecentHigh := m.ValueAgo(4)
+	mfiCurrentHigh := m.ValueAgo(0)
 	priceRecentHigh := prices[pn-5]
 	priceCurrentHigh := prices[pn-1]
 
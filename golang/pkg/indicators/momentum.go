@@ -12,7 +12,7 @@ import (
 type Momentum struct {
 	*BaseIndicator
 	period      int
-	prices      []float64
+	prices      window[float64]
 	initialized bool
 }
 
@@ -29,7 +29,7 @@ func NewMomentum(period int, maxHistory int) *Momentum {
 	return &Momentum{
 		BaseIndicator: NewBaseIndicator("Momentum", maxHistory),
 		period:        period,
-		prices:        make([]float64, 0, period+1),
+		prices:        newWindow[float64](period+1),
 		initialized:   false,
 	}
 }
@@ -70,15 +70,15 @@ func (m *Momentum) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add new price
-	m.prices = append(m.prices, midPrice)
+	m.prices.push(midPrice)
 
 	// Keep period+1 prices (current + N historical)
-	if len(m.prices) > m.period+1 {
-		m.prices = m.prices[1:]
+	if m.prices.len() > m.period+1 {
+		m.prices.dropOldest()
 	}
 
 	// Calculate momentum when we have enough data
-	if len(m.prices) == m.period+1 {
+	if m.prices.len() == m.period+1 {
 		m.initialized = true
 		momentum := m.calculateMomentum()
 		m.AddValue(momentum)
@@ -88,8 +88,8 @@ func (m *Momentum) Update(md *mdpb.MarketDataUpdate) {
 // calculateMomentum computes the momentum value
 func (m *Momentum) calculateMomentum() float64 {
 	// Momentum = Current Price - Price[period] ago
-	currentPrice := m.prices[len(m.prices)-1]
-	pastPrice := m.prices[0]
+	currentPrice := m.prices.at(m.prices.len()-1)
+	pastPrice := m.prices.at(0)
 	return currentPrice - pastPrice
 }
 
@@ -103,13 +103,13 @@ func (m *Momentum) GetValue() float64 {
 
 // IsReady returns true if the indicator has enough data
 func (m *Momentum) IsReady() bool {
-	return m.initialized && len(m.prices) == m.period+1
+	return m.initialized && m.prices.len() == m.period+1
 }
 
 // Reset resets the indicator state
 func (m *Momentum) Reset() {
 	m.BaseIndicator.Reset()
-	m.prices = make([]float64, 0, m.period+1)
+	m.prices.reset()
 	m.initialized = false
 }
 
@@ -120,7 +120,7 @@ func (m *Momentum) GetPeriod() int {
 
 // GetPrices returns the current price window (for testing)
 func (m *Momentum) GetPrices() []float64 {
-	result := make([]float64, len(m.prices))
-	copy(result, m.prices)
+	result := make([]float64, m.prices.len())
+	copy(result, m.prices.values())
 	return result
 }
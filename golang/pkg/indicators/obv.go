@@ -102,18 +102,18 @@ func (o *OBV) Reset() {
 
 // IsReady returns true if the indicator has enough data
 func (o *OBV) IsReady() bool {
-	return o.prevClose > 0 && len(o.values) > 0
+	return o.prevClose > 0 && o.Len() > 0
 }
 
 // GetTrend returns the trend direction based on OBV slope
 // Returns 1 for uptrend, -1 for downtrend, 0 for neutral
 func (o *OBV) GetTrend() int {
-	if len(o.values) < 2 {
+	if o.Len() < 2 {
 		return 0
 	}
 
-	current := o.values[len(o.values)-1]
-	previous := o.values[len(o.values)-2]
+	current := o.ValueAgo(0)
+	previous := o.ValueAgo(1)
 
 	if current > previous {
 		return 1 // Uptrend
@@ -128,17 +128,14 @@ func (o *OBV) GetTrend() int {
 // Requires at least 5 data points
 // Returns: 1 for bullish divergence, -1 for bearish divergence, 0 for no divergence
 func (o *OBV) IsDivergence(prices []float64) int {
-	if len(o.values) < 5 || len(prices) < 5 {
+	if o.Len() < 5 || len(prices) < 5 {
 		return 0
 	}
 
-	// Get recent OBV values
-	n := len(o.values)
-	obvRecent := o.values[n-5:]
 	priceRecent := prices[len(prices)-5:]
 
 	// Calculate trends (simple slope check)
-	obvTrend := obvRecent[4] - obvRecent[0]
+	obvTrend := o.ValueAgo(0) - o.ValueAgo(4)
 	priceTrend := priceRecent[4] - priceRecent[0]
 
 	// Bullish divergence: price falling but OBV rising
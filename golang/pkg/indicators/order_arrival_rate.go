@@ -29,8 +29,8 @@ type OrderArrivalRate struct {
 	prevAskQty     []uint32  // Previous ask quantities
 
 	// Arrival tracking
-	bidArrivals    []int64 // Timestamps of new bid orders (nanoseconds)
-	askArrivals    []int64 // Timestamps of new ask orders (nanoseconds)
+	bidArrivals    window[int64] // Timestamps of new bid orders (nanoseconds)
+	askArrivals    window[int64] // Timestamps of new ask orders (nanoseconds)
 
 	// Current state
 	bidArrivalRate  float64 // Bid orders per second
@@ -56,8 +56,8 @@ func NewOrderArrivalRate(name string, windowDuration time.Duration, levels int,
 		BaseIndicator:  NewBaseIndicator(name, maxHistory),
 		windowDuration: windowDuration,
 		levels:         levels,
-		bidArrivals:    make([]int64, 0, 1000),
-		askArrivals:    make([]int64, 0, 1000),
+		bidArrivals:    newWindow[int64](1000),
+		askArrivals:    newWindow[int64](1000),
 	}
 
 	return oar
@@ -111,7 +111,7 @@ func (oar *OrderArrivalRate) Update(md *mdpb.MarketDataUpdate) {
 		)
 		if bidNewOrders > 0 {
 			for i := 0; i < bidNewOrders; i++ {
-				oar.bidArrivals = append(oar.bidArrivals, currentTime)
+				oar.bidArrivals.push(currentTime)
 			}
 		}
 
@@ -122,7 +122,7 @@ func (oar *OrderArrivalRate) Update(md *mdpb.MarketDataUpdate) {
 		)
 		if askNewOrders > 0 {
 			for i := 0; i < askNewOrders; i++ {
-				oar.askArrivals = append(oar.askArrivals, currentTime)
+				oar.askArrivals.push(currentTime)
 			}
 		}
 	}
@@ -198,24 +198,16 @@ func (oar *OrderArrivalRate) savePreviousState(md *mdpb.MarketDataUpdate) {
 	if maxLevels > len(md.BidPrice) {
 		maxLevels = len(md.BidPrice)
 	}
-	oar.prevBidPrices = make([]float64, maxLevels)
-	oar.prevBidQty = make([]uint32, maxLevels)
-	for i := 0; i < maxLevels; i++ {
-		oar.prevBidPrices[i] = md.BidPrice[i]
-		oar.prevBidQty[i] = md.BidQty[i]
-	}
+	oar.prevBidPrices = append(oar.prevBidPrices[:0], md.BidPrice[:maxLevels]...)
+	oar.prevBidQty = append(oar.prevBidQty[:0], md.BidQty[:maxLevels]...)
 
 	// Save ask side
 	maxLevels = oar.levels
 	if maxLevels > len(md.AskPrice) {
 		maxLevels = len(md.AskPrice)
 	}
-	oar.prevAskPrices = make([]float64, maxLevels)
-	oar.prevAskQty = make([]uint32, maxLevels)
-	for i := 0; i < maxLevels; i++ {
-		oar.prevAskPrices[i] = md.AskPrice[i]
-		oar.prevAskQty[i] = md.AskQty[i]
-	}
+	oar.prevAskPrices = append(oar.prevAskPrices[:0], md.AskPrice[:maxLevels]...)
+	oar.prevAskQty = append(oar.prevAskQty[:0], md.AskQty[:maxLevels]...)
 }
 
 // cleanOldArrivals removes arrival timestamps outside the time window
@@ -225,32 +217,32 @@ func (oar *OrderArrivalRate) cleanOldArrivals(currentTime int64) {
 
 	// Clean bid arrivals
 	firstValid := 0
-	for i, t := range oar.bidArrivals {
+	for i, t := range oar.bidArrivals.values() {
 		if t >= cutoffTime {
 			firstValid = i
 			break
 		}
 	}
 	if firstValid > 0 {
-		oar.bidArrivals = oar.bidArrivals[firstValid:]
+		oar.bidArrivals.dropFirst(firstValid)
 	}
 
 	// Clean ask arrivals
 	firstValid = 0
-	for i, t := range oar.askArrivals {
+	for i, t := range oar.askArrivals.values() {
 		if t >= cutoffTime {
 			firstValid = i
 			break
 		}
 	}
 	if firstValid > 0 {
-		oar.askArrivals = oar.askArrivals[firstValid:]
+		oar.askArrivals.dropFirst(firstValid)
 	}
 }
 
 // calculateRates calculates arrival rates
 func (oar *OrderArrivalRate) calculateRates() {
-	if len(oar.bidArrivals) == 0 && len(oar.askArrivals) == 0 {
+	if oar.bidArrivals.len() == 0 && oar.askArrivals.len() == 0 {
 		oar.bidArrivalRate = 0
 		oar.askArrivalRate = 0
 		oar.totalArrivalRate = 0
@@ -260,8 +252,8 @@ func (oar *OrderArrivalRate) calculateRates() {
 
 	// Calculate rates (arrivals per second)
 	windowSeconds := oar.windowDuration.Seconds()
-	oar.bidArrivalRate = float64(len(oar.bidArrivals)) / windowSeconds
-	oar.askArrivalRate = float64(len(oar.askArrivals)) / windowSeconds
+	oar.bidArrivalRate = float64(oar.bidArrivals.len()) / windowSeconds
+	oar.askArrivalRate = float64(oar.askArrivals.len()) / windowSeconds
 	oar.totalArrivalRate = oar.bidArrivalRate + oar.askArrivalRate
 
 	// Calculate imbalance
@@ -26,8 +26,8 @@ import (
 type PriceChannel struct {
 	*BaseIndicator
 	period       int
-	highs        []float64
-	lows         []float64
+	highs        window[float64]
+	lows         window[float64]
 	upperChannel float64
 	lowerChannel float64
 }
@@ -44,8 +44,8 @@ func NewPriceChannel(period int, maxHistory int) *PriceChannel {
 	return &PriceChannel{
 		BaseIndicator: NewBaseIndicator("Price Channel", maxHistory),
 		period:        period,
-		highs:         make([]float64, 0, period),
-		lows:          make([]float64, 0, period),
+		highs:         newWindow[float64](period),
+		lows:          newWindow[float64](period),
 	}
 }
 
@@ -87,33 +87,33 @@ func (p *PriceChannel) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store high and low
-	p.highs = append(p.highs, high)
-	p.lows = append(p.lows, low)
+	p.highs.push(high)
+	p.lows.push(low)
 
 	// Keep only period values
-	if len(p.highs) > p.period {
-		p.highs = p.highs[1:]
-		p.lows = p.lows[1:]
+	if p.highs.len() > p.period {
+		p.highs.dropOldest()
+		p.lows.dropOldest()
 	}
 
 	// Need at least period values
-	if len(p.highs) < p.period {
+	if p.highs.len() < p.period {
 		return
 	}
 
 	// Calculate upper channel (highest high)
-	p.upperChannel = p.highs[0]
-	for i := 1; i < len(p.highs); i++ {
-		if p.highs[i] > p.upperChannel {
-			p.upperChannel = p.highs[i]
+	p.upperChannel = p.highs.at(0)
+	for i := 1; i < p.highs.len(); i++ {
+		if p.highs.at(i) > p.upperChannel {
+			p.upperChannel = p.highs.at(i)
 		}
 	}
 
 	// Calculate lower channel (lowest low)
-	p.lowerChannel = p.lows[0]
-	for i := 1; i < len(p.lows); i++ {
-		if p.lows[i] < p.lowerChannel {
-			p.lowerChannel = p.lows[i]
+	p.lowerChannel = p.lows.at(0)
+	for i := 1; i < p.lows.len(); i++ {
+		if p.lows.at(i) < p.lowerChannel {
+			p.lowerChannel = p.lows.at(i)
 		}
 	}
 
@@ -144,15 +144,15 @@ func (p *PriceChannel) GetChannelWidth() float64 {
 // Reset resets the indicator
 func (p *PriceChannel) Reset() {
 	p.BaseIndicator.Reset()
-	p.highs = p.highs[:0]
-	p.lows = p.lows[:0]
+	p.highs.reset()
+	p.lows.reset()
 	p.upperChannel = 0
 	p.lowerChannel = 0
 }
 
 // IsReady returns true if the indicator has enough data
 func (p *PriceChannel) IsReady() bool {
-	return len(p.highs) >= p.period
+	return p.highs.len() >= p.period
 }
 
 // GetPeriod returns the period
@@ -98,18 +98,18 @@ func (p *PVT) Reset() {
 
 // IsReady returns true if the indicator has enough data
 func (p *PVT) IsReady() bool {
-	return p.prevClose > 0 && len(p.values) > 0
+	return p.prevClose > 0 && p.Len() > 0
 }
 
 // GetTrend returns the trend direction based on PVT slope
 // Returns 1 for uptrend, -1 for downtrend, 0 for neutral
 func (p *PVT) GetTrend() int {
-	if len(p.values) < 2 {
+	if p.Len() < 2 {
 		return 0
 	}
 
-	current := p.values[len(p.values)-1]
-	previous := p.values[len(p.values)-2]
+	current := p.ValueAgo(0)
+	previous := p.ValueAgo(1)
 
 	if current > previous {
 		return 1 // Uptrend
@@ -123,13 +123,12 @@ func (p *PVT) GetTrend() int {
 // GetSlope returns the average slope over the last N periods
 // Positive slope indicates accumulation, negative indicates distribution
 func (p *PVT) GetSlope(periods int) float64 {
-	if len(p.values) < periods {
+	if p.Len() < periods {
 		return 0
 	}
 
-	n := len(p.values)
-	start := p.values[n-periods]
-	end := p.values[n-1]
+	start := p.ValueAgo(periods-1)
+	end := p.ValueAgo(0)
 
 	// Average slope per period
 	return (end - start) / float64(periods)
@@ -138,16 +137,15 @@ func (p *PVT) GetSlope(periods int) float64 {
 // IsDivergence checks for bullish or bearish divergence
 // Returns: 1 for bullish divergence, -1 for bearish divergence, 0 for no divergence
 func (p *PVT) IsDivergence(prices []float64, lookback int) int {
-	if len(p.values) < lookback || len(prices) < lookback {
+	if p.Len() < lookback || len(prices) < lookback {
 		return 0
 	}
 
 	// Get recent values
-	n := len(p.values)
 	pn := len(prices)
 
-	pvtStart := p.values[n-lookback]
-	pvtEnd := p.values[n-1]
+	pvtStart := p.ValueAgo(lookback-1)
+	pvtEnd := p.ValueAgo(0)
 	priceStart := prices[pn-lookback]
 	priceEnd := prices[pn-1]
 
@@ -171,15 +169,14 @@ func (p *PVT) IsDivergence(prices []float64, lookback int) int {
 // IsConfirmingTrend checks if PVT confirms the price trend
 // Returns true if both price and PVT are moving in the same direction
 func (p *PVT) IsConfirmingTrend(prices []float64, lookback int) bool {
-	if len(p.values) < lookback || len(prices) < lookback {
+	if p.Len() < lookback || len(prices) < lookback {
 		return false
 	}
 
-	n := len(p.values)
 	pn := len(prices)
 
-	pvtStart := p.values[n-lookback]
-	pvtEnd := p.values[n-1]
+	pvtStart := p.ValueAgo(lookback-1)
+	pvtEnd := p.ValueAgo(0)
 	priceStart := prices[pn-lookback]
 	priceEnd := prices[pn-1]
 
@@ -193,13 +190,12 @@ func (p *PVT) IsConfirmingTrend(prices []float64, lookback int) bool {
 // GetStrength returns the trend strength based on PVT absolute change
 // Higher values indicate stronger trends
 func (p *PVT) GetStrength(periods int) float64 {
-	if len(p.values) < periods {
+	if p.Len() < periods {
 		return 0
 	}
 
-	n := len(p.values)
-	start := p.values[n-periods]
-	end := p.values[n-1]
+	start := p.ValueAgo(periods-1)
+	end := p.ValueAgo(0)
 
 	// Absolute change normalized by periods
 	return (end - start) / float64(periods)
@@ -24,8 +24,8 @@ type QuoteStability struct {
 	// Historical tracking
 	prevBidPrice  float64   // Previous bid price
 	prevAskPrice  float64   // Previous ask price
-	bidChanges    []float64 // Recent bid price changes
-	askChanges    []float64 // Recent ask price changes
+	bidChanges    window[float64] // Recent bid price changes
+	askChanges    window[float64] // Recent ask price changes
 
 	// Change counters
 	bidChangeCount int // Number of bid changes
@@ -48,8 +48,8 @@ func NewQuoteStability(name string, windowSize int, maxHistory int) *QuoteStabil
 	qs := &QuoteStability{
 		BaseIndicator: NewBaseIndicator(name, maxHistory),
 		windowSize:    windowSize,
-		bidChanges:    make([]float64, 0, windowSize),
-		askChanges:    make([]float64, 0, windowSize),
+		bidChanges:    newWindow[float64](windowSize),
+		askChanges:    newWindow[float64](windowSize),
 	}
 
 	return qs
@@ -134,18 +134,18 @@ func (qs *QuoteStability) Update(md *mdpb.MarketDataUpdate) {
 
 // addChange adds a price change to the rolling window
 func (qs *QuoteStability) addChange(bidChange, askChange float64) {
-	qs.bidChanges = append(qs.bidChanges, bidChange)
-	qs.askChanges = append(qs.askChanges, askChange)
+	qs.bidChanges.push(bidChange)
+	qs.askChanges.push(askChange)
 }
 
 // removeOldest removes the oldest change from the window
 func (qs *QuoteStability) removeOldest() {
-	if len(qs.bidChanges) > 0 {
-		oldBidChange := qs.bidChanges[0]
-		oldAskChange := qs.askChanges[0]
+	if qs.bidChanges.len() > 0 {
+		oldBidChange := qs.bidChanges.at(0)
+		oldAskChange := qs.askChanges.at(0)
 
-		qs.bidChanges = qs.bidChanges[1:]
-		qs.askChanges = qs.askChanges[1:]
+		qs.bidChanges.dropOldest()
+		qs.askChanges.dropOldest()
 
 		if oldBidChange > 0 {
 			qs.bidChangeCount--
@@ -173,12 +173,12 @@ func (qs *QuoteStability) calculateStability() {
 	qs.changeFrequency = (bidFreq + askFreq) / 2.0
 
 	// 2. Average magnitude of changes (lower = more stable)
-	bidMagnitude := qs.calculateAverage(qs.bidChanges)
-	askMagnitude := qs.calculateAverage(qs.askChanges)
+	bidMagnitude := qs.calculateAverage(qs.bidChanges.values())
+	askMagnitude := qs.calculateAverage(qs.askChanges.values())
 
 	// 3. Volatility of changes (lower = more stable)
-	bidVolatility := qs.calculateStdDev(qs.bidChanges)
-	askVolatility := qs.calculateStdDev(qs.askChanges)
+	bidVolatility := qs.calculateStdDev(qs.bidChanges.values())
+	askVolatility := qs.calculateStdDev(qs.askChanges.values())
 
 	// Calculate stability scores (0-100, higher = more stable)
 	// Less frequent changes + smaller magnitude + lower volatility = higher stability
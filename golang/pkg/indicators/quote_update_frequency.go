@@ -24,7 +24,7 @@ type QuoteUpdateFrequency struct {
 	// State tracking
 	prevBidPrice   float64   // Previous bid price
 	prevAskPrice   float64   // Previous ask price
-	updateTimes    []int64   // Timestamps of quote updates (nanoseconds)
+	updateTimes    window[int64]   // Timestamps of quote updates (nanoseconds)
 
 	// Current state
 	frequency         float64 // Updates per second
@@ -47,7 +47,7 @@ func NewQuoteUpdateFrequency(name string, windowDuration time.Duration, maxHisto
 	quf := &QuoteUpdateFrequency{
 		BaseIndicator:  NewBaseIndicator(name, maxHistory),
 		windowDuration: windowDuration,
-		updateTimes:    make([]int64, 0, 1000),
+		updateTimes:    newWindow[int64](1000),
 	}
 
 	return quf
@@ -113,7 +113,7 @@ func (quf *QuoteUpdateFrequency) Update(md *mdpb.MarketDataUpdate) {
 	// Record update time if any quote changed
 	if bidChanged || askChanged {
 		currentTime := time.Now().UnixNano()
-		quf.updateTimes = append(quf.updateTimes, currentTime)
+		quf.updateTimes.push(currentTime)
 		quf.totalUpdates++
 
 		// Remove old timestamps outside window
@@ -137,7 +137,7 @@ func (quf *QuoteUpdateFrequency) cleanOldTimestamps(currentTime int64) {
 
 	// Find first index within window
 	firstValid := 0
-	for i, t := range quf.updateTimes {
+	for i, t := range quf.updateTimes.values() {
 		if t >= cutoffTime {
 			firstValid = i
 			break
@@ -146,18 +146,18 @@ func (quf *QuoteUpdateFrequency) cleanOldTimestamps(currentTime int64) {
 
 	// Remove old timestamps
 	if firstValid > 0 {
-		quf.updateTimes = quf.updateTimes[firstValid:]
+		quf.updateTimes.dropFirst(firstValid)
 	}
 
 	// Recalculate counters
 	// Note: This is approximate since we only track timestamps, not which side changed
 	// For exact tracking, we'd need to store (timestamp, side) pairs
-	quf.totalUpdates = len(quf.updateTimes)
+	quf.totalUpdates = quf.updateTimes.len()
 }
 
 // calculateFrequencies calculates update frequencies
 func (quf *QuoteUpdateFrequency) calculateFrequencies() {
-	if len(quf.updateTimes) < 2 {
+	if quf.updateTimes.len() < 2 {
 		quf.frequency = 0
 		quf.bidUpdateFreq = 0
 		quf.askUpdateFreq = 0
@@ -166,19 +166,19 @@ func (quf *QuoteUpdateFrequency) calculateFrequencies() {
 	}
 
 	// Calculate overall frequency (updates per second)
-	timespanNanos := quf.updateTimes[len(quf.updateTimes)-1] - quf.updateTimes[0]
+	timespanNanos := quf.updateTimes.at(quf.updateTimes.len()-1) - quf.updateTimes.at(0)
 	if timespanNanos > 0 {
 		timespanSeconds := float64(timespanNanos) / float64(time.Second)
-		quf.frequency = float64(len(quf.updateTimes)) / timespanSeconds
+		quf.frequency = float64(quf.updateTimes.len()) / timespanSeconds
 	}
 
 	// Calculate average interval between updates (milliseconds)
-	if len(quf.updateTimes) > 1 {
+	if quf.updateTimes.len() > 1 {
 		totalInterval := int64(0)
-		for i := 1; i < len(quf.updateTimes); i++ {
-			totalInterval += quf.updateTimes[i] - quf.updateTimes[i-1]
+		for i := 1; i < quf.updateTimes.len(); i++ {
+			totalInterval += quf.updateTimes.at(i) - quf.updateTimes.at(i-1)
 		}
-		avgIntervalNanos := totalInterval / int64(len(quf.updateTimes)-1)
+		avgIntervalNanos := totalInterval / int64(quf.updateTimes.len()-1)
 		quf.avgUpdateInterval = float64(avgIntervalNanos) / float64(time.Millisecond)
 	}
 
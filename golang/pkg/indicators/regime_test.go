@@ -0,0 +1,212 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// gaussSeq returns a deterministic approximately-normal sequence (sum of 12 uniforms - 6)
+func gaussSeq(n int, seed uint32) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		s := 0.0
+		for j := 0; j < 12; j++ {
+			seed ^= seed << 13
+			seed ^= seed >> 17
+			seed ^= seed << 5
+			s += float64(seed) / float64(math.MaxUint32)
+		}
+		out[i] = s - 6
+	}
+	return out
+}
+
+func TestVolatilityRegime_DetectsHighVolatility(t *testing.T) {
+	vr := NewVolatilityRegime("test", 2, 100, 0.98, 0.4, 4.0, 100)
+	noise := gaussSeq(600, 7)
+
+	x := 0.0
+	vr.UpdateWithValue(x)
+	for i := 0; i < 400; i++ {
+		x += noise[i]
+		vr.UpdateWithValue(x)
+	}
+	if !vr.IsReady() {
+		t.Fatal("Expected ready after warmup")
+	}
+	if vr.GetState() != 0 {
+		t.Errorf("Expected normal state in calm period, got %d (P(high)=%.3f)", vr.GetState(), vr.GetValue())
+	}
+	calm := vr.GetValue()
+
+	// 5x volatility burst
+	for i := 400; i < 430; i++ {
+		x += 5 * noise[i]
+		vr.UpdateWithValue(x)
+	}
+	if vr.GetState() != 1 {
+		t.Errorf("Expected high state after burst, got %d", vr.GetState())
+	}
+	if !vr.IsHighVolatility(0.7) {
+		t.Errorf("Expected P(high) >= 0.7 after burst, got %.3f (calm %.3f)", vr.GetValue(), calm)
+	}
+}
+
+func TestVolatilityRegime_ThreeStates(t *testing.T) {
+	vr := NewVolatilityRegime("test", 3, 100, 0.98, 0.4, 4.0, 100)
+	noise := gaussSeq(500, 11)
+
+	x := 0.0
+	for i := 0; i < 300; i++ {
+		x += noise[i]
+		vr.UpdateWithValue(x)
+	}
+	// Very quiet period
+	for i := 300; i < 360; i++ {
+		x += 0.2 * noise[i]
+		vr.UpdateWithValue(x)
+	}
+	if vr.GetState() != 0 {
+		t.Errorf("Expected low state in quiet period, got %d", vr.GetState())
+	}
+
+	sum := 0.0
+	for j := 0; j < vr.NumStates(); j++ {
+		sum += vr.StateProbability(j)
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Expected probabilities to sum to 1, got %f", sum)
+	}
+}
+
+func TestVolatilityRegime_ConstantSeries(t *testing.T) {
+	vr := NewVolatilityRegime("test", 2, 10, 0.98, 0.4, 4.0, 100)
+	for i := 0; i < 50; i++ {
+		vr.UpdateWithValue(100)
+	}
+	if math.IsNaN(vr.GetValue()) {
+		t.Error("Expected non-NaN value for constant series")
+	}
+}
+
+func TestVolatilityRegime_InvalidConfig(t *testing.T) {
+	_, err := NewVolatilityRegimeFromConfig(map[string]interface{}{"states": float64(4)})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Expected ErrInvalidParameter for states=4, got %v", err)
+	}
+	_, err = NewVolatilityRegimeFromConfig(map[string]interface{}{"stay_prob": float64(1)})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Expected ErrInvalidParameter for stay_prob=1, got %v", err)
+	}
+}
+
+func TestVarianceRatio_RandomWalk(t *testing.T) {
+	v := NewVarianceRatio("test", 400, 5, 100)
+	noise := gaussSeq(400, 3)
+
+	x := 0.0
+	for _, e := range noise {
+		x += e
+		v.UpdateWithValue(x)
+	}
+	if !v.IsReady() {
+		t.Fatal("Expected ready")
+	}
+	if math.Abs(v.GetHurst()-0.5) > 0.15 {
+		t.Errorf("Expected Hurst near 0.5 for random walk, got %.3f (VR=%.3f)", v.GetHurst(), v.GetValue())
+	}
+}
+
+func TestVarianceRatio_MeanReverting(t *testing.T) {
+	v := NewVarianceRatio("test", 400, 5, 100)
+	noise := gaussSeq(400, 5)
+
+	// AR(1) with strong pull to zero
+	x := 0.0
+	for _, e := range noise {
+		x = 0.3*x + e
+		v.UpdateWithValue(x)
+	}
+	if v.GetValue() >= 1 {
+		t.Errorf("Expected VR < 1 for AR(1), got %.3f", v.GetValue())
+	}
+	if !v.IsMeanReverting(0.5) {
+		t.Errorf("Expected mean reverting, Hurst=%.3f", v.GetHurst())
+	}
+	if v.GetZStat() > -1.96 {
+		t.Errorf("Expected significant negative z stat, got %.3f", v.GetZStat())
+	}
+}
+
+func TestVarianceRatio_Trending(t *testing.T) {
+	v := NewVarianceRatio("test", 400, 5, 100)
+	noise := gaussSeq(400, 9)
+
+	// Positively autocorrelated increments
+	x, d := 0.0, 0.0
+	for _, e := range noise {
+		d = 0.6*d + e
+		x += d
+		v.UpdateWithValue(x)
+	}
+	if v.GetHurst() <= 0.5 {
+		t.Errorf("Expected Hurst > 0.5 for trending series, got %.3f", v.GetHurst())
+	}
+}
+
+func TestCUSUM_DetectsMeanShift(t *testing.T) {
+	c := NewCUSUM("test", 5, 0.5, 100, 100)
+	noise := gaussSeq(400, 13)
+
+	for i := 0; i < 200; i++ {
+		c.UpdateWithValue(noise[i])
+	}
+	if c.LastChange() != 0 {
+		t.Errorf("Expected no change on stationary noise, got %d", c.LastChange())
+	}
+	if c.ChangedWithin(1000) {
+		t.Error("Expected ChangedWithin false before any change")
+	}
+
+	// Shift the mean up by 3 std
+	detectedAt := -1
+	for i := 200; i < 260; i++ {
+		c.UpdateWithValue(3 + noise[i])
+		if c.Signal() == 1 && detectedAt < 0 {
+			detectedAt = i
+		}
+	}
+	if detectedAt < 0 {
+		t.Fatal("Expected upward change to be detected")
+	}
+	if detectedAt-200 > 10 {
+		t.Errorf("Expected detection within 10 observations, got %d", detectedAt-200)
+	}
+	if c.LastChange() != 1 {
+		t.Errorf("Expected last change +1, got %d", c.LastChange())
+	}
+	if !c.ChangedWithin(60) {
+		t.Errorf("Expected change within 60 bars, bars since = %d", c.BarsSinceChange())
+	}
+}
+
+func TestCUSUM_DownwardShift(t *testing.T) {
+	c := NewCUSUM("test", 5, 0.5, 50, 100)
+	noise := gaussSeq(200, 17)
+
+	for i := 0; i < 100; i++ {
+		c.UpdateWithValue(noise[i])
+	}
+	for i := 100; i < 140; i++ {
+		c.UpdateWithValue(-4 + noise[i])
+	}
+	if c.LastChange() != -1 {
+		t.Errorf("Expected last change -1, got %d", c.LastChange())
+	}
+
+	c.Reset()
+	if c.IsReady() || c.BarsSinceChange() != -1 {
+		t.Error("Expected reset state")
+	}
+}
@@ -27,8 +27,8 @@ type ResilienceScore struct {
 	// Historical tracking
 	prevTotalDepth float64   // Previous total depth
 	prevSpread     float64   // Previous spread
-	depthChanges   []float64 // Recent depth changes
-	spreadChanges  []float64 // Recent spread changes
+	depthChanges   window[float64] // Recent depth changes
+	spreadChanges  window[float64] // Recent spread changes
 
 	// Current state
 	depthRecoveryRate  float64 // How fast depth is replenished
@@ -50,8 +50,8 @@ func NewResilienceScore(name string, levels int, windowSize int, maxHistory int)
 		levels:        levels,
 		windowSize:    windowSize,
 		alpha:         0.2, // Default smoothing
-		depthChanges:  make([]float64, 0, windowSize),
-		spreadChanges: make([]float64, 0, windowSize),
+		depthChanges:  newWindow[float64](windowSize),
+		spreadChanges: newWindow[float64](windowSize),
 	}
 
 	return rs
@@ -146,23 +146,23 @@ func (rs *ResilienceScore) Update(md *mdpb.MarketDataUpdate) {
 
 // trackDepthChange tracks depth change
 func (rs *ResilienceScore) trackDepthChange(change float64) {
-	rs.depthChanges = append(rs.depthChanges, change)
-	if len(rs.depthChanges) > rs.windowSize {
-		rs.depthChanges = rs.depthChanges[1:]
+	rs.depthChanges.push(change)
+	if rs.depthChanges.len() > rs.windowSize {
+		rs.depthChanges.dropOldest()
 	}
 }
 
 // trackSpreadChange tracks spread change
 func (rs *ResilienceScore) trackSpreadChange(change float64) {
-	rs.spreadChanges = append(rs.spreadChanges, change)
-	if len(rs.spreadChanges) > rs.windowSize {
-		rs.spreadChanges = rs.spreadChanges[1:]
+	rs.spreadChanges.push(change)
+	if rs.spreadChanges.len() > rs.windowSize {
+		rs.spreadChanges.dropOldest()
 	}
 }
 
 // calculateDepthRecoveryRate calculates how fast depth recovers
 func (rs *ResilienceScore) calculateDepthRecoveryRate() float64 {
-	if len(rs.depthChanges) < 2 {
+	if rs.depthChanges.len() < 2 {
 		return 0
 	}
 
@@ -170,7 +170,7 @@ func (rs *ResilienceScore) calculateDepthRecoveryRate() float64 {
 	positiveChanges := 0
 	totalPositive := 0.0
 
-	for _, change := range rs.depthChanges {
+	for _, change := range rs.depthChanges.values() {
 		if change > 0 {
 			positiveChanges++
 			totalPositive += change
@@ -182,7 +182,7 @@ func (rs *ResilienceScore) calculateDepthRecoveryRate() float64 {
 	}
 
 	// Recovery rate = proportion of positive changes * average magnitude
-	proportion := float64(positiveChanges) / float64(len(rs.depthChanges))
+	proportion := float64(positiveChanges) / float64(rs.depthChanges.len())
 	avgMagnitude := totalPositive / float64(positiveChanges)
 
 	return proportion * avgMagnitude
@@ -190,7 +190,7 @@ func (rs *ResilienceScore) calculateDepthRecoveryRate() float64 {
 
 // calculateSpreadRecoveryRate calculates how fast spread narrows
 func (rs *ResilienceScore) calculateSpreadRecoveryRate() float64 {
-	if len(rs.spreadChanges) < 2 {
+	if rs.spreadChanges.len() < 2 {
 		return 0
 	}
 
@@ -198,7 +198,7 @@ func (rs *ResilienceScore) calculateSpreadRecoveryRate() float64 {
 	negativeChanges := 0
 	totalNegative := 0.0
 
-	for _, change := range rs.spreadChanges {
+	for _, change := range rs.spreadChanges.values() {
 		if change < 0 {
 			negativeChanges++
 			totalNegative += math.Abs(change)
@@ -210,7 +210,7 @@ func (rs *ResilienceScore) calculateSpreadRecoveryRate() float64 {
 	}
 
 	// Recovery rate = proportion of negative changes * average magnitude
-	proportion := float64(negativeChanges) / float64(len(rs.spreadChanges))
+	proportion := float64(negativeChanges) / float64(rs.spreadChanges.len())
 	avgMagnitude := totalNegative / float64(negativeChanges)
 
 	return proportion * avgMagnitude
@@ -218,15 +218,15 @@ func (rs *ResilienceScore) calculateSpreadRecoveryRate() float64 {
 
 // calculateStabilityScore calculates overall stability (0-100)
 func (rs *ResilienceScore) calculateStabilityScore() float64 {
-	if len(rs.depthChanges) < 2 {
+	if rs.depthChanges.len() < 2 {
 		return 50 // Neutral score
 	}
 
 	// Calculate depth volatility (lower is better)
-	depthVol := rs.calculateVolatility(rs.depthChanges)
+	depthVol := rs.calculateVolatility(rs.depthChanges.values())
 
 	// Calculate spread volatility (lower is better)
-	spreadVol := rs.calculateVolatility(rs.spreadChanges)
+	spreadVol := rs.calculateVolatility(rs.spreadChanges.values())
 
 	// Normalize volatilities to 0-100 scale (lower volatility = higher score)
 	depthScore := math.Max(0, 100-depthVol*10)
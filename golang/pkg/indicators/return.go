@@ -12,7 +12,7 @@ type Return struct {
 	*BaseIndicator
 	returnType   string  // "simple", "log", or "cumulative"
 	period       int     // lookback period for return calculation
-	prices       []float64
+	prices       window[float64]
 	lastValue    float64
 	cumulativeReturn float64
 }
@@ -35,7 +35,7 @@ func NewReturn(returnType string, period int, maxHistory int) *Return {
 		BaseIndicator:    NewBaseIndicator("Return", maxHistory),
 		returnType:       returnType,
 		period:           period,
-		prices:           make([]float64, 0, period+1),
+		prices:           newWindow[float64](period+1),
 		cumulativeReturn: 1.0, // Start at 1.0 for cumulative return
 	}
 }
@@ -74,20 +74,20 @@ func (r *Return) Update(md *mdpb.MarketDataUpdate) {
 		return
 	}
 
-	r.prices = append(r.prices, price)
+	r.prices.push(price)
 
 	// Keep only the necessary price history
-	if len(r.prices) > r.period+1 {
-		r.prices = r.prices[1:]
+	if r.prices.len() > r.period+1 {
+		r.prices.dropOldest()
 	}
 
 	// Need at least period+1 prices to calculate return
-	if len(r.prices) < r.period+1 {
+	if r.prices.len() < r.period+1 {
 		return
 	}
 
-	currentPrice := r.prices[len(r.prices)-1]
-	oldPrice := r.prices[len(r.prices)-1-r.period]
+	currentPrice := r.prices.at(r.prices.len()-1)
+	oldPrice := r.prices.at(r.prices.len()-1-r.period)
 
 	var returnValue float64
 
@@ -140,12 +140,12 @@ func (r *Return) GetCumulativeReturn() float64 {
 // Reset resets the indicator
 func (r *Return) Reset() {
 	r.BaseIndicator.Reset()
-	r.prices = r.prices[:0]
+	r.prices.reset()
 	r.lastValue = 0
 	r.cumulativeReturn = 1.0
 }
 
 // IsReady returns true if we have enough data
 func (r *Return) IsReady() bool {
-	return len(r.prices) > r.period
+	return r.prices.len() > r.period
 }
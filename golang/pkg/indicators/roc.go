@@ -12,7 +12,7 @@ import (
 type ROC struct {
 	*BaseIndicator
 	period      int
-	prices      []float64
+	prices      window[float64]
 	initialized bool
 }
 
@@ -29,7 +29,7 @@ func NewROC(period int, maxHistory int) *ROC {
 	return &ROC{
 		BaseIndicator: NewBaseIndicator("ROC", maxHistory),
 		period:        period,
-		prices:        make([]float64, 0, period+1),
+		prices:        newWindow[float64](period+1),
 		initialized:   false,
 	}
 }
@@ -70,15 +70,15 @@ func (r *ROC) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add new price
-	r.prices = append(r.prices, midPrice)
+	r.prices.push(midPrice)
 
 	// Keep period+1 prices (current + N historical)
-	if len(r.prices) > r.period+1 {
-		r.prices = r.prices[1:]
+	if r.prices.len() > r.period+1 {
+		r.prices.dropOldest()
 	}
 
 	// Calculate ROC when we have enough data
-	if len(r.prices) == r.period+1 {
+	if r.prices.len() == r.period+1 {
 		r.initialized = true
 		roc := r.calculateROC()
 		r.AddValue(roc)
@@ -87,8 +87,8 @@ func (r *ROC) Update(md *mdpb.MarketDataUpdate) {
 
 // calculateROC computes the rate of change
 func (r *ROC) calculateROC() float64 {
-	currentPrice := r.prices[len(r.prices)-1]
-	pastPrice := r.prices[0]
+	currentPrice := r.prices.at(r.prices.len()-1)
+	pastPrice := r.prices.at(0)
 
 	// Avoid division by zero
 	if pastPrice == 0 {
@@ -109,13 +109,13 @@ func (r *ROC) GetValue() float64 {
 
 // IsReady returns true if the indicator has enough data
 func (r *ROC) IsReady() bool {
-	return r.initialized && len(r.prices) == r.period+1
+	return r.initialized && r.prices.len() == r.period+1
 }
 
 // Reset resets the indicator state
 func (r *ROC) Reset() {
 	r.BaseIndicator.Reset()
-	r.prices = make([]float64, 0, r.period+1)
+	r.prices.reset()
 	r.initialized = false
 }
 
@@ -126,7 +126,7 @@ func (r *ROC) GetPeriod() int {
 
 // GetPrices returns the current price window (for testing)
 func (r *ROC) GetPrices() []float64 {
-	result := make([]float64, len(r.prices))
-	copy(result, r.prices)
+	result := make([]float64, r.prices.len())
+	copy(result, r.prices.values())
 	return result
 }
@@ -9,8 +9,8 @@ import (
 type RSI struct {
 	*BaseIndicator
 	period     int
-	gains      []float64
-	losses     []float64
+	gains      window[float64]
+	losses     window[float64]
 	avgGain    float64
 	avgLoss    float64
 	lastPrice  float64
@@ -21,13 +21,10 @@ type RSI struct {
 // NewRSI creates a new RSI indicator
 func NewRSI(period float64, maxHistory int) *RSI {
 	return &RSI{
-		BaseIndicator: &BaseIndicator{
-			name:       "RSI",
-			maxHistory: maxHistory,
-		},
+		BaseIndicator: NewBaseIndicator("RSI", maxHistory),
 		period:  int(period),
-		gains:   make([]float64, 0, maxHistory),
-		losses:  make([]float64, 0, maxHistory),
+		gains:   newWindow[float64](maxHistory),
+		losses:  newWindow[float64](maxHistory),
 		isInit:  false,
 	}
 }
@@ -54,31 +51,31 @@ func (rsi *RSI) Update(md *mdpb.MarketDataUpdate) {
 		}
 
 		// Add to history
-		rsi.gains = append(rsi.gains, gain)
-		rsi.losses = append(rsi.losses, loss)
+		rsi.gains.push(gain)
+		rsi.losses.push(loss)
 
 		// Limit history size
-		if len(rsi.gains) > rsi.maxHistory {
-			rsi.gains = rsi.gains[1:]
-			rsi.losses = rsi.losses[1:]
+		if rsi.gains.len() > rsi.maxHistory {
+			rsi.gains.dropOldest()
+			rsi.losses.dropOldest()
 		}
 
 		// Calculate average gain/loss
-		if len(rsi.gains) < rsi.period {
+		if rsi.gains.len() < rsi.period {
 			// Not enough data yet, use simple average
 			sumGain, sumLoss := 0.0, 0.0
-			for i := 0; i < len(rsi.gains); i++ {
-				sumGain += rsi.gains[i]
-				sumLoss += rsi.losses[i]
+			for i := 0; i < rsi.gains.len(); i++ {
+				sumGain += rsi.gains.at(i)
+				sumLoss += rsi.losses.at(i)
 			}
-			rsi.avgGain = sumGain / float64(len(rsi.gains))
-			rsi.avgLoss = sumLoss / float64(len(rsi.losses))
-		} else if len(rsi.gains) == rsi.period {
+			rsi.avgGain = sumGain / float64(rsi.gains.len())
+			rsi.avgLoss = sumLoss / float64(rsi.losses.len())
+		} else if rsi.gains.len() == rsi.period {
 			// First time we have enough data, calculate simple average
 			sumGain, sumLoss := 0.0, 0.0
 			for i := 0; i < rsi.period; i++ {
-				sumGain += rsi.gains[i]
-				sumLoss += rsi.losses[i]
+				sumGain += rsi.gains.at(i)
+				sumLoss += rsi.losses.at(i)
 			}
 			rsi.avgGain = sumGain / float64(rsi.period)
 			rsi.avgLoss = sumLoss / float64(rsi.period)
@@ -116,13 +113,13 @@ func (rsi *RSI) GetValues() []float64 {
 
 // IsReady returns true if the indicator has enough data
 func (rsi *RSI) IsReady() bool {
-	return rsi.isInit && len(rsi.gains) >= rsi.period
+	return rsi.isInit && rsi.gains.len() >= rsi.period
 }
 
 // Reset resets the indicator state
 func (rsi *RSI) Reset() {
-	rsi.gains = make([]float64, 0, rsi.maxHistory)
-	rsi.losses = make([]float64, 0, rsi.maxHistory)
+	rsi.gains.reset()
+	rsi.losses.reset()
 	rsi.avgGain = 0
 	rsi.avgLoss = 0
 	rsi.lastPrice = 0
@@ -129,12 +129,12 @@ func TestRSI_Reset(t *testing.T) {
 		t.Errorf("RSI value should be 0 after reset, got %.2f", rsi.GetValue())
 	}
 
-	if len(rsi.gains) != 0 {
-		t.Errorf("Gains history should be empty after reset, got %d", len(rsi.gains))
+	if rsi.gains.len() != 0 {
+		t.Errorf("Gains history should be empty after reset, got %d", rsi.gains.len())
 	}
 
-	if len(rsi.losses) != 0 {
-		t.Errorf("Losses history should be empty after reset, got %d", len(rsi.losses))
+	if rsi.losses.len() != 0 {
+		t.Errorf("Losses history should be empty after reset, got %d", rsi.losses.len())
 	}
 }
 
@@ -173,12 +173,12 @@ func TestRSI_HistoryLimit(t *testing.T) {
 		rsi.Update(md)
 	}
 
-	if len(rsi.gains) > maxHistory {
-		t.Errorf("Gains history should not exceed maxHistory %d, got %d", maxHistory, len(rsi.gains))
+	if rsi.gains.len() > maxHistory {
+		t.Errorf("Gains history should not exceed maxHistory %d, got %d", maxHistory, rsi.gains.len())
 	}
 
-	if len(rsi.losses) > maxHistory {
-		t.Errorf("Losses history should not exceed maxHistory %d, got %d", maxHistory, len(rsi.losses))
+	if rsi.losses.len() > maxHistory {
+		t.Errorf("Losses history should not exceed maxHistory %d, got %d", maxHistory, rsi.losses.len())
 	}
 }
 
@@ -12,7 +12,7 @@ import (
 type SharpeRatioIndicator struct {
 	*BaseIndicator
 	period       int
-	returns      []float64
+	returns      window[float64]
 	prevPrice    float64
 	riskFreeRate float64 // annualized risk-free rate
 	lastValue    float64
@@ -33,7 +33,7 @@ func NewSharpeRatioIndicator(period int, riskFreeRate float64, annualize bool, m
 	return &SharpeRatioIndicator{
 		BaseIndicator: NewBaseIndicator("SharpeRatio", maxHistory),
 		period:        period,
-		returns:       make([]float64, 0, period),
+		returns:       newWindow[float64](period),
 		riskFreeRate:  riskFreeRate,
 		annualize:     annualize,
 	}
@@ -83,23 +83,23 @@ func (s *SharpeRatioIndicator) Update(md *mdpb.MarketDataUpdate) {
 	// Calculate return if we have previous price
 	if s.prevPrice > 0 {
 		ret := (price - s.prevPrice) / s.prevPrice
-		s.returns = append(s.returns, ret)
+		s.returns.push(ret)
 
-		if len(s.returns) > s.period {
-			s.returns = s.returns[1:]
+		if s.returns.len() > s.period {
+			s.returns.dropOldest()
 		}
 	}
 
 	s.prevPrice = price
 
 	// Need at least period returns
-	if len(s.returns) < s.period {
+	if s.returns.len() < s.period {
 		return
 	}
 
 	// Calculate mean and std dev of returns
-	meanReturn := stats.Mean(s.returns)
-	stdReturn := stats.StdDev(s.returns)
+	meanReturn := stats.Mean(s.returns.values())
+	stdReturn := stats.StdDev(s.returns.values())
 
 	if stdReturn == 0 {
 		s.lastValue = 0
@@ -129,14 +129,14 @@ func (s *SharpeRatioIndicator) GetValue() float64 {
 // Reset resets the indicator
 func (s *SharpeRatioIndicator) Reset() {
 	s.BaseIndicator.Reset()
-	s.returns = s.returns[:0]
+	s.returns.reset()
 	s.prevPrice = 0
 	s.lastValue = 0
 }
 
 // IsReady returns true if we have enough data
 func (s *SharpeRatioIndicator) IsReady() bool {
-	return len(s.returns) >= s.period
+	return s.returns.len() >= s.period
 }
 
 // MaxDrawdownIndicator calculates rolling maximum drawdown
@@ -144,7 +144,7 @@ func (s *SharpeRatioIndicator) IsReady() bool {
 type MaxDrawdownIndicator struct {
 	*BaseIndicator
 	period      int
-	prices      []float64
+	prices      window[float64]
 	lastValue   float64
 	lastDD      float64
 	lastDDDuration int
@@ -162,7 +162,7 @@ func NewMaxDrawdownIndicator(period int, maxHistory int) *MaxDrawdownIndicator {
 	return &MaxDrawdownIndicator{
 		BaseIndicator: NewBaseIndicator("MaxDrawdown", maxHistory),
 		period:        period,
-		prices:        make([]float64, 0, period),
+		prices:        newWindow[float64](period),
 	}
 }
 
@@ -193,29 +193,29 @@ func (m *MaxDrawdownIndicator) Update(md *mdpb.MarketDataUpdate) {
 		return
 	}
 
-	m.prices = append(m.prices, price)
-	if len(m.prices) > m.period {
-		m.prices = m.prices[1:]
+	m.prices.push(price)
+	if m.prices.len() > m.period {
+		m.prices.dropOldest()
 	}
 
-	if len(m.prices) < 2 {
+	if m.prices.len() < 2 {
 		return
 	}
 
 	// Calculate max drawdown over the period
 	var maxDD float64
 	var maxDDDuration int
-	peak := m.prices[0]
+	peak := m.prices.at(0)
 	peakIdx := 0
 
-	for i := 1; i < len(m.prices); i++ {
-		if m.prices[i] > peak {
-			peak = m.prices[i]
+	for i := 1; i < m.prices.len(); i++ {
+		if m.prices.at(i) > peak {
+			peak = m.prices.at(i)
 			peakIdx = i
 		}
 
 		if peak > 0 {
-			dd := (peak - m.prices[i]) / peak
+			dd := (peak - m.prices.at(i)) / peak
 			if dd > maxDD {
 				maxDD = dd
 				maxDDDuration = i - peakIdx
@@ -242,7 +242,7 @@ func (m *MaxDrawdownIndicator) GetDrawdownDuration() int {
 // Reset resets the indicator
 func (m *MaxDrawdownIndicator) Reset() {
 	m.BaseIndicator.Reset()
-	m.prices = m.prices[:0]
+	m.prices.reset()
 	m.lastValue = 0
 	m.lastDD = 0
 	m.lastDDDuration = 0
@@ -250,5 +250,5 @@ func (m *MaxDrawdownIndicator) Reset() {
 
 // IsReady returns true if we have at least 2 prices
 func (m *MaxDrawdownIndicator) IsReady() bool {
-	return len(m.prices) >= 2
+	return m.prices.len() >= 2
 }
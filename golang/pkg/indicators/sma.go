@@ -9,7 +9,7 @@ import (
 type SMA struct {
 	*BaseIndicator
 	period      int
-	prices      []float64
+	prices      window[float64]
 	sum         float64
 	initialized bool
 }
@@ -18,12 +18,9 @@ type SMA struct {
 func NewSMA(period float64, maxHistory int) *SMA {
 	p := int(period)
 	return &SMA{
-		BaseIndicator: &BaseIndicator{
-			name:       "SMA",
-			maxHistory: maxHistory,
-		},
+		BaseIndicator: NewBaseIndicator("SMA", maxHistory),
 		period:      p,
-		prices:      make([]float64, 0, p),
+		prices:      newWindow[float64](p),
 		sum:         0.0,
 		initialized: false,
 	}
@@ -38,18 +35,18 @@ func (sma *SMA) Update(md *mdpb.MarketDataUpdate) {
 	price := (md.BidPrice[0] + md.AskPrice[0]) / 2.0
 
 	// Add new price
-	sma.prices = append(sma.prices, price)
+	sma.prices.push(price)
 	sma.sum += price
 
 	// Remove oldest price if we exceed the period
-	if len(sma.prices) > sma.period {
-		oldest := sma.prices[0]
-		sma.prices = sma.prices[1:]
+	if sma.prices.len() > sma.period {
+		oldest := sma.prices.at(0)
+		sma.prices.dropOldest()
 		sma.sum -= oldest
 	}
 
 	// Calculate average
-	if len(sma.prices) == sma.period {
+	if sma.prices.len() == sma.period {
 		sma.initialized = true
 		avg := sma.sum / float64(sma.period)
 		sma.AddValue(avg)
@@ -66,12 +63,12 @@ func (sma *SMA) GetValue() float64 {
 
 // IsReady returns true if the indicator has enough data
 func (sma *SMA) IsReady() bool {
-	return sma.initialized && len(sma.prices) == sma.period
+	return sma.initialized && sma.prices.len() == sma.period
 }
 
 // Reset resets the indicator state
 func (sma *SMA) Reset() {
-	sma.prices = make([]float64, 0, sma.period)
+	sma.prices.reset()
 	sma.sum = 0.0
 	sma.initialized = false
 	sma.BaseIndicator.Reset()
@@ -84,8 +81,8 @@ func (sma *SMA) GetPeriod() int {
 
 // GetPrices returns the current price window (for testing)
 func (sma *SMA) GetPrices() []float64 {
-	result := make([]float64, len(sma.prices))
-	copy(result, sma.prices)
+	result := make([]float64, sma.prices.len())
+	copy(result, sma.prices.values())
 	return result
 }
 
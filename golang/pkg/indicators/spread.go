@@ -153,8 +153,8 @@ func (es *EffectiveSpread) IsReady() bool {
 type RealizedSpread struct {
 	*BaseIndicator
 	window       int
-	tradePrices  []float64
-	midPrices    []float64
+	tradePrices  window[float64]
+	midPrices    window[float64]
 }
 
 // NewRealizedSpread creates a new RealizedSpread indicator
@@ -166,8 +166,8 @@ func NewRealizedSpread(window int, maxHistory int) *RealizedSpread {
 	return &RealizedSpread{
 		BaseIndicator: NewBaseIndicator("RealizedSpread", maxHistory),
 		window:        window,
-		tradePrices:   make([]float64, 0, window),
-		midPrices:     make([]float64, 0, window),
+		tradePrices:   newWindow[float64](window),
+		midPrices:     newWindow[float64](window),
 	}
 }
 
@@ -180,24 +180,24 @@ func (rs *RealizedSpread) Update(md *mdpb.MarketDataUpdate) {
 
 	if md.LastPrice > 0 {
 		// Add current values
-		rs.tradePrices = append(rs.tradePrices, md.LastPrice)
-		rs.midPrices = append(rs.midPrices, midPrice)
+		rs.tradePrices.push(md.LastPrice)
+		rs.midPrices.push(midPrice)
 
 		// Maintain window size
-		if len(rs.tradePrices) > rs.window {
-			rs.tradePrices = rs.tradePrices[1:]
-			rs.midPrices = rs.midPrices[1:]
+		if rs.tradePrices.len() > rs.window {
+			rs.tradePrices.dropOldest()
+			rs.midPrices.dropOldest()
 		}
 
 		// Calculate realized spread if we have enough data
-		if len(rs.tradePrices) >= 2 {
+		if rs.tradePrices.len() >= 2 {
 			// Average spread over the window
 			var sumSpread float64
-			for i := range rs.tradePrices {
-				spread := 2 * abs(rs.tradePrices[i]-rs.midPrices[i])
+			for i := range rs.tradePrices.values() {
+				spread := 2 * abs(rs.tradePrices.at(i)-rs.midPrices.at(i))
 				sumSpread += spread
 			}
-			avgSpread := sumSpread / float64(len(rs.tradePrices))
+			avgSpread := sumSpread / float64(rs.tradePrices.len())
 			rs.AddValue(avgSpread)
 		}
 	}
@@ -206,13 +206,13 @@ func (rs *RealizedSpread) Update(md *mdpb.MarketDataUpdate) {
 // Reset resets the indicator
 func (rs *RealizedSpread) Reset() {
 	rs.BaseIndicator.Reset()
-	rs.tradePrices = rs.tradePrices[:0]
-	rs.midPrices = rs.midPrices[:0]
+	rs.tradePrices.reset()
+	rs.midPrices.reset()
 }
 
 // IsReady returns true if we have enough data
 func (rs *RealizedSpread) IsReady() bool {
-	return len(rs.tradePrices) >= 2
+	return rs.tradePrices.len() >= 2
 }
 
 // QuotedSpread calculates the spread at different depth levels
@@ -22,7 +22,7 @@ type SpreadVolatility struct {
 	normalized bool // Use relative spread (spread/mid) instead of absolute
 
 	// Historical tracking
-	spreadHistory []float64 // Recent spread values
+	spreadHistory window[float64] // Recent spread values
 
 	// Current state
 	volatility     float64 // Current spread volatility
@@ -43,7 +43,7 @@ func NewSpreadVolatility(name string, windowSize int, normalized bool, maxHistor
 		BaseIndicator: NewBaseIndicator(name, maxHistory),
 		windowSize:    windowSize,
 		normalized:    normalized,
-		spreadHistory: make([]float64, 0, windowSize),
+		spreadHistory: newWindow[float64](windowSize),
 	}
 
 	return sv
@@ -112,11 +112,11 @@ func (sv *SpreadVolatility) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add to history
-	sv.spreadHistory = append(sv.spreadHistory, spread)
+	sv.spreadHistory.push(spread)
 
 	// Remove oldest if window is full
-	if len(sv.spreadHistory) > sv.windowSize {
-		sv.spreadHistory = sv.spreadHistory[1:]
+	if sv.spreadHistory.len() > sv.windowSize {
+		sv.spreadHistory.dropOldest()
 	}
 
 	// Calculate metrics
@@ -127,7 +127,7 @@ func (sv *SpreadVolatility) Update(md *mdpb.MarketDataUpdate) {
 
 // calculateMetrics calculates volatility and related metrics
 func (sv *SpreadVolatility) calculateMetrics() {
-	if len(sv.spreadHistory) < 2 {
+	if sv.spreadHistory.len() < 2 {
 		sv.volatility = 0
 		sv.avgSpread = 0
 		sv.minSpread = 0
@@ -139,10 +139,10 @@ func (sv *SpreadVolatility) calculateMetrics() {
 
 	// Calculate average
 	sum := 0.0
-	min := sv.spreadHistory[0]
-	max := sv.spreadHistory[0]
+	min := sv.spreadHistory.at(0)
+	max := sv.spreadHistory.at(0)
 
-	for _, s := range sv.spreadHistory {
+	for _, s := range sv.spreadHistory.values() {
 		sum += s
 		if s < min {
 			min = s
@@ -152,18 +152,18 @@ func (sv *SpreadVolatility) calculateMetrics() {
 		}
 	}
 
-	sv.avgSpread = sum / float64(len(sv.spreadHistory))
+	sv.avgSpread = sum / float64(sv.spreadHistory.len())
 	sv.minSpread = min
 	sv.maxSpread = max
 	sv.spreadRange = max - min
 
 	// Calculate volatility (standard deviation)
 	variance := 0.0
-	for _, s := range sv.spreadHistory {
+	for _, s := range sv.spreadHistory.values() {
 		diff := s - sv.avgSpread
 		variance += diff * diff
 	}
-	variance /= float64(len(sv.spreadHistory))
+	variance /= float64(sv.spreadHistory.len())
 	sv.volatility = math.Sqrt(variance)
 
 	// Calculate coefficient of variation (CV = std/mean)
@@ -13,7 +13,7 @@ import (
 type StdDev struct {
 	*BaseIndicator
 	period      int
-	prices      []float64
+	prices      window[float64]
 	sum         float64
 	initialized bool
 }
@@ -31,7 +31,7 @@ func NewStdDev(period int, maxHistory int) *StdDev {
 	return &StdDev{
 		BaseIndicator: NewBaseIndicator("StdDev", maxHistory),
 		period:        period,
-		prices:        make([]float64, 0, period),
+		prices:        newWindow[float64](period),
 		sum:           0.0,
 		initialized:   false,
 	}
@@ -73,18 +73,18 @@ func (s *StdDev) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add new price
-	s.prices = append(s.prices, midPrice)
+	s.prices.push(midPrice)
 	s.sum += midPrice
 
 	// Remove oldest price if we exceed the period
-	if len(s.prices) > s.period {
-		oldest := s.prices[0]
-		s.prices = s.prices[1:]
+	if s.prices.len() > s.period {
+		oldest := s.prices.at(0)
+		s.prices.dropOldest()
 		s.sum -= oldest
 	}
 
 	// Calculate standard deviation when we have enough data
-	if len(s.prices) == s.period {
+	if s.prices.len() == s.period {
 		s.initialized = true
 		stddev := s.calculateStdDev()
 		s.AddValue(stddev)
@@ -93,7 +93,7 @@ func (s *StdDev) Update(md *mdpb.MarketDataUpdate) {
 
 // calculateStdDev computes the standard deviation
 func (s *StdDev) calculateStdDev() float64 {
-	n := float64(len(s.prices))
+	n := float64(s.prices.len())
 	if n == 0 {
 		return 0.0
 	}
@@ -103,7 +103,7 @@ func (s *StdDev) calculateStdDev() float64 {
 
 	// Calculate variance: Σ(x - mean)² / N
 	var variance float64
-	for _, price := range s.prices {
+	for _, price := range s.prices.values() {
 		diff := price - mean
 		variance += diff * diff
 	}
@@ -123,13 +123,13 @@ func (s *StdDev) GetValue() float64 {
 
 // IsReady returns true if the indicator has enough data
 func (s *StdDev) IsReady() bool {
-	return s.initialized && len(s.prices) == s.period
+	return s.initialized && s.prices.len() == s.period
 }
 
 // Reset resets the indicator state
 func (s *StdDev) Reset() {
 	s.BaseIndicator.Reset()
-	s.prices = make([]float64, 0, s.period)
+	s.prices.reset()
 	s.sum = 0.0
 	s.initialized = false
 }
@@ -141,8 +141,8 @@ func (s *StdDev) GetPeriod() int {
 
 // GetPrices returns the current price window (for testing)
 func (s *StdDev) GetPrices() []float64 {
-	result := make([]float64, len(s.prices))
-	copy(result, s.prices)
+	result := make([]float64, s.prices.len())
+	copy(result, s.prices.values())
 	return result
 }
 
@@ -151,5 +151,5 @@ func (s *StdDev) GetMean() float64 {
 	if !s.IsReady() {
 		return 0.0
 	}
-	return s.sum / float64(len(s.prices))
+	return s.sum / float64(s.prices.len())
 }
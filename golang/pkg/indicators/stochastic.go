@@ -30,10 +30,10 @@ type Stochastic struct {
 	period       int       // Lookback period for %K
 	smoothK      int       // Smoothing period for %K
 	smoothD      int       // Smoothing period for %D (SMA of %K)
-	highs        []float64 // High prices window
-	lows         []float64 // Low prices window
-	closes       []float64 // Close prices window
-	kValues      []float64 // %K values for %D calculation
+	highs        window[float64] // High prices window
+	lows         window[float64] // Low prices window
+	closes       window[float64] // Close prices window
+	kValues      window[float64] // %K values for %D calculation
 	percentK     float64   // Current %K value
 	percentD     float64   // Current %D value
 	prevK        float64   // Previous %K for crossover detection
@@ -63,10 +63,10 @@ func NewStochastic(period int, smoothK int, smoothD int, maxHistory int) *Stocha
 		period:        period,
 		smoothK:       smoothK,
 		smoothD:       smoothD,
-		highs:         make([]float64, 0, period),
-		lows:          make([]float64, 0, period),
-		closes:        make([]float64, 0, period),
-		kValues:       make([]float64, 0, smoothD),
+		highs:         newWindow[float64](period),
+		lows:          newWindow[float64](period),
+		closes:        newWindow[float64](period),
+		kValues:       newWindow[float64](smoothD),
 	}
 }
 
@@ -123,37 +123,37 @@ func (s *Stochastic) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add to windows
-	s.highs = append(s.highs, high)
-	s.lows = append(s.lows, low)
-	s.closes = append(s.closes, close)
+	s.highs.push(high)
+	s.lows.push(low)
+	s.closes.push(close)
 
 	// Keep only period elements
-	if len(s.highs) > s.period {
-		s.highs = s.highs[1:]
-		s.lows = s.lows[1:]
-		s.closes = s.closes[1:]
+	if s.highs.len() > s.period {
+		s.highs.dropOldest()
+		s.lows.dropOldest()
+		s.closes.dropOldest()
 	}
 
 	// Need at least period values to calculate
-	if len(s.closes) < s.period {
+	if s.closes.len() < s.period {
 		return
 	}
 
 	// Calculate raw %K
 	// %K = 100 × (Close - Lowest Low) / (Highest High - Lowest Low)
-	highestHigh := s.highs[0]
-	lowestLow := s.lows[0]
+	highestHigh := s.highs.at(0)
+	lowestLow := s.lows.at(0)
 
-	for i := 1; i < len(s.highs); i++ {
-		if s.highs[i] > highestHigh {
-			highestHigh = s.highs[i]
+	for i := 1; i < s.highs.len(); i++ {
+		if s.highs.at(i) > highestHigh {
+			highestHigh = s.highs.at(i)
 		}
-		if s.lows[i] < lowestLow {
-			lowestLow = s.lows[i]
+		if s.lows.at(i) < lowestLow {
+			lowestLow = s.lows.at(i)
 		}
 	}
 
-	currentClose := s.closes[len(s.closes)-1]
+	currentClose := s.closes.at(s.closes.len()-1)
 	denominator := highestHigh - lowestLow
 
 	var rawK float64
@@ -164,33 +164,31 @@ func (s *Stochastic) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add raw %K to buffer for smoothing
-	s.kValues = append(s.kValues, rawK)
+	s.kValues.push(rawK)
 
 	// Keep only smoothK elements for %K smoothing
-	if len(s.kValues) > s.smoothK {
-		s.kValues = s.kValues[len(s.kValues)-s.smoothK:]
+	if s.kValues.len() > s.smoothK {
+		s.kValues.keepLast(s.smoothK)
 	}
 
 	// Calculate smoothed %K (SMA of raw %K)
-	if len(s.kValues) >= s.smoothK {
+	if s.kValues.len() >= s.smoothK {
 		sum := 0.0
-		for _, k := range s.kValues {
+		for _, k := range s.kValues.values() {
 			sum += k
 		}
 		s.prevK = s.percentK
-		s.percentK = sum / float64(len(s.kValues))
+		s.percentK = sum / float64(s.kValues.len())
 
 		// Store %K values for %D calculation
 		s.AddValue(s.percentK)
 
 		// Calculate %D (SMA of %K)
-		values := s.GetValues()
-		if len(values) >= s.smoothD {
-			// Get last smoothD values
-			recentK := values[len(values)-s.smoothD:]
+		if s.Len() >= s.smoothD {
+			// Sum last smoothD values
 			sumD := 0.0
-			for _, k := range recentK {
-				sumD += k
+			for i := 0; i < s.smoothD; i++ {
+				sumD += s.ValueAgo(i)
 			}
 			s.prevD = s.percentD
 			s.percentD = sumD / float64(s.smoothD)
@@ -216,10 +214,10 @@ func (s *Stochastic) GetPercentD() float64 {
 // Reset resets the indicator
 func (s *Stochastic) Reset() {
 	s.BaseIndicator.Reset()
-	s.highs = s.highs[:0]
-	s.lows = s.lows[:0]
-	s.closes = s.closes[:0]
-	s.kValues = s.kValues[:0]
+	s.highs.reset()
+	s.lows.reset()
+	s.closes.reset()
+	s.kValues.reset()
 	s.percentK = 0
 	s.percentD = 0
 	s.prevK = 0
@@ -228,7 +226,7 @@ func (s *Stochastic) Reset() {
 
 // IsReady returns true if the indicator has enough data
 func (s *Stochastic) IsReady() bool {
-	return len(s.closes) >= s.period && len(s.kValues) >= s.smoothK && len(s.GetValues()) >= s.smoothD
+	return s.closes.len() >= s.period && s.kValues.len() >= s.smoothK && s.Len() >= s.smoothD
 }
 
 // GetPeriod returns the period
@@ -241,7 +241,7 @@ func TestStochastic_Reset(t *testing.T) {
 			stoch.GetPercentK(), stoch.GetPercentD())
 	}
 
-	if len(stoch.highs) != 0 || len(stoch.lows) != 0 || len(stoch.closes) != 0 {
+	if stoch.highs.len() != 0 || stoch.lows.len() != 0 || stoch.closes.len() != 0 {
 		t.Error("Price windows should be empty after reset")
 	}
 }
@@ -220,12 +220,12 @@ func (t *T3) GetVFactor() float64 {
 // GetTrend returns the trend direction
 // Returns 1 for uptrend, -1 for downtrend, 0 for neutral
 func (t *T3) GetTrend() int {
-	if len(t.values) < 2 {
+	if t.Len() < 2 {
 		return 0
 	}
 
-	current := t.values[len(t.values)-1]
-	previous := t.values[len(t.values)-2]
+	current := t.ValueAgo(0)
+	previous := t.ValueAgo(1)
 
 	if current > previous {
 		return 1
@@ -239,10 +239,9 @@ func (t *T3) GetTrend() int {
 // GetSlope returns the recent slope (last 3 periods)
 // Positive slope indicates uptrend, negative indicates downtrend
 func (t *T3) GetSlope() float64 {
-	if len(t.values) < 3 {
+	if t.Len() < 3 {
 		return 0
 	}
 
-	n := len(t.values)
-	return (t.values[n-1] - t.values[n-3]) / 2.0
+	return (t.ValueAgo(0) - t.ValueAgo(2)) / 2.0
 }
@@ -199,6 +199,21 @@ func TestCointegrationIndicator_Basic(t *testing.T) {
 	}
 }
 
+func TestCointegrationIndicator_Overshoot(t *testing.T) {
+	ind := NewCointegrationIndicator(60, "", 100)
+
+	// Residual flips sign every step (λ ≈ -2): reverts within one step, not "no reversion"
+	for i := 0; i < 60; i++ {
+		price2 := 100.0 + float64(i)*0.5
+		price1 := 2.0*price2 + float64(1-2*(i%2))
+		ind.UpdateWithPair(price1, price2)
+	}
+
+	if score := ind.GetValue(); score != 0 || !ind.IsCointegrated(0.3) {
+		t.Errorf("Overshooting residuals should score 0, got %f", score)
+	}
+}
+
 func TestAllTask4Indicators_FromConfig(t *testing.T) {
 	configs := []struct {
 		name   string
@@ -31,7 +31,7 @@ type TickRule struct {
 	upticks       int // Count of upticks
 	downticks     int // Count of downticks
 	zeroTicks     int // Count of zero ticks
-	tickHistory   []int // Rolling window of tick directions
+	tickHistory   window[int] // Rolling window of tick directions
 
 	// Derived metrics
 	tickBalance   float64 // (upticks - downticks) / total
@@ -47,7 +47,7 @@ func NewTickRule(name string, windowSize int, maxHistory int) *TickRule {
 	tr := &TickRule{
 		BaseIndicator: NewBaseIndicator(name, maxHistory),
 		windowSize:    windowSize,
-		tickHistory:   make([]int, 0, windowSize),
+		tickHistory:   newWindow[int](windowSize),
 	}
 
 	return tr
@@ -115,7 +115,7 @@ func (tr *TickRule) Update(md *mdpb.MarketDataUpdate) {
 	tr.addTick(tr.currentTick)
 
 	// Calculate tick balance
-	total := len(tr.tickHistory)
+	total := tr.tickHistory.len()
 	if total > 0 {
 		tr.tickBalance = float64(tr.upticks-tr.downticks) / float64(total)
 	}
@@ -132,7 +132,7 @@ func (tr *TickRule) Update(md *mdpb.MarketDataUpdate) {
 
 // addTick adds a tick to the rolling window
 func (tr *TickRule) addTick(tick int) {
-	tr.tickHistory = append(tr.tickHistory, tick)
+	tr.tickHistory.push(tick)
 
 	// Update counts
 	switch tick {
@@ -145,9 +145,9 @@ func (tr *TickRule) addTick(tick int) {
 	}
 
 	// Remove oldest tick if window is full
-	if len(tr.tickHistory) > tr.windowSize {
-		oldTick := tr.tickHistory[0]
-		tr.tickHistory = tr.tickHistory[1:]
+	if tr.tickHistory.len() > tr.windowSize {
+		oldTick := tr.tickHistory.at(0)
+		tr.tickHistory.dropOldest()
 
 		switch oldTick {
 		case 1:
@@ -197,7 +197,7 @@ func (tr *TickRule) GetUptickRatio() float64 {
 	tr.mu.RLock()
 	defer tr.mu.RUnlock()
 
-	total := len(tr.tickHistory)
+	total := tr.tickHistory.len()
 	if total == 0 {
 		return 0
 	}
@@ -209,7 +209,7 @@ func (tr *TickRule) GetDowntickRatio() float64 {
 	tr.mu.RLock()
 	defer tr.mu.RUnlock()
 
-	total := len(tr.tickHistory)
+	total := tr.tickHistory.len()
 	if total == 0 {
 		return 0
 	}
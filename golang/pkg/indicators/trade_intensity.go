@@ -22,8 +22,8 @@ type TradeIntensity struct {
 	useVolume      bool          // Use volume instead of trade count
 
 	// Tracking
-	tradeTimestamps []time.Time // Recent trade timestamps
-	tradeVolumes    []float64   // Recent trade volumes (if useVolume=true)
+	tradeTimestamps window[time.Time] // Recent trade timestamps
+	tradeVolumes    window[float64]   // Recent trade volumes (if useVolume=true)
 
 	// Current state
 	intensity float64 // Current trading intensity
@@ -39,8 +39,8 @@ func NewTradeIntensity(name string, windowDuration time.Duration, useVolume bool
 		BaseIndicator:   NewBaseIndicator(name, maxHistory),
 		windowDuration:  windowDuration,
 		useVolume:       useVolume,
-		tradeTimestamps: make([]time.Time, 0, 1000),
-		tradeVolumes:    make([]float64, 0, 1000),
+		tradeTimestamps: newWindow[time.Time](1000),
+		tradeVolumes:    newWindow[float64](1000),
 	}
 
 	return ti
@@ -89,9 +89,9 @@ func (ti *TradeIntensity) Update(md *mdpb.MarketDataUpdate) {
 	now := time.Now()
 
 	// Add current trade
-	ti.tradeTimestamps = append(ti.tradeTimestamps, now)
+	ti.tradeTimestamps.push(now)
 	if ti.useVolume {
-		ti.tradeVolumes = append(ti.tradeVolumes, float64(md.LastQty))
+		ti.tradeVolumes.push(float64(md.LastQty))
 	}
 
 	// Remove trades outside the window
@@ -102,13 +102,13 @@ func (ti *TradeIntensity) Update(md *mdpb.MarketDataUpdate) {
 	if ti.useVolume {
 		// Volume-based intensity (volume per second)
 		totalVolume := 0.0
-		for _, vol := range ti.tradeVolumes {
+		for _, vol := range ti.tradeVolumes.values() {
 			totalVolume += vol
 		}
 		ti.intensity = totalVolume / ti.windowDuration.Seconds()
 	} else {
 		// Count-based intensity (trades per second)
-		ti.intensity = float64(len(ti.tradeTimestamps)) / ti.windowDuration.Seconds()
+		ti.intensity = float64(ti.tradeTimestamps.len()) / ti.windowDuration.Seconds()
 	}
 
 	ti.AddValue(ti.intensity)
@@ -118,7 +118,7 @@ func (ti *TradeIntensity) Update(md *mdpb.MarketDataUpdate) {
 func (ti *TradeIntensity) removeOldTrades(cutoffTime time.Time) {
 	// Find first index to keep
 	firstKeep := 0
-	for i, ts := range ti.tradeTimestamps {
+	for i, ts := range ti.tradeTimestamps.values() {
 		if ts.After(cutoffTime) {
 			firstKeep = i
 			break
@@ -127,9 +127,9 @@ func (ti *TradeIntensity) removeOldTrades(cutoffTime time.Time) {
 
 	// Remove old trades
 	if firstKeep > 0 {
-		ti.tradeTimestamps = ti.tradeTimestamps[firstKeep:]
+		ti.tradeTimestamps.dropFirst(firstKeep)
 		if ti.useVolume {
-			ti.tradeVolumes = ti.tradeVolumes[firstKeep:]
+			ti.tradeVolumes.dropFirst(firstKeep)
 		}
 	}
 }
@@ -145,7 +145,7 @@ func (ti *TradeIntensity) GetIntensity() float64 {
 func (ti *TradeIntensity) GetTradeCount() int {
 	ti.mu.RLock()
 	defer ti.mu.RUnlock()
-	return len(ti.tradeTimestamps)
+	return ti.tradeTimestamps.len()
 }
 
 // GetIntensityLevel returns intensity level classification
@@ -187,5 +187,5 @@ func (ti *TradeIntensity) GetName() string {
 // String returns a string representation
 func (ti *TradeIntensity) String() string {
 	return fmt.Sprintf("TradeIntensity(window=%.0fs, intensity=%.2f, level=%s, trades=%d)",
-		ti.windowDuration.Seconds(), ti.intensity, ti.GetIntensityLevel(), len(ti.tradeTimestamps))
+		ti.windowDuration.Seconds(), ti.intensity, ti.GetIntensityLevel(), ti.tradeTimestamps.len())
 }
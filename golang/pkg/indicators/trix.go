@@ -136,7 +136,7 @@ func (t *TRIX) Reset() {
 
 // IsReady returns true if TRIX has valid values
 func (t *TRIX) IsReady() bool {
-	return t.ema3.IsReady() && t.prevEMA3 > 0 && len(t.GetValues()) > 0
+	return t.ema3.IsReady() && t.prevEMA3 > 0 && t.Len() > 0
 }
 
 // GetPeriod returns the period
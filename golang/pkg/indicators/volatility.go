@@ -11,7 +11,7 @@ import (
 type Volatility struct {
 	*BaseIndicator
 	window      int
-	returns     []float64
+	returns     window[float64]
 	lastPrice   float64
 	useLogReturns bool
 	annualizationFactor float64
@@ -26,7 +26,7 @@ func NewVolatility(window int, useLogReturns bool, maxHistory int) *Volatility {
 	return &Volatility{
 		BaseIndicator: NewBaseIndicator("Volatility", maxHistory),
 		window:        window,
-		returns:       make([]float64, 0, window),
+		returns:       newWindow[float64](window),
 		useLogReturns: useLogReturns,
 		annualizationFactor: math.Sqrt(252), // Assuming 252 trading days
 	}
@@ -84,13 +84,13 @@ func (v *Volatility) Update(md *mdpb.MarketDataUpdate) {
 		}
 
 		// Add return to window
-		v.returns = append(v.returns, ret)
-		if len(v.returns) > v.window {
-			v.returns = v.returns[1:]
+		v.returns.push(ret)
+		if v.returns.len() > v.window {
+			v.returns.dropOldest()
 		}
 
 		// Calculate volatility if we have enough data
-		if len(v.returns) >= 2 {
+		if v.returns.len() >= 2 {
 			volatility := v.calculateStdDev()
 			v.AddValue(volatility)
 		}
@@ -101,24 +101,24 @@ func (v *Volatility) Update(md *mdpb.MarketDataUpdate) {
 
 // calculateStdDev calculates standard deviation of returns
 func (v *Volatility) calculateStdDev() float64 {
-	if len(v.returns) == 0 {
+	if v.returns.len() == 0 {
 		return 0.0
 	}
 
 	// Calculate mean
 	var sum float64
-	for _, ret := range v.returns {
+	for _, ret := range v.returns.values() {
 		sum += ret
 	}
-	mean := sum / float64(len(v.returns))
+	mean := sum / float64(v.returns.len())
 
 	// Calculate variance
 	var variance float64
-	for _, ret := range v.returns {
+	for _, ret := range v.returns.values() {
 		diff := ret - mean
 		variance += diff * diff
 	}
-	variance /= float64(len(v.returns))
+	variance /= float64(v.returns.len())
 
 	// Return standard deviation
 	return math.Sqrt(variance)
@@ -132,13 +132,13 @@ func (v *Volatility) GetAnnualizedValue() float64 {
 // Reset resets the indicator
 func (v *Volatility) Reset() {
 	v.BaseIndicator.Reset()
-	v.returns = v.returns[:0]
+	v.returns.reset()
 	v.lastPrice = 0
 }
 
 // IsReady returns true if we have enough data
 func (v *Volatility) IsReady() bool {
-	return len(v.returns) >= 2
+	return v.returns.len() >= 2
 }
 
 // EWMAVolatility calculates EWMA-based volatility (like EWMA of squared returns)
@@ -218,7 +218,7 @@ func (ev *EWMAVolatility) IsReady() bool {
 type ParkinsonVolatility struct {
 	*BaseIndicator
 	window    int
-	hlRatios  []float64
+	hlRatios  window[float64]
 }
 
 // NewParkinsonVolatility creates Parkinson volatility estimator
@@ -230,7 +230,7 @@ func NewParkinsonVolatility(window int, maxHistory int) *ParkinsonVolatility {
 	return &ParkinsonVolatility{
 		BaseIndicator: NewBaseIndicator("ParkinsonVolatility", maxHistory),
 		window:        window,
-		hlRatios:      make([]float64, 0, window),
+		hlRatios:      newWindow[float64](window),
 	}
 }
 
@@ -245,18 +245,18 @@ func (pv *ParkinsonVolatility) Update(md *mdpb.MarketDataUpdate) {
 	logHLRatio := math.Log(md.HighPrice / md.LowPrice)
 	squaredLogRatio := logHLRatio * logHLRatio
 
-	pv.hlRatios = append(pv.hlRatios, squaredLogRatio)
-	if len(pv.hlRatios) > pv.window {
-		pv.hlRatios = pv.hlRatios[1:]
+	pv.hlRatios.push(squaredLogRatio)
+	if pv.hlRatios.len() > pv.window {
+		pv.hlRatios.dropOldest()
 	}
 
-	if len(pv.hlRatios) >= 2 {
+	if pv.hlRatios.len() >= 2 {
 		// Parkinson estimator: sqrt(1/(4*ln(2)) * mean(log(H/L)^2))
 		var sum float64
-		for _, ratio := range pv.hlRatios {
+		for _, ratio := range pv.hlRatios.values() {
 			sum += ratio
 		}
-		mean := sum / float64(len(pv.hlRatios))
+		mean := sum / float64(pv.hlRatios.len())
 
 		// Parkinson constant
 		constant := 1.0 / (4.0 * math.Log(2))
@@ -269,19 +269,19 @@ func (pv *ParkinsonVolatility) Update(md *mdpb.MarketDataUpdate) {
 // Reset resets the indicator
 func (pv *ParkinsonVolatility) Reset() {
 	pv.BaseIndicator.Reset()
-	pv.hlRatios = pv.hlRatios[:0]
+	pv.hlRatios.reset()
 }
 
 // IsReady returns true if we have enough data
 func (pv *ParkinsonVolatility) IsReady() bool {
-	return len(pv.hlRatios) >= 2
+	return pv.hlRatios.len() >= 2
 }
 
 // GarmanKlassVolatility calculates Garman-Klass volatility estimator
 type GarmanKlassVolatility struct {
 	*BaseIndicator
 	window    int
-	values    []garmanKlassValues
+	values    window[garmanKlassValues]
 }
 
 type garmanKlassValues struct {
@@ -300,7 +300,7 @@ func NewGarmanKlassVolatility(window int, maxHistory int) *GarmanKlassVolatility
 	return &GarmanKlassVolatility{
 		BaseIndicator: NewBaseIndicator("GarmanKlassVolatility", maxHistory),
 		window:        window,
-		values:        make([]garmanKlassValues, 0, window),
+		values:        newWindow[garmanKlassValues](window),
 	}
 }
 
@@ -316,20 +316,20 @@ func (gk *GarmanKlassVolatility) Update(md *mdpb.MarketDataUpdate) {
 		close = GetMidPrice(md)
 	}
 
-	gk.values = append(gk.values, garmanKlassValues{
+	gk.values.push(garmanKlassValues{
 		open:  md.OpenPrice,
 		high:  md.HighPrice,
 		low:   md.LowPrice,
 		close: close,
 	})
 
-	if len(gk.values) > gk.window {
-		gk.values = gk.values[1:]
+	if gk.values.len() > gk.window {
+		gk.values.dropOldest()
 	}
 
-	if len(gk.values) >= 2 {
+	if gk.values.len() >= 2 {
 		var sum float64
-		for _, v := range gk.values {
+		for _, v := range gk.values.values() {
 			// Garman-Klass formula:
 			// 0.5 * (log(H/L))^2 - (2*log(2)-1) * (log(C/O))^2
 			logHL := math.Log(v.high / v.low)
@@ -341,7 +341,7 @@ func (gk *GarmanKlassVolatility) Update(md *mdpb.MarketDataUpdate) {
 			sum += term1 - term2
 		}
 
-		mean := sum / float64(len(gk.values))
+		mean := sum / float64(gk.values.len())
 		volatility := math.Sqrt(mean)
 
 		gk.AddValue(volatility)
@@ -351,10 +351,10 @@ func (gk *GarmanKlassVolatility) Update(md *mdpb.MarketDataUpdate) {
 // Reset resets the indicator
 func (gk *GarmanKlassVolatility) Reset() {
 	gk.BaseIndicator.Reset()
-	gk.values = gk.values[:0]
+	gk.values.reset()
 }
 
 // IsReady returns true if we have enough data
 func (gk *GarmanKlassVolatility) IsReady() bool {
-	return len(gk.values) >= 2
+	return gk.values.len() >= 2
 }
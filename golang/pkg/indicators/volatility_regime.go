@@ -0,0 +1,161 @@
+package indicators
+
+import (
+	"fmt"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+
+	"tbsrc-golang/pkg/regime"
+)
+
+// VolatilityRegime classifies volatility into 2 or 3 hidden states
+// 波动率状态识别：隐马尔可夫（HMM）前向滤波
+//
+// Each state j emits increments dx ~ N(0, m_j * baseVar), where baseVar is a
+// slow EWMA of dx² (frozen while in the high state) and m_j is the state's
+// variance multiplier:
+//
+//	2 states: [normal=1, high=highMult]
+//	3 states: [low=lowMult, normal=1, high=highMult]
+//
+// The transition matrix keeps a state with probability stayProb and moves to
+// any other state uniformly. On every observation the filter runs one forward
+// step, so the posterior P(state | history) is available in O(states) time.
+//
+// The value is the posterior probability of the highest-volatility state.
+//
+// The filter itself is tbsrc-golang/pkg/regime.VolHMM, shared with the tbsrc
+// PairwiseArb regime detector.
+type VolatilityRegime struct {
+	*BaseIndicator
+	hmm *regime.VolHMM
+}
+
+// NewVolatilityRegime creates a new volatility regime filter
+func NewVolatilityRegime(name string, numStates, period int, stayProb, lowMult, highMult float64, maxHistory int) *VolatilityRegime {
+	return &VolatilityRegime{
+		BaseIndicator: NewBaseIndicator(name, maxHistory),
+		hmm:           regime.NewVolHMM(numStates, period, stayProb, lowMult, highMult),
+	}
+}
+
+// NewVolatilityRegimeFromConfig creates a VolatilityRegime from configuration
+func NewVolatilityRegimeFromConfig(config map[string]interface{}) (Indicator, error) {
+	name := "VolatilityRegime"
+	if v, ok := config["name"]; ok {
+		if s, ok := v.(string); ok {
+			name = s
+		}
+	}
+
+	numStates := 2
+	if v, ok := config["states"]; ok {
+		if f, ok := v.(float64); ok {
+			numStates = int(f)
+		}
+	}
+	if numStates != 2 && numStates != 3 {
+		return nil, fmt.Errorf("%w: states must be 2 or 3, got %d", ErrInvalidParameter, numStates)
+	}
+
+	period := 100
+	if v, ok := config["period"]; ok {
+		if f, ok := v.(float64); ok {
+			period = int(f)
+		}
+	}
+
+	stayProb := 0.98
+	if v, ok := config["stay_prob"]; ok {
+		if f, ok := v.(float64); ok {
+			stayProb = f
+		}
+	}
+	if stayProb <= 0 || stayProb >= 1 {
+		return nil, fmt.Errorf("%w: stay_prob must be in (0, 1), got %f", ErrInvalidParameter, stayProb)
+	}
+
+	lowMult := 0.4
+	if v, ok := config["low_mult"]; ok {
+		if f, ok := v.(float64); ok {
+			lowMult = f
+		}
+	}
+
+	highMult := 4.0
+	if v, ok := config["high_mult"]; ok {
+		if f, ok := v.(float64); ok {
+			highMult = f
+		}
+	}
+
+	maxHistory := 1000
+	if v, ok := config["max_history"]; ok {
+		if f, ok := v.(float64); ok {
+			maxHistory = int(f)
+		}
+	}
+
+	return NewVolatilityRegime(name, numStates, period, stayProb, lowMult, highMult, maxHistory), nil
+}
+
+// Update feeds the mid price
+func (vr *VolatilityRegime) Update(md *mdpb.MarketDataUpdate) {
+	mid := GetMidPrice(md)
+	if mid <= 0 {
+		return
+	}
+	vr.UpdateWithValue(mid)
+}
+
+// UpdateWithValue feeds one observation of a level series (price, spread, ...)
+// The filter runs on the increments of the series.
+func (vr *VolatilityRegime) UpdateWithValue(x float64) {
+	if vr.hmm.Update(x) && vr.hmm.Ready() {
+		vr.AddValue(vr.hmm.HighProb())
+	}
+}
+
+// GetValue returns the posterior probability of the high-volatility state
+func (vr *VolatilityRegime) GetValue() float64 {
+	return vr.hmm.HighProb()
+}
+
+// GetState returns the most likely state (0 = lowest volatility, NumStates()-1 = highest)
+func (vr *VolatilityRegime) GetState() int {
+	return vr.hmm.State()
+}
+
+// NumStates returns the number of hidden states (2 or 3)
+func (vr *VolatilityRegime) NumStates() int {
+	return vr.hmm.NumStates()
+}
+
+// StateProbability returns the posterior probability of state j
+func (vr *VolatilityRegime) StateProbability(j int) float64 {
+	return vr.hmm.StateProb(j)
+}
+
+// IsHighVolatility returns true if P(high state) >= threshold
+func (vr *VolatilityRegime) IsHighVolatility(threshold float64) bool {
+	if threshold <= 0 {
+		threshold = 0.5 // Default threshold
+	}
+	return vr.IsReady() && vr.hmm.HighProb() >= threshold
+}
+
+// GetBaseVariance returns the long-run increment variance
+func (vr *VolatilityRegime) GetBaseVariance() float64 {
+	return vr.hmm.BaseVariance()
+}
+
+// Reset resets the indicator
+func (vr *VolatilityRegime) Reset() {
+	vr.BaseIndicator.Reset()
+	vr.hmm.Reset()
+}
+
+// IsReady returns true once the warmup window is filled
+func (vr *VolatilityRegime) IsReady() bool {
+	return vr.hmm.Ready()
+}
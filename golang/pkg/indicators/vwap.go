@@ -141,7 +141,7 @@ func (v *VWAP) GetCumulativeVolume() float64 {
 type TimeWeightedVWAP struct {
 	*BaseIndicator
 	window            time.Duration
-	priceVolumePairs  []priceVolumePair
+	priceVolumePairs  window[priceVolumePair]
 	resetDaily        bool
 	lastResetTime     time.Time
 	resetHour         int
@@ -158,7 +158,7 @@ func NewTimeWeightedVWAP(window time.Duration, resetDaily bool, resetHour int, m
 	return &TimeWeightedVWAP{
 		BaseIndicator:    NewBaseIndicator("TWVWAP", maxHistory),
 		window:           window,
-		priceVolumePairs: make([]priceVolumePair, 0, 1000),
+		priceVolumePairs: newWindow[priceVolumePair](1000),
 		resetDaily:       resetDaily,
 		resetHour:        resetHour,
 	}
@@ -170,7 +170,7 @@ func (tw *TimeWeightedVWAP) Update(md *mdpb.MarketDataUpdate) {
 
 	// Check if we need to reset
 	if tw.resetDaily && tw.shouldReset(now) {
-		tw.priceVolumePairs = tw.priceVolumePairs[:0]
+		tw.priceVolumePairs.reset()
 		tw.lastResetTime = now
 	}
 
@@ -189,7 +189,7 @@ func (tw *TimeWeightedVWAP) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add new price-volume pair
-	tw.priceVolumePairs = append(tw.priceVolumePairs, priceVolumePair{
+	tw.priceVolumePairs.push(priceVolumePair{
 		price:     price,
 		volume:    volume,
 		timestamp: now,
@@ -198,20 +198,20 @@ func (tw *TimeWeightedVWAP) Update(md *mdpb.MarketDataUpdate) {
 	// Remove old pairs outside the window
 	cutoff := now.Add(-tw.window)
 	validStart := 0
-	for i, pair := range tw.priceVolumePairs {
+	for i, pair := range tw.priceVolumePairs.values() {
 		if pair.timestamp.After(cutoff) {
 			validStart = i
 			break
 		}
 	}
 	if validStart > 0 {
-		tw.priceVolumePairs = tw.priceVolumePairs[validStart:]
+		tw.priceVolumePairs.dropFirst(validStart)
 	}
 
 	// Calculate TWVWAP
-	if len(tw.priceVolumePairs) > 0 {
+	if tw.priceVolumePairs.len() > 0 {
 		var sumValue, sumVolume float64
-		for _, pair := range tw.priceVolumePairs {
+		for _, pair := range tw.priceVolumePairs.values() {
 			sumValue += pair.price * pair.volume
 			sumVolume += pair.volume
 		}
@@ -243,11 +243,11 @@ func (tw *TimeWeightedVWAP) shouldReset(now time.Time) bool {
 // Reset resets the indicator
 func (tw *TimeWeightedVWAP) Reset() {
 	tw.BaseIndicator.Reset()
-	tw.priceVolumePairs = tw.priceVolumePairs[:0]
+	tw.priceVolumePairs.reset()
 	tw.lastResetTime = time.Time{}
 }
 
 // IsReady returns true if there's data
 func (tw *TimeWeightedVWAP) IsReady() bool {
-	return len(tw.priceVolumePairs) > 0
+	return tw.priceVolumePairs.len() > 0
 }
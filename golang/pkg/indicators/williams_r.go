@@ -21,9 +21,9 @@ import (
 type WilliamsR struct {
 	*BaseIndicator
 	period    int
-	highs     []float64
-	lows      []float64
-	closes    []float64
+	highs     window[float64]
+	lows      window[float64]
+	closes    window[float64]
 	williamsR float64
 }
 
@@ -39,9 +39,9 @@ func NewWilliamsR(period int, maxHistory int) *WilliamsR {
 	return &WilliamsR{
 		BaseIndicator: NewBaseIndicator("Williams %R", maxHistory),
 		period:        period,
-		highs:         make([]float64, 0, period),
-		lows:          make([]float64, 0, period),
-		closes:        make([]float64, 0, period),
+		highs:         newWindow[float64](period),
+		lows:          newWindow[float64](period),
+		closes:        newWindow[float64](period),
 	}
 }
 
@@ -84,37 +84,37 @@ func (w *WilliamsR) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add to windows
-	w.highs = append(w.highs, high)
-	w.lows = append(w.lows, low)
-	w.closes = append(w.closes, close)
+	w.highs.push(high)
+	w.lows.push(low)
+	w.closes.push(close)
 
 	// Keep only period elements
-	if len(w.highs) > w.period {
-		w.highs = w.highs[1:]
-		w.lows = w.lows[1:]
-		w.closes = w.closes[1:]
+	if w.highs.len() > w.period {
+		w.highs.dropOldest()
+		w.lows.dropOldest()
+		w.closes.dropOldest()
 	}
 
 	// Need at least period values to calculate
-	if len(w.closes) < w.period {
+	if w.closes.len() < w.period {
 		return
 	}
 
 	// Calculate Williams %R
 	// %R = -100 × (Highest High - Close) / (Highest High - Lowest Low)
-	highestHigh := w.highs[0]
-	lowestLow := w.lows[0]
+	highestHigh := w.highs.at(0)
+	lowestLow := w.lows.at(0)
 
-	for i := 1; i < len(w.highs); i++ {
-		if w.highs[i] > highestHigh {
-			highestHigh = w.highs[i]
+	for i := 1; i < w.highs.len(); i++ {
+		if w.highs.at(i) > highestHigh {
+			highestHigh = w.highs.at(i)
 		}
-		if w.lows[i] < lowestLow {
-			lowestLow = w.lows[i]
+		if w.lows.at(i) < lowestLow {
+			lowestLow = w.lows.at(i)
 		}
 	}
 
-	currentClose := w.closes[len(w.closes)-1]
+	currentClose := w.closes.at(w.closes.len()-1)
 	denominator := highestHigh - lowestLow
 
 	if denominator == 0 {
@@ -134,15 +134,15 @@ func (w *WilliamsR) GetValue() float64 {
 // Reset resets the indicator
 func (w *WilliamsR) Reset() {
 	w.BaseIndicator.Reset()
-	w.highs = w.highs[:0]
-	w.lows = w.lows[:0]
-	w.closes = w.closes[:0]
+	w.highs.reset()
+	w.lows.reset()
+	w.closes.reset()
 	w.williamsR = 0
 }
 
 // IsReady returns true if the indicator has enough data
 func (w *WilliamsR) IsReady() bool {
-	return len(w.closes) >= w.period
+	return w.closes.len() >= w.period
 }
 
 // GetPeriod returns the period
@@ -185,7 +185,7 @@ func TestWilliamsR_Reset(t *testing.T) {
 		t.Errorf("Williams %%R value should be 0 after reset, got %.2f", wr.GetValue())
 	}
 
-	if len(wr.highs) != 0 || len(wr.lows) != 0 || len(wr.closes) != 0 {
+	if wr.highs.len() != 0 || wr.lows.len() != 0 || wr.closes.len() != 0 {
 		t.Error("Price windows should be empty after reset")
 	}
 }
@@ -0,0 +1,77 @@
+package indicators
+
+// window is a FIFO sliding window over a reused backing array.
+//
+// Live values are buf[head:]. Dropping from the front only advances head, so
+// push and dropOldest are O(1); like the BaseIndicator ring, no element is
+// moved per tick. Once the backing array is full and at least half of it is
+// dead space in front of head, push moves the live values back to the start
+// of the array. That copy happens at most once per len() drops, so the cost is
+// amortized O(1) and, unlike a modulo ring, values() stays one contiguous
+// slice that can be handed to the stats helpers without copying.
+type window[T any] struct {
+	buf  []T
+	head int
+}
+
+// newWindow creates a window with room for size live values before it first compacts
+func newWindow[T any](size int) window[T] {
+	if size < 1 {
+		size = 1
+	}
+	return window[T]{buf: make([]T, 0, 2*size)}
+}
+
+// push appends v as the newest value
+func (w *window[T]) push(v T) {
+	if len(w.buf) == cap(w.buf) && w.head > 0 && w.head >= len(w.buf)-w.head {
+		n := copy(w.buf, w.buf[w.head:])
+		w.buf = w.buf[:n]
+		w.head = 0
+	}
+	w.buf = append(w.buf, v)
+}
+
+// dropOldest removes the oldest value
+func (w *window[T]) dropOldest() {
+	w.dropFirst(1)
+}
+
+// dropFirst removes the n oldest values
+func (w *window[T]) dropFirst(n int) {
+	if n <= 0 {
+		return
+	}
+	if n >= w.len() {
+		w.reset()
+		return
+	}
+	w.head += n
+}
+
+// keepLast trims the window to its n newest values
+func (w *window[T]) keepLast(n int) {
+	w.dropFirst(w.len() - n)
+}
+
+// reset removes all values, keeping the backing array
+func (w *window[T]) reset() {
+	w.buf = w.buf[:0]
+	w.head = 0
+}
+
+// len returns the number of live values
+func (w *window[T]) len() int {
+	return len(w.buf) - w.head
+}
+
+// values returns the live values (oldest first). The slice aliases the window
+// and is only valid until the next push.
+func (w *window[T]) values() []T {
+	return w.buf[w.head:]
+}
+
+// at returns the i-th live value (0 = oldest)
+func (w *window[T]) at(i int) T {
+	return w.buf[w.head+i]
+}
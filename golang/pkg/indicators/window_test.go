@@ -0,0 +1,40 @@
+package indicators
+
+import "testing"
+
+func TestWindow_SlidingAndCompaction(t *testing.T) {
+	w := newWindow[int](4)
+	for i := 1; i <= 10; i++ {
+		w.push(i)
+		if w.len() > 4 {
+			w.dropOldest()
+		}
+	}
+	if got := w.values(); len(got) != 4 || got[0] != 7 || got[3] != 10 || w.at(1) != 8 {
+		t.Fatalf("window = %v", got)
+	}
+	if cap(w.buf) != 8 {
+		t.Errorf("backing array grew to %d, want 8", cap(w.buf))
+	}
+
+	w.dropFirst(3)
+	if w.len() != 1 || w.at(0) != 10 {
+		t.Errorf("after dropFirst(3) = %v", w.values())
+	}
+	w.keepLast(5)
+	w.dropFirst(5)
+	if w.len() != 0 {
+		t.Errorf("after dropFirst past end = %v", w.values())
+	}
+
+	i := 0
+	if allocs := testing.AllocsPerRun(1000, func() {
+		w.push(i)
+		if w.len() > 4 {
+			w.dropOldest()
+		}
+		i++
+	}); allocs > 0 {
+		t.Errorf("push/dropOldest allocate %.1f times per call", allocs)
+	}
+}
@@ -12,7 +12,7 @@ import (
 type WMA struct {
 	*BaseIndicator
 	period      int
-	prices      []float64
+	prices      window[float64]
 	weightSum   float64 // Sum of weights: period + (period-1) + ... + 1 = period*(period+1)/2
 	initialized bool
 }
@@ -33,7 +33,7 @@ func NewWMA(period int, maxHistory int) *WMA {
 	return &WMA{
 		BaseIndicator: NewBaseIndicator("WMA", maxHistory),
 		period:        period,
-		prices:        make([]float64, 0, period),
+		prices:        newWindow[float64](period),
 		weightSum:     weightSum,
 		initialized:   false,
 	}
@@ -75,15 +75,15 @@ func (w *WMA) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Add new price
-	w.prices = append(w.prices, midPrice)
+	w.prices.push(midPrice)
 
 	// Remove oldest price if we exceed the period
-	if len(w.prices) > w.period {
-		w.prices = w.prices[1:]
+	if w.prices.len() > w.period {
+		w.prices.dropOldest()
 	}
 
 	// Calculate WMA when we have enough data
-	if len(w.prices) == w.period {
+	if w.prices.len() == w.period {
 		w.initialized = true
 		wma := w.calculateWMA()
 		w.AddValue(wma)
@@ -95,7 +95,7 @@ func (w *WMA) calculateWMA() float64 {
 	var weightedSum float64
 
 	// Apply weights: oldest price gets weight 1, newest gets weight n
-	for i, price := range w.prices {
+	for i, price := range w.prices.values() {
 		weight := float64(i + 1) // Weight increases linearly
 		weightedSum += price * weight
 	}
@@ -113,13 +113,13 @@ func (w *WMA) GetValue() float64 {
 
 // IsReady returns true if the indicator has enough data
 func (w *WMA) IsReady() bool {
-	return w.initialized && len(w.prices) == w.period
+	return w.initialized && w.prices.len() == w.period
 }
 
 // Reset resets the indicator state
 func (w *WMA) Reset() {
 	w.BaseIndicator.Reset()
-	w.prices = make([]float64, 0, w.period)
+	w.prices.reset()
 	w.initialized = false
 }
 
@@ -130,7 +130,7 @@ func (w *WMA) GetPeriod() int {
 
 // GetPrices returns the current price window (for testing)
 func (w *WMA) GetPrices() []float64 {
-	result := make([]float64, len(w.prices))
-	copy(result, w.prices)
+	result := make([]float64, w.prices.len())
+	copy(result, w.prices.values())
 	return result
 }
@@ -28,7 +28,7 @@ type ZLEMA struct {
 	*BaseIndicator
 	period  int
 	lag     int
-	prices  []float64
+	prices  window[float64]
 	ema     *EMA
 	zlema   float64
 }
@@ -49,7 +49,7 @@ func NewZLEMA(period int, maxHistory int) *ZLEMA {
 		BaseIndicator: NewBaseIndicator("ZLEMA", maxHistory),
 		period:        period,
 		lag:           lag,
-		prices:        make([]float64, 0, lag+1),
+		prices:        newWindow[float64](lag+1),
 		ema:           NewEMA(period, maxHistory),
 	}
 }
@@ -82,20 +82,20 @@ func (z *ZLEMA) Update(md *mdpb.MarketDataUpdate) {
 	}
 
 	// Store price
-	z.prices = append(z.prices, price)
+	z.prices.push(price)
 
 	// Keep only lag+1 values
-	if len(z.prices) > z.lag+1 {
-		z.prices = z.prices[1:]
+	if z.prices.len() > z.lag+1 {
+		z.prices.dropOldest()
 	}
 
 	// Need at least lag+1 values to calculate ZLEMA
-	if len(z.prices) < z.lag+1 {
+	if z.prices.len() < z.lag+1 {
 		return
 	}
 
 	// Calculate EMA_Data = Price + (Price - Price[Lag])
-	laggedPrice := z.prices[0]
+	laggedPrice := z.prices.at(0)
 	emaData := price + (price - laggedPrice)
 
 	// Update EMA with adjusted data
@@ -119,14 +119,14 @@ func (z *ZLEMA) GetValue() float64 {
 // Reset resets the indicator
 func (z *ZLEMA) Reset() {
 	z.BaseIndicator.Reset()
-	z.prices = z.prices[:0]
+	z.prices.reset()
 	z.ema.Reset()
 	z.zlema = 0
 }
 
 // IsReady returns true if the indicator has enough data
 func (z *ZLEMA) IsReady() bool {
-	return z.ema.IsReady() && len(z.prices) >= z.lag+1
+	return z.ema.IsReady() && z.prices.len() >= z.lag+1
 }
 
 // GetPeriod returns the period
@@ -142,12 +142,12 @@ func (z *ZLEMA) GetLag() int {
 // GetTrend returns the trend direction
 // Returns 1 for uptrend, -1 for downtrend, 0 for neutral
 func (z *ZLEMA) GetTrend() int {
-	if len(z.values) < 2 {
+	if z.Len() < 2 {
 		return 0
 	}
 
-	current := z.values[len(z.values)-1]
-	previous := z.values[len(z.values)-2]
+	current := z.ValueAgo(0)
+	previous := z.ValueAgo(1)
 
 	if current > previous {
 		return 1
@@ -161,32 +161,31 @@ func (z *ZLEMA) GetTrend() int {
 // GetSlope returns the recent slope (last 3 periods)
 // Positive slope indicates uptrend, negative indicates downtrend
 func (z *ZLEMA) GetSlope() float64 {
-	if len(z.values) < 3 {
+	if z.Len() < 3 {
 		return 0
 	}
 
-	n := len(z.values)
-	return (z.values[n-1] - z.values[n-3]) / 2.0
+	return (z.ValueAgo(0) - z.ValueAgo(2)) / 2.0
 }
 
 // IsCrossAbove checks if price crossed above ZLEMA
 // Requires price history
 func (z *ZLEMA) IsCrossAbove(currentPrice float64, prevPrice float64) bool {
-	if len(z.values) < 2 {
+	if z.Len() < 2 {
 		return false
 	}
 
-	prevZLEMA := z.values[len(z.values)-2]
+	prevZLEMA := z.ValueAgo(1)
 	return prevPrice <= prevZLEMA && currentPrice > z.zlema
 }
 
 // IsCrossBelow checks if price crossed below ZLEMA
 // Requires price history
 func (z *ZLEMA) IsCrossBelow(currentPrice float64, prevPrice float64) bool {
-	if len(z.values) < 2 {
+	if z.Len() < 2 {
 		return false
 	}
 
-	prevZLEMA := z.values[len(z.values)-2]
+	prevZLEMA := z.ValueAgo(1)
 	return prevPrice >= prevZLEMA && currentPrice < z.zlema
 }
@@ -0,0 +1,119 @@
+package journal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// Entry 记录的可读形式（日志查看/导出用）
+type Entry struct {
+	Offset        int64     `json:"offset"`
+	Time          time.Time `json:"time"`
+	Kind          string    `json:"kind"`
+	Type          string    `json:"type,omitempty"` // 订单状态 / 应答错误码 / checkpoint reason
+	OrderID       string    `json:"order_id,omitempty"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	StrategyID    string    `json:"strategy_id,omitempty"`
+	Symbol        string    `json:"symbol,omitempty"`
+	Side          string    `json:"side,omitempty"`
+	OpenClose     string    `json:"open_close,omitempty"`
+	Price         float64   `json:"price,omitempty"`
+	Qty           int64     `json:"qty,omitempty"`
+	FilledQty     int64     `json:"filled_qty,omitempty"`
+	ExecID        string    `json:"exec_id,omitempty"`
+	TradingDay    string    `json:"trading_day,omitempty"`
+}
+
+// EntryHeader CSV 列名，与 Entry.Fields 一致
+var EntryHeader = []string{"offset", "time", "kind", "type", "order_id", "client_order_id", "strategy_id",
+	"symbol", "side", "open_close", "price", "qty", "filled_qty", "exec_id", "trading_day"}
+
+// Fields CSV 行
+func (e Entry) Fields() []string {
+	return []string{
+		strconv.FormatInt(e.Offset, 10),
+		e.Time.Format("2006-01-02 15:04:05.000000"),
+		e.Kind,
+		e.Type,
+		e.OrderID,
+		e.ClientOrderID,
+		e.StrategyID,
+		e.Symbol,
+		e.Side,
+		e.OpenClose,
+		strconv.FormatFloat(e.Price, 'f', -1, 64),
+		strconv.FormatInt(e.Qty, 10),
+		strconv.FormatInt(e.FilledQty, 10),
+		e.ExecID,
+		e.TradingDay,
+	}
+}
+
+// Decode 解析记录为可读形式
+func Decode(rec Record) (Entry, error) {
+	e := Entry{Offset: rec.Offset, Time: rec.Time, Kind: KindName(rec.Kind)}
+	switch rec.Kind {
+	case KindOrderRequest:
+		req, err := DecodeRequest(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.Type = req.OrderType.String()
+		e.fromRequest(req)
+	case KindOrderAck:
+		req, resp, err := DecodeAck(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.fromRequest(req)
+		e.Type = resp.ErrorCode.String()
+		e.OrderID = resp.OrderId
+	case KindOrderUpdate:
+		u, err := DecodeUpdate(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.Type = u.Status.String()
+		e.OrderID = u.OrderId
+		e.ClientOrderID = u.ClientOrderId
+		e.StrategyID = u.StrategyId
+		e.Symbol = u.Symbol
+		e.Side = u.Side.String()
+		e.Price = u.Price
+		e.Qty = u.Quantity
+		e.FilledQty = u.FilledQty
+		e.ExecID = u.ExecId
+	case KindCancel:
+		req, err := DecodeCancel(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.OrderID = req.OrderId
+		e.ClientOrderID = req.ClientOrderId
+		e.StrategyID = req.StrategyId
+		e.Symbol = req.Symbol
+	case KindCheckpoint:
+		cp, err := DecodeCheckpoint(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.Type = cp.Reason
+		e.TradingDay = cp.TradingDay
+	default:
+		return e, fmt.Errorf("journal: unknown record kind %d at offset %d", rec.Kind, rec.Offset)
+	}
+	return e, nil
+}
+
+func (e *Entry) fromRequest(req *orspb.OrderRequest) {
+	e.ClientOrderID = req.ClientOrderId
+	e.StrategyID = req.StrategyId
+	e.Symbol = req.Symbol
+	e.Side = req.Side.String()
+	e.OpenClose = req.OpenClose.String()
+	e.Price = req.Price
+	e.Qty = req.Quantity
+}
@@ -0,0 +1,82 @@
+// Package journal implements the append-only write-ahead journal of order
+// requests, acknowledgements and order updates used for crash recovery.
+//
+// 策略持仓快照（positions/<strategy>.json）只在正常停止和交易日切换时写出，
+// 进程崩溃后快照之后的成交全部丢失。这里把引擎收发的每条 OrderRequest / 下单应答 /
+// OrderUpdate 追加写入日志，批量 fsync；重启时按快照时间重放之后的成交，修正快照持仓，
+// 并找出日志末尾仍在途的订单。
+//
+// 记录帧（u32 长度 | u32 CRC32C | u8 kind | u64 时间 | payload）、批量 fsync、
+// 残缺尾部截断和读取都由 tbsrc-golang/pkg/journal 实现，本包只定义自己的 kind 和
+// payload 编码（protobuf）。kind 取值与 tbsrc-golang 的日志（SHM 结构体）不通用，
+// 两者的日志文件不能互相读取。
+package journal
+
+import (
+	"fmt"
+
+	tbjournal "tbsrc-golang/pkg/journal"
+)
+
+// Kind 记录类型（帧头中的 u8）
+type Kind = tbjournal.Kind
+
+const (
+	KindOrderRequest Kind = 1 // orspb.OrderRequest（发送前写入）
+	KindOrderAck     Kind = 2 // orspb.OrderRequest + orspb.OrderResponse（网关应答）
+	KindOrderUpdate  Kind = 3 // orspb.OrderUpdate（回报）
+	KindCheckpoint   Kind = 4 // 策略持仓快照已保存
+	KindCancel       Kind = 5 // orspb.CancelRequest（发送前写入）
+)
+
+// KindName 返回记录类型名称（Kind.String 是 tbsrc-golang 日志的名称，这里按本包的取值）
+func KindName(k Kind) string {
+	switch k {
+	case KindOrderRequest:
+		return "REQUEST"
+	case KindOrderAck:
+		return "ACK"
+	case KindOrderUpdate:
+		return "UPDATE"
+	case KindCheckpoint:
+		return "CHECKPOINT"
+	case KindCancel:
+		return "CANCEL"
+	default:
+		return fmt.Sprintf("KIND(%d)", uint8(k))
+	}
+}
+
+// Options 写入参数（SyncInterval / SyncBatch）
+type Options = tbjournal.Options
+
+// Record 一条日志记录
+type Record = tbjournal.Record
+
+// ScanResult 扫描结果
+type ScanResult = tbjournal.ScanResult
+
+// Writer 日志写入器，并发安全；Append/Sync/Rotate/Close 等来自 tbsrc-golang 的 Writer，
+// 本包在其上增加 protobuf 记录的写入方法
+type Writer struct {
+	*tbjournal.Writer
+}
+
+// Open 打开（或创建）日志文件并启动后台 fsync，已有文件先截断残缺尾部
+func Open(path string, opts Options) (*Writer, error) {
+	w, err := tbjournal.Open(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{Writer: w}, nil
+}
+
+// ReadAll 读取全部有效记录
+func ReadAll(path string) ([]Record, ScanResult, error) {
+	return tbjournal.ReadAll(path)
+}
+
+// AfterCheckpoint 返回最后一个 checkpoint 之后的记录（没有 checkpoint 时返回全部）
+func AfterCheckpoint(recs []Record) []Record {
+	return tbjournal.AfterKind(recs, KindCheckpoint)
+}
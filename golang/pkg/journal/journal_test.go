@@ -0,0 +1,141 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+func openTemp(t *testing.T) (*Writer, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "orders.journal")
+	w, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return w, path
+}
+
+func order(sid, symbol string, side orspb.OrderSide, qty int64) *orspb.OrderRequest {
+	return &orspb.OrderRequest{StrategyId: sid, Symbol: symbol, Side: side, Price: 5810, Quantity: qty}
+}
+
+func ack(id string) *orspb.OrderResponse {
+	return &orspb.OrderResponse{OrderId: id, ErrorCode: orspb.ErrorCode_SUCCESS}
+}
+
+func update(id, sid, symbol string, side orspb.OrderSide, status orspb.OrderStatus, qty, filled int64) *orspb.OrderUpdate {
+	return &orspb.OrderUpdate{OrderId: id, StrategyId: sid, Symbol: symbol, Side: side,
+		Status: status, Quantity: qty, FilledQty: filled}
+}
+
+func TestJournal_RoundTripAndTornTail(t *testing.T) {
+	w, path := openTemp(t)
+	req := order("s1", "ag2506", orspb.OrderSide_BUY, 2)
+	w.RecordOrderRequest(req)
+	w.RecordOrderAck(req, ack("O1"))
+	w.RecordOrderUpdate(update("O1", "s1", "ag2506", orspb.OrderSide_BUY, orspb.OrderStatus_FILLED, 2, 2))
+	w.Checkpoint("positions_saved", "20250102")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	recs, _, err := ReadAll(path)
+	if err != nil || len(recs) != 4 {
+		t.Fatalf("ReadAll = %d records, err=%v, want 4", len(recs), err)
+	}
+	e, err := Decode(recs[1])
+	if err != nil {
+		t.Fatalf("Decode ack: %v", err)
+	}
+	if e.Kind != "ACK" || e.OrderID != "O1" || e.StrategyID != "s1" || e.Qty != 2 {
+		t.Errorf("ack entry = %+v", e)
+	}
+	if e, _ := Decode(recs[2]); e.Type != "FILLED" || e.FilledQty != 2 {
+		t.Errorf("update entry = %+v", e)
+	}
+	if len(AfterCheckpoint(recs)) != 0 {
+		t.Error("no records expected after the checkpoint")
+	}
+
+	// 模拟崩溃：checkpoint 只写了一半
+	info, _ := os.Stat(path)
+	os.Truncate(path, info.Size()-5)
+	w, err = Open(path, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if w.Records() != 3 {
+		t.Errorf("Records() = %d, want 3 after truncating the torn tail", w.Records())
+	}
+	w.Close()
+	if _, res, _ := ReadAll(path); res.TailBytes != 0 {
+		t.Errorf("TailBytes = %d after reopen, want 0", res.TailBytes)
+	}
+}
+
+func TestJournal_CancelRecord(t *testing.T) {
+	w, path := openTemp(t)
+	w.RecordCancelRequest(&orspb.CancelRequest{OrderId: "O1", StrategyId: "s1", Symbol: "ag2506"})
+	w.Close()
+
+	recs, _, err := ReadAll(path)
+	if err != nil || len(recs) != 1 {
+		t.Fatalf("ReadAll = %d records, err=%v, want 1", len(recs), err)
+	}
+	if e, err := Decode(recs[0]); err != nil || e.Kind != "CANCEL" || e.OrderID != "O1" || e.StrategyID != "s1" {
+		t.Errorf("cancel entry = %+v, err=%v", e, err)
+	}
+}
+
+func TestReplayRecords_FillsSinceSnapshotAndOpenOrders(t *testing.T) {
+	w, path := openTemp(t)
+	buy := order("s1", "ag2506", orspb.OrderSide_BUY, 3)
+	w.RecordOrderRequest(buy)
+	w.RecordOrderAck(buy, ack("O1"))
+	w.RecordOrderUpdate(update("O1", "s1", "ag2506", orspb.OrderSide_BUY, orspb.OrderStatus_PARTIALLY_FILLED, 3, 1))
+	w.Sync()
+	time.Sleep(2 * time.Millisecond)
+	snapshotAt := time.Now() // s1 快照在第一笔成交之后保存
+	time.Sleep(2 * time.Millisecond)
+
+	w.RecordOrderUpdate(update("O1", "s1", "ag2506", orspb.OrderSide_BUY, orspb.OrderStatus_PARTIALLY_FILLED, 3, 2))
+	w.RecordOrderUpdate(update("O1", "s1", "ag2506", orspb.OrderSide_BUY, orspb.OrderStatus_PARTIALLY_FILLED, 3, 2)) // 重复推送
+
+	sell := order("s2", "ag2512", orspb.OrderSide_SELL, 2)
+	w.RecordOrderRequest(sell)
+	w.RecordOrderAck(sell, ack("O2"))
+	w.RecordOrderUpdate(update("O2", "s2", "ag2512", orspb.OrderSide_SELL, orspb.OrderStatus_FILLED, 2, 2))
+
+	cancel := order("s2", "ag2512", orspb.OrderSide_SELL, 1)
+	w.RecordOrderRequest(cancel)
+	w.RecordOrderAck(cancel, ack("O3"))
+	w.RecordOrderUpdate(update("O3", "s2", "ag2512", orspb.OrderSide_SELL, orspb.OrderStatus_CANCELED, 1, 0))
+	w.Close()
+
+	recs, _, _ := ReadAll(path)
+	r := ReplayRecords(recs, func(sid string) time.Time {
+		if sid == "s1" {
+			return snapshotAt
+		}
+		return time.Time{}
+	})
+
+	if got := r.Fills["s1"]["ag2506"]; got != 1 {
+		t.Errorf("s1 ag2506 = %d, want 1 (first fill already in snapshot, duplicate ignored)", got)
+	}
+	if got := r.Fills["s2"]["ag2512"]; got != -2 {
+		t.Errorf("s2 ag2512 = %d, want -2", got)
+	}
+	if len(r.OpenOrders) != 1 || r.OpenOrders[0].OrderID != "O1" || r.OpenOrders[0].FilledQty != 2 {
+		t.Errorf("OpenOrders = %+v, want O1 with 2 filled", r.OpenOrders)
+	}
+
+	// 不限快照时间：全部成交
+	if all := ReplayRecords(recs, nil); all.Fills["s1"]["ag2506"] != 2 {
+		t.Errorf("all fills s1 = %d, want 2", all.Fills["s1"]["ag2506"])
+	}
+}
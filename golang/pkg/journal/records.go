@@ -0,0 +1,142 @@
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// Checkpoint checkpoint 记录内容
+type Checkpoint struct {
+	Reason     string `json:"reason"`                // positions_saved / rollover / recovery
+	TradingDay string `json:"trading_day,omitempty"` // YYYYMMDD
+}
+
+// EncodeAck 下单应答：u32 请求长度 | OrderRequest | OrderResponse
+// OrderResponse 本身不带策略/合约/方向，与请求一起写入，重放时不必再按 client_order_id 配对
+func EncodeAck(req *orspb.OrderRequest, resp *orspb.OrderResponse) ([]byte, error) {
+	reqData, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	respData, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4, 4+len(reqData)+len(respData))
+	binary.LittleEndian.PutUint32(buf, uint32(len(reqData)))
+	buf = append(buf, reqData...)
+	return append(buf, respData...), nil
+}
+
+// DecodeRequest 解析 KindOrderRequest 记录
+func DecodeRequest(p []byte) (*orspb.OrderRequest, error) {
+	req := &orspb.OrderRequest{}
+	if err := proto.Unmarshal(p, req); err != nil {
+		return nil, fmt.Errorf("journal: decode request: %w", err)
+	}
+	return req, nil
+}
+
+// DecodeAck 解析 KindOrderAck 记录
+func DecodeAck(p []byte) (*orspb.OrderRequest, *orspb.OrderResponse, error) {
+	if len(p) < 4 {
+		return nil, nil, fmt.Errorf("journal: ack record too short (%d bytes)", len(p))
+	}
+	n := binary.LittleEndian.Uint32(p)
+	if uint64(n) > uint64(len(p)-4) {
+		return nil, nil, fmt.Errorf("journal: ack request length %d exceeds record", n)
+	}
+	req, err := DecodeRequest(p[4 : 4+n])
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := &orspb.OrderResponse{}
+	if err := proto.Unmarshal(p[4+n:], resp); err != nil {
+		return nil, nil, fmt.Errorf("journal: decode ack: %w", err)
+	}
+	return req, resp, nil
+}
+
+// DecodeUpdate 解析 KindOrderUpdate 记录
+func DecodeUpdate(p []byte) (*orspb.OrderUpdate, error) {
+	update := &orspb.OrderUpdate{}
+	if err := proto.Unmarshal(p, update); err != nil {
+		return nil, fmt.Errorf("journal: decode update: %w", err)
+	}
+	return update, nil
+}
+
+// DecodeCancel 解析 KindCancel 记录
+func DecodeCancel(p []byte) (*orspb.CancelRequest, error) {
+	req := &orspb.CancelRequest{}
+	if err := proto.Unmarshal(p, req); err != nil {
+		return nil, fmt.Errorf("journal: decode cancel: %w", err)
+	}
+	return req, nil
+}
+
+// DecodeCheckpoint 解析 KindCheckpoint 记录
+func DecodeCheckpoint(p []byte) (Checkpoint, error) {
+	var cp Checkpoint
+	err := json.Unmarshal(p, &cp)
+	return cp, err
+}
+
+// RecordOrderRequest 记录即将发送的请求（写前）
+func (w *Writer) RecordOrderRequest(req *orspb.OrderRequest) error {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return w.Append(KindOrderRequest, data)
+}
+
+// RecordOrderAck 记录网关对请求的应答（含网关分配的 order_id）
+func (w *Writer) RecordOrderAck(req *orspb.OrderRequest, resp *orspb.OrderResponse) error {
+	data, err := EncodeAck(req, resp)
+	if err != nil {
+		return err
+	}
+	return w.Append(KindOrderAck, data)
+}
+
+// RecordCancelRequest 记录即将发送的撤单请求（写前）
+func (w *Writer) RecordCancelRequest(req *orspb.CancelRequest) error {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return w.Append(KindCancel, data)
+}
+
+// RecordOrderUpdate 记录收到的回报
+func (w *Writer) RecordOrderUpdate(update *orspb.OrderUpdate) error {
+	data, err := proto.Marshal(update)
+	if err != nil {
+		return err
+	}
+	return w.Append(KindOrderUpdate, data)
+}
+
+// Checkpoint 记录持仓快照已保存并立即 fsync
+func (w *Writer) Checkpoint(reason, tradingDay string) error {
+	data, _ := json.Marshal(Checkpoint{Reason: reason, TradingDay: tradingDay})
+	if err := w.Append(KindCheckpoint, data); err != nil {
+		return err
+	}
+	return w.Sync()
+}
+
+// ArchivePath 交易日归档文件名：<path>.<YYYYMMDD>
+func ArchivePath(path, tradingDay string) string {
+	if tradingDay == "" {
+		tradingDay = time.Now().Format("20060102")
+	}
+	return path + "." + tradingDay
+}
@@ -0,0 +1,116 @@
+package journal
+
+import (
+	"sort"
+	"time"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// OpenOrder 日志末尾仍在途的订单
+type OpenOrder struct {
+	OrderID    string
+	StrategyID string
+	Symbol     string
+	Side       orspb.OrderSide
+	Price      float64
+	Qty        int64
+	FilledQty  int64
+	Status     orspb.OrderStatus
+}
+
+// Replay 重放结果
+type Replay struct {
+	Records     int
+	Checkpoints int
+	// Fills 策略 → 合约 → 成交净量（买为正），只统计各策略 since 之后的成交
+	Fills map[string]map[string]int64
+	// FillCount 计入 Fills 的回报条数
+	FillCount int
+	// OpenOrders 应答成功且尚无终态回报的订单，按 order_id 排序
+	OpenOrders []OpenOrder
+}
+
+// ReplayRecords 重放日志
+// since 返回策略持仓快照的保存时间：其前的成交已包含在快照中，不重复计入；
+// since 为 nil 或返回零值时统计全部成交。
+// OrderUpdate 的 filled_qty 是累计量，按订单取增量去重（重复推送的回报不会重复计入）
+func ReplayRecords(recs []Record, since func(strategyID string) time.Time) *Replay {
+	r := &Replay{Records: len(recs), Fills: make(map[string]map[string]int64)}
+	filled := make(map[string]int64) // order_id → 已处理的累计成交量
+	open := make(map[string]*OpenOrder)
+
+	for _, rec := range recs {
+		switch rec.Kind {
+		case KindCheckpoint:
+			r.Checkpoints++
+
+		case KindOrderAck:
+			req, resp, err := DecodeAck(rec.Payload)
+			if err != nil || resp.ErrorCode != orspb.ErrorCode_SUCCESS || resp.OrderId == "" {
+				continue
+			}
+			if _, seen := filled[resp.OrderId]; seen {
+				continue // 回报先于应答到达且已终结
+			}
+			open[resp.OrderId] = &OpenOrder{
+				OrderID:    resp.OrderId,
+				StrategyID: req.StrategyId,
+				Symbol:     req.Symbol,
+				Side:       req.Side,
+				Price:      req.Price,
+				Qty:        req.Quantity,
+				Status:     orspb.OrderStatus_SUBMITTED,
+			}
+
+		case KindOrderUpdate:
+			u, err := DecodeUpdate(rec.Payload)
+			if err != nil {
+				continue
+			}
+			if delta := u.FilledQty - filled[u.OrderId]; delta > 0 {
+				filled[u.OrderId] = u.FilledQty
+				if since == nil || rec.Time.After(since(u.StrategyId)) {
+					if u.Side == orspb.OrderSide_SELL {
+						delta = -delta
+					}
+					if r.Fills[u.StrategyId] == nil {
+						r.Fills[u.StrategyId] = make(map[string]int64)
+					}
+					r.Fills[u.StrategyId][u.Symbol] += delta
+					r.FillCount++
+				}
+			} else if _, ok := filled[u.OrderId]; !ok {
+				filled[u.OrderId] = 0
+			}
+
+			switch u.Status {
+			case orspb.OrderStatus_FILLED, orspb.OrderStatus_CANCELED,
+				orspb.OrderStatus_REJECTED, orspb.OrderStatus_EXPIRED:
+				delete(open, u.OrderId)
+			default:
+				if o, ok := open[u.OrderId]; ok {
+					o.Status = u.Status
+					o.FilledQty = u.FilledQty
+				} else {
+					open[u.OrderId] = &OpenOrder{
+						OrderID:    u.OrderId,
+						StrategyID: u.StrategyId,
+						Symbol:     u.Symbol,
+						Side:       u.Side,
+						Price:      u.Price,
+						Qty:        u.Quantity,
+						FilledQty:  u.FilledQty,
+						Status:     u.Status,
+					}
+				}
+			}
+		}
+	}
+
+	for _, o := range open {
+		r.OpenOrders = append(r.OpenOrders, *o)
+	}
+	sort.Slice(r.OpenOrders, func(i, j int) bool { return r.OpenOrders[i].OrderID < r.OpenOrders[j].OrderID })
+	return r
+}
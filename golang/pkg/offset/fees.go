@@ -0,0 +1,18 @@
+package offset
+
+import (
+	tboffset "tbsrc-golang/pkg/offset"
+)
+
+// 品种表与 tbsrc-golang/pkg/offset 共用：费率、乘数、交易所只维护一份
+
+// Fee 单项手续费：按成交金额比例 + 按手固定金额
+type Fee = tboffset.Fee
+
+// Product 品种属性：交易所、合约乘数、开/平昨/平今手续费
+type Product = tboffset.Product
+
+// DefaultProducts 内置品种表（小写品种代码）
+func DefaultProducts() map[string]Product {
+	return tboffset.DefaultProducts()
+}
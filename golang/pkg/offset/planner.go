@@ -0,0 +1,464 @@
+// Package offset provides the open/close offset planner for Chinese futures orders
+// 开平仓选择：按交易所规则（SHFE/INE 平今/平昨）、品种手续费和锁仓偏好，把一笔订单拆成平昨/平今/开仓
+package offset
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	tboffset "tbsrc-golang/pkg/offset"
+)
+
+// 交易所代码（与 TradingSignal.Exchange 一致）
+const (
+	ExchangeSHFE  = tboffset.ExchangeSHFE
+	ExchangeINE   = tboffset.ExchangeINE
+	ExchangeDCE   = tboffset.ExchangeDCE
+	ExchangeCZCE  = tboffset.ExchangeCZCE
+	ExchangeCFFEX = tboffset.ExchangeCFFEX
+	ExchangeGFEX  = tboffset.ExchangeGFEX
+)
+
+// Offset 开平标志
+type Offset int
+
+const (
+	Open           Offset = iota // 开仓
+	Close                        // 平仓（交易所自行决定今昨，DCE/CZCE/CFFEX 先平昨）
+	CloseToday                   // 平今（SHFE/INE）
+	CloseYesterday               // 平昨（SHFE/INE）
+)
+
+// String 返回开平标志名称
+func (o Offset) String() string {
+	switch o {
+	case Open:
+		return "OPEN"
+	case Close:
+		return "CLOSE"
+	case CloseToday:
+		return "CLOSE_TODAY"
+	case CloseYesterday:
+		return "CLOSE_YESTERDAY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Side 买卖方向
+type Side int
+
+const (
+	Buy  Side = 1
+	Sell Side = 2
+)
+
+// LockMode 平今偏好
+type LockMode int
+
+const (
+	LockNever  LockMode = iota // 总是平今
+	LockAuto                   // 平今手续费高于开仓时锁仓（反向开仓）
+	LockAlways                 // 从不平今，今仓一律锁仓
+)
+
+// ParseLockMode 解析配置中的锁仓偏好："" / never / auto / always
+func ParseLockMode(s string) (LockMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "never":
+		return LockNever, nil
+	case "auto":
+		return LockAuto, nil
+	case "always":
+		return LockAlways, nil
+	default:
+		return LockNever, fmt.Errorf("unknown lock mode %q", s)
+	}
+}
+
+// String 返回锁仓偏好名称
+func (m LockMode) String() string {
+	switch m {
+	case LockAuto:
+		return "auto"
+	case LockAlways:
+		return "always"
+	default:
+		return "never"
+	}
+}
+
+// Position 单合约今/昨持仓（可平量，已扣除在途平仓单）
+type Position struct {
+	LongTd  int64
+	LongYd  int64
+	ShortTd int64
+	ShortYd int64
+}
+
+// Request 待拆分的订单
+type Request struct {
+	Account  string // 资金账户，空表示默认账户（多账户时各账户台账独立）
+	Symbol   string
+	Exchange string // 为空时取品种表中的交易所
+	Side     Side
+	Qty      int64
+	Price    float64 // 用于按比例收费品种的手续费比较
+}
+
+// Piece 拆分后的子订单
+type Piece struct {
+	Account string
+	Symbol  string
+	Side    Side
+	Offset  Offset
+	Qty     int64
+
+	fromTd int64 // 占用的今仓
+	fromYd int64 // 占用的昨仓
+}
+
+// tracked 已报出子订单的跟踪状态
+type tracked struct {
+	piece  Piece
+	filled int64
+}
+
+// Planner 开平仓规划器 + 持仓台账
+// 参考: gateway/src/counter_bridge.cpp SetCombOffsetFlag / updatePosition
+// C++ 只为整笔订单选择一个开平标志，下单时即扣减可平量，撤单/拒单时恢复；
+// 这里在此基础上允许拆单（平昨 + 平今 + 开仓），并按品种手续费决定平今/平昨的先后和是否锁仓；
+// 台账按（账户, 合约）分别记录，多账户跟单时各账户按自身持仓选择开平
+type Planner struct {
+	mu        sync.Mutex
+	products  map[string]Product
+	lock      LockMode
+	positions map[posKey]*Position
+	orders    map[string]*tracked
+}
+
+// posKey 台账键：账户 + 合约
+type posKey struct {
+	account string
+	symbol  string
+}
+
+// NewPlanner 创建规划器，使用内置品种表
+func NewPlanner(lock LockMode) *Planner {
+	return &Planner{
+		products:  DefaultProducts(),
+		lock:      lock,
+		positions: make(map[posKey]*Position),
+		orders:    make(map[string]*tracked),
+	}
+}
+
+// LockMode 返回锁仓偏好
+func (p *Planner) LockMode() LockMode {
+	return p.lock
+}
+
+// SetProduct 覆盖品种属性（品种代码不区分大小写）
+func (p *Planner) SetProduct(product string, prod Product) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.products[strings.ToLower(product)] = prod
+}
+
+// Product 查询合约所属品种的属性
+func (p *Planner) Product(symbol string) (Product, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prod, ok := p.products[tboffset.ProductKey(symbol)]
+	return prod, ok
+}
+
+// SetPosition 设置默认账户的合约持仓（启动时从柜台查询结果初始化）
+func (p *Planner) SetPosition(symbol string, pos Position) {
+	p.SetAccountPosition("", symbol, pos)
+}
+
+// SetAccountPosition 设置指定账户的合约持仓
+func (p *Planner) SetAccountPosition(account, symbol string, pos Position) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cp := pos
+	p.positions[posKey{account, symbol}] = &cp
+}
+
+// Position 返回默认账户的合约当前可平量
+func (p *Planner) Position(symbol string) Position {
+	return p.AccountPosition("", symbol)
+}
+
+// AccountPosition 返回指定账户的合约当前可平量
+func (p *Planner) AccountPosition(account, symbol string) Position {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pos, ok := p.positions[posKey{account, symbol}]; ok {
+		return *pos
+	}
+	return Position{}
+}
+
+// Plan 拆分订单并扣减可平量
+// 顺序：先平手续费低的一侧（通常平昨），再平今（锁仓时跳过），剩余部分开仓
+// 返回的子订单须逐个 Bind（报单成功）或 Abort（报单失败）
+func (p *Planner) Plan(req Request) []Piece {
+	if req.Qty <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pos := p.position(req.Account, req.Symbol)
+	td, yd := &pos.LongTd, &pos.LongYd
+	if req.Side == Buy {
+		td, yd = &pos.ShortTd, &pos.ShortYd
+	}
+
+	prod, known := p.products[tboffset.ProductKey(req.Symbol)]
+	exchange := req.Exchange
+	if exchange == "" {
+		exchange = prod.Exchange
+	}
+	lockToday := p.lockToday(prod, known, req.Price)
+
+	remaining := req.Qty
+	var pieces []Piece
+	take := func(bucket *int64) int64 {
+		n := min(remaining, *bucket)
+		if n < 0 {
+			n = 0
+		}
+		*bucket -= n
+		remaining -= n
+		return n
+	}
+
+	if SplitsToday(exchange) {
+		closeTd := func() {
+			if lockToday {
+				return
+			}
+			if n := take(td); n > 0 {
+				pieces = append(pieces, Piece{Offset: CloseToday, Qty: n, fromTd: n})
+			}
+		}
+		closeYd := func() {
+			if n := take(yd); n > 0 {
+				pieces = append(pieces, Piece{Offset: CloseYesterday, Qty: n, fromYd: n})
+			}
+		}
+		if known && cheaperToday(prod, req.Price) {
+			closeTd()
+			closeYd()
+		} else {
+			closeYd()
+			closeTd()
+		}
+	} else {
+		// 其他交易所平仓不区分今昨，先平昨；锁仓时只平到昨仓为止
+		nYd := take(yd)
+		var nTd int64
+		if !lockToday {
+			nTd = take(td)
+		}
+		if nYd+nTd > 0 {
+			pieces = append(pieces, Piece{Offset: Close, Qty: nYd + nTd, fromTd: nTd, fromYd: nYd})
+		}
+	}
+
+	if remaining > 0 {
+		pieces = append(pieces, Piece{Offset: Open, Qty: remaining})
+	}
+	for i := range pieces {
+		pieces[i].Account = req.Account
+		pieces[i].Symbol = req.Symbol
+		pieces[i].Side = req.Side
+	}
+	return pieces
+}
+
+// Bind 子订单报单成功，开始按订单号跟踪成交/撤单
+func (p *Planner) Bind(orderID string, piece Piece) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.orders[orderID] = &tracked{piece: piece}
+}
+
+// Adopt 接管重启前报出的在途订单，filledQty 为已成交量，返回推定的子订单
+// 柜台查询的在途订单不带开平标志：真实平仓单的未成交量不会超过当前可平量，
+// 因此未成交量能被可平量覆盖时按平仓处理（先昨后今）并扣减，否则视为开仓。
+// 开仓单被误判为平仓只会暂时少用可平量（多开少平），不会导致平仓被拒
+func (p *Planner) Adopt(orderID string, req Request, filledQty int64) Piece {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	piece := Piece{Account: req.Account, Symbol: req.Symbol, Side: req.Side, Offset: Open, Qty: req.Qty}
+	remaining := req.Qty - filledQty
+	if remaining <= 0 {
+		return piece
+	}
+
+	pos := p.position(req.Account, req.Symbol)
+	td, yd := &pos.LongTd, &pos.LongYd
+	if req.Side == Buy {
+		td, yd = &pos.ShortTd, &pos.ShortYd
+	}
+	if remaining <= max(*td, 0)+max(*yd, 0) {
+		piece.fromYd = min(remaining, max(*yd, 0))
+		piece.fromTd = remaining - piece.fromYd
+		*yd -= piece.fromYd
+		*td -= piece.fromTd
+		piece.Offset = Close
+		exchange := req.Exchange
+		if exchange == "" {
+			exchange = p.products[tboffset.ProductKey(req.Symbol)].Exchange
+		}
+		if SplitsToday(exchange) {
+			switch {
+			case piece.fromTd == 0:
+				piece.Offset = CloseYesterday
+			case piece.fromYd == 0:
+				piece.Offset = CloseToday
+			}
+		}
+	}
+	p.orders[orderID] = &tracked{piece: piece, filled: filledQty}
+	return piece
+}
+
+// Abort 子订单未报出，恢复 Plan 时扣减的可平量
+func (p *Planner) Abort(piece Piece) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.restore(piece.Account, piece.Symbol, piece.Side, piece.fromTd, piece.fromYd)
+}
+
+// OffsetOf 查询在途子订单的开平标志
+func (p *Planner) OffsetOf(orderID string) (Offset, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.orders[orderID]; ok {
+		return t.piece.Offset, true
+	}
+	return Open, false
+}
+
+// OnFill 成交回报，filledQty 为累计成交量
+// 开仓成交计入今仓；平仓成交在 Plan 时已扣减，只消耗占用（先昨后今）
+func (p *Planner) OnFill(orderID string, filledQty int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, ok := p.orders[orderID]
+	if !ok {
+		return
+	}
+	delta := min(filledQty, t.piece.Qty) - t.filled
+	if delta <= 0 {
+		return
+	}
+	t.filled += delta
+
+	if t.piece.Offset == Open {
+		pos := p.position(t.piece.Account, t.piece.Symbol)
+		if t.piece.Side == Buy {
+			pos.LongTd += delta
+		} else {
+			pos.ShortTd += delta
+		}
+	} else {
+		fromYd := min(delta, t.piece.fromYd)
+		t.piece.fromYd -= fromYd
+		t.piece.fromTd -= delta - fromYd
+	}
+
+	if t.filled >= t.piece.Qty {
+		delete(p.orders, orderID)
+	}
+}
+
+// OnDone 订单终结（撤单/拒单），恢复未成交部分占用的可平量
+func (p *Planner) OnDone(orderID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, ok := p.orders[orderID]
+	if !ok {
+		return
+	}
+	delete(p.orders, orderID)
+	p.restore(t.piece.Account, t.piece.Symbol, t.piece.Side, t.piece.fromTd, t.piece.fromYd)
+}
+
+// Rollover 交易日切换：今仓转为昨仓
+func (p *Planner) Rollover() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pos := range p.positions {
+		pos.LongYd += pos.LongTd
+		pos.LongTd = 0
+		pos.ShortYd += pos.ShortTd
+		pos.ShortTd = 0
+	}
+}
+
+// SplitsToday 交易所是否要求显式区分平今/平昨
+func SplitsToday(exchange string) bool {
+	switch strings.ToUpper(exchange) {
+	case ExchangeSHFE, ExchangeINE:
+		return true
+	}
+	return false
+}
+
+// position 取账户合约持仓（不存在时创建），调用方持锁
+func (p *Planner) position(account, symbol string) *Position {
+	key := posKey{account, symbol}
+	pos, ok := p.positions[key]
+	if !ok {
+		pos = &Position{}
+		p.positions[key] = pos
+	}
+	return pos
+}
+
+// restore 恢复平仓占用，调用方持锁
+func (p *Planner) restore(account, symbol string, side Side, fromTd, fromYd int64) {
+	if fromTd <= 0 && fromYd <= 0 {
+		return
+	}
+	pos := p.position(account, symbol)
+	if side == Buy {
+		pos.ShortTd += fromTd
+		pos.ShortYd += fromYd
+	} else {
+		pos.LongTd += fromTd
+		pos.LongYd += fromYd
+	}
+}
+
+// lockToday 是否以锁仓代替平今，调用方持锁
+func (p *Planner) lockToday(prod Product, known bool, price float64) bool {
+	switch p.lock {
+	case LockAlways:
+		return true
+	case LockAuto:
+		if !known {
+			return false
+		}
+		notional := price * prod.Multiplier
+		return prod.CloseToday.Cost(notional, 1) > prod.Open.Cost(notional, 1)
+	}
+	return false
+}
+
+// cheaperToday 平今手续费是否低于平昨（如 au/sc 平今免费）
+func cheaperToday(prod Product, price float64) bool {
+	notional := price * prod.Multiplier
+	return prod.CloseToday.Cost(notional, 1) < prod.CloseYesterday.Cost(notional, 1)
+}
@@ -0,0 +1,182 @@
+package offset
+
+import "testing"
+
+func offsets(pieces []Piece) []Offset {
+	out := make([]Offset, len(pieces))
+	for i, pc := range pieces {
+		out[i] = pc.Offset
+	}
+	return out
+}
+
+func TestPlan_SHFESplitsYesterdayTodayOpen(t *testing.T) {
+	p := NewPlanner(LockNever)
+	p.SetPosition("rb2505", Position{LongTd: 3, LongYd: 2})
+
+	pieces := p.Plan(Request{Symbol: "rb2505", Side: Sell, Qty: 8, Price: 3500})
+	if len(pieces) != 3 {
+		t.Fatalf("pieces = %v, want 3", offsets(pieces))
+	}
+	want := []struct {
+		off Offset
+		qty int64
+	}{{CloseYesterday, 2}, {CloseToday, 3}, {Open, 3}}
+	for i, w := range want {
+		if pieces[i].Offset != w.off || pieces[i].Qty != w.qty {
+			t.Errorf("piece[%d] = %v/%d, want %v/%d", i, pieces[i].Offset, pieces[i].Qty, w.off, w.qty)
+		}
+		if pieces[i].Symbol != "rb2505" || pieces[i].Side != Sell {
+			t.Errorf("piece[%d] = %s/%d, want rb2505/Sell", i, pieces[i].Symbol, pieces[i].Side)
+		}
+	}
+
+	pos := p.Position("rb2505")
+	if pos.LongTd != 0 || pos.LongYd != 0 {
+		t.Errorf("position after plan = %+v, want long fully reserved", pos)
+	}
+}
+
+func TestPlan_CheaperTodayClosesTodayFirst(t *testing.T) {
+	p := NewPlanner(LockNever)
+	// au 平今免费
+	p.SetPosition("au2506", Position{ShortTd: 1, ShortYd: 5})
+
+	pieces := p.Plan(Request{Symbol: "au2506", Side: Buy, Qty: 2, Price: 600})
+	if len(pieces) != 2 || pieces[0].Offset != CloseToday || pieces[1].Offset != CloseYesterday {
+		t.Fatalf("offsets = %v, want [CLOSE_TODAY CLOSE_YESTERDAY]", offsets(pieces))
+	}
+	if pieces[0].Qty != 1 || pieces[1].Qty != 1 {
+		t.Errorf("qty = %d/%d, want 1/1", pieces[0].Qty, pieces[1].Qty)
+	}
+}
+
+func TestPlan_LockAutoOpensInsteadOfCloseToday(t *testing.T) {
+	p := NewPlanner(LockAuto)
+	// cu 平今 万1 > 开仓 万0.5
+	p.SetPosition("cu2505", Position{LongTd: 4, LongYd: 1})
+
+	pieces := p.Plan(Request{Symbol: "cu2505", Side: Sell, Qty: 3, Price: 75000})
+	if len(pieces) != 2 || pieces[0].Offset != CloseYesterday || pieces[1].Offset != Open {
+		t.Fatalf("offsets = %v, want [CLOSE_YESTERDAY OPEN]", offsets(pieces))
+	}
+	if pieces[1].Qty != 2 {
+		t.Errorf("open qty = %d, want 2", pieces[1].Qty)
+	}
+	if got := p.Position("cu2505").LongTd; got != 4 {
+		t.Errorf("LongTd = %d, want 4 (locked, not closed)", got)
+	}
+
+	// rb 平今与开仓同费率，auto 不锁仓
+	p.SetPosition("rb2505", Position{LongTd: 2})
+	pieces = p.Plan(Request{Symbol: "rb2505", Side: Sell, Qty: 2, Price: 3500})
+	if len(pieces) != 1 || pieces[0].Offset != CloseToday {
+		t.Errorf("offsets = %v, want [CLOSE_TODAY]", offsets(pieces))
+	}
+}
+
+func TestPlan_NonSHFEUsesClose(t *testing.T) {
+	p := NewPlanner(LockNever)
+	p.SetPosition("IF2506", Position{ShortTd: 2, ShortYd: 1})
+
+	pieces := p.Plan(Request{Symbol: "IF2506", Side: Buy, Qty: 4, Price: 3800})
+	if len(pieces) != 2 || pieces[0].Offset != Close || pieces[1].Offset != Open {
+		t.Fatalf("offsets = %v, want [CLOSE OPEN]", offsets(pieces))
+	}
+	if pieces[0].Qty != 3 || pieces[1].Qty != 1 {
+		t.Errorf("qty = %d/%d, want 3/1", pieces[0].Qty, pieces[1].Qty)
+	}
+
+	// 股指平今 10 倍手续费：always 只平昨仓
+	p = NewPlanner(LockAlways)
+	p.SetPosition("IF2506", Position{ShortTd: 2, ShortYd: 1})
+	pieces = p.Plan(Request{Symbol: "IF2506", Side: Buy, Qty: 2, Price: 3800})
+	if len(pieces) != 2 || pieces[0].Qty != 1 || pieces[1].Offset != Open || pieces[1].Qty != 1 {
+		t.Errorf("pieces = %+v, want close 1 + open 1", pieces)
+	}
+}
+
+func TestPlanner_FillsCancelsAndRollover(t *testing.T) {
+	p := NewPlanner(LockNever)
+	p.SetPosition("ag2506", Position{LongYd: 2})
+
+	pieces := p.Plan(Request{Symbol: "ag2506", Side: Sell, Qty: 5, Price: 8000})
+	if len(pieces) != 2 {
+		t.Fatalf("pieces = %v, want 2", offsets(pieces))
+	}
+	p.Bind("c1", pieces[0])
+	p.Bind("o1", pieces[1])
+
+	// 平昨成交 1 手后撤单：恢复 1 手昨仓
+	p.OnFill("c1", 1)
+	p.OnDone("c1")
+	if got := p.Position("ag2506").LongYd; got != 1 {
+		t.Errorf("LongYd = %d, want 1", got)
+	}
+
+	// 开仓成交计入今空仓，重复回报不重复计入
+	p.OnFill("o1", 2)
+	p.OnFill("o1", 2)
+	p.OnFill("o1", 3)
+	if got := p.Position("ag2506").ShortTd; got != 3 {
+		t.Errorf("ShortTd = %d, want 3", got)
+	}
+
+	// 报单失败恢复占用
+	more := p.Plan(Request{Symbol: "ag2506", Side: Sell, Qty: 1, Price: 8000})
+	p.Abort(more[0])
+	if got := p.Position("ag2506").LongYd; got != 1 {
+		t.Errorf("LongYd after abort = %d, want 1", got)
+	}
+
+	p.Rollover()
+	pos := p.Position("ag2506")
+	if pos.ShortTd != 0 || pos.ShortYd != 3 {
+		t.Errorf("after rollover = %+v, want ShortYd 3", pos)
+	}
+}
+
+func TestPlanner_AdoptReservesCloseQty(t *testing.T) {
+	p := NewPlanner(LockNever)
+	p.SetPosition("rb2505", Position{LongTd: 3, LongYd: 2})
+
+	// 卖 5 已成交 1：未成交 4 手在可平量内，按平仓扣减（先昨后今）
+	pc := p.Adopt("c1", Request{Symbol: "rb2505", Side: Sell, Qty: 5, Price: 3500}, 1)
+	if pc.Offset != Close || pc.Qty != 5 {
+		t.Errorf("adopted piece = %v/%d, want CLOSE/5", pc.Offset, pc.Qty)
+	}
+	if pos := p.Position("rb2505"); pos.LongYd != 0 || pos.LongTd != 1 {
+		t.Errorf("position after adopt = %+v, want LongYd 0 LongTd 1", pos)
+	}
+	if off, ok := p.OffsetOf("c1"); !ok || off != Close {
+		t.Errorf("OffsetOf(c1) = %v/%v, want CLOSE", off, ok)
+	}
+
+	// 超出可平量的在途单视为开仓，成交计入今仓
+	if pc := p.Adopt("o1", Request{Symbol: "rb2505", Side: Sell, Qty: 2, Price: 3500}, 0); pc.Offset != Open {
+		t.Errorf("adopted piece = %v, want OPEN", pc.Offset)
+	}
+	p.OnFill("o1", 2)
+	if got := p.Position("rb2505").ShortTd; got != 2 {
+		t.Errorf("ShortTd = %d, want 2", got)
+	}
+
+	// 平仓单成交 2 手（累计 3）后撤单：恢复剩余 2 手今仓
+	p.OnFill("c1", 3)
+	p.OnDone("c1")
+	if pos := p.Position("rb2505"); pos.LongYd != 0 || pos.LongTd != 3 {
+		t.Errorf("position after cancel = %+v, want LongTd 3", pos)
+	}
+}
+
+func TestParseLockMode(t *testing.T) {
+	for in, want := range map[string]LockMode{"": LockNever, "never": LockNever, "Auto": LockAuto, "always": LockAlways} {
+		got, err := ParseLockMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLockMode(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLockMode("sometimes"); err == nil {
+		t.Error("ParseLockMode(sometimes) error = nil, want error")
+	}
+}
@@ -0,0 +1,350 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/stats"
+)
+
+// AllocationPolicy computes target capital weights for the portfolio (Go 扩展)
+//
+// 权重为占 TotalCapital 的比例。满仓类策略（equal / inverse_vol / risk_parity）权重和为 1；
+// vol_target / kelly 权重和可以小于 1（剩余资金不分配）。
+// 边界（min/max）、换手阻尼由 PortfolioManager 统一处理，策略只给出目标值
+type AllocationPolicy interface {
+	// Name returns the policy name used in configuration
+	Name() string
+
+	// FullyInvested reports whether weights are renormalized to sum to 1 after bounds
+	FullyInvested() bool
+
+	// TargetWeights returns target weights aligned with in.StrategyIDs
+	TargetWeights(in *AllocationInput) ([]float64, error)
+}
+
+// AllocationInput 分配策略的输入：各策略在相同采样点上的收益率序列
+// 收益率 = 采样周期内 P&L 变化 / 当时的分配资金
+type AllocationInput struct {
+	StrategyIDs    []string
+	Returns        [][]float64 // [strategy][sample]，长度相同
+	Covariance     [][]float64 // 收益率协方差（每采样周期）
+	Current        []float64   // 当前权重
+	PeriodsPerYear float64     // 年化因子（每年采样周期数）
+}
+
+// PolicyConfig 分配策略参数
+type PolicyConfig struct {
+	Name               string   // equal（默认）/ inverse_vol / risk_parity / vol_target / kelly
+	Lookback           int      // 使用最近 N 个采样点（默认 500）
+	MinHistory         int      // 少于 N 个采样点时不调整（默认 30）
+	SampleIntervalSec  int      // 收益率采样间隔（默认 300 秒）
+	PeriodsPerYear     float64  // 年化因子（默认按每天 6 个交易小时、252 个交易日折算）
+	TargetVol          float64  // vol_target: 组合年化波动率目标（占总资金比例，如 0.10）
+	MaxGross           float64  // vol_target / kelly: 权重和上限（默认 1）
+	KellyFraction      float64  // kelly: 分数 Kelly 系数（默认 0.25）
+	KellyCap           float64  // kelly: 单策略权重上限（默认 MaxAllocation）
+	Damping            float64  // 换手阻尼: 每次只移动 damping*(目标-当前)，(0,1]，默认 1（不阻尼）
+	MaxTurnover        float64  // 单次再平衡权重变化绝对值之和上限（0 = 不限）
+	RebalanceThreshold float64  // 单策略权重变化小于该值时保持不变（默认 0）
+	SizeParams         []string // 随分配同比缩放的策略参数（默认 order_size / max_position_size）
+}
+
+// DefaultSizeParams 随分配同比缩放的默认策略参数
+var DefaultSizeParams = []string{"order_size", "max_position_size"}
+
+func (c *PolicyConfig) withDefaults(maxAlloc float64) PolicyConfig {
+	out := *c
+	if out.Name == "" {
+		out.Name = "equal"
+	}
+	out.Name = strings.ToLower(out.Name)
+	if out.Lookback <= 0 {
+		out.Lookback = 500
+	}
+	if out.MinHistory <= 0 {
+		out.MinHistory = 30
+	}
+	if out.SampleIntervalSec <= 0 {
+		out.SampleIntervalSec = 300
+	}
+	if out.PeriodsPerYear <= 0 {
+		out.PeriodsPerYear = 252 * 6 * 3600 / float64(out.SampleIntervalSec)
+	}
+	if out.MaxGross <= 0 {
+		out.MaxGross = 1
+	}
+	if out.KellyFraction <= 0 {
+		out.KellyFraction = 0.25
+	}
+	if out.KellyCap <= 0 {
+		out.KellyCap = maxAlloc
+	}
+	if out.Damping <= 0 || out.Damping > 1 {
+		out.Damping = 1
+	}
+	if out.SizeParams == nil {
+		out.SizeParams = DefaultSizeParams
+	}
+	return out
+}
+
+// NewAllocationPolicy creates a policy by name
+func NewAllocationPolicy(cfg PolicyConfig) (AllocationPolicy, error) {
+	switch cfg.Name {
+	case "", "equal":
+		return equalWeight{}, nil
+	case "inverse_vol":
+		return inverseVol{}, nil
+	case "risk_parity":
+		return riskParity{}, nil
+	case "vol_target":
+		if cfg.TargetVol <= 0 {
+			return nil, fmt.Errorf("vol_target requires target_vol > 0")
+		}
+		return volTarget{target: cfg.TargetVol, maxGross: cfg.MaxGross}, nil
+	case "kelly":
+		return kelly{fraction: cfg.KellyFraction, cap: cfg.KellyCap, maxGross: cfg.MaxGross}, nil
+	}
+	return nil, fmt.Errorf("unknown allocation policy %q (equal, inverse_vol, risk_parity, vol_target, kelly)", cfg.Name)
+}
+
+// equalWeight 等权（原 Rebalance 行为）
+type equalWeight struct{}
+
+func (equalWeight) Name() string        { return "equal" }
+func (equalWeight) FullyInvested() bool { return true }
+func (equalWeight) TargetWeights(in *AllocationInput) ([]float64, error) {
+	w := make([]float64, len(in.StrategyIDs))
+	for i := range w {
+		w[i] = 1 / float64(len(w))
+	}
+	return w, nil
+}
+
+// inverseVol 波动率倒数加权：w_i ∝ 1/σ_i
+type inverseVol struct{}
+
+func (inverseVol) Name() string        { return "inverse_vol" }
+func (inverseVol) FullyInvested() bool { return true }
+func (inverseVol) TargetWeights(in *AllocationInput) ([]float64, error) {
+	w := inverseVolWeights(in.Covariance)
+	if w == nil {
+		return nil, fmt.Errorf("zero volatility for all strategies")
+	}
+	return w, nil
+}
+
+func inverseVolWeights(cov [][]float64) []float64 {
+	w := make([]float64, len(cov))
+	sum := 0.0
+	for i := range cov {
+		if cov[i][i] > 0 {
+			w[i] = 1 / math.Sqrt(cov[i][i])
+			sum += w[i]
+		}
+	}
+	if sum == 0 {
+		return nil
+	}
+	for i := range w {
+		w[i] /= sum
+	}
+	return w
+}
+
+// riskParity 等风险贡献：w_i·(Σw)_i 对所有策略相等
+// 不动点迭代 w_i ← sqrt(w_i / (Σw)_i)，从波动率倒数权重出发；
+// 边际风险非正（强负相关）时退化为波动率倒数权重
+type riskParity struct{}
+
+func (riskParity) Name() string        { return "risk_parity" }
+func (riskParity) FullyInvested() bool { return true }
+func (riskParity) TargetWeights(in *AllocationInput) ([]float64, error) {
+	w := inverseVolWeights(in.Covariance)
+	if w == nil {
+		return nil, fmt.Errorf("zero volatility for all strategies")
+	}
+	for iter := 0; iter < 500; iter++ {
+		mrc := matVec(in.Covariance, w)
+		next := make([]float64, len(w))
+		sum := 0.0
+		for i := range w {
+			if w[i] == 0 {
+				continue
+			}
+			if mrc[i] <= 0 {
+				return inverseVolWeights(in.Covariance), nil
+			}
+			next[i] = math.Sqrt(w[i] / mrc[i])
+			sum += next[i]
+		}
+		maxDiff := 0.0
+		for i := range next {
+			next[i] /= sum
+			maxDiff = math.Max(maxDiff, math.Abs(next[i]-w[i]))
+		}
+		w = next
+		if maxDiff < 1e-10 {
+			break
+		}
+	}
+	return w, nil
+}
+
+// volTarget 波动率目标：风险平价权重整体缩放，使组合年化波动率 = target
+// 权重和不超过 maxGross
+type volTarget struct {
+	target   float64
+	maxGross float64
+}
+
+func (volTarget) Name() string        { return "vol_target" }
+func (volTarget) FullyInvested() bool { return false }
+func (v volTarget) TargetWeights(in *AllocationInput) ([]float64, error) {
+	w, err := riskParity{}.TargetWeights(in)
+	if err != nil {
+		return nil, err
+	}
+	vol := math.Sqrt(dot(w, matVec(in.Covariance, w)) * in.PeriodsPerYear)
+	if vol == 0 {
+		return nil, fmt.Errorf("zero portfolio volatility")
+	}
+	scale := math.Min(v.target/vol, v.maxGross)
+	for i := range w {
+		w[i] *= scale
+	}
+	return w, nil
+}
+
+// kelly 分数 Kelly：w = f·Σ⁻¹μ，负权重置 0，单策略不超过 cap，权重和不超过 maxGross
+// 协方差矩阵奇异时只用对角线（w_i = f·μ_i/σ_i²）
+type kelly struct {
+	fraction float64
+	cap      float64
+	maxGross float64
+}
+
+func (kelly) Name() string        { return "kelly" }
+func (kelly) FullyInvested() bool { return false }
+func (k kelly) TargetWeights(in *AllocationInput) ([]float64, error) {
+	mu := make([]float64, len(in.Returns))
+	for i, r := range in.Returns {
+		mu[i] = stats.Mean(r)
+	}
+	w, ok := stats.SolveLinear(in.Covariance, mu, 1e-18)
+	if !ok {
+		w = make([]float64, len(mu))
+		for i := range mu {
+			if v := in.Covariance[i][i]; v > 0 {
+				w[i] = mu[i] / v
+			}
+		}
+	}
+	sum := 0.0
+	for i := range w {
+		w[i] = math.Min(math.Max(w[i]*k.fraction, 0), k.cap)
+		sum += w[i]
+	}
+	if sum > k.maxGross {
+		for i := range w {
+			w[i] *= k.maxGross / sum
+		}
+	}
+	return w, nil
+}
+
+// applyBounds 限制每个权重在 [lo, hi]（hi 未配置时为 1）；fullyInvested 时把超出/不足部分按比例分摊给未触边界的策略，
+// 使权重和为 1（无法满足时尽量接近）。非满仓策略权重和超过 1 时等比缩小
+func applyBounds(w []float64, lo, hi float64, fullyInvested bool) []float64 {
+	if hi <= 0 {
+		hi = 1
+	}
+	out := make([]float64, len(w))
+	copy(out, w)
+	if !fullyInvested {
+		sum := 0.0
+		for i := range out {
+			out[i] = math.Min(math.Max(out[i], lo), hi)
+			sum += out[i]
+		}
+		if sum > 1 {
+			for i := range out {
+				out[i] /= sum
+			}
+		}
+		return out
+	}
+
+	fixed := make([]bool, len(out))
+	for iter := 0; iter <= len(out); iter++ {
+		freeSum, fixedSum := 0.0, 0.0
+		for i := range out {
+			if fixed[i] {
+				fixedSum += out[i]
+			} else {
+				freeSum += out[i]
+			}
+		}
+		if freeSum <= 0 {
+			break
+		}
+		scale := (1 - fixedSum) / freeSum
+		changed := false
+		for i := range out {
+			if fixed[i] {
+				continue
+			}
+			out[i] *= scale
+			if out[i] > hi {
+				out[i], fixed[i], changed = hi, true, true
+			} else if out[i] < lo {
+				out[i], fixed[i], changed = lo, true, true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return out
+}
+
+// applyDamping 换手阻尼：按 damping 向目标移动，总换手不超过 maxTurnover，
+// 变化小于 threshold 的策略保持不变
+func applyDamping(target, current []float64, damping, maxTurnover, threshold float64) []float64 {
+	delta := make([]float64, len(target))
+	turnover := 0.0
+	for i := range target {
+		delta[i] = damping * (target[i] - current[i])
+		if math.Abs(delta[i]) < threshold {
+			delta[i] = 0
+		}
+		turnover += math.Abs(delta[i])
+	}
+	scale := 1.0
+	if maxTurnover > 0 && turnover > maxTurnover {
+		scale = maxTurnover / turnover
+	}
+	out := make([]float64, len(target))
+	for i := range target {
+		out[i] = current[i] + scale*delta[i]
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func matVec(m [][]float64, v []float64) []float64 {
+	out := make([]float64, len(m))
+	for i := range m {
+		out[i] = dot(m[i], v)
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// diagInput 各策略不相关、波动率为 vols 的输入
+func diagInput(vols ...float64) *AllocationInput {
+	n := len(vols)
+	in := &AllocationInput{
+		StrategyIDs:    make([]string, n),
+		Returns:        make([][]float64, n),
+		Covariance:     make([][]float64, n),
+		Current:        make([]float64, n),
+		PeriodsPerYear: 252,
+	}
+	for i, v := range vols {
+		in.StrategyIDs[i] = string(rune('a' + i))
+		in.Covariance[i] = make([]float64, n)
+		in.Covariance[i][i] = v * v
+	}
+	return in
+}
+
+func TestInverseVol(t *testing.T) {
+	w, err := inverseVol{}.TargetWeights(diagInput(0.01, 0.02))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(w[0]-2.0/3) > 1e-9 || math.Abs(w[1]-1.0/3) > 1e-9 {
+		t.Errorf("weights = %v, want [0.667 0.333]", w)
+	}
+}
+
+func TestRiskParity_EqualRiskContribution(t *testing.T) {
+	in := diagInput(0.01, 0.02, 0.04)
+	// 前两个策略正相关 0.5
+	in.Covariance[0][1] = 0.5 * 0.01 * 0.02
+	in.Covariance[1][0] = in.Covariance[0][1]
+
+	w, err := riskParity{}.TargetWeights(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mrc := matVec(in.Covariance, w)
+	rc0 := w[0] * mrc[0]
+	for i := range w {
+		if rc := w[i] * mrc[i]; math.Abs(rc-rc0)/rc0 > 1e-6 {
+			t.Errorf("risk contribution %d = %g, want %g (weights %v)", i, rc, rc0, w)
+		}
+	}
+	if math.Abs(w[0]+w[1]+w[2]-1) > 1e-9 {
+		t.Errorf("weights sum = %v", w[0]+w[1]+w[2])
+	}
+}
+
+func TestVolTarget(t *testing.T) {
+	in := diagInput(0.01, 0.01)
+	w, err := volTarget{target: 0.05, maxGross: 1}.TargetWeights(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol := math.Sqrt(dot(w, matVec(in.Covariance, w)) * in.PeriodsPerYear)
+	if math.Abs(vol-0.05) > 1e-9 {
+		t.Errorf("portfolio vol = %v, want 0.05", vol)
+	}
+
+	// 目标过高时受 maxGross 限制
+	w, _ = volTarget{target: 10, maxGross: 0.8}.TargetWeights(in)
+	if math.Abs(w[0]+w[1]-0.8) > 1e-9 {
+		t.Errorf("gross = %v, want 0.8", w[0]+w[1])
+	}
+}
+
+func TestKelly(t *testing.T) {
+	in := diagInput(0.02, 0.02, 0.02)
+	in.Returns = [][]float64{{0.001, 0.001}, {0.0002, 0.0002}, {-0.001, -0.001}}
+
+	w, err := kelly{fraction: 0.5, cap: 0.4, maxGross: 1}.TargetWeights(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// f·μ/σ² = 0.5*0.001/0.0004 = 1.25 → cap 0.4；0.5*0.0002/0.0004 = 0.25；负收益 → 0
+	want := []float64{0.4, 0.25, 0}
+	for i := range want {
+		if math.Abs(w[i]-want[i]) > 1e-9 {
+			t.Errorf("weights = %v, want %v", w, want)
+			break
+		}
+	}
+}
+
+func TestApplyBounds(t *testing.T) {
+	w := applyBounds([]float64{0.8, 0.15, 0.05}, 0.1, 0.5, true)
+	if w[0] != 0.5 || w[2] != 0.1 || math.Abs(w[0]+w[1]+w[2]-1) > 1e-9 {
+		t.Errorf("fully invested bounds = %v", w)
+	}
+	w = applyBounds([]float64{0.8, 0.02}, 0.05, 0.5, false)
+	if w[0] != 0.5 || w[1] != 0.05 {
+		t.Errorf("partial bounds = %v", w)
+	}
+}
+
+func TestApplyDamping(t *testing.T) {
+	w := applyDamping([]float64{0.6, 0.4}, []float64{0.5, 0.5}, 0.5, 0, 0)
+	if math.Abs(w[0]-0.55) > 1e-9 || math.Abs(w[1]-0.45) > 1e-9 {
+		t.Errorf("damped = %v, want [0.55 0.45]", w)
+	}
+	w = applyDamping([]float64{0.9, 0.1}, []float64{0.5, 0.5}, 1, 0.2, 0)
+	if math.Abs(w[0]-0.6) > 1e-9 || math.Abs(w[1]-0.4) > 1e-9 {
+		t.Errorf("turnover capped = %v, want [0.6 0.4]", w)
+	}
+	w = applyDamping([]float64{0.51, 0.49}, []float64{0.5, 0.5}, 1, 0, 0.02)
+	if w[0] != 0.5 || w[1] != 0.5 {
+		t.Errorf("below threshold = %v, want unchanged", w)
+	}
+}
+
+// sizedMock 带 MaxPositionSize 和下单量参数的策略
+type sizedMock struct {
+	*MockStrategy
+	cfg    *strategy.StrategyConfig
+	params map[string]interface{}
+}
+
+func (m *sizedMock) GetConfig() *strategy.StrategyConfig          { return m.cfg }
+func (m *sizedMock) GetCurrentParameters() map[string]interface{} { return m.params }
+func (m *sizedMock) UpdateParameters(p map[string]interface{}) error {
+	for k, v := range p {
+		m.params[k] = v
+	}
+	return nil
+}
+
+func TestPortfolioManager_InverseVolRebalanceResizes(t *testing.T) {
+	pm := NewPortfolioManager(&PortfolioConfig{
+		TotalCapital:          1000000,
+		MinAllocation:         0.05,
+		MaxAllocation:         0.9,
+		EnableCorrelationCalc: true,
+		Policy:                PolicyConfig{Name: "inverse_vol", MinHistory: 10, Damping: 0.5},
+	})
+	if err := pm.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	calm := &sizedMock{MockStrategy: NewMockStrategy("calm"),
+		cfg:    &strategy.StrategyConfig{MaxPositionSize: 10},
+		params: map[string]interface{}{"order_size": 2.0, "max_position_size": 10}}
+	wild := &sizedMock{MockStrategy: NewMockStrategy("wild"),
+		cfg:    &strategy.StrategyConfig{MaxPositionSize: 10},
+		params: map[string]interface{}{"order_size": 2.0}}
+	calm.Start()
+	wild.Start()
+	pm.AddStrategy(calm, 0.5)
+	pm.AddStrategy(wild, 0.5)
+
+	// 历史不足：保持不变
+	if err := pm.Rebalance(); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := pm.GetAllocation("calm"); a.AllocationPercent != 0.5 {
+		t.Fatalf("allocation changed without history: %v", a.AllocationPercent)
+	}
+
+	// wild 的 P&L 波动是 calm 的 3 倍
+	pnlCalm, pnlWild := 0.0, 0.0
+	for i := 0; i <= 20; i++ {
+		sign := float64(1 - 2*(i%2))
+		pnlCalm += 1000 * sign
+		pnlWild += 3000 * sign
+		calm.SetPNL(strategy.PNL{TotalPnL: pnlCalm})
+		wild.SetPNL(strategy.PNL{TotalPnL: pnlWild})
+		pm.SampleReturns()
+	}
+
+	if err := pm.Rebalance(); err != nil {
+		t.Fatal(err)
+	}
+	// 目标 0.75/0.25，阻尼 0.5 → 0.625/0.375
+	a, _ := pm.GetAllocation("calm")
+	b, _ := pm.GetAllocation("wild")
+	if math.Abs(a.AllocationPercent-0.625) > 1e-9 || math.Abs(b.AllocationPercent-0.375) > 1e-9 {
+		t.Errorf("allocations = %.4f / %.4f, want 0.625 / 0.375", a.AllocationPercent, b.AllocationPercent)
+	}
+
+	// 分配传递到策略：0.625/0.5 = 1.25 倍，0.375/0.5 = 0.75 倍
+	if calm.cfg.MaxPositionSize != 13 || wild.cfg.MaxPositionSize != 8 {
+		t.Errorf("MaxPositionSize = %d / %d, want 13 / 8", calm.cfg.MaxPositionSize, wild.cfg.MaxPositionSize)
+	}
+	if calm.params["order_size"] != 3.0 || calm.params["max_position_size"] != 13.0 || wild.params["order_size"] != 2.0 {
+		t.Errorf("params = %v / %v", calm.params, wild.params)
+	}
+
+	corr, err := pm.CalculateCorrelation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corr.Samples != 20 || math.Abs(corr.Matrix[0][1]-1) > 1e-9 {
+		t.Errorf("correlation = %v (samples %d), want 1", corr.Matrix[0][1], corr.Samples)
+	}
+}
+
+func TestNewAllocationPolicy_Unknown(t *testing.T) {
+	if _, err := NewAllocationPolicy(PolicyConfig{Name: "mean_variance"}); err == nil {
+		t.Error("unknown policy should fail")
+	}
+	if _, err := NewAllocationPolicy(PolicyConfig{Name: "vol_target"}); err == nil {
+		t.Error("vol_target without target_vol should fail")
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/yourusername/quantlink-trade-system/pkg/stats"
 	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
 )
 
@@ -20,6 +22,8 @@ type PortfolioConfig struct {
 	MaxAllocation         float64            // Maximum allocation per strategy
 	EnableAutoRebalance   bool               // Enable automatic rebalancing
 	EnableCorrelationCalc bool               // Enable correlation calculation
+	Policy                PolicyConfig       // Allocation policy (Go 扩展，默认等权)
+	Sizing                SizingConfig       // Allocation → strategy size translation (Go 扩展)
 }
 
 // PortfolioStats represents portfolio statistics
@@ -53,6 +57,8 @@ type StrategyAllocation struct {
 type CorrelationMatrix struct {
 	StrategyIDs []string
 	Matrix      [][]float64 // correlation coefficients
+	Covariance  [][]float64 // covariance of per-sample returns
+	Samples     int         // number of return samples used
 	Timestamp   time.Time
 }
 
@@ -70,6 +76,15 @@ type PortfolioManager struct {
 	pnlHistory   []float64
 	maxPnLHistory int
 
+	// 分配策略（Go 扩展）
+	policy    AllocationPolicy
+	policyCfg PolicyConfig
+	returns   map[string][]float64    // strategy_id -> 每采样周期收益率（ΔP&L / 分配资金）
+	lastPnL   map[string]float64      // strategy_id -> 上次采样的 TotalPnL
+	sizing    map[string]*sizeBaseline // strategy_id -> 加入组合时的分配与下单量
+	sizingCfg SizingConfig
+	resizeEvents []ResizeEvent
+
 	mu           sync.RWMutex
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
@@ -95,6 +110,7 @@ func NewPortfolioManager(config *PortfolioConfig) *PortfolioManager {
 		config.StrategyAllocation = make(map[string]float64)
 	}
 
+	policyCfg := config.Policy.withDefaults(config.MaxAllocation)
 	return &PortfolioManager{
 		config:        config,
 		strategies:    make(map[string]strategy.Strategy),
@@ -103,6 +119,12 @@ func NewPortfolioManager(config *PortfolioConfig) *PortfolioManager {
 		pnlHistory:    make([]float64, 0, 1000),
 		maxPnLHistory: 1000,
 		stopChan:      make(chan struct{}),
+		policy:        equalWeight{},
+		policyCfg:     policyCfg,
+		returns:       make(map[string][]float64),
+		lastPnL:       make(map[string]float64),
+		sizing:        make(map[string]*sizeBaseline),
+		sizingCfg:     config.Sizing.withDefaults(),
 	}
 }
 
@@ -111,10 +133,20 @@ func (pm *PortfolioManager) Initialize() error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	policy, err := NewAllocationPolicy(pm.policyCfg)
+	if err != nil {
+		return err
+	}
+	pm.policy = policy
+	if err := pm.sizingCfg.validate(); err != nil {
+		return err
+	}
+
 	pm.stats.TotalCapital = pm.config.TotalCapital
 	pm.stats.Timestamp = time.Now()
 
-	log.Printf("[PortfolioManager] Initialized with capital: %.2f", pm.config.TotalCapital)
+	log.Printf("[PortfolioManager] Initialized with capital: %.2f, allocation policy: %s, sizing: %s",
+		pm.config.TotalCapital, policy.Name(), pm.sizingCfg.Mode)
 	return nil
 }
 
@@ -189,6 +221,7 @@ func (pm *PortfolioManager) AddStrategy(s strategy.Strategy, allocationPercent f
 
 	pm.allocations[id] = allocation
 	pm.config.StrategyAllocation[id] = allocationPercent
+	pm.sizing[id] = newSizeBaseline(s, allocationPercent, pm.policyCfg.SizeParams)
 
 	log.Printf("[PortfolioManager] Added strategy %s with %.2f%% allocation (%.2f capital)",
 		id, allocationPercent*100, allocation.AllocatedCapital)
@@ -208,6 +241,9 @@ func (pm *PortfolioManager) RemoveStrategy(strategyID string) error {
 	delete(pm.strategies, strategyID)
 	delete(pm.allocations, strategyID)
 	delete(pm.config.StrategyAllocation, strategyID)
+	delete(pm.returns, strategyID)
+	delete(pm.lastPnL, strategyID)
+	delete(pm.sizing, strategyID)
 
 	log.Printf("[PortfolioManager] Removed strategy %s", strategyID)
 	return nil
@@ -277,53 +313,127 @@ func (pm *PortfolioManager) UpdateAllocations() error {
 	return nil
 }
 
-// Rebalance rebalances the portfolio
+// Rebalance rebalances the portfolio with the configured allocation policy
+// 目标权重 → [MinAllocation, MaxAllocation] 边界 → 换手阻尼；
+// 分配变化的策略重新计算 MaxPositionSize 和下单量参数（见 sizing.go，缩小时逐步下发）
 func (pm *PortfolioManager) Rebalance() error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
-	log.Println("[PortfolioManager] Rebalancing portfolio...")
-
-	// Simple equal-weight rebalancing
-	// More sophisticated methods could be implemented:
-	// - Risk parity
-	// - Mean-variance optimization
-	// - Kelly criterion
+	if len(pm.strategies) == 0 {
+		return nil
+	}
 
-	numStrategies := len(pm.strategies)
-	if numStrategies == 0 {
+	in := pm.allocationInputLocked()
+	if _, ok := pm.policy.(equalWeight); !ok && len(in.Returns[0]) < pm.policyCfg.MinHistory {
+		log.Printf("[PortfolioManager] Rebalance skipped: %d return samples, %s needs %d",
+			len(in.Returns[0]), pm.policy.Name(), pm.policyCfg.MinHistory)
 		return nil
 	}
 
-	// Equal weight allocation
-	equalWeight := 1.0 / float64(numStrategies)
+	target, err := pm.policy.TargetWeights(in)
+	if err != nil {
+		return fmt.Errorf("allocation policy %s: %w", pm.policy.Name(), err)
+	}
+	bounded := applyBounds(target, pm.config.MinAllocation, pm.config.MaxAllocation, pm.policy.FullyInvested())
+	weights := applyDamping(bounded, in.Current, pm.policyCfg.Damping,
+		pm.policyCfg.MaxTurnover, pm.policyCfg.RebalanceThreshold)
 
-	// Ensure within bounds
-	if equalWeight < pm.config.MinAllocation {
-		equalWeight = pm.config.MinAllocation
+	log.Printf("[PortfolioManager] Rebalancing portfolio (policy=%s, samples=%d)...",
+		pm.policy.Name(), len(in.Returns[0]))
+	for i, id := range in.StrategyIDs {
+		alloc := pm.allocations[id]
+		old := alloc.AllocationPercent
+		alloc.AllocationPercent = weights[i]
+		alloc.AllocatedCapital = pm.config.TotalCapital * weights[i]
+		pm.config.StrategyAllocation[id] = weights[i]
+
+		log.Printf("[PortfolioManager] Rebalanced %s: %.2f%% -> %.2f%% (target %.2f%%, %.2f capital)",
+			id, old*100, weights[i]*100, target[i]*100, alloc.AllocatedCapital)
+		if weights[i] != old {
+			pm.resizeStrategyLocked(id)
+		}
 	}
-	if equalWeight > pm.config.MaxAllocation {
-		equalWeight = pm.config.MaxAllocation
+
+	return nil
+}
+
+// allocationInputLocked 按策略 ID 排序，取各策略最近的共同采样窗口
+func (pm *PortfolioManager) allocationInputLocked() *AllocationInput {
+	ids := pm.sortedIDsLocked()
+	n := pm.policyCfg.Lookback
+	for _, id := range ids {
+		n = min(n, len(pm.returns[id]))
+	}
+
+	in := &AllocationInput{
+		StrategyIDs:    ids,
+		Returns:        make([][]float64, len(ids)),
+		Current:        make([]float64, len(ids)),
+		PeriodsPerYear: pm.policyCfg.PeriodsPerYear,
+	}
+	for i, id := range ids {
+		r := pm.returns[id]
+		in.Returns[i] = r[len(r)-n:]
+		in.Current[i] = pm.allocations[id].AllocationPercent
+	}
+	in.Covariance = make([][]float64, len(ids))
+	for i := range ids {
+		in.Covariance[i] = make([]float64, len(ids))
+		for j := range ids {
+			in.Covariance[i][j] = stats.Covariance(in.Returns[i], in.Returns[j])
+		}
 	}
+	return in
+}
 
-	// Update allocations
+func (pm *PortfolioManager) sortedIDsLocked() []string {
+	ids := make([]string, 0, len(pm.strategies))
 	for id := range pm.strategies {
-		alloc := pm.allocations[id]
-		alloc.AllocationPercent = equalWeight
-		alloc.AllocatedCapital = pm.config.TotalCapital * equalWeight
-		pm.config.StrategyAllocation[id] = equalWeight
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SampleReturns records one return sample per strategy: ΔTotalPnL / AllocatedCapital
+// 由 rebalanceLoop 按 SampleIntervalSec 调用；所有策略都未运行时（休市）只更新基准不记录样本
+func (pm *PortfolioManager) SampleReturns() {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 
-		log.Printf("[PortfolioManager] Rebalanced %s: %.2f%% (%.2f capital)",
-			id, equalWeight*100, alloc.AllocatedCapital)
+	running := false
+	for _, s := range pm.strategies {
+		if s.IsRunning() {
+			running = true
+			break
+		}
 	}
 
-	return nil
+	for id, s := range pm.strategies {
+		pnl := s.GetPNL().TotalPnL
+		prev, ok := pm.lastPnL[id]
+		pm.lastPnL[id] = pnl
+		if !ok || !running {
+			continue
+		}
+		r := 0.0
+		if capital := pm.allocations[id].AllocatedCapital; capital > 0 {
+			r = (pnl - prev) / capital
+		}
+		series := append(pm.returns[id], r)
+		if len(series) > pm.policyCfg.Lookback {
+			series = series[len(series)-pm.policyCfg.Lookback:]
+		}
+		pm.returns[id] = series
+	}
 }
 
 // CalculateCorrelation calculates correlation matrix between strategies
+// 基于 SampleReturns 记录的收益率（最近的共同窗口）；样本不足 2 个时非对角线为 0
 func (pm *PortfolioManager) CalculateCorrelation() (*CorrelationMatrix, error) {
-	pm.mu.RLock()
-	defer pm.mu.RUnlock()
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 
 	if !pm.config.EnableCorrelationCalc {
 		return nil, fmt.Errorf("correlation calculation disabled")
@@ -334,37 +444,29 @@ func (pm *PortfolioManager) CalculateCorrelation() (*CorrelationMatrix, error) {
 		return nil, fmt.Errorf("need at least 2 strategies for correlation")
 	}
 
-	// Get strategy IDs
-	ids := make([]string, 0, n)
-	for id := range pm.strategies {
-		ids = append(ids, id)
-	}
-
-	// Initialize correlation matrix
+	in := pm.allocationInputLocked()
+	samples := len(in.Returns[0])
 	matrix := make([][]float64, n)
 	for i := range matrix {
 		matrix[i] = make([]float64, n)
 		// Diagonal is 1.0 (self-correlation)
 		matrix[i][i] = 1.0
 	}
-
-	// Calculate pairwise correlations
-	// Note: This is a simplified implementation
-	// Real implementation would need return time series
-	for i := 0; i < n; i++ {
-		for j := i + 1; j < n; j++ {
-			// Placeholder: random correlation for demo
-			// Real implementation: corr := calculatePearsonCorrelation(returns1, returns2)
-			corr := 0.0 // Simplified: assume uncorrelated
-
-			matrix[i][j] = corr
-			matrix[j][i] = corr
+	if samples >= 2 {
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				corr := stats.Correlation(in.Returns[i], in.Returns[j])
+				matrix[i][j] = corr
+				matrix[j][i] = corr
+			}
 		}
 	}
 
 	correlation := &CorrelationMatrix{
-		StrategyIDs: ids,
+		StrategyIDs: in.StrategyIDs,
 		Matrix:      matrix,
+		Covariance:  in.Covariance,
+		Samples:     samples,
 		Timestamp:   time.Now(),
 	}
 
@@ -379,9 +481,19 @@ func (pm *PortfolioManager) rebalanceLoop() {
 
 	ticker := time.NewTicker(time.Duration(pm.config.RebalanceIntervalSec) * time.Second)
 	defer ticker.Stop()
+	sampler := time.NewTicker(time.Duration(pm.policyCfg.SampleIntervalSec) * time.Second)
+	defer sampler.Stop()
+	stepper := time.NewTicker(time.Duration(pm.sizingCfg.StepIntervalSec) * time.Second)
+	defer stepper.Stop()
 
 	for {
 		select {
+		case <-sampler.C:
+			pm.SampleReturns()
+
+		case <-stepper.C:
+			pm.StepResizes()
+
 		case <-ticker.C:
 			if err := pm.Rebalance(); err != nil {
 				log.Printf("[PortfolioManager] Rebalancing error: %v", err)
@@ -0,0 +1,320 @@
+package portfolio
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// 下单量转换模式
+const (
+	SizingProportional = "proportional" // 按 新分配/基准分配 缩放基准下单量（默认）
+	SizingCapital      = "capital"      // 按分配资金 / 每手保证金计算最大持仓
+)
+
+// SizingConfig 分配资金 → 策略下单量的转换配置（Go 扩展）
+type SizingConfig struct {
+	Mode            string  // proportional / capital
+	MarginUsage     float64 // capital 模式：分配资金中可用作保证金的比例（默认 0.3）
+	ShrinkStep      float64 // 缩小时每步最多减少当前值的比例（默认 0.25，1 表示一步到位）
+	StepIntervalSec int     // 缩小步进间隔（默认 60）
+	ForceFlatten    bool    // 目标手数为 0 时触发平仓；默认只把限额降到 1 手、停止加仓
+	MaxEvents       int     // 保留的调整事件数（默认 200）
+}
+
+func (c SizingConfig) withDefaults() SizingConfig {
+	if c.Mode == "" {
+		c.Mode = SizingProportional
+	}
+	if c.MarginUsage <= 0 {
+		c.MarginUsage = 0.3
+	}
+	if c.ShrinkStep <= 0 {
+		c.ShrinkStep = 0.25
+	}
+	if c.StepIntervalSec <= 0 {
+		c.StepIntervalSec = 60
+	}
+	if c.MaxEvents <= 0 {
+		c.MaxEvents = 200
+	}
+	return c
+}
+
+func (c SizingConfig) validate() error {
+	if c.Mode != SizingProportional && c.Mode != SizingCapital {
+		return fmt.Errorf("unknown sizing mode %q (want %s or %s)", c.Mode, SizingProportional, SizingCapital)
+	}
+	return nil
+}
+
+// ResizeEvent 一次下单量调整（日志 + dashboard）
+type ResizeEvent struct {
+	Time         time.Time
+	StrategyID   string
+	Mode         string
+	Allocation   float64            // 新分配比例
+	Capital      float64            // 新分配资金
+	MarginPerLot float64            // capital 模式：每手（各腿一手）保证金
+	OldMaxPos    int64              // 调整前 MaxPositionSize
+	NewMaxPos    int64              // 本步生效的 MaxPositionSize
+	TargetMaxPos int64              // 最终目标
+	Params       map[string]float64 // 本步下发的下单量参数
+	Pending      bool               // 缩小未完成，后续按 StepIntervalSec 继续
+	Flatten      bool               // 触发了平仓（ForceFlatten）
+	Error        string
+}
+
+// sizeBaseline 策略加入组合时的分配比例、MaxPositionSize 与下单量参数，
+// 以及当前已下发的值和目标值。目标总是由基准重新计算，避免多次缩放累积取整误差
+type sizeBaseline struct {
+	alloc  float64
+	maxPos int64
+	params map[string]float64
+
+	appliedMax int64
+	applied    map[string]float64
+	targetMax  int64
+	target     map[string]float64
+	mode       string
+	pending    bool
+}
+
+func newSizeBaseline(s strategy.Strategy, alloc float64, keys []string) *sizeBaseline {
+	b := &sizeBaseline{alloc: alloc, params: make(map[string]float64)}
+	if cfg := s.GetConfig(); cfg != nil {
+		b.maxPos = cfg.MaxPositionSize
+	}
+	current := s.GetCurrentParameters()
+	for _, k := range keys {
+		if v, ok := toFloat(current[k]); ok && v > 0 {
+			b.params[k] = v
+		}
+	}
+	b.appliedMax = b.maxPos
+	b.applied = make(map[string]float64, len(b.params))
+	for k, v := range b.params {
+		b.applied[k] = v
+	}
+	return b
+}
+
+// limit 基准最大持仓：StrategyConfig.MaxPositionSize，未设置时取 max_position_size 参数
+func (b *sizeBaseline) limit() float64 {
+	if b.maxPos > 0 {
+		return float64(b.maxPos)
+	}
+	return b.params["max_position_size"]
+}
+
+// marginPerLot 各腿各一手的保证金合计，价格取最新成交价（无成交取买卖中间价）
+func marginPerLot(s strategy.Strategy) (float64, bool) {
+	cfg := s.GetConfig()
+	if cfg == nil || len(cfg.Symbols) == 0 {
+		return 0, false
+	}
+	total := 0.0
+	for _, sym := range cfg.Symbols {
+		md := s.GetLastMarketData(sym)
+		if md == nil {
+			return 0, false
+		}
+		price := md.LastPrice
+		if price <= 0 && len(md.BidPrice) > 0 && len(md.AskPrice) > 0 {
+			price = (md.BidPrice[0] + md.AskPrice[0]) / 2
+		}
+		if price <= 0 {
+			return 0, false
+		}
+		total += strategy.MarginPerLot(sym, price)
+	}
+	return total, total > 0
+}
+
+// CapitalToLots 分配资金可支撑的最大手数：capital × usage / 每手保证金（向下取整）
+func CapitalToLots(capital, usage, perLot float64) int64 {
+	if capital <= 0 || usage <= 0 || perLot <= 0 {
+		return 0
+	}
+	return int64(math.Floor(capital * usage / perLot))
+}
+
+// resizeStrategyLocked 按新分配计算目标下单量并下发第一步
+// 扩大立即生效；缩小按 ShrinkStep 逐步降低，剩余部分由 StepResizes 继续
+func (pm *PortfolioManager) resizeStrategyLocked(id string) {
+	b := pm.sizing[id]
+	s := pm.strategies[id]
+	alloc := pm.allocations[id]
+	if b == nil || b.alloc <= 0 || alloc == nil {
+		return
+	}
+	cfg := pm.sizingCfg
+
+	scale := alloc.AllocationPercent / b.alloc
+	mode := SizingProportional
+	perLot := 0.0
+	lots := int64(-1)
+	if cfg.Mode == SizingCapital {
+		if p, ok := marginPerLot(s); ok && b.limit() > 0 {
+			perLot = p
+			lots = CapitalToLots(alloc.AllocatedCapital, cfg.MarginUsage, perLot)
+			scale = float64(lots) / b.limit()
+			mode = SizingCapital
+		} else {
+			log.Printf("[PortfolioManager] Resize %s: no price/limit for capital sizing, falling back to proportional", id)
+		}
+	}
+
+	b.targetMax = 0
+	if b.maxPos > 0 {
+		b.targetMax = int64(math.Max(1, math.Round(float64(b.maxPos)*scale)))
+	}
+	b.target = make(map[string]float64, len(b.params))
+	for k, v := range b.params {
+		b.target[k] = math.Max(1, math.Round(v*scale))
+	}
+
+	b.mode = mode
+	ev := pm.stepResizeLocked(id)
+	if ev == nil {
+		return
+	}
+	ev.MarginPerLot = perLot
+	if lots == 0 && cfg.ForceFlatten {
+		s.TriggerFlatten(strategy.FlattenReasonAllocation, false)
+		ev.Flatten = true
+		log.Printf("[PortfolioManager] Allocation of %s supports 0 lots, flatten triggered", id)
+	}
+}
+
+// stepResizeLocked 向目标移动一步并通过 UpdateParameters 下发，记录调整事件
+// 没有变化时返回 nil
+func (pm *PortfolioManager) stepResizeLocked(id string) *ResizeEvent {
+	b := pm.sizing[id]
+	s := pm.strategies[id]
+	alloc := pm.allocations[id]
+	frac := pm.sizingCfg.ShrinkStep
+
+	newMax := int64(stepToward(float64(b.appliedMax), float64(b.targetMax), frac))
+	params := make(map[string]interface{}, len(b.target))
+	sized := make(map[string]float64, len(b.target))
+	changed := newMax != b.appliedMax
+	for k, t := range b.target {
+		v := stepToward(b.applied[k], t, frac)
+		if k != "max_position_size" && newMax > 0 {
+			v = math.Min(v, float64(newMax)) // 下单量不超过限额
+		}
+		params[k] = v
+		sized[k] = v
+		changed = changed || v != b.applied[k]
+	}
+	if !changed {
+		b.pending = false
+		return nil
+	}
+
+	ev := ResizeEvent{
+		Time:         time.Now(),
+		StrategyID:   id,
+		Mode:         b.mode,
+		Allocation:   alloc.AllocationPercent,
+		Capital:      alloc.AllocatedCapital,
+		OldMaxPos:    b.appliedMax,
+		NewMaxPos:    newMax,
+		TargetMaxPos: b.targetMax,
+		Params:       sized,
+	}
+	if len(params) > 0 {
+		if err := s.UpdateParameters(params); err != nil {
+			// 失败时保持原值，不再重试直到下一次再平衡
+			b.pending = false
+			ev.NewMaxPos = b.appliedMax
+			ev.Error = err.Error()
+			log.Printf("[PortfolioManager] Resize %s failed: %v", id, err)
+			return pm.recordResizeLocked(ev)
+		}
+	}
+	if cfg := s.GetConfig(); cfg != nil && newMax > 0 {
+		cfg.MaxPositionSize = newMax
+	}
+	b.appliedMax = newMax
+	for k, v := range sized {
+		b.applied[k] = v
+	}
+	b.pending = b.appliedMax > b.targetMax
+	for k, t := range b.target {
+		b.pending = b.pending || b.applied[k] > t
+	}
+	ev.Pending = b.pending
+
+	note := ""
+	if ev.Pending {
+		note = ", shrinking gradually"
+	}
+	log.Printf("[PortfolioManager] Resized %s (%s, alloc %.2f%%, capital %.2f): max_position %d -> %d (target %d), params %v%s",
+		id, b.mode, ev.Allocation*100, ev.Capital, ev.OldMaxPos, ev.NewMaxPos, ev.TargetMaxPos, sized, note)
+	return pm.recordResizeLocked(ev)
+}
+
+// stepToward 扩大一步到位；缩小每步最多减少 ceil(cur × frac)
+func stepToward(cur, target, frac float64) float64 {
+	if target >= cur || frac >= 1 {
+		return target
+	}
+	return math.Max(target, cur-math.Ceil(cur*frac))
+}
+
+func (pm *PortfolioManager) recordResizeLocked(ev ResizeEvent) *ResizeEvent {
+	pm.resizeEvents = append(pm.resizeEvents, ev)
+	if n := len(pm.resizeEvents) - pm.sizingCfg.MaxEvents; n > 0 {
+		pm.resizeEvents = pm.resizeEvents[n:]
+	}
+	return &pm.resizeEvents[len(pm.resizeEvents)-1]
+}
+
+// StepResizes 继续未完成的逐步缩小，由 rebalanceLoop 按 StepIntervalSec 调用
+func (pm *PortfolioManager) StepResizes() {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	ids := make([]string, 0)
+	for id, b := range pm.sizing {
+		if b.pending {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		pm.stepResizeLocked(id)
+	}
+}
+
+// GetResizeEvents returns recent resize events, oldest first
+func (pm *PortfolioManager) GetResizeEvents() []ResizeEvent {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	events := make([]ResizeEvent, len(pm.resizeEvents))
+	copy(events, pm.resizeEvents)
+	return events
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	}
+	return 0, false
+}
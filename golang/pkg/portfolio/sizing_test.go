@@ -0,0 +1,142 @@
+package portfolio
+
+import (
+	"testing"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// pricedMock 带行情和平仓记录的 sizedMock
+type pricedMock struct {
+	*sizedMock
+	md      map[string]*mdpb.MarketDataUpdate
+	flatten strategy.FlattenReason
+}
+
+func (m *pricedMock) GetLastMarketData(symbol string) *mdpb.MarketDataUpdate { return m.md[symbol] }
+func (m *pricedMock) TriggerFlatten(reason strategy.FlattenReason, aggressive bool) {
+	m.flatten = reason
+}
+
+func setAllocation(pm *PortfolioManager, id string, weight float64) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.allocations[id].AllocationPercent = weight
+	pm.allocations[id].AllocatedCapital = pm.config.TotalCapital * weight
+	pm.resizeStrategyLocked(id)
+}
+
+func TestCapitalToLots(t *testing.T) {
+	if got := CapitalToLots(500000, 0.3, 28800); got != 5 {
+		t.Errorf("CapitalToLots = %d, want 5", got)
+	}
+	if got := CapitalToLots(500000, 0.3, 0); got != 0 {
+		t.Errorf("CapitalToLots without margin = %d, want 0", got)
+	}
+}
+
+func TestStepToward(t *testing.T) {
+	if v := stepToward(10, 20, 0.25); v != 20 {
+		t.Errorf("grow = %v, want 20", v)
+	}
+	if v := stepToward(10, 5, 0.25); v != 7 {
+		t.Errorf("shrink step = %v, want 7", v)
+	}
+	if v := stepToward(7, 5, 0.25); v != 5 {
+		t.Errorf("last shrink step = %v, want 5", v)
+	}
+	if v := stepToward(10, 5, 1); v != 5 {
+		t.Errorf("immediate shrink = %v, want 5", v)
+	}
+}
+
+func TestPortfolioManager_CapitalSizing(t *testing.T) {
+	pm := NewPortfolioManager(&PortfolioConfig{
+		TotalCapital:  1000000,
+		MinAllocation: 0.01,
+		MaxAllocation: 0.9,
+		Sizing:        SizingConfig{Mode: SizingCapital, ForceFlatten: true},
+	})
+	if err := pm.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &pricedMock{
+		sizedMock: &sizedMock{MockStrategy: NewMockStrategy("ag_spread"),
+			cfg:    &strategy.StrategyConfig{Symbols: []string{"ag2603", "ag2605"}, MaxPositionSize: 10},
+			params: map[string]interface{}{"order_size": 2.0, "max_position_size": 10.0}},
+		md: map[string]*mdpb.MarketDataUpdate{
+			"ag2603": {LastPrice: 8000},
+			"ag2605": {BidPrice: []float64{7999}, AskPrice: []float64{8001}},
+		},
+	}
+	if err := pm.AddStrategy(s, 0.9); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每手保证金 2 × 8000 × 15 × 0.12 = 28800；500000 × 0.3 / 28800 → 5 手
+	setAllocation(pm, "ag_spread", 0.5)
+	if s.cfg.MaxPositionSize != 7 || s.params["order_size"] != 1.0 {
+		t.Fatalf("first shrink step: max=%d params=%v, want 7 / order 1", s.cfg.MaxPositionSize, s.params)
+	}
+	pm.StepResizes()
+	if s.cfg.MaxPositionSize != 5 || s.params["max_position_size"] != 5.0 {
+		t.Fatalf("second shrink step: max=%d params=%v, want 5", s.cfg.MaxPositionSize, s.params)
+	}
+	pm.StepResizes() // 已到目标，不再产生事件
+
+	events := pm.GetResizeEvents()
+	if len(events) != 2 || !events[0].Pending || events[1].Pending {
+		t.Fatalf("events = %+v, want 2 (pending, done)", events)
+	}
+	if events[0].Mode != SizingCapital || events[0].MarginPerLot != 28800 || events[0].TargetMaxPos != 5 {
+		t.Errorf("event = %+v", events[0])
+	}
+
+	// 扩大一步到位：900000 × 0.3 / 28800 → 9 手
+	setAllocation(pm, "ag_spread", 0.9)
+	if s.cfg.MaxPositionSize != 9 || s.params["order_size"] != 2.0 {
+		t.Errorf("grow: max=%d params=%v, want 9 / order 2", s.cfg.MaxPositionSize, s.params)
+	}
+
+	// 资金不足一手：限额降到 1 手，配置了 ForceFlatten 才平仓
+	setAllocation(pm, "ag_spread", 0.01)
+	if s.flatten != strategy.FlattenReasonAllocation {
+		t.Errorf("flatten reason = %v, want Allocation", s.flatten)
+	}
+	if ev := pm.GetResizeEvents(); !ev[len(ev)-1].Flatten || ev[len(ev)-1].TargetMaxPos != 1 {
+		t.Errorf("last event = %+v", ev[len(ev)-1])
+	}
+}
+
+func TestPortfolioManager_CapitalSizingFallsBackWithoutPrice(t *testing.T) {
+	pm := NewPortfolioManager(&PortfolioConfig{
+		TotalCapital:  1000000,
+		MinAllocation: 0.01,
+		MaxAllocation: 0.9,
+		Sizing:        SizingConfig{Mode: SizingCapital, ShrinkStep: 1},
+	})
+	if err := pm.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	s := &sizedMock{MockStrategy: NewMockStrategy("no_md"),
+		cfg:    &strategy.StrategyConfig{Symbols: []string{"ag2603"}, MaxPositionSize: 10},
+		params: map[string]interface{}{"order_size": 4.0}}
+	pm.AddStrategy(s, 0.5)
+
+	setAllocation(pm, "no_md", 0.25)
+	if s.cfg.MaxPositionSize != 5 || s.params["order_size"] != 2.0 {
+		t.Errorf("proportional fallback: max=%d params=%v, want 5 / order 2", s.cfg.MaxPositionSize, s.params)
+	}
+	if ev := pm.GetResizeEvents(); len(ev) != 1 || ev[0].Mode != SizingProportional {
+		t.Errorf("events = %+v", ev)
+	}
+}
+
+func TestSizingConfig_Validate(t *testing.T) {
+	pm := NewPortfolioManager(&PortfolioConfig{TotalCapital: 1, Sizing: SizingConfig{Mode: "notional"}})
+	if err := pm.Initialize(); err == nil {
+		t.Error("unknown sizing mode should fail")
+	}
+}
@@ -0,0 +1,181 @@
+// Package reconcile provides the fill journal, fill-derived position ledger and
+// three-way position reconciliation (strategy vs ledger vs counter)
+// 对账：按当日成交流水重建持仓，与策略持仓、柜台持仓三方比对并分类差异
+package reconcile
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// Fill 成交流水记录（每条成交回报一行，不去重；去重在重建台账时进行）
+type Fill struct {
+	TradingDay string    `json:"trading_day"`
+	Time       time.Time `json:"time"`
+	OrderID    string    `json:"order_id"`
+	ExecID     string    `json:"exec_id,omitempty"`
+	StrategyID string    `json:"strategy_id"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`             // BUY / SELL
+	Offset     string    `json:"offset,omitempty"` // OPEN / CLOSE / CLOSE_TODAY / CLOSE_YESTERDAY，空 = 未知（按先平后开推断）
+	Qty        int64     `json:"qty"`              // 本次成交量（LastFillQty）
+	FilledQty  int64     `json:"filled_qty"`       // 订单累计成交量
+	Price      float64   `json:"price"`
+	Source     string    `json:"source,omitempty"` // 空 = 成交回报；reconcile = 对账调整
+}
+
+// FillFromUpdate 由订单回报构造成交记录
+func FillFromUpdate(update *orspb.OrderUpdate, offset string) Fill {
+	side := "BUY"
+	if update.Side == orspb.OrderSide_SELL {
+		side = "SELL"
+	}
+	price := update.LastFillPrice
+	if price == 0 {
+		price = update.AvgPrice
+	}
+	return Fill{
+		Time:       time.Now(),
+		OrderID:    update.OrderId,
+		ExecID:     update.ExecId,
+		StrategyID: update.StrategyId,
+		Symbol:     update.Symbol,
+		Side:       side,
+		Offset:     offset,
+		Qty:        update.LastFillQty,
+		FilledQty:  update.FilledQty,
+		Price:      price,
+	}
+}
+
+// Journal 当日成交流水（JSONL，每个交易日一个文件：<dir>/fills.<YYYYMMDD>.jsonl）
+type Journal struct {
+	dir string
+	day string
+	f   *os.File
+	mu  sync.Mutex
+}
+
+// NewJournal 创建成交流水，day 为当前交易日（YYYYMMDD）
+func NewJournal(dir, day string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create fill journal dir: %w", err)
+	}
+	j := &Journal{dir: dir}
+	if err := j.SetTradingDay(day); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Dir 返回流水目录
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// TradingDay 返回当前交易日
+func (j *Journal) TradingDay() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.day
+}
+
+// SetTradingDay 切换到新交易日的流水文件
+func (j *Journal) SetTradingDay(day string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f != nil && j.day == day {
+		return nil
+	}
+	f, err := os.OpenFile(j.path(day), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open fill journal: %w", err)
+	}
+	if j.f != nil {
+		j.f.Close()
+	}
+	j.f = f
+	j.day = day
+	return nil
+}
+
+// Append 追加一条成交记录（TradingDay 为空时取当前交易日）
+func (j *Journal) Append(fill Fill) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return errors.New("fill journal closed")
+	}
+	if fill.TradingDay == "" {
+		fill.TradingDay = j.day
+	}
+	data, err := json.Marshal(fill)
+	if err != nil {
+		return err
+	}
+	_, err = j.f.Write(append(data, '\n'))
+	return err
+}
+
+// RecordFill 记录成交回报（实现 strategy.FillRecorder）
+func (j *Journal) RecordFill(update *orspb.OrderUpdate, offset string) error {
+	return j.Append(FillFromUpdate(update, offset))
+}
+
+// Load 读取某交易日的全部成交记录
+func (j *Journal) Load(day string) ([]Fill, error) {
+	return LoadFills(j.path(day))
+}
+
+// Close 关闭流水文件
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+func (j *Journal) path(day string) string {
+	return filepath.Join(j.dir, fmt.Sprintf("fills.%s.jsonl", day))
+}
+
+// LoadFills 读取成交流水文件，文件不存在时返回空
+func LoadFills(path string) ([]Fill, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var fills []Fill
+	sc := bufio.NewScanner(f)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var fill Fill
+		if err := json.Unmarshal(sc.Bytes(), &fill); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		fills = append(fills, fill)
+	}
+	return fills, sc.Err()
+}
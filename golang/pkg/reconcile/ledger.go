@@ -0,0 +1,149 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Split 单合约多空今昨持仓
+type Split struct {
+	LongTd  int64 `json:"long_td"`
+	LongYd  int64 `json:"long_yd"`
+	ShortTd int64 `json:"short_td"`
+	ShortYd int64 `json:"short_yd"`
+}
+
+// Net 净持仓（多 - 空）
+func (s Split) Net() int64 {
+	return s.LongTd + s.LongYd - s.ShortTd - s.ShortYd
+}
+
+// Rolled 交易日切换后的持仓：今仓转昨仓
+func (s Split) Rolled() Split {
+	return Split{LongYd: s.LongTd + s.LongYd, ShortYd: s.ShortTd + s.ShortYd}
+}
+
+// apply 按开平标志计入一笔成交
+// 未知开平（含 CLOSE）按柜台先平昨、再平今、剩余开仓处理
+func (s *Split) apply(side, offset string, qty int64) {
+	long := side == "BUY"
+	openTd, closeTd, closeYd := &s.LongTd, &s.ShortTd, &s.ShortYd
+	if !long {
+		openTd, closeTd, closeYd = &s.ShortTd, &s.LongTd, &s.LongYd
+	}
+
+	switch offset {
+	case "OPEN":
+		*openTd += qty
+	case "CLOSE_TODAY":
+		*closeTd -= qty
+	case "CLOSE_YESTERDAY":
+		*closeYd -= qty
+	default:
+		n := min(qty, max(*closeYd, 0))
+		*closeYd -= n
+		qty -= n
+		n = min(qty, max(*closeTd, 0))
+		*closeTd -= n
+		qty -= n
+		*openTd += qty
+	}
+}
+
+// Ledger 由昨仓基线 + 当日成交流水重建的持仓台账
+type Ledger struct {
+	Positions  map[string]Split `json:"positions"`
+	Fills      int              `json:"fills"`      // 有效成交记录数
+	Duplicates int              `json:"duplicates"` // 重复成交回报数
+	// DuplicateQty 按品种的重复成交净数量（买为正），策略若重复计入会恰好偏离该值
+	DuplicateQty map[string]int64 `json:"duplicate_qty"`
+}
+
+// BuildLedger 重建持仓台账
+// 去重：有 ExecID 时按 ExecID，否则按 (OrderID, 累计成交量)；无 LastFillQty 时用累计成交量差值
+func BuildLedger(base map[string]Split, fills []Fill) *Ledger {
+	l := &Ledger{
+		Positions:    make(map[string]Split, len(base)),
+		DuplicateQty: make(map[string]int64),
+	}
+	for sym, s := range base {
+		l.Positions[sym] = s
+	}
+
+	seen := make(map[string]bool)
+	lastFilled := make(map[string]int64) // orderID → 已计入的累计成交量
+	for _, f := range fills {
+		key := f.ExecID
+		if key == "" {
+			key = fmt.Sprintf("%s/%d", f.OrderID, f.FilledQty)
+		}
+		if f.Source == "" && seen[key] {
+			l.Duplicates++
+			if f.Side == "BUY" {
+				l.DuplicateQty[f.Symbol] += f.Qty
+			} else {
+				l.DuplicateQty[f.Symbol] -= f.Qty
+			}
+			continue
+		}
+		seen[key] = true
+
+		qty := f.Qty
+		if qty <= 0 && f.FilledQty > 0 {
+			qty = f.FilledQty - lastFilled[f.OrderID]
+		}
+		if f.FilledQty > lastFilled[f.OrderID] {
+			lastFilled[f.OrderID] = f.FilledQty
+		}
+		if qty == 0 {
+			continue
+		}
+
+		s := l.Positions[f.Symbol]
+		s.apply(f.Side, f.Offset, qty)
+		l.Positions[f.Symbol] = s
+		l.Fills++
+	}
+	return l
+}
+
+// baselinePath 昨仓基线文件：<dir>/baseline.<YYYYMMDD>.json
+func baselinePath(dir, day string) string {
+	return filepath.Join(dir, fmt.Sprintf("baseline.%s.json", day))
+}
+
+// LoadBaseline 读取交易日的昨仓基线，不存在时返回 nil
+func LoadBaseline(dir, day string) (map[string]Split, error) {
+	data, err := os.ReadFile(baselinePath(dir, day))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var base map[string]Split
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", day, err)
+	}
+	return base, nil
+}
+
+// SaveBaseline 保存交易日的昨仓基线（同一交易日重启时复用，避免用盘中持仓作基线）
+func SaveBaseline(dir, day string, base map[string]Split) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := baselinePath(dir, day) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, baselinePath(dir, day))
+}
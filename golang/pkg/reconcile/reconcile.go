@@ -0,0 +1,206 @@
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kind 差异类型
+type Kind string
+
+const (
+	// MissedFill 台账与柜台一致，策略持仓偏离（策略漏处理成交回报）
+	MissedFill Kind = "missed_fill"
+	// DuplicateFill 台账与柜台一致，策略偏离量恰为重复成交回报量（策略重复计入）
+	DuplicateFill Kind = "duplicate_fill"
+	// ManualTrade 策略与台账一致，柜台偏离（成交未经本系统：手工单或成交回报丢失）
+	ManualTrade Kind = "manual_trade"
+	// YesterdaySplit 净持仓三方一致，但台账与柜台的今/昨拆分不一致（影响 SHFE 平今/平昨）
+	YesterdaySplit Kind = "yesterday_split"
+	// Unexplained 三方两两不一致
+	Unexplained Kind = "unexplained"
+)
+
+// Kinds 全部差异类型
+var Kinds = []Kind{MissedFill, DuplicateFill, ManualTrade, YesterdaySplit, Unexplained}
+
+// Action 差异处理策略
+type Action string
+
+const (
+	ActionAlert    Action = "alert"     // 仅记录/告警
+	ActionAutoHeal Action = "auto_heal" // 自动修复（以台账/柜台为准修正策略持仓或台账）
+	ActionFreeze   Action = "freeze"    // 停用相关策略，等待人工处理
+)
+
+// ParseAction 解析处理策略
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case ActionAlert, ActionAutoHeal, ActionFreeze:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown reconcile action %q", s)
+}
+
+// Policy 差异类型 → 处理策略
+type Policy map[Kind]Action
+
+// DefaultPolicy 默认处理策略：可确定来源的差异自动修复，其余停用策略
+func DefaultPolicy() Policy {
+	return Policy{
+		MissedFill:     ActionAutoHeal,
+		DuplicateFill:  ActionAutoHeal,
+		ManualTrade:    ActionFreeze,
+		YesterdaySplit: ActionAutoHeal,
+		Unexplained:    ActionFreeze,
+	}
+}
+
+// ActionFor 返回差异类型的处理策略（未配置时为 alert）
+func (p Policy) ActionFor(k Kind) Action {
+	if a, ok := p[k]; ok {
+		return a
+	}
+	return ActionAlert
+}
+
+// Input 对账输入
+type Input struct {
+	TradingDay string
+	Strategy   map[string]int64    // 品种 → 策略净持仓（所有策略合计）
+	Owners     map[string][]string // 品种 → 持有/交易该品种的策略 ID（无策略的品种只比较台账与柜台）
+	Ledger     *Ledger
+	Counter    map[string]Split // 品种 → 柜台持仓
+	Policy     Policy
+}
+
+// Row 单品种三方持仓
+type Row struct {
+	Symbol       string   `json:"symbol"`
+	Strategy     int64    `json:"strategy"`
+	Ledger       int64    `json:"ledger"`
+	Counter      int64    `json:"counter"`
+	LedgerSplit  Split    `json:"ledger_split"`
+	CounterSplit Split    `json:"counter_split"`
+	Owners       []string `json:"owners,omitempty"`
+}
+
+// Discrepancy 单品种差异
+type Discrepancy struct {
+	Row
+	Kind   Kind   `json:"kind"`
+	Action Action `json:"action"`
+	Detail string `json:"detail"`
+	Healed bool   `json:"healed"`
+	Frozen bool   `json:"frozen"`
+}
+
+// Report 对账报告
+type Report struct {
+	TradingDay    string        `json:"trading_day"`
+	Time          time.Time     `json:"time"`
+	Fills         int           `json:"fills"`
+	Duplicates    int           `json:"duplicates"`
+	Rows          []Row         `json:"rows"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// OK 无差异
+func (r *Report) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Reconcile 三方对账并按策略标注处理方式（不执行处理）
+func Reconcile(in Input) *Report {
+	policy := in.Policy
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	ledger := in.Ledger
+	if ledger == nil {
+		ledger = BuildLedger(nil, nil)
+	}
+
+	symbols := make(map[string]bool)
+	for s := range in.Strategy {
+		symbols[s] = true
+	}
+	for s := range ledger.Positions {
+		symbols[s] = true
+	}
+	for s := range in.Counter {
+		symbols[s] = true
+	}
+	sorted := make([]string, 0, len(symbols))
+	for s := range symbols {
+		sorted = append(sorted, s)
+	}
+	sort.Strings(sorted)
+
+	rep := &Report{
+		TradingDay:    in.TradingDay,
+		Time:          time.Now(),
+		Fills:         ledger.Fills,
+		Duplicates:    ledger.Duplicates,
+		Discrepancies: []Discrepancy{},
+	}
+	for _, sym := range sorted {
+		row := Row{
+			Symbol:       sym,
+			Strategy:     in.Strategy[sym],
+			LedgerSplit:  ledger.Positions[sym],
+			CounterSplit: in.Counter[sym],
+			Owners:       in.Owners[sym],
+		}
+		row.Ledger = row.LedgerSplit.Net()
+		row.Counter = row.CounterSplit.Net()
+		rep.Rows = append(rep.Rows, row)
+
+		// 无策略持有的品种不比较策略侧（如账户里的手工持仓），只比较台账与柜台
+		cmp := row
+		if len(row.Owners) == 0 {
+			cmp.Strategy = row.Ledger
+		}
+		kind, detail, ok := classify(cmp, ledger.DuplicateQty[sym])
+		if ok {
+			continue
+		}
+		rep.Discrepancies = append(rep.Discrepancies, Discrepancy{
+			Row:    row,
+			Kind:   kind,
+			Action: policy.ActionFor(kind),
+			Detail: detail,
+		})
+	}
+	return rep
+}
+
+// classify 差异分类，ok = 无差异
+func classify(r Row, dupQty int64) (Kind, string, bool) {
+	s, l, c := r.Strategy, r.Ledger, r.Counter
+	switch {
+	case s == l && l == c:
+		if splitKnown(r.CounterSplit) && r.CounterSplit != r.LedgerSplit {
+			return YesterdaySplit, fmt.Sprintf("ledger td/yd long %d/%d short %d/%d, counter long %d/%d short %d/%d",
+				r.LedgerSplit.LongTd, r.LedgerSplit.LongYd, r.LedgerSplit.ShortTd, r.LedgerSplit.ShortYd,
+				r.CounterSplit.LongTd, r.CounterSplit.LongYd, r.CounterSplit.ShortTd, r.CounterSplit.ShortYd), false
+		}
+		return "", "", true
+	case l == c:
+		if dupQty != 0 && s-l == dupQty {
+			return DuplicateFill, fmt.Sprintf("strategy off by %d, equals duplicated fill reports", s-l), false
+		}
+		return MissedFill, fmt.Sprintf("strategy off by %d vs ledger/counter", s-l), false
+	case s == l:
+		return ManualTrade, fmt.Sprintf("counter off by %d vs strategy/ledger", c-l), false
+	default:
+		return Unexplained, fmt.Sprintf("strategy=%d ledger=%d counter=%d", s, l, c), false
+	}
+}
+
+// splitKnown 柜台是否提供了今昨拆分（全零视为未提供）
+func splitKnown(s Split) bool {
+	return s != Split{}
+}
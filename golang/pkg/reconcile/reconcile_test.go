@@ -0,0 +1,146 @@
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildLedger_DedupAndOffsets(t *testing.T) {
+	base := map[string]Split{"ag2506": {LongYd: 3}}
+	fills := []Fill{
+		{OrderID: "1", Symbol: "ag2506", Side: "SELL", Offset: "CLOSE_YESTERDAY", Qty: 2, FilledQty: 2},
+		{OrderID: "1", Symbol: "ag2506", Side: "SELL", Offset: "CLOSE_YESTERDAY", Qty: 2, FilledQty: 2}, // 重复回报
+		{OrderID: "2", Symbol: "ag2506", Side: "BUY", Offset: "OPEN", Qty: 1, FilledQty: 1},
+		// 未知开平、无 LastFillQty：按累计量差值，先平昨再平今，剩余开仓
+		{OrderID: "3", Symbol: "ag2506", Side: "SELL", FilledQty: 1},
+		{OrderID: "3", Symbol: "ag2506", Side: "SELL", FilledQty: 4},
+	}
+
+	l := BuildLedger(base, fills)
+	if l.Duplicates != 1 {
+		t.Errorf("Duplicates = %d, want 1", l.Duplicates)
+	}
+	if got := l.DuplicateQty["ag2506"]; got != -2 {
+		t.Errorf("DuplicateQty = %d, want -2", got)
+	}
+	want := Split{ShortTd: 2}
+	if got := l.Positions["ag2506"]; got != want {
+		t.Errorf("ledger = %+v, want %+v", got, want)
+	}
+	if base["ag2506"].LongYd != 3 {
+		t.Error("BuildLedger modified base")
+	}
+}
+
+func TestReconcile_Classify(t *testing.T) {
+	ledger := BuildLedger(map[string]Split{
+		"ag2506": {LongYd: 2},
+		"cu2505": {LongYd: 1},
+		"rb2505": {ShortTd: 1},
+		"au2506": {LongYd: 1},
+	}, []Fill{
+		{OrderID: "9", Symbol: "rb2505", Side: "SELL", Offset: "OPEN", Qty: 1, FilledQty: 1},
+		{OrderID: "9", Symbol: "rb2505", Side: "SELL", Offset: "OPEN", Qty: 1, FilledQty: 1},
+	})
+
+	rep := Reconcile(Input{
+		TradingDay: "20250103",
+		Strategy: map[string]int64{
+			"ag2506": 1,  // 漏成交
+			"cu2505": 1,  // 柜台多出 → 手工单
+			"rb2505": -3, // 重复计入
+			"au2506": 1,  // 今昨拆分不一致
+			"ni2505": 2,  // 三方都不一致
+		},
+		Owners: map[string][]string{
+			"ag2506": {"s1"}, "cu2505": {"s1"}, "rb2505": {"s2"}, "au2506": {"s2"}, "ni2505": {"s1", "s2"},
+		},
+		Ledger: ledger,
+		Counter: map[string]Split{
+			"ag2506": {LongYd: 2},
+			"cu2505": {LongYd: 1, LongTd: 1},
+			"rb2505": {ShortTd: 2},
+			"au2506": {LongTd: 1},
+			"ni2505": {LongTd: 1},
+			"zn2505": {},
+			"al2505": {ShortYd: 2}, // 无策略持有：台账为 0 → 手工单
+		},
+	})
+
+	got := make(map[string]Discrepancy)
+	for _, d := range rep.Discrepancies {
+		got[d.Symbol] = d
+	}
+	want := map[string]struct {
+		kind   Kind
+		action Action
+	}{
+		"ag2506": {MissedFill, ActionAutoHeal},
+		"cu2505": {ManualTrade, ActionFreeze},
+		"rb2505": {DuplicateFill, ActionAutoHeal},
+		"au2506": {YesterdaySplit, ActionAutoHeal},
+		"ni2505": {Unexplained, ActionFreeze},
+		"al2505": {ManualTrade, ActionFreeze},
+	}
+	if len(got) != len(want) {
+		t.Errorf("discrepancies = %d, want %d", len(got), len(want))
+	}
+	for sym, w := range want {
+		d, ok := got[sym]
+		if !ok {
+			t.Errorf("%s: no discrepancy, want %s", sym, w.kind)
+			continue
+		}
+		if d.Kind != w.kind || d.Action != w.action {
+			t.Errorf("%s: %s/%s, want %s/%s", sym, d.Kind, d.Action, w.kind, w.action)
+		}
+	}
+	if len(rep.Rows) != 7 {
+		t.Errorf("rows = %d, want 7", len(rep.Rows))
+	}
+}
+
+func TestJournalAndReport(t *testing.T) {
+	dir := t.TempDir()
+	j, err := NewJournal(dir, "20250103")
+	if err != nil {
+		t.Fatalf("NewJournal: %v", err)
+	}
+	if err := j.Append(Fill{OrderID: "1", Symbol: "ag2506", Side: "BUY", Qty: 1, FilledQty: 1}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := j.SetTradingDay("20250106"); err != nil {
+		t.Fatalf("SetTradingDay: %v", err)
+	}
+	j.Append(Fill{OrderID: "2", Symbol: "ag2506", Side: "SELL", Qty: 1, FilledQty: 1})
+	j.Close()
+
+	fills, err := j.Load("20250103")
+	if err != nil || len(fills) != 1 || fills[0].TradingDay != "20250103" {
+		t.Fatalf("Load = %+v, %v, want 1 fill on 20250103", fills, err)
+	}
+
+	base := map[string]Split{"ag2506": {LongYd: 1}}
+	if err := SaveBaseline(dir, "20250106", base); err != nil {
+		t.Fatalf("SaveBaseline: %v", err)
+	}
+	loaded, err := LoadBaseline(dir, "20250106")
+	if err != nil || loaded["ag2506"] != base["ag2506"] {
+		t.Errorf("LoadBaseline = %+v, %v, want %+v", loaded, err, base)
+	}
+	if missing, err := LoadBaseline(dir, "20250107"); missing != nil || err != nil {
+		t.Errorf("LoadBaseline(missing) = %+v, %v, want nil, nil", missing, err)
+	}
+
+	rep := Reconcile(Input{TradingDay: "20250106"})
+	path, err := WriteReport(dir, rep)
+	if err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	for _, p := range []string{path, filepath.Join(dir, "reconcile.latest.json")} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("report %s: %v", p, err)
+		}
+	}
+}
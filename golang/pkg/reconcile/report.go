@@ -0,0 +1,31 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteReport 写出对账报告：<dir>/reconcile.<day>.<HHMMSS>.json，并覆盖 reconcile.latest.json
+// 返回带时间戳的报告路径
+func WriteReport(dir string, rep *Report) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create report dir: %w", err)
+	}
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("reconcile.%s.%s.json", rep.TradingDay, rep.Time.Format("150405")))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	latest := filepath.Join(dir, "reconcile.latest.json")
+	tmp := latest + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return path, err
+	}
+	return path, os.Rename(tmp, latest)
+}
@@ -580,6 +580,18 @@ func (rm *RiskManager) ResetEmergencyStop() {
 	log.Println("[RiskManager] Emergency stop reset")
 }
 
+// ResetDaily resets daily statistics at trading-day close
+// 交易日切换时调用；不清除紧急停止状态（需人工 ResetEmergencyStop）
+func (rm *RiskManager) ResetDaily() {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.globalStats.DailyPnL = 0
+	rm.globalStats.OrderCount = 0
+	rm.globalStats.LastResetTime = time.Now()
+	log.Println("[RiskManager] Daily statistics reset")
+}
+
 // UpdateLimit updates a risk limit
 func (rm *RiskManager) UpdateLimit(limitID string, value float64, enabled bool) error {
 	rm.mu.Lock()
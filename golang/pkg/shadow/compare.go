@@ -0,0 +1,591 @@
+package shadow
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind 差异类型
+type Kind string
+
+const (
+	// OrderLiveOnly 实盘下单，影子在对齐窗口内没有同品种同方向的委托
+	OrderLiveOnly Kind = "order_live_only"
+	// OrderShadowOnly 影子下单，实盘在对齐窗口内没有同品种同方向的委托
+	OrderShadowOnly Kind = "order_shadow_only"
+	// OrderMismatch 双方在窗口内同品种同方向下单，但价格或数量不一致
+	OrderMismatch Kind = "order_mismatch"
+	// FillLiveOnly 实盘成交，回测撮合在窗口内未成交（影子漏成交）
+	FillLiveOnly Kind = "fill_live_only"
+	// FillShadowOnly 回测撮合成交，实盘在窗口内未成交（实盘漏成交）
+	FillShadowOnly Kind = "fill_shadow_only"
+	// PositionMismatch 持仓不一致持续超过容忍时长
+	PositionMismatch Kind = "position_mismatch"
+	// SignalMismatch 同一笔行情处理后的信号值（z_score）差异超过阈值
+	SignalMismatch Kind = "signal_mismatch"
+)
+
+// Kinds 全部差异类型（报告按此顺序输出）
+var Kinds = []Kind{OrderLiveOnly, OrderShadowOnly, OrderMismatch, FillLiveOnly, FillShadowOnly, PositionMismatch, SignalMismatch}
+
+// Source 事件来源
+type Source int
+
+const (
+	Live Source = iota
+	Shadow
+)
+
+func (s Source) String() string {
+	if s == Live {
+		return "live"
+	}
+	return "shadow"
+}
+
+// Order 一笔委托决策或成交（成交时 Price 为成交价、Qty 为本次成交量）
+type Order struct {
+	Time    time.Time
+	Symbol  string
+	Buy     bool
+	Price   float64
+	Qty     int64
+	OrderID string
+}
+
+func (o *Order) String() string {
+	side := "SELL"
+	if o.Buy {
+		side = "BUY"
+	}
+	return fmt.Sprintf("%s %d@%g", side, o.Qty, o.Price)
+}
+
+// Divergence 单条差异（附发生时的盘口和对比双方的取值）
+type Divergence struct {
+	Time       time.Time              `json:"time"`
+	StrategyID string                 `json:"strategy_id"`
+	Kind       Kind                   `json:"kind"`
+	Symbol     string                 `json:"symbol,omitempty"`
+	Live       string                 `json:"live,omitempty"`
+	Shadow     string                 `json:"shadow,omitempty"`
+	Context    map[string]interface{} `json:"context,omitempty"`
+}
+
+// StrategyStats 单策略当日对比统计
+type StrategyStats struct {
+	LiveOrders       int     `json:"live_orders"`
+	ShadowOrders     int     `json:"shadow_orders"`
+	MatchedOrders    int     `json:"matched_orders"`
+	LiveFillQty      int64   `json:"live_fill_qty"`
+	ShadowFillQty    int64   `json:"shadow_fill_qty"`
+	MatchedFillQty   int64   `json:"matched_fill_qty"`
+	FillPriceDiff    float64 `json:"fill_price_diff"` // 已对齐成交的实盘相对影子成交价差（按量加权，正 = 实盘更差）
+	SignalChecks     int     `json:"signal_checks"`
+	SignalMismatches int     `json:"signal_mismatches"`
+	MaxSignalDiff    float64 `json:"max_signal_diff"`
+	Divergences      int     `json:"divergences"`
+
+	fillDiffSum float64
+}
+
+// Report 交易日对比报告
+type Report struct {
+	TradingDay  string                    `json:"trading_day"`
+	Start       time.Time                 `json:"start"`
+	End         time.Time                 `json:"end"`
+	Strategies  map[string]*StrategyStats `json:"strategies"`
+	Counts      map[Kind]int              `json:"counts"`
+	Divergences []Divergence              `json:"divergences"`
+	Truncated   int                       `json:"truncated,omitempty"`      // 超过明细上限未保留的差异条数
+	Dropped     int64                     `json:"dropped_events,omitempty"` // 队列满丢弃的实盘事件数（丢弃期间的差异不可信）
+}
+
+// Total 差异总数
+func (r *Report) Total() int {
+	n := 0
+	for _, c := range r.Counts {
+		n += c
+	}
+	return n
+}
+
+// Options 对比参数
+type Options struct {
+	DecisionWindow  time.Duration // 委托对齐窗口，默认 2s
+	FillWindow      time.Duration // 成交对齐窗口，默认 5s
+	PositionGrace   time.Duration // 持仓不一致容忍时长，默认 5s
+	PriceTolerance  float64       // 委托价允许差异
+	SignalTolerance float64       // 信号值允许差异，默认 0.1
+	MaxDivergences  int           // 报告保留的差异明细上限，默认 10000
+	Logf            func(format string, args ...interface{})
+}
+
+func (o *Options) setDefaults() {
+	if o.DecisionWindow <= 0 {
+		o.DecisionWindow = 2 * time.Second
+	}
+	if o.FillWindow <= 0 {
+		o.FillWindow = 5 * time.Second
+	}
+	if o.PositionGrace <= 0 {
+		o.PositionGrace = 5 * time.Second
+	}
+	if o.SignalTolerance <= 0 {
+		o.SignalTolerance = 0.1
+	}
+	if o.MaxDivergences <= 0 {
+		o.MaxDivergences = 10000
+	}
+	if o.Logf == nil {
+		o.Logf = log.Printf
+	}
+}
+
+type pendingFill struct {
+	Order
+	remaining int64
+}
+
+type pendingSignal struct {
+	t     time.Time
+	src   Source
+	value float64
+}
+
+// book 单策略的待对齐事件
+type book struct {
+	orders  [2][]*Order
+	fills   [2][]*pendingFill
+	signals map[interface{}]*pendingSignal
+
+	signalDiverged bool
+	posSince       time.Time
+	posReported    bool
+}
+
+type quote struct {
+	bid, ask float64
+}
+
+// Comparator 对齐实盘与影子事件并记录差异
+// 时间一律使用行情时间：双方由同一笔行情驱动，对齐不受处理延迟影响
+type Comparator struct {
+	mu     sync.Mutex
+	opts   Options
+	report *Report
+	books  map[string]*book
+	quotes map[string]quote
+}
+
+// NewComparator 创建对比器，day 为当前交易日
+func NewComparator(opts Options, day string) *Comparator {
+	opts.setDefaults()
+	return &Comparator{
+		opts:   opts,
+		report: newReport(day),
+		books:  make(map[string]*book),
+		quotes: make(map[string]quote),
+	}
+}
+
+func newReport(day string) *Report {
+	return &Report{
+		TradingDay: day,
+		Start:      time.Now(),
+		Strategies: make(map[string]*StrategyStats),
+		Counts:     make(map[Kind]int),
+	}
+}
+
+func (c *Comparator) book(id string) *book {
+	b, ok := c.books[id]
+	if !ok {
+		b = &book{signals: make(map[interface{}]*pendingSignal)}
+		c.books[id] = b
+	}
+	return b
+}
+
+func (c *Comparator) stats(id string) *StrategyStats {
+	st, ok := c.report.Strategies[id]
+	if !ok {
+		st = &StrategyStats{}
+		c.report.Strategies[id] = st
+	}
+	return st
+}
+
+// OnQuote 记录最新盘口（差异上下文）
+func (c *Comparator) OnQuote(symbol string, bid, ask float64) {
+	c.mu.Lock()
+	c.quotes[symbol] = quote{bid: bid, ask: ask}
+	c.mu.Unlock()
+}
+
+// OnOrder 记录一笔委托决策，与对方窗口内同品种同方向的委托对齐
+// 价格和数量一致的优先；只有不一致的候选时记 OrderMismatch
+func (c *Comparator) OnOrder(src Source, id string, o Order) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := c.stats(id)
+	if src == Live {
+		st.LiveOrders++
+	} else {
+		st.ShadowOrders++
+	}
+
+	b := c.book(id)
+	other := b.orders[1-src]
+	cand, exact := -1, false
+	for i, p := range other {
+		if p.Symbol != o.Symbol || p.Buy != o.Buy || absDuration(p.Time.Sub(o.Time)) > c.opts.DecisionWindow {
+			continue
+		}
+		if p.Qty == o.Qty && math.Abs(p.Price-o.Price) <= c.opts.PriceTolerance+1e-9 {
+			cand, exact = i, true
+			break
+		}
+		if cand < 0 {
+			cand = i
+		}
+	}
+	if cand < 0 {
+		b.orders[src] = append(b.orders[src], &o)
+		return
+	}
+
+	p := other[cand]
+	b.orders[1-src] = append(other[:cand:cand], other[cand+1:]...)
+	if exact {
+		st.MatchedOrders++
+		return
+	}
+	live, shadow := p, &o
+	if src == Live {
+		live, shadow = &o, p
+	}
+	c.record(id, OrderMismatch, o.Time, o.Symbol, live.String(), shadow.String(), map[string]interface{}{
+		"live_time":   live.Time,
+		"shadow_time": shadow.Time,
+		"price_diff":  live.Price - shadow.Price,
+		"qty_diff":    live.Qty - shadow.Qty,
+	})
+}
+
+// OnFill 记录一笔成交，按数量与对方窗口内同品种同方向的成交抵消（拆单/部分成交可跨笔对齐）
+func (c *Comparator) OnFill(src Source, id string, f Order) {
+	if f.Qty <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := c.stats(id)
+	if src == Live {
+		st.LiveFillQty += f.Qty
+	} else {
+		st.ShadowFillQty += f.Qty
+	}
+
+	b := c.book(id)
+	remaining := f.Qty
+	other := b.fills[1-src]
+	kept := other[:0]
+	for _, p := range other {
+		if remaining > 0 && p.Symbol == f.Symbol && p.Buy == f.Buy &&
+			absDuration(p.Time.Sub(f.Time)) <= c.opts.FillWindow {
+			q := min(remaining, p.remaining)
+			remaining -= q
+			p.remaining -= q
+			st.MatchedFillQty += q
+
+			livePx, shadowPx := p.Price, f.Price
+			if src == Live {
+				livePx, shadowPx = f.Price, p.Price
+			}
+			diff := livePx - shadowPx
+			if !f.Buy {
+				diff = -diff
+			}
+			st.fillDiffSum += diff * float64(q)
+		}
+		if p.remaining > 0 {
+			kept = append(kept, p)
+		}
+	}
+	b.fills[1-src] = kept
+	if remaining > 0 {
+		b.fills[src] = append(b.fills[src], &pendingFill{Order: f, remaining: remaining})
+	}
+}
+
+// OnSignal 记录同一笔行情（key）处理后的信号值，双方都到齐后比较
+// 连续超阈值只记一条差异，回到阈值内后重新计
+func (c *Comparator) OnSignal(src Source, id, symbol string, key interface{}, t time.Time, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.book(id)
+	p, ok := b.signals[key]
+	if !ok || p.src == src {
+		b.signals[key] = &pendingSignal{t: t, src: src, value: value}
+		return
+	}
+	delete(b.signals, key)
+
+	live, shadow := p.value, value
+	if src == Live {
+		live, shadow = value, p.value
+	}
+	st := c.stats(id)
+	st.SignalChecks++
+	d := math.Abs(live - shadow)
+	if d > st.MaxSignalDiff {
+		st.MaxSignalDiff = d
+	}
+	if d <= c.opts.SignalTolerance {
+		b.signalDiverged = false
+		return
+	}
+	st.SignalMismatches++
+	if b.signalDiverged {
+		return
+	}
+	b.signalDiverged = true
+	c.record(id, SignalMismatch, t, symbol, fmt.Sprintf("%.4f", live), fmt.Sprintf("%.4f", shadow), map[string]interface{}{
+		"diff":      live - shadow,
+		"tolerance": c.opts.SignalTolerance,
+	})
+}
+
+// OnPositions 比较按品种的持仓；不一致持续超过 PositionGrace 记一条差异，恢复一致后重新计
+func (c *Comparator) OnPositions(id string, t time.Time, live, shadow map[string]int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.book(id)
+	diff := positionDiff(live, shadow)
+	if len(diff) == 0 {
+		if b.posReported {
+			c.opts.Logf("[Shadow] %s: position back in sync (%s)", id, formatPositions(live))
+		}
+		b.posSince, b.posReported = time.Time{}, false
+		return
+	}
+	if b.posSince.IsZero() {
+		b.posSince = t
+	}
+	if b.posReported || t.Sub(b.posSince) < c.opts.PositionGrace {
+		return
+	}
+	b.posReported = true
+	c.record(id, PositionMismatch, t, diff[0], formatPositions(live), formatPositions(shadow), map[string]interface{}{
+		"symbols": diff,
+		"since":   b.posSince,
+	})
+}
+
+// Advance 以行情时间 now 结算超出窗口仍未对齐的委托和成交
+func (c *Comparator) Advance(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(now, false)
+}
+
+// Flush 结算全部未对齐事件（换日/停止时调用）
+func (c *Comparator) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(time.Time{}, true)
+}
+
+func (c *Comparator) expire(now time.Time, all bool) {
+	ids := make([]string, 0, len(c.books))
+	for id := range c.books {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		b := c.books[id]
+		for src := Live; src <= Shadow; src++ {
+			kind := OrderLiveOnly
+			if src == Shadow {
+				kind = OrderShadowOnly
+			}
+			kept := b.orders[src][:0]
+			for _, o := range b.orders[src] {
+				if !all && now.Sub(o.Time) <= c.opts.DecisionWindow {
+					kept = append(kept, o)
+					continue
+				}
+				live, shadow := o.String(), ""
+				if src == Shadow {
+					live, shadow = "", o.String()
+				}
+				c.record(id, kind, o.Time, o.Symbol, live, shadow, map[string]interface{}{
+					"window_ms": c.opts.DecisionWindow.Milliseconds(),
+				})
+			}
+			b.orders[src] = kept
+
+			kind = FillLiveOnly
+			if src == Shadow {
+				kind = FillShadowOnly
+			}
+			keptFills := b.fills[src][:0]
+			for _, f := range b.fills[src] {
+				if !all && now.Sub(f.Time) <= c.opts.FillWindow {
+					keptFills = append(keptFills, f)
+					continue
+				}
+				o := f.Order
+				o.Qty = f.remaining
+				live, shadow := o.String(), ""
+				if src == Shadow {
+					live, shadow = "", o.String()
+				}
+				c.record(id, kind, f.Time, f.Symbol, live, shadow, map[string]interface{}{
+					"order_id":  f.OrderID,
+					"fill_qty":  f.Qty,
+					"window_ms": c.opts.FillWindow.Milliseconds(),
+				})
+			}
+			b.fills[src] = keptFills
+		}
+		for key, p := range b.signals {
+			if all || now.Sub(p.t) > c.opts.DecisionWindow {
+				delete(b.signals, key)
+			}
+		}
+	}
+}
+
+// record 记录并输出一条差异（调用方持有锁）
+func (c *Comparator) record(id string, kind Kind, t time.Time, symbol, live, shadow string, ctx map[string]interface{}) {
+	if q, ok := c.quotes[symbol]; ok {
+		ctx["bid"], ctx["ask"] = q.bid, q.ask
+	}
+	rep := c.report
+	rep.Counts[kind]++
+	c.stats(id).Divergences++
+
+	if len(rep.Divergences) >= c.opts.MaxDivergences {
+		if rep.Truncated == 0 {
+			c.opts.Logf("[Shadow] Divergence detail limit (%d) reached, further divergences are only counted", c.opts.MaxDivergences)
+		}
+		rep.Truncated++
+		return
+	}
+	rep.Divergences = append(rep.Divergences, Divergence{
+		Time:       t,
+		StrategyID: id,
+		Kind:       kind,
+		Symbol:     symbol,
+		Live:       live,
+		Shadow:     shadow,
+		Context:    ctx,
+	})
+	c.opts.Logf("[Shadow] %s %s %s at %s: live=[%s] shadow=[%s] %v",
+		id, kind, symbol, t.Format("15:04:05.000"), live, shadow, ctx)
+}
+
+// Snapshot 返回当前交易日报告的副本
+func (c *Comparator) Snapshot() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshotLocked()
+}
+
+func (c *Comparator) snapshotLocked() *Report {
+	src := c.report
+	rep := &Report{
+		TradingDay:  src.TradingDay,
+		Start:       src.Start,
+		End:         time.Now(),
+		Strategies:  make(map[string]*StrategyStats, len(src.Strategies)),
+		Counts:      make(map[Kind]int, len(src.Counts)),
+		Divergences: append([]Divergence(nil), src.Divergences...),
+		Truncated:   src.Truncated,
+		Dropped:     src.Dropped,
+	}
+	for id, st := range src.Strategies {
+		cp := *st
+		if cp.MatchedFillQty > 0 {
+			cp.FillPriceDiff = cp.fillDiffSum / float64(cp.MatchedFillQty)
+		}
+		rep.Strategies[id] = &cp
+	}
+	for k, n := range src.Counts {
+		rep.Counts[k] = n
+	}
+	return rep
+}
+
+// Roll 结束当前交易日：返回其报告并以 day 开始新报告
+// 调用前应先 Flush，未对齐事件计入旧交易日
+func (c *Comparator) Roll(day string) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rep := c.snapshotLocked()
+	c.report = newReport(day)
+	return rep
+}
+
+// TradingDay 当前报告的交易日
+func (c *Comparator) TradingDay() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.report.TradingDay
+}
+
+// AddDropped 累计丢弃的实盘事件数
+func (c *Comparator) AddDropped(n int64) {
+	c.mu.Lock()
+	c.report.Dropped += n
+	c.mu.Unlock()
+}
+
+// positionDiff 返回持仓不一致的品种（缺失按 0 计）
+func positionDiff(live, shadow map[string]int64) []string {
+	var diff []string
+	for sym, q := range live {
+		if shadow[sym] != q {
+			diff = append(diff, sym)
+		}
+	}
+	for sym, q := range shadow {
+		if _, ok := live[sym]; !ok && q != 0 {
+			diff = append(diff, sym)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+func formatPositions(pos map[string]int64) string {
+	syms := make([]string, 0, len(pos))
+	for sym := range pos {
+		syms = append(syms, sym)
+	}
+	sort.Strings(syms)
+	parts := make([]string, len(syms))
+	for i, sym := range syms {
+		parts[i] = fmt.Sprintf("%s=%d", sym, pos[sym])
+	}
+	return strings.Join(parts, " ")
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,124 @@
+package shadow
+
+import (
+	"testing"
+	"time"
+)
+
+func quietOptions() Options {
+	return Options{
+		DecisionWindow: 2 * time.Second,
+		FillWindow:     2 * time.Second,
+		PositionGrace:  3 * time.Second,
+		Logf:           func(string, ...interface{}) {},
+	}
+}
+
+func TestComparator_Orders(t *testing.T) {
+	c := NewComparator(quietOptions(), "20250102")
+	t0 := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+
+	// 一致：对齐
+	c.OnOrder(Live, "s1", Order{Time: t0, Symbol: "ag2502", Buy: true, Price: 5000, Qty: 1})
+	c.OnOrder(Shadow, "s1", Order{Time: t0, Symbol: "ag2502", Buy: true, Price: 5000, Qty: 1})
+	// 同方向价格不同：OrderMismatch
+	c.OnOrder(Shadow, "s1", Order{Time: t0.Add(time.Second), Symbol: "ag2502", Buy: false, Price: 5001, Qty: 1})
+	c.OnOrder(Live, "s1", Order{Time: t0.Add(time.Second), Symbol: "ag2502", Buy: false, Price: 5002, Qty: 1})
+	// 只有实盘：窗口过后 OrderLiveOnly
+	c.OnOrder(Live, "s1", Order{Time: t0.Add(2 * time.Second), Symbol: "ag2504", Buy: true, Price: 5100, Qty: 2})
+
+	c.Advance(t0.Add(3 * time.Second))
+	if n := c.Snapshot().Counts[OrderLiveOnly]; n != 0 {
+		t.Fatalf("live-only reported before window elapsed: %d", n)
+	}
+	c.Advance(t0.Add(5 * time.Second))
+
+	rep := c.Snapshot()
+	st := rep.Strategies["s1"]
+	if st.LiveOrders != 3 || st.ShadowOrders != 2 || st.MatchedOrders != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+	if rep.Counts[OrderMismatch] != 1 || rep.Counts[OrderLiveOnly] != 1 || rep.Total() != 2 {
+		t.Errorf("counts = %v", rep.Counts)
+	}
+	d := rep.Divergences[0]
+	if d.Kind != OrderMismatch || d.Live != "SELL 1@5002" || d.Shadow != "SELL 1@5001" || d.Context["price_diff"] != 1.0 {
+		t.Errorf("mismatch divergence = %+v", d)
+	}
+}
+
+func TestComparator_FillsSplitAcrossOrders(t *testing.T) {
+	c := NewComparator(quietOptions(), "20250102")
+	t0 := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+	c.OnQuote("ag2502", 4999, 5000)
+
+	// 影子一笔 3 手，实盘拆成 1 + 2 手（平昨/开仓拆单）
+	c.OnFill(Shadow, "s1", Order{Time: t0, Symbol: "ag2502", Buy: true, Price: 5000, Qty: 3})
+	c.OnFill(Live, "s1", Order{Time: t0, Symbol: "ag2502", Buy: true, Price: 5001, Qty: 1})
+	c.OnFill(Live, "s1", Order{Time: t0.Add(time.Second), Symbol: "ag2502", Buy: true, Price: 5002, Qty: 2})
+	// 实盘多成交 1 手，影子没有
+	c.OnFill(Live, "s1", Order{Time: t0.Add(time.Second), Symbol: "ag2502", Buy: true, Price: 5002, Qty: 1, OrderID: "L9"})
+	c.Flush()
+
+	rep := c.Snapshot()
+	st := rep.Strategies["s1"]
+	if st.MatchedFillQty != 3 || st.LiveFillQty != 4 || st.ShadowFillQty != 3 {
+		t.Errorf("stats = %+v", st)
+	}
+	// 买入：实盘均价 (5001 + 2*5002)/3 相对影子 5000 更差
+	if want := 5.0 / 3; st.FillPriceDiff < want-1e-9 || st.FillPriceDiff > want+1e-9 {
+		t.Errorf("FillPriceDiff = %v, want %v", st.FillPriceDiff, want)
+	}
+	if rep.Counts[FillLiveOnly] != 1 || rep.Total() != 1 {
+		t.Fatalf("counts = %v", rep.Counts)
+	}
+	d := rep.Divergences[0]
+	if d.Live != "BUY 1@5002" || d.Context["order_id"] != "L9" || d.Context["ask"] != 5000.0 {
+		t.Errorf("divergence = %+v", d)
+	}
+}
+
+func TestComparator_PositionGraceAndSignals(t *testing.T) {
+	c := NewComparator(quietOptions(), "20250102")
+	t0 := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+
+	live := map[string]int64{"ag2502": 1, "ag2504": -1}
+	c.OnPositions("s1", t0, live, map[string]int64{"ag2502": 1}) // 回报延迟，容忍期内
+	c.OnPositions("s1", t0.Add(time.Second), live, live)         // 恢复一致
+	c.OnPositions("s1", t0.Add(2*time.Second), live, map[string]int64{"ag2502": 1})
+	c.OnPositions("s1", t0.Add(4*time.Second), live, map[string]int64{"ag2502": 1})
+	if n := c.Snapshot().Counts[PositionMismatch]; n != 0 {
+		t.Fatalf("position mismatch reported within grace: %d", n)
+	}
+	c.OnPositions("s1", t0.Add(5*time.Second), live, map[string]int64{"ag2502": 1})
+	c.OnPositions("s1", t0.Add(6*time.Second), live, map[string]int64{"ag2502": 1})
+	c.OnPositions("s1", t0.Add(7*time.Second), live, map[string]int64{"ag2502": 1})
+
+	// 同一笔行情（key）双方信号值，连续超阈值只记一次
+	for i, diff := range []float64{0.05, 0.5, 0.6, 0.01, 0.7} {
+		key := &struct{ n int }{i}
+		c.OnSignal(Shadow, "s1", "ag2502", key, t0, 1.0+diff)
+		c.OnSignal(Live, "s1", "ag2502", key, t0, 1.0)
+	}
+
+	rep := c.Snapshot()
+	if rep.Counts[PositionMismatch] != 1 {
+		t.Errorf("position mismatches = %d, want 1", rep.Counts[PositionMismatch])
+	}
+	d := rep.Divergences[0]
+	if d.Symbol != "ag2504" || d.Live != "ag2502=1 ag2504=-1" || d.Shadow != "ag2502=1" {
+		t.Errorf("position divergence = %+v", d)
+	}
+	st := rep.Strategies["s1"]
+	if st.SignalChecks != 5 || st.SignalMismatches != 3 || rep.Counts[SignalMismatch] != 2 {
+		t.Errorf("signal stats = %+v counts = %v", st, rep.Counts)
+	}
+	if st.MaxSignalDiff < 0.7-1e-9 {
+		t.Errorf("MaxSignalDiff = %v", st.MaxSignalDiff)
+	}
+
+	old := c.Roll("20250103")
+	if old.TradingDay != "20250102" || c.TradingDay() != "20250103" || c.Snapshot().Total() != 0 {
+		t.Errorf("roll: old=%s new=%s", old.TradingDay, c.TradingDay())
+	}
+}
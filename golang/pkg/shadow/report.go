@@ -0,0 +1,99 @@
+package shadow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxReportRows 日报 markdown 中列出的差异明细上限（完整明细见 JSON）
+const maxReportRows = 200
+
+// WriteReport 写出交易日报告：<dir>/shadow.<day>.json 和 <dir>/shadow.<day>.md
+// 同一交易日重复写出（重启/停止）时覆盖，返回 JSON 路径
+func WriteReport(dir string, rep *Report) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create report dir: %w", err)
+	}
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("shadow.%s.json", rep.TradingDay))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	md := filepath.Join(dir, fmt.Sprintf("shadow.%s.md", rep.TradingDay))
+	return path, os.WriteFile(md, []byte(FormatMarkdown(rep)), 0644)
+}
+
+// FormatMarkdown 日报 markdown：按策略的对齐统计、按类型的差异计数和差异明细
+func FormatMarkdown(rep *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 影子对比日报 %s\n\n", rep.TradingDay)
+	fmt.Fprintf(&b, "- 区间: %s ~ %s\n", rep.Start.Format("2006-01-02 15:04:05"), rep.End.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- 差异总数: %d\n", rep.Total())
+	if rep.Dropped > 0 {
+		fmt.Fprintf(&b, "- ⚠ 队列满丢弃实盘事件 %d 条，丢弃期间的差异不可信\n", rep.Dropped)
+	}
+	b.WriteString("\n")
+
+	ids := make([]string, 0, len(rep.Strategies))
+	for id := range rep.Strategies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	b.WriteString("## 策略对齐\n\n")
+	b.WriteString("| 策略 | 委托(实盘/影子/对齐) | 成交量(实盘/影子/对齐) | 成交价差 | 信号(比较/超阈值/最大差) | 差异 |\n")
+	b.WriteString("|------|------|------|------|------|------|\n")
+	for _, id := range ids {
+		st := rep.Strategies[id]
+		fmt.Fprintf(&b, "| %s | %d / %d / %d | %d / %d / %d | %.4f | %d / %d / %.4f | %d |\n",
+			id, st.LiveOrders, st.ShadowOrders, st.MatchedOrders,
+			st.LiveFillQty, st.ShadowFillQty, st.MatchedFillQty, st.FillPriceDiff,
+			st.SignalChecks, st.SignalMismatches, st.MaxSignalDiff, st.Divergences)
+	}
+
+	b.WriteString("\n## 差异类型\n\n")
+	b.WriteString("| 类型 | 数量 |\n|------|------|\n")
+	for _, k := range Kinds {
+		if n := rep.Counts[k]; n > 0 {
+			fmt.Fprintf(&b, "| %s | %d |\n", k, n)
+		}
+	}
+
+	if len(rep.Divergences) > 0 {
+		b.WriteString("\n## 差异明细\n\n")
+		b.WriteString("| 时间 | 策略 | 类型 | 品种 | 实盘 | 影子 | 上下文 |\n")
+		b.WriteString("|------|------|------|------|------|------|------|\n")
+		for i, d := range rep.Divergences {
+			if i >= maxReportRows {
+				fmt.Fprintf(&b, "\n（另有 %d 条见 JSON）\n", len(rep.Divergences)-maxReportRows)
+				break
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+				d.Time.Format("15:04:05.000"), d.StrategyID, d.Kind, d.Symbol, d.Live, d.Shadow, formatContext(d.Context))
+		}
+	}
+	if rep.Truncated > 0 {
+		fmt.Fprintf(&b, "\n超过明细上限未保留 %d 条（只计数）\n", rep.Truncated)
+	}
+	return b.String()
+}
+
+func formatContext(ctx map[string]interface{}) string {
+	keys := make([]string, 0, len(ctx))
+	for k := range ctx {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, ctx[k])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,555 @@
+// Package shadow implements the live-vs-backtest shadow comparison mode.
+//
+// 影子模式（Go 扩展）：实盘收到的每笔行情同时驱动一份策略副本，副本的委托
+// 不发往柜台，而是接回测撮合（backtest.BacktestOrderRouter）。Runner 持续对比
+// 实盘与副本的信号值、委托决策、（理论）成交和持仓，差异带上下文记日志，并按
+// 交易日输出报告，用于发现实盘与回测行为不一致（参数/状态漂移、回报丢失、撮合
+// 假设失真等）。
+//
+// 副本与实盘使用相同的策略 ID 和参数，但：
+//   - 不写共享内存（去掉 tcache_key），不调用 Stop/RolloverTradingDay（不落盘）
+//   - 启动时以实盘持仓为初值，之后只由回测撮合的成交驱动
+//   - 原生 tbsrc 策略（NativeSHMAware）直接收发 SHM，不支持影子；启用影子模式时配置校验拒绝 tbsrc_pairwise
+package shadow
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/backtest"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// Config 影子模式参数
+type Config struct {
+	Options
+	ReportDir     string                    // 日报目录
+	QueueSize     int                       // 实盘事件队列长度，满时丢弃并计数，默认 65536
+	TimerInterval time.Duration             // 副本 OnTimer/撤单处理间隔，默认 1s
+	Sim           backtest.OrderSimSettings // 回测撮合参数
+	TradingDay    func(t time.Time) string  // 行情时间 → 交易日（nil = 自然日）
+}
+
+// replica 实盘策略及其影子副本
+type replica struct {
+	id      string
+	live    strategy.Strategy
+	sim     strategy.Strategy
+	started bool
+	failed  bool
+}
+
+type eventKind int
+
+const (
+	evMarketData eventKind = iota
+	evLiveSignals
+	evLiveUpdate
+)
+
+type event struct {
+	kind     eventKind
+	md       *mdpb.MarketDataUpdate
+	id       string
+	orders   []Order
+	value    float64
+	hasValue bool
+	update   *orspb.OrderUpdate
+}
+
+// signalReader 可输出信号值的策略（价差类策略的 z_score）
+type signalReader interface {
+	GetSpreadStatus() map[string]interface{}
+}
+
+// positionSeeder 可由外部初始化持仓的策略
+type positionSeeder interface {
+	InitializePositions(positions map[string]int64) error
+}
+
+// Runner 驱动影子副本并与实盘对比，实现 strategy.ShadowTap
+// 实盘侧回调只入队；副本、回测撮合和对比都在 Runner 自己的 goroutine 中运行
+type Runner struct {
+	cfg    Config
+	cmp    *Comparator
+	router *backtest.BacktestOrderRouter
+
+	replicas []*replica
+	byID     map[string]*replica
+
+	// 以下只在 run goroutine 中访问
+	orders     map[string]*replica // 影子订单 ID → 副本
+	liveFilled map[string]int64    // 实盘订单 ID → 已计入的成交量
+	seq        int64
+	now        time.Time // 最新行情时间
+
+	events  chan event
+	dropped int64
+
+	running  bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New 为 live 中的每个策略创建影子副本（须在策略 Start 之前调用，此时参数未被热加载修改）
+// 不支持的策略记日志后跳过
+func New(cfg Config, live []strategy.Strategy) (*Runner, error) {
+	return newRunner(cfg, live, cloneStrategy)
+}
+
+func newRunner(cfg Config, live []strategy.Strategy, clone func(strategy.Strategy) (strategy.Strategy, error)) (*Runner, error) {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 65536
+	}
+	if cfg.TimerInterval <= 0 {
+		cfg.TimerInterval = time.Second
+	}
+	if cfg.TradingDay == nil {
+		cfg.TradingDay = func(t time.Time) string { return t.Format("20060102") }
+	}
+
+	router, err := backtest.NewBacktestOrderRouter(&backtest.BacktestConfig{
+		Backtest: backtest.BacktestSettings{OrderSim: cfg.Sim},
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("create shadow order router: %w", err)
+	}
+
+	r := &Runner{
+		cfg:        cfg,
+		cmp:        NewComparator(cfg.Options, cfg.TradingDay(time.Now())),
+		router:     router,
+		byID:       make(map[string]*replica),
+		orders:     make(map[string]*replica),
+		liveFilled: make(map[string]int64),
+		events:     make(chan event, cfg.QueueSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	router.SetOrderUpdateCallback(r.onShadowUpdate)
+
+	for _, s := range live {
+		if _, ok := s.(strategy.NativeSHMAware); ok {
+			log.Printf("[Shadow] Skipping %s: native tbsrc strategies are not supported", s.GetID())
+			continue
+		}
+		sim, err := clone(s)
+		if err != nil {
+			log.Printf("[Shadow] Skipping %s: %v", s.GetID(), err)
+			continue
+		}
+		rep := &replica{id: s.GetID(), live: s, sim: sim}
+		r.replicas = append(r.replicas, rep)
+		r.byID[rep.id] = rep
+	}
+	sort.Slice(r.replicas, func(i, j int) bool { return r.replicas[i].id < r.replicas[j].id })
+
+	if len(r.replicas) == 0 {
+		return nil, fmt.Errorf("no strategy supports shadow mode")
+	}
+	return r, nil
+}
+
+// cloneStrategy 按实盘策略的类型和配置新建副本（去掉共享内存写入键）
+func cloneStrategy(live strategy.Strategy) (strategy.Strategy, error) {
+	cfg := live.GetConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("strategy has no config")
+	}
+	typ := cfg.StrategyType
+	if typ == "" {
+		typ = live.GetType()
+	}
+	sim, err := strategy.NewStrategy(typ, live.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	cp := *cfg
+	cp.Parameters = make(map[string]interface{}, len(cfg.Parameters))
+	for k, v := range cfg.Parameters {
+		if k == "tcache_key" {
+			continue
+		}
+		cp.Parameters[k] = v
+	}
+	if err := sim.Initialize(&cp); err != nil {
+		return nil, fmt.Errorf("initialize shadow copy: %w", err)
+	}
+	return sim, nil
+}
+
+// StrategyIDs 有影子副本的策略
+func (r *Runner) StrategyIDs() []string {
+	ids := make([]string, len(r.replicas))
+	for i, rep := range r.replicas {
+		ids[i] = rep.id
+	}
+	return ids
+}
+
+// Start 启动对比 goroutine
+func (r *Runner) Start() {
+	r.running = true
+	go r.run()
+	log.Printf("[Shadow] ✓ Shadow mode started for %v (report dir %s)", r.StrategyIDs(), r.cfg.ReportDir)
+}
+
+// Stop 处理完已入队事件，结算未对齐事件并写出当日报告
+// 副本不调用 Stop（不写 daily_init/持仓快照）
+func (r *Runner) Stop() error {
+	r.stopOnce.Do(func() { close(r.stop) })
+	if r.running {
+		<-r.done
+	}
+
+	r.cmp.Flush()
+	_, err := r.writeReport(r.cmp.Snapshot())
+	return err
+}
+
+// Report 当前交易日报告（副本）
+func (r *Runner) Report() *Report {
+	return r.cmp.Snapshot()
+}
+
+// ==================== strategy.ShadowTap ====================
+
+// OnMarketData 实盘行情（分发给策略之前）
+func (r *Runner) OnMarketData(md *mdpb.MarketDataUpdate) {
+	r.push(event{kind: evMarketData, md: md})
+}
+
+// OnLiveSignals 实盘策略处理完一笔行情后下发的信号
+// 信号值在此同步读取，保证与副本比较的是同一笔行情之后的状态
+func (r *Runner) OnLiveSignals(s strategy.Strategy, md *mdpb.MarketDataUpdate, signals []*strategy.TradingSignal) {
+	id := s.GetID()
+	if _, ok := r.byID[id]; !ok {
+		return
+	}
+	t := mdTime(md)
+	ev := event{kind: evLiveSignals, md: md, id: id}
+	for _, sig := range signals {
+		ev.orders = append(ev.orders, signalOrder(sig, t))
+	}
+	ev.value, ev.hasValue = signalValue(s)
+	r.push(ev)
+}
+
+// OnLiveOrderUpdate 实盘订单回报
+func (r *Runner) OnLiveOrderUpdate(update *orspb.OrderUpdate) {
+	r.push(event{kind: evLiveUpdate, update: update})
+}
+
+// push 入队，不阻塞实盘路径
+func (r *Runner) push(ev event) {
+	select {
+	case r.events <- ev:
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+	}
+}
+
+// ==================== 对比 goroutine ====================
+
+func (r *Runner) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.TimerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-r.events:
+			r.handle(ev)
+		case now := <-ticker.C:
+			r.onTimer(now)
+		case <-r.stop:
+			for {
+				select {
+				case ev := <-r.events:
+					r.handle(ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Runner) handle(ev event) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("[Shadow] Panic while handling event: %v", p)
+		}
+	}()
+
+	switch ev.kind {
+	case evMarketData:
+		r.onMarketData(ev.md)
+	case evLiveSignals:
+		r.onLiveSignals(ev)
+	case evLiveUpdate:
+		r.onLiveUpdate(ev.update)
+	}
+}
+
+// onMarketData 先更新回测撮合（挂单可能成交），再驱动各副本，最后对比持仓并结算超时事件
+// 实盘策略未运行时副本也不处理行情（跟随实盘的激活状态）
+func (r *Runner) onMarketData(md *mdpb.MarketDataUpdate) {
+	t := mdTime(md)
+	r.rollDay(t)
+	r.now = t
+	if len(md.BidPrice) > 0 && len(md.AskPrice) > 0 {
+		r.cmp.OnQuote(md.Symbol, md.BidPrice[0], md.AskPrice[0])
+	}
+	r.router.UpdateMarketData(md)
+
+	for _, rep := range r.replicas {
+		if !rep.live.IsRunning() || !r.ensureStarted(rep) {
+			continue
+		}
+		rep.sim.SetLastMarketData(md.GetSymbol(), md)
+		rep.sim.OnMarketData(md)
+		r.submit(rep, rep.sim.GetSignals(), t)
+
+		if v, ok := signalValue(rep.sim); ok {
+			r.cmp.OnSignal(Shadow, rep.id, md.Symbol, md, t, v)
+		}
+		r.cmp.OnPositions(rep.id, t, positions(rep.live), positions(rep.sim))
+	}
+	r.cmp.Advance(t)
+}
+
+// ensureStarted 实盘策略首次运行时启动副本，并以实盘当前持仓为初值
+func (r *Runner) ensureStarted(rep *replica) bool {
+	if rep.started || rep.failed {
+		return rep.started
+	}
+	if err := rep.sim.Start(); err != nil {
+		log.Printf("[Shadow] Failed to start shadow copy of %s: %v", rep.id, err)
+		rep.failed = true
+		return false
+	}
+	if seeder, ok := rep.sim.(positionSeeder); ok {
+		if pos, ok := rep.live.(strategy.PositionProvider); ok {
+			if err := seeder.InitializePositions(pos.GetPositionsBySymbol()); err != nil {
+				log.Printf("[Shadow] Failed to seed positions of %s: %v", rep.id, err)
+			}
+		}
+	}
+	rep.started = true
+	log.Printf("[Shadow] Shadow copy of %s started (positions %s)", rep.id, formatPositions(positions(rep.sim)))
+	return true
+}
+
+// submit 副本信号发往回测撮合（订单 ID 由影子生成，回报按 ID 回到副本）
+func (r *Runner) submit(rep *replica, signals []*strategy.TradingSignal, t time.Time) {
+	for _, sig := range signals {
+		if !rep.sim.CanSendOrder() || !rep.live.CanSendOrder() {
+			continue
+		}
+		req := sig.ToOrderRequest()
+		r.seq++
+		req.ClientOrderId = fmt.Sprintf("SHADOW_%d", r.seq)
+		req.StrategyId = rep.id
+		r.orders[req.ClientOrderId] = rep
+
+		r.cmp.OnOrder(Shadow, rep.id, signalOrder(sig, t))
+		if err := r.router.SubmitOrder(req); err != nil {
+			log.Printf("[Shadow] %s: shadow order rejected: %v", rep.id, err)
+			delete(r.orders, req.ClientOrderId)
+		}
+	}
+}
+
+// onShadowUpdate 回测撮合回报：转给所属副本，成交计入对比
+// 由 router 在 run goroutine 中同步回调
+func (r *Runner) onShadowUpdate(update *orspb.OrderUpdate) {
+	rep, ok := r.orders[update.OrderId]
+	if !ok {
+		return
+	}
+	update.StrategyId = rep.id
+
+	rep.sim.OnOrderUpdate(update)
+	if detailed, ok := rep.sim.(strategy.DetailedOrderStrategy); ok {
+		switch update.Status {
+		case orspb.OrderStatus_ACCEPTED, orspb.OrderStatus_SUBMITTED:
+			detailed.OnOrderNew(update)
+		case orspb.OrderStatus_FILLED, orspb.OrderStatus_PARTIALLY_FILLED:
+			detailed.OnOrderFilled(update)
+		case orspb.OrderStatus_CANCELED:
+			detailed.OnOrderCanceled(update)
+		case orspb.OrderStatus_REJECTED:
+			detailed.OnOrderRejected(update)
+		}
+	}
+
+	switch update.Status {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_PARTIALLY_FILLED:
+		r.cmp.OnFill(Shadow, rep.id, fillOrder(update, update.LastFillQty, r.now))
+		if update.Status == orspb.OrderStatus_FILLED {
+			delete(r.orders, update.OrderId)
+		}
+	case orspb.OrderStatus_CANCELED, orspb.OrderStatus_REJECTED:
+		delete(r.orders, update.OrderId)
+	}
+}
+
+func (r *Runner) onLiveSignals(ev event) {
+	t := mdTime(ev.md)
+	for _, o := range ev.orders {
+		r.cmp.OnOrder(Live, ev.id, o)
+	}
+	if ev.hasValue {
+		r.cmp.OnSignal(Live, ev.id, ev.md.Symbol, ev.md, t, ev.value)
+	}
+}
+
+// onLiveUpdate 实盘成交按累计成交量差分计入对比（时间取最新行情时间）
+func (r *Runner) onLiveUpdate(update *orspb.OrderUpdate) {
+	rep, ok := r.byID[update.StrategyId]
+	if !ok {
+		if update.StrategyId != "" || len(r.replicas) != 1 {
+			return
+		}
+		rep = r.replicas[0]
+	}
+
+	switch update.Status {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_PARTIALLY_FILLED:
+		qty := update.FilledQty - r.liveFilled[update.OrderId]
+		if qty > 0 {
+			r.liveFilled[update.OrderId] = update.FilledQty
+			r.cmp.OnFill(Live, rep.id, fillOrder(update, qty, r.now))
+		}
+		if update.Status == orspb.OrderStatus_FILLED {
+			delete(r.liveFilled, update.OrderId)
+		}
+	case orspb.OrderStatus_CANCELED, orspb.OrderStatus_REJECTED:
+		delete(r.liveFilled, update.OrderId)
+	}
+}
+
+// onTimer 副本定时回调、状态检查和撤单（对应 StrategyEngine.timerLoop）
+func (r *Runner) onTimer(now time.Time) {
+	if n := atomic.SwapInt64(&r.dropped, 0); n > 0 {
+		r.cmp.AddDropped(n)
+		log.Printf("[Shadow] Warning: event queue full, dropped %d live events", n)
+	}
+
+	for _, rep := range r.replicas {
+		if !rep.started {
+			continue
+		}
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					log.Printf("[Shadow] Panic in shadow copy %s OnTimer: %v", rep.id, p)
+				}
+			}()
+			rep.sim.OnTimer(now)
+			if cs := rep.sim.GetControlState(); cs != nil {
+				rep.sim.CheckSquareoff()
+				if cs.FlattenMode || cs.ExitRequested {
+					rep.sim.HandleSquareoff()
+				}
+			}
+			for _, o := range rep.sim.GetPendingCancels() {
+				// 已成交的订单撤单失败属正常
+				_ = r.router.CancelOrder(o.OrderId)
+			}
+		}()
+	}
+}
+
+// rollDay 行情进入新交易日时结算并写出上一交易日报告
+func (r *Runner) rollDay(t time.Time) {
+	day := r.cfg.TradingDay(t)
+	if day == r.cmp.TradingDay() {
+		return
+	}
+	r.cmp.Flush()
+	rep := r.cmp.Roll(day)
+	if len(rep.Strategies) == 0 {
+		return
+	}
+	if _, err := r.writeReport(rep); err != nil {
+		log.Printf("[Shadow] Failed to write report for %s: %v", rep.TradingDay, err)
+	}
+}
+
+func (r *Runner) writeReport(rep *Report) (string, error) {
+	if r.cfg.ReportDir == "" {
+		return "", nil
+	}
+	path, err := WriteReport(r.cfg.ReportDir, rep)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[Shadow] Report for %s: %d divergences → %s", rep.TradingDay, rep.Total(), path)
+	return path, nil
+}
+
+// ==================== helpers ====================
+
+// mdTime 行情时间（纳秒时间戳），缺失时用本地时间
+func mdTime(md *mdpb.MarketDataUpdate) time.Time {
+	if md != nil && md.Timestamp > 0 {
+		return time.Unix(0, int64(md.Timestamp))
+	}
+	return time.Now()
+}
+
+func signalOrder(sig *strategy.TradingSignal, t time.Time) Order {
+	return Order{
+		Time:   t,
+		Symbol: sig.Symbol,
+		Buy:    sig.Side == strategy.OrderSideBuy,
+		Price:  sig.Price,
+		Qty:    sig.Quantity,
+	}
+}
+
+func fillOrder(update *orspb.OrderUpdate, qty int64, t time.Time) Order {
+	price := update.LastFillPrice
+	if price <= 0 {
+		price = update.AvgPrice
+	}
+	return Order{
+		Time:    t,
+		Symbol:  update.Symbol,
+		Buy:     update.Side == orspb.OrderSide_BUY,
+		Price:   price,
+		Qty:     qty,
+		OrderID: update.OrderId,
+	}
+}
+
+// signalValue 读取价差类策略的 z_score
+func signalValue(s strategy.Strategy) (float64, bool) {
+	sr, ok := s.(signalReader)
+	if !ok {
+		return 0, false
+	}
+	z, ok := sr.GetSpreadStatus()["z_score"].(float64)
+	return z, ok
+}
+
+// positions 按品种持仓；不提供按品种持仓的策略用净持仓（键 "net"）
+func positions(s strategy.Strategy) map[string]int64 {
+	if p, ok := s.(strategy.PositionProvider); ok {
+		return p.GetPositionsBySymbol()
+	}
+	if pos := s.GetEstimatedPosition(); pos != nil {
+		return map[string]int64{"net": pos.NetQty}
+	}
+	return map[string]int64{}
+}
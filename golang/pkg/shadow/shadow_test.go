@@ -0,0 +1,193 @@
+package shadow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// scriptedStrategy 在指定行情序号按卖一价买 1 手，z_score = 序号 * 0.1 + zShift（从 shiftFrom 起）
+type scriptedStrategy struct {
+	*strategy.PassiveStrategy
+
+	buyOn     map[int]bool
+	zShift    float64
+	shiftFrom int
+
+	mu      sync.Mutex
+	n       int
+	z       float64
+	pending []*strategy.TradingSignal
+	pos     map[string]int64
+}
+
+func newScripted(t *testing.T, buyOn ...int) *scriptedStrategy {
+	s := &scriptedStrategy{
+		PassiveStrategy: strategy.NewPassiveStrategy("s1"),
+		buyOn:           make(map[int]bool),
+		pos:             map[string]int64{"ag2502": 0},
+	}
+	for _, n := range buyOn {
+		s.buyOn[n] = true
+	}
+	if err := s.Initialize(&strategy.StrategyConfig{StrategyID: "s1", StrategyType: "passive", Symbols: []string{"ag2502"}}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func (s *scriptedStrategy) OnMarketData(md *mdpb.MarketDataUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n++
+	s.z = float64(s.n) * 0.1
+	if s.shiftFrom > 0 && s.n >= s.shiftFrom {
+		s.z += s.zShift
+	}
+	if s.buyOn[s.n] {
+		s.pending = append(s.pending, &strategy.TradingSignal{
+			StrategyID: "s1",
+			Symbol:     md.Symbol,
+			Side:       strategy.OrderSideBuy,
+			Price:      md.AskPrice[0],
+			Quantity:   1,
+			OrderType:  strategy.OrderTypeLimit,
+		})
+	}
+}
+
+func (s *scriptedStrategy) GetSignals() []*strategy.TradingSignal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.pending
+	s.pending = nil
+	return out
+}
+
+func (s *scriptedStrategy) OnOrderUpdate(u *orspb.OrderUpdate) {
+	if u.StrategyId != "s1" || u.LastFillQty == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u.Side == orspb.OrderSide_BUY {
+		s.pos[u.Symbol] += u.LastFillQty
+	} else {
+		s.pos[u.Symbol] -= u.LastFillQty
+	}
+}
+
+func (s *scriptedStrategy) GetPositionsBySymbol() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]int64, len(s.pos))
+	for k, v := range s.pos {
+		out[k] = v
+	}
+	return out
+}
+
+func (s *scriptedStrategy) GetSpreadStatus() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]interface{}{"z_score": s.z}
+}
+
+func TestRunner_LiveVsShadow(t *testing.T) {
+	dir := t.TempDir()
+	live := newScripted(t, 2)
+	sim := newScripted(t, 2, 3) // 影子在第 3 笔行情多下一单
+	sim.zShift, sim.shiftFrom = 1.0, 4
+
+	r, err := newRunner(Config{
+		Options: Options{
+			DecisionWindow: 2 * time.Second,
+			FillWindow:     2 * time.Second,
+			PositionGrace:  3 * time.Second,
+			Logf:           func(string, ...interface{}) {},
+		},
+		ReportDir:  dir,
+		TradingDay: func(time.Time) string { return "20250102" },
+	}, []strategy.Strategy{live}, func(strategy.Strategy) (strategy.Strategy, error) { return sim, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := live.Start(); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+
+	// 模拟 StrategyEngine：行情 → 实盘策略 → 信号（实盘按卖一价全部成交）
+	t0 := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+	for i := 1; i <= 10; i++ {
+		md := &mdpb.MarketDataUpdate{
+			Symbol:    "ag2502",
+			Timestamp: uint64(t0.Add(time.Duration(i) * time.Second).UnixNano()),
+			BidPrice:  []float64{5000},
+			BidQty:    []uint32{10},
+			AskPrice:  []float64{5001},
+			AskQty:    []uint32{10},
+		}
+		r.OnMarketData(md)
+		live.OnMarketData(md)
+		signals := live.GetSignals()
+		r.OnLiveSignals(live, md, signals)
+		for j, sig := range signals {
+			u := &orspb.OrderUpdate{
+				OrderId:       "L" + string(rune('0'+j)),
+				StrategyId:    "s1",
+				Symbol:        sig.Symbol,
+				Side:          orspb.OrderSide_BUY,
+				Status:        orspb.OrderStatus_FILLED,
+				FilledQty:     sig.Quantity,
+				LastFillQty:   sig.Quantity,
+				LastFillPrice: sig.Price,
+			}
+			live.OnOrderUpdate(u)
+			r.OnLiveOrderUpdate(u)
+		}
+	}
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	rep := r.Report()
+	st := rep.Strategies["s1"]
+	if st == nil {
+		t.Fatal("no stats for s1")
+	}
+	if st.LiveOrders != 1 || st.ShadowOrders != 2 || st.MatchedOrders != 1 {
+		t.Errorf("orders: %+v", st)
+	}
+	if st.LiveFillQty != 1 || st.ShadowFillQty != 2 || st.MatchedFillQty != 1 || st.FillPriceDiff != 0 {
+		t.Errorf("fills: %+v", st)
+	}
+	for kind, want := range map[Kind]int{OrderShadowOnly: 1, FillShadowOnly: 1, PositionMismatch: 1, SignalMismatch: 1} {
+		if got := rep.Counts[kind]; got != want {
+			t.Errorf("%s = %d, want %d (counts %v)", kind, got, want, rep.Counts)
+		}
+	}
+	if rep.Total() != 4 {
+		t.Errorf("total = %d, want 4", rep.Total())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "shadow.20250102.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"影子对比日报 20250102", "| s1 | 1 / 2 / 1 |", "order_shadow_only", "position_mismatch"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("markdown report missing %q:\n%s", want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "shadow.20250102.json")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,110 @@
+package stats
+
+import "math"
+
+// KalmanRegression 动态线性回归 y = alpha + beta * x 的卡尔曼滤波估计
+// 用于配对交易的动态对冲比率：对冲比率随时间漂移时比滚动 OLS 反应更平滑、无窗口边界效应
+//
+// 状态空间模型:
+//
+//	状态:   θ_t = [alpha_t, beta_t]'，随机游走 θ_t = θ_{t-1} + w_t, w_t ~ N(0, diag(QAlpha, QBeta))
+//	观测:   y_t = [1, x_t] · θ_t + v_t, v_t ~ N(0, R)
+//
+// 每次 Update 返回新息 e_t = y_t - (alpha + beta*x_t)（先验残差），其方差 S_t = H P H' + R。
+// e_t / sqrt(S_t) 在模型正确时近似标准正态，可直接作为价差 z-score。
+//
+// QBeta/R 越大，beta 跟随越快；QBeta → 0 时退化为递归 OLS。
+// 所有状态为定长数组，Update 无内存分配。
+type KalmanRegression struct {
+	QAlpha float64 // 截距过程噪声方差
+	QBeta  float64 // 斜率过程噪声方差
+	R      float64 // 观测噪声方差
+
+	Alpha float64
+	Beta  float64
+	P     [2][2]float64 // 状态协方差
+
+	Residual    float64 // 最近一次新息 e
+	ResidualVar float64 // 最近一次新息方差 S
+	Count       int
+}
+
+// 初始状态不确定性
+const (
+	kalmanInitAlphaVar = 1e4
+	kalmanInitBetaVar  = 1e-2
+)
+
+// NewKalmanRegression 创建卡尔曼回归，beta 初值为 initBeta（配对通常为 1）
+func NewKalmanRegression(qAlpha, qBeta, r, initBeta float64) *KalmanRegression {
+	k := &KalmanRegression{
+		QAlpha: qAlpha,
+		QBeta:  qBeta,
+		R:      r,
+	}
+	k.Reset(initBeta)
+	return k
+}
+
+// Reset 重置状态，beta 回到 initBeta
+func (k *KalmanRegression) Reset(initBeta float64) {
+	k.Alpha = 0
+	k.Beta = initBeta
+	k.P = [2][2]float64{{kalmanInitAlphaVar, 0}, {0, kalmanInitBetaVar}}
+	k.Residual = 0
+	k.ResidualVar = 0
+	k.Count = 0
+}
+
+// Update 输入一组观测 (x, y)，返回新息 e
+func (k *KalmanRegression) Update(x, y float64) float64 {
+	if k.Count == 0 {
+		// 首次观测：截距锚定到当前水平，避免大的初始新息
+		k.Alpha = y - k.Beta*x
+	}
+	k.Count++
+
+	// 预测: P = P + Q
+	k.P[0][0] += k.QAlpha
+	k.P[1][1] += k.QBeta
+
+	// 新息
+	e := y - (k.Alpha + k.Beta*x)
+	ph0 := k.P[0][0] + k.P[0][1]*x // P H'
+	ph1 := k.P[1][0] + k.P[1][1]*x
+	s := ph0 + ph1*x + k.R
+	if s <= 0 || math.IsNaN(s) || math.IsInf(s, 0) {
+		return 0
+	}
+
+	// 更新: θ += K e, P -= K (H P)
+	k0 := ph0 / s
+	k1 := ph1 / s
+	k.Alpha += k0 * e
+	k.Beta += k1 * e
+	k.P[0][0] -= k0 * ph0
+	k.P[0][1] -= k0 * ph1
+	k.P[1][0] -= k1 * ph0
+	k.P[1][1] -= k1 * ph1
+	// 保持对称
+	off := 0.5 * (k.P[0][1] + k.P[1][0])
+	k.P[0][1] = off
+	k.P[1][0] = off
+
+	k.Residual = e
+	k.ResidualVar = s
+	return e
+}
+
+// Spread 返回按当前估计计算的价差残差 y - alpha - beta*x（后验）
+func (k *KalmanRegression) Spread(x, y float64) float64 {
+	return y - k.Alpha - k.Beta*x
+}
+
+// ZScore 返回最近一次新息的标准化值 e / sqrt(S)
+func (k *KalmanRegression) ZScore() float64 {
+	if k.ResidualVar <= 0 {
+		return 0
+	}
+	return k.Residual / math.Sqrt(k.ResidualVar)
+}
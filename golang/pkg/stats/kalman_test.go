@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// kalmanNoise 确定性近似正态噪声（xorshift32，12 个均匀分布求和）
+func kalmanNoise(n int, seed uint32) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		s := 0.0
+		for j := 0; j < 12; j++ {
+			seed ^= seed << 13
+			seed ^= seed >> 17
+			seed ^= seed << 5
+			s += float64(seed) / float64(math.MaxUint32)
+		}
+		out[i] = s - 6
+	}
+	return out
+}
+
+func TestKalmanRegression_StaticBeta(t *testing.T) {
+	kf := NewKalmanRegression(1e-4, 1e-8, 1.0, 1.0)
+	walk := kalmanNoise(3000, 3)
+	noise := kalmanNoise(3000, 5)
+
+	x := 5000.0
+	for i := range walk {
+		x += 5 * walk[i]
+		y := 20 + 1.05*x + noise[i]
+		kf.Update(x, y)
+	}
+
+	if !almostEqual(kf.Beta, 1.05, 0.005) {
+		t.Errorf("Beta = %v, want 1.05", kf.Beta)
+	}
+	if !almostEqual(kf.Spread(x, 20+1.05*x), 0, 3) {
+		t.Errorf("Spread = %v, want ~0", kf.Spread(x, 20+1.05*x))
+	}
+	// 新息标准差应接近观测噪声
+	if kf.ResidualVar < 0.9 || kf.ResidualVar > 2 {
+		t.Errorf("ResidualVar = %v, want ~1", kf.ResidualVar)
+	}
+}
+
+func TestKalmanRegression_TracksDrift(t *testing.T) {
+	kf := NewKalmanRegression(1e-4, 1e-8, 1.0, 1.0)
+	walk := kalmanNoise(4000, 7)
+	noise := kalmanNoise(4000, 11)
+
+	x := 5000.0
+	beta := 1.0
+	sumZ2 := 0.0
+	for i := range walk {
+		beta = 1.0 + 0.03*float64(i)/float64(len(walk)) // 对冲比率日内漂移
+		x += 5 * walk[i]
+		kf.Update(x, beta*x+noise[i])
+		if i >= len(walk)/2 {
+			sumZ2 += kf.ZScore() * kf.ZScore()
+		}
+	}
+
+	if !almostEqual(kf.Beta, beta, 0.005) {
+		t.Errorf("Beta = %v, want %v", kf.Beta, beta)
+	}
+	// 标准化新息方差 ≈ 1
+	if z2 := sumZ2 / float64(len(walk)/2); z2 < 0.5 || z2 > 1.5 {
+		t.Errorf("mean z^2 = %v, want ~1", z2)
+	}
+}
+
+func TestKalmanRegression_FirstObservation(t *testing.T) {
+	kf := NewKalmanRegression(1e-4, 1e-8, 1.0, 1.0)
+	e := kf.Update(5000, 5012)
+	if !almostEqual(e, 0, 1e-9) || !almostEqual(kf.Alpha, 12, 1e-9) {
+		t.Errorf("first update: e = %v, Alpha = %v, want 0, 12", e, kf.Alpha)
+	}
+	if kf.P[0][1] != kf.P[1][0] {
+		t.Errorf("P not symmetric: %v", kf.P)
+	}
+
+	kf.Reset(1.0)
+	if kf.Count != 0 || kf.Beta != 1.0 || kf.Alpha != 0 {
+		t.Errorf("Reset: Count = %d, Beta = %v, Alpha = %v", kf.Count, kf.Beta, kf.Alpha)
+	}
+}
@@ -0,0 +1,106 @@
+package stats
+
+import "math"
+
+// MultipleRegression 多元线性回归 y = intercept + Σ coef_j * xs[j]
+// xs 为 k 个自变量序列，每个与 y 等长。先去均值再解正规方程 (X'X)β = X'y（SolveLinear），
+// 价格量级较大时也不会因截距列导致病态。样本数不足、自变量共线时返回 ok = false
+func MultipleRegression(xs [][]float64, y []float64) (coef []float64, intercept float64, ok bool) {
+	k, n := len(xs), len(y)
+	if k == 0 || n <= k {
+		return nil, 0, false
+	}
+	for _, x := range xs {
+		if len(x) != n {
+			return nil, 0, false
+		}
+	}
+
+	meanY := Mean(y)
+	meanX := make([]float64, k)
+	for j, x := range xs {
+		meanX[j] = Mean(x)
+	}
+
+	// 正规方程 X'X β = X'y（去均值）
+	a := make([][]float64, k)
+	for i := range a {
+		a[i] = make([]float64, k)
+	}
+	b := make([]float64, k)
+	for t := 0; t < n; t++ {
+		dy := y[t] - meanY
+		for i := 0; i < k; i++ {
+			di := xs[i][t] - meanX[i]
+			for j := i; j < k; j++ {
+				a[i][j] += di * (xs[j][t] - meanX[j])
+			}
+			b[i] += di * dy
+		}
+	}
+	scale := 0.0
+	for i := 0; i < k; i++ {
+		for j := 0; j < i; j++ {
+			a[i][j] = a[j][i]
+		}
+		scale = math.Max(scale, a[i][i])
+	}
+	if scale < 1e-10 {
+		return nil, 0, false
+	}
+
+	coef, ok = SolveLinear(a, b, 1e-10*scale)
+	if !ok {
+		return nil, 0, false
+	}
+	intercept = meanY
+	for j := range coef {
+		intercept -= coef[j] * meanX[j]
+	}
+	return coef, intercept, true
+}
+
+// SolveLinear 部分主元高斯消元求解 A x = b（A 为 n×n，不修改 A、b）
+// 消元中主元绝对值低于 tol 视为奇异，返回 ok = false
+func SolveLinear(a [][]float64, b []float64, tol float64) (x []float64, ok bool) {
+	n := len(b)
+	if len(a) != n {
+		return nil, false
+	}
+	m := make([][]float64, n)
+	for i := range a {
+		if len(a[i]) != n {
+			return nil, false
+		}
+		m[i] = make([]float64, n+1)
+		copy(m[i], a[i])
+		m[i][n] = b[i]
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(m[r][col]) > math.Abs(m[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(m[pivot][col]) < tol {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for r := col + 1; r < n; r++ {
+			f := m[r][col] / m[col][col]
+			for c := col; c <= n; c++ {
+				m[r][c] -= f * m[col][c]
+			}
+		}
+	}
+	x = make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		sum := m[r][n]
+		for c := r + 1; c < n; c++ {
+			sum -= m[r][c] * x[c]
+		}
+		x[r] = sum / m[r][r]
+	}
+	return x, true
+}
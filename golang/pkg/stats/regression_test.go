@@ -0,0 +1,86 @@
+package stats
+
+import "testing"
+
+func TestMultipleRegression(t *testing.T) {
+	n1 := kalmanNoise(500, 7)
+	n2 := kalmanNoise(500, 11)
+	eps := kalmanNoise(500, 13)
+
+	// 同品种三个月份：y = 30 + 0.6*x1 + 0.4*x2 + 噪声
+	x1, x2 := make([]float64, 500), make([]float64, 500)
+	y := make([]float64, 500)
+	p1, p2 := 5000.0, 5100.0
+	for i := range y {
+		p1 += 5 * n1[i]
+		p2 += 5 * n2[i]
+		x1[i], x2[i] = p1, p2
+		y[i] = 30 + 0.6*p1 + 0.4*p2 + 0.5*eps[i]
+	}
+
+	coef, intercept, ok := MultipleRegression([][]float64{x1, x2}, y)
+	if !ok {
+		t.Fatal("regression failed")
+	}
+	if !almostEqual(coef[0], 0.6, 0.01) || !almostEqual(coef[1], 0.4, 0.01) {
+		t.Errorf("coef = %v, want [0.6 0.4]", coef)
+	}
+	if !almostEqual(intercept+coef[0]*x1[0]+coef[1]*x2[0], y[0], 2) {
+		t.Errorf("intercept = %v does not fit first sample", intercept)
+	}
+
+	// 单变量时与 LinearRegression 一致
+	coef, intercept, ok = MultipleRegression([][]float64{x1}, y)
+	slope, icpt := LinearRegression(x1, y)
+	if !ok || !almostEqual(coef[0], slope, 1e-9) || !almostEqual(intercept, icpt, 1e-6) {
+		t.Errorf("single = %v %v, want %v %v", coef, intercept, slope, icpt)
+	}
+}
+
+func TestMultipleRegression_Degenerate(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	y := []float64{2, 4, 6, 8, 10}
+
+	// 共线自变量
+	if _, _, ok := MultipleRegression([][]float64{x, x}, y); ok {
+		t.Error("expected failure for collinear regressors")
+	}
+	// 常数自变量
+	if _, _, ok := MultipleRegression([][]float64{{3, 3, 3, 3, 3}}, y); ok {
+		t.Error("expected failure for constant regressor")
+	}
+	// 样本不足、长度不一致
+	if _, _, ok := MultipleRegression([][]float64{{1, 2}, {3, 4}}, []float64{1, 2}); ok {
+		t.Error("expected failure for too few samples")
+	}
+	if _, _, ok := MultipleRegression([][]float64{{1, 2, 3}}, y); ok {
+		t.Error("expected failure for length mismatch")
+	}
+}
+
+func TestSolveLinear(t *testing.T) {
+	// 首列主元为 0，需要换行
+	a := [][]float64{{0, 2, 1}, {1, 1, 0}, {3, 0, 2}}
+	b := []float64{5, 3, 5}
+	x, ok := SolveLinear(a, b, 1e-12)
+	if !ok {
+		t.Fatal("solve failed")
+	}
+	for i, want := range []float64{1, 2, 1} {
+		if !almostEqual(x[i], want, 1e-12) {
+			t.Errorf("x = %v, want [1 2 1]", x)
+			break
+		}
+	}
+	if a[0][0] != 0 || b[0] != 5 {
+		t.Error("inputs were modified")
+	}
+
+	// 奇异、维度不一致
+	if _, ok := SolveLinear([][]float64{{1, 2}, {2, 4}}, []float64{1, 2}, 1e-12); ok {
+		t.Error("expected failure for singular matrix")
+	}
+	if _, ok := SolveLinear([][]float64{{1, 2}}, []float64{1, 2}, 1e-12); ok {
+		t.Error("expected failure for dimension mismatch")
+	}
+}
@@ -443,7 +443,6 @@ func (as *AggressiveStrategy) Stop() error {
 // AggressiveStrategy ignores auction data by default
 func (as *AggressiveStrategy) OnAuctionData(md *mdpb.MarketDataUpdate) {
 	// Aggressive strategy does not trade during auction periods
-	log.Printf("[AggressiveStrategy:%s] Ignoring auction data for %s", as.ID, md.Symbol)
 }
 
 // === Strategy 接口方法 ===
@@ -0,0 +1,66 @@
+package strategy
+
+import (
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+
+	"tbsrc-golang/pkg/auction"
+)
+
+// 集合竞价（Go 扩展）
+//
+// StrategyEngine 把集合竞价行情路由到 OnAuctionData，连续交易行情路由到 OnMarketData。
+// 识别依据（任一满足即为竞价行情）：
+//   - feed_type == AUCTION（行情源明确标注）
+//   - 交易日历判定该合约处于集合竞价时段（SetAuctionPhaseFunc，08:55-09:00 / 20:55-21:00 等）
+//   - 盘口交叉（买一 >= 卖一），连续交易中不会出现，只出现在竞价的指示性盘口中
+
+// AuctionPhaseFunc 按交易日历判断合约在时刻 t 是否处于集合竞价时段
+type AuctionPhaseFunc func(symbol string, t time.Time) bool
+
+// AuctionPhaseSetter 可选接口：自行接收行情的策略（如 tbsrc 桥接）使用引擎的竞价时段判断
+type AuctionPhaseSetter interface {
+	SetAuctionPhaseFunc(fn AuctionPhaseFunc)
+}
+
+// IsAuctionData 判断行情是否为集合竞价行情，phase 可为 nil
+func IsAuctionData(md *mdpb.MarketDataUpdate, phase AuctionPhaseFunc) bool {
+	if md.GetFeedType() == mdpb.FeedType_AUCTION {
+		return true
+	}
+	if phase != nil && phase(md.GetSymbol(), MarketDataTime(md)) {
+		return true
+	}
+	return len(md.BidPrice) > 0 && len(md.AskPrice) > 0 && auction.Crossed(md.BidPrice[0], md.AskPrice[0])
+}
+
+// MarketDataTime 行情时间：优先交易所时间戳
+func MarketDataTime(md *mdpb.MarketDataUpdate) time.Time {
+	if ts := md.GetExchangeTimestamp(); ts > 0 {
+		return time.Unix(0, int64(ts))
+	}
+	return time.Unix(0, int64(md.GetTimestamp()))
+}
+
+// IndicativeAuction 由指示性盘口计算集合竞价均衡价和失衡量
+// 参考价取最新价，无最新价时取昨收
+func IndicativeAuction(md *mdpb.MarketDataUpdate) auction.Result {
+	bids := make([]auction.Level, 0, len(md.BidPrice))
+	for i, p := range md.BidPrice {
+		if i < len(md.BidQty) {
+			bids = append(bids, auction.Level{Price: p, Qty: int64(md.BidQty[i])})
+		}
+	}
+	asks := make([]auction.Level, 0, len(md.AskPrice))
+	for i, p := range md.AskPrice {
+		if i < len(md.AskQty) {
+			asks = append(asks, auction.Level{Price: p, Qty: int64(md.AskQty[i])})
+		}
+	}
+	ref := md.GetLastPrice()
+	if ref <= 0 {
+		ref = md.GetPreClosePrice()
+	}
+	return auction.Uncross(bids, asks, ref)
+}
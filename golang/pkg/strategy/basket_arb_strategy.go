@@ -0,0 +1,1301 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/indicators"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/stats"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy/spread"
+
+	"tbsrc-golang/pkg/execution"
+	"tbsrc-golang/pkg/types"
+)
+
+// BasketArbStrategyType 篮子协整统计套利策略类型名
+const BasketArbStrategyType = "basket_arb"
+
+// basketAckTimeout 腿单发出后迟迟没有回报（发送失败/被本地风控拦截）时视为未发出
+const basketAckTimeout = 5 * time.Second
+
+// BasketArbParams 篮子套利参数，热更新时整体校验后替换
+type BasketArbParams struct {
+	Lookback           int64   // lookback 协整估计窗口（采样点数）
+	SampleMs           int64   // sample_ms 按行情时间计的采样间隔，0 表示每笔行情采样
+	LogPrices          bool    // log_prices 对数价格回归（跨品种），否则价格回归（同品种跨期）
+	EntryZ             float64 // entry_zscore 残差 |z| 达到时开仓
+	ExitZ              float64 // exit_zscore 残差回到 |z| <= exit 时平仓
+	StopZ              float64 // stop_zscore 持仓方向 |z| 达到时止损，止损后 |z| 回到 exit 以内才允许再开仓
+	RebalanceSamples   int64   // rebalance_samples 每隔多少采样点重估协整向量，0 表示只估计一次
+	RebalanceThreshold float64 // rebalance_threshold 新权重相对变化达到此比例才采用（迟滞）
+	CointThreshold     float64 // coint_threshold 协整得分（残差半衰期 / 窗口）低于此值才允许开仓
+	UnitLots           int64   // unit_lots 每单位篮子锚定腿（第一腿）手数，其余腿按权重折算
+	SlippageTicks      float64 // slippage_ticks 对价基础上让出的 tick 数
+	ChaseMs            int64   // chase_ms 腿单按行情时间超过此值未完成则撤单按新对价重发
+	HedgeMaxSlip       int32   // hedge_max_slip 追单价不超过本轮首单对价 ± N tick，0 表示不限制
+	LegTimeoutMs       int64   // leg_timeout_ms 调仓后各腿超过此值仍未配齐则放弃本次调仓回到空仓，0 表示不限制
+
+	// 固定权重价差（蝶式、跨品种组合），仅 Initialize 时读取
+	Weights    []float64 // weights 各腿价差系数，与 symbols 一一对应；非空时不做协整估计
+	Ratios     []int64   // ratios 每单位价差各腿手数（带符号），空表示取 round(weight)
+	SpreadType string    // spread_type 固定权重价差形式：difference / ratio / log
+}
+
+// DefaultBasketArbParams 默认篮子套利参数
+func DefaultBasketArbParams() BasketArbParams {
+	return BasketArbParams{
+		Lookback:           300,
+		SampleMs:           1000,
+		EntryZ:             2.0,
+		ExitZ:              0.5,
+		StopZ:              4.0,
+		RebalanceSamples:   60,
+		RebalanceThreshold: 0.05,
+		CointThreshold:     0.3,
+		UnitLots:           1,
+		SlippageTicks:      0,
+		ChaseMs:            2000,
+	}
+}
+
+// load 从参数表读取（YAML/JSON 数值均为 float64），返回是否有识别的参数
+func (p *BasketArbParams) load(params map[string]interface{}) bool {
+	found := false
+	setFloat := func(key string, dst *float64) {
+		switch v := params[key].(type) {
+		case float64:
+			*dst = v
+			found = true
+		case int:
+			*dst = float64(v)
+			found = true
+		case int64:
+			*dst = float64(v)
+			found = true
+		}
+	}
+	setInt := func(key string, dst *int64) {
+		switch v := params[key].(type) {
+		case float64:
+			*dst = int64(v)
+			found = true
+		case int:
+			*dst = int64(v)
+			found = true
+		case int64:
+			*dst = v
+			found = true
+		}
+	}
+	setInt("lookback", &p.Lookback)
+	setInt("sample_ms", &p.SampleMs)
+	if v, ok := params["log_prices"].(bool); ok {
+		p.LogPrices = v
+		found = true
+	}
+	setFloat("entry_zscore", &p.EntryZ)
+	setFloat("exit_zscore", &p.ExitZ)
+	setFloat("stop_zscore", &p.StopZ)
+	setInt("rebalance_samples", &p.RebalanceSamples)
+	setFloat("rebalance_threshold", &p.RebalanceThreshold)
+	setFloat("coint_threshold", &p.CointThreshold)
+	setInt("unit_lots", &p.UnitLots)
+	setFloat("slippage_ticks", &p.SlippageTicks)
+	setInt("chase_ms", &p.ChaseMs)
+	maxSlip := int64(p.HedgeMaxSlip)
+	setInt("hedge_max_slip", &maxSlip)
+	p.HedgeMaxSlip = int32(maxSlip)
+	setInt("leg_timeout_ms", &p.LegTimeoutMs)
+	if v, ok := params["weights"].([]interface{}); ok {
+		p.Weights = make([]float64, 0, len(v))
+		for _, x := range v {
+			if f, ok := toFloat(x); ok {
+				p.Weights = append(p.Weights, f)
+			}
+		}
+		found = true
+	}
+	if v, ok := params["ratios"].([]interface{}); ok {
+		p.Ratios = make([]int64, 0, len(v))
+		for _, x := range v {
+			if f, ok := toFloat(x); ok {
+				p.Ratios = append(p.Ratios, int64(f))
+			}
+		}
+		found = true
+	}
+	if v, ok := params["spread_type"].(string); ok {
+		p.SpreadType = v
+		found = true
+	}
+	return found
+}
+
+// toFloat YAML/JSON 数值转 float64
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// validate 参数合法性检查
+func (p *BasketArbParams) validate() error {
+	switch {
+	case p.Lookback < 20:
+		return fmt.Errorf("invalid lookback (%d), must be >= 20", p.Lookback)
+	case p.SampleMs < 0:
+		return fmt.Errorf("invalid sample_ms (%d), must be >= 0", p.SampleMs)
+	case p.ExitZ < 0:
+		return fmt.Errorf("invalid exit_zscore (%.2f), must be >= 0", p.ExitZ)
+	case p.EntryZ <= p.ExitZ:
+		return fmt.Errorf("invalid entry_zscore (%.2f), must be > exit_zscore (%.2f)", p.EntryZ, p.ExitZ)
+	case p.StopZ != 0 && p.StopZ <= p.EntryZ:
+		return fmt.Errorf("invalid stop_zscore (%.2f), must be 0 or > entry_zscore (%.2f)", p.StopZ, p.EntryZ)
+	case p.RebalanceSamples < 0:
+		return fmt.Errorf("invalid rebalance_samples (%d), must be >= 0", p.RebalanceSamples)
+	case p.RebalanceThreshold < 0:
+		return fmt.Errorf("invalid rebalance_threshold (%.4f), must be >= 0", p.RebalanceThreshold)
+	case p.CointThreshold <= 0:
+		return fmt.Errorf("invalid coint_threshold (%.4f), must be > 0", p.CointThreshold)
+	case p.UnitLots <= 0:
+		return fmt.Errorf("invalid unit_lots (%d), must be > 0", p.UnitLots)
+	case p.SlippageTicks < 0:
+		return fmt.Errorf("invalid slippage_ticks (%.1f), must be >= 0", p.SlippageTicks)
+	case p.ChaseMs < 0:
+		return fmt.Errorf("invalid chase_ms (%d), must be >= 0", p.ChaseMs)
+	case p.HedgeMaxSlip < 0:
+		return fmt.Errorf("invalid hedge_max_slip (%d), must be >= 0", p.HedgeMaxSlip)
+	case p.LegTimeoutMs < 0:
+		return fmt.Errorf("invalid leg_timeout_ms (%d), must be >= 0", p.LegTimeoutMs)
+	case len(p.Ratios) > 0 && len(p.Ratios) != len(p.Weights):
+		return fmt.Errorf("invalid ratios (%d legs), must match weights (%d legs)", len(p.Ratios), len(p.Weights))
+	}
+	switch spread.SpreadType(p.SpreadType) {
+	case "", spread.SpreadTypeDifference, spread.SpreadTypeRatio, spread.SpreadTypeLog:
+	default:
+		return fmt.Errorf("invalid spread_type (%s), must be difference, ratio or log", p.SpreadType)
+	}
+	return nil
+}
+
+// toMap 当前参数（GetCurrentParameters）
+func (p *BasketArbParams) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"lookback":            p.Lookback,
+		"sample_ms":           p.SampleMs,
+		"log_prices":          p.LogPrices,
+		"entry_zscore":        p.EntryZ,
+		"exit_zscore":         p.ExitZ,
+		"stop_zscore":         p.StopZ,
+		"rebalance_samples":   p.RebalanceSamples,
+		"rebalance_threshold": p.RebalanceThreshold,
+		"coint_threshold":     p.CointThreshold,
+		"unit_lots":           p.UnitLots,
+		"slippage_ticks":      p.SlippageTicks,
+		"chase_ms":            p.ChaseMs,
+		"hedge_max_slip":      p.HedgeMaxSlip,
+		"leg_timeout_ms":      p.LegTimeoutMs,
+		"weights":             p.Weights,
+		"ratios":              p.Ratios,
+		"spread_type":         p.SpreadType,
+	}
+}
+
+// sameFixedSpread 固定权重价差配置是否相同（不支持热更新）
+func (p *BasketArbParams) sameFixedSpread(o BasketArbParams) bool {
+	if p.SpreadType != o.SpreadType || len(p.Weights) != len(o.Weights) || len(p.Ratios) != len(o.Ratios) {
+		return false
+	}
+	for i := range p.Weights {
+		if p.Weights[i] != o.Weights[i] {
+			return false
+		}
+	}
+	for i := range p.Ratios {
+		if p.Ratios[i] != o.Ratios[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// basketLeg 单腿行情、持仓与在途腿单
+// 开平拆单时一笔腿单可能对应多个 order_id
+type basketLeg struct {
+	symbol   string
+	exchange string
+	tickSize float64
+	mult     float64
+
+	bid, ask float64
+
+	target   int64   // 目标手数（带符号）
+	perUnit  float64 // 每单位篮子的手数（带符号，按权重折算）
+	pos      int64
+	avgPrice float64 // 当前持仓均价
+	realized float64
+
+	// 在途腿单
+	side       orspb.OrderSide
+	qty        int64
+	filled     int64
+	orders     []string
+	pending    bool // 信号已发出，尚未收到回报
+	cancelling bool // 已要求撤单，之后到达的 order_id 也立即撤
+	sentAt     time.Time
+	sentMdAt   time.Time
+	refPx      float64 // 本轮调仓首笔腿单的对价（hedge_max_slip 基准），0 表示已配齐
+}
+
+func (l *basketLeg) active() bool {
+	return l.pending || len(l.orders) > 0
+}
+
+func (l *basketLeg) mid() float64 {
+	if l.bid <= 0 || l.ask <= 0 {
+		return 0
+	}
+	return (l.bid + l.ask) / 2
+}
+
+// remaining 在途腿单未成交部分（带符号）
+func (l *basketLeg) remaining() int64 {
+	if !l.active() {
+		return 0
+	}
+	if l.side == orspb.OrderSide_BUY {
+		return l.qty - l.filled
+	}
+	return l.filled - l.qty
+}
+
+// BasketLegSnapshot 单腿明细
+type BasketLegSnapshot struct {
+	Symbol        string  `json:"symbol"`
+	Weight        float64 `json:"weight"`   // 协整向量系数（锚定腿为 1）
+	PerUnit       float64 `json:"per_unit"` // 每单位篮子手数
+	Target        int64   `json:"target"`
+	Position      int64   `json:"position"`
+	AvgPrice      float64 `json:"avg_price"`
+	Price         float64 `json:"price"`
+	Exposure      float64 `json:"exposure"` // 货值敞口（带符号）
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+}
+
+// BasketSnapshot 篮子层面的状态与敞口
+type BasketSnapshot struct {
+	StrategyID    string              `json:"strategy_id"`
+	Ready         bool                `json:"ready"` // 已估计出协整向量
+	Cointegrated  bool                `json:"cointegrated"`
+	Units         int64               `json:"units"` // 目标篮子单位数（+1 多残差 / -1 空残差）
+	Stopped       bool                `json:"stopped"`
+	Intercept     float64             `json:"intercept"`
+	Residual      float64             `json:"residual"`
+	ZScore        float64             `json:"zscore"`
+	HalfLife      float64             `json:"half_life"`   // 残差半衰期（采样点数）
+	CointScore    float64             `json:"coint_score"` // 半衰期 / 窗口
+	Rebalances    int                 `json:"rebalances"`
+	NetExposure   float64             `json:"net_exposure"`
+	GrossExposure float64             `json:"gross_exposure"`
+	Legs          []BasketLegSnapshot `json:"legs"`
+}
+
+// BasketArbStrategy 篮子协整统计套利策略（Go 扩展）
+// N 个相关合约（同品种多个月份或相关品种）按 Engle-Granger 两步法估计协整向量：
+// 第一腿（锚定腿）对其余各腿做多元回归 x_0 = α + Σ β_i x_i，协整向量 w = [1, -β_1, ...]，
+// 残差 e = Σ w_i x_i - α。用 CointegrationIndicator 以锚定腿对拟合值检验残差的均值回归（半衰期），
+// 得分低于 coint_threshold 才允许开仓。
+//
+// 残差 z-score ≥ entry 空篮子、≤ -entry 多篮子；回到 exit 以内平仓；持仓方向 |z| ≥ stop 止损，
+// 止损后须等 |z| 回到 exit 以内才允许再开仓。每 rebalance_samples 个采样点重估一次协整向量，
+// 新权重相对变化超过 rebalance_threshold 才采用（迟滞，避免估计噪声引起来回调仓），
+// 采用后重算残差统计，持仓中各腿按新权重调整到目标手数。
+//
+// 每腿同一时间最多一笔腿单在途，以对价（± slippage_ticks）主动成交，超过 chase_ms 未完成撤单重发，
+// 重发价不超过本轮首单对价 ± hedge_max_slip tick（与 tbsrc SpreadExecutor 共用 execution.HedgePrice）；
+// 调仓后超过 leg_timeout_ms 仍有腿未配齐时放弃本次调仓回到空仓，按止损处理等 |z| 回到 exit 以内。
+// 风控以篮子整体计：ExposureValue 为各腿净货值敞口（对冲后）的绝对值，GrossExposure 为总货值
+//
+// 配置 weights 时为固定权重价差（日历蝶式 +1/-2/+1、钢厂利润 rb/i/j 等）：不做协整估计和重估，
+// 价差按 spread_type 计算，由 spread.MultiLegAnalyzer 维护 lookback 窗口的均值/标准差，
+// 每单位篮子各腿手数为 unit_lots × ratio，不受 coint_threshold 限制
+type BasketArbStrategy struct {
+	*StrategyDataContext
+
+	mu     sync.Mutex
+	params BasketArbParams
+	now    func() time.Time
+
+	legs     []*basketLeg
+	legIndex map[string]int
+	def      *spread.Definition // 当前协整向量，nil 表示尚未估计
+	alpha    float64
+
+	// 采样与残差
+	samples      [][]float64 // 各腿中间价，按采样时间顺序
+	residuals    []float64   // 与 samples 对齐，按当前协整向量计算
+	resMean      float64
+	resStd       float64
+	coint        *indicators.CointegrationIndicator
+	fixed        *spread.MultiLegAnalyzer // 固定权重价差统计，nil 表示协整模式
+	lastSampleAt time.Time
+	sinceFit     int64
+	rebalances   int
+
+	units    int64
+	stopped  bool
+	zscore   float64
+	leggedAt time.Time // 出现未配齐腿的行情时间，零值表示各腿已配齐
+
+	orderLeg    map[string]int
+	orderFilled map[string]int64
+	orderValue  map[string]float64
+	orderDone   map[string]bool
+	cancels     []*orspb.OrderUpdate
+
+	estimatedPosition *EstimatedPosition
+	pnl               *PNL
+	riskMetrics       *RiskMetrics
+}
+
+// NewBasketArbStrategy 创建篮子套利策略
+func NewBasketArbStrategy(id string) *BasketArbStrategy {
+	s := &BasketArbStrategy{
+		StrategyDataContext: NewStrategyDataContext(id, BasketArbStrategyType),
+		params:              DefaultBasketArbParams(),
+		now:                 time.Now,
+		legIndex:            make(map[string]int),
+		orderLeg:            make(map[string]int),
+		orderFilled:         make(map[string]int64),
+		orderValue:          make(map[string]float64),
+		orderDone:           make(map[string]bool),
+		estimatedPosition:   &EstimatedPosition{},
+		pnl:                 &PNL{},
+		riskMetrics:         &RiskMetrics{},
+	}
+	s.StrategyDataContext.SetConcreteStrategy(s)
+	return s
+}
+
+// Initialize 读取参数，建立各腿
+func (s *BasketArbStrategy) Initialize(config *StrategyConfig) error {
+	if len(config.Symbols) < 2 {
+		return fmt.Errorf("basket_arb requires at least 2 symbols, got %d", len(config.Symbols))
+	}
+	params := DefaultBasketArbParams()
+	params.load(config.Parameters)
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	s.Config = config
+	s.params = params
+	s.legs = make([]*basketLeg, len(config.Symbols))
+	s.legIndex = make(map[string]int, len(config.Symbols))
+	for i, sym := range config.Symbols {
+		if _, dup := s.legIndex[sym]; dup {
+			return fmt.Errorf("basket_arb: duplicate symbol %s", sym)
+		}
+		leg := &basketLeg{symbol: sym, tickSize: GetTickSize(sym), mult: GetContractMultiplier(sym)}
+		if i < len(config.Exchanges) {
+			leg.exchange = config.Exchanges[i]
+		} else if len(config.Exchanges) > 0 {
+			leg.exchange = config.Exchanges[0]
+		}
+		s.legs[i] = leg
+		s.legIndex[sym] = i
+	}
+	s.coint = indicators.NewCointegrationIndicator(int(params.Lookback), "", int(params.Lookback))
+	if len(params.Weights) > 0 {
+		if err := s.useFixedSpread(); err != nil {
+			return err
+		}
+	}
+	s.estimatedPosition.Symbol = s.legs[0].symbol
+	s.estimatedPosition.Exchange = s.legs[0].exchange
+
+	s.Status.StartTime = time.Now()
+	log.Printf("[BasketArb:%s] Initialized: %v lookback=%d sample=%dms entry=%.2f exit=%.2f stop=%.2f log=%v",
+		s.ID, config.Symbols, params.Lookback, params.SampleMs, params.EntryZ, params.ExitZ, params.StopZ, params.LogPrices)
+	return nil
+}
+
+// useFixedSpread 按 weights/ratios/spread_type 建立固定权重价差，各腿手数取 unit_lots × ratio
+func (s *BasketArbStrategy) useFixedSpread() error {
+	p := s.params
+	if len(p.Weights) != len(s.legs) {
+		return fmt.Errorf("basket_arb: weights has %d legs, symbols has %d", len(p.Weights), len(s.legs))
+	}
+	legs := make([]spread.Leg, len(s.legs))
+	for i, leg := range s.legs {
+		legs[i] = spread.Leg{Symbol: leg.symbol, Weight: p.Weights[i]}
+		if len(p.Ratios) > 0 {
+			legs[i].Ratio = p.Ratios[i]
+		}
+	}
+	FillLegMultipliers(legs)
+	def, err := spread.NewDefinition(s.ID, spread.SpreadType(p.SpreadType), legs)
+	if err != nil {
+		return fmt.Errorf("basket_arb: %w", err)
+	}
+	s.def, s.alpha = def, 0
+	s.fixed = spread.NewMultiLegAnalyzer(def, int(p.Lookback))
+	for i, leg := range s.legs {
+		leg.perUnit = float64(p.UnitLots * def.Legs[i].Ratio)
+	}
+	return nil
+}
+
+// tradable 是否允许开仓：固定权重价差总是允许，协整模式需通过协整检验（调用方持有 mu）
+func (s *BasketArbStrategy) tradable() bool {
+	return s.fixed != nil || s.coint.IsReady() && s.coint.IsCointegrated(s.params.CointThreshold)
+}
+
+// Start starts the strategy
+func (s *BasketArbStrategy) Start() error {
+	s.ControlState.RunState = StrategyRunStateActive
+	s.Activate()
+	log.Printf("[BasketArb:%s] Started", s.ID)
+	return nil
+}
+
+// Stop 撤销全部在途腿单后停止（持仓保留）
+func (s *BasketArbStrategy) Stop() error {
+	if !s.IsRunning() {
+		return fmt.Errorf("strategy not running")
+	}
+	s.mu.Lock()
+	s.cancelAll()
+	s.mu.Unlock()
+	s.ControlState.RunState = StrategyRunStateStopped
+	s.Deactivate()
+	return nil
+}
+
+// OnMarketData 更新腿行情，采样、重估协整向量，按残差 z-score 调整篮子持仓
+func (s *BasketArbStrategy) OnMarketData(md *mdpb.MarketDataUpdate) {
+	if !s.IsRunning() || len(md.BidPrice) == 0 || len(md.AskPrice) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.legIndex[md.Symbol]
+	if !ok {
+		return
+	}
+	s.legs[i].bid, s.legs[i].ask = md.BidPrice[0], md.AskPrice[0]
+	mids, ok := s.mids()
+	if !ok {
+		return
+	}
+	s.updatePNL(mids)
+
+	mdTime := MarketDataTime(md)
+	if s.lastSampleAt.IsZero() || mdTime.Sub(s.lastSampleAt) >= time.Duration(s.params.SampleMs)*time.Millisecond {
+		s.lastSampleAt = mdTime
+		s.addSample(mids, mdTime)
+	}
+	if s.def == nil {
+		return
+	}
+
+	residual, _ := s.def.Value(mids)
+	residual -= s.alpha
+	s.zscore = stats.ZScore(residual, s.resMean, s.resStd)
+	cointegrated := s.tradable()
+	defer s.publish(residual, cointegrated)
+
+	if s.ControlState.FlattenMode {
+		s.cancelAll()
+		return
+	}
+	if s.resStd > 1e-10 {
+		s.decide(cointegrated)
+	}
+	s.checkLegging(mdTime)
+	s.work(mdTime)
+}
+
+// mids 各腿中间价，任一腿无盘口时返回 false（调用方持有 mu）
+func (s *BasketArbStrategy) mids() ([]float64, bool) {
+	out := make([]float64, len(s.legs))
+	for i, leg := range s.legs {
+		if out[i] = leg.mid(); out[i] <= 0 {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// addSample 记录一个采样点，窗口满后估计/定期重估协整向量（调用方持有 mu）
+// 固定权重价差只更新 MultiLegAnalyzer 的窗口统计
+func (s *BasketArbStrategy) addSample(mids []float64, at time.Time) {
+	if s.fixed != nil {
+		s.fixed.UpdatePrices(mids, at.UnixNano())
+		s.fixed.UpdateStatistics(int(s.params.Lookback))
+		st := s.fixed.GetStats()
+		s.resMean, s.resStd = st.Mean, st.Std
+		return
+	}
+	s.samples = append(s.samples, mids)
+	if int64(len(s.samples)) > s.params.Lookback {
+		s.samples = s.samples[1:]
+	}
+	if s.def != nil {
+		s.residuals = append(s.residuals, s.residualOf(mids))
+		if len(s.residuals) > len(s.samples) {
+			s.residuals = s.residuals[1:]
+		}
+		s.feedCoint(mids)
+		st := stats.CalculateRollingStats(s.residuals, 0)
+		s.resMean, s.resStd = st.Mean, st.Std
+	}
+
+	s.sinceFit++
+	if int64(len(s.samples)) < s.params.Lookback {
+		return
+	}
+	if s.def == nil || (s.params.RebalanceSamples > 0 && s.sinceFit >= s.params.RebalanceSamples) {
+		s.sinceFit = 0
+		s.refit()
+	}
+}
+
+// transform 回归使用的价格（对数或原始价格）
+func (s *BasketArbStrategy) transform(p float64) float64 {
+	if s.params.LogPrices {
+		return math.Log(p)
+	}
+	return p
+}
+
+// residualOf 按当前协整向量计算残差（调用方持有 mu）
+func (s *BasketArbStrategy) residualOf(mids []float64) float64 {
+	v, _ := s.def.Value(mids)
+	return v - s.alpha
+}
+
+// feedCoint 锚定腿对其余各腿的拟合值做两序列协整检验（调用方持有 mu）
+func (s *BasketArbStrategy) feedCoint(mids []float64) {
+	y := s.transform(mids[0])
+	s.coint.UpdateWithPair(y, y-s.residualOf(mids))
+}
+
+// refit 重新估计协整向量，变化超过迟滞阈值才采用（调用方持有 mu）
+func (s *BasketArbStrategy) refit() {
+	n := len(s.legs)
+	y := make([]float64, len(s.samples))
+	xs := make([][]float64, n-1)
+	for j := range xs {
+		xs[j] = make([]float64, len(s.samples))
+	}
+	for t, mids := range s.samples {
+		y[t] = s.transform(mids[0])
+		for j := 1; j < n; j++ {
+			xs[j-1][t] = s.transform(mids[j])
+		}
+	}
+	beta, alpha, ok := stats.MultipleRegression(xs, y)
+	if !ok {
+		log.Printf("[BasketArb:%s] Cointegration fit failed (degenerate prices), keeping current weights", s.ID)
+		return
+	}
+	weights := make([]float64, n)
+	weights[0] = 1
+	for j, b := range beta {
+		if math.Abs(b) < 1e-9 {
+			log.Printf("[BasketArb:%s] Leg %s has zero weight, keeping current weights", s.ID, s.legs[j+1].symbol)
+			return
+		}
+		weights[j+1] = -b
+	}
+
+	if s.def != nil {
+		change := 0.0
+		for i := 1; i < n; i++ {
+			old := s.def.Legs[i].Weight
+			change = math.Max(change, math.Abs(weights[i]-old)/math.Max(math.Abs(old), 1e-9))
+		}
+		if change < s.params.RebalanceThreshold {
+			return
+		}
+	}
+	if err := s.adopt(weights, alpha); err != nil {
+		log.Printf("[BasketArb:%s] Rebalance rejected: %v", s.ID, err)
+	}
+}
+
+// adopt 采用新的协整向量：重算窗口残差与协整检验，按新权重折算每单位手数（调用方持有 mu）
+func (s *BasketArbStrategy) adopt(weights []float64, alpha float64) error {
+	legs := make([]spread.Leg, len(s.legs))
+	for i, leg := range s.legs {
+		ratio := int64(1)
+		if weights[i] < 0 {
+			ratio = -1
+		}
+		legs[i] = spread.Leg{Symbol: leg.symbol, Weight: weights[i], Ratio: ratio, Multiplier: leg.mult}
+	}
+	spreadType := spread.SpreadTypeDifference
+	if s.params.LogPrices {
+		spreadType = spread.SpreadTypeLog
+	}
+	def, err := spread.NewDefinition(s.ID, spreadType, legs)
+	if err != nil {
+		return err
+	}
+	s.def, s.alpha = def, alpha
+
+	s.residuals = s.residuals[:0]
+	s.coint.Reset()
+	for _, mids := range s.samples {
+		s.residuals = append(s.residuals, s.residualOf(mids))
+		s.feedCoint(mids)
+	}
+	st := stats.CalculateRollingStats(s.residuals, 0)
+	s.resMean, s.resStd = st.Mean, st.Std
+
+	// 每单位篮子手数：价格回归按合约乘数折算，对数回归按货值折算
+	last := s.samples[len(s.samples)-1]
+	anchor := s.legs[0].mult
+	if s.params.LogPrices {
+		anchor *= last[0]
+	}
+	for i, leg := range s.legs {
+		notional := leg.mult
+		if s.params.LogPrices {
+			notional *= last[i]
+		}
+		leg.perUnit = float64(s.params.UnitLots) * weights[i] * anchor / notional
+	}
+	s.retarget()
+	s.rebalances++
+
+	log.Printf("[BasketArb:%s] Rebalance #%d: weights=%v alpha=%.4f residual mean=%.4f std=%.4f half_life=%.1f",
+		s.ID, s.rebalances, weights, alpha, s.resMean, s.resStd, s.coint.GetValue()*float64(s.params.Lookback))
+	return nil
+}
+
+// retarget 按篮子单位数更新各腿目标手数，开始新一轮调仓（调用方持有 mu）
+func (s *BasketArbStrategy) retarget() {
+	for _, leg := range s.legs {
+		leg.target = int64(math.Round(float64(s.units) * leg.perUnit))
+		leg.refPx = 0
+	}
+}
+
+// decide 残差带状态机：开仓 / 平仓 / 止损（调用方持有 mu）
+func (s *BasketArbStrategy) decide(cointegrated bool) {
+	p, z := s.params, s.zscore
+	next := s.units
+	switch {
+	case s.units == 0:
+		if s.stopped {
+			if math.Abs(z) <= p.ExitZ {
+				s.stopped = false
+			}
+			return
+		}
+		if !cointegrated {
+			return
+		}
+		if z >= p.EntryZ {
+			next = -1
+		} else if z <= -p.EntryZ {
+			next = 1
+		}
+	case s.units > 0:
+		if p.StopZ > 0 && z <= -p.StopZ {
+			next, s.stopped = 0, true
+		} else if z >= -p.ExitZ {
+			next = 0
+		}
+	default:
+		if p.StopZ > 0 && z >= p.StopZ {
+			next, s.stopped = 0, true
+		} else if z <= p.ExitZ {
+			next = 0
+		}
+	}
+	if next == s.units {
+		return
+	}
+	log.Printf("[BasketArb:%s] Basket units %d -> %d: z=%.2f stopped=%v", s.ID, s.units, next, z, s.stopped)
+	s.units = next
+	s.retarget()
+}
+
+// checkLegging 瘸腿计时（调用方持有 mu）
+// 各腿配齐时结束本轮调仓；超过 leg_timeout_ms 仍未配齐且持有篮子时放弃本次调仓，回到空仓
+func (s *BasketArbStrategy) checkLegging(mdTime time.Time) {
+	legged := false
+	for _, leg := range s.legs {
+		if leg.pos != leg.target {
+			legged = true
+		}
+	}
+	if !legged {
+		s.leggedAt = time.Time{}
+		return
+	}
+	if s.leggedAt.IsZero() {
+		s.leggedAt = mdTime
+		return
+	}
+	if s.units == 0 || !execution.LegTimedOut(uint64(s.leggedAt.UnixNano()), uint64(mdTime.UnixNano()), s.params.LegTimeoutMs) {
+		return
+	}
+	log.Printf("[BasketArb:%s] Legs unbalanced for %v (units=%d), unwinding to flat",
+		s.ID, mdTime.Sub(s.leggedAt), s.units)
+	s.units, s.stopped = 0, true
+	s.leggedAt = mdTime
+	s.retarget()
+}
+
+// work 比较各腿目标手数与持仓+在途，发出/撤销腿单（调用方持有 mu）
+func (s *BasketArbStrategy) work(mdTime time.Time) {
+	for i, leg := range s.legs {
+		want := leg.target - leg.pos
+		if leg.active() {
+			if leg.cancelling {
+				continue
+			}
+			rem := leg.remaining()
+			stale := s.params.ChaseMs > 0 && mdTime.Sub(leg.sentMdAt) >= time.Duration(s.params.ChaseMs)*time.Millisecond
+			if stale || want*rem < 0 || absInt64(rem) > absInt64(want) {
+				s.cancelLeg(leg)
+			}
+			continue
+		}
+		if want == 0 {
+			continue
+		}
+		s.sendLeg(i, leg, want, mdTime)
+	}
+}
+
+// sendLeg 按对价（± slippage_ticks）发出腿单，价格受 hedge_max_slip 限制（调用方持有 mu）
+func (s *BasketArbStrategy) sendLeg(i int, leg *basketLeg, qty int64, mdTime time.Time) {
+	side, base, signal, tbSide := OrderSideBuy, leg.ask+s.params.SlippageTicks*leg.tickSize, 1.0, types.Buy
+	leg.side = orspb.OrderSide_BUY
+	if qty < 0 {
+		side, base, signal, tbSide = OrderSideSell, leg.bid-s.params.SlippageTicks*leg.tickSize, -1.0, types.Sell
+		leg.side = orspb.OrderSide_SELL
+		qty = -qty
+	}
+	if leg.refPx == 0 {
+		leg.refPx = base
+	}
+	price := execution.HedgePrice(tbSide, base, leg.tickSize, true, 0, 0, leg.refPx, s.params.HedgeMaxSlip)
+	leg.qty, leg.filled, leg.orders = qty, 0, nil
+	leg.pending, leg.cancelling = true, false
+	leg.sentAt, leg.sentMdAt = s.now(), mdTime
+
+	s.AddSignal(&TradingSignal{
+		StrategyID:  s.ID,
+		Symbol:      leg.symbol,
+		Exchange:    leg.exchange,
+		Side:        side,
+		Price:       price,
+		Quantity:    qty,
+		OrderType:   OrderTypeLimit,
+		TimeInForce: TimeInForceGTC,
+		Signal:      signal,
+		Confidence:  math.Min(1.0, math.Abs(s.zscore)/5.0),
+		Timestamp:   leg.sentAt,
+		Category:    SignalCategoryAggressive,
+		Metadata: map[string]interface{}{
+			"type":    "basket",
+			"leg":     i + 1,
+			"units":   s.units,
+			"target":  leg.target,
+			"z_score": s.zscore,
+		},
+	})
+}
+
+// cancelLeg 撤销一腿在途腿单（调用方持有 mu）
+func (s *BasketArbStrategy) cancelLeg(leg *basketLeg) {
+	if !leg.active() || leg.cancelling {
+		return
+	}
+	leg.cancelling = true
+	for _, oid := range leg.orders {
+		if !s.orderDone[oid] {
+			s.cancels = append(s.cancels, &orspb.OrderUpdate{OrderId: oid, Symbol: leg.symbol, StrategyId: s.ID})
+		}
+	}
+}
+
+func (s *BasketArbStrategy) cancelAll() {
+	for _, leg := range s.legs {
+		s.cancelLeg(leg)
+	}
+}
+
+// OnOrderUpdate 归属腿单、累计成交、腿单结束
+func (s *BasketArbStrategy) OnOrderUpdate(update *orspb.OrderUpdate) {
+	if update.StrategyId != s.ID || update.OrderId == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oid := update.OrderId
+	i, known := s.orderLeg[oid]
+	if !known {
+		if i, known = s.legIndex[update.Symbol]; !known {
+			return
+		}
+		s.orderLeg[oid] = i
+		if leg := s.legs[i]; leg.active() {
+			leg.orders = append(leg.orders, oid)
+			leg.pending = false
+			if leg.cancelling {
+				s.cancels = append(s.cancels, &orspb.OrderUpdate{OrderId: oid, Symbol: leg.symbol, StrategyId: s.ID})
+			}
+		}
+	}
+	leg := s.legs[i]
+	s.Orders[oid] = update
+
+	if update.FilledQty > s.orderFilled[oid] {
+		delta := update.FilledQty - s.orderFilled[oid]
+		price := update.LastFillPrice
+		if update.AvgPrice > 0 {
+			price = (update.AvgPrice*float64(update.FilledQty) - s.orderValue[oid]) / float64(delta)
+		} else if price == 0 {
+			price = update.Price
+		}
+		s.orderFilled[oid] = update.FilledQty
+		s.orderValue[oid] += price * float64(delta)
+		if leg.active() && update.Side == leg.side {
+			leg.filled += delta
+		}
+		s.applyFill(leg, update.Side, delta, price)
+		if mids, ok := s.mids(); ok {
+			s.updatePNL(mids)
+		}
+	}
+
+	switch update.Status {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_CANCELED, orspb.OrderStatus_REJECTED, orspb.OrderStatus_EXPIRED:
+	default:
+		return
+	}
+	s.orderDone[oid] = true
+	delete(s.Orders, oid)
+	for _, id := range leg.orders {
+		if !s.orderDone[id] {
+			return
+		}
+	}
+	if len(leg.orders) > 0 && !leg.pending {
+		leg.orders, leg.cancelling, leg.qty, leg.filled = nil, false, 0, 0
+	}
+}
+
+// OnTimer 腿单无回报超时
+func (s *BasketArbStrategy) OnTimer(now time.Time) {
+	if !s.IsRunning() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now = s.now()
+	for _, leg := range s.legs {
+		if leg.pending && len(leg.orders) == 0 && now.Sub(leg.sentAt) >= basketAckTimeout {
+			log.Printf("[BasketArb:%s] Leg order %s x %d got no order update in %v, treated as rejected",
+				s.ID, leg.symbol, leg.qty, basketAckTimeout)
+			leg.pending, leg.cancelling, leg.qty, leg.filled = false, false, 0, 0
+		}
+	}
+}
+
+// applyFill 单腿净持仓模型更新持仓与已实现盈亏（按合约乘数折算金额，调用方持有 mu）
+func (s *BasketArbStrategy) applyFill(leg *basketLeg, side orspb.OrderSide, qty int64, price float64) {
+	signed := qty
+	if side == orspb.OrderSide_SELL {
+		signed = -qty
+	}
+	if leg.pos != 0 && (leg.pos > 0) != (signed > 0) {
+		closed := min(absInt64(signed), absInt64(leg.pos))
+		dir := 1.0
+		if leg.pos < 0 {
+			dir = -1.0
+		}
+		leg.realized += dir * (price - leg.avgPrice) * float64(closed) * leg.mult
+		if leg.pos > 0 {
+			leg.pos -= closed
+			signed += closed
+		} else {
+			leg.pos += closed
+			signed -= closed
+		}
+		if leg.pos == 0 {
+			leg.avgPrice = 0
+		}
+	}
+	if signed != 0 {
+		held := absInt64(leg.pos)
+		leg.avgPrice = (leg.avgPrice*float64(held) + price*float64(absInt64(signed))) / float64(held+absInt64(signed))
+		leg.pos += signed
+	}
+
+	pos := s.estimatedPosition
+	if side == orspb.OrderSide_BUY {
+		pos.BuyTotalQty += qty
+		pos.BuyTotalValue += float64(qty) * price
+	} else {
+		pos.SellTotalQty += qty
+		pos.SellTotalValue += float64(qty) * price
+	}
+	s.pnl.RealizedPnL = 0
+	for _, l := range s.legs {
+		s.pnl.RealizedPnL += l.realized
+	}
+	pos.RealizedPnL = s.pnl.RealizedPnL
+	pos.LastUpdate = time.Now()
+}
+
+// updatePNL 按对手价估值各腿浮动盈亏，汇总篮子敞口（调用方持有 mu）
+// 估计持仓按持仓最大的一腿计手数、方向取锚定腿，供 RiskManager 按手数限仓
+func (s *BasketArbStrategy) updatePNL(mids []float64) {
+	qty := make([]int64, len(s.legs))
+	unrealized := 0.0
+	var maxLots, maxTarget int64
+	for i, leg := range s.legs {
+		qty[i] = leg.pos
+		switch {
+		case leg.pos > 0:
+			unrealized += (leg.bid - leg.avgPrice) * float64(leg.pos) * leg.mult
+		case leg.pos < 0:
+			unrealized += (leg.avgPrice - leg.ask) * float64(-leg.pos) * leg.mult
+		}
+		maxLots = max(maxLots, absInt64(leg.pos))
+		maxTarget = max(maxTarget, int64(math.Round(math.Abs(leg.perUnit))))
+	}
+
+	pos := s.estimatedPosition
+	pos.NetQty = maxLots
+	if s.legs[0].pos < 0 {
+		pos.NetQty = -maxLots
+	}
+	pos.BuyQty, pos.SellQty = max(pos.NetQty, 0), max(-pos.NetQty, 0)
+	pos.BuyAvgPrice, pos.SellAvgPrice = 0, 0
+	if s.legs[0].pos > 0 {
+		pos.BuyAvgPrice = s.legs[0].avgPrice
+	} else if s.legs[0].pos < 0 {
+		pos.SellAvgPrice = s.legs[0].avgPrice
+	}
+	pos.UnrealizedPnL = unrealized
+	pos.UpdateCompatibilityFields()
+
+	s.pnl.UnrealizedPnL = unrealized
+	s.pnl.TotalPnL = s.pnl.RealizedPnL + s.pnl.UnrealizedPnL
+	s.pnl.NetPnL = s.pnl.TotalPnL - s.pnl.TradingFees
+	s.pnl.Timestamp = time.Now()
+
+	rm := s.riskMetrics
+	rm.PositionSize = maxLots
+	rm.MaxPositionSize = maxTarget
+	if s.def != nil {
+		rm.ExposureValue = math.Abs(s.def.NetExposure(qty, mids))
+		rm.GrossExposure = s.def.GrossExposure(qty, mids)
+	} else {
+		net, gross := 0.0, 0.0
+		for i, leg := range s.legs {
+			net += float64(leg.pos) * mids[i] * leg.mult
+			gross += float64(absInt64(leg.pos)) * mids[i] * leg.mult
+		}
+		rm.ExposureValue, rm.GrossExposure = math.Abs(net), gross
+	}
+	if s.pnl.TotalPnL < 0 && -s.pnl.TotalPnL > rm.MaxDrawdown {
+		rm.MaxDrawdown = -s.pnl.TotalPnL
+	}
+	rm.Timestamp = time.Now()
+}
+
+// publish 发布篮子指标（前端 / WebSocket，调用方持有 mu）
+func (s *BasketArbStrategy) publish(residual float64, cointegrated bool) {
+	ind := map[string]float64{
+		"basket_zscore":         s.zscore,
+		"basket_residual":       residual,
+		"basket_units":          float64(s.units),
+		"basket_half_life":      s.coint.GetValue() * float64(s.params.Lookback),
+		"basket_coint_score":    s.coint.GetValue(),
+		"basket_rebalances":     float64(s.rebalances),
+		"basket_net_exposure":   s.riskMetrics.ExposureValue,
+		"basket_gross_exposure": s.riskMetrics.GrossExposure,
+	}
+	for i, leg := range s.legs {
+		ind[fmt.Sprintf("leg%d_position", i+1)] = float64(leg.pos)
+		ind[fmt.Sprintf("leg%d_price", i+1)] = leg.avgPrice
+		ind[fmt.Sprintf("leg%d_weight", i+1)] = s.def.Legs[i].Weight
+	}
+	s.ControlState.UpdateConditions(cointegrated && math.Abs(s.zscore) >= s.params.EntryZ, -s.zscore/s.params.EntryZ, ind)
+}
+
+// Basket 篮子层面状态与各腿敞口
+func (s *BasketArbStrategy) Basket() BasketSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap := BasketSnapshot{
+		StrategyID:    s.ID,
+		Ready:         s.def != nil,
+		Cointegrated:  s.coint != nil && s.tradable(),
+		Units:         s.units,
+		Stopped:       s.stopped,
+		Intercept:     s.alpha,
+		ZScore:        s.zscore,
+		Rebalances:    s.rebalances,
+		NetExposure:   s.riskMetrics.ExposureValue,
+		GrossExposure: s.riskMetrics.GrossExposure,
+		Legs:          make([]BasketLegSnapshot, len(s.legs)),
+	}
+	if s.coint != nil {
+		snap.CointScore = s.coint.GetValue()
+		snap.HalfLife = snap.CointScore * float64(s.params.Lookback)
+	}
+	mids, ok := s.mids()
+	if ok && s.def != nil {
+		snap.Residual = s.residualOf(mids)
+	}
+	for i, leg := range s.legs {
+		ls := BasketLegSnapshot{
+			Symbol:   leg.symbol,
+			PerUnit:  leg.perUnit,
+			Target:   leg.target,
+			Position: leg.pos,
+			AvgPrice: leg.avgPrice,
+		}
+		if s.def != nil {
+			ls.Weight = s.def.Legs[i].Weight
+		}
+		if ok {
+			ls.Price = mids[i]
+			ls.Exposure = float64(leg.pos) * mids[i] * leg.mult
+		}
+		switch {
+		case leg.pos > 0:
+			ls.UnrealizedPnL = (leg.bid - leg.avgPrice) * float64(leg.pos) * leg.mult
+		case leg.pos < 0:
+			ls.UnrealizedPnL = (leg.avgPrice - leg.ask) * float64(-leg.pos) * leg.mult
+		}
+		snap.Legs[i] = ls
+	}
+	return snap
+}
+
+// GetSignals returns pending signals and clears the queue
+func (s *BasketArbStrategy) GetSignals() []*TradingSignal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.StrategyDataContext.GetSignals()
+}
+
+// GetPendingCancels returns orders pending cancellation
+func (s *BasketArbStrategy) GetPendingCancels() []*orspb.OrderUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.cancels
+	s.cancels = nil
+	return out
+}
+
+// TriggerFlatten 风控触发时撤销全部在途腿单（持仓由人工或风控平仓处理）
+func (s *BasketArbStrategy) TriggerFlatten(reason FlattenReason, aggressive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ControlState.FlattenMode = true
+	s.ControlState.FlattenReason = reason
+	s.cancelAll()
+}
+
+// === C++ 虚函数对应 ===
+
+// Reset resets the strategy to initial state
+func (s *BasketArbStrategy) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, leg := range s.legs {
+		*leg = basketLeg{symbol: leg.symbol, exchange: leg.exchange, tickSize: leg.tickSize, mult: leg.mult}
+	}
+	s.def, s.alpha = nil, 0
+	s.samples, s.residuals = nil, nil
+	s.resMean, s.resStd = 0, 0
+	if s.coint != nil {
+		s.coint.Reset()
+	}
+	if s.fixed != nil {
+		s.useFixedSpread() // 定义已在 Initialize 校验
+	}
+	s.lastSampleAt = time.Time{}
+	s.sinceFit, s.rebalances = 0, 0
+	s.units, s.stopped, s.zscore = 0, false, 0
+	s.leggedAt = time.Time{}
+	s.estimatedPosition = &EstimatedPosition{Symbol: s.estimatedPosition.Symbol, Exchange: s.estimatedPosition.Exchange}
+	s.pnl = &PNL{}
+	s.riskMetrics = &RiskMetrics{}
+	s.PendingSignals = make([]*TradingSignal, 0)
+}
+
+// SendOrder 腿单由 OnMarketData 产生
+func (s *BasketArbStrategy) SendOrder() {}
+
+// OnTradeUpdate is called after a trade is processed
+func (s *BasketArbStrategy) OnTradeUpdate() {}
+
+// CheckSquareoff 止损由残差带处理
+func (s *BasketArbStrategy) CheckSquareoff() {}
+
+// HandleSquareON handles square off initiation
+func (s *BasketArbStrategy) HandleSquareON() {
+	s.TriggerFlatten(FlattenReasonNone, false)
+}
+
+// HandleSquareoff 撤销在途腿单
+func (s *BasketArbStrategy) HandleSquareoff() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelAll()
+}
+
+// SetThresholds 残差带为固定参数
+func (s *BasketArbStrategy) SetThresholds() {}
+
+// OnAuctionData 集合竞价期间不交易，竞价盘口不进入采样
+func (s *BasketArbStrategy) OnAuctionData(md *mdpb.MarketDataUpdate) {}
+
+// === Engine/Manager 需要的方法 ===
+
+// GetControlState returns the strategy control state
+func (s *BasketArbStrategy) GetControlState() *StrategyControlState {
+	return s.ControlState
+}
+
+// GetConfig returns the strategy configuration
+func (s *BasketArbStrategy) GetConfig() *StrategyConfig {
+	return s.Config
+}
+
+// CanSendOrder returns true if strategy can send orders
+func (s *BasketArbStrategy) CanSendOrder() bool {
+	return s.IsRunning() && s.ControlState.IsActivated() && !s.ControlState.FlattenMode
+}
+
+// GetEstimatedPosition returns current estimated position
+func (s *BasketArbStrategy) GetEstimatedPosition() *EstimatedPosition {
+	return s.estimatedPosition
+}
+
+// GetPosition returns current position (alias)
+func (s *BasketArbStrategy) GetPosition() *EstimatedPosition {
+	return s.estimatedPosition
+}
+
+// GetPositionsBySymbol 实现 PositionProvider：各腿持仓（对账、持仓快照用）
+func (s *BasketArbStrategy) GetPositionsBySymbol() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]int64, len(s.legs))
+	for _, leg := range s.legs {
+		out[leg.symbol] = leg.pos
+	}
+	return out
+}
+
+// GetPNL returns current P&L
+func (s *BasketArbStrategy) GetPNL() *PNL {
+	return s.pnl
+}
+
+// GetRiskMetrics returns risk metrics
+func (s *BasketArbStrategy) GetRiskMetrics() *RiskMetrics {
+	return s.riskMetrics
+}
+
+// GetStatus returns strategy status
+func (s *BasketArbStrategy) GetStatus() *StrategyStatus {
+	s.Status.IsRunning = s.ControlState.IsActivated() && s.ControlState.RunState != StrategyRunStateStopped
+	s.Status.EstimatedPosition = s.estimatedPosition
+	s.Status.PNL = s.pnl
+	s.Status.RiskMetrics = s.riskMetrics
+	return s.Status
+}
+
+// UpdateParameters updates strategy parameters
+func (s *BasketArbStrategy) UpdateParameters(params map[string]interface{}) error {
+	return s.ApplyParameters(params)
+}
+
+// GetCurrentParameters returns current strategy parameters
+func (s *BasketArbStrategy) GetCurrentParameters() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.params.toMap()
+}
+
+// GetThresholds 残差带参数（用于前端显示）
+func (s *BasketArbStrategy) GetThresholds() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]float64{
+		"entry_zscore":        s.params.EntryZ,
+		"exit_zscore":         s.params.ExitZ,
+		"stop_zscore":         s.params.StopZ,
+		"coint_threshold":     s.params.CointThreshold,
+		"rebalance_threshold": s.params.RebalanceThreshold,
+	}
+}
+
+// ApplyParameters 应用新参数（实现 ParameterUpdatable 接口）
+// 全部参数校验通过才生效。lookback、log_prices 改变估计口径，weights/ratios/spread_type 改变价差定义，不支持热更新；
+// unit_lots 改变时按当前权重重算各腿目标手数
+func (s *BasketArbStrategy) ApplyParameters(params map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.params
+	if !next.load(params) {
+		return fmt.Errorf("no valid parameters found to update")
+	}
+	if err := next.validate(); err != nil {
+		return err
+	}
+	if next.Lookback != s.params.Lookback || next.LogPrices != s.params.LogPrices {
+		return fmt.Errorf("lookback and log_prices cannot be changed at runtime")
+	}
+	if !next.sameFixedSpread(s.params) {
+		return fmt.Errorf("weights, ratios and spread_type cannot be changed at runtime")
+	}
+
+	old := s.params.toMap()
+	if next.UnitLots != s.params.UnitLots {
+		for _, leg := range s.legs {
+			leg.perUnit *= float64(next.UnitLots) / float64(s.params.UnitLots)
+		}
+	}
+	s.params = next
+	s.retarget()
+
+	log.Printf("[BasketArb:%s] ✓ Parameters updated:", s.ID)
+	for k, v := range next.toMap() {
+		if fmt.Sprint(old[k]) != fmt.Sprint(v) {
+			log.Printf("[BasketArb:%s]   %s: %v -> %v", s.ID, k, old[k], v)
+		}
+	}
+	return nil
+}
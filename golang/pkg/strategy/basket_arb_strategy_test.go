@@ -0,0 +1,386 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// basketHarness 三个月份白银组成的篮子：ag2603 ≈ 20 + 0.5·ag2605 + 0.5·ag2612 + 残差
+type basketHarness struct {
+	t  *testing.T
+	s  *BasketArbStrategy
+	ts uint64
+}
+
+func newBasketHarness(t *testing.T, params map[string]interface{}) *basketHarness {
+	s, err := NewStrategy(BasketArbStrategyType, "basket_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := s.(*BasketArbStrategy)
+	err = b.Initialize(&StrategyConfig{
+		StrategyID: "basket_1",
+		Symbols:    []string{"ag2603", "ag2605", "ag2612"},
+		Exchanges:  []string{"SHFE"},
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return &basketHarness{t: t, s: b, ts: uint64(time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local).UnixNano())}
+}
+
+// step 一个采样周期：先更新两条回归腿，再更新锚定腿（锚定腿行情触发采样）
+func (h *basketHarness) step(p0, p1, p2 float64) []*TradingSignal {
+	h.ts += uint64(time.Second)
+	var sigs []*TradingSignal
+	for i, leg := range []struct {
+		symbol string
+		mid    float64
+	}{{"ag2605", p1}, {"ag2612", p2}, {"ag2603", p0}} {
+		ts := h.ts - uint64(500*time.Millisecond)
+		if i == 2 {
+			ts = h.ts
+		}
+		h.s.OnMarketData(&mdpb.MarketDataUpdate{
+			Symbol: leg.symbol, Timestamp: ts, LastPrice: leg.mid,
+			BidPrice: []float64{leg.mid - 0.5}, AskPrice: []float64{leg.mid + 0.5}, BidQty: []uint32{10}, AskQty: []uint32{10},
+		})
+		sigs = append(sigs, h.s.GetSignals()...)
+	}
+	return sigs
+}
+
+// fill 按信号价格全部成交
+func (h *basketHarness) fill(id string, sig *TradingSignal) {
+	side := orspb.OrderSide_BUY
+	if sig.Side == OrderSideSell {
+		side = orspb.OrderSide_SELL
+	}
+	h.s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: id, StrategyId: "basket_1", Symbol: sig.Symbol, Side: side,
+		Status: orspb.OrderStatus_FILLED, FilledQty: sig.Quantity, AvgPrice: sig.Price})
+}
+
+func basketPrices(i int) (p1, p2 float64) {
+	x := float64(i)
+	return 5000 + 2*x + 8*math.Cos(0.7*x), 5100 + 3*x + 8*math.Sin(0.5*x)
+}
+
+// warmUp 喂满一个估计窗口，残差为有界振荡
+func (h *basketHarness) warmUp(n int) (p1, p2 float64) {
+	for i := 0; i < n; i++ {
+		p1, p2 = basketPrices(i)
+		if sigs := h.step(20+0.5*p1+0.5*p2+3*math.Sin(2.1*float64(i)), p1, p2); len(sigs) != 0 {
+			h.t.Fatalf("warm-up step %d produced signals: %+v", i, sigs)
+		}
+	}
+	return p1, p2
+}
+
+func TestBasketArb_Lifecycle(t *testing.T) {
+	h := newBasketHarness(t, map[string]interface{}{
+		"lookback":          40.0,
+		"sample_ms":         1000.0,
+		"entry_zscore":      2.0,
+		"exit_zscore":       0.5,
+		"stop_zscore":       6.0,
+		"rebalance_samples": 0.0,
+		"unit_lots":         2.0,
+	})
+	p1, p2 := h.warmUp(40)
+
+	snap := h.s.Basket()
+	if !snap.Ready || !snap.Cointegrated || snap.Units != 0 {
+		t.Fatalf("basket after warm-up = %+v", snap)
+	}
+	if w := snap.Legs; math.Abs(w[0].Weight-1) > 1e-9 || math.Abs(w[1].Weight+0.5) > 0.05 || math.Abs(w[2].Weight+0.5) > 0.05 {
+		t.Fatalf("weights = %+v", w)
+	}
+	fair := 20 + 0.5*p1 + 0.5*p2
+
+	// 锚定腿偏高：空篮子，卖锚定腿 2 手、买两条回归腿各 1 手
+	sigs := h.step(fair+10, p1, p2)
+	if len(sigs) != 3 || h.s.Basket().Units != -1 {
+		t.Fatalf("entry signals = %d units = %d", len(sigs), h.s.Basket().Units)
+	}
+	want := []struct {
+		symbol string
+		side   OrderSide
+		qty    int64
+	}{{"ag2603", OrderSideSell, 2}, {"ag2605", OrderSideBuy, 1}, {"ag2612", OrderSideBuy, 1}}
+	for i, w := range want {
+		if sigs[i].Symbol != w.symbol || sigs[i].Side != w.side || sigs[i].Quantity != w.qty || sigs[i].Category != SignalCategoryAggressive {
+			t.Errorf("entry leg %d = %s %v %d, want %s %v %d", i, sigs[i].Symbol, sigs[i].Side, sigs[i].Quantity, w.symbol, w.side, w.qty)
+		}
+	}
+	entry := sigs
+	for i, sig := range entry {
+		h.fill(string(rune('A'+i)), sig)
+	}
+
+	// 篮子敞口：各腿对冲后的净货值远小于总货值，估计持仓取最大一腿、方向随锚定腿
+	if pos := h.s.GetEstimatedPosition(); pos.NetQty != -2 {
+		t.Errorf("estimated NetQty = %d, want -2", pos.NetQty)
+	}
+	if got := h.s.GetPositionsBySymbol(); got["ag2603"] != -2 || got["ag2605"] != 1 || got["ag2612"] != 1 {
+		t.Errorf("positions by symbol = %v", got)
+	}
+	h.step(fair+10, p1, p2)
+	rm := h.s.GetRiskMetrics()
+	if rm.GrossExposure < 4*5000*15 || rm.ExposureValue > rm.GrossExposure/20 {
+		t.Errorf("exposure net = %.0f gross = %.0f", rm.ExposureValue, rm.GrossExposure)
+	}
+	if ind := h.s.GetControlState().Indicators; ind["leg1_position"] != -2 || ind["leg3_position"] != 1 || ind["basket_units"] != -1 {
+		t.Errorf("indicators = %v", ind)
+	}
+
+	// 残差回归：平仓，锚定腿赚 9×2×15，两条回归腿各亏一个买卖价差 1×15
+	sigs = h.step(fair, p1, p2)
+	if len(sigs) != 3 || h.s.Basket().Units != 0 {
+		t.Fatalf("exit signals = %d units = %d", len(sigs), h.s.Basket().Units)
+	}
+	for i, sig := range sigs {
+		if sig.Side == entry[i].Side || sig.Quantity != entry[i].Quantity {
+			t.Errorf("exit leg %d = %v %d", i, sig.Side, sig.Quantity)
+		}
+		h.fill(string(rune('D'+i)), sig)
+	}
+	if pnl := h.s.GetPNL(); pnl.RealizedPnL != 240 || h.s.GetEstimatedPosition().NetQty != 0 {
+		t.Fatalf("realized = %.2f, want 240", pnl.RealizedPnL)
+	}
+
+	// 锚定腿偏低：多篮子；继续偏离到 stop 止损，止损后 |z| 未回到 exit 以内不再开仓
+	sigs = h.step(fair-10, p1, p2)
+	if len(sigs) != 3 || h.s.Basket().Units != 1 {
+		t.Fatalf("long entry signals = %d units = %d", len(sigs), h.s.Basket().Units)
+	}
+	for i, sig := range sigs {
+		h.fill(string(rune('G'+i)), sig)
+	}
+	sigs = h.step(fair-150, p1, p2)
+	if snap := h.s.Basket(); snap.Units != 0 || !snap.Stopped || len(sigs) != 3 {
+		t.Fatalf("stop: units = %d stopped = %v signals = %d z = %.2f", snap.Units, snap.Stopped, len(sigs), snap.ZScore)
+	}
+	for i, sig := range sigs {
+		h.fill(string(rune('J'+i)), sig)
+	}
+	if sigs = h.step(fair-30, p1, p2); len(sigs) != 0 || !h.s.Basket().Stopped {
+		t.Fatalf("re-entered after stop: %d signals, z = %.2f", len(sigs), h.s.Basket().ZScore)
+	}
+	h.step(fair, p1, p2)
+	if h.s.Basket().Stopped {
+		t.Errorf("stop cooldown should clear once |z| <= exit (z = %.2f)", h.s.Basket().ZScore)
+	}
+}
+
+func TestBasketArb_ChaseAndFlatten(t *testing.T) {
+	h := newBasketHarness(t, map[string]interface{}{
+		"lookback":          40.0,
+		"rebalance_samples": 0.0,
+		"chase_ms":          1500.0,
+		"unit_lots":         2.0,
+	})
+	p1, p2 := h.warmUp(40)
+	fair := 20 + 0.5*p1 + 0.5*p2
+
+	sigs := h.step(fair+10, p1, p2)
+	if len(sigs) != 3 {
+		t.Fatalf("entry signals = %d", len(sigs))
+	}
+	for i, sig := range sigs {
+		h.s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: string(rune('A' + i)), StrategyId: "basket_1", Symbol: sig.Symbol,
+			Side: orspb.OrderSide(sig.Side), Status: orspb.OrderStatus_ACCEPTED})
+	}
+	h.fill("A", sigs[0])
+
+	// 两条回归腿未成交，超过 chase_ms 撤单
+	h.step(fair+10, p1, p2)
+	h.step(fair+10, p1, p2)
+	cancels := h.s.GetPendingCancels()
+	if len(cancels) != 2 || cancels[0].OrderId != "B" || cancels[1].OrderId != "C" {
+		t.Fatalf("chase cancels = %+v", cancels)
+	}
+
+	// 风控平仓模式：撤单后不再发单
+	h.s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: "B", StrategyId: "basket_1", Symbol: "ag2605", Side: orspb.OrderSide_BUY, Status: orspb.OrderStatus_CANCELED})
+	h.s.TriggerFlatten(FlattenReasonNone, false)
+	if sigs = h.step(fair+10, p1, p2); len(sigs) != 0 || h.s.CanSendOrder() {
+		t.Fatalf("flatten mode signals = %d", len(sigs))
+	}
+}
+
+func TestBasketArb_HedgeSlipAndLegTimeout(t *testing.T) {
+	h := newBasketHarness(t, map[string]interface{}{
+		"lookback":          40.0,
+		"rebalance_samples": 0.0,
+		"chase_ms":          1500.0,
+		"hedge_max_slip":    2.0,
+		"leg_timeout_ms":    3500.0,
+		"unit_lots":         2.0,
+	})
+	p1, p2 := h.warmUp(40)
+	fair := 20 + 0.5*p1 + 0.5*p2
+	tick := GetTickSize("ag2605")
+
+	sigs := h.step(fair+10, p1, p2)
+	if len(sigs) != 3 {
+		t.Fatalf("entry signals = %d", len(sigs))
+	}
+	for i, sig := range sigs {
+		h.s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: string(rune('A' + i)), StrategyId: "basket_1", Symbol: sig.Symbol,
+			Side: orspb.OrderSide(sig.Side), Status: orspb.OrderStatus_ACCEPTED})
+	}
+	h.fill("A", sigs[0])
+
+	// 回归腿未成交，超过 chase_ms 撤单
+	h.step(fair+10, p1, p2)
+	h.step(fair+10, p1, p2)
+	for _, c := range h.s.GetPendingCancels() {
+		h.s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: c.OrderId, StrategyId: "basket_1", Symbol: c.Symbol,
+			Side: orspb.OrderSide_BUY, Status: orspb.OrderStatus_CANCELED})
+	}
+
+	// ag2605 对价上移 10，重发价不超过本轮首单对价 + hedge_max_slip tick
+	sigs = h.step(fair+15, p1+10, p2)
+	var chased *TradingSignal
+	for _, sig := range sigs {
+		if sig.Symbol == "ag2605" {
+			chased = sig
+		}
+	}
+	if chased == nil || chased.Side != OrderSideBuy {
+		t.Fatalf("chase signals = %+v", sigs)
+	}
+	if want := p1 + 0.5 + 2*tick; math.Abs(chased.Price-want) > 1e-9 {
+		t.Errorf("chased price = %.2f, want %.2f (capped by hedge_max_slip)", chased.Price, want)
+	}
+
+	// 超过 leg_timeout_ms 仍未配齐：放弃本次调仓，回到空仓并等待 |z| 回到 exit 以内
+	h.step(fair+15, p1+10, p2)
+	snap := h.s.Basket()
+	if snap.Units != 0 || !snap.Stopped {
+		t.Fatalf("after leg timeout units = %d stopped = %v, want 0/true", snap.Units, snap.Stopped)
+	}
+	if snap.Legs[0].Target != 0 {
+		t.Errorf("anchor target = %d, want 0", snap.Legs[0].Target)
+	}
+}
+
+func TestBasketArb_RebalanceHysteresis(t *testing.T) {
+	h := newBasketHarness(t, map[string]interface{}{
+		"lookback":            40.0,
+		"rebalance_samples":   10.0,
+		"rebalance_threshold": 0.05,
+		"entry_zscore":        100.0,
+		"stop_zscore":         0.0,
+	})
+	h.warmUp(40)
+	if n := h.s.Basket().Rebalances; n != 1 {
+		t.Fatalf("rebalances after warm-up = %d", n)
+	}
+
+	// 关系不变：重估的权重变化在迟滞范围内，不采用
+	for i := 40; i < 80; i++ {
+		p1, p2 := basketPrices(i)
+		h.step(20+0.5*p1+0.5*p2+3*math.Sin(2.1*float64(i)), p1, p2)
+	}
+	if n := h.s.Basket().Rebalances; n != 1 {
+		t.Errorf("rebalances with unchanged relation = %d, want 1", n)
+	}
+
+	// 关系改变：采用新权重
+	for i := 80; i < 130; i++ {
+		p1, p2 := basketPrices(i)
+		h.step(0.8*p1+0.2*p2+3*math.Sin(2.1*float64(i)), p1, p2)
+	}
+	snap := h.s.Basket()
+	if snap.Rebalances < 2 || math.Abs(snap.Legs[1].Weight+0.8) > 0.05 || math.Abs(snap.Legs[2].Weight+0.2) > 0.05 {
+		t.Errorf("rebalances = %d weights = %.3f %.3f", snap.Rebalances, snap.Legs[1].Weight, snap.Legs[2].Weight)
+	}
+}
+
+// TestBasketArb_FixedButterfly 固定权重日历蝶式 ag2603 - 2·ag2605 + ag2612：不做协整估计，按 ratio 下单
+func TestBasketArb_FixedButterfly(t *testing.T) {
+	h := newBasketHarness(t, map[string]interface{}{
+		"lookback":     40.0,
+		"entry_zscore": 2.0,
+		"exit_zscore":  0.5,
+		"unit_lots":    2.0,
+		"weights":      []interface{}{1.0, -2.0, 1.0},
+		"spread_type":  "difference",
+	})
+	var p1, p2 float64
+	for i := 0; i < 40; i++ {
+		p1, p2 = basketPrices(i)
+		if sigs := h.step(2*p1-p2+3*math.Sin(2.1*float64(i)), p1, p2); len(sigs) != 0 {
+			t.Fatalf("warm-up step %d produced signals: %+v", i, sigs)
+		}
+	}
+	snap := h.s.Basket()
+	if !snap.Ready || !snap.Cointegrated || snap.Rebalances != 0 {
+		t.Fatalf("basket after warm-up = %+v", snap)
+	}
+	for i, w := range []float64{1, -2, 1} {
+		if snap.Legs[i].Weight != w || snap.Legs[i].PerUnit != 2*w {
+			t.Errorf("leg %d weight = %v per_unit = %v", i, snap.Legs[i].Weight, snap.Legs[i].PerUnit)
+		}
+	}
+
+	// 蝶式价差偏高：空蝶式，卖两翼各 2 手、买中间腿 4 手
+	sigs := h.step(2*p1-p2+20, p1, p2)
+	want := []struct {
+		symbol string
+		side   OrderSide
+		qty    int64
+	}{{"ag2603", OrderSideSell, 2}, {"ag2605", OrderSideBuy, 4}, {"ag2612", OrderSideSell, 2}}
+	if len(sigs) != len(want) || h.s.Basket().Units != -1 {
+		t.Fatalf("entry signals = %d units = %d", len(sigs), h.s.Basket().Units)
+	}
+	for i, w := range want {
+		if sigs[i].Symbol != w.symbol || sigs[i].Side != w.side || sigs[i].Quantity != w.qty {
+			t.Errorf("entry leg %d = %s %v %d, want %s %v %d", i, sigs[i].Symbol, sigs[i].Side, sigs[i].Quantity, w.symbol, w.side, w.qty)
+		}
+	}
+
+	if err := h.s.ApplyParameters(map[string]interface{}{"weights": []interface{}{1.0, -1.0, 1.0}}); err == nil {
+		t.Error("expected error for runtime weights change")
+	}
+	h.s.Reset()
+	if snap := h.s.Basket(); !snap.Ready || snap.Legs[1].PerUnit != -4 {
+		t.Errorf("basket after reset = %+v", snap)
+	}
+
+	b := NewBasketArbStrategy("basket_2")
+	err := b.Initialize(&StrategyConfig{StrategyID: "basket_2", Symbols: []string{"ag2603", "ag2605", "ag2612"},
+		Parameters: map[string]interface{}{"weights": []interface{}{1.0, -1.0}}})
+	if err == nil {
+		t.Error("expected error for weights/symbols length mismatch")
+	}
+}
+
+func TestBasketArb_ApplyParameters(t *testing.T) {
+	b := NewBasketArbStrategy("basket_1")
+	if err := b.Initialize(&StrategyConfig{StrategyID: "basket_1", Symbols: []string{"ag2603"}}); err == nil {
+		t.Error("expected error for single symbol")
+	}
+	if err := b.ApplyParameters(map[string]interface{}{"entry_zscore": 0.4, "exit_zscore": 0.5}); err == nil {
+		t.Error("expected error for entry <= exit")
+	}
+	if err := b.ApplyParameters(map[string]interface{}{"lookback": 100.0}); err == nil {
+		t.Error("expected error for runtime lookback change")
+	}
+	if err := b.UpdateParameters(map[string]interface{}{"entry_zscore": 2.5, "unit_lots": 3.0}); err != nil {
+		t.Fatal(err)
+	}
+	params := b.GetCurrentParameters()
+	if params["entry_zscore"] != 2.5 || params["unit_lots"] != int64(3) {
+		t.Errorf("params = %v", params)
+	}
+}
@@ -7,11 +7,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/nats-io/nats.go"
-	"google.golang.org/protobuf/proto"
-
 	"github.com/yourusername/quantlink-trade-system/pkg/client"
 	"github.com/yourusername/quantlink-trade-system/pkg/indicators"
+	"github.com/yourusername/quantlink-trade-system/pkg/offset"
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
 	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
 )
@@ -19,9 +17,9 @@ import (
 // StrategyEngine manages multiple trading strategies
 type StrategyEngine struct {
 	strategies      map[string]Strategy // strategy_id -> Strategy
-	orsClient       *client.ORSClient
-	natsConn        *nats.Conn
-	mdSubscriptions map[string]*nats.Subscription // symbol -> subscription
+	transport       Transport                       // 行情/下单链路（nil = Initialize 时使用 NATS + gRPC）
+	connected       bool                            // transport 已连接；未连接时下单走模拟应答
+	mdSubscriptions map[string]bool                 // subscribed symbols
 	sharedIndPool   *indicators.SharedIndicatorPool // Shared indicator pool (like tbsrc Instrument-level indicators)
 
 	ctx             context.Context
@@ -32,6 +30,49 @@ type StrategyEngine struct {
 	isRunning       bool
 	orderQueue      chan *TradingSignal
 	config          *EngineConfig
+
+	offsetPlanner   *offset.Planner  // 开平仓规划器（nil = 未指定开平的信号一律开仓）
+	fillRecorder    FillRecorder     // 成交流水（对账用，可选）
+	orderJournal    OrderJournal     // 写前日志（崩溃恢复用，可选）
+	accountRouter   AccountRouter    // 多账户下单路由（可选）
+	shadowTap       ShadowTap        // 影子模式对比（可选）
+	auctionPhase    AuctionPhaseFunc // 集合竞价时段判断（可选，见 auction.go）
+}
+
+// FillRecorder records fill reports for reconciliation
+// offset 为规划器记录的开平标志（未知时为空）
+type FillRecorder interface {
+	RecordFill(update *orspb.OrderUpdate, offset string) error
+}
+
+// OrderJournal records order traffic for crash recovery
+// 请求在发送前写入，应答和回报在处理前写入
+type OrderJournal interface {
+	RecordOrderRequest(req *orspb.OrderRequest) error
+	RecordOrderAck(req *orspb.OrderRequest, resp *orspb.OrderResponse) error
+	RecordCancelRequest(req *orspb.CancelRequest) error
+	RecordOrderUpdate(update *orspb.OrderUpdate) error
+}
+
+// AccountRouter fans strategy orders out to the strategy's accounts (多账户，Go 扩展)
+// 主账户订单排在最前，策略只收到主账户订单的回报；
+// 已分配账户的策略订单由路由按各账户台账规划开平（Assigned 为 true 时引擎不再规划）
+type AccountRouter interface {
+	Assigned(strategyID string) bool
+	Fanout(req *orspb.OrderRequest) ([]*orspb.OrderRequest, error)
+	Bind(req *orspb.OrderRequest, orderID, leadOrderID string)
+	Abort(req *orspb.OrderRequest)
+	Followers(leadOrderID string) []string
+	OnOrderUpdate(update *orspb.OrderUpdate) bool // false = 不分发给策略
+	MarkPrice(symbol string, price float64)
+}
+
+// ShadowTap receives the live event stream for shadow comparison (影子模式，Go 扩展)
+// 在行情/回报分发路径上同步调用，实现须立即返回（入队后异步处理）
+type ShadowTap interface {
+	OnMarketData(md *mdpb.MarketDataUpdate)
+	OnLiveSignals(s Strategy, md *mdpb.MarketDataUpdate, signals []*TradingSignal) // 策略处理完该笔行情后实际下发的信号（可为空）
+	OnLiveOrderUpdate(update *orspb.OrderUpdate)
 }
 
 // OrderMode defines how orders are sent
@@ -70,7 +111,7 @@ func NewStrategyEngine(config *EngineConfig) *StrategyEngine {
 
 	return &StrategyEngine{
 		strategies:      make(map[string]Strategy),
-		mdSubscriptions: make(map[string]*nats.Subscription),
+		mdSubscriptions: make(map[string]bool),
 		sharedIndPool:   indicators.NewSharedIndicatorPool(),
 		ctx:             ctx,
 		cancel:          cancel,
@@ -79,28 +120,31 @@ func NewStrategyEngine(config *EngineConfig) *StrategyEngine {
 	}
 }
 
+// SetTransport 设置行情/下单链路（如 SHMTransport），须在 Initialize 之前调用
+func (se *StrategyEngine) SetTransport(t Transport) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.transport = t
+}
+
+// Transport 返回行情/下单链路（Initialize 之前未设置时为 nil）
+func (se *StrategyEngine) Transport() Transport {
+	se.mu.RLock()
+	defer se.mu.RUnlock()
+	return se.transport
+}
+
 // Initialize initializes the strategy engine
+// 未设置 transport 时连接 NATS + gRPC ORS Gateway
 func (se *StrategyEngine) Initialize() error {
-	// Connect to NATS
-	var err error
-	se.natsConn, err = nats.Connect(se.config.NATSAddr)
-	if err != nil {
-		return fmt.Errorf("failed to connect to NATS: %w", err)
+	if se.transport == nil {
+		se.transport = newNATSTransport(se.config)
 	}
-	log.Printf("[StrategyEngine] Connected to NATS: %s", se.config.NATSAddr)
-
-	// Initialize ORS client
-	se.orsClient, err = client.NewORSClient(client.ORSClientConfig{
-		GatewayAddr:       se.config.ORSGatewayAddr,
-		NATSAddr:          se.config.NATSAddr,
-		CounterBridgeAddr: se.config.CounterBridgeAddr, // 从配置读取Counter Bridge地址
-		StrategyID:        "strategy_engine",            // 使用通用ID
-	})
-	if err != nil {
-		return fmt.Errorf("failed to initialize ORS client: %w", err)
+	if err := se.transport.Connect(); err != nil {
+		return err
 	}
-	log.Printf("[StrategyEngine] ORS Client initialized: %s", se.config.ORSGatewayAddr)
-
+	se.connected = true
+	log.Printf("[StrategyEngine] Transport connected: %s", se.transport.Name())
 	return nil
 }
 
@@ -115,6 +159,9 @@ func (se *StrategyEngine) AddStrategy(strategy Strategy) error {
 	}
 
 	se.strategies[id] = strategy
+	if ps, ok := strategy.(AuctionPhaseSetter); ok && se.auctionPhase != nil {
+		ps.SetAuctionPhaseFunc(se.auctionPhase)
+	}
 	log.Printf("[StrategyEngine] Added strategy: %s (type: %s)", id, strategy.GetType())
 	return nil
 }
@@ -203,15 +250,11 @@ func (se *StrategyEngine) Stop() error {
 	// Cancel context
 	se.cancel()
 
-	// Unsubscribe from all market data
-	for symbol, sub := range se.mdSubscriptions {
-		sub.Unsubscribe()
-		log.Printf("[StrategyEngine] Unsubscribed from market data: %s", symbol)
-	}
-
-	// Close connections
-	if se.natsConn != nil {
-		se.natsConn.Close()
+	// Unsubscribe from all market data and close connections
+	if se.connected {
+		if err := se.transport.Close(); err != nil {
+			log.Printf("[StrategyEngine] Failed to close transport %s: %v", se.transport.Name(), err)
+		}
 	}
 
 	// Wait for goroutines to finish
@@ -226,20 +269,14 @@ func (se *StrategyEngine) SubscribeMarketData(symbol string) error {
 	se.mu.Lock()
 	defer se.mu.Unlock()
 
-	if _, exists := se.mdSubscriptions[symbol]; exists {
+	if se.mdSubscriptions[symbol] {
 		return nil // Already subscribed
 	}
+	if !se.connected {
+		return fmt.Errorf("failed to subscribe to %s: transport not connected", symbol)
+	}
 
-	// Subscribe to md.*.symbol to match MD Gateway's md.exchange.symbol format
-	subject := fmt.Sprintf("md.*.%s", symbol)
-	sub, err := se.natsConn.Subscribe(subject, func(msg *nats.Msg) {
-		// Parse market data update
-		var md mdpb.MarketDataUpdate
-		if err := proto.Unmarshal(msg.Data, &md); err != nil {
-			log.Printf("[StrategyEngine] Failed to unmarshal market data: %v", err)
-			return
-		}
-
+	err := se.transport.SubscribeMarketData(symbol, func(md *mdpb.MarketDataUpdate) {
 		bidPrice := 0.0
 		askPrice := 0.0
 		if len(md.BidPrice) > 0 {
@@ -252,20 +289,25 @@ func (se *StrategyEngine) SubscribeMarketData(symbol string) error {
 			md.Symbol, bidPrice, askPrice)
 
 		// Dispatch to all strategies
-		se.dispatchMarketData(&md)
+		se.dispatchMarketData(md)
 	})
-
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+		return err
 	}
 
-	se.mdSubscriptions[symbol] = sub
+	se.mdSubscriptions[symbol] = true
 	log.Printf("[StrategyEngine] Subscribed to market data: %s", symbol)
 	return nil
 }
 
 // dispatchMarketData dispatches market data to all strategies
 func (se *StrategyEngine) dispatchMarketData(md *mdpb.MarketDataUpdate) {
+	if se.accountRouter != nil {
+		se.accountRouter.MarkPrice(md.Symbol, md.LastPrice)
+	}
+	if se.shadowTap != nil {
+		se.shadowTap.OnMarketData(md)
+	}
 	if se.config.OrderMode == OrderModeSync {
 		se.dispatchMarketDataSync(md)
 	} else {
@@ -275,24 +317,29 @@ func (se *StrategyEngine) dispatchMarketData(md *mdpb.MarketDataUpdate) {
 
 // dispatchMarketDataSync - Synchronous mode (low latency, like tbsrc)
 func (se *StrategyEngine) dispatchMarketDataSync(md *mdpb.MarketDataUpdate) {
+	// 集合竞价的指示性盘口不参与共享指标计算
+	inAuction := se.isAuctionData(md)
+
 	// Step 1: Update shared indicators first (only once for all strategies)
 	// 步骤1：先更新共享指标（所有策略只计算一次）
-	se.sharedIndPool.UpdateAll(md.Symbol, md)
-
-	// Step 2: Notify strategies about indicator update (optional interface)
-	// 步骤2：通知策略指标已更新（可选接口，类似tbsrc INDCallBack）
-	se.mu.RLock()
-	sharedInds, _ := se.sharedIndPool.Get(md.Symbol)
-	for _, strategy := range se.strategies {
-		if !strategy.IsRunning() {
-			continue
-		}
-		// Check if strategy implements IndicatorAwareStrategy interface
-		if indStrategy, ok := strategy.(IndicatorAwareStrategy); ok {
-			indStrategy.OnIndicatorUpdate(md.Symbol, sharedInds)
+	if !inAuction {
+		se.sharedIndPool.UpdateAll(md.Symbol, md)
+
+		// Step 2: Notify strategies about indicator update (optional interface)
+		// 步骤2：通知策略指标已更新（可选接口，类似tbsrc INDCallBack）
+		se.mu.RLock()
+		sharedInds, _ := se.sharedIndPool.Get(md.Symbol)
+		for _, strategy := range se.strategies {
+			if !strategy.IsRunning() {
+				continue
+			}
+			// Check if strategy implements IndicatorAwareStrategy interface
+			if indStrategy, ok := strategy.(IndicatorAwareStrategy); ok {
+				indStrategy.OnIndicatorUpdate(md.Symbol, sharedInds)
+			}
 		}
+		se.mu.RUnlock()
 	}
-	se.mu.RUnlock()
 
 	// Step 3: Process each strategy (distinguish auction vs continuous)
 	// 步骤3：处理每个策略（区分竞价期/连续交易期，类似tbsrc AuctionCallBack）
@@ -315,16 +362,19 @@ func (se *StrategyEngine) dispatchMarketDataSync(md *mdpb.MarketDataUpdate) {
 			// 1. Update LastMarketData for WebSocket push
 			s.SetLastMarketData(md.GetSymbol(), md)
 
-			// 2. Call market data callback
-			// TODO: Add FeedType to MarketDataUpdate protobuf to distinguish auction vs continuous
-			// For now, always call OnMarketData
-			s.OnMarketData(md)
+			// 2. Call market data callback (auction vs continuous)
+			if inAuction {
+				s.OnAuctionData(md)
+			} else {
+				s.OnMarketData(md)
+			}
 
 			// 3. Immediately collect signals
 			signals := s.GetSignals()
 
 			// 3. Send orders immediately (synchronous) - but check state first
 			// 对应 tbsrc: !m_onFlat && m_Active check before SendOrder()
+			var sent []*TradingSignal
 			for _, signal := range signals {
 				// Check if strategy can send orders (aligned with tbsrc)
 				if !s.CanSendOrder() {
@@ -335,6 +385,10 @@ func (se *StrategyEngine) dispatchMarketDataSync(md *mdpb.MarketDataUpdate) {
 				}
 
 				se.sendOrderSync(signal)
+				sent = append(sent, signal)
+			}
+			if se.shadowTap != nil {
+				se.shadowTap.OnLiveSignals(s, md, sent)
 			}
 		}(strategy)
 	}
@@ -342,23 +396,27 @@ func (se *StrategyEngine) dispatchMarketDataSync(md *mdpb.MarketDataUpdate) {
 
 // dispatchMarketDataAsync - Asynchronous mode (high throughput, original behavior)
 func (se *StrategyEngine) dispatchMarketDataAsync(md *mdpb.MarketDataUpdate) {
+	inAuction := se.isAuctionData(md)
+
 	// Step 1: Update shared indicators first (only once for all strategies)
 	// 步骤1：先更新共享指标（所有策略只计算一次）
-	se.sharedIndPool.UpdateAll(md.Symbol, md)
-
-	// Step 2: Notify strategies about indicator update (optional interface)
-	// 步骤2：通知策略指标已更新（可选接口）
-	se.mu.RLock()
-	sharedInds, _ := se.sharedIndPool.Get(md.Symbol)
-	for _, strategy := range se.strategies {
-		if !strategy.IsRunning() {
-			continue
-		}
-		if indStrategy, ok := strategy.(IndicatorAwareStrategy); ok {
-			indStrategy.OnIndicatorUpdate(md.Symbol, sharedInds)
+	if !inAuction {
+		se.sharedIndPool.UpdateAll(md.Symbol, md)
+
+		// Step 2: Notify strategies about indicator update (optional interface)
+		// 步骤2：通知策略指标已更新（可选接口）
+		se.mu.RLock()
+		sharedInds, _ := se.sharedIndPool.Get(md.Symbol)
+		for _, strategy := range se.strategies {
+			if !strategy.IsRunning() {
+				continue
+			}
+			if indStrategy, ok := strategy.(IndicatorAwareStrategy); ok {
+				indStrategy.OnIndicatorUpdate(md.Symbol, sharedInds)
+			}
 		}
+		se.mu.RUnlock()
 	}
-	se.mu.RUnlock()
 
 	// Step 3: Process each strategy in goroutine
 	// 步骤3：在goroutine中处理每个策略
@@ -381,12 +439,18 @@ func (se *StrategyEngine) dispatchMarketDataAsync(md *mdpb.MarketDataUpdate) {
 			// Update LastMarketData for WebSocket push
 			s.SetLastMarketData(md.GetSymbol(), md)
 
-			// Call market data callback
-			// TODO: Add FeedType to MarketDataUpdate protobuf to distinguish auction vs continuous
-			s.OnMarketData(md)
+			// Call market data callback (auction vs continuous)
+			if inAuction {
+				s.OnAuctionData(md)
+			} else {
+				s.OnMarketData(md)
+			}
 
 			// Collect signals
 			signals := s.GetSignals()
+			if se.shadowTap != nil {
+				se.shadowTap.OnLiveSignals(s, md, signals)
+			}
 			for _, signal := range signals {
 				select {
 				case se.orderQueue <- signal:
@@ -400,39 +464,149 @@ func (se *StrategyEngine) dispatchMarketDataAsync(md *mdpb.MarketDataUpdate) {
 
 // sendOrderSync sends an order synchronously (for low-latency mode)
 func (se *StrategyEngine) sendOrderSync(signal *TradingSignal) {
-	// Convert signal to order request
-	req := signal.ToOrderRequest()
-
 	// Send order with timeout
 	ctx, cancel := context.WithTimeout(se.ctx, se.config.OrderTimeout)
 	defer cancel()
 
-	resp, err := se.sendOrder(ctx, req)
-	if err != nil {
-		log.Printf("[StrategyEngine] Order failed for %s: %v", signal.StrategyID, err)
-		return
+	se.submitSignal(ctx, signal)
+}
+
+// SetOffsetPlanner 设置开平仓规划器，须在 Start 之前调用
+func (se *StrategyEngine) SetOffsetPlanner(p *offset.Planner) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.offsetPlanner = p
+}
+
+// OffsetPlanner 返回开平仓规划器（未启用时为 nil）
+func (se *StrategyEngine) OffsetPlanner() *offset.Planner {
+	se.mu.RLock()
+	defer se.mu.RUnlock()
+	return se.offsetPlanner
+}
+
+// SetFillRecorder 设置成交流水记录器，须在 Start 之前调用
+func (se *StrategyEngine) SetFillRecorder(r FillRecorder) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.fillRecorder = r
+}
+
+// SetOrderJournal 设置写前日志，须在 Start 之前调用
+func (se *StrategyEngine) SetOrderJournal(j OrderJournal) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.orderJournal = j
+}
+
+// SetAccountRouter 设置多账户下单路由，须在 Start 之前调用
+func (se *StrategyEngine) SetAccountRouter(r AccountRouter) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.accountRouter = r
+}
+
+// SetAuctionPhaseFunc 设置交易日历的集合竞价时段判断，同时传给实现 AuctionPhaseSetter 的策略
+func (se *StrategyEngine) SetAuctionPhaseFunc(fn AuctionPhaseFunc) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.auctionPhase = fn
+	for _, s := range se.strategies {
+		if ps, ok := s.(AuctionPhaseSetter); ok {
+			ps.SetAuctionPhaseFunc(fn)
+		}
 	}
+}
 
-	log.Printf("[StrategyEngine] Order sent: %s, OrderID: %s, Status: %v",
-		signal.StrategyID, resp.OrderId, resp.ErrorCode)
+// isAuctionData 行情是否为集合竞价行情（见 auction.go）
+func (se *StrategyEngine) isAuctionData(md *mdpb.MarketDataUpdate) bool {
+	se.mu.RLock()
+	phase := se.auctionPhase
+	se.mu.RUnlock()
+	return IsAuctionData(md, phase)
 }
 
-// subscribeOrderUpdates subscribes to order updates
-func (se *StrategyEngine) subscribeOrderUpdates() error {
-	// Subscribe to all strategy order updates
-	subject := "order.>"
-	_, err := se.natsConn.Subscribe(subject, func(msg *nats.Msg) {
-		// Parse order update
-		var update orspb.OrderUpdate
-		if err := proto.Unmarshal(msg.Data, &update); err != nil {
-			log.Printf("[StrategyEngine] Failed to unmarshal order update: %v", err)
+// SetShadowTap 设置影子模式对比，须在 Start 之前调用
+func (se *StrategyEngine) SetShadowTap(t ShadowTap) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.shadowTap = t
+}
+
+// submitSignal converts a signal into order requests and sends them
+// 未指定开平（OpenCloseUnknown）且启用了规划器时，按持仓台账拆分为平昨/平今/开仓子订单；
+// 分配了账户的策略由 AccountRouter.Fanout 按各账户台账分别拆分
+func (se *StrategyEngine) submitSignal(ctx context.Context, signal *TradingSignal) {
+	planner := se.offsetPlanner
+	routed := se.accountRouter != nil && se.accountRouter.Assigned(signal.StrategyID)
+	if planner == nil || signal.OpenClose != OpenCloseUnknown || routed {
+		resp, err := se.sendRouted(ctx, signal.ToOrderRequest())
+		if err != nil {
+			log.Printf("[StrategyEngine] Order failed for %s: %v", signal.StrategyID, err)
 			return
 		}
+		log.Printf("[StrategyEngine] Order sent: %s, OrderID: %s, Status: %v",
+			signal.StrategyID, resp.OrderId, resp.ErrorCode)
+		return
+	}
+
+	side := offset.Buy
+	if signal.Side == OrderSideSell {
+		side = offset.Sell
+	}
+	pieces := planner.Plan(offset.Request{
+		Symbol:   signal.Symbol,
+		Exchange: signal.Exchange,
+		Side:     side,
+		Qty:      signal.Quantity,
+		Price:    signal.Price,
+	})
+
+	for _, pc := range pieces {
+		child := *signal
+		child.Quantity = pc.Qty
+		child.OpenClose = openCloseOf(pc.Offset)
+
+		resp, err := se.sendRouted(ctx, child.ToOrderRequest())
+		if err != nil || resp.ErrorCode != orspb.ErrorCode_SUCCESS {
+			planner.Abort(pc)
+			if err != nil {
+				log.Printf("[StrategyEngine] Order failed for %s (%s %d): %v", signal.StrategyID, pc.Offset, pc.Qty, err)
+			} else {
+				log.Printf("[StrategyEngine] Order rejected for %s (%s %d): %v", signal.StrategyID, pc.Offset, pc.Qty, resp.ErrorCode)
+			}
+			continue
+		}
+		planner.Bind(resp.OrderId, pc)
+		log.Printf("[StrategyEngine] Order sent: %s, OrderID: %s, Offset: %s, Qty: %d",
+			signal.StrategyID, resp.OrderId, pc.Offset, pc.Qty)
+	}
+}
 
+// openCloseOf maps planner offsets to signal open/close flags
+func openCloseOf(o offset.Offset) OpenClose {
+	switch o {
+	case offset.Close:
+		return OpenCloseClose
+	case offset.CloseToday:
+		return OpenCloseCloseToday
+	case offset.CloseYesterday:
+		return OpenCloseCloseYesterday
+	default:
+		return OpenCloseOpen
+	}
+}
+
+// subscribeOrderUpdates subscribes to order updates
+func (se *StrategyEngine) subscribeOrderUpdates() error {
+	if !se.connected {
+		return fmt.Errorf("failed to subscribe to order updates: transport not connected")
+	}
+	err := se.transport.SubscribeOrderUpdates(func(update *orspb.OrderUpdate) {
 		log.Printf("[StrategyEngine] Received order update: %s, Status: %v", update.OrderId, update.Status)
 
 		// Dispatch to strategies
-		se.dispatchOrderUpdate(&update)
+		se.dispatchOrderUpdate(update)
 	})
 
 	if err != nil {
@@ -448,6 +622,37 @@ func (se *StrategyEngine) dispatchOrderUpdate(update *orspb.OrderUpdate) {
 	se.mu.RLock()
 	defer se.mu.RUnlock()
 
+	if se.orderJournal != nil {
+		if err := se.orderJournal.RecordOrderUpdate(update); err != nil {
+			log.Printf("[StrategyEngine] Failed to journal order update %s: %v", update.OrderId, err)
+		}
+	}
+
+	// 跟随账户订单只计入账户持仓和该账户的开平台账，不进入成交流水，也不分发给策略
+	if se.accountRouter != nil && !se.accountRouter.OnOrderUpdate(update) {
+		se.updateOffsetLedger(update)
+		return
+	}
+	if se.shadowTap != nil {
+		se.shadowTap.OnLiveOrderUpdate(update)
+	}
+
+	// 记录成交流水（先于开平台账更新，全部成交后台账不再保留订单的开平标志）
+	if se.fillRecorder != nil && update.FilledQty > 0 &&
+		(update.Status == orspb.OrderStatus_FILLED || update.Status == orspb.OrderStatus_PARTIALLY_FILLED) {
+		offsetName := ""
+		if se.offsetPlanner != nil {
+			if o, ok := se.offsetPlanner.OffsetOf(update.OrderId); ok {
+				offsetName = o.String()
+			}
+		}
+		if err := se.fillRecorder.RecordFill(update, offsetName); err != nil {
+			log.Printf("[StrategyEngine] Failed to record fill %s: %v", update.OrderId, err)
+		}
+	}
+
+	se.updateOffsetLedger(update)
+
 	// Dispatch to all strategies (they will filter based on their orders)
 	for _, strategy := range se.strategies {
 		if !strategy.IsRunning() {
@@ -481,6 +686,20 @@ func (se *StrategyEngine) dispatchOrderUpdate(update *orspb.OrderUpdate) {
 	}
 }
 
+// updateOffsetLedger 更新开平仓台账：开仓成交计入今仓，撤单/拒单恢复平仓占用
+func (se *StrategyEngine) updateOffsetLedger(update *orspb.OrderUpdate) {
+	if se.offsetPlanner == nil {
+		return
+	}
+	switch update.Status {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_PARTIALLY_FILLED:
+		se.offsetPlanner.OnFill(update.OrderId, update.FilledQty)
+	case orspb.OrderStatus_CANCELED, orspb.OrderStatus_REJECTED:
+		se.offsetPlanner.OnFill(update.OrderId, update.FilledQty)
+		se.offsetPlanner.OnDone(update.OrderId)
+	}
+}
+
 // processOrders processes trading signals and sends orders
 func (se *StrategyEngine) processOrders() {
 	defer se.wg.Done()
@@ -490,21 +709,11 @@ func (se *StrategyEngine) processOrders() {
 	for {
 		select {
 		case signal := <-se.orderQueue:
-			// Convert signal to order request
-			req := signal.ToOrderRequest()
-
 			// Send order via ORS client
 			ctx, cancel := context.WithTimeout(se.ctx, 5*time.Second)
-			resp, err := se.sendOrder(ctx, req)
+			se.submitSignal(ctx, signal)
 			cancel()
 
-			if err != nil {
-				log.Printf("[StrategyEngine] Failed to send order for %s: %v", signal.StrategyID, err)
-			} else {
-				log.Printf("[StrategyEngine] Order sent: %s, OrderID: %s, Status: %v",
-					signal.StrategyID, resp.OrderId, resp.ErrorCode)
-			}
-
 		case <-se.ctx.Done():
 			log.Println("[StrategyEngine] Order processor stopped")
 			return
@@ -512,26 +721,107 @@ func (se *StrategyEngine) processOrders() {
 	}
 }
 
+// sendRouted sends a strategy order to all of its accounts
+// 未设置路由时等同 sendOrder；主账户下单失败或被拒时不发送跟随账户订单，返回主账户应答
+func (se *StrategyEngine) sendRouted(ctx context.Context, req *orspb.OrderRequest) (*orspb.OrderResponse, error) {
+	router := se.accountRouter
+	if router == nil {
+		return se.sendOrder(ctx, req)
+	}
+	reqs, err := router.Fanout(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 主账户订单（按开平拆分时可能多笔）全部失败才放弃跟随账户订单
+	nLead := 1
+	for nLead < len(reqs) && reqs[nLead].StrategyId == req.StrategyId {
+		nLead++
+	}
+	var resp *orspb.OrderResponse
+	var leadErr error
+	for _, lead := range reqs[:nLead] {
+		lresp, lerr := se.sendOrder(ctx, lead)
+		if lerr == nil && lresp.ErrorCode != orspb.ErrorCode_SUCCESS {
+			lerr = fmt.Errorf("lead order rejected: %v", lresp.ErrorCode)
+		}
+		if lerr != nil {
+			router.Abort(lead)
+			leadErr = lerr
+			continue
+		}
+		router.Bind(lead, lresp.OrderId, "")
+		if resp == nil {
+			resp = lresp
+		}
+	}
+	if resp == nil {
+		for _, r := range reqs[nLead:] {
+			router.Abort(r)
+		}
+		return nil, leadErr
+	}
+
+	for _, f := range reqs[nLead:] {
+		fresp, ferr := se.sendOrder(ctx, f)
+		switch {
+		case ferr != nil:
+			router.Abort(f)
+			log.Printf("[StrategyEngine] Follower order failed for %s (%s %d): %v", f.StrategyId, f.Account, f.Quantity, ferr)
+		case fresp.ErrorCode != orspb.ErrorCode_SUCCESS:
+			router.Abort(f)
+			log.Printf("[StrategyEngine] Follower order rejected for %s (%s %d): %v", f.StrategyId, f.Account, f.Quantity, fresp.ErrorCode)
+		default:
+			router.Bind(f, fresp.OrderId, resp.OrderId)
+		}
+	}
+	return resp, nil
+}
+
 // sendOrder sends an order via ORS client
+// 启用写前日志时，请求未能写入日志则不发送（否则崩溃后无法恢复该订单）
 func (se *StrategyEngine) sendOrder(ctx context.Context, req *orspb.OrderRequest) (*orspb.OrderResponse, error) {
-	// Send order via ORS client (gRPC)
-	if se.orsClient != nil {
-		return se.orsClient.SendOrder(ctx, req)
+	journal := se.orderJournal
+	if journal != nil {
+		if err := journal.RecordOrderRequest(req); err != nil {
+			return nil, fmt.Errorf("journal order request %s: %w", req.StrategyId, err)
+		}
 	}
 
-	// Fallback for testing/simulation
-	return &orspb.OrderResponse{
-		OrderId:   fmt.Sprintf("ORD_%d", time.Now().UnixNano()),
-		ErrorCode: orspb.ErrorCode_SUCCESS,
-	}, nil
+	var resp *orspb.OrderResponse
+	var err error
+	// Send order via transport (gRPC ORS Gateway / SHM)
+	if se.connected {
+		resp, err = se.transport.SendOrder(ctx, req)
+	} else {
+		// Fallback for testing/simulation
+		resp = &orspb.OrderResponse{
+			OrderId:   fmt.Sprintf("ORD_%d", time.Now().UnixNano()),
+			ErrorCode: orspb.ErrorCode_SUCCESS,
+		}
+	}
+
+	if journal != nil && err == nil {
+		if jerr := journal.RecordOrderAck(req, resp); jerr != nil {
+			log.Printf("[StrategyEngine] Failed to journal order ack %s: %v", resp.OrderId, jerr)
+		}
+	}
+	return resp, err
 }
 
 // cancelOrder sends a cancel request via ORS client
 // C++: ORSCallBack 中处理 CANCEL_ORDER_CONFIRM 和 CANCEL_ORDER_REJECT
+// 启用写前日志时同样先写日志，写入失败不发送
 func (se *StrategyEngine) cancelOrder(ctx context.Context, req *orspb.CancelRequest) (*orspb.CancelResponse, error) {
-	// Send cancel via ORS client (gRPC)
-	if se.orsClient != nil {
-		return se.orsClient.CancelOrder(ctx, req)
+	if journal := se.orderJournal; journal != nil {
+		if err := journal.RecordCancelRequest(req); err != nil {
+			return nil, fmt.Errorf("journal cancel request %s: %w", req.OrderId, err)
+		}
+	}
+
+	// Send cancel via transport (gRPC ORS Gateway / SHM)
+	if se.connected {
+		return se.transport.CancelOrder(ctx, req)
 	}
 
 	// Fallback for testing/simulation
@@ -541,6 +831,29 @@ func (se *StrategyEngine) cancelOrder(ctx context.Context, req *orspb.CancelRequ
 	}, nil
 }
 
+// CancelOrder sends a cancel request for an order not tracked by any strategy
+// (启动时撤销上一进程遗留的在途订单，Go 扩展)
+func (se *StrategyEngine) CancelOrder(ctx context.Context, req *orspb.CancelRequest) (*orspb.CancelResponse, error) {
+	return se.cancelOrder(ctx, req)
+}
+
+// cancelFollowers 撤销主账户订单对应的跟随账户在途订单
+func (se *StrategyEngine) cancelFollowers(leadOrderID, symbol string) {
+	if se.accountRouter == nil {
+		return
+	}
+	for _, id := range se.accountRouter.Followers(leadOrderID) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		resp, err := se.cancelOrder(ctx, &orspb.CancelRequest{OrderId: id, Symbol: symbol})
+		cancel()
+		if err != nil {
+			log.Printf("[StrategyEngine] Failed to send follower cancel for orderID=%s: %v", id, err)
+		} else if resp.ErrorCode != orspb.ErrorCode_SUCCESS {
+			log.Printf("[StrategyEngine] Follower cancel rejected for orderID=%s: %s", id, resp.ErrorMsg)
+		}
+	}
+}
+
 // ProcessCancelRequests 处理所有策略的撤单请求
 // ProcessCancelRequests processes pending cancel requests for all strategies
 // C++: 对应 ORSCallBack 中的撤单处理逻辑
@@ -563,6 +876,7 @@ func (se *StrategyEngine) ProcessCancelRequests() {
 				Symbol:  order.Symbol,
 			})
 			cancel()
+			se.cancelFollowers(order.OrderId, order.Symbol)
 
 			if err != nil {
 				log.Printf("[StrategyEngine] Failed to send cancel for orderID=%s: %v",
@@ -683,6 +997,11 @@ func (se *StrategyEngine) PrintStatistics() {
 }
 
 // GetORSClient returns the ORS client instance
+// SHM 链路没有 ORS Gateway（持仓查询、在途订单查询不可用），返回 nil；
+// 依赖查询的功能在配置校验时拒绝（见 TraderConfig.validateSHMFeatures）
 func (se *StrategyEngine) GetORSClient() *client.ORSClient {
-	return se.orsClient
+	if t, ok := se.transport.(*natsTransport); ok {
+		return t.orsClient
+	}
+	return nil
 }
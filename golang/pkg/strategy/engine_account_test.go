@@ -0,0 +1,38 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/account"
+)
+
+func TestStrategyEngine_SubmitSignalFansOutToAccounts(t *testing.T) {
+	mgr := account.NewManager(account.Limits{})
+	for _, id := range []string{"main", "fund2"} {
+		if err := mgr.AddAccount(id, account.Limits{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mgr.Assign("test", []account.Allocation{{Account: "main", Scale: 1}, {Account: "fund2", Scale: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	se := NewStrategyEngine(&EngineConfig{})
+	se.SetAccountRouter(mgr)
+	se.submitSignal(context.Background(), &TradingSignal{
+		StrategyID: "test",
+		Symbol:     "ag2506",
+		Side:       OrderSideBuy,
+		OpenClose:  OpenCloseOpen,
+		Price:      8000,
+		Quantity:   2,
+	})
+
+	want := map[string]int64{"main": 2, "fund2": 4}
+	for _, snap := range mgr.Snapshot() {
+		if len(snap.Positions) != 1 || snap.Positions[0].PendingBuy != want[snap.Account] {
+			t.Errorf("%s positions = %+v, want pending buy %d", snap.Account, snap.Positions, want[snap.Account])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package strategy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+)
+
+// newDispatchBenchEngine creates a sync-mode engine with default shared indicators for each symbol
+func newDispatchBenchEngine(tb testing.TB, numSymbols int) (*StrategyEngine, []*mdpb.MarketDataUpdate) {
+	se := NewStrategyEngine(&EngineConfig{
+		OrderMode:     OrderModeSync,
+		TimerInterval: time.Second,
+	})
+
+	mds := make([]*mdpb.MarketDataUpdate, 0, numSymbols)
+	for i := 0; i < numSymbols; i++ {
+		symbol := fmt.Sprintf("sym%02d", i)
+		if err := se.InitializeSharedIndicators(symbol, nil); err != nil {
+			tb.Fatalf("InitializeSharedIndicators(%s): %v", symbol, err)
+		}
+		mds = append(mds, &mdpb.MarketDataUpdate{
+			Symbol:      symbol,
+			Exchange:    "SHFE",
+			BidPrice:    []float64{5000, 4999, 4998},
+			BidQty:      []uint32{10, 20, 30},
+			AskPrice:    []float64{5001, 5002, 5003},
+			AskQty:      []uint32{15, 25, 35},
+			LastPrice:   5000.5,
+			LastQty:     2,
+			TotalVolume: 1000,
+			Turnover:    5000500,
+		})
+	}
+
+	// Warm up: fill indicator windows and history rings
+	for i := 0; i < 2000; i++ {
+		md := mds[i%len(mds)]
+		md.BidPrice[0] = 5000 + float64(i%7)
+		md.AskPrice[0] = md.BidPrice[0] + 1
+		se.dispatchMarketDataSync(md)
+	}
+	return se, mds
+}
+
+// BenchmarkDispatchMarketDataSync benchmarks one tick through the sync dispatch path with 32 symbols
+func BenchmarkDispatchMarketDataSync(b *testing.B) {
+	se, mds := newDispatchBenchEngine(b, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		se.dispatchMarketDataSync(mds[i%len(mds)])
+	}
+}
+
+func TestDispatchMarketDataSync_SharedIndicatorsZeroAlloc(t *testing.T) {
+	se, mds := newDispatchBenchEngine(t, 32)
+	i := 0
+	allocs := testing.AllocsPerRun(1000, func() {
+		se.dispatchMarketDataSync(mds[i%len(mds)])
+		i++
+	})
+	if allocs > 0 {
+		t.Errorf("dispatchMarketDataSync allocates %.1f times per tick, expected 0", allocs)
+	}
+}
+
+func TestStrategyDataContext_GetIndicatorValues(t *testing.T) {
+	se, mds := newDispatchBenchEngine(t, 1)
+	shared, _ := se.GetSharedIndicators(mds[0].Symbol)
+
+	ctx := NewStrategyDataContext("test", "test")
+	ctx.SetSharedIndicators(shared)
+	ctx.ControlState.Indicators = map[string]float64{"z_score": 1.5}
+
+	values := ctx.GetIndicatorValues()
+	if len(values) != 5 {
+		t.Fatalf("Expected 4 shared + 1 control indicator, got %d: %v", len(values), values)
+	}
+	if values["z_score"] != 1.5 {
+		t.Errorf("Expected z_score 1.5, got %v", values["z_score"])
+	}
+}
@@ -0,0 +1,59 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// fakeJournal 记录写入次数，fail 时所有写入返回错误
+type fakeJournal struct {
+	fail                             bool
+	requests, acks, cancels, updates int
+}
+
+func (j *fakeJournal) err() error {
+	if j.fail {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func (j *fakeJournal) RecordOrderRequest(*orspb.OrderRequest) error { j.requests++; return j.err() }
+func (j *fakeJournal) RecordOrderAck(*orspb.OrderRequest, *orspb.OrderResponse) error {
+	j.acks++
+	return j.err()
+}
+func (j *fakeJournal) RecordCancelRequest(*orspb.CancelRequest) error { j.cancels++; return j.err() }
+func (j *fakeJournal) RecordOrderUpdate(*orspb.OrderUpdate) error     { j.updates++; return j.err() }
+
+func TestStrategyEngine_JournalWriteAhead(t *testing.T) {
+	j := &fakeJournal{}
+	se := NewStrategyEngine(&EngineConfig{})
+	se.SetOrderJournal(j)
+	ctx := context.Background()
+
+	if _, err := se.sendOrder(ctx, &orspb.OrderRequest{StrategyId: "s1", Symbol: "ag2506", Quantity: 1}); err != nil {
+		t.Fatalf("sendOrder: %v", err)
+	}
+	if _, err := se.cancelOrder(ctx, &orspb.CancelRequest{OrderId: "O1", StrategyId: "s1"}); err != nil {
+		t.Fatalf("cancelOrder: %v", err)
+	}
+	if j.requests != 1 || j.acks != 1 || j.cancels != 1 {
+		t.Errorf("journal = %+v, want 1 request, 1 ack, 1 cancel", *j)
+	}
+
+	// 日志写入失败：拒绝发送
+	j.fail = true
+	if resp, err := se.sendOrder(ctx, &orspb.OrderRequest{StrategyId: "s1", Symbol: "ag2506", Quantity: 1}); err == nil {
+		t.Errorf("sendOrder with failing journal = %v, want error", resp)
+	}
+	if resp, err := se.cancelOrder(ctx, &orspb.CancelRequest{OrderId: "O1", StrategyId: "s1"}); err == nil {
+		t.Errorf("cancelOrder with failing journal = %v, want error", resp)
+	}
+	if j.acks != 1 {
+		t.Errorf("acks = %d, want no ack for a refused order", j.acks)
+	}
+}
@@ -0,0 +1,43 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/offset"
+)
+
+func TestStrategyEngine_SubmitSignalUsesOffsetPlanner(t *testing.T) {
+	se := NewStrategyEngine(&EngineConfig{})
+	planner := offset.NewPlanner(offset.LockNever)
+	planner.SetPosition("ag2506", offset.Position{LongTd: 1, LongYd: 2})
+	se.SetOffsetPlanner(planner)
+
+	se.submitSignal(context.Background(), &TradingSignal{
+		StrategyID: "test",
+		Symbol:     "ag2506",
+		Exchange:   "SHFE",
+		Side:       OrderSideSell,
+		Price:      8000,
+		Quantity:   5,
+	})
+
+	// 平昨 2 + 平今 1 全部占用，开仓 2 手未成交不计入持仓
+	pos := planner.Position("ag2506")
+	if pos.LongTd != 0 || pos.LongYd != 0 || pos.ShortTd != 0 {
+		t.Errorf("position = %+v, want long reserved and no short yet", pos)
+	}
+
+	// 显式指定开平的信号不经过规划器
+	se.submitSignal(context.Background(), &TradingSignal{
+		StrategyID: "test",
+		Symbol:     "ag2506",
+		Side:       OrderSideBuy,
+		OpenClose:  OpenCloseOpen,
+		Price:      8000,
+		Quantity:   1,
+	})
+	if got := planner.Position("ag2506"); got != pos {
+		t.Errorf("position = %+v, want unchanged %+v", got, pos)
+	}
+}
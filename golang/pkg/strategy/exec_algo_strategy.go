@@ -0,0 +1,418 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/algo"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// ExecAlgoStrategyType 执行算法母单的策略类型名
+const ExecAlgoStrategyType = "exec_algo"
+
+// execAlgoAckTimeout 子单发出后迟迟没有回报（发送失败/被本地风控拦截）时按拒单处理
+const execAlgoAckTimeout = 5 * time.Second
+
+// ExecAlgoStrategy 执行算法母单宿主（Go 扩展）
+// 每个母单是一个独立的策略实例（策略 ID = 母单 ID），由 StrategyEngine 驱动：
+// 行情 → algo.OnQuote → 子单信号（GetSignals）；定时器 → 被动超时/结束时间 → 撤单（GetPendingCancels）。
+// 同一母单同时最多一个子单在途，回报按 StrategyId 归属；
+// 回报由引擎并发分发可能乱序，成交按每个订单的累计成交量计算增量
+type ExecAlgoStrategy struct {
+	*StrategyDataContext
+
+	mu   sync.Mutex
+	algo *algo.Algo
+	spec algo.Spec
+	now  func() time.Time
+
+	// 子单与订单
+	current       string   // 当前子单 ID（空 = 无在途子单）
+	currentOrders []string // 当前子单已确认的 order_id（开平拆单时可能多个）
+	placedAt      time.Time
+	cancelWanted  bool // 算法要求撤单但尚未收到 order_id
+	childOf       map[string]string
+	orderFilled   map[string]int64
+	orderValue    map[string]float64
+	orderDone     map[string]bool
+	cancels       []*orspb.OrderUpdate
+
+	estimatedPosition *EstimatedPosition
+	pnl               *PNL
+	riskMetrics       *RiskMetrics
+}
+
+// NewExecAlgoStrategy 创建母单宿主策略
+func NewExecAlgoStrategy(a *algo.Algo) *ExecAlgoStrategy {
+	spec := a.Spec()
+	s := &ExecAlgoStrategy{
+		StrategyDataContext: NewStrategyDataContext(spec.ID, ExecAlgoStrategyType),
+		algo:                a,
+		spec:                spec,
+		now:                 time.Now,
+		childOf:             make(map[string]string),
+		orderFilled:         make(map[string]int64),
+		orderValue:          make(map[string]float64),
+		orderDone:           make(map[string]bool),
+		estimatedPosition:   &EstimatedPosition{Symbol: spec.Symbol, Exchange: spec.Exchange},
+		pnl:                 &PNL{},
+		riskMetrics:         &RiskMetrics{},
+	}
+	s.Config = &StrategyConfig{
+		StrategyID:   spec.ID,
+		StrategyType: ExecAlgoStrategyType,
+		Symbols:      []string{spec.Symbol},
+		Parameters:   map[string]interface{}{},
+	}
+	s.StrategyDataContext.SetConcreteStrategy(s)
+	return s
+}
+
+// Initialize 母单参数在创建时给定，无需配置
+func (s *ExecAlgoStrategy) Initialize(config *StrategyConfig) error {
+	s.Status.StartTime = time.Now()
+	return nil
+}
+
+// Start starts the strategy
+func (s *ExecAlgoStrategy) Start() error {
+	s.ControlState.RunState = StrategyRunStateActive
+	s.Activate()
+	log.Printf("[ExecAlgo:%s] Started: %s %s %d %s", s.ID, s.spec.Type, s.spec.Side, s.spec.Qty, s.spec.Symbol)
+	return nil
+}
+
+// Stop 撤销母单（在途子单由引擎定时撤单）后停止
+func (s *ExecAlgoStrategy) Stop() error {
+	if !s.IsRunning() {
+		return fmt.Errorf("strategy not running")
+	}
+	s.Cancel()
+	s.ControlState.RunState = StrategyRunStateStopped
+	s.Deactivate()
+	return nil
+}
+
+// Cancel 撤销母单
+func (s *ExecAlgoStrategy) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apply(s.algo.Cancel(s.now()))
+}
+
+// Progress 返回母单进度
+func (s *ExecAlgoStrategy) Progress() algo.Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.algo.Progress()
+}
+
+// Done 母单已结束且没有在途子单
+func (s *ExecAlgoStrategy) Done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.algo.State().Terminal() && s.current == ""
+}
+
+// OnMarketData 用行情驱动算法
+func (s *ExecAlgoStrategy) OnMarketData(md *mdpb.MarketDataUpdate) {
+	if !s.IsRunning() || md.Symbol != s.spec.Symbol {
+		return
+	}
+	q := algo.Quote{
+		Time:        s.now(),
+		TotalVolume: int64(md.TotalVolume),
+		LastQty:     int64(md.LastQty),
+	}
+	if len(md.BidPrice) > 0 {
+		q.Bid = md.BidPrice[0]
+	}
+	if len(md.AskPrice) > 0 {
+		q.Ask = md.AskPrice[0]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apply(s.algo.OnQuote(q))
+	s.updatePNL(q.Bid, q.Ask)
+}
+
+// OnTimer 被动子单超时、结束时间、子单无回报检查
+// 定时器产生的新子单在下一笔行情时由引擎发出
+func (s *ExecAlgoStrategy) OnTimer(now time.Time) {
+	if !s.IsRunning() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now = s.now()
+	if s.current != "" && len(s.currentOrders) == 0 && now.Sub(s.placedAt) >= execAlgoAckTimeout {
+		log.Printf("[ExecAlgo:%s] Child %s got no order update in %v, treated as rejected", s.ID, s.current, execAlgoAckTimeout)
+		s.algo.OnChildDone(s.current, true)
+		s.current = ""
+		s.cancelWanted = false
+	}
+	s.apply(s.algo.OnTimer(now))
+}
+
+// OnOrderUpdate 归属子单、累计成交、子单结束
+func (s *ExecAlgoStrategy) OnOrderUpdate(update *orspb.OrderUpdate) {
+	if update.StrategyId != s.ID || update.OrderId == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oid := update.OrderId
+	childID, known := s.childOf[oid]
+	if !known {
+		childID = s.current
+		s.childOf[oid] = childID
+		if childID != "" {
+			s.currentOrders = append(s.currentOrders, oid)
+			if s.cancelWanted {
+				s.queueCancel(oid)
+			}
+		}
+	}
+
+	if update.FilledQty > s.orderFilled[oid] {
+		delta := update.FilledQty - s.orderFilled[oid]
+		price := update.LastFillPrice
+		if update.AvgPrice > 0 {
+			price = (update.AvgPrice*float64(update.FilledQty) - s.orderValue[oid]) / float64(delta)
+		} else if price == 0 {
+			price = update.Price
+		}
+		s.orderFilled[oid] = update.FilledQty
+		s.orderValue[oid] += price * float64(delta)
+		s.algo.OnFill(childID, delta, price)
+		s.updatePosition(delta, price)
+	}
+
+	switch update.Status {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_CANCELED, orspb.OrderStatus_REJECTED, orspb.OrderStatus_EXPIRED:
+	default:
+		return
+	}
+	s.orderDone[oid] = true
+	if childID == "" || childID != s.current {
+		return
+	}
+	for _, id := range s.currentOrders {
+		if !s.orderDone[id] {
+			return
+		}
+	}
+	rejected := update.Status == orspb.OrderStatus_REJECTED && update.FilledQty == 0
+	s.algo.OnChildDone(childID, rejected)
+	s.current = ""
+	s.currentOrders = nil
+	s.cancelWanted = false
+}
+
+// apply 将算法动作转换为信号/撤单（调用方持有 mu）
+func (s *ExecAlgoStrategy) apply(acts []algo.Action) {
+	for _, act := range acts {
+		switch act.Kind {
+		case algo.ActionPlace:
+			side := OrderSideBuy
+			if s.spec.Side == algo.Sell {
+				side = OrderSideSell
+			}
+			category := SignalCategoryPassive
+			if act.Aggressive {
+				category = SignalCategoryAggressive
+			}
+			s.current = act.ChildID
+			s.currentOrders = nil
+			s.cancelWanted = false
+			s.placedAt = s.now()
+			s.AddSignal(&TradingSignal{
+				StrategyID: s.ID,
+				Symbol:     s.spec.Symbol,
+				Exchange:   s.spec.Exchange,
+				Side:       side,
+				Price:      act.Price,
+				Quantity:   act.Qty,
+				OrderType:  OrderTypeLimit,
+				Timestamp:  s.placedAt,
+				Category:   category,
+				Metadata:   map[string]interface{}{"algo_child": act.ChildID},
+			})
+		case algo.ActionCancel:
+			if act.ChildID != s.current {
+				continue
+			}
+			s.cancelWanted = true
+			for _, oid := range s.currentOrders {
+				if !s.orderDone[oid] {
+					s.queueCancel(oid)
+				}
+			}
+		}
+	}
+}
+
+func (s *ExecAlgoStrategy) queueCancel(orderID string) {
+	s.cancels = append(s.cancels, &orspb.OrderUpdate{OrderId: orderID, Symbol: s.spec.Symbol, StrategyId: s.ID})
+}
+
+// updatePosition 按成交更新持仓（调用方持有 mu）
+func (s *ExecAlgoStrategy) updatePosition(qty int64, price float64) {
+	pos := s.estimatedPosition
+	if s.spec.Side == algo.Buy {
+		pos.BuyTotalQty += qty
+		pos.BuyTotalValue += float64(qty) * price
+		pos.BuyAvgPrice = pos.BuyTotalValue / float64(pos.BuyTotalQty)
+	} else {
+		pos.SellTotalQty += qty
+		pos.SellTotalValue += float64(qty) * price
+		pos.SellAvgPrice = pos.SellTotalValue / float64(pos.SellTotalQty)
+	}
+	pos.NetQty = pos.BuyTotalQty - pos.SellTotalQty
+	if pos.NetQty > 0 {
+		pos.BuyQty, pos.SellQty = pos.NetQty, 0
+	} else {
+		pos.BuyQty, pos.SellQty = 0, -pos.NetQty
+	}
+	pos.TodayQty = pos.BuyQty + pos.SellQty
+	pos.LastUpdate = time.Now()
+}
+
+// updatePNL 执行单只计浮动盈亏（相对成交均价按对手价估值）
+func (s *ExecAlgoStrategy) updatePNL(bid, ask float64) {
+	pos := s.estimatedPosition
+	switch {
+	case pos.NetQty > 0 && bid > 0:
+		pos.UnrealizedPnL = (bid - pos.BuyAvgPrice) * float64(pos.NetQty)
+	case pos.NetQty < 0 && ask > 0:
+		pos.UnrealizedPnL = (pos.SellAvgPrice - ask) * float64(-pos.NetQty)
+	}
+	s.pnl.UnrealizedPnL = pos.UnrealizedPnL
+	s.pnl.TotalPnL = pos.UnrealizedPnL
+	s.pnl.NetPnL = pos.UnrealizedPnL
+	s.pnl.Timestamp = time.Now()
+	s.riskMetrics.PositionSize = pos.NetQty
+}
+
+// GetSignals returns pending signals and clears the queue
+func (s *ExecAlgoStrategy) GetSignals() []*TradingSignal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.StrategyDataContext.GetSignals()
+}
+
+// GetPendingCancels returns orders pending cancellation
+func (s *ExecAlgoStrategy) GetPendingCancels() []*orspb.OrderUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.cancels
+	s.cancels = nil
+	return out
+}
+
+// TriggerFlatten 风控触发平仓时撤销母单（执行单不反向平仓）
+func (s *ExecAlgoStrategy) TriggerFlatten(reason FlattenReason, aggressive bool) {
+	s.ControlState.FlattenMode = true
+	s.Cancel()
+}
+
+// === C++ 虚函数对应（执行算法不使用）===
+
+// Reset resets the strategy to initial state
+func (s *ExecAlgoStrategy) Reset() {}
+
+// SendOrder 子单由 OnMarketData 产生
+func (s *ExecAlgoStrategy) SendOrder() {}
+
+// OnTradeUpdate is called after a trade is processed
+func (s *ExecAlgoStrategy) OnTradeUpdate() {}
+
+// CheckSquareoff checks if position needs to be squared off
+func (s *ExecAlgoStrategy) CheckSquareoff() {}
+
+// HandleSquareON handles square off initiation
+func (s *ExecAlgoStrategy) HandleSquareON() {
+	s.ControlState.FlattenMode = true
+}
+
+// HandleSquareoff executes the square off logic
+func (s *ExecAlgoStrategy) HandleSquareoff() {
+	s.Cancel()
+}
+
+// SetThresholds sets dynamic thresholds based on position
+func (s *ExecAlgoStrategy) SetThresholds() {}
+
+// OnAuctionData 集合竞价期间不下子单
+func (s *ExecAlgoStrategy) OnAuctionData(md *mdpb.MarketDataUpdate) {}
+
+// === Engine/Manager 需要的方法 ===
+
+// GetControlState returns the strategy control state
+func (s *ExecAlgoStrategy) GetControlState() *StrategyControlState {
+	return s.ControlState
+}
+
+// GetConfig returns the strategy configuration
+func (s *ExecAlgoStrategy) GetConfig() *StrategyConfig {
+	return s.Config
+}
+
+// CanSendOrder returns true if strategy can send orders
+func (s *ExecAlgoStrategy) CanSendOrder() bool {
+	return s.IsRunning() && s.ControlState.IsActivated() && !s.ControlState.FlattenMode
+}
+
+// GetEstimatedPosition returns current estimated position
+func (s *ExecAlgoStrategy) GetEstimatedPosition() *EstimatedPosition {
+	return s.estimatedPosition
+}
+
+// GetPosition returns current position (alias)
+func (s *ExecAlgoStrategy) GetPosition() *EstimatedPosition {
+	return s.estimatedPosition
+}
+
+// GetPNL returns current P&L
+func (s *ExecAlgoStrategy) GetPNL() *PNL {
+	return s.pnl
+}
+
+// GetRiskMetrics returns risk metrics
+func (s *ExecAlgoStrategy) GetRiskMetrics() *RiskMetrics {
+	return s.riskMetrics
+}
+
+// GetStatus returns strategy status
+func (s *ExecAlgoStrategy) GetStatus() *StrategyStatus {
+	s.Status.IsRunning = s.ControlState.IsActivated() && s.ControlState.RunState != StrategyRunStateStopped
+	s.Status.EstimatedPosition = s.estimatedPosition
+	s.Status.PNL = s.pnl
+	s.Status.RiskMetrics = s.riskMetrics
+	return s.Status
+}
+
+// UpdateParameters 母单参数不支持热更新（撤单后重新下母单）
+func (s *ExecAlgoStrategy) UpdateParameters(params map[string]interface{}) error {
+	return fmt.Errorf("exec algo %s parameters are immutable", s.ID)
+}
+
+// GetCurrentParameters returns the parent order spec
+func (s *ExecAlgoStrategy) GetCurrentParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type":              string(s.spec.Type),
+		"side":              string(s.spec.Side),
+		"qty":               s.spec.Qty,
+		"limit_price":       s.spec.LimitPrice,
+		"participation":     s.spec.Participation,
+		"min_participation": s.spec.MinParticipation,
+		"max_participation": s.spec.MaxParticipation,
+		"urgency":           s.spec.Urgency,
+	}
+}
@@ -0,0 +1,113 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/algo"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+func TestExecAlgoStrategy_ChildLifecycle(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+	now := t0
+	a, err := algo.New(algo.Spec{
+		ID: "twap_1", Symbol: "ag2502", Side: algo.Buy, Qty: 10, Type: algo.TypeTWAP,
+		End: t0.Add(10 * time.Minute), SliceSec: 600, PassiveWaitMs: 2000,
+	}, nil, t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewExecAlgoStrategy(a)
+	s.now = func() time.Time { return now }
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	md := &mdpb.MarketDataUpdate{Symbol: "ag2502", BidPrice: []float64{5000}, AskPrice: []float64{5001}, TotalVolume: 100}
+	s.OnMarketData(&mdpb.MarketDataUpdate{Symbol: "au2502", BidPrice: []float64{1}, AskPrice: []float64{2}})
+	s.OnMarketData(md)
+	sigs := s.GetSignals()
+	if len(sigs) != 1 || sigs[0].Price != 5000 || sigs[0].Quantity != 10 || sigs[0].Category != SignalCategoryPassive || sigs[0].StrategyID != "twap_1" {
+		t.Fatalf("signals = %+v", sigs)
+	}
+
+	upd := func(id string, status orspb.OrderStatus, filled int64, avg float64) {
+		s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: id, StrategyId: "twap_1", Symbol: "ag2502", Status: status, FilledQty: filled, AvgPrice: avg})
+	}
+	upd("O1", orspb.OrderStatus_ACCEPTED, 0, 0)
+	s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: "X", StrategyId: "other", FilledQty: 5, Status: orspb.OrderStatus_FILLED})
+
+	// 被动超时：撤 O1，撤单回报带 3 手成交
+	now = t0.Add(2 * time.Second)
+	s.OnTimer(now)
+	cancels := s.GetPendingCancels()
+	if len(cancels) != 1 || cancels[0].OrderId != "O1" {
+		t.Fatalf("cancels = %+v", cancels)
+	}
+	upd("O1", orspb.OrderStatus_CANCELED, 3, 5000)
+
+	// 剩余 7 手按卖一追单；回报乱序（全部成交先于部分成交）不重复计量
+	s.OnMarketData(md)
+	sigs = s.GetSignals()
+	if len(sigs) != 1 || sigs[0].Price != 5001 || sigs[0].Quantity != 7 || sigs[0].Category != SignalCategoryAggressive {
+		t.Fatalf("escalated signals = %+v", sigs)
+	}
+	upd("O2", orspb.OrderStatus_FILLED, 7, 5001)
+	upd("O2", orspb.OrderStatus_PARTIALLY_FILLED, 4, 5001)
+
+	p := s.Progress()
+	if p.State != algo.StateCompleted || p.Filled != 10 || p.PassiveFilled != 3 || p.AggressiveFilled != 7 {
+		t.Fatalf("progress = %+v", p)
+	}
+	if want := (3*5000.0 + 7*5001.0) / 10; p.AvgPrice != want {
+		t.Errorf("avg price = %v, want %v", p.AvgPrice, want)
+	}
+	if pos := s.GetEstimatedPosition(); pos.NetQty != 10 {
+		t.Errorf("net position = %d", pos.NetQty)
+	}
+	if !s.Done() {
+		t.Error("parent should be done")
+	}
+}
+
+func TestExecAlgoStrategy_CancelBeforeAckAndSendFailure(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+	now := t0
+	a, _ := algo.New(algo.Spec{ID: "pov_1", Symbol: "ag2502", Side: algo.Sell, Qty: 100, Type: algo.TypePOV, Participation: 0.5}, nil, t0)
+	s := NewExecAlgoStrategy(a)
+	s.now = func() time.Time { return now }
+	s.Start()
+
+	s.OnMarketData(&mdpb.MarketDataUpdate{Symbol: "ag2502", BidPrice: []float64{5000}, AskPrice: []float64{5001}, TotalVolume: 100})
+	s.OnMarketData(&mdpb.MarketDataUpdate{Symbol: "ag2502", BidPrice: []float64{5000}, AskPrice: []float64{5001}, TotalVolume: 120})
+	if sigs := s.GetSignals(); len(sigs) != 1 || sigs[0].Quantity != 10 {
+		t.Fatalf("signals = %+v", sigs)
+	}
+
+	// 子单发出后一直没有回报：超时按拒单处理，下一笔行情重新下单
+	now = t0.Add(execAlgoAckTimeout)
+	s.OnTimer(now)
+	s.OnMarketData(&mdpb.MarketDataUpdate{Symbol: "ag2502", BidPrice: []float64{5000}, AskPrice: []float64{5001}, TotalVolume: 120})
+	if sigs := s.GetSignals(); len(sigs) != 1 {
+		t.Fatalf("no child after ack timeout: %+v", sigs)
+	}
+
+	// 撤母单时订单号未知：收到回报后再撤
+	s.Cancel()
+	if c := s.GetPendingCancels(); len(c) != 0 {
+		t.Fatalf("cancel before order id: %+v", c)
+	}
+	s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: "O9", StrategyId: "pov_1", Status: orspb.OrderStatus_ACCEPTED})
+	if c := s.GetPendingCancels(); len(c) != 1 || c[0].OrderId != "O9" {
+		t.Fatalf("cancels = %+v", c)
+	}
+	if s.Done() {
+		t.Error("done with child still live")
+	}
+	s.OnOrderUpdate(&orspb.OrderUpdate{OrderId: "O9", StrategyId: "pov_1", Status: orspb.OrderStatus_CANCELED})
+	if !s.Done() || s.Progress().State != algo.StateCanceled {
+		t.Errorf("progress = %+v", s.Progress())
+	}
+}
@@ -1239,17 +1239,9 @@ func (es *ExecutionStrategy) Reset() {
 	es.SellTotalQty = 0
 	es.BuyTotalValue = 0
 	es.SellTotalValue = 0
-	es.TradeCount = 0
-	es.OrderCount = 0
-	es.CancelCount = 0
-	es.RejectCount = 0
-	es.ConfirmCount = 0
-	es.ImproveCount = 0
-	es.CrossCount = 0
+	es.resetDailyCounters()
 	es.BuyAggOrder = 0
 	es.SellAggOrder = 0
-	es.BuyAggCount = 0
-	es.SellAggCount = 0
 
 	// 清空订单映射
 	es.OrdMap = make(map[uint32]*OrderStats)
@@ -1265,6 +1257,46 @@ func (es *ExecutionStrategy) Reset() {
 	es.Drawdown = 0
 }
 
+// RollTradingDay 交易日切换：今仓转昨仓并清空日内统计（Go 扩展）
+// 与 Reset 不同：保留持仓、持仓均价和在途订单。
+// 未平持仓按成本结转到新交易日的累计买卖量/金额，平仓时 RealisedPNL
+// = (SellTotalValue - BuyTotalValue) * multiplier 仍然成立。
+// C++ 每个交易日重启进程并从 daily_init 恢复，无对应函数
+func (es *ExecutionStrategy) RollTradingDay() {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	es.NetPosPassYtd = es.NetPosPass
+
+	es.BuyTotalQty = es.BuyQty
+	es.BuyTotalValue = es.BuyQty * es.BuyAvgPrice
+	es.SellTotalQty = es.SellQty
+	es.SellTotalValue = es.SellQty * es.SellAvgPrice
+	es.TransTotalValue = 0
+	es.resetDailyCounters()
+
+	// 日内盈亏重新累计；UnrealisedPNL 下一笔行情按持仓成本重算
+	es.RealisedPNL = 0
+	es.NetPNL = 0
+	es.GrossPNL = 0
+	es.MaxPNL = 0
+	es.Drawdown = 0
+}
+
+// resetDailyCounters 清空订单/成交计数器（调用者已持有锁）
+func (es *ExecutionStrategy) resetDailyCounters() {
+	es.TradeCount = 0
+	es.OrderCount = 0
+	es.CancelCount = 0
+	es.RejectCount = 0
+	es.ConfirmCount = 0
+	es.ImproveCount = 0
+	es.CrossCount = 0
+	es.BuyAggCount = 0
+	es.SellAggCount = 0
+	es.CancelConfirmCount = 0
+}
+
 // ============================================================================
 // 共享内存方法
 // ============================================================================
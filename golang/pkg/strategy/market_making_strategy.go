@@ -0,0 +1,890 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/indicators"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// MarketMakingStrategyType 库存偏斜做市策略类型名
+const MarketMakingStrategyType = "market_making"
+
+// mmAckTimeout 报价发出后迟迟没有回报（发送失败/被本地风控拦截）时视为未挂出
+const mmAckTimeout = 5 * time.Second
+
+// 做市策略读取的指标名：引擎提供同名共享指标时优先使用，否则由策略私有创建
+const (
+	mmIndVolatility       = "volatility"
+	mmIndSpreadVolatility = "spread_volatility"
+	mmIndOrderArrival     = "order_arrival_rate"
+	mmIndTradeIntensity   = "trade_intensity"
+	mmIndOrderFlow        = "order_flow_imbalance"
+)
+
+// MarketMakingParams 做市参数，热更新时整体校验后替换
+// 价格相关的量都以 tick 为单位计算，与品种价格水平无关
+type MarketMakingParams struct {
+	OrderSize      int64   // order_size 基础报单量
+	MaxInventory   int64   // max_inventory 净持仓上限（多空对称）
+	RiskAversion   float64 // risk_aversion 风险厌恶系数 γ
+	Kappa          float64 // kappa 成交强度衰减系数 κ（1/tick），越大成交概率随报价距离衰减越快
+	HorizonTicks   float64 // horizon_ticks 库存持有期（行情笔数），单笔收益率方差按此放大
+	SpreadVolMult  float64 // spread_vol_mult 盘口价差波动率的加宽系数
+	MinSpreadTicks float64 // min_spread_ticks 报价价差下限（tick）
+	MaxSpreadTicks float64 // max_spread_ticks 报价价差上限（tick），0 表示不限
+	SizeDecay      float64 // size_decay 报单量衰减 η：加仓方向报单量 = order_size × exp(-η·|q|/max_inventory)
+	IntensityAlpha float64 // intensity_alpha 成交/挂单强度基线的 EMA 系数
+	ToxicFlow      float64 // toxic_flow_threshold |OFI| 达到时加宽被冲击一侧
+	PullFlow       float64 // pull_flow_threshold |OFI| 达到时撤掉被冲击一侧，0 表示不撤
+	AdverseWiden   float64 // adverse_widen 被冲击一侧的加宽倍数（× |OFI| × 半价差）
+	RequoteTicks   float64 // requote_ticks 目标价偏离挂单价达到此 tick 数才撤单重报
+	RefreshMs      int64   // order_refresh_ms 按行情时间计的最小重报间隔
+	TickSize       float64 // tick_size 最小变动价位，0 表示按品种规格
+}
+
+// DefaultMarketMakingParams 默认做市参数
+func DefaultMarketMakingParams() MarketMakingParams {
+	return MarketMakingParams{
+		OrderSize:      1,
+		MaxInventory:   10,
+		RiskAversion:   0.1,
+		Kappa:          1.5,
+		HorizonTicks:   10,
+		SpreadVolMult:  0.5,
+		MinSpreadTicks: 1,
+		MaxSpreadTicks: 20,
+		SizeDecay:      1.0,
+		IntensityAlpha: 0.01,
+		ToxicFlow:      0.3,
+		PullFlow:       0.7,
+		AdverseWiden:   1.0,
+		RequoteTicks:   1,
+		RefreshMs:      500,
+	}
+}
+
+// load 从参数表读取（YAML/JSON 数值均为 float64），返回是否有识别的参数
+func (p *MarketMakingParams) load(params map[string]interface{}) bool {
+	found := false
+	setFloat := func(key string, dst *float64) {
+		if v, ok := params[key].(float64); ok {
+			*dst = v
+			found = true
+		}
+	}
+	setInt := func(key string, dst *int64) {
+		switch v := params[key].(type) {
+		case float64:
+			*dst = int64(v)
+			found = true
+		case int:
+			*dst = int64(v)
+			found = true
+		case int64:
+			*dst = v
+			found = true
+		}
+	}
+	setInt("order_size", &p.OrderSize)
+	setInt("max_inventory", &p.MaxInventory)
+	setFloat("risk_aversion", &p.RiskAversion)
+	setFloat("kappa", &p.Kappa)
+	setFloat("horizon_ticks", &p.HorizonTicks)
+	setFloat("spread_vol_mult", &p.SpreadVolMult)
+	setFloat("min_spread_ticks", &p.MinSpreadTicks)
+	setFloat("max_spread_ticks", &p.MaxSpreadTicks)
+	setFloat("size_decay", &p.SizeDecay)
+	setFloat("intensity_alpha", &p.IntensityAlpha)
+	setFloat("toxic_flow_threshold", &p.ToxicFlow)
+	setFloat("pull_flow_threshold", &p.PullFlow)
+	setFloat("adverse_widen", &p.AdverseWiden)
+	setFloat("requote_ticks", &p.RequoteTicks)
+	setInt("order_refresh_ms", &p.RefreshMs)
+	setFloat("tick_size", &p.TickSize)
+	return found
+}
+
+// validate 参数合法性检查
+func (p *MarketMakingParams) validate() error {
+	switch {
+	case p.OrderSize <= 0:
+		return fmt.Errorf("invalid order_size (%d), must be > 0", p.OrderSize)
+	case p.MaxInventory < p.OrderSize:
+		return fmt.Errorf("invalid max_inventory (%d), must be >= order_size (%d)", p.MaxInventory, p.OrderSize)
+	case p.RiskAversion <= 0:
+		return fmt.Errorf("invalid risk_aversion (%.4f), must be > 0", p.RiskAversion)
+	case p.Kappa <= 0:
+		return fmt.Errorf("invalid kappa (%.4f), must be > 0", p.Kappa)
+	case p.HorizonTicks <= 0:
+		return fmt.Errorf("invalid horizon_ticks (%.1f), must be > 0", p.HorizonTicks)
+	case p.SpreadVolMult < 0 || p.SizeDecay < 0 || p.AdverseWiden < 0:
+		return fmt.Errorf("spread_vol_mult, size_decay and adverse_widen must be >= 0")
+	case p.MinSpreadTicks < 1:
+		return fmt.Errorf("invalid min_spread_ticks (%.1f), must be >= 1", p.MinSpreadTicks)
+	case p.MaxSpreadTicks != 0 && p.MaxSpreadTicks < p.MinSpreadTicks:
+		return fmt.Errorf("invalid max_spread_ticks (%.1f), must be 0 or >= min_spread_ticks", p.MaxSpreadTicks)
+	case p.IntensityAlpha <= 0 || p.IntensityAlpha > 1:
+		return fmt.Errorf("invalid intensity_alpha (%.4f), must be in (0, 1]", p.IntensityAlpha)
+	case p.ToxicFlow <= 0 || p.ToxicFlow > 1:
+		return fmt.Errorf("invalid toxic_flow_threshold (%.2f), must be in (0, 1]", p.ToxicFlow)
+	case p.PullFlow < 0 || p.PullFlow > 1 || (p.PullFlow > 0 && p.PullFlow < p.ToxicFlow):
+		return fmt.Errorf("invalid pull_flow_threshold (%.2f), must be 0 or in [toxic_flow_threshold, 1]", p.PullFlow)
+	case p.RequoteTicks < 0:
+		return fmt.Errorf("invalid requote_ticks (%.1f), must be >= 0", p.RequoteTicks)
+	case p.RefreshMs < 0:
+		return fmt.Errorf("invalid order_refresh_ms (%d), must be >= 0", p.RefreshMs)
+	case p.TickSize < 0:
+		return fmt.Errorf("invalid tick_size (%.4f), must be >= 0", p.TickSize)
+	}
+	return nil
+}
+
+// toMap 当前参数（GetCurrentParameters）
+func (p *MarketMakingParams) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"order_size":           p.OrderSize,
+		"max_inventory":        p.MaxInventory,
+		"risk_aversion":        p.RiskAversion,
+		"kappa":                p.Kappa,
+		"horizon_ticks":        p.HorizonTicks,
+		"spread_vol_mult":      p.SpreadVolMult,
+		"min_spread_ticks":     p.MinSpreadTicks,
+		"max_spread_ticks":     p.MaxSpreadTicks,
+		"size_decay":           p.SizeDecay,
+		"intensity_alpha":      p.IntensityAlpha,
+		"toxic_flow_threshold": p.ToxicFlow,
+		"pull_flow_threshold":  p.PullFlow,
+		"adverse_widen":        p.AdverseWiden,
+		"requote_ticks":        p.RequoteTicks,
+		"order_refresh_ms":     p.RefreshMs,
+		"tick_size":            p.TickSize,
+	}
+}
+
+// MarketInputs 报价模型的输入
+type MarketInputs struct {
+	Bid, Ask       float64 // 买一/卖一
+	Volatility     float64 // 单笔对数收益率标准差（Volatility 指标）
+	SpreadVol      float64 // 盘口价差标准差（SpreadVolatility 指标，价格单位）
+	IntensityRatio float64 // 当前成交+挂单强度 / 基线强度，1 表示正常
+	FlowImbalance  float64 // 订单流不平衡 [-1, 1]，正 = 主动买入占优
+	Position       int64   // 当前净持仓
+}
+
+// Quote 单边报价，Qty = 0 表示该侧不报
+type Quote struct {
+	Price float64
+	Qty   int64
+}
+
+// QuotePlan 一次报价计算的结果
+type QuotePlan struct {
+	Bid, Ask    Quote
+	Reservation float64 // 保留价（库存偏斜后的公允价）
+	HalfSpread  float64 // 最优半价差（价格单位，未计订单流加宽）
+	Kappa       float64 // 按强度调整后的 κ
+}
+
+// ComputeQuotes 按 Avellaneda-Stoikov 模型计算双边报价（以 tick 为单位）：
+//
+//	σ²        = (volatility × mid / tick)² × horizon_ticks
+//	保留价    r = mid − q·γ·σ²
+//	半价差    δ = γ·σ²/2 + ln(1 + γ/κ')/γ + spread_vol_mult·spread_vol/2，κ' = κ × 强度比
+//
+// 持仓越多保留价越低（多头时更愿意卖），波动越大价差越宽，市场越活跃价差越窄。
+// 订单流单边时对被冲击一侧加宽或撤价（主动买入占优 → 卖价容易被有信息的买方吃掉）。
+// 加仓方向报单量按持仓指数衰减，且不超过 max_inventory；报价不穿越对手盘口
+func (p *MarketMakingParams) ComputeQuotes(in MarketInputs, tick float64) QuotePlan {
+	var plan QuotePlan
+	if in.Bid <= 0 || in.Ask <= 0 || in.Ask < in.Bid || tick <= 0 {
+		return plan
+	}
+	mid := (in.Bid + in.Ask) / 2
+	q := float64(in.Position)
+
+	sigmaTicks := in.Volatility * mid / tick
+	variance := sigmaTicks * sigmaTicks * p.HorizonTicks
+	ratio := in.IntensityRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	ratio = math.Max(0.25, math.Min(4, ratio))
+	plan.Kappa = p.Kappa * ratio
+
+	gamma := p.RiskAversion
+	half := gamma*variance/2 + math.Log(1+gamma/plan.Kappa)/gamma + p.SpreadVolMult*in.SpreadVol/tick/2
+	half = math.Max(half, p.MinSpreadTicks/2)
+	if p.MaxSpreadTicks > 0 {
+		half = math.Min(half, p.MaxSpreadTicks/2)
+	}
+	plan.Reservation = mid - q*gamma*variance*tick
+	plan.HalfSpread = half * tick
+
+	bidHalf, askHalf := plan.HalfSpread, plan.HalfSpread
+	quoteBid, quoteAsk := true, true
+	if ofi := in.FlowImbalance; math.Abs(ofi) >= p.ToxicFlow {
+		widen := p.AdverseWiden * math.Abs(ofi) * plan.HalfSpread
+		pull := p.PullFlow > 0 && math.Abs(ofi) >= p.PullFlow
+		if ofi > 0 {
+			askHalf += widen
+			quoteAsk = !pull
+		} else {
+			bidHalf += widen
+			quoteBid = !pull
+		}
+	}
+
+	if quoteBid {
+		price := math.Min(FloorToTickSize(plan.Reservation-bidHalf+1e-9, tick), in.Ask-tick)
+		qty := p.OrderSize
+		if in.Position > 0 {
+			qty = int64(math.Round(float64(p.OrderSize) * math.Exp(-p.SizeDecay*q/float64(p.MaxInventory))))
+		}
+		qty = min(qty, p.MaxInventory-in.Position)
+		if qty > 0 && price > 0 {
+			plan.Bid = Quote{Price: price, Qty: qty}
+		}
+	}
+	if quoteAsk {
+		price := math.Max(CeilToTickSize(plan.Reservation+askHalf-1e-9, tick), in.Bid+tick)
+		qty := p.OrderSize
+		if in.Position < 0 {
+			qty = int64(math.Round(float64(p.OrderSize) * math.Exp(p.SizeDecay*q/float64(p.MaxInventory))))
+		}
+		qty = min(qty, p.MaxInventory+in.Position)
+		if qty > 0 {
+			plan.Ask = Quote{Price: price, Qty: qty}
+		}
+	}
+	return plan
+}
+
+// mmSide 单边挂单状态
+// 开平拆单时一个报价可能对应多个 order_id
+type mmSide struct {
+	price      float64
+	qty        int64
+	orders     []string
+	pending    bool // 信号已发出，尚未收到回报
+	cancelling bool // 已要求撤单，之后到达的 order_id 也立即撤
+	sentAt     time.Time
+}
+
+func (q *mmSide) active() bool {
+	return q.pending || len(q.orders) > 0
+}
+
+// MarketMakingStrategy 库存偏斜做市策略（Go 扩展）
+// 单品种双边挂单，报价由 ComputeQuotes 给出；每笔行情（按行情时间节流）比较目标报价与在途挂单，
+// 偏离超过 requote_ticks 或需要减量时先撤单，撤单完成后的下一笔行情再报新价，同一侧最多一笔报价在途。
+// 撤单由引擎定时器发出（GetPendingCancels），回报由引擎并发分发，持仓按每个订单的累计成交量计算增量
+type MarketMakingStrategy struct {
+	*ExecutionStrategy
+	*StrategyDataContext
+
+	mu     sync.Mutex
+	params MarketMakingParams
+	now    func() time.Time
+
+	symbol   string
+	exchange string
+	tickSize float64
+
+	bid, ask    mmSide
+	orderSide   map[string]orspb.OrderSide
+	orderFilled map[string]int64
+	orderValue  map[string]float64
+	orderDone   map[string]bool
+	cancels     []*orspb.OrderUpdate
+
+	// 行情状态
+	lastQuoteAt   time.Time // 上次重报的行情时间
+	intensityBase float64   // 成交+挂单强度基线（EMA）
+	lastPlan      QuotePlan
+	lastBid       float64
+	lastAsk       float64
+
+	estimatedPosition *EstimatedPosition
+	pnl               *PNL
+	riskMetrics       *RiskMetrics
+}
+
+// NewMarketMakingStrategy 创建做市策略
+func NewMarketMakingStrategy(id string) *MarketMakingStrategy {
+	s := &MarketMakingStrategy{
+		ExecutionStrategy:   NewExecutionStrategy(int32(hashStringToInt(id)), &Instrument{Symbol: "", TickSize: 1.0}),
+		StrategyDataContext: NewStrategyDataContext(id, MarketMakingStrategyType),
+		params:              DefaultMarketMakingParams(),
+		now:                 time.Now,
+		orderSide:           make(map[string]orspb.OrderSide),
+		orderFilled:         make(map[string]int64),
+		orderValue:          make(map[string]float64),
+		orderDone:           make(map[string]bool),
+		estimatedPosition:   &EstimatedPosition{},
+		pnl:                 &PNL{},
+		riskMetrics:         &RiskMetrics{},
+	}
+	s.StrategyDataContext.SetConcreteStrategy(s)
+	return s
+}
+
+// Initialize 读取参数并创建私有指标
+func (s *MarketMakingStrategy) Initialize(config *StrategyConfig) error {
+	if len(config.Symbols) == 0 {
+		return fmt.Errorf("market_making requires one symbol")
+	}
+	params := DefaultMarketMakingParams()
+	if config.MaxPositionSize > 0 {
+		params.MaxInventory = config.MaxPositionSize
+	}
+	params.load(config.Parameters)
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	s.Config = config
+	s.params = params
+	s.symbol = config.Symbols[0]
+	if len(config.Exchanges) > 0 {
+		s.exchange = config.Exchanges[0]
+	}
+	s.tickSize = s.resolveTickSize()
+	s.estimatedPosition.Symbol = s.symbol
+	s.estimatedPosition.Exchange = s.exchange
+
+	// 引擎没有提供的共享指标由策略私有创建（同名时 GetIndicator 优先取共享）
+	private := []struct {
+		name, kind string
+		config     map[string]interface{}
+	}{
+		{mmIndVolatility, "volatility", map[string]interface{}{"window": 50.0, "use_log_returns": true, "max_history": 200.0}},
+		{mmIndSpreadVolatility, "spread_volatility", map[string]interface{}{"window_size": 50.0, "max_history": 200.0}},
+		{mmIndOrderArrival, "order_arrival_rate", map[string]interface{}{"window_duration_sec": 30.0, "levels": 5.0, "max_history": 200.0}},
+		{mmIndTradeIntensity, "trade_intensity", map[string]interface{}{"window_seconds": 30.0, "max_history": 200.0}},
+		{mmIndOrderFlow, "order_flow_imbalance", map[string]interface{}{"window_size": 50.0, "max_history": 200.0}},
+	}
+	for _, ind := range private {
+		if s.SharedIndicators != nil {
+			if _, ok := s.SharedIndicators.Get(ind.name); ok {
+				continue
+			}
+		}
+		if _, err := s.PrivateIndicators.Create(ind.name, ind.kind, ind.config); err != nil {
+			return fmt.Errorf("failed to create %s indicator: %w", ind.name, err)
+		}
+	}
+
+	s.Status.StartTime = time.Now()
+	log.Printf("[MarketMaking:%s] Initialized: %s tick=%.4f size=%d max_inventory=%d gamma=%.4f kappa=%.4f",
+		s.ID, s.symbol, s.tickSize, params.OrderSize, params.MaxInventory, params.RiskAversion, params.Kappa)
+	return nil
+}
+
+// resolveTickSize tick_size 参数优先，否则按品种规格
+func (s *MarketMakingStrategy) resolveTickSize() float64 {
+	if s.params.TickSize > 0 {
+		return s.params.TickSize
+	}
+	return GetTickSize(s.symbol)
+}
+
+// Start starts the strategy
+func (s *MarketMakingStrategy) Start() error {
+	s.ControlState.RunState = StrategyRunStateActive
+	s.Activate()
+	log.Printf("[MarketMaking:%s] Started", s.ID)
+	return nil
+}
+
+// Stop 撤销全部报价后停止
+func (s *MarketMakingStrategy) Stop() error {
+	if !s.IsRunning() {
+		return fmt.Errorf("strategy not running")
+	}
+	s.mu.Lock()
+	s.cancelSide(&s.bid)
+	s.cancelSide(&s.ask)
+	s.mu.Unlock()
+	s.ControlState.RunState = StrategyRunStateStopped
+	s.Deactivate()
+	return nil
+}
+
+// OnMarketData 更新指标，按行情时间节流重算报价
+func (s *MarketMakingStrategy) OnMarketData(md *mdpb.MarketDataUpdate) {
+	if !s.IsRunning() || md.Symbol != s.symbol {
+		return
+	}
+	s.PrivateIndicators.UpdateAll(md)
+	if len(md.BidPrice) == 0 || len(md.AskPrice) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastBid, s.lastAsk = md.BidPrice[0], md.AskPrice[0]
+	s.updatePNL()
+
+	in := s.marketInputs()
+	mdTime := MarketDataTime(md)
+	if !s.lastQuoteAt.IsZero() && mdTime.Sub(s.lastQuoteAt) < time.Duration(s.params.RefreshMs)*time.Millisecond {
+		return
+	}
+	s.lastQuoteAt = mdTime
+
+	plan := s.params.ComputeQuotes(in, s.tickSize)
+	s.lastPlan = plan
+	s.ControlState.UpdateConditions(plan.Bid.Qty > 0 || plan.Ask.Qty > 0, float64(-in.Position)/float64(s.params.MaxInventory),
+		map[string]float64{
+			"mm_reservation":     plan.Reservation,
+			"mm_half_spread":     plan.HalfSpread,
+			"mm_kappa":           plan.Kappa,
+			"mm_intensity_ratio": in.IntensityRatio,
+			"mm_flow_imbalance":  in.FlowImbalance,
+			"mm_volatility":      in.Volatility,
+			"mm_position":        float64(in.Position),
+		})
+
+	if s.ControlState.FlattenMode {
+		s.cancelSide(&s.bid)
+		s.cancelSide(&s.ask)
+		return
+	}
+	s.requote(&s.bid, OrderSideBuy, plan.Bid)
+	s.requote(&s.ask, OrderSideSell, plan.Ask)
+}
+
+// marketInputs 汇总报价模型输入，同时更新强度基线（调用方持有 mu）
+func (s *MarketMakingStrategy) marketInputs() MarketInputs {
+	in := MarketInputs{
+		Bid:            s.lastBid,
+		Ask:            s.lastAsk,
+		Position:       s.estimatedPosition.NetQty,
+		IntensityRatio: 1,
+	}
+	if ind, ok := s.GetIndicator(mmIndVolatility); ok && ind.IsReady() {
+		in.Volatility = ind.GetValue()
+	}
+	if ind, ok := s.GetIndicator(mmIndSpreadVolatility); ok {
+		if sv, ok := ind.(*indicators.SpreadVolatility); ok {
+			in.SpreadVol = sv.GetVolatility()
+		}
+	}
+	if ind, ok := s.GetIndicator(mmIndOrderFlow); ok {
+		if ofi, ok := ind.(*indicators.OrderFlowImbalance); ok {
+			in.FlowImbalance = ofi.GetImbalance()
+		}
+	}
+
+	var intensity float64
+	if ind, ok := s.GetIndicator(mmIndOrderArrival); ok {
+		if oar, ok := ind.(*indicators.OrderArrivalRate); ok {
+			intensity += oar.GetTotalArrivalRate()
+		}
+	}
+	if ind, ok := s.GetIndicator(mmIndTradeIntensity); ok {
+		if ti, ok := ind.(*indicators.TradeIntensity); ok {
+			intensity += ti.GetIntensity()
+		}
+	}
+	if intensity > 0 {
+		if s.intensityBase <= 0 {
+			s.intensityBase = intensity
+		} else {
+			a := s.params.IntensityAlpha
+			s.intensityBase = a*intensity + (1-a)*s.intensityBase
+		}
+		in.IntensityRatio = intensity / s.intensityBase
+	}
+	return in
+}
+
+// requote 比较目标报价与在途挂单（调用方持有 mu）
+func (s *MarketMakingStrategy) requote(q *mmSide, side OrderSide, want Quote) {
+	if q.active() {
+		if q.cancelling {
+			return
+		}
+		drift := math.Abs(want.Price-q.price) >= math.Max(s.params.RequoteTicks, 0.5)*s.tickSize
+		if want.Qty == 0 || drift || want.Qty < q.qty {
+			s.cancelSide(q)
+		}
+		return
+	}
+	if want.Qty == 0 {
+		return
+	}
+
+	*q = mmSide{price: want.Price, qty: want.Qty, pending: true, sentAt: s.now()}
+	signal := 1.0
+	if side == OrderSideSell {
+		signal = -1.0
+	}
+	s.AddSignal(&TradingSignal{
+		StrategyID:  s.ID,
+		Symbol:      s.symbol,
+		Exchange:    s.exchange,
+		Side:        side,
+		Price:       want.Price,
+		Quantity:    want.Qty,
+		OrderType:   OrderTypeLimit,
+		TimeInForce: TimeInForceGTC,
+		Signal:      signal,
+		Confidence:  0.7,
+		Timestamp:   q.sentAt,
+		Category:    SignalCategoryPassive,
+		Metadata: map[string]interface{}{
+			"type":        "market_making",
+			"reservation": s.lastPlan.Reservation,
+			"half_spread": s.lastPlan.HalfSpread,
+			"position":    s.estimatedPosition.NetQty,
+		},
+	})
+}
+
+// cancelSide 撤销一侧全部挂单（调用方持有 mu）
+func (s *MarketMakingStrategy) cancelSide(q *mmSide) {
+	if !q.active() || q.cancelling {
+		return
+	}
+	q.cancelling = true
+	for _, oid := range q.orders {
+		if !s.orderDone[oid] {
+			s.queueCancel(oid)
+		}
+	}
+}
+
+func (s *MarketMakingStrategy) queueCancel(orderID string) {
+	s.cancels = append(s.cancels, &orspb.OrderUpdate{OrderId: orderID, Symbol: s.symbol, StrategyId: s.ID})
+}
+
+// sideOf 按买卖方向取对应一侧
+func (s *MarketMakingStrategy) sideOf(side orspb.OrderSide) *mmSide {
+	if side == orspb.OrderSide_BUY {
+		return &s.bid
+	}
+	return &s.ask
+}
+
+// OnOrderUpdate 归属报价、累计成交、报价结束
+func (s *MarketMakingStrategy) OnOrderUpdate(update *orspb.OrderUpdate) {
+	if update.StrategyId != s.ID || update.OrderId == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oid := update.OrderId
+	if _, known := s.orderSide[oid]; !known {
+		s.orderSide[oid] = update.Side
+		if q := s.sideOf(update.Side); q.active() {
+			q.orders = append(q.orders, oid)
+			q.pending = false
+			if q.cancelling {
+				s.queueCancel(oid)
+			}
+		}
+	}
+	s.Orders[oid] = update
+
+	if update.FilledQty > s.orderFilled[oid] {
+		delta := update.FilledQty - s.orderFilled[oid]
+		price := update.LastFillPrice
+		if update.AvgPrice > 0 {
+			price = (update.AvgPrice*float64(update.FilledQty) - s.orderValue[oid]) / float64(delta)
+		} else if price == 0 {
+			price = update.Price
+		}
+		s.orderFilled[oid] = update.FilledQty
+		s.orderValue[oid] += price * float64(delta)
+		s.applyFill(update.Side, delta, price)
+		s.updatePNL()
+	}
+
+	switch update.Status {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_CANCELED, orspb.OrderStatus_REJECTED, orspb.OrderStatus_EXPIRED:
+	default:
+		return
+	}
+	s.orderDone[oid] = true
+	delete(s.Orders, oid)
+	q := s.sideOf(update.Side)
+	for _, id := range q.orders {
+		if !s.orderDone[id] {
+			return
+		}
+	}
+	if len(q.orders) > 0 && !q.pending {
+		*q = mmSide{}
+	}
+}
+
+// OnTimer 报价无回报超时
+func (s *MarketMakingStrategy) OnTimer(now time.Time) {
+	if !s.IsRunning() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now = s.now()
+	for _, q := range []*mmSide{&s.bid, &s.ask} {
+		if q.pending && len(q.orders) == 0 && now.Sub(q.sentAt) >= mmAckTimeout {
+			log.Printf("[MarketMaking:%s] Quote %.4f x %d got no order update in %v, treated as rejected",
+				s.ID, q.price, q.qty, mmAckTimeout)
+			*q = mmSide{}
+		}
+	}
+}
+
+// applyFill 净持仓模型更新持仓与已实现盈亏（调用方持有 mu）
+func (s *MarketMakingStrategy) applyFill(side orspb.OrderSide, qty int64, price float64) {
+	pos := s.estimatedPosition
+	if side == orspb.OrderSide_BUY {
+		pos.BuyTotalQty += qty
+		pos.BuyTotalValue += float64(qty) * price
+		if pos.NetQty < 0 {
+			closed := min(qty, -pos.NetQty)
+			s.pnl.RealizedPnL += (pos.SellAvgPrice - price) * float64(closed)
+			pos.NetQty += closed
+			qty -= closed
+		}
+		if qty > 0 {
+			long := max(pos.NetQty, 0)
+			pos.BuyAvgPrice = (pos.BuyAvgPrice*float64(long) + price*float64(qty)) / float64(long+qty)
+			pos.NetQty += qty
+		}
+	} else {
+		pos.SellTotalQty += qty
+		pos.SellTotalValue += float64(qty) * price
+		if pos.NetQty > 0 {
+			closed := min(qty, pos.NetQty)
+			s.pnl.RealizedPnL += (price - pos.BuyAvgPrice) * float64(closed)
+			pos.NetQty -= closed
+			qty -= closed
+		}
+		if qty > 0 {
+			short := max(-pos.NetQty, 0)
+			pos.SellAvgPrice = (pos.SellAvgPrice*float64(short) + price*float64(qty)) / float64(short+qty)
+			pos.NetQty -= qty
+		}
+	}
+	pos.BuyQty, pos.SellQty = max(pos.NetQty, 0), max(-pos.NetQty, 0)
+	if pos.BuyQty == 0 {
+		pos.BuyAvgPrice = 0
+	}
+	if pos.SellQty == 0 {
+		pos.SellAvgPrice = 0
+	}
+	pos.RealizedPnL = s.pnl.RealizedPnL
+	pos.UpdateCompatibilityFields()
+	pos.LastUpdate = time.Now()
+}
+
+// updatePNL 按对手价估值浮动盈亏（调用方持有 mu）
+func (s *MarketMakingStrategy) updatePNL() {
+	pos := s.estimatedPosition
+	pos.UnrealizedPnL = 0
+	switch {
+	case pos.NetQty > 0 && s.lastBid > 0:
+		pos.UnrealizedPnL = (s.lastBid - pos.BuyAvgPrice) * float64(pos.NetQty)
+	case pos.NetQty < 0 && s.lastAsk > 0:
+		pos.UnrealizedPnL = (pos.SellAvgPrice - s.lastAsk) * float64(-pos.NetQty)
+	}
+	s.pnl.UnrealizedPnL = pos.UnrealizedPnL
+	s.pnl.TotalPnL = s.pnl.RealizedPnL + s.pnl.UnrealizedPnL
+	s.pnl.NetPnL = s.pnl.TotalPnL - s.pnl.TradingFees
+	s.pnl.Timestamp = time.Now()
+
+	s.riskMetrics.PositionSize = abs(pos.NetQty)
+	s.riskMetrics.MaxPositionSize = s.params.MaxInventory
+	s.riskMetrics.ExposureValue = float64(abs(pos.NetQty)) * (s.lastBid + s.lastAsk) / 2
+	s.riskMetrics.Timestamp = time.Now()
+}
+
+// GetSignals returns pending signals and clears the queue
+func (s *MarketMakingStrategy) GetSignals() []*TradingSignal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.StrategyDataContext.GetSignals()
+}
+
+// GetPendingCancels returns orders pending cancellation
+func (s *MarketMakingStrategy) GetPendingCancels() []*orspb.OrderUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.cancels
+	s.cancels = nil
+	return out
+}
+
+// TriggerFlatten 风控触发时撤销全部报价（持仓由人工或风控平仓处理）
+func (s *MarketMakingStrategy) TriggerFlatten(reason FlattenReason, aggressive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ControlState.FlattenMode = true
+	s.cancelSide(&s.bid)
+	s.cancelSide(&s.ask)
+}
+
+// === C++ 虚函数对应 ===
+
+// Reset resets the strategy to initial state
+func (s *MarketMakingStrategy) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ExecutionStrategy.Reset()
+	s.estimatedPosition = &EstimatedPosition{Symbol: s.symbol, Exchange: s.exchange}
+	s.pnl = &PNL{}
+	s.riskMetrics = &RiskMetrics{}
+	s.bid, s.ask = mmSide{}, mmSide{}
+	s.intensityBase = 0
+	s.lastQuoteAt = time.Time{}
+	s.PendingSignals = make([]*TradingSignal, 0)
+}
+
+// SendOrder 报价由 OnMarketData 产生
+func (s *MarketMakingStrategy) SendOrder() {}
+
+// OnTradeUpdate is called after a trade is processed
+func (s *MarketMakingStrategy) OnTradeUpdate() {}
+
+// CheckSquareoff 持仓上限由报单量上限保证
+func (s *MarketMakingStrategy) CheckSquareoff() {}
+
+// HandleSquareON handles square off initiation
+func (s *MarketMakingStrategy) HandleSquareON() {
+	s.TriggerFlatten(FlattenReasonNone, false)
+}
+
+// HandleSquareoff executes the square off logic
+func (s *MarketMakingStrategy) HandleSquareoff() {
+	s.ExecutionStrategy.HandleSquareoff()
+}
+
+// SetThresholds 价差由模型实时计算
+func (s *MarketMakingStrategy) SetThresholds() {}
+
+// OnAuctionData 集合竞价期间不报价
+func (s *MarketMakingStrategy) OnAuctionData(md *mdpb.MarketDataUpdate) {}
+
+// === Engine/Manager 需要的方法 ===
+
+// GetControlState returns the strategy control state
+func (s *MarketMakingStrategy) GetControlState() *StrategyControlState {
+	return s.ControlState
+}
+
+// GetConfig returns the strategy configuration
+func (s *MarketMakingStrategy) GetConfig() *StrategyConfig {
+	return s.Config
+}
+
+// CanSendOrder returns true if strategy can send orders
+func (s *MarketMakingStrategy) CanSendOrder() bool {
+	return s.IsRunning() && s.ControlState.IsActivated() && !s.ControlState.FlattenMode
+}
+
+// GetEstimatedPosition returns current estimated position
+func (s *MarketMakingStrategy) GetEstimatedPosition() *EstimatedPosition {
+	return s.estimatedPosition
+}
+
+// GetPosition returns current position (alias)
+func (s *MarketMakingStrategy) GetPosition() *EstimatedPosition {
+	return s.estimatedPosition
+}
+
+// GetPositionsBySymbol 实现 PositionProvider：做市品种净持仓
+func (s *MarketMakingStrategy) GetPositionsBySymbol() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.symbol == "" {
+		return map[string]int64{}
+	}
+	return map[string]int64{s.symbol: s.estimatedPosition.NetQty}
+}
+
+// GetPNL returns current P&L
+func (s *MarketMakingStrategy) GetPNL() *PNL {
+	return s.pnl
+}
+
+// GetRiskMetrics returns risk metrics
+func (s *MarketMakingStrategy) GetRiskMetrics() *RiskMetrics {
+	return s.riskMetrics
+}
+
+// GetStatus returns strategy status
+func (s *MarketMakingStrategy) GetStatus() *StrategyStatus {
+	s.Status.IsRunning = s.ControlState.IsActivated() && s.ControlState.RunState != StrategyRunStateStopped
+	s.Status.EstimatedPosition = s.estimatedPosition
+	s.Status.PNL = s.pnl
+	s.Status.RiskMetrics = s.riskMetrics
+	return s.Status
+}
+
+// UpdateParameters updates strategy parameters
+func (s *MarketMakingStrategy) UpdateParameters(params map[string]interface{}) error {
+	return s.ApplyParameters(params)
+}
+
+// GetCurrentParameters returns current strategy parameters
+func (s *MarketMakingStrategy) GetCurrentParameters() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.params.toMap()
+}
+
+// GetThresholds 报价参数（用于前端显示）
+func (s *MarketMakingStrategy) GetThresholds() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]float64)
+	for k, v := range s.params.toMap() {
+		switch n := v.(type) {
+		case float64:
+			out[k] = n
+		case int64:
+			out[k] = float64(n)
+		}
+	}
+	return out
+}
+
+// ApplyParameters 应用新参数（实现 ParameterUpdatable 接口）
+// 全部参数校验通过才生效；下一次重报按新参数计算，在途报价偏离时自然撤单重报
+func (s *MarketMakingStrategy) ApplyParameters(params map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.params
+	if !next.load(params) {
+		return fmt.Errorf("no valid parameters found to update")
+	}
+	if err := next.validate(); err != nil {
+		return err
+	}
+
+	old := s.params.toMap()
+	s.params = next
+	if s.symbol != "" {
+		s.tickSize = s.resolveTickSize()
+	}
+	s.lastQuoteAt = time.Time{}
+
+	log.Printf("[MarketMaking:%s] ✓ Parameters updated:", s.ID)
+	for k, v := range next.toMap() {
+		if old[k] != v {
+			log.Printf("[MarketMaking:%s]   %s: %v -> %v", s.ID, k, old[k], v)
+		}
+	}
+	return nil
+}
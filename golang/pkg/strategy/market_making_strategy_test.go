@@ -0,0 +1,187 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+func TestMarketMaking_ComputeQuotes(t *testing.T) {
+	p := DefaultMarketMakingParams()
+	p.OrderSize = 4
+	p.MaxInventory = 10
+	p.HorizonTicks = 10
+	in := MarketInputs{Bid: 5000, Ask: 5001, IntensityRatio: 1}
+
+	// 空仓、无波动：以中间价对称报价，半价差 = ln(1+γ/κ)/γ ≈ 0.645 tick
+	plan := p.ComputeQuotes(in, 1)
+	if plan.Reservation != 5000.5 || plan.Bid != (Quote{4999, 4}) || plan.Ask != (Quote{5002, 4}) {
+		t.Fatalf("flat plan = %+v", plan)
+	}
+
+	// 多头 2 手、单笔波动 1 tick：保留价下移 2 tick，买量按持仓衰减，卖价不穿越买一
+	in.Volatility = 1 / 5000.5
+	in.Position = 2
+	plan = p.ComputeQuotes(in, 1)
+	if plan.Reservation != 4998.5 || plan.Bid != (Quote{4997, 3}) || plan.Ask != (Quote{5001, 4}) {
+		t.Fatalf("long plan = %+v", plan)
+	}
+
+	// 持仓到上限：不再报加仓方向
+	in.Position = 10
+	if plan = p.ComputeQuotes(in, 1); plan.Bid.Qty != 0 || plan.Ask.Qty != 4 {
+		t.Fatalf("max inventory plan = %+v", plan)
+	}
+
+	// 市场越活跃价差越窄
+	in = MarketInputs{Bid: 5000, Ask: 5001, IntensityRatio: 4}
+	busy := p.ComputeQuotes(in, 1)
+	in.IntensityRatio = 0.25
+	quiet := p.ComputeQuotes(in, 1)
+	if busy.HalfSpread >= quiet.HalfSpread || busy.Kappa != 6 {
+		t.Errorf("half spread busy = %.4f quiet = %.4f kappa = %.4f", busy.HalfSpread, quiet.HalfSpread, busy.Kappa)
+	}
+}
+
+func TestMarketMaking_AdverseSelection(t *testing.T) {
+	p := DefaultMarketMakingParams()
+	p.AdverseWiden = 4
+	in := MarketInputs{Bid: 5000, Ask: 5001, IntensityRatio: 1}
+
+	// 主动买入占优：卖价加宽，买价不变
+	in.FlowImbalance = 0.5
+	plan := p.ComputeQuotes(in, 1)
+	if plan.Bid.Price != 4999 || plan.Ask.Price != 5003 {
+		t.Fatalf("toxic buy flow plan = %+v", plan)
+	}
+
+	// 超过撤价阈值：不报卖价
+	in.FlowImbalance = 0.8
+	if plan = p.ComputeQuotes(in, 1); plan.Ask.Qty != 0 || plan.Bid.Qty == 0 {
+		t.Fatalf("pull ask plan = %+v", plan)
+	}
+	in.FlowImbalance = -0.8
+	if plan = p.ComputeQuotes(in, 1); plan.Bid.Qty != 0 || plan.Ask.Qty == 0 {
+		t.Fatalf("pull bid plan = %+v", plan)
+	}
+}
+
+func TestMarketMaking_QuoteLifecycle(t *testing.T) {
+	s, err := NewStrategy(MarketMakingStrategyType, "mm_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm := s.(*MarketMakingStrategy)
+	err = mm.Initialize(&StrategyConfig{
+		StrategyID: "mm_1",
+		Symbols:    []string{"ag2603"},
+		Exchanges:  []string{"SHFE"},
+		Parameters: map[string]interface{}{
+			"order_size":       2.0,
+			"max_inventory":    4.0,
+			"tick_size":        1.0,
+			"min_spread_ticks": 3.0,
+			"max_spread_ticks": 3.0,
+			"order_refresh_ms": 0.0,
+			"horizon_ticks":    0.001, // 行情跳动带来的波动不影响保留价，便于核对报价
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mm.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := uint64(time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local).UnixNano())
+	tick := func(bid, ask float64) []*TradingSignal {
+		ts += uint64(time.Second)
+		mm.OnMarketData(&mdpb.MarketDataUpdate{
+			Symbol: "ag2603", Timestamp: ts, LastPrice: (bid + ask) / 2,
+			BidPrice: []float64{bid}, AskPrice: []float64{ask}, BidQty: []uint32{10}, AskQty: []uint32{10},
+		})
+		return mm.GetSignals()
+	}
+	upd := func(id string, side orspb.OrderSide, status orspb.OrderStatus, filled int64, avg float64) {
+		mm.OnOrderUpdate(&orspb.OrderUpdate{OrderId: id, StrategyId: "mm_1", Symbol: "ag2603", Side: side, Status: status, FilledQty: filled, AvgPrice: avg})
+	}
+
+	sigs := tick(5000, 5001)
+	if len(sigs) != 2 || sigs[0].Side != OrderSideBuy || sigs[0].Price != 4999 || sigs[0].Quantity != 2 ||
+		sigs[1].Side != OrderSideSell || sigs[1].Price != 5002 || sigs[1].Category != SignalCategoryPassive {
+		t.Fatalf("initial quotes = %+v", sigs)
+	}
+	upd("B1", orspb.OrderSide_BUY, orspb.OrderStatus_ACCEPTED, 0, 0)
+	upd("A1", orspb.OrderSide_SELL, orspb.OrderStatus_ACCEPTED, 0, 0)
+	if sigs = tick(5000, 5001); len(sigs) != 0 {
+		t.Fatalf("unchanged market should not requote: %+v", sigs)
+	}
+
+	// 买单成交：多头 2 手，买量衰减到 1 手
+	upd("B1", orspb.OrderSide_BUY, orspb.OrderStatus_FILLED, 2, 4999)
+	if pos := mm.GetEstimatedPosition(); pos.NetQty != 2 || pos.BuyAvgPrice != 4999 {
+		t.Fatalf("position = %+v", pos)
+	}
+	if got := mm.GetPositionsBySymbol(); len(got) != 1 || got["ag2603"] != 2 {
+		t.Errorf("positions by symbol = %v, want ag2603:2", got)
+	}
+	sigs = tick(5000, 5001)
+	if len(sigs) != 1 || sigs[0].Side != OrderSideBuy || sigs[0].Quantity != 1 {
+		t.Fatalf("quotes after fill = %+v", sigs)
+	}
+	upd("B2", orspb.OrderSide_BUY, orspb.OrderStatus_ACCEPTED, 0, 0)
+
+	// 行情上移：两侧偏离，撤单后才重报
+	if sigs = tick(5010, 5011); len(sigs) != 0 {
+		t.Fatalf("should cancel before requote: %+v", sigs)
+	}
+	cancels := mm.GetPendingCancels()
+	if len(cancels) != 2 || cancels[0].OrderId != "B2" || cancels[1].OrderId != "A1" {
+		t.Fatalf("cancels = %+v", cancels)
+	}
+	upd("A1", orspb.OrderSide_SELL, orspb.OrderStatus_CANCELED, 0, 0)
+	sigs = tick(5010, 5011)
+	if len(sigs) != 1 || sigs[0].Side != OrderSideSell || sigs[0].Price != 5012 || sigs[0].Quantity != 2 {
+		t.Fatalf("requote = %+v", sigs)
+	}
+	if pnl := mm.GetPNL(); pnl.UnrealizedPnL != 22 {
+		t.Errorf("unrealized = %.2f, want 22", pnl.UnrealizedPnL)
+	}
+
+	// 卖单成交平仓：已实现盈亏
+	upd("A2", orspb.OrderSide_SELL, orspb.OrderStatus_FILLED, 2, 5012)
+	if pos := mm.GetEstimatedPosition(); pos.NetQty != 0 || mm.GetPNL().RealizedPnL != 26 {
+		t.Fatalf("position = %+v realized = %.2f", pos, mm.GetPNL().RealizedPnL)
+	}
+
+	// 风控平仓模式：撤掉剩余报价
+	upd("B2", orspb.OrderSide_BUY, orspb.OrderStatus_CANCELED, 0, 0)
+	tick(5010, 5011)
+	upd("B3", orspb.OrderSide_BUY, orspb.OrderStatus_ACCEPTED, 0, 0)
+	mm.TriggerFlatten(FlattenReasonNone, false)
+	if cancels = mm.GetPendingCancels(); len(cancels) != 1 || cancels[0].OrderId != "B3" {
+		t.Fatalf("flatten cancels = %+v", cancels)
+	}
+}
+
+func TestMarketMaking_ApplyParameters(t *testing.T) {
+	mm := NewMarketMakingStrategy("mm_1")
+	if err := mm.ApplyParameters(map[string]interface{}{"unknown": 1.0}); err == nil {
+		t.Error("expected error for unknown parameters")
+	}
+	if err := mm.ApplyParameters(map[string]interface{}{"order_size": 3.0, "risk_aversion": -1.0}); err == nil {
+		t.Error("expected error for negative risk_aversion")
+	}
+	if got := mm.GetCurrentParameters()["order_size"]; got != int64(1) {
+		t.Errorf("order_size = %v after rejected update, want 1", got)
+	}
+	if err := mm.UpdateParameters(map[string]interface{}{"order_size": 3.0, "kappa": 2.0}); err != nil {
+		t.Fatal(err)
+	}
+	params := mm.GetCurrentParameters()
+	if params["order_size"] != int64(3) || params["kappa"] != 2.0 {
+		t.Errorf("params = %v", params)
+	}
+}
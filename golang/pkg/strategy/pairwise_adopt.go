@@ -0,0 +1,57 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// AdoptOrder 实现 OrderAdopter：接管上一进程遗留在柜台上的在途订单（Go 扩展）
+// 订单登记到 leg1OrderMap/leg2OrderMap（与 updateOrderMaps 相同），之后的成交/撤单回报按正常订单处理；
+// leg2 主动单（metadata order_category=aggressive）同时累加 agg 计数，终态回报时由 handleAggOrder 递减。
+// 同价位已有订单时返回错误，由调用方撤单（价格映射一价一单）
+func (pas *PairwiseArbStrategy) AdoptOrder(update *orspb.OrderUpdate) error {
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+
+	var orderMap *OrderPriceMap
+	switch update.Symbol {
+	case pas.symbol1:
+		orderMap = pas.leg1OrderMap
+	case pas.symbol2:
+		orderMap = pas.leg2OrderMap
+	default:
+		return fmt.Errorf("symbol %s is not a leg of strategy %s", update.Symbol, pas.ID)
+	}
+
+	if update.Status != orspb.OrderStatus_ACCEPTED && update.Status != orspb.OrderStatus_PARTIALLY_FILLED {
+		return fmt.Errorf("order %s status %v is not a confirmed live order", update.OrderId, update.Status)
+	}
+	if orderMap.GetOrderByID(update.OrderId) != nil {
+		return nil // 已在订单表中
+	}
+
+	side := OrderSideSell
+	if update.Side == orspb.OrderSide_BUY {
+		side = OrderSideBuy
+	}
+	if orderMap.HasOrderAtPrice(update.Price, side) {
+		return fmt.Errorf("price level %.4f (%v) of %s already has an order", update.Price, update.Side, update.Symbol)
+	}
+
+	pas.Orders[update.OrderId] = update
+	pas.updateOrderMaps(update)
+
+	if update.Symbol == pas.symbol2 && update.Metadata["order_category"] == "aggressive" && pas.secondStrat != nil {
+		if update.Side == orspb.OrderSide_BUY {
+			pas.secondStrat.BuyAggOrder++
+		} else {
+			pas.secondStrat.SellAggOrder++
+		}
+	}
+
+	log.Printf("[PairwiseArb:%s] Adopted live order %s %s %v %d@%.2f filled=%d",
+		pas.ID, update.OrderId, update.Symbol, update.Side, update.Quantity, update.Price, update.FilledQty)
+	return nil
+}
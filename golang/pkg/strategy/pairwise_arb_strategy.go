@@ -11,6 +11,8 @@ import (
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
 	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
 	"github.com/yourusername/quantlink-trade-system/pkg/strategy/spread"
+
+	"tbsrc-golang/pkg/auction"
 )
 
 // PairwiseArbStrategy implements a statistical arbitrage / pairs trading strategy
@@ -46,9 +48,18 @@ type PairwiseArbStrategy struct {
 	maxPositionSize   int64   // Maximum position per leg (default: 50)
 	minCorrelation    float64 // Minimum correlation to trade (default: 0.7)
 	hedgeRatio        float64 // 对冲比率，当前固定为 1.0（同品种跨期套利）
-	spreadType        string  // "ratio" or "difference" (default: "difference")
+	spreadType        string  // "difference", "ratio" or "log" (default: "difference")
 	useCointegration  bool    // Use cointegration instead of correlation (default: false)
 
+	// 动态对冲比率（hedge_method: kalman），只影响价差计算，下单数量仍为 1:1
+	hedgeMethod  string  // "fixed"（默认，C++ 一致）或 "kalman"
+	kalmanQAlpha float64 // 截距过程噪声方差
+	kalmanQBeta  float64 // 对冲比率过程噪声方差
+	kalmanR      float64 // 观测噪声方差
+
+	// spreadDef 价差定义（两腿 1:-1，含合约乘数），用于把两腿当作一个对象计算净敞口
+	spreadDef *spread.Definition
+
 	// State
 	price1            float64
 	price2            float64
@@ -76,6 +87,12 @@ type PairwiseArbStrategy struct {
 	longExitZScore      float64       // 满仓多头时撤单阈值 (LONG_REMOVE)
 	shortExitZScore     float64       // 满仓空头时撤单阈值 (SHORT_REMOVE)
 
+	// 高波动阈值组（C++: BEGIN_PLACE_HIGH / LONG_PLACE_HIGH, SET_HIGH）
+	// regimeSwitch 判定为高状态时替换 beginZScore / longZScore，0 表示不替换
+	beginZScoreHigh float64
+	longZScoreHigh  float64
+	regimeSwitch    *RegimeSwitch // nil 表示未启用市场状态切换
+
 	// 主动追单参数（参考旧系统 SendAggressiveOrder）
 	aggressiveEnabled       bool          // 是否启用追单
 	aggressiveInterval      time.Duration // 追单间隔
@@ -127,6 +144,12 @@ type PairwiseArbStrategy struct {
 	avgSpreadRatio_ori float64 // C++: avgSpreadRatio_ori - 原始价差均值（从 daily_init 加载）
 	tValue             float64 // 外部调整值（正值提高均值，负值降低均值）
 
+	// === 集合竞价（Go 扩展，见 pairwise_auction.go） ===
+	auction1    auction.Result // Leg1 最近一次指示性撮合结果
+	auction2    auction.Result // Leg2 最近一次指示性撮合结果
+	inAuction   bool           // 当前处于集合竞价
+	auctionSent bool           // 本次竞价已报单
+
 	// === 风控字段 (C++: PairwiseArbStrategy.h) ===
 	maxLossLimit  float64 // m_maxloss_limit - 最大亏损限制
 	isValidMkdata bool    // is_valid_mkdata - 行情数据是否有效
@@ -198,6 +221,10 @@ func NewPairwiseArbStrategy(id string) *PairwiseArbStrategy {
 		maxPositionSize:  50,
 		minCorrelation:   0.7,
 		hedgeRatio:       1.0,
+		hedgeMethod:      "fixed",
+		kalmanQAlpha:     1e-4,
+		kalmanQBeta:      1e-8,
+		kalmanR:          1.0,
 		spreadType:       "difference",
 		useCointegration: false,
 		minTradeInterval: 3 * time.Second,
@@ -303,10 +330,26 @@ func (pas *PairwiseArbStrategy) Initialize(config *StrategyConfig) error {
 
 	// 初始化 SpreadAnalyzer（现在知道 symbol 和 spread_type 了）
 	spreadType := spread.SpreadTypeDifference
-	if pas.spreadType == "ratio" {
+	switch pas.spreadType {
+	case "ratio":
 		spreadType = spread.SpreadTypeRatio
+	case "log":
+		spreadType = spread.SpreadTypeLog
 	}
 	pas.spreadAnalyzer = spread.NewSpreadAnalyzer(pas.symbol1, pas.symbol2, spreadType, 200)
+	def, err := spread.NewPairDefinition(pas.symbol1, pas.symbol2, spreadType, 1,
+		GetContractMultiplier(pas.symbol1), GetContractMultiplier(pas.symbol2))
+	if err != nil {
+		return fmt.Errorf("invalid spread definition: %w", err)
+	}
+	pas.spreadDef = def
+	pas.loadHedgeParams(config.Parameters)
+	if pas.hedgeMethod == "kalman" {
+		if spreadType != spread.SpreadTypeDifference {
+			return fmt.Errorf("hedge_method kalman requires spread_type difference, got %s", pas.spreadType)
+		}
+		pas.spreadAnalyzer.EnableKalmanHedge(pas.kalmanQAlpha, pas.kalmanQBeta, pas.kalmanR)
+	}
 	if val, ok := config.Parameters["use_cointegration"].(bool); ok {
 		pas.useCointegration = val
 	}
@@ -357,6 +400,16 @@ func (pas *PairwiseArbStrategy) Initialize(config *StrategyConfig) error {
 		// 如果配置了 long_zscore 和 short_zscore，则自动启用
 		pas.useDynamicThreshold = pas.longZScore > 0 && pas.shortZScore > 0
 	}
+	// 高波动阈值组 + 市场状态切换
+	if val, ok := config.Parameters["begin_zscore_high"].(float64); ok {
+		pas.beginZScoreHigh = val
+	}
+	if val, ok := config.Parameters["long_zscore_high"].(float64); ok {
+		pas.longZScoreHigh = val
+	}
+	if val, ok := config.Parameters["regime_switch_enabled"].(bool); ok && val {
+		pas.regimeSwitch = NewRegimeSwitch(LoadRegimeSwitchConfig(config.Parameters))
+	}
 	// 初始化运行时阈值
 	pas.entryZScoreBid = pas.beginZScore
 	pas.entryZScoreAsk = pas.beginZScore
@@ -460,6 +513,14 @@ func (pas *PairwiseArbStrategy) Initialize(config *StrategyConfig) error {
 		log.Printf("[PairwiseArbStrategy:%s] Dynamic threshold enabled: begin=%.2f, long=%.2f, short=%.2f",
 			pas.ID, pas.beginZScore, pas.longZScore, pas.shortZScore)
 	}
+	if pas.hedgeMethod == "kalman" {
+		log.Printf("[PairwiseArbStrategy:%s] Kalman hedge ratio enabled: q_alpha=%g, q_beta=%g, r=%g",
+			pas.ID, pas.kalmanQAlpha, pas.kalmanQBeta, pas.kalmanR)
+	}
+	if pas.regimeSwitch != nil {
+		log.Printf("[PairwiseArbStrategy:%s] Regime switch enabled: begin_high=%.2f, long_high=%.2f, high_prob=%.2f, max_hurst=%.2f, hold_bars=%d",
+			pas.ID, pas.beginZScoreHigh, pas.longZScoreHigh, pas.regimeSwitch.HighProb, pas.regimeSwitch.MaxHurst, pas.regimeSwitch.HoldBars)
+	}
 	if pas.aggressiveEnabled {
 		log.Printf("[PairwiseArbStrategy:%s] Aggressive order enabled: interval=%v, max_retry=%d, slop_ticks=%d",
 			pas.ID, pas.aggressiveInterval, pas.aggressiveMaxRetry, pas.aggressiveSlopTicks)
@@ -514,6 +575,7 @@ func (pas *PairwiseArbStrategy) Initialize(config *StrategyConfig) error {
 	pas.tholdFirst.MaxSize = int32(pas.maxPositionSize)
 	pas.tholdFirst.Size = int32(pas.orderSize)
 	pas.tholdFirst.Slop = float64(pas.aggressiveSlopTicks)
+	pas.tholdFirst.loadAuction(config.Parameters)
 
 	// 将阈值配置关联到 firstStrat（C++: m_firstStrat->m_thold = m_thold_first）
 	pas.firstStrat.Thold = pas.tholdFirst
@@ -610,6 +672,7 @@ func (pas *PairwiseArbStrategy) OnMarketData(md *mdpb.MarketDataUpdate) {
 	if !pas.running {
 		return
 	}
+	pas.endAuction()
 
 	// 从共享内存加载 tValue（C++: PairwiseArbStrategy.cpp:482-485）
 	// if (m_tvar) {
@@ -693,6 +756,17 @@ func (pas *PairwiseArbStrategy) OnMarketData(md *mdpb.MarketDataUpdate) {
 		pas.avgSpreadRatio_ori = (1-alpha)*pas.avgSpreadRatio_ori + alpha*currentSpread
 	}
 
+	// 市场状态识别（驱动 setDynamicThresholds 的高/低阈值组切换）
+	if pas.regimeSwitch != nil {
+		wasHigh := pas.regimeSwitch.IsHigh()
+		pas.regimeSwitch.Update(pas.spreadAnalyzer.GetStats().CurrentSpread)
+		if wasHigh != pas.regimeSwitch.IsHigh() {
+			log.Printf("[PairwiseArb:%s] Regime switch: high=%v (vol=%v, trending=%v, change=%v)",
+				pas.ID, pas.regimeSwitch.IsHigh(), pas.regimeSwitch.VolHigh(),
+				pas.regimeSwitch.Trending(), pas.regimeSwitch.ChangePoint())
+		}
+	}
+
 	// Update PNL (配对策略专用计算：分别计算两腿)
 	pas.updatePairwisePNL()
 
@@ -734,6 +808,16 @@ func (pas *PairwiseArbStrategy) OnMarketData(md *mdpb.MarketDataUpdate) {
 		"leg2_position": float64(pas.leg2Position),
 		// Exposure (敞口)
 		"exposure":      float64(exposure),
+		"exposure_value": pas.riskMetrics.ExposureValue,
+		"gross_exposure": pas.riskMetrics.GrossExposure,
+	}
+	if pas.regimeSwitch != nil {
+		pas.regimeSwitch.FillIndicators(indicators)
+	}
+	if pas.hedgeMethod == "kalman" {
+		indicators["kalman_alpha"] = spreadStats.Intercept
+		indicators["spread_residual"] = spreadStats.Residual
+		indicators["spread_residual_var"] = spreadStats.ResidualVar
 	}
 
 	// Conditions are met if:
@@ -917,6 +1001,9 @@ func (pas *PairwiseArbStrategy) generateSpreadSignals(md *mdpb.MarketDataUpdate,
 			"z_score":     spreadStats.ZScore,
 			"spread":      spreadStats.CurrentSpread,
 			"hedge_ratio": spreadStats.HedgeRatio,
+
+			MetaFairSpread:     spreadStats.Mean + pas.tValue,
+			MetaEntryThreshold: pas.entryThreshold(direction),
 		},
 	}
 	pas.AddSignal(signal1)
@@ -944,6 +1031,9 @@ func (pas *PairwiseArbStrategy) generateSpreadSignals(md *mdpb.MarketDataUpdate,
 			"z_score":     spreadStats.ZScore,
 			"spread":      spreadStats.CurrentSpread,
 			"hedge_ratio": spreadStats.HedgeRatio,
+
+			MetaFairSpread:     spreadStats.Mean + pas.tValue,
+			MetaEntryThreshold: pas.entryThreshold(direction),
 		},
 	}
 	pas.AddSignal(signal2)
@@ -1133,6 +1223,14 @@ func (pas *PairwiseArbStrategy) generateMultiLevelSignals(md *mdpb.MarketDataUpd
 	}
 }
 
+// entryThreshold 开仓方向对应的当前动态阈值（做多 entryZScoreBid，做空 entryZScoreAsk）
+func (pas *PairwiseArbStrategy) entryThreshold(direction string) float64 {
+	if direction == "long" {
+		return pas.entryZScoreBid
+	}
+	return pas.entryZScoreAsk
+}
+
 // generateLevelSignal 生成指定层级的挂单信号
 // C++: 对应每层独立的信号生成逻辑
 func (pas *PairwiseArbStrategy) generateLevelSignal(direction string, level int, price float64, qty int64, stats spread.SpreadStats) {
@@ -1171,6 +1269,9 @@ func (pas *PairwiseArbStrategy) generateLevelSignal(direction string, level int,
 			"z_score":     stats.ZScore,
 			"spread":      stats.CurrentSpread,
 			"hedge_ratio": stats.HedgeRatio,
+
+			MetaFairSpread:     stats.Mean + pas.tValue,
+			MetaEntryThreshold: pas.entryThreshold(direction),
 		},
 	}
 	pas.AddSignal(signal1)
@@ -1215,6 +1316,9 @@ func (pas *PairwiseArbStrategy) generateLevelSignal(direction string, level int,
 			"z_score":     stats.ZScore,
 			"spread":      stats.CurrentSpread,
 			"hedge_ratio": stats.HedgeRatio,
+
+			MetaFairSpread:     stats.Mean + pas.tValue,
+			MetaEntryThreshold: pas.entryThreshold(direction),
 		},
 	}
 	pas.AddSignal(signal2)
@@ -1312,20 +1416,29 @@ func (pas *PairwiseArbStrategy) optimizeOrderPrice(side OrderSide, level int, ba
 //   空头持仓 (netpos < 0):
 //     tholdBidPlace = BEGIN_PLACE + short_place_diff_thold * netpos / maxPos
 //     tholdAskPlace = BEGIN_PLACE - long_place_diff_thold * netpos / maxPos
+//
+// regimeSwitch 判定为高状态时（C++: SET_HIGH），BEGIN_PLACE/LONG_PLACE
+// 替换为 begin_zscore_high/long_zscore_high
 func (pas *PairwiseArbStrategy) setDynamicThresholds() {
+	beginZScore, longZScore := pas.placeThresholds()
+
 	if !pas.useDynamicThreshold || pas.maxPositionSize == 0 {
 		// 未启用动态阈值，使用静态 entryZScore 和 exitZScore
-		pas.entryZScoreBid = pas.entryZScore
-		pas.entryZScoreAsk = pas.entryZScore
+		entryZScore := pas.entryZScore
+		if pas.isHighRegime() && pas.beginZScoreHigh > 0 {
+			entryZScore = pas.beginZScoreHigh
+		}
+		pas.entryZScoreBid = entryZScore
+		pas.entryZScoreAsk = entryZScore
 		pas.exitZScoreBid = pas.exitZScore
 		pas.exitZScoreAsk = pas.exitZScore
 		return
 	}
 
 	// C++: long_place_diff_thold = LONG_PLACE - BEGIN_PLACE
-	longPlaceDiff := pas.longZScore - pas.beginZScore
+	longPlaceDiff := longZScore - beginZScore
 	// C++: short_place_diff_thold = BEGIN_PLACE - SHORT_PLACE
-	shortPlaceDiff := pas.beginZScore - pas.shortZScore
+	shortPlaceDiff := beginZScore - pas.shortZScore
 	// C++: long_remove_diff_thold = LONG_REMOVE - BEGIN_REMOVE
 	longRemoveDiff := pas.longExitZScore - pas.exitZScore
 	// C++: short_remove_diff_thold = BEGIN_REMOVE - SHORT_REMOVE
@@ -1343,16 +1456,16 @@ func (pas *PairwiseArbStrategy) setDynamicThresholds() {
 
 	if netPosPass == 0 {
 		// C++: 无持仓时使用初始阈值
-		pas.entryZScoreBid = pas.beginZScore
-		pas.entryZScoreAsk = pas.beginZScore
+		pas.entryZScoreBid = beginZScore
+		pas.entryZScoreAsk = beginZScore
 		pas.exitZScoreBid = pas.exitZScore
 		pas.exitZScoreAsk = pas.exitZScore
 	} else if netPosPass > 0 {
 		// C++: 多头持仓
 		// tholdBidPlace = BEGIN_PLACE + long_place_diff_thold * netpos / maxPos
-		pas.entryZScoreBid = pas.beginZScore + longPlaceDiff*posRatio
+		pas.entryZScoreBid = beginZScore + longPlaceDiff*posRatio
 		// tholdAskPlace = BEGIN_PLACE - short_place_diff_thold * netpos / maxPos
-		pas.entryZScoreAsk = pas.beginZScore - shortPlaceDiff*posRatio
+		pas.entryZScoreAsk = beginZScore - shortPlaceDiff*posRatio
 		// tholdBidRemove = BEGIN_REMOVE + long_remove_diff_thold * netpos / maxPos
 		pas.exitZScoreBid = pas.exitZScore + longRemoveDiff*posRatio
 		// tholdAskRemove = BEGIN_REMOVE - short_remove_diff_thold * netpos / maxPos
@@ -1360,9 +1473,9 @@ func (pas *PairwiseArbStrategy) setDynamicThresholds() {
 	} else {
 		// C++: 空头持仓 (netpos < 0)
 		// tholdBidPlace = BEGIN_PLACE + short_place_diff_thold * netpos / maxPos
-		pas.entryZScoreBid = pas.beginZScore + shortPlaceDiff*posRatio
+		pas.entryZScoreBid = beginZScore + shortPlaceDiff*posRatio
 		// tholdAskPlace = BEGIN_PLACE - long_place_diff_thold * netpos / maxPos
-		pas.entryZScoreAsk = pas.beginZScore - longPlaceDiff*posRatio
+		pas.entryZScoreAsk = beginZScore - longPlaceDiff*posRatio
 		// tholdBidRemove = BEGIN_REMOVE + short_remove_diff_thold * netpos / maxPos
 		pas.exitZScoreBid = pas.exitZScore + shortRemoveDiff*posRatio
 		// tholdAskRemove = BEGIN_REMOVE - long_remove_diff_thold * netpos / maxPos
@@ -1370,6 +1483,49 @@ func (pas *PairwiseArbStrategy) setDynamicThresholds() {
 	}
 }
 
+// placeThresholds 返回当前状态下的 BEGIN_PLACE / LONG_PLACE
+func (pas *PairwiseArbStrategy) placeThresholds() (float64, float64) {
+	begin, long := pas.beginZScore, pas.longZScore
+	if pas.isHighRegime() {
+		if pas.beginZScoreHigh > 0 {
+			begin = pas.beginZScoreHigh
+		}
+		if pas.longZScoreHigh > 0 {
+			long = pas.longZScoreHigh
+		}
+	}
+	return begin, long
+}
+
+// loadHedgeParams 读取动态对冲比率参数，有任一参数时返回 true
+//   hedge_method: "fixed" | "kalman"
+//   kalman_process_noise_alpha / kalman_process_noise_beta / kalman_observation_noise
+func (pas *PairwiseArbStrategy) loadHedgeParams(params map[string]interface{}) bool {
+	found := false
+	if val, ok := params["hedge_method"].(string); ok {
+		pas.hedgeMethod = val
+		found = true
+	}
+	if val, ok := params["kalman_process_noise_alpha"].(float64); ok && val >= 0 {
+		pas.kalmanQAlpha = val
+		found = true
+	}
+	if val, ok := params["kalman_process_noise_beta"].(float64); ok && val >= 0 {
+		pas.kalmanQBeta = val
+		found = true
+	}
+	if val, ok := params["kalman_observation_noise"].(float64); ok && val > 0 {
+		pas.kalmanR = val
+		found = true
+	}
+	return found
+}
+
+// isHighRegime 返回 regimeSwitch 是否判定为高波动状态
+func (pas *PairwiseArbStrategy) isHighRegime() bool {
+	return pas.regimeSwitch != nil && pas.regimeSwitch.IsHigh()
+}
+
 // getAvgSpreadRatio 获取调整后的价差均值
 // C++: avgSpreadRatio = avgSpreadRatio_ori + tValue
 // tValue 允许外部信号调整价差均值，使策略更容易入场或出场
@@ -2290,6 +2446,33 @@ func (pas *PairwiseArbStrategy) ApplyParameters(params map[string]interface{}) e
 		pas.useDynamicThreshold = val
 		updated = true
 	}
+	if val, ok := params["begin_zscore_high"].(float64); ok {
+		pas.beginZScoreHigh = val
+		updated = true
+	}
+	if val, ok := params["long_zscore_high"].(float64); ok {
+		pas.longZScoreHigh = val
+		updated = true
+	}
+	if val, ok := params["regime_switch_enabled"].(bool); ok {
+		if !val {
+			pas.regimeSwitch = nil
+		} else if pas.regimeSwitch == nil {
+			pas.regimeSwitch = NewRegimeSwitch(LoadRegimeSwitchConfig(params))
+		}
+		updated = true
+	}
+	// 动态对冲比率
+	if pas.loadHedgeParams(params) {
+		if pas.hedgeMethod == "kalman" && pas.spreadType == "difference" {
+			pas.spreadAnalyzer.EnableKalmanHedge(pas.kalmanQAlpha, pas.kalmanQBeta, pas.kalmanR)
+		} else {
+			pas.hedgeMethod = "fixed"
+			pas.spreadAnalyzer.DisableKalmanHedge()
+			pas.spreadAnalyzer.SetHedgeRatio(pas.hedgeRatio)
+		}
+		updated = true
+	}
 
 	// 主动追单参数
 	if val, ok := params["aggressive_enabled"].(bool); ok {
@@ -2342,6 +2525,15 @@ func (pas *PairwiseArbStrategy) ApplyParameters(params map[string]interface{}) e
 			pas.orderSize, pas.maxPositionSize)
 	}
 
+	// 同步阈值集中的 SIZE/MAX_SIZE，避免执行层仍按 model 文件的下单量（组合 resize 走这里）
+	for _, th := range []*ThresholdSet{pas.tholdFirst, pas.tholdSecond} {
+		if th != nil {
+			th.Size = int32(pas.orderSize)
+			th.MaxSize = int32(pas.maxPositionSize)
+			th.loadAuction(params)
+		}
+	}
+
 	// 输出变更日志
 	log.Printf("[PairwiseArbStrategy:%s] ✓ Parameters updated:", pas.ID)
 	if oldEntryZ != pas.entryZScore {
@@ -2379,6 +2571,10 @@ func (pas *PairwiseArbStrategy) GetCurrentParameters() map[string]interface{} {
 		"hedge_ratio":              pas.hedgeRatio,
 		"spread_type":              pas.spreadType,
 		"use_cointegration":        pas.useCointegration,
+		"hedge_method":             pas.hedgeMethod,
+		"kalman_process_noise_alpha": pas.kalmanQAlpha,
+		"kalman_process_noise_beta":  pas.kalmanQBeta,
+		"kalman_observation_noise":   pas.kalmanR,
 		// 动态阈值参数
 		"use_dynamic_threshold":    pas.useDynamicThreshold,
 		"begin_zscore":             pas.beginZScore,
@@ -2386,6 +2582,10 @@ func (pas *PairwiseArbStrategy) GetCurrentParameters() map[string]interface{} {
 		"short_zscore":             pas.shortZScore,
 		"entry_zscore_bid":         pas.entryZScoreBid, // 运行时值
 		"entry_zscore_ask":         pas.entryZScoreAsk, // 运行时值
+		"begin_zscore_high":        pas.beginZScoreHigh,
+		"long_zscore_high":         pas.longZScoreHigh,
+		"regime_switch_enabled":    pas.regimeSwitch != nil,
+		"regime_high":              pas.isHighRegime(),
 		// 主动追单参数
 		"aggressive_enabled":       pas.aggressiveEnabled,
 		"aggressive_interval_ms":   pas.aggressiveInterval.Milliseconds(),
@@ -2481,6 +2681,41 @@ func (pas *PairwiseArbStrategy) Stop() error {
 	// === 保存 daily_init 文件（C++: PairwiseArbStrategy::SaveMatrix2） ===
 	// C++: SaveMatrix2(std::string("../data/daily_init.") + std::to_string(m_strategyID));
 	// 在 HandleSquareoff() 末尾调用，保存当前状态供下次启动恢复
+	pas.saveDailyInit()
+
+	// 保存当前持仓到文件（包括昨/今仓区分）- JSON 格式（Go 特有）
+	snapshot := pas.positionSnapshot()
+	if err := SavePositionSnapshot(snapshot); err != nil {
+		log.Printf("[PairwiseArbStrategy:%s] Warning: Failed to save position snapshot: %v", pas.ID, err)
+		// 不阻断停止流程
+	} else {
+		leg1TodayNet := pas.leg1Position - pas.leg1YtdPosition
+		leg2TodayNet := pas.leg2Position - pas.leg2YtdPosition
+		log.Printf("[PairwiseArbStrategy:%s] Position snapshot saved: Long=%d, Short=%d, Net=%d [leg1: ytd=%d, 2day=%d] [leg2: ytd=%d, 2day=%d]",
+			pas.ID, snapshot.TotalLongQty, snapshot.TotalShortQty, snapshot.TotalNetQty,
+			pas.leg1YtdPosition, leg1TodayNet, pas.leg2YtdPosition, leg2TodayNet)
+	}
+
+	pas.ControlState.RunState = StrategyRunStateStopped
+	// 直接设置，避免死锁
+	pas.running = false
+	if pas.ControlState != nil {
+		pas.ControlState.Active = false
+	}
+
+	// 关闭共享内存（C++: 析构函数中调用 shmdt）
+	if pas.ExecutionStrategy != nil {
+		pas.ExecutionStrategy.CloseSharedMemory()
+	}
+
+	log.Printf("[%s] Strategy deactivated", pas.ID)
+	log.Printf("[PairwiseArbStrategy:%s] Stopped", pas.ID)
+	return nil
+}
+
+// saveDailyInit 写 daily_init.<strategyID>（调用者已持有锁）
+// C++: PairwiseArbStrategy::SaveMatrix2
+func (pas *PairwiseArbStrategy) saveDailyInit() {
 	dailyInitPath := GetDailyInitPath(pas.ExecutionStrategy.StrategyID)
 
 	// C++: avgSpreadRatio_ori 在停止时应保存运行时计算的均值
@@ -2494,11 +2729,11 @@ func (pas *PairwiseArbStrategy) Stop() error {
 	err := SaveMatrix2(
 		dailyInitPath,
 		pas.ExecutionStrategy.StrategyID,
-		avgPxToSave,                                      // avgSpreadRatio_ori（运行时均值）
-		pas.firstStrat.Instru.Symbol,                     // m_origbaseName1
-		pas.secondStrat.Instru.Symbol,                    // m_origbaseName2
-		pas.firstStrat.NetPosPass,                        // m_netpos_pass (ytd1)
-		pas.secondStrat.NetPosAgg,                        // m_netpos_agg (ytd2)
+		avgPxToSave,                   // avgSpreadRatio_ori（运行时均值）
+		pas.firstStrat.Instru.Symbol,  // m_origbaseName1
+		pas.secondStrat.Instru.Symbol, // m_origbaseName2
+		pas.firstStrat.NetPosPass,     // m_netpos_pass (ytd1)
+		pas.secondStrat.NetPosAgg,     // m_netpos_agg (ytd2)
 	)
 	if err != nil {
 		log.Printf("[PairwiseArbStrategy:%s] Warning: SaveMatrix2 failed: %v", pas.ID, err)
@@ -2509,9 +2744,11 @@ func (pas *PairwiseArbStrategy) Stop() error {
 			pas.firstStrat.Instru.Symbol, pas.secondStrat.Instru.Symbol,
 			pas.firstStrat.NetPosPass, pas.secondStrat.NetPosAgg)
 	}
+}
 
-	// 保存当前持仓到文件（包括昨/今仓区分）- JSON 格式（Go 特有）
-	snapshot := PositionSnapshot{
+// positionSnapshot 当前持仓快照（调用者已持有锁）
+func (pas *PairwiseArbStrategy) positionSnapshot() PositionSnapshot {
+	return PositionSnapshot{
 		StrategyID:    pas.ID,
 		Timestamp:     time.Now(),
 		TotalLongQty:  pas.estimatedPosition.LongQty,
@@ -2520,6 +2757,7 @@ func (pas *PairwiseArbStrategy) Stop() error {
 		AvgLongPrice:  pas.estimatedPosition.AvgLongPrice,
 		AvgShortPrice: pas.estimatedPosition.AvgShortPrice,
 		RealizedPnL:   pas.pnl.RealizedPnL,
+		UnrealizedPnL: pas.pnl.UnrealizedPnL,
 		SymbolsPos: map[string]int64{
 			pas.symbol1: pas.leg1Position,
 			pas.symbol2: pas.leg2Position,
@@ -2531,33 +2769,58 @@ func (pas *PairwiseArbStrategy) Stop() error {
 			pas.symbol2: pas.leg2Position,
 		},
 	}
+}
 
-	if err := SavePositionSnapshot(snapshot); err != nil {
-		log.Printf("[PairwiseArbStrategy:%s] Warning: Failed to save position snapshot: %v", pas.ID, err)
-		// 不阻断停止流程
-	} else {
-		leg1TodayNet := pas.leg1Position - pas.leg1YtdPosition
-		leg2TodayNet := pas.leg2Position - pas.leg2YtdPosition
-		log.Printf("[PairwiseArbStrategy:%s] Position snapshot saved: Long=%d, Short=%d, Net=%d [leg1: ytd=%d, 2day=%d] [leg2: ytd=%d, 2day=%d]",
-			pas.ID, snapshot.TotalLongQty, snapshot.TotalShortQty, snapshot.TotalNetQty,
-			pas.leg1YtdPosition, leg1TodayNet, pas.leg2YtdPosition, leg2TodayNet)
+// RolloverTradingDay 实现 TradingDayRoller：交易日切换（Go 扩展）
+// 相当于 C++ 收盘 SaveMatrix2 + 次日重启 LoadMatrix2，但不停止进程：
+//  1. 写日终快照 positions/eod/<id>.<tradingDay>.json（持仓、已实现/浮动盈亏）
+//  2. 今仓转昨仓：firstStrat/secondStrat NetPosPassYtd = NetPosPass
+//  3. 清空日内计数器和盈亏
+//  4. 重写 daily_init.<strategyID> 与 positions/<id>.json
+//
+// 日终快照已存在时视为已切换，返回 false（重复调用无副作用）
+func (pas *PairwiseArbStrategy) RolloverTradingDay(tradingDay string) (bool, error) {
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+
+	if pas.firstStrat == nil || pas.secondStrat == nil {
+		return false, fmt.Errorf("strategy %s not initialized", pas.ID)
+	}
+	if EODSnapshotExists(pas.ID, tradingDay) {
+		log.Printf("[PairwiseArbStrategy:%s] Trading day %s already rolled over, skip", pas.ID, tradingDay)
+		return false, nil
 	}
 
-	pas.ControlState.RunState = StrategyRunStateStopped
-	// 直接设置，避免死锁
-	pas.running = false
-	if pas.ControlState != nil {
-		pas.ControlState.Active = false
+	eod := pas.positionSnapshot()
+	eod.TradingDay = tradingDay
+	eod.SymbolsYesterdayPos = map[string]int64{
+		pas.symbol1: pas.leg1YtdPosition,
+		pas.symbol2: pas.leg2YtdPosition,
+	}
+	if err := SaveEODSnapshot(eod); err != nil {
+		return false, err
 	}
 
-	// 关闭共享内存（C++: 析构函数中调用 shmdt）
-	if pas.ExecutionStrategy != nil {
-		pas.ExecutionStrategy.CloseSharedMemory()
+	pas.firstStrat.RollTradingDay()
+	pas.secondStrat.RollTradingDay()
+	pas.leg1YtdPosition = pas.leg1Position
+	pas.leg2YtdPosition = pas.leg2Position
+	pas.pnl.RealizedPnL = 0
+	pas.pnl.TotalPnL = pas.pnl.UnrealizedPnL
+	pas.pnl.NetPnL = pas.pnl.UnrealizedPnL
+	pas.pnl.TradingFees = 0
+	pas.aggRepeat = 0
+	pas.aggFailCount = 0
+
+	pas.saveDailyInit()
+	if err := SavePositionSnapshot(pas.positionSnapshot()); err != nil {
+		log.Printf("[PairwiseArbStrategy:%s] Warning: Failed to save position snapshot: %v", pas.ID, err)
 	}
 
-	log.Printf("[%s] Strategy deactivated", pas.ID)
-	log.Printf("[PairwiseArbStrategy:%s] Stopped", pas.ID)
-	return nil
+	log.Printf("[PairwiseArbStrategy:%s] Trading day %s rolled over: realized=%.2f unrealized=%.2f, "+
+		"ytd leg1=%d leg2=%d", pas.ID, tradingDay, eod.RealizedPnL, eod.UnrealizedPnL,
+		pas.leg1YtdPosition, pas.leg2YtdPosition)
+	return true, nil
 }
 
 // InitializePositions 实现PositionInitializer接口：从外部初始化持仓
@@ -2730,6 +2993,10 @@ func (pas *PairwiseArbStrategy) GetSpreadStatus() map[string]interface{} {
 		"spread_std":     stats.Std,
 		"z_score":        stats.ZScore,
 		"hedge_ratio":    stats.HedgeRatio,
+		"hedge_method":   pas.hedgeMethod,
+		"spread_intercept":    stats.Intercept,
+		"spread_residual":     stats.Residual,
+		"spread_residual_var": stats.ResidualVar,
 		"leg1_position":  pas.leg1Position,
 		"leg2_position":  pas.leg2Position,
 	}
@@ -2983,9 +3250,17 @@ func (pas *PairwiseArbStrategy) Deactivate() {
 }
 
 // updateRiskMetrics updates risk metrics
+// 价差作为一个对象：ExposureValue 为两腿净货值（含合约乘数），GrossExposure 为两腿货值之和
 func (pas *PairwiseArbStrategy) updateRiskMetrics(currentPrice float64) {
 	pas.riskMetrics.PositionSize = abs(pas.estimatedPosition.NetQty)
-	pas.riskMetrics.ExposureValue = float64(pas.riskMetrics.PositionSize) * currentPrice
+	if pas.spreadDef != nil && pas.price1 > 0 && pas.price2 > 0 {
+		qty := [2]int64{pas.leg1Position, pas.leg2Position}
+		prices := [2]float64{pas.price1, pas.price2}
+		pas.riskMetrics.ExposureValue = absFloat(pas.spreadDef.NetExposure(qty[:], prices[:]))
+		pas.riskMetrics.GrossExposure = pas.spreadDef.GrossExposure(qty[:], prices[:])
+	} else {
+		pas.riskMetrics.ExposureValue = float64(pas.riskMetrics.PositionSize) * currentPrice
+	}
 	pas.riskMetrics.Timestamp = time.Now()
 
 	// Update max drawdown
@@ -3075,12 +3350,6 @@ func (pas *PairwiseArbStrategy) UpdateParameters(params map[string]interface{})
 	return pas.ApplyParameters(params)
 }
 
-// OnAuctionData handles auction data (集合竞价行情)
-func (pas *PairwiseArbStrategy) OnAuctionData(md *mdpb.MarketDataUpdate) {
-	// 配对套利策略在集合竞价期间不操作
-	log.Printf("[PairwiseArbStrategy:%s] Ignoring auction data for %s", pas.ID, md.Symbol)
-}
-
 // GetConfig returns the strategy configuration
 func (pas *PairwiseArbStrategy) GetConfig() *StrategyConfig {
 	pas.mu.RLock()
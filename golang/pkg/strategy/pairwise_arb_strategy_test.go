@@ -6,6 +6,8 @@ import (
 	"time"
 
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy/spread"
 )
 
 func TestPairwiseArbStrategy_Creation(t *testing.T) {
@@ -155,6 +157,149 @@ func TestPairwiseArbStrategy_SpreadCalculation_Difference(t *testing.T) {
 	}
 }
 
+func TestPairwiseArbStrategy_KalmanHedge(t *testing.T) {
+	pas := NewPairwiseArbStrategy("pairwise_1")
+
+	config := &StrategyConfig{
+		StrategyID:   "pairwise_1",
+		StrategyType: "pairwise_arb",
+		Symbols:      []string{"ag2603", "ag2605"},
+		Parameters: map[string]interface{}{
+			"hedge_method":               "kalman",
+			"kalman_process_noise_beta":  1e-8,
+			"kalman_observation_noise":   2.0,
+		},
+		Enabled: true,
+	}
+	if err := pas.Initialize(config); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	if !pas.spreadAnalyzer.IsKalmanHedge() {
+		t.Fatal("Expected kalman hedge mode")
+	}
+
+	// 对冲比率 1.01：spread = price1 - beta*price2 应收敛到截距附近
+	for i := 0; i < 2000; i++ {
+		price2 := 5000 + 100*math.Sin(float64(i)/40)
+		pas.spreadAnalyzer.UpdatePricesNow(20+1.01*price2+math.Sin(float64(i)), price2)
+	}
+	status := pas.GetSpreadStatus()
+	if hr := status["hedge_ratio"].(float64); math.Abs(hr-1.01) > 0.005 {
+		t.Errorf("Expected hedge_ratio ~1.01, got %f", hr)
+	}
+	if status["hedge_method"] != "kalman" || status["spread_residual_var"].(float64) <= 0 {
+		t.Errorf("Unexpected spread status: %v", status)
+	}
+
+	// 切回固定对冲比率
+	if err := pas.ApplyParameters(map[string]interface{}{"hedge_method": "fixed"}); err != nil {
+		t.Fatalf("ApplyParameters failed: %v", err)
+	}
+	if pas.spreadAnalyzer.IsKalmanHedge() || pas.spreadAnalyzer.GetHedgeRatio() != 1.0 {
+		t.Errorf("Expected fixed hedge ratio 1.0, got %f", pas.spreadAnalyzer.GetHedgeRatio())
+	}
+	if pas.GetCurrentParameters()["kalman_observation_noise"] != 2.0 {
+		t.Errorf("Expected kalman_observation_noise=2.0")
+	}
+}
+
+func TestPairwiseArbStrategy_KalmanHedge_RequiresDifference(t *testing.T) {
+	pas := NewPairwiseArbStrategy("pairwise_1")
+	config := &StrategyConfig{
+		StrategyID:   "pairwise_1",
+		StrategyType: "pairwise_arb",
+		Symbols:      []string{"ag2603", "ag2605"},
+		Parameters: map[string]interface{}{
+			"spread_type":  "ratio",
+			"hedge_method": "kalman",
+		},
+	}
+	if err := pas.Initialize(config); err == nil {
+		t.Error("Expected error for kalman hedge with ratio spread")
+	}
+}
+
+func TestPairwiseArbStrategy_SpreadExposure(t *testing.T) {
+	pas := NewPairwiseArbStrategy("pairwise_1")
+	config := &StrategyConfig{
+		StrategyID:   "pairwise_1",
+		StrategyType: "pairwise_arb",
+		Symbols:      []string{"ag2603", "ag2605"},
+		Parameters:   map[string]interface{}{},
+	}
+	if err := pas.Initialize(config); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	// 多 3 手 ag2603 / 空 3 手 ag2605：作为一个价差对象，净敞口只剩价差部分
+	pas.leg1Position = 3
+	pas.leg2Position = -3
+	pas.price1 = 5010
+	pas.price2 = 5000
+	pas.updateRiskMetrics((pas.price1 + pas.price2) / 2)
+
+	assertFloat(t, "ExposureValue", pas.riskMetrics.ExposureValue, 3*10*15)
+	assertFloat(t, "GrossExposure", pas.riskMetrics.GrossExposure, 3*(5010+5000)*15)
+}
+
+func TestPairwiseArbStrategy_RolloverTradingDay(t *testing.T) {
+	oldDir := GetDataDir()
+	SetDataDir(t.TempDir())
+	defer SetDataDir(oldDir)
+
+	pas := NewPairwiseArbStrategy("pairwise_1")
+	config := &StrategyConfig{
+		StrategyID:   "pairwise_1",
+		StrategyType: "pairwise_arb",
+		Symbols:      []string{"ag2603", "ag2605"},
+		Parameters:   map[string]interface{}{},
+	}
+	if err := pas.Initialize(config); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	// 今日: leg1 被动买入 3 手 @5000, leg2 主动卖出 3 手
+	pas.firstStrat.OrdMap[1] = &OrderStats{OrderID: 1, OrdType: OrderHitTypeStandard, OpenQty: 3}
+	pas.firstStrat.ProcessTrade(1, 3, 5000, TransactionTypeBuy)
+	pas.secondStrat.OrdMap[2] = &OrderStats{OrderID: 2, OrdType: OrderHitTypeCross, OpenQty: 3}
+	pas.secondStrat.ProcessTrade(2, 3, 4990, TransactionTypeSell)
+	pas.leg1Position, pas.leg2Position = 3, -3
+
+	rolled, err := pas.RolloverTradingDay("20260930")
+	if err != nil || !rolled {
+		t.Fatalf("RolloverTradingDay = %v, %v, want true, nil", rolled, err)
+	}
+	if pas.firstStrat.NetPosPassYtd != 3 || pas.firstStrat.NetPos != 3 {
+		t.Errorf("leg1 ytd = %d, netpos = %d, want 3, 3", pas.firstStrat.NetPosPassYtd, pas.firstStrat.NetPos)
+	}
+	if pas.firstStrat.TradeCount != 0 || pas.firstStrat.RealisedPNL != 0 {
+		t.Errorf("leg1 trade count = %d, realised = %f, want 0, 0", pas.firstStrat.TradeCount, pas.firstStrat.RealisedPNL)
+	}
+	// 未平持仓按成本结转
+	assertFloat(t, "BuyTotalValue", pas.firstStrat.BuyTotalValue, 15000)
+	if pas.leg1YtdPosition != 3 || pas.leg2YtdPosition != -3 {
+		t.Errorf("ytd positions = %d, %d, want 3, -3", pas.leg1YtdPosition, pas.leg2YtdPosition)
+	}
+
+	mx, err := LoadMatrix2(GetDailyInitPath(pas.ExecutionStrategy.StrategyID))
+	if err != nil {
+		t.Fatalf("LoadMatrix2: %v", err)
+	}
+	if row := mx[pas.ExecutionStrategy.StrategyID]; row == nil || row.Ytd1 != 3 || row.Ytd2 != -3 {
+		t.Errorf("daily_init row = %+v, want ytd1=3 ytd2=-3", row)
+	}
+
+	// 重复调用不再结转
+	pas.firstStrat.TradeCount = 1
+	rolled, err = pas.RolloverTradingDay("20260930")
+	if err != nil || rolled {
+		t.Errorf("second RolloverTradingDay = %v, %v, want false, nil", rolled, err)
+	}
+	if pas.firstStrat.TradeCount != 1 {
+		t.Errorf("TradeCount = %d, want 1 (no second reset)", pas.firstStrat.TradeCount)
+	}
+}
+
 func TestPairwiseArbStrategy_SpreadCalculation_Ratio(t *testing.T) {
 	pas := NewPairwiseArbStrategy("pairwise_1")
 
@@ -428,6 +573,25 @@ func TestPairwiseArbStrategy_EntrySignal_HighSpread(t *testing.T) {
 	pas.Stop()
 }
 
+// 开仓信号把公允价差（均值 + tValue）和当前动态阈值带到订单请求，供回测归因读取
+func TestPairwiseArbStrategy_EntrySignalMetadata(t *testing.T) {
+	pas := NewPairwiseArbStrategy("pairwise_1")
+	pas.symbol1, pas.symbol2 = "ag2506", "ag2512"
+	pas.bid2, pas.ask2 = 5800, 5801
+	pas.entryZScoreBid, pas.entryZScoreAsk = 1.25, 2
+	pas.tValue = 0.5
+
+	pas.generateLevelSignal("long", 0, 5790, 1, spread.SpreadStats{CurrentSpread: -11, Mean: -8, Std: 1, ZScore: -3})
+	signals := pas.GetSignals()
+	if len(signals) != 2 {
+		t.Fatalf("signals = %d, want 2", len(signals))
+	}
+	req := signals[0].ToOrderRequest()
+	if req.Metadata[MetaFairSpread] != "-7.5" || req.Metadata[MetaEntryThreshold] != "1.25" {
+		t.Errorf("order metadata = %v, want fair_spread=-7.5 entry_threshold=1.25", req.Metadata)
+	}
+}
+
 func TestPairwiseArbStrategy_ExitSignal(t *testing.T) {
 	pas := NewPairwiseArbStrategy("pairwise_1")
 
@@ -1071,3 +1235,73 @@ func BenchmarkPairwiseArbStrategy_OnMarketData(b *testing.B) {
 		pas.OnMarketData(md)
 	}
 }
+
+func TestPairwiseArbStrategy_AdoptOrder(t *testing.T) {
+	pas := NewPairwiseArbStrategy("pairwise_1")
+	if err := pas.Initialize(&StrategyConfig{
+		StrategyID:   "pairwise_1",
+		StrategyType: "pairwise_arb",
+		Symbols:      []string{"SYMBOL1", "SYMBOL2"},
+		Exchanges:    []string{"TEST"},
+		Parameters:   map[string]interface{}{},
+		Enabled:      true,
+	}); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	live := func(id, symbol string, side orspb.OrderSide, price float64) *orspb.OrderUpdate {
+		return &orspb.OrderUpdate{OrderId: id, StrategyId: "pairwise_1", Symbol: symbol, Side: side,
+			Status: orspb.OrderStatus_ACCEPTED, Price: price, Quantity: 2}
+	}
+
+	if err := pas.AdoptOrder(live("A1", "SYMBOL1", orspb.OrderSide_BUY, 100)); err != nil {
+		t.Fatalf("AdoptOrder: %v", err)
+	}
+	if !pas.leg1OrderMap.HasOrderAtPrice(100, OrderSideBuy) {
+		t.Error("adopted order should be in leg1 order map")
+	}
+	if err := pas.AdoptOrder(live("A1", "SYMBOL1", orspb.OrderSide_BUY, 100)); err != nil {
+		t.Errorf("re-adopting the same order should be a no-op, got %v", err)
+	}
+	if err := pas.AdoptOrder(live("A2", "SYMBOL1", orspb.OrderSide_BUY, 100)); err == nil {
+		t.Error("expected error for occupied price level")
+	}
+	if err := pas.AdoptOrder(live("A3", "SYMBOL3", orspb.OrderSide_SELL, 100)); err == nil {
+		t.Error("expected error for unknown symbol")
+	}
+
+	agg := live("A4", "SYMBOL2", orspb.OrderSide_SELL, 99)
+	agg.Metadata = map[string]string{"order_category": "aggressive"}
+	if err := pas.AdoptOrder(agg); err != nil {
+		t.Fatalf("AdoptOrder leg2: %v", err)
+	}
+	if pas.secondStrat.SellAggOrder != 1 {
+		t.Errorf("SellAggOrder = %v, want 1", pas.secondStrat.SellAggOrder)
+	}
+}
+
+func TestPairwiseArbStrategy_ApplyParameters_SyncsThresholdSize(t *testing.T) {
+	pas := NewPairwiseArbStrategy("pairwise_1")
+	config := &StrategyConfig{
+		StrategyID:   "pairwise_1",
+		StrategyType: "pairwise_arb",
+		Symbols:      []string{"ag2603", "ag2605"},
+		Parameters: map[string]interface{}{
+			"order_size":        4.0,
+			"max_position_size": 20.0,
+		},
+	}
+	if err := pas.Initialize(config); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	// 组合 resize 通过 UpdateParameters 下发，阈值集的 SIZE/MAX_SIZE 需同步
+	if err := pas.UpdateParameters(map[string]interface{}{"order_size": 2.0, "max_position_size": 7.0}); err != nil {
+		t.Fatalf("UpdateParameters failed: %v", err)
+	}
+	for _, th := range []*ThresholdSet{pas.tholdFirst, pas.tholdSecond} {
+		if th.Size != 2 || th.MaxSize != 7 {
+			t.Errorf("threshold Size/MaxSize = %d/%d, want 2/7", th.Size, th.MaxSize)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package strategy
+
+import (
+	"log"
+	"math"
+	"time"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+
+	"tbsrc-golang/pkg/auction"
+)
+
+// OnAuctionData 处理集合竞价行情（Go 扩展）
+// 竞价期间的指示性盘口不进入价差统计；AUCTION_ENABLE 时按两腿指示性均衡价计算价差 z-score，
+// 偏离达到 AUCTION_PLACE 时两腿同时报竞价单。开盘按统一价撮合，两腿没有先后成交的瘸腿风险，
+// 未成交部分在连续交易开始后按正常挂单处理。每次竞价只报一次
+func (pas *PairwiseArbStrategy) OnAuctionData(md *mdpb.MarketDataUpdate) {
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+
+	if !pas.running {
+		return
+	}
+
+	eq := IndicativeAuction(md)
+	switch md.Symbol {
+	case pas.symbol1:
+		pas.auction1 = eq
+	case pas.symbol2:
+		pas.auction2 = eq
+	default:
+		return
+	}
+	if !pas.inAuction {
+		pas.inAuction = true
+		log.Printf("[PairwiseArb:%s] Auction phase: %s", pas.ID, md.Symbol)
+	}
+
+	th := pas.tholdFirst
+	if !th.AuctionEnable || pas.auctionSent {
+		return
+	}
+	eq1, eq2 := pas.auction1, pas.auction2
+	if !eq1.Valid() || !eq2.Valid() || min(eq1.Matched, eq2.Matched) < th.AuctionMinVolume {
+		return
+	}
+	stats := pas.spreadAnalyzer.GetStats()
+	if stats.Std < 1e-10 || !pas.spreadAnalyzer.IsReady(pas.lookbackPeriod) {
+		return
+	}
+
+	// 与 generateSignals 相同的均值口径（EMA 均值优先）
+	mean := stats.Mean + pas.tValue
+	if th.Alpha > 0 && pas.avgSpreadRatio_ori != 0 {
+		mean = pas.avgSpreadRatio_ori + pas.tValue
+	}
+	indicative := pas.spreadAnalyzer.SpreadOf(eq1.Price, eq2.Price)
+	zscore := (indicative - mean) / stats.Std
+
+	size := int64(th.AuctionSize)
+	if size <= 0 {
+		size = pas.orderSize
+	}
+	maxSize := int64(th.AuctionMaxSize)
+	if maxSize <= 0 {
+		maxSize = pas.maxPositionSize
+	}
+
+	var direction string
+	var side1, side2 OrderSide
+	var qty int64
+	switch {
+	case zscore >= th.AuctionPlace:
+		direction, side1, side2 = "short", OrderSideSell, OrderSideBuy
+		qty = min(size, maxSize+pas.leg1Position)
+	case -zscore >= th.AuctionPlace:
+		direction, side1, side2 = "long", OrderSideBuy, OrderSideSell
+		qty = min(size, maxSize-pas.leg1Position)
+	default:
+		return
+	}
+	if qty <= 0 {
+		return
+	}
+
+	pas.AddSignal(pas.auctionSignal(pas.symbol1, side1, eq1, pas.tickSize1, qty, 1, direction, zscore, indicative))
+	pas.AddSignal(pas.auctionSignal(pas.symbol2, side2, eq2, pas.tickSize2, qty, 2, direction, zscore, indicative))
+	pas.auctionSent = true
+
+	log.Printf("[PairwiseArb:%s] Auction %s spread: z=%.2f indicative=%.4f mean=%.4f, leg1 %.2f/%d leg2 %.2f/%d, qty=%d",
+		pas.ID, direction, zscore, indicative, mean, eq1.Price, eq1.Matched, eq2.Price, eq2.Matched, qty)
+}
+
+// auctionSignal 竞价单：以均衡价向对手方让出 AUCTION_SLOP 个 tick（成交价仍为统一开盘价）
+func (pas *PairwiseArbStrategy) auctionSignal(symbol string, side OrderSide, eq auction.Result, tickSize float64,
+	qty int64, leg int, direction string, zscore, indicative float64) *TradingSignal {
+
+	slop := float64(pas.tholdFirst.AuctionSlop) * tickSize
+	price := eq.Price + slop
+	if side == OrderSideSell {
+		price = eq.Price - slop
+	}
+	return &TradingSignal{
+		StrategyID: pas.ID,
+		Symbol:     symbol,
+		Side:       side,
+		Price:      price,
+		Quantity:   qty,
+		OrderType:  OrderTypeLimit,
+		Signal:     -zscore,
+		Confidence: math.Min(1.0, math.Abs(zscore)/5.0),
+		Timestamp:  time.Now(),
+		Category:   SignalCategoryPassive,
+		Metadata: map[string]interface{}{
+			"type":              "auction",
+			"leg":               leg,
+			"direction":         direction,
+			"z_score":           zscore,
+			"spread":            indicative,
+			"auction_price":     eq.Price,
+			"auction_matched":   eq.Matched,
+			"auction_imbalance": eq.Imbalance,
+		},
+	}
+}
+
+// endAuction 收到连续交易行情时清理竞价状态（调用方已持有 pas.mu）
+func (pas *PairwiseArbStrategy) endAuction() {
+	if !pas.inAuction {
+		return
+	}
+	log.Printf("[PairwiseArb:%s] Auction ended: leg1 %.2f/%d leg2 %.2f/%d sent=%v",
+		pas.ID, pas.auction1.Price, pas.auction1.Matched, pas.auction2.Price, pas.auction2.Matched, pas.auctionSent)
+	pas.inAuction = false
+	pas.auctionSent = false
+	pas.auction1, pas.auction2 = auction.Result{}, auction.Result{}
+}
@@ -24,6 +24,10 @@ type PositionSnapshot struct {
 	// C++: m_netpos_pass_ytd - 昨日收盘时的净持仓
 	// 今仓净值 = SymbolsPos[symbol] - SymbolsYesterdayPos[symbol]
 	SymbolsYesterdayPos map[string]int64 `json:"symbols_yesterday_position,omitempty"` // symbol -> ytd_position
+
+	// 日终快照（交易日切换时写入 positions/eod/），Go 扩展
+	TradingDay    string  `json:"trading_day,omitempty"` // YYYYMMDD
+	UnrealizedPnL float64 `json:"unrealized_pnl,omitempty"`
 }
 
 // PositionWithCost 持仓信息（含成本价）
@@ -99,6 +103,37 @@ func LoadPositionSnapshot(strategyID string) (*PositionSnapshot, error) {
 	return &snapshot, nil
 }
 
+// getEODSnapshotPath 日终快照路径: <dataDir>/positions/eod/<strategyID>.<YYYYMMDD>.json
+func getEODSnapshotPath(strategyID, tradingDay string) string {
+	return filepath.Join(getPositionDataDir(), "eod", fmt.Sprintf("%s.%s.json", strategyID, tradingDay))
+}
+
+// SaveEODSnapshot 保存交易日日终持仓/盈亏快照（snapshot.TradingDay 必填）
+func SaveEODSnapshot(snapshot PositionSnapshot) error {
+	if snapshot.TradingDay == "" {
+		return fmt.Errorf("EOD snapshot for %s: trading day is empty", snapshot.StrategyID)
+	}
+	path := getEODSnapshotPath(snapshot.StrategyID, snapshot.TradingDay)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create EOD snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal EOD snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write EOD snapshot: %w", err)
+	}
+	return nil
+}
+
+// EODSnapshotExists 该交易日是否已完成日终切换（用于防止重复切换）
+func EODSnapshotExists(strategyID, tradingDay string) bool {
+	_, err := os.Stat(getEODSnapshotPath(strategyID, tradingDay))
+	return err == nil
+}
+
 // DeletePositionSnapshot 删除持仓快照文件
 func DeletePositionSnapshot(strategyID string) error {
 	filename := filepath.Join(getPositionDataDir(), fmt.Sprintf("%s.json", strategyID))
@@ -2,6 +2,8 @@ package strategy
 
 import (
 	"math"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy/spread"
 )
 
 // InstrumentSpec 品种规格
@@ -9,27 +11,28 @@ type InstrumentSpec struct {
 	TickSize           float64 // 最小变动价位
 	ContractMultiplier int64   // 合约乘数
 	Exchange           string  // 交易所
+	MarginRate         float64 // 保证金率（交易所标准，0 表示未知，按全额计算）
 }
 
 // DefaultInstrumentSpecs 默认品种规格
 var DefaultInstrumentSpecs = map[string]InstrumentSpec{
 	// 白银
-	"ag2603": {TickSize: 1.0, ContractMultiplier: 15, Exchange: "SHFE"},
-	"ag2605": {TickSize: 1.0, ContractMultiplier: 15, Exchange: "SHFE"},
-	"ag2612": {TickSize: 1.0, ContractMultiplier: 15, Exchange: "SHFE"},
+	"ag2603": {TickSize: 1.0, ContractMultiplier: 15, Exchange: "SHFE", MarginRate: 0.12},
+	"ag2605": {TickSize: 1.0, ContractMultiplier: 15, Exchange: "SHFE", MarginRate: 0.12},
+	"ag2612": {TickSize: 1.0, ContractMultiplier: 15, Exchange: "SHFE", MarginRate: 0.12},
 
 	// 黄金
-	"au2604": {TickSize: 0.02, ContractMultiplier: 1000, Exchange: "SHFE"},
-	"au2606": {TickSize: 0.02, ContractMultiplier: 1000, Exchange: "SHFE"},
-	"au2612": {TickSize: 0.02, ContractMultiplier: 1000, Exchange: "SHFE"},
+	"au2604": {TickSize: 0.02, ContractMultiplier: 1000, Exchange: "SHFE", MarginRate: 0.10},
+	"au2606": {TickSize: 0.02, ContractMultiplier: 1000, Exchange: "SHFE", MarginRate: 0.10},
+	"au2612": {TickSize: 0.02, ContractMultiplier: 1000, Exchange: "SHFE", MarginRate: 0.10},
 
 	// 螺纹钢
-	"rb2605": {TickSize: 1.0, ContractMultiplier: 10, Exchange: "SHFE"},
-	"rb2610": {TickSize: 1.0, ContractMultiplier: 10, Exchange: "SHFE"},
+	"rb2605": {TickSize: 1.0, ContractMultiplier: 10, Exchange: "SHFE", MarginRate: 0.10},
+	"rb2610": {TickSize: 1.0, ContractMultiplier: 10, Exchange: "SHFE", MarginRate: 0.10},
 
 	// 铜
-	"cu2604": {TickSize: 10.0, ContractMultiplier: 5, Exchange: "SHFE"},
-	"cu2606": {TickSize: 10.0, ContractMultiplier: 5, Exchange: "SHFE"},
+	"cu2604": {TickSize: 10.0, ContractMultiplier: 5, Exchange: "SHFE", MarginRate: 0.10},
+	"cu2606": {TickSize: 10.0, ContractMultiplier: 5, Exchange: "SHFE", MarginRate: 0.10},
 }
 
 // RoundToTickSize 将价格四舍五入到tick size的倍数
@@ -148,8 +151,30 @@ func GetContractMultiplier(symbol string) float64 {
 	return 1.0
 }
 
+// FillLegMultipliers 用品种规格表补齐价差各腿的合约乘数（已设置的保持不变）
+func FillLegMultipliers(legs []spread.Leg) {
+	for i := range legs {
+		if legs[i].Multiplier <= 0 {
+			legs[i].Multiplier = GetContractMultiplier(legs[i].Symbol)
+		}
+	}
+}
+
 // GetInstrumentSpec 获取品种规格
 func GetInstrumentSpec(symbol string) (InstrumentSpec, bool) {
 	spec, ok := DefaultInstrumentSpecs[symbol]
 	return spec, ok
 }
+
+// GetMarginRate 获取品种的保证金率，未配置时返回 1（按合约全额价值计算）
+func GetMarginRate(symbol string) float64 {
+	if spec, ok := DefaultInstrumentSpecs[symbol]; ok && spec.MarginRate > 0 {
+		return spec.MarginRate
+	}
+	return 1.0
+}
+
+// MarginPerLot 每手占用保证金 = 价格 × 合约乘数 × 保证金率（Go 扩展）
+func MarginPerLot(symbol string, price float64) float64 {
+	return price * GetContractMultiplier(symbol) * GetMarginRate(symbol)
+}
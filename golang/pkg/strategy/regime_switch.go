@@ -0,0 +1,158 @@
+package strategy
+
+import (
+	"github.com/yourusername/quantlink-trade-system/pkg/indicators"
+)
+
+// RegimeSwitch 根据价差的市场状态决定是否切换到高波动阈值组
+// 对应 C++ ExecutionStrategy 的 SET_HIGH（BEGIN_PLACE_HIGH / LONG_PLACE_HIGH）
+//
+// 三个独立的判据，任一触发即进入高状态：
+//   - VolatilityRegime: HMM 后验 P(high) >= HighProb
+//   - VarianceRatio:    Hurst 指数 > MaxHurst（价差不再均值回归）
+//   - CUSUM:            最近 HoldBars 次观测内检测到均值突变
+type RegimeSwitch struct {
+	Vol   *indicators.VolatilityRegime
+	VR    *indicators.VarianceRatio
+	Cusum *indicators.CUSUM
+
+	HighProb float64 // P(high) 阈值 (default 0.7)
+	MaxHurst float64 // Hurst 上限，0 表示不使用 (default 0.55)
+	HoldBars int     // CUSUM 突变后保持高状态的观测数，0 表示不使用 (default 50)
+
+	high bool
+}
+
+// RegimeSwitchConfig RegimeSwitch 参数
+type RegimeSwitchConfig struct {
+	VolStates      int
+	VolPeriod      int
+	VolHighMult    float64
+	HighProb       float64
+	HurstPeriod    int
+	HurstLag       int
+	MaxHurst       float64
+	CusumThreshold float64
+	CusumDrift     float64
+	CusumPeriod    int
+	HoldBars       int
+}
+
+// DefaultRegimeSwitchConfig 返回默认参数
+func DefaultRegimeSwitchConfig() RegimeSwitchConfig {
+	return RegimeSwitchConfig{
+		VolStates:      2,
+		VolPeriod:      100,
+		VolHighMult:    4.0,
+		HighProb:       0.7,
+		HurstPeriod:    200,
+		HurstLag:       5,
+		MaxHurst:       0.55,
+		CusumThreshold: 5.0,
+		CusumDrift:     0.5,
+		CusumPeriod:    100,
+		HoldBars:       50,
+	}
+}
+
+// LoadRegimeSwitchConfig 从策略参数读取 regime_* 配置
+func LoadRegimeSwitchConfig(params map[string]interface{}) RegimeSwitchConfig {
+	cfg := DefaultRegimeSwitchConfig()
+	if val, ok := params["regime_vol_states"].(float64); ok {
+		cfg.VolStates = int(val)
+	}
+	if val, ok := params["regime_vol_period"].(float64); ok {
+		cfg.VolPeriod = int(val)
+	}
+	if val, ok := params["regime_vol_high_mult"].(float64); ok {
+		cfg.VolHighMult = val
+	}
+	if val, ok := params["regime_high_prob"].(float64); ok {
+		cfg.HighProb = val
+	}
+	if val, ok := params["regime_hurst_period"].(float64); ok {
+		cfg.HurstPeriod = int(val)
+	}
+	if val, ok := params["regime_hurst_lag"].(float64); ok {
+		cfg.HurstLag = int(val)
+	}
+	if val, ok := params["regime_max_hurst"].(float64); ok {
+		cfg.MaxHurst = val
+	}
+	if val, ok := params["regime_cusum_threshold"].(float64); ok {
+		cfg.CusumThreshold = val
+	}
+	if val, ok := params["regime_cusum_drift"].(float64); ok {
+		cfg.CusumDrift = val
+	}
+	if val, ok := params["regime_cusum_period"].(float64); ok {
+		cfg.CusumPeriod = int(val)
+	}
+	if val, ok := params["regime_hold_bars"].(float64); ok {
+		cfg.HoldBars = int(val)
+	}
+	return cfg
+}
+
+// NewRegimeSwitch 创建 RegimeSwitch
+func NewRegimeSwitch(cfg RegimeSwitchConfig) *RegimeSwitch {
+	return &RegimeSwitch{
+		Vol:      indicators.NewVolatilityRegime("regime_vol", cfg.VolStates, cfg.VolPeriod, 0.98, 0.4, cfg.VolHighMult, 1),
+		VR:       indicators.NewVarianceRatio("regime_vr", cfg.HurstPeriod, cfg.HurstLag, 1),
+		Cusum:    indicators.NewCUSUM("regime_cusum", cfg.CusumThreshold, cfg.CusumDrift, cfg.CusumPeriod, 1),
+		HighProb: cfg.HighProb,
+		MaxHurst: cfg.MaxHurst,
+		HoldBars: cfg.HoldBars,
+	}
+}
+
+// Update 输入一次价差观测并重新判定状态
+func (rs *RegimeSwitch) Update(spread float64) {
+	rs.Vol.UpdateWithValue(spread)
+	rs.VR.UpdateWithValue(spread)
+	rs.Cusum.UpdateWithValue(spread)
+
+	rs.high = rs.VolHigh() || rs.Trending() || rs.ChangePoint()
+}
+
+// IsHigh 返回是否应使用高波动阈值组
+func (rs *RegimeSwitch) IsHigh() bool {
+	return rs.high
+}
+
+// VolHigh HMM 判定为高波动
+func (rs *RegimeSwitch) VolHigh() bool {
+	return rs.Vol.IsHighVolatility(rs.HighProb)
+}
+
+// Trending 方差比判定价差趋势化（不再均值回归）
+func (rs *RegimeSwitch) Trending() bool {
+	return rs.MaxHurst > 0 && rs.VR.IsReady() && rs.VR.GetHurst() > rs.MaxHurst
+}
+
+// ChangePoint CUSUM 在 HoldBars 内检测到突变
+func (rs *RegimeSwitch) ChangePoint() bool {
+	return rs.HoldBars > 0 && rs.Cusum.ChangedWithin(rs.HoldBars)
+}
+
+// FillIndicators 将状态写入 UI 指标 map
+func (rs *RegimeSwitch) FillIndicators(dst map[string]float64) {
+	high := 0.0
+	if rs.high {
+		high = 1
+	}
+	dst["regime_high"] = high
+	dst["regime_vol_state"] = float64(rs.Vol.GetState())
+	dst["regime_vol_high_prob"] = rs.Vol.GetValue()
+	dst["regime_variance_ratio"] = rs.VR.GetValue()
+	dst["regime_hurst"] = rs.VR.GetHurst()
+	dst["regime_cusum"] = rs.Cusum.GetValue()
+}
+
+// Reset 重置所有判据
+func (rs *RegimeSwitch) Reset() {
+	rs.Vol.Reset()
+	rs.VR.Reset()
+	rs.Cusum.Reset()
+	rs.high = false
+}
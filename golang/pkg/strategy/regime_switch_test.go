@@ -0,0 +1,172 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+)
+
+// regimeNoise 确定性伪随机噪声（xorshift32，近似正态）
+func regimeNoise(n int, seed uint32) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		s := 0.0
+		for j := 0; j < 12; j++ {
+			seed ^= seed << 13
+			seed ^= seed >> 17
+			seed ^= seed << 5
+			s += float64(seed) / float64(math.MaxUint32)
+		}
+		out[i] = s - 6
+	}
+	return out
+}
+
+func newRegimeTestPAS(t *testing.T) *PairwiseArbStrategy {
+	pas := NewPairwiseArbStrategy("regime_test")
+	config := &StrategyConfig{
+		StrategyID:   "regime_test",
+		StrategyType: "pairwise_arb",
+		Symbols:      []string{"ag2603", "ag2605"},
+		Parameters: map[string]interface{}{
+			"entry_zscore":          2.0,
+			"exit_zscore":           0.5,
+			"max_position_size":     100.0,
+			"use_dynamic_threshold": true,
+			"begin_zscore":          2.0,
+			"long_zscore":           3.5,
+			"short_zscore":          0.5,
+			"begin_zscore_high":     3.0,
+			"long_zscore_high":      5.0,
+			"regime_switch_enabled": true,
+			"regime_vol_period":     50.0,
+			"regime_hurst_period":   100.0,
+			"regime_cusum_period":   50.0,
+			"regime_max_hurst":      0.0, // 只测试波动率判据
+			"regime_hold_bars":      0.0,
+		},
+	}
+	if err := pas.Initialize(config); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if pas.regimeSwitch == nil {
+		t.Fatal("Expected regimeSwitch to be created")
+	}
+	return pas
+}
+
+func TestRegimeSwitch_SetDynamicThresholds(t *testing.T) {
+	pas := newRegimeTestPAS(t)
+	noise := regimeNoise(300, 21)
+
+	// 平稳期
+	spread := 10.0
+	for i := 0; i < 200; i++ {
+		spread = 10 + 0.5*noise[i]
+		pas.regimeSwitch.Update(spread)
+	}
+	if pas.regimeSwitch.IsHigh() {
+		t.Fatalf("Expected normal regime, P(high)=%.3f", pas.regimeSwitch.Vol.GetValue())
+	}
+
+	pas.firstStrat.NetPosPass = 50
+	pas.setDynamicThresholds()
+	assertFloat(t, "normal entryZScoreBid", pas.entryZScoreBid, 2.0+(3.5-2.0)*0.5)
+	assertFloat(t, "normal entryZScoreAsk", pas.entryZScoreAsk, 2.0-(2.0-0.5)*0.5)
+
+	// 高波动期
+	for i := 200; i < 230; i++ {
+		spread = 10 + 5*noise[i]
+		pas.regimeSwitch.Update(spread)
+	}
+	if !pas.regimeSwitch.IsHigh() {
+		t.Fatalf("Expected high regime, P(high)=%.3f", pas.regimeSwitch.Vol.GetValue())
+	}
+
+	pas.setDynamicThresholds()
+	// BEGIN_PLACE_HIGH=3.0, LONG_PLACE_HIGH=5.0
+	assertFloat(t, "high entryZScoreBid", pas.entryZScoreBid, 3.0+(5.0-3.0)*0.5)
+	assertFloat(t, "high entryZScoreAsk", pas.entryZScoreAsk, 3.0-(3.0-0.5)*0.5)
+
+	pas.firstStrat.NetPosPass = 0
+	pas.setDynamicThresholds()
+	assertFloat(t, "high flat entryZScoreBid", pas.entryZScoreBid, 3.0)
+
+	indicators := map[string]float64{}
+	pas.regimeSwitch.FillIndicators(indicators)
+	if indicators["regime_high"] != 1 {
+		t.Errorf("Expected regime_high=1, got %f", indicators["regime_high"])
+	}
+}
+
+func TestRegimeSwitch_StaticThresholds(t *testing.T) {
+	pas := newRegimeTestPAS(t)
+	pas.useDynamicThreshold = false
+
+	noise := regimeNoise(300, 23)
+	for i := 0; i < 200; i++ {
+		pas.regimeSwitch.Update(10 + 0.5*noise[i])
+	}
+	pas.setDynamicThresholds()
+	assertFloat(t, "normal entryZScoreBid", pas.entryZScoreBid, 2.0)
+
+	for i := 200; i < 230; i++ {
+		pas.regimeSwitch.Update(10 + 5*noise[i])
+	}
+	pas.setDynamicThresholds()
+	assertFloat(t, "high entryZScoreBid", pas.entryZScoreBid, 3.0)
+	assertFloat(t, "high exitZScoreBid", pas.exitZScoreBid, 0.5)
+}
+
+func TestRegimeSwitch_ChangePointHolds(t *testing.T) {
+	cfg := DefaultRegimeSwitchConfig()
+	cfg.CusumPeriod = 50
+	cfg.HoldBars = 20
+	cfg.MaxHurst = 0
+	cfg.HighProb = 1.1 // 禁用波动率判据
+	rs := NewRegimeSwitch(cfg)
+
+	noise := regimeNoise(200, 29)
+	for i := 0; i < 100; i++ {
+		rs.Update(noise[i])
+	}
+	if rs.IsHigh() {
+		t.Fatal("Expected normal regime before mean shift")
+	}
+	for i := 100; i < 110; i++ {
+		rs.Update(4 + noise[i])
+	}
+	if !rs.ChangePoint() || !rs.IsHigh() {
+		t.Fatal("Expected change point to switch to high regime")
+	}
+	for i := 110; i < 200; i++ {
+		rs.Update(4 + noise[i])
+	}
+	if rs.IsHigh() {
+		t.Errorf("Expected high regime to expire after %d bars, bars since = %d",
+			cfg.HoldBars, rs.Cusum.BarsSinceChange())
+	}
+}
+
+func TestRegimeSwitch_ApplyParameters(t *testing.T) {
+	pas := newRegimeTestPAS(t)
+
+	if err := pas.ApplyParameters(map[string]interface{}{"regime_switch_enabled": false}); err != nil {
+		t.Fatalf("ApplyParameters failed: %v", err)
+	}
+	if pas.regimeSwitch != nil {
+		t.Error("Expected regimeSwitch to be disabled")
+	}
+	if err := pas.ApplyParameters(map[string]interface{}{
+		"regime_switch_enabled": true,
+		"begin_zscore_high":     2.5,
+	}); err != nil {
+		t.Fatalf("ApplyParameters failed: %v", err)
+	}
+	if pas.regimeSwitch == nil || pas.beginZScoreHigh != 2.5 {
+		t.Error("Expected regimeSwitch enabled with begin_zscore_high=2.5")
+	}
+	params := pas.GetCurrentParameters()
+	if params["regime_switch_enabled"] != true {
+		t.Errorf("Expected regime_switch_enabled=true, got %v", params["regime_switch_enabled"])
+	}
+}
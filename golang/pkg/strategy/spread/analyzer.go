@@ -15,6 +15,9 @@ type SpreadAnalyzer struct {
 	spreadType SpreadType
 	hedgeRatio float64
 
+	// kalman 非空时对冲比率由卡尔曼滤波逐笔估计（差价 spread），UpdateHedgeRatio 不再做 OLS
+	kalman *stats.KalmanRegression
+
 	// Time series
 	price1Series *stats.TimeSeries
 	price2Series *stats.TimeSeries
@@ -115,6 +118,10 @@ func (sa *SpreadAnalyzer) calculateSpreadLocked() float64 {
 	case SpreadTypeDifference:
 		fallthrough
 	default:
+		if sa.kalman != nil {
+			sa.kalman.Update(sa.price2, sa.price1)
+			sa.hedgeRatio = sa.kalman.Beta
+		}
 		sa.currentSpread = sa.price1 - sa.hedgeRatio*sa.price2
 	}
 
@@ -143,10 +150,14 @@ func (sa *SpreadAnalyzer) UpdateStatistics(lookbackPeriod int) {
 }
 
 // UpdateHedgeRatio 更新对冲比率（使用线性回归）
+// 卡尔曼模式下对冲比率已在 calculateSpreadLocked 中逐笔更新，这里不做处理
 func (sa *SpreadAnalyzer) UpdateHedgeRatio(lookbackPeriod int) {
 	sa.mu.Lock()
 	defer sa.mu.Unlock()
 
+	if sa.kalman != nil {
+		return
+	}
 	if sa.price1Series.Len() < lookbackPeriod || sa.price2Series.Len() < lookbackPeriod {
 		return
 	}
@@ -186,7 +197,7 @@ func (sa *SpreadAnalyzer) GetStats() SpreadStats {
 	sa.mu.RLock()
 	defer sa.mu.RUnlock()
 
-	return SpreadStats{
+	st := SpreadStats{
 		CurrentSpread: sa.currentSpread,
 		Mean:          sa.spreadMean,
 		Std:           sa.spreadStd,
@@ -194,6 +205,12 @@ func (sa *SpreadAnalyzer) GetStats() SpreadStats {
 		Correlation:   sa.correlation,
 		HedgeRatio:    sa.hedgeRatio,
 	}
+	if sa.kalman != nil {
+		st.Intercept = sa.kalman.Alpha
+		st.Residual = sa.kalman.Residual
+		st.ResidualVar = sa.kalman.ResidualVar
+	}
+	return st
 }
 
 // GetZScore 获取当前 z-score
@@ -217,6 +234,23 @@ func (sa *SpreadAnalyzer) GetHedgeRatio() float64 {
 	return sa.hedgeRatio
 }
 
+// SpreadOf 按当前 spread 类型和对冲比率计算给定价格的 spread（不记入历史，用于集合竞价指示价）
+func (sa *SpreadAnalyzer) SpreadOf(price1, price2 float64) float64 {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+	if price1 <= 0 || price2 <= 0 {
+		return 0
+	}
+	switch sa.spreadType {
+	case SpreadTypeRatio:
+		return price1 / price2
+	case SpreadTypeLog:
+		return math.Log(price1) - math.Log(price2)
+	default:
+		return price1 - sa.hedgeRatio*price2
+	}
+}
+
 // GetCurrentSpread 获取当前 spread 值
 func (sa *SpreadAnalyzer) GetCurrentSpread() float64 {
 	sa.mu.RLock()
@@ -253,6 +287,35 @@ func (sa *SpreadAnalyzer) SetHedgeRatio(ratio float64) {
 	sa.hedgeRatio = ratio
 }
 
+// EnableKalmanHedge 启用卡尔曼滤波动态对冲比率
+// qAlpha/qBeta: 截距/斜率过程噪声方差，r: 观测噪声方差
+// 已启用时只更新噪声参数，保留滤波状态
+func (sa *SpreadAnalyzer) EnableKalmanHedge(qAlpha, qBeta, r float64) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	if sa.kalman != nil {
+		sa.kalman.QAlpha = qAlpha
+		sa.kalman.QBeta = qBeta
+		sa.kalman.R = r
+		return
+	}
+	sa.kalman = stats.NewKalmanRegression(qAlpha, qBeta, r, sa.hedgeRatio)
+}
+
+// DisableKalmanHedge 关闭卡尔曼模式，保留当前对冲比率，后续由 UpdateHedgeRatio (OLS) 更新
+func (sa *SpreadAnalyzer) DisableKalmanHedge() {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	sa.kalman = nil
+}
+
+// IsKalmanHedge 是否为卡尔曼对冲比率模式
+func (sa *SpreadAnalyzer) IsKalmanHedge() bool {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+	return sa.kalman != nil
+}
+
 // IsReady 检查是否有足够的历史数据
 func (sa *SpreadAnalyzer) IsReady(lookbackPeriod int) bool {
 	sa.mu.RLock()
@@ -275,6 +338,10 @@ func (sa *SpreadAnalyzer) Reset() {
 	sa.spreadStd = 0
 	sa.currentZScore = 0
 	sa.correlation = 0
+	if sa.kalman != nil {
+		sa.kalman.Reset(1.0)
+		sa.hedgeRatio = 1.0
+	}
 
 	sa.price1Series.Clear()
 	sa.price2Series.Clear()
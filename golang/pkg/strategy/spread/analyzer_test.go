@@ -162,6 +162,39 @@ func TestSpreadAnalyzer_UpdateHedgeRatio(t *testing.T) {
 	}
 }
 
+func TestSpreadAnalyzer_KalmanHedge(t *testing.T) {
+	sa := NewSpreadAnalyzer("ag2603", "ag2605", SpreadTypeDifference, 100)
+	sa.EnableKalmanHedge(1e-4, 1e-8, 1.0)
+
+	// 对冲比率从 1.00 漂移到 1.02
+	beta := 1.0
+	for i := 0; i < 3000; i++ {
+		beta = 1.0 + 0.02*float64(i)/3000
+		price2 := 5000 + 200*math.Sin(float64(i)/50)
+		price1 := 10 + beta*price2 + math.Sin(float64(i)*1.7)
+		sa.UpdatePricesNow(price1, price2)
+	}
+
+	st := sa.GetStats()
+	if !almostEqual(st.HedgeRatio, beta, 0.005) {
+		t.Errorf("HedgeRatio = %f, want %f", st.HedgeRatio, beta)
+	}
+	if st.ResidualVar <= 0 || math.Abs(st.Residual) > 5 {
+		t.Errorf("Residual = %f, ResidualVar = %f", st.Residual, st.ResidualVar)
+	}
+
+	// 卡尔曼模式下 OLS 不覆盖对冲比率
+	sa.UpdateHedgeRatio(20)
+	if sa.GetHedgeRatio() != st.HedgeRatio {
+		t.Errorf("UpdateHedgeRatio overrode kalman beta: %f", sa.GetHedgeRatio())
+	}
+
+	sa.DisableKalmanHedge()
+	if sa.IsKalmanHedge() || sa.GetStats().ResidualVar != 0 {
+		t.Error("expected kalman mode disabled")
+	}
+}
+
 func TestSpreadAnalyzer_UpdateCorrelation(t *testing.T) {
 	sa := NewSpreadAnalyzer("AAPL", "MSFT", SpreadTypeDifference, 100)
 
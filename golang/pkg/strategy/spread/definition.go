@@ -0,0 +1,272 @@
+package spread
+
+import (
+	"fmt"
+	"math"
+)
+
+// Leg N 腿价差中的一条腿
+type Leg struct {
+	Symbol     string
+	Weight     float64 // 价差系数（带符号），如日历蝶式 +1 / -2 / +1
+	Ratio      int64   // 每单位价差的下单手数（带符号），0 表示取 round(Weight)
+	Multiplier float64 // 合约乘数（InstrumentSpec.ContractMultiplier），0 视为 1
+}
+
+// Definition N 腿价差定义
+// 把多条腿视为一个交易对象：一个价差值、一个价差持仓（单位数）、一个净敞口
+//
+// 价差值:
+//
+//	difference: Σ w_i * p_i
+//	ratio:      Σ_{w>0} w_i * p_i / Σ_{w<0} |w_i| * p_i   （两腿 +1/-1 时即 p1/p2）
+//	log:        Σ w_i * ln(p_i)                          （两腿 +1/-1 时即 ln(p1/p2)）
+//
+// Notional 为 true 时 p_i 取货值 price * multiplier，用于跨品种（如 rb/hc/i）按货值配比
+type Definition struct {
+	Name     string
+	Type     SpreadType
+	Legs     []Leg
+	Notional bool
+}
+
+// NewDefinition 创建并校验 N 腿价差定义
+func NewDefinition(name string, spreadType SpreadType, legs []Leg) (*Definition, error) {
+	if len(legs) < 2 {
+		return nil, fmt.Errorf("spread %s: need at least 2 legs, got %d", name, len(legs))
+	}
+	switch spreadType {
+	case SpreadTypeDifference, SpreadTypeRatio, SpreadTypeLog:
+	case "":
+		spreadType = SpreadTypeDifference
+	default:
+		return nil, fmt.Errorf("spread %s: unknown spread type %q", name, spreadType)
+	}
+
+	d := &Definition{Name: name, Type: spreadType, Legs: make([]Leg, len(legs))}
+	hasPos, hasNeg := false, false
+	seen := make(map[string]bool, len(legs))
+	for i, leg := range legs {
+		if leg.Symbol == "" {
+			return nil, fmt.Errorf("spread %s: leg %d has no symbol", name, i)
+		}
+		if seen[leg.Symbol] {
+			return nil, fmt.Errorf("spread %s: duplicate leg %s", name, leg.Symbol)
+		}
+		seen[leg.Symbol] = true
+		if leg.Weight == 0 {
+			return nil, fmt.Errorf("spread %s: leg %s has zero weight", name, leg.Symbol)
+		}
+		if leg.Ratio == 0 {
+			leg.Ratio = int64(math.Round(leg.Weight))
+		}
+		if leg.Ratio == 0 || (leg.Ratio > 0) != (leg.Weight > 0) {
+			return nil, fmt.Errorf("spread %s: leg %s ratio %d inconsistent with weight %g",
+				name, leg.Symbol, leg.Ratio, leg.Weight)
+		}
+		if leg.Multiplier <= 0 {
+			leg.Multiplier = 1
+		}
+		if leg.Weight > 0 {
+			hasPos = true
+		} else {
+			hasNeg = true
+		}
+		d.Legs[i] = leg
+	}
+	if spreadType == SpreadTypeRatio && (!hasPos || !hasNeg) {
+		return nil, fmt.Errorf("spread %s: ratio spread needs both positive and negative legs", name)
+	}
+	return d, nil
+}
+
+// NewPairDefinition 两腿价差 p1 - hedgeRatio*p2（或 ratio/log），下单比例 1:-1
+func NewPairDefinition(symbol1, symbol2 string, spreadType SpreadType, hedgeRatio, mult1, mult2 float64) (*Definition, error) {
+	if hedgeRatio <= 0 {
+		hedgeRatio = 1
+	}
+	return NewDefinition(symbol1+"/"+symbol2, spreadType, []Leg{
+		{Symbol: symbol1, Weight: 1, Ratio: 1, Multiplier: mult1},
+		{Symbol: symbol2, Weight: -hedgeRatio, Ratio: -1, Multiplier: mult2},
+	})
+}
+
+// NumLegs 腿数
+func (d *Definition) NumLegs() int {
+	return len(d.Legs)
+}
+
+// IndexOf 返回 symbol 所在腿的下标，不存在返回 -1
+func (d *Definition) IndexOf(symbol string) int {
+	for i := range d.Legs {
+		if d.Legs[i].Symbol == symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+// Value 按腿价格计算价差值，prices 与 Legs 一一对应
+// 任一腿价格无效（<=0）时返回 false
+func (d *Definition) Value(prices []float64) (float64, bool) {
+	if len(prices) != len(d.Legs) {
+		return 0, false
+	}
+	var sum, num, den float64
+	for i := range d.Legs {
+		p := prices[i]
+		if p <= 0 {
+			return 0, false
+		}
+		leg := &d.Legs[i]
+		if d.Notional {
+			p *= leg.Multiplier
+		}
+		switch d.Type {
+		case SpreadTypeRatio:
+			if leg.Weight > 0 {
+				num += leg.Weight * p
+			} else {
+				den -= leg.Weight * p
+			}
+		case SpreadTypeLog:
+			sum += leg.Weight * math.Log(p)
+		default:
+			sum += leg.Weight * p
+		}
+	}
+	if d.Type == SpreadTypeRatio {
+		return num / den, true
+	}
+	return sum, true
+}
+
+// Units 已配齐的价差单位数（带符号）：各腿 qty_i / Ratio_i 同号时取绝对值最小者，否则为 0
+func (d *Definition) Units(qty []int64) int64 {
+	if len(qty) != len(d.Legs) {
+		return 0
+	}
+	var units int64
+	for i := range d.Legs {
+		u := qty[i] / d.Legs[i].Ratio
+		if u == 0 {
+			return 0
+		}
+		if i == 0 {
+			units = u
+			continue
+		}
+		if (u > 0) != (units > 0) {
+			return 0
+		}
+		if absInt64(u) < absInt64(units) {
+			units = u
+		}
+	}
+	return units
+}
+
+// LegImbalance 第 i 腿相对于已配齐单位数的多余手数（瘸腿敞口）
+func (d *Definition) LegImbalance(qty []int64, i int) int64 {
+	if i < 0 || i >= len(d.Legs) || len(qty) != len(d.Legs) {
+		return 0
+	}
+	return qty[i] - d.Units(qty)*d.Legs[i].Ratio
+}
+
+// NetExposure 净货值敞口 Σ qty_i * p_i * multiplier_i（带符号）
+// 完全对冲的价差持仓（如同品种跨期）净敞口接近 0，风控应以此作为价差的单一敞口
+func (d *Definition) NetExposure(qty []int64, prices []float64) float64 {
+	if len(qty) != len(d.Legs) || len(prices) != len(d.Legs) {
+		return 0
+	}
+	net := 0.0
+	for i := range d.Legs {
+		net += float64(qty[i]) * prices[i] * d.Legs[i].Multiplier
+	}
+	return net
+}
+
+// GrossExposure 总货值敞口 Σ |qty_i| * p_i * multiplier_i
+func (d *Definition) GrossExposure(qty []int64, prices []float64) float64 {
+	if len(qty) != len(d.Legs) || len(prices) != len(d.Legs) {
+		return 0
+	}
+	gross := 0.0
+	for i := range d.Legs {
+		gross += float64(absInt64(qty[i])) * prices[i] * d.Legs[i].Multiplier
+	}
+	return gross
+}
+
+// ParseLegs 从策略参数解析腿定义
+//
+//	spread_weights: [1, -2, 1]      与 symbols 一一对应，缺省为 [1, -1]
+//	spread_ratios:  [1, -2, 1]      下单手数比例，缺省取 round(weight)
+//
+// 合约乘数由调用方填充（spread 包不依赖品种规格表）
+func ParseLegs(symbols []string, params map[string]interface{}) ([]Leg, error) {
+	weights, err := floatList(params["spread_weights"])
+	if err != nil {
+		return nil, fmt.Errorf("spread_weights: %w", err)
+	}
+	ratios, err := floatList(params["spread_ratios"])
+	if err != nil {
+		return nil, fmt.Errorf("spread_ratios: %w", err)
+	}
+	if weights == nil {
+		if len(symbols) != 2 {
+			return nil, fmt.Errorf("spread_weights required for %d legs", len(symbols))
+		}
+		weights = []float64{1, -1}
+	}
+	if len(weights) != len(symbols) {
+		return nil, fmt.Errorf("spread_weights has %d entries, want %d", len(weights), len(symbols))
+	}
+	if ratios != nil && len(ratios) != len(symbols) {
+		return nil, fmt.Errorf("spread_ratios has %d entries, want %d", len(ratios), len(symbols))
+	}
+
+	legs := make([]Leg, len(symbols))
+	for i, sym := range symbols {
+		legs[i] = Leg{Symbol: sym, Weight: weights[i]}
+		if ratios != nil {
+			legs[i].Ratio = int64(ratios[i])
+		}
+	}
+	return legs, nil
+}
+
+// floatList 把 YAML/JSON 解析出的 []interface{} / []float64 转成 []float64，nil 表示未配置
+func floatList(v interface{}) ([]float64, error) {
+	switch list := v.(type) {
+	case nil:
+		return nil, nil
+	case []float64:
+		return list, nil
+	case []interface{}:
+		out := make([]float64, len(list))
+		for i, x := range list {
+			switch f := x.(type) {
+			case float64:
+				out[i] = f
+			case int:
+				out[i] = float64(f)
+			case int64:
+				out[i] = float64(f)
+			default:
+				return nil, fmt.Errorf("entry %d: expected number, got %T", i, x)
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("expected list, got %T", v)
+	}
+}
+
+func absInt64(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,175 @@
+package spread
+
+import (
+	"math"
+	"testing"
+)
+
+func newButterfly(t *testing.T) *Definition {
+	def, err := NewDefinition("ag_fly", SpreadTypeDifference, []Leg{
+		{Symbol: "ag2603", Weight: 1, Multiplier: 15},
+		{Symbol: "ag2605", Weight: -2, Multiplier: 15},
+		{Symbol: "ag2607", Weight: 1, Multiplier: 15},
+	})
+	if err != nil {
+		t.Fatalf("NewDefinition: %v", err)
+	}
+	return def
+}
+
+func TestDefinition_ButterflyValue(t *testing.T) {
+	def := newButterfly(t)
+
+	v, ok := def.Value([]float64{5000, 5010, 5030})
+	if !ok || !almostEqual(v, 10, 1e-9) {
+		t.Errorf("Value = %f (ok=%v), want 10", v, ok)
+	}
+	if def.Legs[1].Ratio != -2 {
+		t.Errorf("default Ratio = %d, want -2", def.Legs[1].Ratio)
+	}
+	if _, ok := def.Value([]float64{5000, 0, 5030}); ok {
+		t.Error("Value should be invalid with a missing leg price")
+	}
+}
+
+func TestDefinition_RatioAndLog(t *testing.T) {
+	ratio, err := NewDefinition("r", SpreadTypeRatio, []Leg{
+		{Symbol: "a", Weight: 1}, {Symbol: "b", Weight: -1},
+	})
+	if err != nil {
+		t.Fatalf("NewDefinition: %v", err)
+	}
+	if v, _ := ratio.Value([]float64{110, 100}); !almostEqual(v, 1.1, 1e-12) {
+		t.Errorf("ratio = %f, want 1.1", v)
+	}
+
+	logDef, err := NewDefinition("l", SpreadTypeLog, []Leg{
+		{Symbol: "a", Weight: 1}, {Symbol: "b", Weight: -1},
+	})
+	if err != nil {
+		t.Fatalf("NewDefinition: %v", err)
+	}
+	if v, _ := logDef.Value([]float64{110, 100}); !almostEqual(v, math.Log(1.1), 1e-12) {
+		t.Errorf("log = %f, want %f", v, math.Log(1.1))
+	}
+
+	// 跨品种按货值：rb(10) - i(100)
+	notional, _ := NewDefinition("rb_i", SpreadTypeDifference, []Leg{
+		{Symbol: "rb2605", Weight: 1, Multiplier: 10},
+		{Symbol: "i2605", Weight: -1, Multiplier: 100},
+	})
+	notional.Notional = true
+	if v, _ := notional.Value([]float64{3500, 800}); !almostEqual(v, 35000-80000, 1e-9) {
+		t.Errorf("notional = %f, want %f", v, 35000.0-80000)
+	}
+}
+
+func TestDefinition_Validation(t *testing.T) {
+	cases := []struct {
+		name string
+		typ  SpreadType
+		legs []Leg
+	}{
+		{"one leg", SpreadTypeDifference, []Leg{{Symbol: "a", Weight: 1}}},
+		{"zero weight", SpreadTypeDifference, []Leg{{Symbol: "a", Weight: 1}, {Symbol: "b"}}},
+		{"duplicate", SpreadTypeDifference, []Leg{{Symbol: "a", Weight: 1}, {Symbol: "a", Weight: -1}}},
+		{"ratio sign", SpreadTypeDifference, []Leg{{Symbol: "a", Weight: 1}, {Symbol: "b", Weight: -1, Ratio: 1}}},
+		{"ratio one-sided", SpreadTypeRatio, []Leg{{Symbol: "a", Weight: 1}, {Symbol: "b", Weight: 2}}},
+		{"unknown type", "spline", []Leg{{Symbol: "a", Weight: 1}, {Symbol: "b", Weight: -1}}},
+	}
+	for _, c := range cases {
+		if _, err := NewDefinition(c.name, c.typ, c.legs); err == nil {
+			t.Errorf("%s: expected error", c.name)
+		}
+	}
+}
+
+func TestDefinition_UnitsAndExposure(t *testing.T) {
+	def := newButterfly(t)
+	prices := []float64{5000, 5010, 5030}
+
+	// 3 个完整蝶式 + 第一腿多 1 手（瘸腿）
+	qty := []int64{4, -6, 3}
+	if u := def.Units(qty); u != 3 {
+		t.Errorf("Units = %d, want 3", u)
+	}
+	if imb := def.LegImbalance(qty, 0); imb != 1 {
+		t.Errorf("LegImbalance(0) = %d, want 1", imb)
+	}
+	if u := def.Units([]int64{-2, 4, -2}); u != -2 {
+		t.Errorf("short Units = %d, want -2", u)
+	}
+	if u := def.Units([]int64{1, 2, 1}); u != 0 {
+		t.Errorf("inconsistent Units = %d, want 0", u)
+	}
+
+	net := def.NetExposure(qty, prices)
+	want := (4*5000.0 - 6*5010 + 3*5030) * 15
+	if !almostEqual(net, want, 1e-6) {
+		t.Errorf("NetExposure = %f, want %f", net, want)
+	}
+	gross := def.GrossExposure(qty, prices)
+	if !almostEqual(gross, (4*5000.0+6*5010+3*5030)*15, 1e-6) {
+		t.Errorf("GrossExposure = %f", gross)
+	}
+}
+
+func TestParseLegs(t *testing.T) {
+	legs, err := ParseLegs([]string{"a", "b", "c"}, map[string]interface{}{
+		"spread_weights": []interface{}{1.0, -2.0, 1.0},
+	})
+	if err != nil {
+		t.Fatalf("ParseLegs: %v", err)
+	}
+	if len(legs) != 3 || legs[1].Weight != -2 || legs[2].Symbol != "c" {
+		t.Errorf("legs = %+v", legs)
+	}
+
+	legs, err = ParseLegs([]string{"a", "b"}, map[string]interface{}{})
+	if err != nil || legs[1].Weight != -1 {
+		t.Errorf("default pair legs = %+v, err = %v", legs, err)
+	}
+
+	if _, err := ParseLegs([]string{"a", "b", "c"}, map[string]interface{}{}); err == nil {
+		t.Error("expected error without spread_weights for 3 legs")
+	}
+	if _, err := ParseLegs([]string{"a", "b"}, map[string]interface{}{"spread_weights": []interface{}{1.0}}); err == nil {
+		t.Error("expected error for weight count mismatch")
+	}
+}
+
+func TestMultiLegAnalyzer(t *testing.T) {
+	ma := NewMultiLegAnalyzer(newButterfly(t), 100)
+
+	if ma.UpdateLegPrice("rb2605", 3500) {
+		t.Error("UpdateLegPrice should reject unknown symbol")
+	}
+	ma.UpdateLegPrice("ag2603", 5000)
+	ma.UpdateLegPrice("ag2605", 5010)
+	ma.CalculateSpread(1)
+	if ma.IsValid() {
+		t.Fatal("spread should be invalid until all legs priced")
+	}
+
+	for i := 0; i < 30; i++ {
+		ma.UpdatePrices([]float64{5000, 5010, 5020 + float64(i%5)}, int64(i))
+	}
+	ma.UpdateStatistics(20)
+	st := ma.GetStats()
+	if !ma.IsValid() || !almostEqual(st.CurrentSpread, 4, 1e-9) {
+		t.Errorf("CurrentSpread = %f, want 4", st.CurrentSpread)
+	}
+	if !almostEqual(st.Mean, 2, 1e-9) || st.Std <= 0 {
+		t.Errorf("Mean = %f, Std = %f", st.Mean, st.Std)
+	}
+
+	// 完全对冲的蝶式净敞口 = (5000 - 2*5010 + 5024) * 15 * 1
+	if net := ma.NetExposure([]int64{1, -2, 1}); !almostEqual(net, 4*15, 1e-9) {
+		t.Errorf("NetExposure = %f, want 60", net)
+	}
+
+	ma.Reset()
+	if ma.IsValid() || ma.IsReady(1) {
+		t.Error("Reset should clear state")
+	}
+}
@@ -0,0 +1,156 @@
+package spread
+
+import (
+	"sync"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/stats"
+)
+
+// MultiLegAnalyzer 分析 N 腿价差（蝶式、跨品种组合等）
+// 与 SpreadAnalyzer 的统计口径一致（均值、标准差、z-score），价差值由 Definition 计算
+type MultiLegAnalyzer struct {
+	def *Definition
+
+	prices       []float64
+	spreadSeries *stats.TimeSeries
+
+	currentSpread float64
+	spreadMean    float64
+	spreadStd     float64
+	currentZScore float64
+	valid         bool
+
+	mu sync.RWMutex
+}
+
+// NewMultiLegAnalyzer 创建 N 腿价差分析器
+func NewMultiLegAnalyzer(def *Definition, maxHistory int) *MultiLegAnalyzer {
+	return &MultiLegAnalyzer{
+		def:          def,
+		prices:       make([]float64, def.NumLegs()),
+		spreadSeries: stats.NewTimeSeries(def.Name, maxHistory),
+	}
+}
+
+// Definition 返回价差定义
+func (ma *MultiLegAnalyzer) Definition() *Definition {
+	return ma.def
+}
+
+// UpdateLegPrice 更新某条腿的价格，symbol 不属于该价差时返回 false
+func (ma *MultiLegAnalyzer) UpdateLegPrice(symbol string, price float64) bool {
+	i := ma.def.IndexOf(symbol)
+	if i < 0 {
+		return false
+	}
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+	ma.prices[i] = price
+	return true
+}
+
+// UpdatePrices 同时更新所有腿价格并计算价差
+func (ma *MultiLegAnalyzer) UpdatePrices(prices []float64, timestamp int64) float64 {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+
+	copy(ma.prices, prices)
+	return ma.calculateSpreadLocked(timestamp)
+}
+
+// CalculateSpread 用当前各腿价格计算价差并加入历史
+// 任一腿尚无价格时不计算，返回上一次的价差
+func (ma *MultiLegAnalyzer) CalculateSpread(timestamp int64) float64 {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+	return ma.calculateSpreadLocked(timestamp)
+}
+
+func (ma *MultiLegAnalyzer) calculateSpreadLocked(timestamp int64) float64 {
+	v, ok := ma.def.Value(ma.prices)
+	if !ok {
+		return ma.currentSpread
+	}
+	ma.currentSpread = v
+	ma.valid = true
+	ma.spreadSeries.Append(v, timestamp)
+	return v
+}
+
+// UpdateStatistics 更新统计指标（均值、标准差、z-score）
+func (ma *MultiLegAnalyzer) UpdateStatistics(lookbackPeriod int) {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+
+	if ma.spreadSeries.Len() < lookbackPeriod {
+		return
+	}
+	st := ma.spreadSeries.Stats(lookbackPeriod)
+	ma.spreadMean = st.Mean
+	ma.spreadStd = st.Std
+	ma.currentZScore = stats.ZScore(ma.currentSpread, ma.spreadMean, ma.spreadStd)
+}
+
+// GetStats 获取当前统计信息（HedgeRatio/Correlation 对 N 腿价差无意义，保持 0）
+func (ma *MultiLegAnalyzer) GetStats() SpreadStats {
+	ma.mu.RLock()
+	defer ma.mu.RUnlock()
+	return SpreadStats{
+		CurrentSpread: ma.currentSpread,
+		Mean:          ma.spreadMean,
+		Std:           ma.spreadStd,
+		ZScore:        ma.currentZScore,
+	}
+}
+
+// Prices 返回各腿最新价格的副本
+func (ma *MultiLegAnalyzer) Prices() []float64 {
+	ma.mu.RLock()
+	defer ma.mu.RUnlock()
+	out := make([]float64, len(ma.prices))
+	copy(out, ma.prices)
+	return out
+}
+
+// NetExposure 按最新价格计算净货值敞口
+func (ma *MultiLegAnalyzer) NetExposure(qty []int64) float64 {
+	ma.mu.RLock()
+	defer ma.mu.RUnlock()
+	return ma.def.NetExposure(qty, ma.prices)
+}
+
+// GrossExposure 按最新价格计算总货值敞口
+func (ma *MultiLegAnalyzer) GrossExposure(qty []int64) float64 {
+	ma.mu.RLock()
+	defer ma.mu.RUnlock()
+	return ma.def.GrossExposure(qty, ma.prices)
+}
+
+// IsValid 是否所有腿都有价格并已算出价差
+func (ma *MultiLegAnalyzer) IsValid() bool {
+	ma.mu.RLock()
+	defer ma.mu.RUnlock()
+	return ma.valid
+}
+
+// IsReady 是否有足够的价差历史
+func (ma *MultiLegAnalyzer) IsReady(lookbackPeriod int) bool {
+	ma.mu.RLock()
+	defer ma.mu.RUnlock()
+	return ma.spreadSeries.Len() >= lookbackPeriod
+}
+
+// Reset 重置分析器
+func (ma *MultiLegAnalyzer) Reset() {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+	for i := range ma.prices {
+		ma.prices[i] = 0
+	}
+	ma.currentSpread = 0
+	ma.spreadMean = 0
+	ma.spreadStd = 0
+	ma.currentZScore = 0
+	ma.valid = false
+	ma.spreadSeries.Clear()
+}
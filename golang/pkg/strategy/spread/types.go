@@ -24,4 +24,9 @@ type SpreadStats struct {
 	ZScore        float64 // Z-Score
 	Correlation   float64 // 价格相关系数
 	HedgeRatio    float64 // 对冲比率（Beta）
+
+	// 卡尔曼对冲比率模式（EnableKalmanHedge）下有效
+	Intercept   float64 // 截距 alpha
+	Residual    float64 // 新息残差 price1 - alpha - beta*price2
+	ResidualVar float64 // 残差方差
 }
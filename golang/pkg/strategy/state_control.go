@@ -73,6 +73,8 @@ const (
 	FlattenReasonMaxOrderCount
 	// FlattenReasonManual - Manual flatten request
 	FlattenReasonManual
+	// FlattenReasonAllocation - Portfolio allocation shrunk to zero (Go 扩展，需 sizing.force_flatten)
+	FlattenReasonAllocation
 )
 
 // String returns the string representation of FlattenReason
@@ -98,6 +100,8 @@ func (r FlattenReason) String() string {
 		return "MaxOrderCount"
 	case FlattenReasonManual:
 		return "Manual"
+	case FlattenReasonAllocation:
+		return "Allocation"
 	default:
 		return "Unknown"
 	}
@@ -8,6 +8,8 @@ import (
 	"github.com/yourusername/quantlink-trade-system/pkg/indicators"
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
 	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	"tbsrc-golang/pkg/calendar"
 )
 
 // Strategy is the interface that all trading strategies must implement
@@ -175,6 +177,35 @@ type DetailedOrderStrategy interface {
 	OnOrderRejected(update *orspb.OrderUpdate)
 }
 
+// SessionAware is an optional interface for strategies that need trading
+// session phase notifications (pre-open, auction, open, break, closed).
+// 由 Trader 按策略首个品种的时段模板推送，需启用 session.calendar
+type SessionAware interface {
+	// OnSessionEvent is called on startup and on every phase change
+	OnSessionEvent(ev calendar.Event)
+}
+
+// TradingDayRoller is an optional interface for strategies that carry
+// positions across trading days (今仓/昨仓区分，SHFE 平今/平昨).
+// 由 Trader 在交易日历的收盘事件中调用
+type TradingDayRoller interface {
+	// RolloverTradingDay closes tradingDay (YYYYMMDD): saves the end-of-day
+	// snapshot, promotes today's positions to yesterday's, resets daily
+	// counters and regenerates daily_init. Returns false if the day was
+	// already rolled over.
+	RolloverTradingDay(tradingDay string) (bool, error)
+}
+
+// OrderAdopter is an optional interface for strategies that can take over
+// orders left live at the exchange by a previous process (重启后的在途订单).
+// 由 Trader 在启动时调用；返回错误的订单由 Trader 撤单（Go 扩展）
+type OrderAdopter interface {
+	// AdoptOrder registers a live order in the strategy's order maps.
+	// Returns an error if the order cannot be tracked (unknown symbol,
+	// price level already occupied).
+	AdoptOrder(update *orspb.OrderUpdate) error
+}
+
 // StrategyDataProvider 提供策略数据给外部系统（WebSocket、REST API等）
 // 与核心 Strategy 接口分离，职责单一
 // 由 StrategyDataContext 实现，具体策略通过嵌入自动获得
@@ -295,25 +326,25 @@ func (ctx *StrategyDataContext) SetConcreteStrategy(strategy interface{}) {
 // === StrategyDataProvider 接口实现 ===
 
 // GetIndicatorValues 获取所有指标值
+// 返回新 map：WebSocket 推送异步序列化，调用方之间不能共享结果
 func (ctx *StrategyDataContext) GetIndicatorValues() map[string]float64 {
-	values := make(map[string]float64)
+	dst := make(map[string]float64)
+	set := func(key string, value float64) {
+		dst[key] = value
+	}
 
 	if ctx.SharedIndicators != nil {
-		for key, value := range ctx.SharedIndicators.GetAllValues() {
-			values[key] = value
-		}
+		ctx.SharedIndicators.VisitValues(set)
 	}
 	if ctx.PrivateIndicators != nil {
-		for key, value := range ctx.PrivateIndicators.GetAllValues() {
-			values[key] = value
-		}
+		ctx.PrivateIndicators.VisitValues(set)
 	}
 	if ctx.ControlState != nil && ctx.ControlState.Indicators != nil {
 		for key, value := range ctx.ControlState.Indicators {
-			values[key] = value
+			dst[key] = value
 		}
 	}
-	return values
+	return dst
 }
 
 // GetMarketDataSnapshot 获取最新行情快照
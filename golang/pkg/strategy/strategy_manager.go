@@ -65,11 +65,18 @@ func (sm *StrategyManager) addStrategyLocked(cfg config.StrategyItemConfig) erro
 	}
 
 	// 创建策略实例
-	strategy, err := sm.createStrategy(cfg)
+	strategy, err := NewStrategy(cfg.Type, cfg.ID)
 	if err != nil {
 		return fmt.Errorf("failed to create strategy: %w", err)
 	}
 
+	// 原生 tbsrc 策略挂接共享 SHM 链路（Go 扩展）
+	if na, ok := strategy.(NativeSHMAware); ok && sm.engine != nil {
+		if t, ok := sm.engine.Transport().(NativeSHM); ok {
+			na.AttachSHM(t)
+		}
+	}
+
 	// 初始化策略
 	strategyConfig := sm.toStrategyConfig(cfg)
 	if err := strategy.Initialize(strategyConfig); err != nil {
@@ -95,24 +102,30 @@ func (sm *StrategyManager) addStrategyLocked(cfg config.StrategyItemConfig) erro
 	return nil
 }
 
-// createStrategy 策略工厂
-func (sm *StrategyManager) createStrategy(cfg config.StrategyItemConfig) (Strategy, error) {
-	switch cfg.Type {
+// NewStrategy 策略工厂（影子模式按类型另建策略副本）
+func NewStrategy(strategyType, id string) (Strategy, error) {
+	switch strategyType {
 	case "passive":
-		return NewPassiveStrategy(cfg.ID), nil
+		return NewPassiveStrategy(id), nil
 	case "aggressive":
-		return NewAggressiveStrategy(cfg.ID), nil
+		return NewAggressiveStrategy(id), nil
 	case "hedging":
-		return NewHedgingStrategy(cfg.ID), nil
+		return NewHedgingStrategy(id), nil
 	case "pairwise_arb":
-		return NewPairwiseArbStrategy(cfg.ID), nil
+		return NewPairwiseArbStrategy(id), nil
+	case "tbsrc_pairwise":
+		return NewTbsrcPairwiseStrategy(id), nil
+	case MarketMakingStrategyType:
+		return NewMarketMakingStrategy(id), nil
+	case BasketArbStrategyType:
+		return NewBasketArbStrategy(id), nil
 	// 可扩展更多策略类型
 	// case "trend_following":
 	// 	return NewTrendFollowingStrategy(cfg.ID), nil
 	// case "grid":
 	// 	return NewGridStrategy(cfg.ID), nil
 	default:
-		return nil, fmt.Errorf("unknown strategy type: %s", cfg.Type)
+		return nil, fmt.Errorf("unknown strategy type: %s", strategyType)
 	}
 }
 
@@ -122,6 +135,7 @@ func (sm *StrategyManager) toStrategyConfig(cfg config.StrategyItemConfig) *Stra
 		StrategyID:      cfg.ID,
 		StrategyType:    cfg.Type,
 		Symbols:         cfg.Symbols,
+		Exchanges:       cfg.Exchanges,
 		MaxPositionSize: cfg.MaxPositionSize,
 		Allocation:      cfg.Allocation,
 		Parameters:      cfg.Parameters,
@@ -231,6 +245,9 @@ func (sm *StrategyManager) RemoveStrategy(strategyID string) error {
 			log.Printf("[StrategyManager] Warning: failed to remove strategy from engine: %v", err)
 		}
 	}
+	if na, ok := strategy.(NativeSHMAware); ok {
+		na.DetachSHM()
+	}
 
 	// 从管理器移除
 	delete(sm.strategies, strategyID)
@@ -0,0 +1,381 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	tbclient "tbsrc-golang/pkg/client"
+	tbconfig "tbsrc-golang/pkg/config"
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/shm"
+	tbstrategy "tbsrc-golang/pkg/strategy"
+	"tbsrc-golang/pkg/types"
+)
+
+// NativeSHMAware is an optional interface for strategies that run natively on
+// the SHM transport (tbsrc-golang 策略，直接处理 shm 消息)
+// 由 StrategyManager 在 Initialize 之前调用 AttachSHM；引擎未使用 SHM 链路时不调用
+type NativeSHMAware interface {
+	// AttachSHM hands the shared SHM transport to the strategy
+	AttachSHM(t NativeSHM)
+
+	// DetachSHM stops routing SHM messages to the strategy (RemoveStrategy)
+	DetachSHM()
+}
+
+// TbsrcPairwiseStrategy 在 StrategyEngine 中运行 tbsrc-golang 的 PairwiseArbStrategy（Go 扩展）
+//
+// 策略逻辑完全由 tbsrc 实现（与 C++ PairwiseArbStrategy 对齐），行情/回报不经过 proto 转换：
+// SHMTransport 把原始 MarketUpdateNew 和本策略 StrategyID 的 ResponseMsg 交给 tbsrc Client。
+// 本类型只把启停、持仓/盈亏查询、参数热加载和交易日切换适配到 Strategy 接口。
+//
+// parameters 与 tbsrc trader 配置的 strategy 段相同：
+//
+//	strategy_id: 92201          # RequestMsg.StrategyID，daily_init.<id>
+//	account / product
+//	instruments: {ag2603: {exchange: SHFE, tick_size: 1, lot_size: 15, ...}, ...}
+//	thresholds: {first: {...}, second: {...}}
+//	exchange_costs: {...}
+//	daily_init: ""              # 默认 <dataDir>/daily_init.<strategy_id>
+type TbsrcPairwiseStrategy struct {
+	*StrategyDataContext
+
+	shm NativeSHM
+	pas *tbstrategy.PairwiseArbStrategy
+	cfg tbconfig.StrategyConfig
+
+	squaredOff   bool
+	auctionPhase AuctionPhaseFunc // 交易日历竞价时段判断（引擎设置，可为 nil）
+	mu           sync.Mutex
+}
+
+// NewTbsrcPairwiseStrategy creates an unattached native pairwise strategy
+func NewTbsrcPairwiseStrategy(id string) *TbsrcPairwiseStrategy {
+	s := &TbsrcPairwiseStrategy{
+		StrategyDataContext: NewStrategyDataContext(id, "tbsrc_pairwise"),
+	}
+	s.StrategyDataContext.SetConcreteStrategy(s)
+	return s
+}
+
+// AttachSHM implements NativeSHMAware
+func (s *TbsrcPairwiseStrategy) AttachSHM(t NativeSHM) {
+	s.shm = t
+}
+
+// DetachSHM implements NativeSHMAware
+func (s *TbsrcPairwiseStrategy) DetachSHM() {
+	if s.shm != nil && s.pas != nil {
+		s.shm.DetachNative(s.pas.StrategyID)
+	}
+}
+
+// SetAuctionPhaseFunc implements AuctionPhaseSetter
+func (s *TbsrcPairwiseStrategy) SetAuctionPhaseFunc(fn AuctionPhaseFunc) {
+	s.mu.Lock()
+	s.auctionPhase = fn
+	s.mu.Unlock()
+}
+
+// nativeAuctionPhase 把引擎的竞价时段判断适配到 tbsrc（交易所时间戳，纳秒）
+func (s *TbsrcPairwiseStrategy) nativeAuctionPhase(symbol string, exchTS uint64) bool {
+	s.mu.Lock()
+	fn := s.auctionPhase
+	s.mu.Unlock()
+	return fn != nil && exchTS > 0 && fn(symbol, time.Unix(0, int64(exchTS)))
+}
+
+// Native returns the underlying tbsrc strategy (nil before Initialize)
+func (s *TbsrcPairwiseStrategy) Native() *tbstrategy.PairwiseArbStrategy {
+	return s.pas
+}
+
+// Initialize builds the tbsrc Client / Instruments / PairwiseArbStrategy on the shared connector
+// 步骤与 tbsrc cmd/trader 相同，daily_init 不存在时从零开始
+func (s *TbsrcPairwiseStrategy) Initialize(config *StrategyConfig) error {
+	s.Config = config
+	if s.shm == nil || s.shm.Connector() == nil {
+		return fmt.Errorf("strategy type tbsrc_pairwise requires engine.transport: shm")
+	}
+	if len(config.Symbols) != 2 {
+		return fmt.Errorf("tbsrc_pairwise requires exactly 2 symbols, got %d", len(config.Symbols))
+	}
+
+	raw, err := yaml.Marshal(config.Parameters)
+	if err != nil {
+		return fmt.Errorf("parameters: %w", err)
+	}
+	if err := yaml.Unmarshal(raw, &s.cfg); err != nil {
+		return fmt.Errorf("parameters: %w", err)
+	}
+	if s.cfg.StrategyID <= 0 {
+		return fmt.Errorf("parameters.strategy_id must be a positive integer")
+	}
+	sym1, sym2 := config.Symbols[0], config.Symbols[1]
+	s.cfg.Symbols = config.Symbols
+	icfg1, icfg2 := s.cfg.Instruments[sym1], s.cfg.Instruments[sym2]
+	for i, icfg := range []*tbconfig.InstrumentConfig{&icfg1, &icfg2} {
+		if icfg.Exchange == "" && i < len(config.Exchanges) {
+			icfg.Exchange = config.Exchanges[i]
+		}
+		if icfg.TickSize <= 0 {
+			return fmt.Errorf("parameters.instruments.%s.tick_size must be positive", config.Symbols[i])
+		}
+	}
+
+	id := int32(s.cfg.StrategyID)
+	cli := tbclient.NewClient(s.shm.Connector(), id, s.cfg.Account, s.cfg.Product, shmExchangeCode(icfg1.Exchange))
+	inst1 := instrument.NewFromConfig(sym1, icfg1.Exchange, icfg1.TickSize, icfg1.LotSize,
+		icfg1.ContractFactor, icfg1.PriceMultiplier, icfg1.PriceFactor, icfg1.SendInLots,
+		icfg1.Token, icfg1.ExpiryDate)
+	inst2 := instrument.NewFromConfig(sym2, icfg2.Exchange, icfg2.TickSize, icfg2.LotSize,
+		icfg2.ContractFactor, icfg2.PriceMultiplier, icfg2.PriceFactor, icfg2.SendInLots,
+		icfg2.Token, icfg2.ExpiryDate)
+	cli.RegisterInstrument(inst1)
+	cli.RegisterInstrument(inst2)
+
+	thold1, thold2 := types.NewThresholdSet(), types.NewThresholdSet()
+	if m, ok := s.cfg.Thresholds["first"]; ok {
+		thold1.LoadFromMap(m)
+	}
+	if m, ok := s.cfg.Thresholds["second"]; ok {
+		thold2.LoadFromMap(m)
+	}
+
+	pas := tbstrategy.NewPairwiseArbStrategy(cli, inst1, inst2, thold1, thold2, id, s.cfg.Account)
+	pas.AuctionPhase = s.nativeAuctionPhase
+	ec := s.cfg.ExchCosts
+	pas.Leg1.SetExchangeCosts(ec.BuyExchTx, ec.SellExchTx, ec.BuyExchContractTx, ec.SellExchContractTx)
+	pas.Leg2.SetExchangeCosts(ec.BuyExchTx, ec.SellExchTx, ec.BuyExchContractTx, ec.SellExchContractTx)
+	cli.RegisterStrategy(sym1, pas)
+	cli.RegisterStrategy(sym2, pas)
+
+	dailyPath, _ := config.Parameters["daily_init"].(string)
+	if dailyPath == "" {
+		dailyPath = tbconfig.DailyInitPath(GetDataDir(), s.cfg.StrategyID)
+	}
+	daily, err := tbconfig.LoadMatrix2(dailyPath, id)
+	if err != nil {
+		return fmt.Errorf("daily_init %s: %w", dailyPath, err)
+	}
+	pas.DailyInitPath = dailyPath
+	pas.Init(daily.AvgSpreadOri, daily.NetposYtd1, daily.Netpos2day1, daily.NetposAgg2)
+
+	if err := s.shm.AttachNative(cli); err != nil {
+		return err
+	}
+	s.pas = pas
+	s.Status.StartTime = time.Now()
+	log.Printf("[TbsrcPairwise:%s] Initialized: strategyID=%d %s/%s daily_init=%s avgSpreadOri=%.4f ytd1=%d agg2=%d",
+		s.ID, id, sym1, sym2, dailyPath, daily.AvgSpreadOri, daily.NetposYtd1, daily.NetposAgg2)
+	return nil
+}
+
+// Start 激活策略（C++: HandleSquareON）
+func (s *TbsrcPairwiseStrategy) Start() error {
+	if s.pas == nil {
+		return fmt.Errorf("strategy not initialized")
+	}
+	s.mu.Lock()
+	s.squaredOff = false
+	s.mu.Unlock()
+	s.pas.HandleSquareON()
+	s.ControlState.RunState = StrategyRunStateActive
+	s.Activate()
+	log.Printf("[TbsrcPairwise:%s] Started", s.ID)
+	return nil
+}
+
+// Stop 停止报单并保存 daily_init（不主动平仓，与 tbsrc SIGTERM 一致）
+func (s *TbsrcPairwiseStrategy) Stop() error {
+	if !s.IsRunning() {
+		return fmt.Errorf("strategy not running")
+	}
+	s.pas.SetActive(false)
+	s.pas.SaveDailyInit()
+	s.ControlState.RunState = StrategyRunStateStopped
+	s.Deactivate()
+	return nil
+}
+
+// 行情/回报由 tbsrc Client 直接处理，proto 回调不做任何事
+
+func (s *TbsrcPairwiseStrategy) OnMarketData(md *mdpb.MarketDataUpdate)  {}
+func (s *TbsrcPairwiseStrategy) OnAuctionData(md *mdpb.MarketDataUpdate) {}
+func (s *TbsrcPairwiseStrategy) OnOrderUpdate(update *orspb.OrderUpdate) {}
+func (s *TbsrcPairwiseStrategy) OnTimer(now time.Time)                   {}
+func (s *TbsrcPairwiseStrategy) Reset()                                  {}
+func (s *TbsrcPairwiseStrategy) SendOrder()                              {}
+func (s *TbsrcPairwiseStrategy) OnTradeUpdate()                          {}
+func (s *TbsrcPairwiseStrategy) CheckSquareoff()                         {}
+func (s *TbsrcPairwiseStrategy) SetThresholds()                          {}
+func (s *TbsrcPairwiseStrategy) GetPendingCancels() []*orspb.OrderUpdate { return nil }
+func (s *TbsrcPairwiseStrategy) GetControlState() *StrategyControlState  { return s.ControlState }
+func (s *TbsrcPairwiseStrategy) GetConfig() *StrategyConfig              { return s.Config }
+func (s *TbsrcPairwiseStrategy) GetPosition() *EstimatedPosition         { return s.GetEstimatedPosition() }
+
+// HandleSquareON 恢复报单
+func (s *TbsrcPairwiseStrategy) HandleSquareON() {
+	s.mu.Lock()
+	s.squaredOff = false
+	s.mu.Unlock()
+	s.pas.HandleSquareON()
+}
+
+// HandleSquareoff 撤单平仓并保存 daily_init，重复调用只执行一次
+// C++: PairwiseArbStrategy::HandleSquareoff
+func (s *TbsrcPairwiseStrategy) HandleSquareoff() {
+	s.mu.Lock()
+	done := s.squaredOff
+	s.squaredOff = true
+	s.mu.Unlock()
+	if !done {
+		s.pas.HandleSquareoff()
+	}
+}
+
+// TriggerFlatten 平仓（tbsrc 只有一种平仓方式，aggressive 忽略）
+func (s *TbsrcPairwiseStrategy) TriggerFlatten(reason FlattenReason, aggressive bool) {
+	log.Printf("[TbsrcPairwise:%s] Flatten triggered: reason=%v", s.ID, reason)
+	s.ControlState.FlattenMode = true
+	s.HandleSquareoff()
+}
+
+// CanSendOrder returns true if the tbsrc strategy is quoting
+func (s *TbsrcPairwiseStrategy) CanSendOrder() bool {
+	return s.pas != nil && s.pas.IsActive()
+}
+
+// GetEstimatedPosition 第一腿被动仓（价差方向持仓）
+// 第二腿对冲仓见 NetExposure（= leg1 被动 + leg2 主动）
+func (s *TbsrcPairwiseStrategy) GetEstimatedPosition() *EstimatedPosition {
+	if s.pas == nil {
+		return &EstimatedPosition{}
+	}
+	st := s.pas.Leg1.State
+	pos := &EstimatedPosition{
+		Symbol:         s.pas.Inst1.Symbol,
+		Exchange:       s.pas.Inst1.Exchange,
+		NetQty:         int64(st.NetposPass),
+		BuyTotalQty:    int64(st.BuyTotalQty),
+		SellTotalQty:   int64(st.SellTotalQty),
+		BuyTotalValue:  st.BuyTotalValue,
+		SellTotalValue: st.SellTotalValue,
+		BuyAvgPrice:    st.BuyAvgPrice,
+		SellAvgPrice:   st.SellAvgPrice,
+		YesterdayQty:   int64(st.NetposPassYtd),
+		TodayQty:       int64(st.NetposPass - st.NetposPassYtd),
+		RealizedPnL:    st.RealisedPNL,
+	}
+	if pos.NetQty > 0 {
+		pos.BuyQty = pos.NetQty
+	} else {
+		pos.SellQty = -pos.NetQty
+	}
+	return pos
+}
+
+// GetPositionsBySymbol 实现 PositionProvider：两腿净仓（ExecutionState.Netpos = 被动 + 主动）
+func (s *TbsrcPairwiseStrategy) GetPositionsBySymbol() map[string]int64 {
+	if s.pas == nil {
+		return map[string]int64{}
+	}
+	return map[string]int64{
+		s.pas.Inst1.Symbol: int64(s.pas.Leg1.State.Netpos),
+		s.pas.Inst2.Symbol: int64(s.pas.Leg2.State.Netpos),
+	}
+}
+
+// GetPNL 两腿合计
+func (s *TbsrcPairwiseStrategy) GetPNL() *PNL {
+	if s.pas == nil {
+		return &PNL{}
+	}
+	l1, l2 := s.pas.Leg1.State, s.pas.Leg2.State
+	return &PNL{
+		RealizedPnL:   l1.RealisedPNL + l2.RealisedPNL,
+		UnrealizedPnL: l1.UnrealisedPNL + l2.UnrealisedPNL,
+		TotalPnL:      l1.GrossPNL + l2.GrossPNL,
+		TradingFees:   l1.TransTotalValue + l2.TransTotalValue,
+		NetPnL:        l1.NetPNL + l2.NetPNL,
+		MaxDrawdown:   l1.Drawdown + l2.Drawdown,
+		Timestamp:     time.Now(),
+	}
+}
+
+// GetRiskMetrics 净敞口（手）与价差对象货值
+func (s *TbsrcPairwiseStrategy) GetRiskMetrics() *RiskMetrics {
+	if s.pas == nil {
+		return &RiskMetrics{}
+	}
+	net, gross := s.pas.ExposureValue()
+	return &RiskMetrics{
+		PositionSize:    int64(s.pas.NetExposure()),
+		MaxPositionSize: int64(s.pas.Thold1.MaxSize),
+		ExposureValue:   net,
+		GrossExposure:   gross,
+		Timestamp:       time.Now(),
+	}
+}
+
+// GetStatus returns strategy status
+func (s *TbsrcPairwiseStrategy) GetStatus() *StrategyStatus {
+	s.Status.IsRunning = s.IsRunning()
+	s.Status.EstimatedPosition = s.GetEstimatedPosition()
+	s.Status.PNL = s.GetPNL()
+	s.Status.RiskMetrics = s.GetRiskMetrics()
+	return s.Status
+}
+
+// UpdateParameters 热加载阈值（同 tbsrc SIGUSR2 / 参数变更工作流）
+func (s *TbsrcPairwiseStrategy) UpdateParameters(params map[string]interface{}) error {
+	if s.pas == nil {
+		return fmt.Errorf("strategy not initialized")
+	}
+	t := tbstrategy.NewParamsTarget(s.pas)
+	return t.Apply(strconv.Itoa(int(s.pas.StrategyID)), params)
+}
+
+// GetCurrentParameters 当前第一腿阈值
+func (s *TbsrcPairwiseStrategy) GetCurrentParameters() map[string]interface{} {
+	if s.pas == nil {
+		return map[string]interface{}{}
+	}
+	t := tbstrategy.NewParamsTarget(s.pas)
+	params, _ := t.Parameters(strconv.Itoa(int(s.pas.StrategyID)))
+	return params
+}
+
+// RolloverTradingDay implements TradingDayRoller
+func (s *TbsrcPairwiseStrategy) RolloverTradingDay(tradingDay string) (bool, error) {
+	if s.pas == nil {
+		return false, fmt.Errorf("strategy not initialized")
+	}
+	return s.pas.RolloverTradingDay(tradingDay), nil
+}
+
+// shmExchangeCode 交易所名称 → SHM 交易所代码
+// 与 tbsrc cmd/trader exchangeTypeFromString 相同，另接受 CZCE / INE
+func shmExchangeCode(exchange string) uint8 {
+	switch exchange {
+	case "SHFE", "INE":
+		return shm.ChinaSHFE
+	case "CFFEX":
+		return shm.ChinaCFFEX
+	case "ZCE", "CZCE":
+		return shm.ChinaZCE
+	case "DCE":
+		return shm.ChinaDCE
+	case "GFEX":
+		return shm.ChinaGFEX
+	}
+	return shm.ExchangeUnknown
+}
@@ -88,6 +88,15 @@ type ThresholdSet struct {
 	// C++: ExecutionStrategy.cpp:99-113
 	TVarKey   int // TVAR_KEY - tValue 共享内存键 (用于外部调整价差均值)
 	TCacheKey int // TCACHE_KEY - tcache 共享内存键 (用于向外部共享持仓)
+
+	// === 集合竞价参数 (Auction Parameters，Go 扩展) ===
+	// 仅在 OnAuctionData 中使用，与连续交易阈值相互独立
+	AuctionEnable    bool    // AUCTION_ENABLE - 集合竞价期间参与报单
+	AuctionPlace     float64 // AUCTION_PLACE - 指示性价差偏离达到该值时报单（配对策略为 z-score）
+	AuctionSize      int32   // AUCTION_SIZE - 竞价单数量（0 = SIZE）
+	AuctionMaxSize   int32   // AUCTION_MAX_SIZE - 竞价后持仓上限（0 = MAX_SIZE）
+	AuctionSlop      int32   // AUCTION_SLOP - 报价相对均衡价向对手方让出的 tick 数
+	AuctionMinVolume int64   // AUCTION_MIN_VOLUME - 指示性可成交量下限（手）
 }
 
 // NewThresholdSet creates a new ThresholdSet with default values
@@ -177,6 +186,10 @@ func NewThresholdSet() *ThresholdSet {
 		// 共享内存默认值（0 表示不启用）
 		TVarKey:   0,
 		TCacheKey: 0,
+
+		// 集合竞价默认不参与
+		AuctionEnable: false,
+		AuctionPlace:  1e9, // 默认不触发
 	}
 }
 
@@ -403,6 +416,30 @@ func (ts *ThresholdSet) LoadFromMap(params map[string]interface{}) {
 	if val, ok := params["tcache_key"].(float64); ok {
 		ts.TCacheKey = int(val)
 	}
+
+	ts.loadAuction(params)
+}
+
+// loadAuction loads the auction thresholds (AUCTION_*)
+func (ts *ThresholdSet) loadAuction(params map[string]interface{}) {
+	if val, ok := params["auction_enable"].(bool); ok {
+		ts.AuctionEnable = val
+	}
+	if val, ok := params["auction_place"].(float64); ok {
+		ts.AuctionPlace = val
+	}
+	if val, ok := params["auction_size"].(float64); ok {
+		ts.AuctionSize = int32(val)
+	}
+	if val, ok := params["auction_max_size"].(float64); ok {
+		ts.AuctionMaxSize = int32(val)
+	}
+	if val, ok := params["auction_slop"].(float64); ok {
+		ts.AuctionSlop = int32(val)
+	}
+	if val, ok := params["auction_min_volume"].(float64); ok {
+		ts.AuctionMinVolume = int64(val)
+	}
 }
 
 // GetLongPlaceDiff returns LONG_PLACE - BEGIN_PLACE
@@ -0,0 +1,153 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/client"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// Transport carries market data, orders and order updates between the
+// StrategyEngine and the trading infrastructure (Go 扩展)
+//
+// 默认实现为 NATS 行情/回报 + gRPC ORS Gateway；SHMTransport 直接读写
+// hftbase SysV 共享内存队列（与 C++ TradeBot / tbsrc-golang 相同的链路）。
+// 回调在传输层的 goroutine 中调用
+type Transport interface {
+	// Name returns the transport name for logging ("nats", "shm")
+	Name() string
+
+	// Connect connects to the infrastructure; called once by StrategyEngine.Initialize
+	Connect() error
+
+	// SubscribeMarketData delivers market data of symbol to handler
+	SubscribeMarketData(symbol string, handler func(md *mdpb.MarketDataUpdate)) error
+
+	// SubscribeOrderUpdates delivers order updates of all orders to handler
+	SubscribeOrderUpdates(handler func(update *orspb.OrderUpdate)) error
+
+	// SendOrder sends a new order
+	SendOrder(ctx context.Context, req *orspb.OrderRequest) (*orspb.OrderResponse, error)
+
+	// CancelOrder sends a cancel request
+	CancelOrder(ctx context.Context, req *orspb.CancelRequest) (*orspb.CancelResponse, error)
+
+	// Close releases all connections and subscriptions
+	Close() error
+}
+
+// natsTransport NATS 行情/回报 + gRPC ORS Gateway 下单（原 StrategyEngine 内置链路）
+type natsTransport struct {
+	config    *EngineConfig
+	natsConn  *nats.Conn
+	orsClient *client.ORSClient
+
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription // subject -> subscription
+}
+
+func newNATSTransport(config *EngineConfig) *natsTransport {
+	return &natsTransport{
+		config: config,
+		subs:   make(map[string]*nats.Subscription),
+	}
+}
+
+func (t *natsTransport) Name() string { return "nats" }
+
+func (t *natsTransport) Connect() error {
+	var err error
+	t.natsConn, err = nats.Connect(t.config.NATSAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	log.Printf("[StrategyEngine] Connected to NATS: %s", t.config.NATSAddr)
+
+	t.orsClient, err = client.NewORSClient(client.ORSClientConfig{
+		GatewayAddr:       t.config.ORSGatewayAddr,
+		NATSAddr:          t.config.NATSAddr,
+		CounterBridgeAddr: t.config.CounterBridgeAddr, // 从配置读取Counter Bridge地址
+		StrategyID:        "strategy_engine",          // 使用通用ID
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize ORS client: %w", err)
+	}
+	log.Printf("[StrategyEngine] ORS Client initialized: %s", t.config.ORSGatewayAddr)
+	return nil
+}
+
+func (t *natsTransport) subscribe(subject string, cb nats.MsgHandler) error {
+	if t.natsConn == nil {
+		return fmt.Errorf("NATS not connected")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.subs[subject]; exists {
+		return nil // Already subscribed
+	}
+	sub, err := t.natsConn.Subscribe(subject, cb)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	t.subs[subject] = sub
+	return nil
+}
+
+// SubscribeMarketData subscribes to md.*.symbol to match MD Gateway's md.exchange.symbol format
+func (t *natsTransport) SubscribeMarketData(symbol string, handler func(md *mdpb.MarketDataUpdate)) error {
+	return t.subscribe(fmt.Sprintf("md.*.%s", symbol), func(msg *nats.Msg) {
+		var md mdpb.MarketDataUpdate
+		if err := proto.Unmarshal(msg.Data, &md); err != nil {
+			log.Printf("[StrategyEngine] Failed to unmarshal market data: %v", err)
+			return
+		}
+		handler(&md)
+	})
+}
+
+// SubscribeOrderUpdates subscribes to all strategy order updates (order.>)
+func (t *natsTransport) SubscribeOrderUpdates(handler func(update *orspb.OrderUpdate)) error {
+	return t.subscribe("order.>", func(msg *nats.Msg) {
+		var update orspb.OrderUpdate
+		if err := proto.Unmarshal(msg.Data, &update); err != nil {
+			log.Printf("[StrategyEngine] Failed to unmarshal order update: %v", err)
+			return
+		}
+		handler(&update)
+	})
+}
+
+func (t *natsTransport) SendOrder(ctx context.Context, req *orspb.OrderRequest) (*orspb.OrderResponse, error) {
+	if t.orsClient == nil {
+		return nil, fmt.Errorf("ORS client not connected")
+	}
+	return t.orsClient.SendOrder(ctx, req)
+}
+
+func (t *natsTransport) CancelOrder(ctx context.Context, req *orspb.CancelRequest) (*orspb.CancelResponse, error) {
+	if t.orsClient == nil {
+		return nil, fmt.Errorf("ORS client not connected")
+	}
+	return t.orsClient.CancelOrder(ctx, req)
+}
+
+func (t *natsTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for subject, sub := range t.subs {
+		sub.Unsubscribe()
+		log.Printf("[StrategyEngine] Unsubscribed: %s", subject)
+	}
+	t.subs = make(map[string]*nats.Subscription)
+	if t.natsConn != nil {
+		t.natsConn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,462 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+
+	commonpb "github.com/yourusername/quantlink-trade-system/pkg/proto/common"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	tbclient "tbsrc-golang/pkg/client"
+	"tbsrc-golang/pkg/connector"
+	"tbsrc-golang/pkg/shm"
+)
+
+// NativeSHM is implemented by transports that share their SysV SHM connector
+// with native tbsrc strategies (TbsrcPairwiseStrategy)
+// 原生策略直接收发 shm.MarketUpdateNew / RequestMsg / ResponseMsg，不经过 proto 转换
+type NativeSHM interface {
+	// Connector returns the shared connector (nil before Connect)
+	Connector() *connector.Connector
+
+	// AttachNative routes responses with the client's StrategyID to the client
+	// and delivers all market data to it
+	AttachNative(cli *tbclient.Client) error
+
+	// DetachNative stops routing to the client of strategyID
+	DetachNative(strategyID int32)
+}
+
+// SHMTransport StrategyEngine 的 SysV 共享内存链路（Go 扩展）
+//
+// 与 C++ TradeBot / tbsrc-golang 相同：行情读 MD 队列，下单写 Request 队列，
+// 回报读 Response 队列，进程内所有策略共享一个 clientID 和 OrderID 序列。
+// 原生 tbsrc 策略（AttachNative）直接处理 SHM 消息；其余策略的消息转换为 proto：
+//   - shm.MarketUpdateNew → mdpb.MarketDataUpdate
+//   - orspb.OrderRequest  → shm.RequestMsg（OrderID 由 Connector 分配）
+//   - shm.ResponseMsg     → orspb.OrderUpdate（按 OrderID 累计成交）
+type SHMTransport struct {
+	config  connector.Config
+	create  bool // 测试：创建新的 SHM 段
+	conn    *connector.Connector
+	natives *tbclient.Router
+
+	mu       sync.RWMutex
+	mdSubs   map[string][]func(*mdpb.MarketDataUpdate) // symbol -> handlers
+	orderSub func(*orspb.OrderUpdate)
+	orders   map[uint32]*shmOrder // OrderID -> 在途订单（proto 策略）
+}
+
+// shmOrder proto 策略的在途订单，用于把 ResponseMsg 还原为完整的 OrderUpdate
+type shmOrder struct {
+	req       *orspb.OrderRequest
+	msg       shm.RequestMsg // 撤单复用合约信息
+	status    orspb.OrderStatus
+	filledQty int64
+	avgPrice  float64
+}
+
+// NewSHMTransport creates a transport over existing SHM queues
+func NewSHMTransport(cfg connector.Config) *SHMTransport {
+	return &SHMTransport{
+		config:  cfg,
+		natives: tbclient.NewRouter(),
+		mdSubs:  make(map[string][]func(*mdpb.MarketDataUpdate)),
+		orders:  make(map[uint32]*shmOrder),
+	}
+}
+
+// newSHMTransportForTest creates a transport that creates its SHM segments
+func newSHMTransportForTest(cfg connector.Config) *SHMTransport {
+	t := NewSHMTransport(cfg)
+	t.create = true
+	return t
+}
+
+func (t *SHMTransport) Name() string { return "shm" }
+
+// Connect attaches the SHM queues, allocates a client ID and starts polling
+func (t *SHMTransport) Connect() error {
+	newConn := connector.New
+	if t.create {
+		newConn = connector.NewForTest
+	}
+	conn, err := newConn(t.config, t.onMD, t.onResponse)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.conn = conn
+	t.mu.Unlock()
+	conn.Start()
+	log.Printf("[SHMTransport] Connected: md=0x%x req=0x%x resp=0x%x clientID=%d",
+		t.config.MDShmKey, t.config.ReqShmKey, t.config.RespShmKey, conn.ClientID())
+	return nil
+}
+
+// Connector returns the shared connector
+func (t *SHMTransport) Connector() *connector.Connector {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.conn
+}
+
+// AttachNative registers a native tbsrc client on the shared connector
+func (t *SHMTransport) AttachNative(cli *tbclient.Client) error {
+	if !t.natives.Add(cli) {
+		return fmt.Errorf("strategy id %d already attached to SHM transport", cli.StrategyID())
+	}
+	return nil
+}
+
+// DetachNative removes a native tbsrc client
+func (t *SHMTransport) DetachNative(strategyID int32) {
+	t.natives.Remove(strategyID)
+}
+
+func (t *SHMTransport) SubscribeMarketData(symbol string, handler func(md *mdpb.MarketDataUpdate)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mdSubs[symbol] = append(t.mdSubs[symbol], handler)
+	return nil
+}
+
+func (t *SHMTransport) SubscribeOrderUpdates(handler func(update *orspb.OrderUpdate)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.orderSub = handler
+	return nil
+}
+
+// SendOrder enqueues a NEWORDER request; the returned OrderId is the SHM OrderID
+// SHM 队列没有同步应答，入队即视为成功，拒单通过回报（ORS_REJECT 等）送达
+func (t *SHMTransport) SendOrder(ctx context.Context, req *orspb.OrderRequest) (*orspb.OrderResponse, error) {
+	conn := t.Connector()
+	if conn == nil {
+		return nil, fmt.Errorf("SHM transport not connected")
+	}
+	msg := RequestMsgFromProto(req)
+
+	// 先登记再入队：回报可能在 SendNewOrder 返回前到达
+	t.mu.Lock()
+	orderID := conn.SendNewOrder(&msg)
+	t.orders[orderID] = &shmOrder{req: req, msg: msg, status: orspb.OrderStatus_SUBMITTED}
+	t.mu.Unlock()
+
+	return &orspb.OrderResponse{
+		OrderId:       strconv.FormatUint(uint64(orderID), 10),
+		ClientOrderId: req.ClientOrderId,
+		ErrorCode:     orspb.ErrorCode_SUCCESS,
+		Timestamp:     uint64(time.Now().UnixNano()),
+	}, nil
+}
+
+// CancelOrder enqueues a CANCELORDER request for an order sent through this transport
+func (t *SHMTransport) CancelOrder(ctx context.Context, req *orspb.CancelRequest) (*orspb.CancelResponse, error) {
+	conn := t.Connector()
+	if conn == nil {
+		return nil, fmt.Errorf("SHM transport not connected")
+	}
+	id, err := strconv.ParseUint(req.OrderId, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHM order id %q", req.OrderId)
+	}
+	t.mu.RLock()
+	o, ok := t.orders[uint32(id)]
+	var msg shm.RequestMsg
+	if ok {
+		msg = o.msg
+	}
+	t.mu.RUnlock()
+	if !ok {
+		return &orspb.CancelResponse{
+			OrderId:   req.OrderId,
+			ErrorCode: orspb.ErrorCode_ORDER_NOT_FOUND,
+			ErrorMsg:  "order not live on SHM transport",
+		}, nil
+	}
+
+	msg.OrderID = uint32(id)
+	conn.SendCancelOrder(&msg)
+	return &orspb.CancelResponse{
+		OrderId:   req.OrderId,
+		ErrorCode: orspb.ErrorCode_SUCCESS,
+		Timestamp: uint64(time.Now().UnixNano()),
+	}, nil
+}
+
+// Close stops polling and detaches the SHM segments
+func (t *SHMTransport) Close() error {
+	conn := t.Connector()
+	if conn == nil {
+		return nil
+	}
+	if t.create {
+		return conn.Destroy()
+	}
+	return conn.Close()
+}
+
+// onMD Connector MD 回调：原生策略收原始消息，proto 订阅者收转换后的行情
+func (t *SHMTransport) onMD(md *shm.MarketUpdateNew) {
+	t.natives.OnMDUpdate(md)
+
+	symbol := cString(md.Header.Symbol[:])
+	t.mu.RLock()
+	handlers := t.mdSubs[symbol]
+	t.mu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+	update := MarketDataFromSHM(md)
+	for _, h := range handlers {
+		h(update)
+	}
+}
+
+// onResponse Connector 回报回调：原生策略按 StrategyID 路由，其余按 OrderID 转换为 OrderUpdate
+func (t *SHMTransport) onResponse(resp *shm.ResponseMsg) {
+	if t.natives.OnORSUpdate(resp) {
+		return
+	}
+
+	t.mu.Lock()
+	o, ok := t.orders[resp.OrderID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	update := applyResponse(o, resp)
+	if update != nil && isTerminal(update.Status) {
+		delete(t.orders, resp.OrderID)
+	}
+	handler := t.orderSub
+	t.mu.Unlock()
+
+	if update != nil && handler != nil {
+		handler(update)
+	}
+}
+
+// applyResponse 按回报更新订单状态并生成 OrderUpdate；不改变订单状态的回报返回 nil
+// C++: ORSCallBack 中的 Response_Type 分支
+func applyResponse(o *shmOrder, resp *shm.ResponseMsg) *orspb.OrderUpdate {
+	var lastQty int64
+	var lastPx float64
+	errCode, errMsg := orspb.ErrorCode_SUCCESS, ""
+
+	switch resp.Response_Type {
+	case shm.NEW_ORDER_CONFIRM:
+		o.status = orspb.OrderStatus_ACCEPTED
+	case shm.TRADE_CONFIRM:
+		lastQty, lastPx = int64(resp.Quantity), resp.Price
+		if total := o.filledQty + lastQty; total > 0 {
+			o.avgPrice = (o.avgPrice*float64(o.filledQty) + lastPx*float64(lastQty)) / float64(total)
+		}
+		o.filledQty += lastQty
+		o.status = orspb.OrderStatus_PARTIALLY_FILLED
+		if o.filledQty >= o.req.Quantity {
+			o.status = orspb.OrderStatus_FILLED
+		}
+	case shm.CANCEL_ORDER_CONFIRM:
+		o.status = orspb.OrderStatus_CANCELED
+	case shm.ORDER_EXPIRED:
+		o.status = orspb.OrderStatus_EXPIRED
+	case shm.RMS_REJECT:
+		o.status = orspb.OrderStatus_REJECTED
+		errCode, errMsg = orspb.ErrorCode_RISK_CHECK_FAILED, fmt.Sprintf("RMS_REJECT errorCode=%d", resp.ErrorCode)
+	case shm.ORDER_ERROR, shm.ORS_REJECT, shm.SIM_REJECT, shm.BUSINESS_REJECT, shm.ORDERS_PER_DAY_LIMIT_REJECT:
+		o.status = orspb.OrderStatus_REJECTED
+		errCode, errMsg = orspb.ErrorCode_COUNTER_ERROR, fmt.Sprintf("response type %d errorCode=%d", resp.Response_Type, resp.ErrorCode)
+	case shm.CANCEL_ORDER_REJECT:
+		errCode, errMsg = orspb.ErrorCode_COUNTER_ERROR, fmt.Sprintf("cancel rejected errorCode=%d", resp.ErrorCode)
+	default:
+		// MODIFY_*、*_PENDING、警告、NULL_RESPONSE：不改变订单状态
+		return nil
+	}
+
+	return &orspb.OrderUpdate{
+		OrderId:           strconv.FormatUint(uint64(resp.OrderID), 10),
+		ClientOrderId:     o.req.ClientOrderId,
+		StrategyId:        o.req.StrategyId,
+		Symbol:            o.req.Symbol,
+		Exchange:          o.req.Exchange,
+		Side:              o.req.Side,
+		Status:            o.status,
+		Price:             o.req.Price,
+		Quantity:          o.req.Quantity,
+		FilledQty:         o.filledQty,
+		RemainingQty:      max(o.req.Quantity-o.filledQty, 0),
+		AvgPrice:          o.avgPrice,
+		LastFillPrice:     lastPx,
+		LastFillQty:       lastQty,
+		ExecId:            cString(resp.ExchangeTradeId[:]),
+		ExchangeTimestamp: resp.TimeStamp,
+		Timestamp:         uint64(time.Now().UnixNano()),
+		ErrorCode:         errCode,
+		ErrorMsg:          errMsg,
+	}
+}
+
+func isTerminal(s orspb.OrderStatus) bool {
+	switch s {
+	case orspb.OrderStatus_FILLED, orspb.OrderStatus_CANCELED, orspb.OrderStatus_REJECTED, orspb.OrderStatus_EXPIRED:
+		return true
+	}
+	return false
+}
+
+// MarketDataFromSHM converts an hftbase market update to the proto format
+// 只转换有效档位（ValidBids / ValidAsks）
+func MarketDataFromSHM(md *shm.MarketUpdateNew) *mdpb.MarketDataUpdate {
+	d := &md.Data
+	nb := clampLevels(d.ValidBids)
+	na := clampLevels(d.ValidAsks)
+	out := &mdpb.MarketDataUpdate{
+		Symbol:            cString(md.Header.Symbol[:]),
+		Exchange:          exchangeNameFromSHM(md.Header.ExchangeName),
+		Timestamp:         md.Header.Timestamp,
+		ExchangeTimestamp: md.Header.ExchTS,
+		FeedType:          mdpb.FeedType_CONTINUOUS,
+		BidPrice:          make([]float64, nb),
+		BidQty:            make([]uint32, nb),
+		BidOrderCount:     make([]uint32, nb),
+		AskPrice:          make([]float64, na),
+		AskQty:            make([]uint32, na),
+		AskOrderCount:     make([]uint32, na),
+		LastPrice:         d.LastTradedPrice,
+		LastQty:           uint32(max(d.LastTradedQuantity, 0)),
+		TotalVolume:       uint64(max(d.TotalTradedQuantity, 0)),
+		Turnover:          d.TotalTradedValue,
+	}
+	for i := 0; i < nb; i++ {
+		b := d.BidUpdates[i]
+		out.BidPrice[i], out.BidQty[i], out.BidOrderCount[i] = b.Price, uint32(max(b.Quantity, 0)), uint32(max(b.OrderCount, 0))
+	}
+	for i := 0; i < na; i++ {
+		a := d.AskUpdates[i]
+		out.AskPrice[i], out.AskQty[i], out.AskOrderCount[i] = a.Price, uint32(max(a.Quantity, 0)), uint32(max(a.OrderCount, 0))
+	}
+	return out
+}
+
+// RequestMsgFromProto converts a proto order to an hftbase RequestMsg
+// OrderID / Request_Type 由 Connector.SendNewOrder 填写
+// C++: CommonClient::SendNewOrder + FillReqInfo（LIMIT / PERUNIT）
+func RequestMsgFromProto(req *orspb.OrderRequest) shm.RequestMsg {
+	var msg shm.RequestMsg
+	copyCString(msg.ContractDesc.Symbol[:], req.Symbol)
+	copyCString(msg.AccountID[:], req.Account)
+	copyCString(msg.Product[:], productOf(req.Symbol))
+	msg.StrategyID = shmStrategyID(req.StrategyId)
+
+	msg.TransactionType = shm.SideBuy
+	if req.Side == orspb.OrderSide_SELL {
+		msg.TransactionType = shm.SideSell
+	}
+	msg.Price = req.Price
+	msg.Quantity = int32(req.Quantity)
+	msg.DisclosedQnty = int32(req.Quantity)
+
+	msg.OrdType = shm.LIMIT
+	if req.OrderType == orspb.OrderType_MARKET {
+		msg.OrdType = shm.MARKET
+	}
+	msg.PxType = shm.PERUNIT
+	switch req.TimeInForce {
+	case orspb.TimeInForce_IOC:
+		msg.Duration = shm.FAK // C++: CROSS 单使用 FAK
+	case orspb.TimeInForce_FOK:
+		msg.Duration = shm.FOK
+	default:
+		msg.Duration = shm.DAY
+	}
+	switch req.OpenClose {
+	case orspb.OpenClose_OPEN:
+		msg.PosDirection = shm.POS_OPEN
+	case orspb.OpenClose_CLOSE, orspb.OpenClose_CLOSE_YESTERDAY:
+		msg.PosDirection = shm.POS_CLOSE
+	case orspb.OpenClose_CLOSE_TODAY:
+		msg.PosDirection = shm.POS_CLOSE_INTRADAY
+	}
+	msg.ExchangeType = exchangeCodeOf(req.Exchange)
+	msg.TimeStamp = uint64(time.Now().UnixNano())
+	return msg
+}
+
+// shmStrategyID proto 策略的字符串 ID → RequestMsg.StrategyID
+// 纯数字 ID 原样使用（与 C++ 策略 ID 一致），否则取哈希
+func shmStrategyID(id string) int32 {
+	if n, err := strconv.ParseInt(id, 10, 32); err == nil {
+		return int32(n)
+	}
+	return int32(hashStringToInt(id) & 0x7fffffff)
+}
+
+// productOf 合约代码的品种部分（ag2603 → ag）
+func productOf(symbol string) string {
+	for i, r := range symbol {
+		if unicode.IsDigit(r) {
+			return symbol[:i]
+		}
+	}
+	return symbol
+}
+
+func exchangeCodeOf(e commonpb.Exchange) uint8 {
+	switch e {
+	case commonpb.Exchange_SHFE, commonpb.Exchange_INE:
+		return shm.ChinaSHFE
+	case commonpb.Exchange_DCE:
+		return shm.ChinaDCE
+	case commonpb.Exchange_CZCE:
+		return shm.ChinaZCE
+	case commonpb.Exchange_CFFEX:
+		return shm.ChinaCFFEX
+	case commonpb.Exchange_GFEX:
+		return shm.ChinaGFEX
+	}
+	return shm.ExchangeUnknown
+}
+
+func exchangeNameFromSHM(code uint8) string {
+	switch code {
+	case shm.ChinaSHFE:
+		return "SHFE"
+	case shm.ChinaDCE:
+		return "DCE"
+	case shm.ChinaZCE:
+		return "CZCE"
+	case shm.ChinaCFFEX:
+		return "CFFEX"
+	case shm.ChinaGFEX:
+		return "GFEX"
+	}
+	return ""
+}
+
+func clampLevels(n int8) int {
+	return min(max(int(n), 0), shm.InterestLevels)
+}
+
+// cString 以 0 结尾的定长字节数组 → string
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+// copyCString string → 以 0 结尾的定长字节数组（超长截断）
+func copyCString(dst []byte, src string) {
+	n := copy(dst[:len(dst)-1], src)
+	dst[n] = 0
+}
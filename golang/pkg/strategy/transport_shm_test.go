@@ -0,0 +1,288 @@
+package strategy
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	commonpb "github.com/yourusername/quantlink-trade-system/pkg/proto/common"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+
+	"tbsrc-golang/pkg/connector"
+	"tbsrc-golang/pkg/shm"
+)
+
+func testSHMConfig(base int) connector.Config {
+	return connector.Config{
+		MDShmKey:          base + 1,
+		MDQueueSz:         64,
+		ReqShmKey:         base + 2,
+		ReqQueueSz:        64,
+		RespShmKey:        base + 3,
+		RespQueueSz:       64,
+		ClientStoreShmKey: base + 4,
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timeout waiting for condition")
+}
+
+func TestRequestMsgFromProto(t *testing.T) {
+	msg := RequestMsgFromProto(&orspb.OrderRequest{
+		StrategyId:  "92201",
+		Symbol:      "ag2603",
+		Exchange:    commonpb.Exchange_SHFE,
+		Side:        orspb.OrderSide_SELL,
+		OrderType:   orspb.OrderType_LIMIT,
+		TimeInForce: orspb.TimeInForce_IOC,
+		Price:       7450,
+		Quantity:    3,
+		OpenClose:   orspb.OpenClose_CLOSE_TODAY,
+		Account:     "PRP05",
+	})
+
+	if got := cString(msg.ContractDesc.Symbol[:]); got != "ag2603" {
+		t.Errorf("Symbol = %q", got)
+	}
+	if got := cString(msg.Product[:]); got != "ag" {
+		t.Errorf("Product = %q, want ag", got)
+	}
+	if got := cString(msg.AccountID[:]); got != "PRP05" {
+		t.Errorf("AccountID = %q", got)
+	}
+	if msg.StrategyID != 92201 {
+		t.Errorf("StrategyID = %d, want 92201", msg.StrategyID)
+	}
+	if msg.TransactionType != shm.SideSell || msg.Price != 7450 || msg.Quantity != 3 {
+		t.Errorf("side/price/qty = %c/%v/%d", msg.TransactionType, msg.Price, msg.Quantity)
+	}
+	if msg.Duration != shm.FAK || msg.OrdType != shm.LIMIT {
+		t.Errorf("Duration/OrdType = %v/%v, want FAK/LIMIT", msg.Duration, msg.OrdType)
+	}
+	if msg.PosDirection != shm.POS_CLOSE_INTRADAY {
+		t.Errorf("PosDirection = %v, want POS_CLOSE_INTRADAY", msg.PosDirection)
+	}
+	if msg.ExchangeType != shm.ChinaSHFE {
+		t.Errorf("ExchangeType = %d, want %d", msg.ExchangeType, shm.ChinaSHFE)
+	}
+
+	// 非数字策略 ID 取哈希，保持非负
+	if id := RequestMsgFromProto(&orspb.OrderRequest{StrategyId: "live_ag_spread"}).StrategyID; id <= 0 {
+		t.Errorf("hashed StrategyID = %d, want > 0", id)
+	}
+}
+
+func TestMarketDataFromSHM(t *testing.T) {
+	var md shm.MarketUpdateNew
+	copy(md.Header.Symbol[:], "ag2603")
+	md.Header.ExchangeName = shm.ChinaSHFE
+	md.Header.ExchTS = 123
+	md.Data.LastTradedPrice = 7451
+	md.Data.TotalTradedQuantity = 1000
+	md.Data.ValidBids = 2
+	md.Data.ValidAsks = 1
+	md.Data.BidUpdates[0] = shm.BookElement{Quantity: 5, OrderCount: 2, Price: 7450}
+	md.Data.BidUpdates[1] = shm.BookElement{Quantity: 7, OrderCount: 3, Price: 7449}
+	md.Data.AskUpdates[0] = shm.BookElement{Quantity: 4, OrderCount: 1, Price: 7452}
+
+	out := MarketDataFromSHM(&md)
+	if out.Symbol != "ag2603" || out.Exchange != "SHFE" || out.ExchangeTimestamp != 123 {
+		t.Errorf("header = %s/%s/%d", out.Symbol, out.Exchange, out.ExchangeTimestamp)
+	}
+	if len(out.BidPrice) != 2 || len(out.AskPrice) != 1 {
+		t.Fatalf("levels = %d/%d, want 2/1", len(out.BidPrice), len(out.AskPrice))
+	}
+	if out.BidPrice[1] != 7449 || out.BidQty[1] != 7 || out.AskOrderCount[0] != 1 {
+		t.Errorf("book = %v %v %v", out.BidPrice, out.BidQty, out.AskOrderCount)
+	}
+	if out.LastPrice != 7451 || out.TotalVolume != 1000 {
+		t.Errorf("last/volume = %v/%d", out.LastPrice, out.TotalVolume)
+	}
+}
+
+func TestSHMTransport_OrderLifecycle(t *testing.T) {
+	tr := newSHMTransportForTest(testSHMConfig(0xE40A00))
+	if err := tr.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	var mu sync.Mutex
+	var mds []*mdpb.MarketDataUpdate
+	var updates []*orspb.OrderUpdate
+	tr.SubscribeMarketData("ag2603", func(md *mdpb.MarketDataUpdate) {
+		mu.Lock()
+		mds = append(mds, md)
+		mu.Unlock()
+	})
+	tr.SubscribeOrderUpdates(func(u *orspb.OrderUpdate) {
+		mu.Lock()
+		updates = append(updates, u)
+		mu.Unlock()
+	})
+
+	// 行情：只投递已订阅品种
+	var md shm.MarketUpdateNew
+	copy(md.Header.Symbol[:], "ag2605")
+	tr.Connector().EnqueueMD(&md)
+	copy(md.Header.Symbol[:], "ag2603")
+	md.Data.LastTradedPrice = 7450
+	tr.Connector().EnqueueMD(&md)
+	waitFor(t, func() bool { mu.Lock(); defer mu.Unlock(); return len(mds) > 0 })
+	mu.Lock()
+	if len(mds) != 1 || mds[0].Symbol != "ag2603" || mds[0].LastPrice != 7450 {
+		t.Errorf("market data = %+v", mds)
+	}
+	mu.Unlock()
+
+	resp, err := tr.SendOrder(context.Background(), &orspb.OrderRequest{
+		StrategyId: "s1", Symbol: "ag2603", Exchange: commonpb.Exchange_SHFE,
+		Side: orspb.OrderSide_BUY, Price: 7450, Quantity: 3, ClientOrderId: "c1",
+	})
+	if err != nil || resp.ErrorCode != orspb.ErrorCode_SUCCESS {
+		t.Fatalf("SendOrder: %v %v", resp, err)
+	}
+	id, _ := strconv.ParseUint(resp.OrderId, 10, 32)
+	orderID := uint32(id)
+
+	conn := tr.Connector()
+	conn.EnqueueResponse(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM, OrderID: orderID})
+	conn.EnqueueResponse(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: orderID, Quantity: 1, Price: 7450})
+	conn.EnqueueResponse(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: orderID, Quantity: 2, Price: 7447})
+	waitFor(t, func() bool { mu.Lock(); defer mu.Unlock(); return len(updates) == 3 })
+
+	mu.Lock()
+	want := []orspb.OrderStatus{orspb.OrderStatus_ACCEPTED, orspb.OrderStatus_PARTIALLY_FILLED, orspb.OrderStatus_FILLED}
+	for i, u := range updates {
+		if u.Status != want[i] {
+			t.Errorf("update %d status = %v, want %v", i, u.Status, want[i])
+		}
+		if u.ClientOrderId != "c1" || u.OrderId != resp.OrderId {
+			t.Errorf("update %d ids = %s/%s", i, u.ClientOrderId, u.OrderId)
+		}
+	}
+	last := updates[2]
+	if last.FilledQty != 3 || last.RemainingQty != 0 || last.AvgPrice != 7448 || last.LastFillQty != 2 {
+		t.Errorf("fill = filled %d remaining %d avg %v last %d", last.FilledQty, last.RemainingQty, last.AvgPrice, last.LastFillQty)
+	}
+	mu.Unlock()
+
+	// 终态订单已移出订单表
+	cr, err := tr.CancelOrder(context.Background(), &orspb.CancelRequest{OrderId: resp.OrderId})
+	if err != nil || cr.ErrorCode != orspb.ErrorCode_ORDER_NOT_FOUND {
+		t.Errorf("CancelOrder after fill = %v %v, want ORDER_NOT_FOUND", cr, err)
+	}
+}
+
+func TestSHMTransport_NativeStrategy(t *testing.T) {
+	tr := newSHMTransportForTest(testSHMConfig(0xE40B00))
+	engine := NewStrategyEngine(&EngineConfig{})
+	engine.SetTransport(tr)
+	if err := engine.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer tr.Close()
+
+	sm := NewStrategyManager(engine)
+	err := sm.AddStrategy(config.StrategyItemConfig{
+		ID:        "ag_tbsrc",
+		Type:      "tbsrc_pairwise",
+		Enabled:   true,
+		Symbols:   []string{"ag2603", "ag2605"},
+		Exchanges: []string{"SHFE", "SHFE"},
+		Parameters: map[string]interface{}{
+			"strategy_id": 92201,
+			"account":     "PRP05",
+			"daily_init":  filepath.Join(t.TempDir(), "daily_init.92201"),
+			"instruments": map[string]interface{}{
+				"ag2603": map[string]interface{}{"tick_size": 1.0, "lot_size": 1.0, "price_multiplier": 15.0},
+				"ag2605": map[string]interface{}{"tick_size": 1.0, "lot_size": 1.0, "price_multiplier": 15.0},
+			},
+			"thresholds": map[string]interface{}{
+				"first": map[string]interface{}{"begin_place": 2.0, "max_size": 4.0},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddStrategy: %v", err)
+	}
+
+	s, _ := sm.GetStrategy("ag_tbsrc")
+	native := s.(*TbsrcPairwiseStrategy)
+	if native.Native() == nil || native.Native().Inst1.Exchange != "SHFE" {
+		t.Fatal("native strategy not built")
+	}
+	if got := s.GetCurrentParameters()["begin_place"]; got != 2.0 {
+		t.Errorf("begin_place = %v, want 2", got)
+	}
+	if err := s.UpdateParameters(map[string]interface{}{"begin_place": 2.5}); err != nil {
+		t.Fatalf("UpdateParameters: %v", err)
+	}
+	if native.Native().Thold1.BeginPlace != 2.5 {
+		t.Errorf("BeginPlace = %v, want 2.5", native.Native().Thold1.BeginPlace)
+	}
+
+	// 对账持仓取两腿 ExecutionState 净仓
+	native.Native().Leg1.State.Netpos, native.Native().Leg2.State.Netpos = 3, -2
+	if got := native.GetPositionsBySymbol(); got["ag2603"] != 3 || got["ag2605"] != -2 {
+		t.Errorf("positions by symbol = %v, want ag2603:3 ag2605:-2", got)
+	}
+
+	// 同一 StrategyID 只能挂接一次
+	dup := config.StrategyItemConfig{ID: "ag_tbsrc_dup", Type: "tbsrc_pairwise", Symbols: []string{"ag2603", "ag2605"},
+		Parameters: map[string]interface{}{
+			"strategy_id": 92201,
+			"daily_init":  filepath.Join(t.TempDir(), "daily_init.92201"),
+			"instruments": map[string]interface{}{
+				"ag2603": map[string]interface{}{"tick_size": 1.0},
+				"ag2605": map[string]interface{}{"tick_size": 1.0},
+			},
+		}}
+	if err := sm.AddStrategy(dup); err == nil {
+		t.Error("duplicate strategy_id should fail")
+	}
+
+	// 原生策略的回报不转换为 proto OrderUpdate
+	var got []*orspb.OrderUpdate
+	var mu sync.Mutex
+	tr.SubscribeOrderUpdates(func(u *orspb.OrderUpdate) { mu.Lock(); got = append(got, u); mu.Unlock() })
+	tr.Connector().EnqueueResponse(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM,
+		OrderID: tr.Connector().ClientID()*connector.OrderIDRange + 999, StrategyID: 92201})
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	if len(got) != 0 {
+		t.Errorf("native response leaked to proto handler: %v", got)
+	}
+	mu.Unlock()
+
+	if err := sm.RemoveStrategy("ag_tbsrc"); err != nil {
+		t.Fatalf("RemoveStrategy: %v", err)
+	}
+	if tr.natives.Has(92201) {
+		t.Error("strategy still attached after RemoveStrategy")
+	}
+}
+
+func TestTbsrcPairwise_RequiresSHMTransport(t *testing.T) {
+	sm := NewStrategyManager(NewStrategyEngine(&EngineConfig{}))
+	err := sm.AddStrategy(config.StrategyItemConfig{
+		ID: "ag_tbsrc", Type: "tbsrc_pairwise", Symbols: []string{"ag2603", "ag2605"},
+	})
+	if err == nil {
+		t.Fatal("tbsrc_pairwise without SHM transport should fail")
+	}
+}
@@ -2,6 +2,7 @@
 package strategy
 
 import (
+	"strconv"
 	"time"
 
 	commonpb "github.com/yourusername/quantlink-trade-system/pkg/proto/common"
@@ -128,6 +129,16 @@ type TradingSignal struct {
 	QuoteLevel int            // 挂单层级：0=一档, 1=二档, ...
 }
 
+// 归因用的信号元数据键（Go 扩展）：ToOrderRequest 把它们带到 OrderRequest.Metadata，
+// 回测归因按成交订单读取发出信号时的策略状态，与 tbsrc 归因读取 avgSpread / 阈值档位一致
+const (
+	MetaFairSpread     = "fair_spread"     // 发出信号时的公允价差（价差均值，含 tValue 调整）
+	MetaEntryThreshold = "entry_threshold" // 触发开仓的 z-score 阈值（动态阈值的当前值）
+)
+
+// signalMetaKeys 透传到 OrderRequest.Metadata 的信号元数据
+var signalMetaKeys = []string{MetaFairSpread, MetaEntryThreshold}
+
 // ToOrderRequest converts TradingSignal to OrderRequest protobuf
 func (ts *TradingSignal) ToOrderRequest() *orspb.OrderRequest {
 	req := &orspb.OrderRequest{
@@ -194,6 +205,18 @@ func (ts *TradingSignal) ToOrderRequest() *orspb.OrderRequest {
 		req.Metadata["order_category"] = "aggressive"
 	}
 
+	// 信号状态（归因用）
+	for _, k := range signalMetaKeys {
+		v, ok := ts.Metadata[k].(float64)
+		if !ok {
+			continue
+		}
+		if req.Metadata == nil {
+			req.Metadata = make(map[string]string)
+		}
+		req.Metadata[k] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
 	return req
 }
 
@@ -306,7 +329,8 @@ type PNL struct {
 type RiskMetrics struct {
 	PositionSize    int64     // Current position size
 	MaxPositionSize int64     // Maximum allowed position
-	ExposureValue   float64   // Position value
+	ExposureValue   float64   // Position value（价差策略为各腿净货值，见 spread.Definition.NetExposure）
+	GrossExposure   float64   // 价差策略各腿货值绝对值之和（单腿策略为 0）
 	MaxExposure     float64   // Maximum allowed exposure
 	VaR             float64   // Value at Risk
 	SharpeRatio     float64   // Sharpe ratio
@@ -0,0 +1,77 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/account"
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// accountsFile 账户持仓快照文件
+func accountsFile() string {
+	return filepath.Join(strategy.GetDataDir(), "accounts.json")
+}
+
+func accountLimits(c config.AccountLimitsConfig) account.Limits {
+	return account.Limits{MaxOrderQty: c.MaxOrderQty, MaxPosition: c.MaxPosition, MaxDailyLoss: c.MaxDailyLoss}
+}
+
+// initAccounts 创建多账户管理器并接入引擎下单路由（Go 扩展）
+// 未配置 accounts.list 时不启用，订单按原单账户方式发送
+func (t *Trader) initAccounts() error {
+	cfg := &t.Config.Accounts
+	if len(cfg.List) == 0 {
+		return nil
+	}
+
+	mgr := account.NewManager(accountLimits(cfg.Global))
+	mgr.SetMultiplier(strategy.GetContractMultiplier)
+	for _, a := range cfg.List {
+		if err := mgr.AddAccount(a.ID, accountLimits(a.AccountLimitsConfig)); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range t.Config.GetEnabledStrategies() {
+		if len(s.Accounts) == 0 {
+			continue
+		}
+		allocs := make([]account.Allocation, len(s.Accounts))
+		for i, a := range s.Accounts {
+			allocs[i] = account.Allocation{Account: a.Account, Scale: a.Scale}
+		}
+		allocs[0].Scale = 1
+		if err := mgr.Assign(s.ID, allocs); err != nil {
+			return err
+		}
+		log.Printf("[Trader] Strategy %s accounts: %v", s.ID, allocs)
+	}
+
+	n, err := mgr.Load(accountsFile(), t.currentTradingDay())
+	if err != nil {
+		return fmt.Errorf("load account positions: %w", err)
+	}
+
+	// 分配了账户的策略由 Fanout 按各账户台账规划开平
+	if planner := t.Engine.OffsetPlanner(); planner != nil {
+		mgr.SetOffsetPlanner(planner)
+	}
+
+	t.Accounts = mgr
+	t.Engine.SetAccountRouter(mgr)
+	log.Printf("[Trader] ✓ Accounts initialized: %v (restored %d positions)", mgr.Accounts(), n)
+	return nil
+}
+
+// saveAccounts 保存账户持仓快照
+func (t *Trader) saveAccounts(tradingDay string) {
+	if t.Accounts == nil {
+		return
+	}
+	if err := t.Accounts.Save(accountsFile(), tradingDay); err != nil {
+		log.Printf("[Trader] Warning: Failed to save account positions: %v", err)
+	}
+}
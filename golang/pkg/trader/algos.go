@@ -0,0 +1,186 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/algo"
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// AlgoManager 管理通过 API 下达的执行算法母单（Go 扩展）
+// 每个母单作为 exec_algo 策略加入 StrategyEngine；结束且无在途子单后移出引擎，只保留进度
+type AlgoManager struct {
+	engine *strategy.StrategyEngine
+	cfg    config.AlgosConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	active   map[string]*strategy.ExecAlgoStrategy
+	finished map[string]algo.Progress
+	seq      int
+}
+
+func newAlgoManager(engine *strategy.StrategyEngine, cfg config.AlgosConfig) *AlgoManager {
+	if cfg.CurveDir == "" {
+		cfg.CurveDir = filepath.Join(strategy.GetDataDir(), "volume_curves")
+	}
+	if cfg.MaxActive <= 0 {
+		cfg.MaxActive = 20
+	}
+	return &AlgoManager{
+		engine:   engine,
+		cfg:      cfg,
+		now:      time.Now,
+		active:   make(map[string]*strategy.ExecAlgoStrategy),
+		finished: make(map[string]algo.Progress),
+	}
+}
+
+// initAlgos 创建执行算法管理器（algos.enabled）
+func (t *Trader) initAlgos() {
+	if !t.Config.Algos.Enabled || t.Engine == nil {
+		return
+	}
+	t.Algos = newAlgoManager(t.Engine, t.Config.Algos)
+	log.Printf("[Trader] ✓ Execution algos enabled (curves: %s, max_active: %d)", t.Algos.cfg.CurveDir, t.Algos.cfg.MaxActive)
+}
+
+// Submit 下达母单；ID 为空时自动生成。VWAP 从 curve_dir 加载成交量曲线，缺失时退化为时间均匀
+func (m *AlgoManager) Submit(spec algo.Spec) (algo.Progress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reapLocked()
+
+	now := m.now()
+	if spec.ID == "" {
+		m.seq++
+		spec.ID = fmt.Sprintf("algo_%s_%d", now.Format("150405"), m.seq)
+	}
+	if _, ok := m.active[spec.ID]; ok {
+		return algo.Progress{}, fmt.Errorf("algo %s already exists", spec.ID)
+	}
+	if _, ok := m.finished[spec.ID]; ok {
+		return algo.Progress{}, fmt.Errorf("algo %s already exists", spec.ID)
+	}
+	if _, ok := m.engine.GetStrategy(spec.ID); ok {
+		return algo.Progress{}, fmt.Errorf("id %s is used by a strategy", spec.ID)
+	}
+	if len(m.active) >= m.cfg.MaxActive {
+		return algo.Progress{}, fmt.Errorf("too many active algos (max %d)", m.cfg.MaxActive)
+	}
+	if spec.PassiveWaitMs == 0 {
+		spec.PassiveWaitMs = m.cfg.PassiveWaitMs
+	}
+
+	var curve *algo.VolumeCurve
+	if spec.Type == algo.TypeVWAP {
+		path := algo.CurvePath(m.cfg.CurveDir, spec.Symbol)
+		c, err := algo.LoadCurve(path)
+		switch {
+		case err == nil:
+			curve = c
+		case os.IsNotExist(err):
+			log.Printf("[Algo] ⚠️  No volume curve for %s (%s), VWAP falls back to linear schedule", spec.Symbol, path)
+		default:
+			return algo.Progress{}, err
+		}
+	}
+
+	a, err := algo.New(spec, curve, now)
+	if err != nil {
+		return algo.Progress{}, err
+	}
+	s := strategy.NewExecAlgoStrategy(a)
+	if err := m.engine.AddStrategy(s); err != nil {
+		return algo.Progress{}, err
+	}
+	if err := m.engine.SubscribeMarketData(spec.Symbol); err != nil {
+		log.Printf("[Algo] ⚠️  Subscribe %s for %s: %v", spec.Symbol, spec.ID, err)
+	}
+	if err := s.Start(); err != nil {
+		m.engine.RemoveStrategy(spec.ID)
+		return algo.Progress{}, err
+	}
+	m.active[spec.ID] = s
+	log.Printf("[Algo] Submitted %s: %s %s %d %s (%s ~ %s)", spec.ID, a.Spec().Type, spec.Side, spec.Qty, spec.Symbol,
+		a.Spec().Start.Format("15:04:05"), formatAlgoEnd(a.Spec().End))
+	return s.Progress(), nil
+}
+
+// Cancel 撤销母单
+func (m *AlgoManager) Cancel(id string) (algo.Progress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.finished[id]; ok {
+		return p, fmt.Errorf("algo %s already %s", id, p.State)
+	}
+	s, ok := m.active[id]
+	if !ok {
+		return algo.Progress{}, fmt.Errorf("algo %s not found", id)
+	}
+	s.Cancel()
+	log.Printf("[Algo] Canceled %s", id)
+	return s.Progress(), nil
+}
+
+// Get 查询母单进度
+func (m *AlgoManager) Get(id string) (algo.Progress, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.active[id]; ok {
+		return s.Progress(), true
+	}
+	p, ok := m.finished[id]
+	return p, ok
+}
+
+// List 全部母单进度（按开始时间）
+func (m *AlgoManager) List() []algo.Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reapLocked()
+	out := make([]algo.Progress, 0, len(m.active)+len(m.finished))
+	for _, s := range m.active {
+		out = append(out, s.Progress())
+	}
+	for _, p := range m.finished {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Start.Equal(out[j].Start) {
+			return out[i].Start.Before(out[j].Start)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// reapLocked 已结束且无在途子单的母单移出引擎
+func (m *AlgoManager) reapLocked() {
+	for id, s := range m.active {
+		if !s.Done() {
+			continue
+		}
+		p := s.Progress()
+		if err := m.engine.RemoveStrategy(id); err != nil {
+			log.Printf("[Algo] Remove %s from engine: %v", id, err)
+		}
+		m.finished[id] = p
+		delete(m.active, id)
+		log.Printf("[Algo] %s %s: filled %d/%d avg %.4f slippage %.2fbps", id, p.State, p.Filled, p.Qty, p.AvgPrice, p.SlippageBps)
+	}
+}
+
+func formatAlgoEnd(t time.Time) string {
+	if t.IsZero() {
+		return "open"
+	}
+	return t.Format("15:04:05")
+}
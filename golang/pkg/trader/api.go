@@ -13,6 +13,8 @@ import (
 
 	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
 	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+
+	"tbsrc-golang/pkg/auth"
 )
 
 // APIServer provides HTTP REST API for trader control
@@ -24,6 +26,11 @@ type APIServer struct {
 	mu        sync.RWMutex
 	commandMu sync.Mutex // 命令互斥锁，防止并发激活/停止
 	running   bool
+
+	mux   *http.ServeMux
+	port  int
+	guard *auth.Guard    // 认证/授权/审计（ConfigureAuth 设置，nil = 不认证）
+	audit *auth.AuditLog // 控制请求审计日志
 }
 
 // APIResponse is the standard API response format
@@ -79,6 +86,7 @@ func (a *APIServer) loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 func NewAPIServer(trader *Trader, port int) *APIServer {
 	api := &APIServer{
 		trader:  trader,
+		port:    port,
 		running: false,
 	}
 
@@ -101,6 +109,18 @@ func NewAPIServer(trader *Trader, port int) *APIServer {
 	// Position query endpoints
 	mux.HandleFunc("/api/v1/positions", api.corsMiddleware(api.handlePositions))
 	mux.HandleFunc("/api/v1/positions/summary", api.corsMiddleware(api.handlePositionsSummary))
+	mux.HandleFunc("/api/v1/reconcile", api.corsMiddleware(api.handleReconcile))
+
+	// Audit log (控制请求审计)
+	mux.HandleFunc("/api/v1/audit", api.corsMiddleware(api.handleAudit))
+
+	// Staged parameter changes (参数变更预览/审批/生效/回滚)
+	mux.HandleFunc("/api/v1/params", api.corsMiddleware(api.handleParams))
+	mux.HandleFunc("/api/v1/params/", api.corsMiddleware(api.handleParamsRoute))
+
+	// Execution algos (母单下达/进度/撤销)
+	mux.HandleFunc("/api/v1/algos", api.corsMiddleware(api.handleAlgos))
+	mux.HandleFunc("/api/v1/algos/", api.corsMiddleware(api.handleAlgoByID))
 
 	// Multi-strategy management endpoints (P2-12.2)
 	mux.HandleFunc("/api/v1/dashboard/overview", api.corsMiddleware(api.handleDashboardOverview))
@@ -119,8 +139,9 @@ func NewAPIServer(trader *Trader, port int) *APIServer {
 		http.ServeFile(w, r, "golang/web/overview.html")
 	})
 
+	api.mux = mux
 	api.server = &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
+		Addr:         auth.ListenAddr("", false, port), // ConfigureAuth 启用认证后才监听 api.host
 		Handler:      mux,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
@@ -147,7 +168,13 @@ func (a *APIServer) Start() error {
 	log.Printf("[API] WebSocket endpoint: ws://%s/api/v1/ws/dashboard", a.server.Addr)
 
 	go func() {
-		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if a.server.TLSConfig != nil {
+			err = a.server.ListenAndServeTLS("", "")
+		} else {
+			err = a.server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Printf("[API] Error starting server: %v", err)
 		}
 	}()
@@ -174,6 +201,9 @@ func (a *APIServer) Stop() error {
 	if err := a.server.Close(); err != nil {
 		return fmt.Errorf("failed to stop server: %w", err)
 	}
+	if a.audit != nil {
+		a.audit.Close()
+	}
 
 	log.Println("[API] ✓ HTTP API server stopped")
 	return nil
@@ -335,14 +365,10 @@ func (a *APIServer) handleTestMarketData(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// corsMiddleware adds CORS headers to allow browser access from file://
+// corsMiddleware 处理 OPTIONS 预检请求
+// CORS 头由 guard 按 allowed_origins 设置，未配置白名单时不允许跨域
 func (a *APIServer) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// 允许所有来源（开发环境）
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 		// 处理 OPTIONS 预检请求
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -402,6 +428,7 @@ func (a *APIServer) handleModelHistory(w http.ResponseWriter, r *http.Request) {
 
 // handlePositions handles GET /api/v1/positions
 // 返回所有持仓（按交易所分组）
+// ?group_by=account 或 ?account=<id> 时返回多账户持仓（按账户分组，含账户盈亏与限额）
 func (a *APIServer) handlePositions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -412,6 +439,11 @@ func (a *APIServer) handlePositions(w http.ResponseWriter, r *http.Request) {
 	exchange := r.URL.Query().Get("exchange") // 例如: ?exchange=SHFE
 	symbol := r.URL.Query().Get("symbol")     // 例如: ?symbol=ag2603
 
+	if acct := r.URL.Query().Get("account"); acct != "" || r.URL.Query().Get("group_by") == "account" {
+		a.handleAccountPositions(w, acct, symbol)
+		return
+	}
+
 	// 获取持仓数据
 	a.trader.positionsMu.RLock()
 	positions := a.trader.positionsByExchange
@@ -453,6 +485,38 @@ func (a *APIServer) handlePositions(w http.ResponseWriter, r *http.Request) {
 	a.sendSuccess(w, "Positions retrieved", filtered)
 }
 
+// handleAccountPositions 按账户返回持仓（账户 → 快照）
+func (a *APIServer) handleAccountPositions(w http.ResponseWriter, account, symbol string) {
+	mgr := a.trader.Accounts
+	if mgr == nil {
+		a.sendError(w, http.StatusBadRequest, "Multi-account is not configured (accounts.list)")
+		return
+	}
+
+	result := make(map[string]interface{})
+	for _, snap := range mgr.Snapshot() {
+		if account != "" && snap.Account != account {
+			continue
+		}
+		if symbol != "" {
+			kept := snap.Positions[:0]
+			for _, p := range snap.Positions {
+				if p.Symbol == symbol {
+					kept = append(kept, p)
+				}
+			}
+			snap.Positions = kept
+		}
+		result[snap.Account] = snap
+	}
+	if account != "" && len(result) == 0 {
+		a.sendError(w, http.StatusNotFound, fmt.Sprintf("Account %s not found", account))
+		return
+	}
+
+	a.sendSuccess(w, "Account positions retrieved", result)
+}
+
 // handlePositionsSummary handles GET /api/v1/positions/summary
 // 返回持仓摘要统计
 func (a *APIServer) handlePositionsSummary(w http.ResponseWriter, r *http.Request) {
@@ -508,9 +572,61 @@ func (a *APIServer) handlePositionsSummary(w http.ResponseWriter, r *http.Reques
 		"by_exchange":      exchangeStats,
 	}
 
+	// 多账户：各账户净持仓与当日盈亏
+	if a.trader.Accounts != nil {
+		accountStats := make(map[string]map[string]interface{})
+		for _, snap := range a.trader.Accounts.Snapshot() {
+			var netVolume int64
+			for _, p := range snap.Positions {
+				if p.NetQty < 0 {
+					netVolume -= p.NetQty
+				} else {
+					netVolume += p.NetQty
+				}
+			}
+			accountStats[snap.Account] = map[string]interface{}{
+				"position_count": len(snap.Positions),
+				"total_volume":   netVolume,
+				"realized_pnl":   snap.RealizedPnL,
+				"unrealized_pnl": snap.UnrealizedPnL,
+				"daily_pnl":      snap.DailyPnL,
+				"loss_limit_hit": snap.LossLimitHit,
+			}
+		}
+		summary["by_account"] = accountStats
+	}
+
 	a.sendSuccess(w, "Position summary retrieved", summary)
 }
 
+// handleReconcile handles GET/POST /api/v1/reconcile
+// GET 返回最近一次对账报告，POST 立即执行一次三方对账
+func (a *APIServer) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rep := a.trader.LastReconcileReport()
+		if rep == nil {
+			a.sendError(w, http.StatusNotFound, "No reconciliation report available")
+			return
+		}
+		a.sendSuccess(w, "Last reconciliation report", rep)
+	case http.MethodPost:
+		rep, err := a.trader.Reconcile()
+		if rep == nil {
+			a.sendError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if err != nil {
+			// 有差异仍返回报告
+			a.sendSuccess(w, err.Error(), rep)
+			return
+		}
+		a.sendSuccess(w, "Reconciliation passed", rep)
+	default:
+		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
 // ==================== Multi-Strategy API Endpoints (P2-12.2) ====================
 
 // DashboardOverview represents the dashboard overview response
@@ -665,6 +781,7 @@ func (a *APIServer) handleStrategies(w http.ResponseWriter, r *http.Request) {
 //   - POST /api/v1/strategies/{id}/model/reload - Hot reload model
 //   - GET /api/v1/strategies/{id}/model/status - Get model status
 //   - GET /api/v1/strategies/{id}/model/history - Get model reload history
+//   - GET /api/v1/strategies/{id}/basket - Get basket exposure (basket_arb)
 func (a *APIServer) handleStrategyByID(w http.ResponseWriter, r *http.Request) {
 	// Parse strategy ID and action from URL path
 	// Expected paths:
@@ -674,6 +791,7 @@ func (a *APIServer) handleStrategyByID(w http.ResponseWriter, r *http.Request) {
 	//   /api/v1/strategies/{id}/model/reload
 	//   /api/v1/strategies/{id}/model/status
 	//   /api/v1/strategies/{id}/model/history
+	//   /api/v1/strategies/{id}/basket
 	path := r.URL.Path
 	prefix := "/api/v1/strategies/"
 	if len(path) <= len(prefix) {
@@ -706,6 +824,9 @@ func (a *APIServer) handleStrategyByID(w http.ResponseWriter, r *http.Request) {
 	case r.Method == http.MethodGet && len(parts) == 3 && parts[1] == "model" && parts[2] == "history":
 		// GET /api/v1/strategies/{id}/model/history
 		a.handleStrategyModelHistory(w, r, strategyID)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[1] == "basket":
+		// GET /api/v1/strategies/{id}/basket
+		a.handleStrategyBasket(w, r, strategyID)
 	default:
 		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed or invalid action")
 	}
@@ -801,6 +922,20 @@ func (a *APIServer) handleStrategyModelReload(w http.ResponseWriter, r *http.Req
 	}
 
 	mgr := a.trader.GetStrategyManager()
+	if a.trader.Params != nil {
+		c, err := a.trader.ReloadStrategyModelVersioned(strategyID, auth.IdentityFrom(r).Name)
+		if err != nil {
+			log.Printf("[API] Failed to reload model for strategy %s: %v", strategyID, err)
+			a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Failed to reload model: %v", err))
+			return
+		}
+		a.sendSuccess(w, "Model reloaded successfully", map[string]interface{}{
+			"strategy_id": strategyID,
+			"timestamp":   time.Now().Format(time.RFC3339),
+			"change":      c,
+		})
+		return
+	}
 	if err := mgr.ReloadStrategyModel(strategyID); err != nil {
 		log.Printf("[API] Failed to reload model for strategy %s: %v", strategyID, err)
 		a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Failed to reload model: %v", err))
@@ -838,12 +973,27 @@ func (a *APIServer) handleStrategyModelHistory(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// TODO: 实现model重载历史追踪
-	// 目前返回空历史
+	// 参数工作流的版本记录即重载历史
+	history := []interface{}{}
+	if a.trader.Params != nil {
+		for _, v := range a.trader.Params.Versions() {
+			if p, ok := v.Params[strategyID]; ok {
+				history = append(history, map[string]interface{}{
+					"version":    v.ID,
+					"time":       v.Time,
+					"change_id":  v.ChangeID,
+					"kind":       v.Kind,
+					"author":     v.Author,
+					"new_params": p,
+					"old_params": v.Previous[strategyID],
+				})
+			}
+		}
+	}
 	a.sendSuccess(w, "Model reload history retrieved", map[string]interface{}{
 		"strategy_id": strategyID,
-		"history":     []interface{}{},
-		"count":       0,
+		"history":     history,
+		"count":       len(history),
 	})
 }
 
@@ -0,0 +1,75 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/algo"
+)
+
+// handleAlgos handles /api/v1/algos
+//   - GET  /api/v1/algos  全部母单进度
+//   - POST /api/v1/algos  下达母单（body 为 algo.Spec，start/end 为 RFC3339）
+func (a *APIServer) handleAlgos(w http.ResponseWriter, r *http.Request) {
+	m := a.trader.Algos
+	if m == nil {
+		a.sendError(w, http.StatusServiceUnavailable, "Execution algos not enabled")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		a.sendSuccess(w, "Algos retrieved", m.List())
+	case http.MethodPost:
+		var spec algo.Spec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		p, err := m.Submit(spec)
+		if err != nil {
+			a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Submit failed: %v", err))
+			return
+		}
+		a.sendSuccess(w, fmt.Sprintf("Algo %s submitted", p.ID), p)
+	default:
+		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAlgoByID handles /api/v1/algos/{id}
+//   - GET  /api/v1/algos/{id}         母单进度
+//   - POST /api/v1/algos/{id}/cancel  撤销母单
+func (a *APIServer) handleAlgoByID(w http.ResponseWriter, r *http.Request) {
+	m := a.trader.Algos
+	if m == nil {
+		a.sendError(w, http.StatusServiceUnavailable, "Execution algos not enabled")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/algos/"), "/")
+	id := parts[0]
+	if id == "" {
+		a.sendError(w, http.StatusBadRequest, "Algo ID required")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 1:
+		p, ok := m.Get(id)
+		if !ok {
+			a.sendError(w, http.StatusNotFound, fmt.Sprintf("Algo %s not found", id))
+			return
+		}
+		a.sendSuccess(w, "Algo retrieved", p)
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[1] == "cancel":
+		p, err := m.Cancel(id)
+		if err != nil {
+			a.sendError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		a.sendSuccess(w, fmt.Sprintf("Algo %s canceled", id), p)
+	default:
+		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed or invalid action")
+	}
+}
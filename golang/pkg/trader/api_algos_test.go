@@ -0,0 +1,92 @@
+package trader
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/algo"
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	mdpb "github.com/yourusername/quantlink-trade-system/pkg/proto/md"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+
+	"tbsrc-golang/pkg/auth"
+)
+
+func TestAPIAlgosSubmitProgressCancel(t *testing.T) {
+	trader := setupTestTraderWithMultiStrategy(t)
+	trader.Engine = strategy.NewStrategyEngine(&strategy.EngineConfig{})
+	trader.Algos = newAlgoManager(trader.Engine, config.AlgosConfig{CurveDir: t.TempDir(), MaxActive: 1})
+	api := NewAPIServer(trader, 9999)
+
+	call := func(method, path string, body interface{}) (int, json.RawMessage) {
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp.Data
+	}
+
+	// VWAP 无曲线文件：退化为时间均匀
+	code, data := call(http.MethodPost, "/api/v1/algos", algo.Spec{
+		ID: "vwap_1", Symbol: "ag2502", Side: algo.Buy, Qty: 20, Type: algo.TypeVWAP,
+		End: time.Now().Add(time.Hour),
+	})
+	var p algo.Progress
+	json.Unmarshal(data, &p)
+	if code != http.StatusOK || p.ID != "vwap_1" || p.State != algo.StatePending || p.Curve != "linear" {
+		t.Fatalf("submit = %d %s", code, data)
+	}
+	s, ok := trader.Engine.GetStrategy("vwap_1")
+	if !ok || s.GetType() != strategy.ExecAlgoStrategyType || !s.IsRunning() {
+		t.Fatalf("algo strategy not running in engine")
+	}
+
+	// 行情驱动后开始执行
+	s.OnMarketData(&mdpb.MarketDataUpdate{Symbol: "ag2502", BidPrice: []float64{5000}, AskPrice: []float64{5001}})
+	if sigs := s.GetSignals(); len(sigs) != 1 || sigs[0].Price != 5000 {
+		t.Fatalf("signals = %+v", sigs)
+	}
+	code, data = call(http.MethodGet, "/api/v1/algos/vwap_1", nil)
+	json.Unmarshal(data, &p)
+	if code != http.StatusOK || p.State != algo.StateWorking || p.Working == 0 || p.ArrivalPrice != 5000.5 {
+		t.Fatalf("get = %d %s", code, data)
+	}
+
+	if code, _ = call(http.MethodPost, "/api/v1/algos", algo.Spec{ID: "pov_1", Symbol: "ag2502", Side: algo.Sell, Qty: 5, Type: algo.TypePOV, Participation: 0.1}); code != http.StatusBadRequest {
+		t.Errorf("max_active not enforced: %d", code)
+	}
+	if code, _ = call(http.MethodPost, "/api/v1/algos/nope/cancel", nil); code != http.StatusNotFound {
+		t.Errorf("cancel unknown = %d", code)
+	}
+
+	code, data = call(http.MethodPost, "/api/v1/algos/vwap_1/cancel", nil)
+	json.Unmarshal(data, &p)
+	if code != http.StatusOK || p.State != algo.StateCanceled {
+		t.Fatalf("cancel = %d %s", code, data)
+	}
+	code, data = call(http.MethodGet, "/api/v1/algos", nil)
+	var list []algo.Progress
+	json.Unmarshal(data, &list)
+	if code != http.StatusOK || len(list) != 1 || list[0].ID != "vwap_1" {
+		t.Errorf("list = %d %s", code, data)
+	}
+
+	for path, want := range map[string]auth.Permission{
+		"/api/v1/algos":               auth.PermAlgoSubmit,
+		"/api/v1/algos/vwap_1/cancel": auth.PermAlgoCancel,
+	} {
+		if got := routePermission(httptest.NewRequest(http.MethodPost, path, nil)); got != want {
+			t.Errorf("permission(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
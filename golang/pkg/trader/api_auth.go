@@ -0,0 +1,194 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+
+	"tbsrc-golang/pkg/auth"
+)
+
+// ConfigureAuth 按配置启用控制 API 的认证、授权与审计日志（Go 扩展），须在 Start 之前调用
+// 认证关闭时只监听 127.0.0.1（忽略 api.host），所有本机请求按 admin 处理，控制请求仍写入审计日志
+func (a *APIServer) ConfigureAuth(cfg *config.APIAuthConfig) error {
+	users := make([]auth.User, 0, len(cfg.Users))
+	for _, u := range cfg.Users {
+		role, err := auth.ParseRole(u.Role)
+		if err != nil {
+			return fmt.Errorf("api.auth.users[%s]: %w", u.Name, err)
+		}
+		hash := u.TokenSHA256
+		if u.TokenEnv != "" {
+			if hash, err = auth.TokenFromEnv(u.TokenEnv); err != nil {
+				return fmt.Errorf("api.auth.users[%s]: %w", u.Name, err)
+			}
+		}
+		users = append(users, auth.User{Name: u.Name, Role: role, TokenSHA256: hash, CertCN: u.CertCN})
+	}
+
+	overrides := make(map[auth.Permission][]auth.Role, len(cfg.Permissions))
+	for perm, names := range cfg.Permissions {
+		for _, n := range names {
+			role, err := auth.ParseRole(n)
+			if err != nil {
+				return fmt.Errorf("api.auth.permissions[%s]: %w", perm, err)
+			}
+			overrides[auth.Permission(perm)] = append(overrides[auth.Permission(perm)], role)
+		}
+	}
+
+	authn, err := auth.NewAuthenticator(cfg.Enabled, users, overrides)
+	if err != nil {
+		return fmt.Errorf("api.auth: %w", err)
+	}
+
+	auditPath := cfg.AuditFile
+	if auditPath == "" {
+		auditPath = filepath.Join(strategy.GetDataDir(), "audit", "api_audit.jsonl")
+	}
+	audit, err := auth.OpenAuditLog(auditPath)
+	if err != nil {
+		return err
+	}
+	if n, err := audit.Verify(); err != nil {
+		log.Printf("[API] ⚠️  Audit log %s failed verification after %d entries: %v", auditPath, n, err)
+	}
+
+	if cfg.TLS.CertFile != "" {
+		tlsCfg, err := auth.ServerTLSConfig(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, cfg.TLS.RequireClientCert)
+		if err != nil {
+			audit.Close()
+			return err
+		}
+		a.server.TLSConfig = tlsCfg
+	}
+
+	a.audit = audit
+	a.guard = &auth.Guard{
+		Auth:           authn,
+		Audit:          audit,
+		AllowedOrigins: cfg.AllowedOrigins,
+		Public:         map[string]bool{"/api/v1/health": true},
+		Resolve:        routePermission,
+	}
+	a.server.Handler = a.guard.Wrap(a.mux)
+	host := ""
+	if a.trader != nil && a.trader.Config != nil {
+		host = a.trader.Config.API.Host
+	}
+	a.server.Addr = auth.ListenAddr(host, cfg.Enabled, a.port)
+
+	if cfg.Enabled {
+		log.Printf("[API] ✓ Authentication enabled (%d users, tls=%v, mtls=%v), audit log: %s",
+			len(users), a.server.TLSConfig != nil, cfg.TLS.ClientCAFile != "", auditPath)
+	} else {
+		log.Printf("[API] ⚠️  Authentication disabled: listening on %s only (audit log: %s)", a.server.Addr, auditPath)
+	}
+	return nil
+}
+
+// routePermission 端点 → 所需权限
+func routePermission(r *http.Request) auth.Permission {
+	path := r.URL.Path
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		if path == "/api/v1/audit" {
+			return auth.PermAuditRead
+		}
+		return auth.PermRead
+	}
+
+	switch path {
+	case "/api/v1/model/reload":
+		return auth.PermModelReload
+	case "/api/v1/reconcile":
+		return auth.PermReconcile
+	}
+	if rest, ok := strings.CutPrefix(path, "/api/v1/params/"); ok {
+		switch {
+		case rest == "rollback":
+			return auth.PermParamsRollback
+		case strings.HasSuffix(rest, "/approve"):
+			return auth.PermParamsApprove
+		case strings.HasPrefix(rest, "changes"):
+			return auth.PermModelReload
+		}
+	}
+	if path == "/api/v1/algos" {
+		return auth.PermAlgoSubmit
+	}
+	if rest, ok := strings.CutPrefix(path, "/api/v1/algos/"); ok && strings.HasSuffix(rest, "/cancel") {
+		return auth.PermAlgoCancel
+	}
+	if rest, ok := strings.CutPrefix(path, "/api/v1/strategies/"); ok {
+		parts := strings.Split(rest, "/")
+		switch {
+		case len(parts) == 2 && parts[1] == "activate":
+			return auth.PermActivate
+		case len(parts) == 2 && parts[1] == "deactivate":
+			return auth.PermDeactivate
+		case len(parts) == 3 && parts[1] == "model" && parts[2] == "reload":
+			return auth.PermModelReload
+		}
+	}
+	return auth.PermAdmin
+}
+
+// handleAudit handles GET /api/v1/audit
+// 查询参数: user, action, since/until (RFC3339), limit（默认 100），verify=true 时附带哈希链校验结果
+func (a *APIServer) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if a.audit == nil {
+		a.sendError(w, http.StatusServiceUnavailable, "Audit log not configured")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := auth.AuditFilter{User: q.Get("user"), Action: auth.Permission(q.Get("action")), Limit: 100}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				a.sendError(w, http.StatusBadRequest, fmt.Sprintf("%s must be RFC3339", name))
+				return
+			}
+			*dst = t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			a.sendError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, err := a.audit.Query(filter)
+	if err != nil {
+		a.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read audit log: %v", err))
+		return
+	}
+	data := map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	}
+	if q.Get("verify") == "true" {
+		n, err := a.audit.Verify()
+		data["verified_entries"] = n
+		data["chain_ok"] = err == nil
+		if err != nil {
+			data["chain_error"] = err.Error()
+		}
+	}
+	a.sendSuccess(w, "Audit log retrieved", data)
+}
@@ -0,0 +1,30 @@
+package trader
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// handleStrategyBasket handles GET /api/v1/strategies/{id}/basket
+// 篮子套利策略的协整向量、残差 z-score 与篮子层面敞口（各腿持仓、货值）
+func (a *APIServer) handleStrategyBasket(w http.ResponseWriter, r *http.Request, strategyID string) {
+	if a.trader.GetStrategyManager() == nil {
+		a.sendError(w, http.StatusInternalServerError, "Strategy manager not initialized")
+		return
+	}
+
+	s, ok := a.trader.GetStrategyManager().GetStrategy(strategyID)
+	if !ok {
+		a.sendError(w, http.StatusNotFound, fmt.Sprintf("Strategy not found: %s", strategyID))
+		return
+	}
+	basket, ok := s.(*strategy.BasketArbStrategy)
+	if !ok {
+		a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Strategy %s is not a %s strategy", strategyID, strategy.BasketArbStrategyType))
+		return
+	}
+
+	a.sendSuccess(w, "Basket retrieved", basket.Basket())
+}
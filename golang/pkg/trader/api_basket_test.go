@@ -0,0 +1,52 @@
+package trader
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+func TestAPIStrategyBasket(t *testing.T) {
+	trader := setupTestTraderWithMultiStrategy(t)
+	err := trader.StrategyMgr.LoadStrategies([]config.StrategyItemConfig{{
+		ID:      "test_basket",
+		Type:    strategy.BasketArbStrategyType,
+		Enabled: true,
+		Symbols: []string{"ag2603", "ag2605", "ag2612"},
+		Parameters: map[string]interface{}{
+			"lookback": 30.0,
+		},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to load basket strategy: %v", err)
+	}
+	api := NewAPIServer(trader, 9999)
+
+	get := func(path string) (int, json.RawMessage) {
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp.Data
+	}
+
+	code, data := get("/api/v1/strategies/test_basket/basket")
+	var snap strategy.BasketSnapshot
+	json.Unmarshal(data, &snap)
+	if code != http.StatusOK || snap.StrategyID != "test_basket" || snap.Ready || len(snap.Legs) != 3 || snap.Legs[2].Symbol != "ag2612" {
+		t.Fatalf("basket = %d %s", code, data)
+	}
+
+	if code, _ = get("/api/v1/strategies/test_passive/basket"); code != http.StatusBadRequest {
+		t.Errorf("non-basket strategy status = %d, want 400", code)
+	}
+	if code, _ = get("/api/v1/strategies/nonexistent/basket"); code != http.StatusNotFound {
+		t.Errorf("missing strategy status = %d, want 404", code)
+	}
+}
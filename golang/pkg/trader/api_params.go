@@ -0,0 +1,184 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tbsrc-golang/pkg/auth"
+	"tbsrc-golang/pkg/paramset"
+)
+
+// StageParamsRequest 提交参数变更请求
+// params 作用于 strategies（空 = 全部策略），per_strategy 按策略覆盖；
+// model_file 非空时从 model 文件读取参数（与 params 互斥）
+type StageParamsRequest struct {
+	Strategies  []string                          `json:"strategies"`
+	Params      map[string]interface{}            `json:"params"`
+	PerStrategy map[string]map[string]interface{} `json:"per_strategy"`
+	ModelFile   string                            `json:"model_file"`
+	Comment     string                            `json:"comment"`
+}
+
+// ApplyParamsRequest 生效请求：apply_at = now（默认）/ session_start / session_end / RFC3339
+type ApplyParamsRequest struct {
+	ApplyAt string `json:"apply_at"`
+}
+
+// RollbackParamsRequest 回滚请求
+type RollbackParamsRequest struct {
+	Version int    `json:"version"`
+	Comment string `json:"comment"`
+}
+
+// handleParams handles GET /api/v1/params
+// 返回最近的变更、全部版本，以及当前生效值与最新版本的差异（drift）
+func (a *APIServer) handleParams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	wf := a.trader.Params
+	if wf == nil {
+		a.sendError(w, http.StatusServiceUnavailable, "Parameter workflow not initialized")
+		return
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			limit = n
+		}
+	}
+	a.sendSuccess(w, "Parameter workflow state retrieved", map[string]interface{}{
+		"require_approval": a.trader.Config.Params.RequireApproval,
+		"changes":          wf.Changes(limit),
+		"versions":         wf.Versions(),
+		"drift":            wf.Drift(),
+	})
+}
+
+// handleParamsRoute handles /api/v1/params/...
+//   - POST /api/v1/params/changes              提交变更（返回 diff、校验与风险提示）
+//   - GET  /api/v1/params/changes/{id}         查询变更
+//   - POST /api/v1/params/changes/{id}/approve 审批
+//   - POST /api/v1/params/changes/{id}/apply   生效（可定时）
+//   - POST /api/v1/params/changes/{id}/cancel  取消
+//   - GET  /api/v1/params/versions             版本列表
+//   - POST /api/v1/params/rollback             回滚到指定版本
+func (a *APIServer) handleParamsRoute(w http.ResponseWriter, r *http.Request) {
+	wf := a.trader.Params
+	if wf == nil {
+		a.sendError(w, http.StatusServiceUnavailable, "Parameter workflow not initialized")
+		return
+	}
+	user := auth.IdentityFrom(r).Name
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/params/"), "/")
+
+	switch {
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "changes":
+		a.handleStageParams(w, r, user)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "changes":
+		c, err := wf.Change(parts[1])
+		if err != nil {
+			a.sendError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		a.sendSuccess(w, "Change retrieved", c)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "changes":
+		a.handleParamsAction(w, r, parts[1], parts[2], user)
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "versions":
+		a.sendSuccess(w, "Versions retrieved", wf.Versions())
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "rollback":
+		var req RollbackParamsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		c, err := wf.Rollback(req.Version, user, req.Comment)
+		if err != nil {
+			a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Rollback failed: %v", err))
+			return
+		}
+		a.sendSuccess(w, fmt.Sprintf("Rolled back to version %d", req.Version), c)
+	default:
+		a.sendError(w, http.StatusMethodNotAllowed, "Method not allowed or invalid action")
+	}
+}
+
+func (a *APIServer) handleStageParams(w http.ResponseWriter, r *http.Request, user string) {
+	var req StageParamsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	var c *paramset.Change
+	var err error
+	if req.ModelFile != "" {
+		if len(req.Params) > 0 || len(req.PerStrategy) > 0 {
+			a.sendError(w, http.StatusBadRequest, "model_file cannot be combined with params")
+			return
+		}
+		c, err = a.trader.StageModelFile(req.ModelFile, req.Strategies, user, req.Comment)
+	} else {
+		c, err = a.trader.Params.Stage(paramset.StageRequest{
+			Strategies:  req.Strategies,
+			Params:      req.Params,
+			PerStrategy: req.PerStrategy,
+			Author:      user,
+			Comment:     req.Comment,
+			Source:      "api",
+		})
+	}
+	if err != nil {
+		a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Failed to stage change: %v", err))
+		return
+	}
+	a.sendSuccess(w, fmt.Sprintf("Change %s staged", c.ID), c)
+}
+
+func (a *APIServer) handleParamsAction(w http.ResponseWriter, r *http.Request, id, action, user string) {
+	wf := a.trader.Params
+	var c *paramset.Change
+	var err error
+	switch action {
+	case "approve":
+		c, err = wf.Approve(id, user)
+	case "cancel":
+		c, err = wf.Cancel(id, user)
+	case "apply":
+		var req ApplyParamsRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				a.sendError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+				return
+			}
+		}
+		at, err := a.trader.ResolveApplyAt(req.ApplyAt)
+		if err != nil {
+			a.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		c, err = wf.Apply(id, user, at)
+		if err != nil {
+			status := http.StatusBadRequest
+			if c != nil && c.Status == paramset.StatusFailed {
+				status = http.StatusConflict
+			}
+			a.sendError(w, status, fmt.Sprintf("Failed to apply change %s: %v", id, err))
+			return
+		}
+		a.sendSuccess(w, fmt.Sprintf("Change %s %s", id, c.Status), c)
+		return
+	default:
+		a.sendError(w, http.StatusNotFound, fmt.Sprintf("Unknown action: %s", action))
+		return
+	}
+	if err != nil {
+		a.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.sendSuccess(w, fmt.Sprintf("Change %s %s", id, c.Status), c)
+}
@@ -0,0 +1,67 @@
+package trader
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tbsrc-golang/pkg/paramset"
+)
+
+func TestAPIParamsStageApplyRollback(t *testing.T) {
+	trader := setupTestTraderWithMultiStrategy(t)
+	trader.Config.Params.Dir = t.TempDir()
+	if err := trader.initParams(); err != nil {
+		t.Fatal(err)
+	}
+	api := NewAPIServer(trader, 9999)
+	strat, _ := trader.StrategyMgr.GetStrategy("test_pairwise")
+	orig := strat.GetCurrentParameters()["entry_zscore"]
+
+	post := func(path string, body interface{}) (*httptest.ResponseRecorder, *paramset.Change) {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		api.handleParamsRoute(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+		var resp struct {
+			Data paramset.Change `json:"data"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w, &resp.Data
+	}
+
+	w, c := post("/api/v1/params/changes", StageParamsRequest{
+		Strategies: []string{"test_pairwise"},
+		Params:     map[string]interface{}{"entry_zscore": 3.5},
+	})
+	if w.Code != http.StatusOK || len(c.Diffs["test_pairwise"]) != 1 {
+		t.Fatalf("stage = %d, %+v", w.Code, c)
+	}
+	if got := strat.GetCurrentParameters()["entry_zscore"]; got != orig {
+		t.Fatalf("staging must not change live value: %v", got)
+	}
+
+	if w, c = post("/api/v1/params/changes/"+c.ID+"/apply", ApplyParamsRequest{}); w.Code != http.StatusOK || c.Version != 1 {
+		t.Fatalf("apply = %d, %+v", w.Code, c)
+	}
+	if got := strat.GetCurrentParameters()["entry_zscore"]; got != 3.5 {
+		t.Fatalf("entry_zscore after apply = %v", got)
+	}
+
+	if w, _ = post("/api/v1/params/rollback", RollbackParamsRequest{Version: 0}); w.Code != http.StatusOK {
+		t.Fatalf("rollback = %d", w.Code)
+	}
+	if got := strat.GetCurrentParameters()["entry_zscore"]; got != orig {
+		t.Errorf("entry_zscore after rollback = %v, want %v", got, orig)
+	}
+
+	// 未知参数：校验失败，不能生效
+	_, bad := post("/api/v1/params/changes", StageParamsRequest{
+		Strategies: []string{"test_pairwise"},
+		Params:     map[string]interface{}{"no_such_param": 1},
+	})
+	if w, _ = post("/api/v1/params/changes/"+bad.ID+"/apply", nil); w.Code == http.StatusOK {
+		t.Error("change with validation errors should not apply")
+	}
+}
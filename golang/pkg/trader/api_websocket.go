@@ -25,6 +25,24 @@ type DashboardWSUpdate struct {
 	MarketData map[string]*MarketDataDetail     `json:"market_data"`
 	Positions  []*PositionDetail                `json:"positions"`
 	Orders     []*OrderDetail                   `json:"orders"`
+	Resizes    []*ResizeEventDetail             `json:"resizes"` // 组合分配引起的下单量调整（最近在前）
+}
+
+// ResizeEventDetail contains one allocation-driven strategy resize (Go 扩展)
+type ResizeEventDetail struct {
+	Time         string             `json:"time"`
+	StrategyID   string             `json:"strategy_id"`
+	Mode         string             `json:"mode"`       // proportional / capital
+	Allocation   float64            `json:"allocation"` // 0-1
+	Capital      float64            `json:"capital"`
+	MarginPerLot float64            `json:"margin_per_lot"`
+	OldMaxPos    int64              `json:"old_max_position"`
+	NewMaxPos    int64              `json:"new_max_position"`
+	TargetMaxPos int64              `json:"target_max_position"`
+	Params       map[string]float64 `json:"params"`
+	Pending      bool               `json:"pending"` // 仍在逐步缩小
+	Flatten      bool               `json:"flatten"`
+	Error        string             `json:"error,omitempty"`
 }
 
 // StrategyRealtimeData contains real-time strategy data including thresholds
@@ -72,7 +90,7 @@ type PositionDetail struct {
 	AvgPrice      float64 `json:"avg_price"`      // Average open price
 	CurrentPrice  float64 `json:"current_price"`  // Current market price
 	UnrealizedPnL float64 `json:"unrealized_pnl"` // Unrealized P&L
-	LegIndex      int     `json:"leg_index"`      // For pairwise strategies: 1 or 2, 0 for single-leg
+	LegIndex      int     `json:"leg_index"`      // For pairwise/basket strategies: 1-based leg, 0 for single-leg
 }
 
 // OrderDetail contains detailed order information for dashboard
@@ -145,6 +163,34 @@ func (h *WebSocketHub) convertMarketData(symbol string, md *mdpb.MarketDataUpdat
 	return detail
 }
 
+// collectResizes returns the latest allocation resize events, newest first
+func (h *WebSocketHub) collectResizes(limit int) []*ResizeEventDetail {
+	details := make([]*ResizeEventDetail, 0)
+	if h.trader.Portfolio == nil {
+		return details
+	}
+	events := h.trader.Portfolio.GetResizeEvents()
+	for i := len(events) - 1; i >= 0 && len(details) < limit; i-- {
+		ev := events[i]
+		details = append(details, &ResizeEventDetail{
+			Time:         ev.Time.Format("15:04:05"),
+			StrategyID:   ev.StrategyID,
+			Mode:         ev.Mode,
+			Allocation:   ev.Allocation,
+			Capital:      ev.Capital,
+			MarginPerLot: ev.MarginPerLot,
+			OldMaxPos:    ev.OldMaxPos,
+			NewMaxPos:    ev.NewMaxPos,
+			TargetMaxPos: ev.TargetMaxPos,
+			Params:       ev.Params,
+			Pending:      ev.Pending,
+			Flatten:      ev.Flatten,
+			Error:        ev.Error,
+		})
+	}
+	return details
+}
+
 // Start starts the WebSocket hub
 func (h *WebSocketHub) Start() {
 	h.mu.Lock()
@@ -308,6 +354,9 @@ func (h *WebSocketHub) collectDashboardData() *DashboardWSUpdate {
 	// Collect orders
 	update.Orders = h.collectOrders()
 
+	// Collect resize events
+	update.Resizes = h.collectResizes(20)
+
 	return update
 }
 
@@ -532,6 +581,8 @@ func (h *WebSocketHub) collectPositions() []*PositionDetail {
 			// Handle PairwiseArbStrategy (has two legs)
 			if stratType == "pairwise_arb" {
 				positions = append(positions, h.collectPairwisePositions(id, strat)...)
+			} else if basket, ok := strat.(*strategy.BasketArbStrategy); ok {
+				positions = append(positions, h.collectBasketPositions(id, basket)...)
 			} else {
 				// Handle single-leg strategies
 				positions = append(positions, h.collectSingleLegPositions(id, strat)...)
@@ -663,6 +714,45 @@ func (h *WebSocketHub) collectPairwisePositions(strategyID string, strat strateg
 	return positions
 }
 
+// collectBasketPositions collects per-leg positions for basket stat-arb strategy
+func (h *WebSocketHub) collectBasketPositions(strategyID string, basket *strategy.BasketArbStrategy) []*PositionDetail {
+	positions := make([]*PositionDetail, 0)
+
+	exchanges := []string{"SHFE"}
+	if config := basket.GetConfig(); config != nil && len(config.Exchanges) > 0 {
+		exchanges = config.Exchanges
+	}
+
+	for i, leg := range basket.Basket().Legs {
+		if leg.Position == 0 {
+			continue
+		}
+		direction := "LONG"
+		volume := leg.Position
+		if leg.Position < 0 {
+			direction = "SHORT"
+			volume = -leg.Position
+		}
+		exchange := exchanges[0]
+		if i < len(exchanges) {
+			exchange = exchanges[i]
+		}
+		positions = append(positions, &PositionDetail{
+			StrategyID:    strategyID,
+			Symbol:        leg.Symbol,
+			Exchange:      exchange,
+			Direction:     direction,
+			Volume:        volume,
+			AvgPrice:      leg.AvgPrice,
+			CurrentPrice:  leg.Price,
+			UnrealizedPnL: leg.UnrealizedPnL,
+			LegIndex:      i + 1,
+		})
+	}
+
+	return positions
+}
+
 // collectSingleLegPositions collects positions for single-leg strategies
 func (h *WebSocketHub) collectSingleLegPositions(strategyID string, strat strategy.Strategy) []*PositionDetail {
 	positions := make([]*PositionDetail, 0)
@@ -0,0 +1,135 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/journal"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// initJournal 打开写前日志并挂到策略引擎；日志中有快照之后的成交时先修正持仓快照
+// 须在策略 Start（从快照恢复持仓）和启动持仓校验之前调用
+func (t *Trader) initJournal() error {
+	cfg := &t.Config.Journal
+	if cfg.Path == "" {
+		cfg.Path = filepath.Join(strategy.GetDataDir(), "journal", "orders.journal")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	recs, res, err := journal.ReadAll(cfg.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read journal %s: %w", cfg.Path, err)
+	}
+	if res.TailBytes > 0 {
+		log.Printf("[Trader] Journal %s: dropping %d-byte torn tail (%s)", cfg.Path, res.TailBytes, res.TailError)
+	}
+
+	w, err := journal.Open(cfg.Path, journal.Options{
+		SyncInterval: time.Duration(cfg.SyncIntervalMs) * time.Millisecond,
+		SyncBatch:    cfg.SyncBatch,
+	})
+	if err != nil {
+		return err
+	}
+	t.orderJournal = w
+
+	if len(recs) > 0 {
+		t.recoverFromJournal(recs)
+		if err := w.Checkpoint("recovery", t.currentTradingDay()); err != nil {
+			log.Printf("[Trader] Warning: Failed to write journal checkpoint: %v", err)
+		}
+	}
+
+	t.Engine.SetOrderJournal(w)
+	log.Printf("[Trader] ✓ Order journal: %s (%d records)", cfg.Path, w.Records())
+	return nil
+}
+
+// recoverFromJournal 重放快照保存之后的成交，修正各策略持仓快照
+// 只修正已有快照的策略：没有快照的策略由柜台持仓初始化（8.2），不需要日志
+// 均价和已实现盈亏不重建，以 CTP 成本价和对账为准
+func (t *Trader) recoverFromJournal(recs []journal.Record) {
+	snapshots := make(map[string]*strategy.PositionSnapshot)
+	load := func(sid string) *strategy.PositionSnapshot {
+		if snap, ok := snapshots[sid]; ok {
+			return snap
+		}
+		snap, err := strategy.LoadPositionSnapshot(sid)
+		if err != nil {
+			log.Printf("[Trader] Warning: Failed to load position snapshot for %s: %v", sid, err)
+		}
+		snapshots[sid] = snap
+		return snap
+	}
+
+	replay := journal.ReplayRecords(recs, func(sid string) time.Time {
+		if snap := load(sid); snap != nil {
+			return snap.Timestamp
+		}
+		return time.Time{}
+	})
+	log.Printf("[Trader] Journal replay: %d records, %d fills after snapshots, %d open orders",
+		replay.Records, replay.FillCount, len(replay.OpenOrders))
+
+	for sid, fills := range replay.Fills {
+		snap := load(sid)
+		if snap == nil {
+			log.Printf("[Trader] Journal: strategy %s has no position snapshot, fills left to CTP verification", sid)
+			continue
+		}
+		if snap.SymbolsPos == nil {
+			snap.SymbolsPos = make(map[string]int64)
+		}
+		for symbol, delta := range fills {
+			if delta == 0 {
+				continue
+			}
+			log.Printf("[Trader] Journal: %s %s %d → %d", sid, symbol, snap.SymbolsPos[symbol], snap.SymbolsPos[symbol]+delta)
+			snap.SymbolsPos[symbol] += delta
+			snap.TotalNetQty += delta
+		}
+		snap.TotalLongQty, snap.TotalShortQty = 0, 0
+		if snap.TotalNetQty > 0 {
+			snap.TotalLongQty = snap.TotalNetQty
+		} else if snap.TotalNetQty < 0 {
+			snap.TotalShortQty = -snap.TotalNetQty
+		}
+		snap.Timestamp = time.Now()
+		if err := strategy.SavePositionSnapshot(*snap); err != nil {
+			log.Printf("[Trader] Warning: Failed to save recovered snapshot for %s: %v", sid, err)
+		}
+	}
+
+	// 在途订单由柜台决定最终状态（成交会经回报推送），这里只提示
+	for _, o := range replay.OpenOrders {
+		log.Printf("[Trader] Journal: open order %s strategy=%s %s %s %d@%.2f filled=%d status=%s",
+			o.OrderID, o.StrategyID, o.Symbol, o.Side, o.Qty, o.Price, o.FilledQty, o.Status)
+	}
+}
+
+// checkpointJournal 持仓快照已保存，记录 checkpoint
+func (t *Trader) checkpointJournal(reason string) {
+	if t.orderJournal == nil {
+		return
+	}
+	if err := t.orderJournal.Checkpoint(reason, t.currentTradingDay()); err != nil {
+		log.Printf("[Trader] Warning: Failed to write journal checkpoint: %v", err)
+	}
+}
+
+// rolloverJournal 交易日切换：归档当日日志，新日志从 checkpoint 开始
+func (t *Trader) rolloverJournal(day string) {
+	archive := journal.ArchivePath(t.orderJournal.Path(), day)
+	if err := t.orderJournal.Rotate(archive); err != nil {
+		log.Printf("[Trader] Error rotating order journal: %v", err)
+		return
+	}
+	log.Printf("[Trader] ✓ Order journal archived to %s", archive)
+	t.checkpointJournal("rollover")
+}
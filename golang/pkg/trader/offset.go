@@ -0,0 +1,133 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/account"
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	"github.com/yourusername/quantlink-trade-system/pkg/offset"
+	commonpb "github.com/yourusername/quantlink-trade-system/pkg/proto/common"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+)
+
+// newOffsetPlanner 按配置创建开平仓规划器（内置品种表 + 配置覆盖）
+func newOffsetPlanner(cfg *config.OffsetConfig) (*offset.Planner, error) {
+	lock, err := offset.ParseLockMode(cfg.Lock)
+	if err != nil {
+		return nil, fmt.Errorf("offset: %w", err)
+	}
+
+	planner := offset.NewPlanner(lock)
+	for product, pc := range cfg.Products {
+		planner.SetProduct(product, offset.Product{
+			Exchange:       pc.Exchange,
+			Multiplier:     pc.Multiplier,
+			Open:           offset.Fee{Ratio: pc.OpenRatio, PerLot: pc.OpenPerLot},
+			CloseYesterday: offset.Fee{Ratio: pc.CloseYdRatio, PerLot: pc.CloseYdPerLot},
+			CloseToday:     offset.Fee{Ratio: pc.CloseTdRatio, PerLot: pc.CloseTdPerLot},
+		})
+	}
+	return planner, nil
+}
+
+// seedOffsetPositions 用柜台查询的今/昨持仓初始化开平仓台账
+// C++: counter_bridge 启动时 ReqQryInvestorPosition 填充 contractPos
+// 柜台查询为各账户汇总，只用于默认账户台账；多账户时各账户台账按账户持仓单独初始化
+func (t *Trader) seedOffsetPositions() {
+	if t.Engine == nil {
+		return
+	}
+	planner := t.Engine.OffsetPlanner()
+	if planner == nil {
+		return
+	}
+
+	t.positionsMu.RLock()
+	bySymbol := make(map[string]offset.Position)
+	for _, posList := range t.positionsByExchange {
+		for _, pos := range posList {
+			p := bySymbol[pos.Symbol]
+			if pos.Direction == "short" {
+				p.ShortTd += pos.TodayVolume
+				p.ShortYd += pos.YesterdayVolume
+			} else {
+				p.LongTd += pos.TodayVolume
+				p.LongYd += pos.YesterdayVolume
+			}
+			bySymbol[pos.Symbol] = p
+		}
+	}
+	t.positionsMu.RUnlock()
+
+	for symbol, pos := range bySymbol {
+		planner.SetPosition(symbol, pos)
+		log.Printf("[Trader] Offset ledger %s: long %d/%d short %d/%d (today/yesterday)",
+			symbol, pos.LongTd, pos.LongYd, pos.ShortTd, pos.ShortYd)
+	}
+
+	if t.Accounts == nil {
+		return
+	}
+	for _, snap := range t.Accounts.Snapshot() {
+		for _, p := range snap.Positions {
+			pos := accountOffsetPosition(p)
+			planner.SetAccountPosition(snap.Account, p.Symbol, pos)
+			log.Printf("[Trader] Offset ledger %s@%s: long %d/%d short %d/%d (today/yesterday)",
+				p.Symbol, snap.Account, pos.LongTd, pos.LongYd, pos.ShortTd, pos.ShortYd)
+		}
+	}
+}
+
+// accountOffsetPosition 账户净持仓按当日同向成交拆出今仓，其余为昨仓
+// 账户台账只记净持仓，锁仓部分无法还原，按净头寸规划开平
+func accountOffsetPosition(p account.Position) offset.Position {
+	var pos offset.Position
+	switch {
+	case p.NetQty > 0:
+		pos.LongTd = min(p.NetQty, p.BuyQty)
+		pos.LongYd = p.NetQty - pos.LongTd
+	case p.NetQty < 0:
+		pos.ShortTd = min(-p.NetQty, p.SellQty)
+		pos.ShortYd = -p.NetQty - pos.ShortTd
+	}
+	return pos
+}
+
+// adoptOrderOffset 接管的在途订单计入开平仓台账（扣减未成交的平仓量），须在台账初始化之后、策略激活之前调用
+// 主账户订单记入主账户台账，未分配账户的策略记入默认账户台账
+func (t *Trader) adoptOrderOffset(strategyID string, o *orspb.OrderUpdate) {
+	if t.Engine == nil {
+		return
+	}
+	planner := t.Engine.OffsetPlanner()
+	if planner == nil {
+		return
+	}
+
+	acct := ""
+	if t.Accounts != nil {
+		if allocs := t.Accounts.Allocations(strategyID); len(allocs) > 0 {
+			acct = allocs[0].Account
+		}
+	}
+	side := offset.Buy
+	if o.Side == orspb.OrderSide_SELL {
+		side = offset.Sell
+	}
+	exchange := ""
+	if o.Exchange != commonpb.Exchange_UNKNOWN_EXCHANGE {
+		exchange = o.Exchange.String()
+	}
+
+	pc := planner.Adopt(o.OrderId, offset.Request{
+		Account:  acct,
+		Symbol:   o.Symbol,
+		Exchange: exchange,
+		Side:     side,
+		Qty:      o.Quantity,
+		Price:    o.Price,
+	}, o.FilledQty)
+	log.Printf("[Trader] Offset ledger adopted %s %s %s %d@%.2f filled=%d as %s",
+		o.OrderId, o.Symbol, o.Side, o.Quantity, o.Price, o.FilledQty, pc.Offset)
+}
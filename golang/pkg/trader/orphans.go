@@ -0,0 +1,195 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/client"
+	orspb "github.com/yourusername/quantlink-trade-system/pkg/proto/ors"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+)
+
+// liveOrderStatuses 柜台上仍可能成交的订单状态
+var liveOrderStatuses = []orspb.OrderStatus{
+	orspb.OrderStatus_PENDING,
+	orspb.OrderStatus_SUBMITTED,
+	orspb.OrderStatus_ACCEPTED,
+	orspb.OrderStatus_PARTIALLY_FILLED,
+	orspb.OrderStatus_CANCELING,
+}
+
+// OrphanOrder 启动时发现的在途订单及处理结果
+type OrphanOrder struct {
+	StrategyID string  `json:"strategy_id"`
+	OrderID    string  `json:"order_id"`
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`
+	Price      float64 `json:"price"`
+	Quantity   int64   `json:"quantity"`
+	FilledQty  int64   `json:"filled_qty"`
+	Status     string  `json:"status"`
+	Action     string  `json:"action"` // adopted / canceled / cancel_failed / ignored
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// OrphanReport 启动时在途订单处理报告
+type OrphanReport struct {
+	Time   time.Time     `json:"time"`
+	Policy string        `json:"policy"`
+	Orders []OrphanOrder `json:"orders"`
+}
+
+// recoverOpenOrders 查询上一进程遗留在柜台上的在途订单，按 orphan_orders.policy 接管或撤单（Go 扩展）
+// 重启后策略订单表为空，柜台上的挂单仍会成交：不处理会导致重复挂单、成交无法归属
+//
+//	adopt:  策略实现 OrderAdopter 时接管到订单表，无法接管（价位冲突/非本策略合约）的撤单
+//	cancel: 全部撤单
+//	ignore: 只记录
+//
+// 接管的订单同时计入开平仓台账（扣减平仓占用），因此须在台账初始化之后、策略激活之前调用
+// 报告写入 <data_dir>/orphan_orders.json
+func (t *Trader) recoverOpenOrders() {
+	policy := strings.ToLower(t.Config.OrphanOrders.Policy)
+	if policy == "off" || t.Engine == nil || t.Engine.GetORSClient() == nil || t.StrategyMgr == nil {
+		return
+	}
+	orsClient := t.Engine.GetORSClient()
+
+	report := OrphanReport{Time: time.Now(), Policy: policy}
+	t.StrategyMgr.ForEach(func(id string, strat strategy.Strategy) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		orders, err := orsClient.QueryOrders(ctx, &orspb.OrderQuery{StrategyId: id, Status: liveOrderStatuses})
+		cancel()
+		if err != nil {
+			log.Printf("[Trader] Warning: Failed to query open orders for %s: %v", id, err)
+			return
+		}
+
+		adopter, canAdopt := strat.(strategy.OrderAdopter)
+		for _, od := range orders {
+			o := od.GetOrder()
+			if o == nil || o.StrategyId != id {
+				continue
+			}
+			entry := newOrphanOrder(id, o)
+
+			switch {
+			case policy == "ignore":
+				entry.Action, entry.Reason = "ignored", "policy=ignore, order is not tracked"
+			case policy == "adopt" && !canAdopt:
+				entry.Reason = "strategy cannot adopt orders"
+				t.cancelOrphanOrder(o, &entry)
+			case policy == "adopt":
+				if err := adopter.AdoptOrder(o); err != nil {
+					entry.Reason = err.Error()
+					t.cancelOrphanOrder(o, &entry)
+				} else {
+					entry.Action = "adopted"
+					t.adoptOrderOffset(id, o)
+				}
+			default:
+				entry.Reason = "policy=cancel"
+				t.cancelOrphanOrder(o, &entry)
+			}
+			report.Orders = append(report.Orders, entry)
+		}
+
+		// 跟随账户订单（StrategyId = <id>@<account>）重启后无法归属到账户台账，一律撤单
+		if t.Accounts != nil {
+			for _, fid := range t.Accounts.FollowerStrategyIDs(id) {
+				t.cancelFollowerOrphans(orsClient, fid, policy, &report)
+			}
+		}
+	})
+
+	counts := make(map[string]int)
+	for _, o := range report.Orders {
+		counts[o.Action]++
+		log.Printf("[Trader] Open order %-13s %s %s %s %s %d@%.2f filled=%d status=%s %s",
+			o.Action, o.StrategyID, o.OrderID, o.Symbol, o.Side, o.Quantity, o.Price, o.FilledQty, o.Status, o.Reason)
+	}
+	log.Printf("[Trader] ✓ Open order recovery: policy=%s live=%d adopted=%d canceled=%d cancel_failed=%d ignored=%d",
+		policy, len(report.Orders), counts["adopted"], counts["canceled"], counts["cancel_failed"], counts["ignored"])
+
+	path := filepath.Join(strategy.GetDataDir(), "orphan_orders.json")
+	if err := writeOrphanReport(path, &report); err != nil {
+		log.Printf("[Trader] Warning: Failed to write open order report: %v", err)
+	}
+}
+
+func newOrphanOrder(strategyID string, o *orspb.OrderUpdate) OrphanOrder {
+	return OrphanOrder{
+		StrategyID: strategyID,
+		OrderID:    o.OrderId,
+		Symbol:     o.Symbol,
+		Side:       o.Side.String(),
+		Price:      o.Price,
+		Quantity:   o.Quantity,
+		FilledQty:  o.FilledQty,
+		Status:     o.Status.String(),
+	}
+}
+
+// cancelFollowerOrphans 处理跟随账户遗留的在途订单（ignore 时只记录）
+func (t *Trader) cancelFollowerOrphans(orsClient *client.ORSClient, strategyID, policy string, report *OrphanReport) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	orders, err := orsClient.QueryOrders(ctx, &orspb.OrderQuery{StrategyId: strategyID, Status: liveOrderStatuses})
+	cancel()
+	if err != nil {
+		log.Printf("[Trader] Warning: Failed to query open orders for %s: %v", strategyID, err)
+		return
+	}
+	for _, od := range orders {
+		o := od.GetOrder()
+		if o == nil || o.StrategyId != strategyID {
+			continue
+		}
+		entry := newOrphanOrder(strategyID, o)
+		if policy == "ignore" {
+			entry.Action, entry.Reason = "ignored", "policy=ignore, order is not tracked"
+		} else {
+			entry.Reason = "follower account order"
+			t.cancelOrphanOrder(o, &entry)
+		}
+		report.Orders = append(report.Orders, entry)
+	}
+}
+
+// cancelOrphanOrder 撤销无法接管的在途订单
+func (t *Trader) cancelOrphanOrder(o *orspb.OrderUpdate, entry *OrphanOrder) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := t.Engine.CancelOrder(ctx, &orspb.CancelRequest{
+		OrderId:       o.OrderId,
+		ClientOrderId: o.ClientOrderId,
+		StrategyId:    o.StrategyId,
+		Symbol:        o.Symbol,
+	})
+	switch {
+	case err != nil:
+		entry.Action = "cancel_failed"
+		entry.Reason = fmt.Sprintf("%s; cancel: %v", entry.Reason, err)
+	case resp.ErrorCode != orspb.ErrorCode_SUCCESS:
+		entry.Action = "cancel_failed"
+		entry.Reason = fmt.Sprintf("%s; cancel rejected: %s", entry.Reason, resp.ErrorMsg)
+	default:
+		entry.Action = "canceled"
+	}
+}
+
+func writeOrphanReport(path string, report *OrphanReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
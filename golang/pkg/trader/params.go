@@ -0,0 +1,200 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+
+	"tbsrc-golang/pkg/paramset"
+)
+
+// strategyTarget 将 StrategyManager 适配为参数变更的作用对象
+type strategyTarget struct {
+	mgr *strategy.StrategyManager
+}
+
+func (s strategyTarget) StrategyIDs() []string {
+	return s.mgr.GetStrategyIDs()
+}
+
+func (s strategyTarget) Parameters(id string) (map[string]interface{}, error) {
+	strat, ok := s.mgr.GetStrategy(id)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s not found", id)
+	}
+	return strat.GetCurrentParameters(), nil
+}
+
+func (s strategyTarget) Apply(id string, params map[string]interface{}) error {
+	strat, ok := s.mgr.GetStrategy(id)
+	if !ok {
+		return fmt.Errorf("strategy %s not found", id)
+	}
+	return strat.UpdateParameters(params)
+}
+
+// initParams 创建两阶段参数变更工作流（Go 扩展）
+// 版本记录持久化在 params.dir；重启后配置/model 文件中的值可能与最新版本不一致，
+// reapply_on_start 时自动恢复，否则只记录告警
+func (t *Trader) initParams() error {
+	if t.StrategyMgr == nil {
+		return nil
+	}
+	cfg := &t.Config.Params
+	if cfg.Dir == "" {
+		cfg.Dir = filepath.Join(strategy.GetDataDir(), "params")
+	}
+	if cfg.MaxChangePct == 0 {
+		cfg.MaxChangePct = 50
+	}
+
+	wf, err := paramset.New(strategyTarget{mgr: t.StrategyMgr}, paramset.Options{
+		Dir:             cfg.Dir,
+		RequireApproval: cfg.RequireApproval,
+		Limits:          t.paramLimits,
+	})
+	if err != nil {
+		return err
+	}
+	t.Params = wf
+
+	if drift := wf.Drift(); len(drift) > 0 {
+		if cfg.ReapplyOnStart {
+			c, err := wf.Restore("startup")
+			if err != nil {
+				return fmt.Errorf("reapply parameter version: %w", err)
+			}
+			log.Printf("[Trader] ✓ Parameter version re-applied on startup (change %s, version %d)", c.ID, c.Version)
+		} else {
+			for id, diffs := range drift {
+				log.Printf("[Trader] ⚠️  Strategy %s parameters differ from latest version: %d keys (POST /api/v1/params/rollback to restore)",
+					id, len(diffs))
+			}
+		}
+	}
+	log.Printf("[Trader] ✓ Parameter workflow: %s (versions=%d, require_approval=%v)",
+		cfg.Dir, len(wf.Versions()), cfg.RequireApproval)
+	return nil
+}
+
+// paramLimits 参数变更风险提示使用的限额：策略 max_position_size、账户限额（取最严）、风控止损
+func (t *Trader) paramLimits(strategyID string) paramset.Limits {
+	limits := paramset.Limits{
+		StopLoss:     t.Config.Risk.StopLoss,
+		MaxLoss:      t.Config.Risk.MaxLoss,
+		MaxChangePct: t.Config.Params.MaxChangePct,
+	}
+	tighten := func(cur *int64, v int64) {
+		if v > 0 && (*cur == 0 || v < *cur) {
+			*cur = v
+		}
+	}
+	if t.StrategyMgr != nil {
+		if cfg, ok := t.StrategyMgr.GetConfig(strategyID); ok {
+			tighten(&limits.MaxPosition, cfg.MaxPositionSize)
+			accounts := make(map[string]bool)
+			for _, a := range cfg.Accounts {
+				accounts[a.Account] = true
+			}
+			for _, a := range t.Config.Accounts.List {
+				if accounts[a.ID] {
+					tighten(&limits.MaxPosition, a.MaxPosition)
+					tighten(&limits.MaxOrderQty, a.MaxOrderQty)
+				}
+			}
+		}
+	}
+	if len(t.Config.Accounts.List) > 0 {
+		tighten(&limits.MaxPosition, t.Config.Accounts.Global.MaxPosition)
+		tighten(&limits.MaxOrderQty, t.Config.Accounts.Global.MaxOrderQty)
+	}
+	return limits
+}
+
+// StageModelFile 解析 model 文件并提交参数变更（只预览，不生效）
+// strategyIDs 为空时作用于全部策略；各策略不支持的参数被忽略
+func (t *Trader) StageModelFile(path string, strategyIDs []string, author, comment string) (*paramset.Change, error) {
+	if t.Params == nil {
+		return nil, fmt.Errorf("parameter workflow not initialized")
+	}
+	modelParams, err := config.NewModelFileParser(path).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("parse model file: %w", err)
+	}
+	if err := config.ValidateParameters(modelParams); err != nil {
+		return nil, fmt.Errorf("validate model file: %w", err)
+	}
+	return t.Params.Stage(paramset.StageRequest{
+		Strategies:    strategyIDs,
+		Params:        config.ConvertModelToStrategyParams(modelParams),
+		Author:        author,
+		Comment:       comment,
+		Source:        path,
+		IgnoreUnknown: true,
+	})
+}
+
+// applyStaged 立即生效刚提交的变更（模型重载接口）；需要审批时保留为待审批状态并返回错误
+func (t *Trader) applyStaged(c *paramset.Change, user string) (*paramset.Change, error) {
+	if t.Config.Params.RequireApproval {
+		return c, fmt.Errorf("change %s staged and awaiting approval (params.require_approval is set)", c.ID)
+	}
+	return t.Params.Apply(c.ID, user, time.Time{})
+}
+
+// ReloadStrategyModelVersioned 重载单个策略的 model 文件，经参数工作流生效并生成版本
+func (t *Trader) ReloadStrategyModelVersioned(strategyID, user string) (*paramset.Change, error) {
+	cfg, ok := t.StrategyMgr.GetConfig(strategyID)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s not found", strategyID)
+	}
+	if cfg.ModelFile == "" {
+		return nil, fmt.Errorf("strategy %s has no model file configured", strategyID)
+	}
+	if !cfg.HotReload.Enabled {
+		return nil, fmt.Errorf("strategy %s hot reload is not enabled", strategyID)
+	}
+	c, err := t.StageModelFile(cfg.ModelFile, []string{strategyID}, user, "model reload")
+	if err != nil {
+		return nil, err
+	}
+	return t.applyStaged(c, user)
+}
+
+// ResolveApplyAt 解析生效时间：now（默认）/ session_start（下一个交易时段开盘）/ session_end（当前时段收盘）/ RFC3339
+func (t *Trader) ResolveApplyAt(s string) (time.Time, error) {
+	switch s {
+	case "", "now":
+		return time.Time{}, nil
+	case "session_start", "session_end":
+		if t.SessionMgr == nil {
+			return time.Time{}, fmt.Errorf("session manager not initialized")
+		}
+		if s == "session_start" {
+			return t.SessionMgr.GetNextSessionStart()
+		}
+		return t.SessionMgr.GetCurrentSessionEnd()
+	}
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("apply_at must be now, session_start, session_end or RFC3339: %w", err)
+	}
+	return at, nil
+}
+
+// runParamScheduler 执行到期的定时参数变更
+func (t *Trader) runParamScheduler() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for t.IsRunning() {
+		now := <-ticker.C
+		for _, c := range t.Params.ApplyDue(now) {
+			log.Printf("[Trader] Scheduled parameter change %s: %s", c.ID, c.Status)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/yourusername/quantlink-trade-system/pkg/client"
+	"github.com/yourusername/quantlink-trade-system/pkg/reconcile"
+	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+
+	"tbsrc-golang/pkg/calendar"
+)
+
+// initReconcile 创建成交流水并挂到策略引擎，解析差异处理策略
+func (t *Trader) initReconcile() error {
+	cfg := &t.Config.Reconcile
+
+	policy := reconcile.DefaultPolicy()
+	for kind, action := range cfg.Policies {
+		a, err := reconcile.ParseAction(action)
+		if err != nil {
+			return fmt.Errorf("reconcile.policies.%s: %w", kind, err)
+		}
+		policy[reconcile.Kind(kind)] = a
+	}
+	t.reconcilePolicy = policy
+
+	if cfg.FillDir == "" {
+		cfg.FillDir = filepath.Join(strategy.GetDataDir(), "fills")
+	}
+	if cfg.ReportDir == "" {
+		cfg.ReportDir = filepath.Join(strategy.GetDataDir(), "reconcile")
+	}
+
+	journal, err := reconcile.NewJournal(cfg.FillDir, t.currentTradingDay())
+	if err != nil {
+		return err
+	}
+	t.fillJournal = journal
+	t.Engine.SetFillRecorder(journal)
+	log.Printf("[Trader] ✓ Fill journal: %s (trading day %s)", cfg.FillDir, journal.TradingDay())
+	return nil
+}
+
+// currentTradingDay 当前交易日（启用交易日历时夜盘归属下一交易日）
+func (t *Trader) currentTradingDay() string {
+	now := time.Now()
+	if t.SessionMgr != nil {
+		if cal := t.SessionMgr.Calendar(); cal != nil {
+			return calendar.FormatDay(cal.TradingDay(now))
+		}
+	}
+	return now.Format("20060102")
+}
+
+// ensureReconcileBaseline 当日首次启动时以柜台昨仓记录对账基线
+func (t *Trader) ensureReconcileBaseline() {
+	if t.fillJournal == nil {
+		return
+	}
+	dir, day := t.fillJournal.Dir(), t.fillJournal.TradingDay()
+	base, err := reconcile.LoadBaseline(dir, day)
+	if err != nil {
+		log.Printf("[Trader] Warning: failed to load reconcile baseline: %v", err)
+		return
+	}
+	if base != nil {
+		log.Printf("[Trader] Reconcile baseline for %s loaded (%d symbols)", day, len(base))
+		return
+	}
+
+	t.positionsMu.RLock()
+	base = make(map[string]reconcile.Split)
+	for sym, s := range counterSplits(t.positionsByExchange) {
+		if y := (reconcile.Split{LongYd: s.LongYd, ShortYd: s.ShortYd}); y != (reconcile.Split{}) {
+			base[sym] = y
+		}
+	}
+	t.positionsMu.RUnlock()
+
+	if err := reconcile.SaveBaseline(dir, day, base); err != nil {
+		log.Printf("[Trader] Warning: failed to save reconcile baseline: %v", err)
+		return
+	}
+	log.Printf("[Trader] Reconcile baseline for %s recorded from counter (%d symbols)", day, len(base))
+}
+
+// counterSplits 柜台持仓 → 按品种的多空今昨
+func counterSplits(positions map[string][]client.PositionInfo) map[string]reconcile.Split {
+	out := make(map[string]reconcile.Split)
+	for _, posList := range positions {
+		for _, pos := range posList {
+			s := out[pos.Symbol]
+			if pos.Direction == "SHORT" || pos.Direction == "short" {
+				s.ShortTd += pos.TodayVolume
+				s.ShortYd += pos.YesterdayVolume
+			} else {
+				s.LongTd += pos.TodayVolume
+				s.LongYd += pos.YesterdayVolume
+			}
+			out[pos.Symbol] = s
+		}
+	}
+	return out
+}
+
+// strategyPositions 各策略按品种的持仓
+func (t *Trader) strategyPositions() map[string]map[string]int64 {
+	out := make(map[string]map[string]int64)
+	if t.StrategyMgr == nil {
+		return out
+	}
+	t.StrategyMgr.ForEach(func(id string, strat strategy.Strategy) {
+		if provider, ok := strat.(strategy.PositionProvider); ok {
+			out[id] = provider.GetPositionsBySymbol()
+		}
+	})
+	return out
+}
+
+// Reconcile 执行一次三方对账：策略持仓 vs 成交流水台账 vs 柜台持仓
+// 按差异类型的处理策略自动修复或停用策略，报告写入 report_dir 并保留最近一次
+func (t *Trader) Reconcile() (*reconcile.Report, error) {
+	if t.fillJournal == nil {
+		return nil, fmt.Errorf("reconciliation not enabled")
+	}
+	if t.Engine == nil || t.Engine.GetORSClient() == nil {
+		return nil, fmt.Errorf("ORS client not available")
+	}
+
+	t.reconcileMu.Lock()
+	defer t.reconcileMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	positions, err := t.Engine.GetORSClient().QueryPositions(ctx, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counter positions: %w", err)
+	}
+
+	rep, err := t.reconcileWith(counterSplits(positions))
+	if err != nil {
+		return nil, err
+	}
+
+	if path, err := reconcile.WriteReport(t.Config.Reconcile.ReportDir, rep); err != nil {
+		log.Printf("[Trader] Warning: failed to write reconcile report: %v", err)
+	} else {
+		log.Printf("[Trader] Reconcile report: %s", path)
+	}
+	t.lastReconcile = rep
+
+	if !rep.OK() {
+		return rep, fmt.Errorf("%d position discrepancies", len(rep.Discrepancies))
+	}
+	log.Printf("[Trader] ✓ Reconciliation passed (%d symbols, %d fills)", len(rep.Rows), rep.Fills)
+	return rep, nil
+}
+
+// LastReconcileReport 返回最近一次对账报告
+func (t *Trader) LastReconcileReport() *reconcile.Report {
+	t.reconcileMu.Lock()
+	defer t.reconcileMu.Unlock()
+	return t.lastReconcile
+}
+
+// reconcileWith 用给定柜台持仓对账并执行处理策略，调用方持 reconcileMu
+func (t *Trader) reconcileWith(counter map[string]reconcile.Split) (*reconcile.Report, error) {
+	dir, day := t.fillJournal.Dir(), t.fillJournal.TradingDay()
+	base, err := reconcile.LoadBaseline(dir, day)
+	if err != nil {
+		return nil, err
+	}
+	fills, err := t.fillJournal.Load(day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fill journal: %w", err)
+	}
+	ledger := reconcile.BuildLedger(base, fills)
+
+	perStrategy := t.strategyPositions()
+	total := make(map[string]int64)
+	owners := make(map[string][]string)
+	for id, posMap := range perStrategy {
+		for sym, qty := range posMap {
+			total[sym] += qty
+			owners[sym] = append(owners[sym], id)
+		}
+	}
+	for sym := range owners {
+		sort.Strings(owners[sym])
+	}
+
+	rep := reconcile.Reconcile(reconcile.Input{
+		TradingDay: day,
+		Strategy:   total,
+		Owners:     owners,
+		Ledger:     ledger,
+		Counter:    counter,
+		Policy:     t.reconcilePolicy,
+	})
+
+	for i := range rep.Discrepancies {
+		d := &rep.Discrepancies[i]
+		log.Printf("[Trader] ⚠️  Reconcile %s %s: %s (action: %s)", d.Symbol, d.Kind, d.Detail, d.Action)
+		switch d.Action {
+		case reconcile.ActionAutoHeal:
+			t.healDiscrepancy(d, base, perStrategy)
+		case reconcile.ActionFreeze:
+			t.freezeOwners(d)
+		}
+	}
+	return rep, nil
+}
+
+// healDiscrepancy 自动修复
+// 策略侧差异（漏成交/重复成交）：以台账为准修正唯一持有策略的持仓
+// 柜台侧差异（手工单/无法解释）：追加对账调整成交使台账与柜台一致，并修正唯一持有策略
+// 今昨拆分差异：修正昨仓基线
+// 无法归属到唯一策略时退化为停用策略
+func (t *Trader) healDiscrepancy(d *reconcile.Discrepancy, base map[string]reconcile.Split, perStrategy map[string]map[string]int64) {
+	switch d.Kind {
+	case reconcile.YesterdaySplit:
+		if base == nil {
+			base = make(map[string]reconcile.Split)
+		}
+		b := base[d.Symbol]
+		b.LongTd += d.CounterSplit.LongTd - d.LedgerSplit.LongTd
+		b.LongYd += d.CounterSplit.LongYd - d.LedgerSplit.LongYd
+		b.ShortTd += d.CounterSplit.ShortTd - d.LedgerSplit.ShortTd
+		b.ShortYd += d.CounterSplit.ShortYd - d.LedgerSplit.ShortYd
+		base[d.Symbol] = b
+		if err := reconcile.SaveBaseline(t.fillJournal.Dir(), t.fillJournal.TradingDay(), base); err != nil {
+			log.Printf("[Trader] Warning: failed to heal baseline for %s: %v", d.Symbol, err)
+			return
+		}
+		d.Healed = true
+		return
+
+	case reconcile.ManualTrade, reconcile.Unexplained:
+		if diff := d.Counter - d.Ledger; diff != 0 {
+			side, qty := "BUY", diff
+			if diff < 0 {
+				side, qty = "SELL", -diff
+			}
+			adj := reconcile.Fill{Time: time.Now(), Symbol: d.Symbol, Side: side, Qty: qty, Source: "reconcile",
+				OrderID: fmt.Sprintf("reconcile-%d", time.Now().UnixNano())}
+			if err := t.fillJournal.Append(adj); err != nil {
+				log.Printf("[Trader] Warning: failed to append reconcile adjustment for %s: %v", d.Symbol, err)
+				t.freezeOwners(d)
+				return
+			}
+		}
+		if len(d.Owners) == 0 {
+			d.Healed = true
+			return
+		}
+	}
+
+	if len(d.Owners) != 1 {
+		d.Detail += "; cannot attribute to a single strategy, freezing"
+		t.freezeOwners(d)
+		return
+	}
+	id := d.Owners[0]
+	strat, ok := t.StrategyMgr.GetStrategy(id)
+	initializer, canInit := strat.(strategy.PositionInitializer)
+	if !ok || !canInit {
+		d.Detail += "; strategy cannot be re-initialized, freezing"
+		t.freezeOwners(d)
+		return
+	}
+
+	target := perStrategy[id][d.Symbol] + d.Counter - d.Strategy
+	if err := initializer.InitializePositions(map[string]int64{d.Symbol: target}); err != nil {
+		d.Detail += fmt.Sprintf("; heal failed: %v", err)
+		t.freezeOwners(d)
+		return
+	}
+	log.Printf("[Trader] Reconcile healed %s/%s: %d -> %d", id, d.Symbol, perStrategy[id][d.Symbol], target)
+	d.Healed = true
+}
+
+// freezeOwners 停用持有该品种的策略（只停止开新单，不触发平仓：持仓本身存疑）
+func (t *Trader) freezeOwners(d *reconcile.Discrepancy) {
+	if t.StrategyMgr == nil {
+		return
+	}
+	for _, id := range d.Owners {
+		strat, ok := t.StrategyMgr.GetStrategy(id)
+		if !ok {
+			continue
+		}
+		if ctrl := strat.GetControlState(); ctrl != nil {
+			ctrl.Deactivate()
+		}
+		log.Printf("[Trader] Strategy %s frozen by reconciliation (%s %s)", id, d.Symbol, d.Kind)
+		d.Frozen = true
+	}
+}
+
+// rolloverReconcile 交易日切换：日终对账，按台账结转下一交易日昨仓基线并切换流水文件
+func (t *Trader) rolloverReconcile(tradingDay time.Time) {
+	if _, err := t.Reconcile(); err != nil {
+		log.Printf("[Trader] End-of-day reconciliation: %v", err)
+	}
+
+	t.reconcileMu.Lock()
+	defer t.reconcileMu.Unlock()
+
+	dir, day := t.fillJournal.Dir(), t.fillJournal.TradingDay()
+	base, err := reconcile.LoadBaseline(dir, day)
+	if err != nil {
+		log.Printf("[Trader] Warning: failed to load baseline for rollover: %v", err)
+	}
+	fills, err := t.fillJournal.Load(day)
+	if err != nil {
+		log.Printf("[Trader] Warning: failed to load fills for rollover: %v", err)
+	}
+	ledger := reconcile.BuildLedger(base, fills)
+
+	var next time.Time
+	if t.SessionMgr != nil && t.SessionMgr.Calendar() != nil {
+		next = t.SessionMgr.Calendar().NextTradingDay(tradingDay)
+	} else {
+		// 无交易日历时只跳过周末
+		next = tradingDay.AddDate(0, 0, 1)
+		for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	nextDay := calendar.FormatDay(next)
+
+	nextBase := make(map[string]reconcile.Split)
+	for sym, s := range ledger.Positions {
+		if r := s.Rolled(); r != (reconcile.Split{}) {
+			nextBase[sym] = r
+		}
+	}
+	if err := reconcile.SaveBaseline(dir, nextDay, nextBase); err != nil {
+		log.Printf("[Trader] Warning: failed to save baseline for %s: %v", nextDay, err)
+	}
+	if err := t.fillJournal.SetTradingDay(nextDay); err != nil {
+		log.Printf("[Trader] Warning: failed to switch fill journal to %s: %v", nextDay, err)
+		return
+	}
+	log.Printf("[Trader] Fill journal rolled over to %s (%d symbols carried)", nextDay, len(nextBase))
+}
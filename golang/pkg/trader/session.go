@@ -2,9 +2,12 @@ package trader
 
 import (
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/yourusername/quantlink-trade-system/pkg/config"
+
+	"tbsrc-golang/pkg/calendar"
 )
 
 // SessionManager manages trading sessions
@@ -12,6 +15,13 @@ import (
 type SessionManager struct {
 	config   *config.SessionConfig
 	location *time.Location
+
+	// 交易日历（session.calendar.enabled），nil 表示使用单一 StartTime/EndTime
+	calendar        *calendar.Calendar
+	templates       map[string]*calendar.Template
+	products        map[string]string
+	defaultTemplate *calendar.Template
+	preOpenLead     time.Duration
 }
 
 // NewSessionManager creates a new session manager
@@ -29,8 +39,113 @@ func NewSessionManager(config *config.SessionConfig) *SessionManager {
 	}
 }
 
+// LoadCalendar loads holidays and session templates (session.calendar)
+// 加载节假日文件和时段模板，之后 IsInSession 等按交易日历计算
+func (sm *SessionManager) LoadCalendar() error {
+	cfg := &sm.config.Calendar
+	cal := calendar.New(sm.location)
+	if cfg.HolidayFile != "" {
+		if err := cal.LoadHolidays(cfg.HolidayFile); err != nil {
+			return err
+		}
+		// 当年节假日缺失时交易日推算会把节假日当作交易日，拒绝启动
+		now := time.Now().In(sm.location)
+		if !cal.CoversYear(now.Year()) {
+			return fmt.Errorf("holiday file %s has no entries for %d, update it from the exchange holiday notice",
+				cfg.HolidayFile, now.Year())
+		}
+		if now.Month() == time.December && !cal.CoversYear(now.Year()+1) {
+			log.Printf("[SessionManager] WARNING: holiday file %s has no entries for %d, update it before the year ends",
+				cfg.HolidayFile, now.Year()+1)
+		}
+	} else {
+		log.Println("[SessionManager] WARNING: no holiday_file configured, only weekends are non-trading days")
+	}
+
+	templates := make(map[string]*calendar.Template, len(calendar.DefaultTemplates)+len(cfg.Templates))
+	for name, tmpl := range calendar.DefaultTemplates {
+		templates[name] = tmpl
+	}
+	for name, specs := range cfg.Templates {
+		tmpl, err := calendar.NewTemplate(name, specs...)
+		if err != nil {
+			return err
+		}
+		templates[name] = tmpl
+	}
+
+	products := make(map[string]string, len(calendar.DefaultProductTemplates)+len(cfg.Products))
+	for product, name := range calendar.DefaultProductTemplates {
+		products[product] = name
+	}
+	for product, name := range cfg.Products {
+		if _, ok := templates[name]; !ok {
+			return fmt.Errorf("product %s: unknown session template %q", product, name)
+		}
+		products[product] = name
+	}
+
+	defaultName := cfg.DefaultTemplate
+	if defaultName == "" {
+		defaultName = "day"
+	}
+	defaultTemplate, ok := templates[defaultName]
+	if !ok {
+		return fmt.Errorf("unknown default session template %q", defaultName)
+	}
+
+	sm.calendar = cal
+	sm.templates = templates
+	sm.products = products
+	sm.defaultTemplate = defaultTemplate
+	sm.preOpenLead = time.Duration(cfg.PreOpenMinutes) * time.Minute
+	log.Printf("[SessionManager] Trading calendar loaded: %d holidays, %d templates, default=%s",
+		cal.NumHolidays(), len(templates), defaultName)
+	return nil
+}
+
+// Calendar returns the trading calendar (nil if not enabled)
+func (sm *SessionManager) Calendar() *calendar.Calendar {
+	return sm.calendar
+}
+
+// TemplateFor returns the session template for a symbol; empty symbol returns the default template
+func (sm *SessionManager) TemplateFor(symbol string) *calendar.Template {
+	if sm.calendar == nil {
+		return nil
+	}
+	if name, ok := sm.products[calendar.ProductOf(symbol)]; ok && symbol != "" {
+		return sm.templates[name]
+	}
+	return sm.defaultTemplate
+}
+
+// NewTracker creates a session event tracker for a symbol (nil if calendar not enabled)
+func (sm *SessionManager) NewTracker(symbol string) *calendar.Tracker {
+	if sm.calendar == nil {
+		return nil
+	}
+	return calendar.NewTracker(sm.calendar, sm.TemplateFor(symbol), sm.preOpenLead)
+}
+
+// PhaseFor returns the trading phase of a symbol at the given time
+func (sm *SessionManager) PhaseFor(symbol string, now time.Time) calendar.Phase {
+	if sm.calendar == nil {
+		if sm.IsInSession() {
+			return calendar.PhaseOpen
+		}
+		return calendar.PhaseClosed
+	}
+	return sm.calendar.Phase(sm.TemplateFor(symbol), now, sm.preOpenLead)
+}
+
 // IsInSession returns whether current time is within trading session
+// 启用交易日历时：默认模板的交易日内（盘前准备到收盘，含休息）
 func (sm *SessionManager) IsInSession() bool {
+	if sm.calendar != nil {
+		return sm.PhaseFor("", time.Now()) != calendar.PhaseClosed
+	}
+
 	now := time.Now().In(sm.location)
 
 	// If no start/end time configured, always in session
@@ -62,6 +177,10 @@ func (sm *SessionManager) IsInSession() bool {
 func (sm *SessionManager) GetNextSessionStart() (time.Time, error) {
 	now := time.Now().In(sm.location)
 
+	if sm.calendar != nil {
+		return sm.calendar.NextOpen(sm.defaultTemplate, now), nil
+	}
+
 	if sm.config.StartTime == "" {
 		return time.Time{}, fmt.Errorf("no start time configured")
 	}
@@ -85,6 +204,10 @@ func (sm *SessionManager) GetNextSessionStart() (time.Time, error) {
 func (sm *SessionManager) GetCurrentSessionEnd() (time.Time, error) {
 	now := time.Now().In(sm.location)
 
+	if sm.calendar != nil {
+		return sm.calendar.NextClose(sm.defaultTemplate, now), nil
+	}
+
 	if sm.config.EndTime == "" {
 		return time.Time{}, fmt.Errorf("no end time configured")
 	}
@@ -176,5 +299,14 @@ func (sm *SessionManager) GetSessionInfo() map[string]interface{} {
 		info["time_until_start"] = timeUntilStart.String()
 	}
 
+	if sm.calendar != nil {
+		now := time.Now().In(sm.location)
+		info["trading_day"] = calendar.FormatDay(sm.calendar.TradingDay(now))
+		info["phase"] = sm.PhaseFor("", now).String()
+		info["template"] = sm.defaultTemplate.Name
+		info["next_open"] = sm.calendar.NextOpen(sm.defaultTemplate, now).Format(time.RFC3339)
+		info["next_close"] = sm.calendar.NextClose(sm.defaultTemplate, now).Format(time.RFC3339)
+	}
+
 	return info
 }
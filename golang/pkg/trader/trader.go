@@ -12,11 +12,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/yourusername/quantlink-trade-system/pkg/account"
 	"github.com/yourusername/quantlink-trade-system/pkg/client"
 	"github.com/yourusername/quantlink-trade-system/pkg/config"
+	"github.com/yourusername/quantlink-trade-system/pkg/journal"
 	"github.com/yourusername/quantlink-trade-system/pkg/portfolio"
+	"github.com/yourusername/quantlink-trade-system/pkg/reconcile"
 	"github.com/yourusername/quantlink-trade-system/pkg/risk"
 	"github.com/yourusername/quantlink-trade-system/pkg/strategy"
+
+	"tbsrc-golang/pkg/calendar"
+	"tbsrc-golang/pkg/connector"
+	"tbsrc-golang/pkg/paramset"
 )
 
 // Trader encapsulates the complete trading system
@@ -39,6 +46,24 @@ type Trader struct {
 	positionsByExchange map[string][]client.PositionInfo
 	positionsMu         sync.RWMutex
 
+	// Reconciliation (成交流水 + 三方对账)
+	fillJournal     *reconcile.Journal
+	reconcilePolicy reconcile.Policy
+	reconcileMu     sync.Mutex
+	lastReconcile   *reconcile.Report
+
+	// Write-ahead order journal (崩溃恢复)
+	orderJournal *journal.Writer
+
+	// Multi-account (多账户下单与账户持仓，未配置时为 nil)
+	Accounts *account.Manager
+
+	// Staged parameter changes (参数变更预览/审批/定时生效/回滚)
+	Params *paramset.Workflow
+
+	// Execution algos (API 下达的 TWAP/VWAP/POV/IS 母单，未启用时为 nil)
+	Algos *AlgoManager
+
 	// State
 	mu             sync.RWMutex
 	running        bool
@@ -160,6 +185,8 @@ func (t *Trader) Initialize() error {
 			MaxAllocation:         t.Config.Portfolio.MaxAllocation,
 			EnableAutoRebalance:   t.Config.Portfolio.EnableAutoRebalance,
 			EnableCorrelationCalc: t.Config.Portfolio.EnableCorrelation,
+			Policy:                portfolio.PolicyConfig(t.Config.Portfolio.Policy),
+			Sizing:                portfolio.SizingConfig(t.Config.Portfolio.Sizing),
 		}
 		t.Portfolio = portfolio.NewPortfolioManager(portfolioConfig)
 		if err := t.Portfolio.Initialize(); err != nil {
@@ -196,6 +223,30 @@ func (t *Trader) Initialize() error {
 
 	t.Engine = strategy.NewStrategyEngine(engineConfig)
 
+	// SHM 链路（Go 扩展）：与 C++ TradeBot 相同直接读写 hftbase 共享内存队列，替代 NATS + ORS Gateway
+	if t.Config.Engine.Transport == "shm" {
+		s := t.Config.Engine.SHM
+		t.Engine.SetTransport(strategy.NewSHMTransport(connector.Config{
+			MDShmKey:          s.MDShmKey,
+			MDQueueSz:         s.MDQueueSize,
+			ReqShmKey:         s.ReqShmKey,
+			ReqQueueSz:        s.ReqQueueSize,
+			RespShmKey:        s.RespShmKey,
+			RespQueueSz:       s.RespQueueSize,
+			ClientStoreShmKey: s.ClientStoreShmKey,
+		}))
+		log.Printf("[Trader] Using SHM transport: md=0x%x req=0x%x resp=0x%x", s.MDShmKey, s.ReqShmKey, s.RespShmKey)
+	}
+
+	if t.Config.Offset.Enabled {
+		planner, err := newOffsetPlanner(&t.Config.Offset)
+		if err != nil {
+			return err
+		}
+		t.Engine.SetOffsetPlanner(planner)
+		log.Printf("[Trader] ✓ Offset planner enabled (lock: %s)", planner.LockMode())
+	}
+
 	// Initialize engine (may fail if services not running)
 	if err := t.Engine.Initialize(); err != nil {
 		// 在测试环境下，即使是 live 模式也允许启动（不连接外部服务）
@@ -215,12 +266,52 @@ func (t *Trader) Initialize() error {
 	// 5. Create Session Manager
 	log.Println("[Trader] Creating Session Manager...")
 	t.SessionMgr = NewSessionManager(&t.Config.Session)
+	if t.Config.Session.Calendar.Enabled {
+		if err := t.SessionMgr.LoadCalendar(); err != nil {
+			return fmt.Errorf("failed to load trading calendar: %w", err)
+		}
+		// 集合竞价时段内的行情路由到 OnAuctionData
+		sm := t.SessionMgr
+		t.Engine.SetAuctionPhaseFunc(func(symbol string, ts time.Time) bool {
+			return sm.PhaseFor(symbol, ts) == calendar.PhaseAuction
+		})
+	}
 	log.Println("[Trader] ✓ Session Manager created")
 
+	// 5.1 成交流水（对账）
+	if t.Config.Reconcile.Enabled {
+		if err := t.initReconcile(); err != nil {
+			return fmt.Errorf("failed to initialize reconciliation: %w", err)
+		}
+	}
+
+	// 5.2 写前日志（重放崩溃前的成交，须在持仓校验之前）
+	if t.Config.Journal.Enabled {
+		if err := t.initJournal(); err != nil {
+			return fmt.Errorf("failed to initialize order journal: %w", err)
+		}
+	}
+
+	// 5.3 多账户（按比例分发订单，账户级持仓与风控）
+	if err := t.initAccounts(); err != nil {
+		return fmt.Errorf("failed to initialize accounts: %w", err)
+	}
+
+	// 5.4 参数变更工作流（版本持久化，可回滚）
+	if err := t.initParams(); err != nil {
+		return fmt.Errorf("failed to initialize parameter workflow: %w", err)
+	}
+
+	// 5.5 执行算法母单
+	t.initAlgos()
+
 	// 6. Create API Server (if enabled)
 	if t.Config.API.Enabled {
 		log.Printf("[Trader] Creating API Server (port: %d)...", t.Config.API.Port)
 		t.APIServer = NewAPIServer(t, t.Config.API.Port)
+		if err := t.APIServer.ConfigureAuth(&t.Config.API.Auth); err != nil {
+			return fmt.Errorf("failed to configure API auth: %w", err)
+		}
 		log.Println("[Trader] ✓ API Server created")
 	}
 
@@ -247,7 +338,12 @@ func (t *Trader) Initialize() error {
 	}
 
 	// 8. Query initial positions (查询初始持仓)
-	if err := t.queryInitialPositions(); err != nil {
+	// SHM 链路没有柜台查询接口：依赖查询的对账/在途订单恢复/开平台账已在配置校验时拒绝，
+	// 持仓只从本地持久化文件恢复，定期持仓校验也不启动
+	if t.Config.Engine.Transport == "shm" {
+		log.Printf("[Trader] WARNING: transport shm has no counter query; positions restored from local files only, " +
+			"startup and periodic position verification are disabled")
+	} else if err := t.queryInitialPositions(); err != nil {
 		log.Printf("[Trader] Warning: Failed to query initial positions: %v", err)
 		// 不阻断启动，策略可以从持久化文件恢复
 	} else {
@@ -258,10 +354,21 @@ func (t *Trader) Initialize() error {
 
 		// 8.2 初始化策略持仓
 		t.initializeStrategyPositions()
+
+		// 8.3 初始化开平仓台账（今/昨持仓）
+		t.seedOffsetPositions()
+
+		// 8.4 对账昨仓基线（当日首次启动时记录）
+		t.ensureReconcileBaseline()
 	}
 
+	// 8.5 在途订单恢复（接管或撤销上一进程遗留的挂单）
+	t.recoverOpenOrders()
+
 	// 9. Start position verification (定期持仓校验)
-	t.startPositionVerification()
+	if t.Config.Engine.Transport != "shm" {
+		t.startPositionVerification()
+	}
 
 	log.Println("[Trader] ✓ All components initialized successfully")
 	return nil
@@ -434,6 +541,16 @@ func (t *Trader) startPositionVerification() {
 	// 定期校验间隔：5分钟
 	verifyInterval := 5 * time.Minute
 
+	verify := t.verifyPositions
+	if t.fillJournal != nil {
+		// 启用对账后以三方对账代替净持仓校验
+		verifyInterval = time.Duration(t.Config.Reconcile.IntervalSec) * time.Second
+		verify = func() error {
+			_, err := t.Reconcile()
+			return err
+		}
+	}
+
 	log.Printf("[Trader] Starting position verification (interval: %v)", verifyInterval)
 
 	go func() {
@@ -441,7 +558,7 @@ func (t *Trader) startPositionVerification() {
 		defer ticker.Stop()
 
 		for range ticker.C {
-			if err := t.verifyPositions(); err != nil {
+			if err := verify(); err != nil {
 				log.Printf("[Trader] Position verification failed: %v", err)
 			}
 		}
@@ -818,6 +935,11 @@ func (t *Trader) Start() error {
 	// Start risk monitoring
 	go t.runRiskMonitoring()
 
+	// Start scheduled parameter changes
+	if t.Params != nil {
+		go t.runParamScheduler()
+	}
+
 	// Start signal handlers (对应 tbsrc 信号处理)
 	t.setupSignalHandlers()
 
@@ -851,6 +973,8 @@ func (t *Trader) Stop() error {
 
 	// 保存所有策略持仓到文件
 	t.saveAllPositions()
+	t.saveAccounts(t.currentTradingDay())
+	t.checkpointJournal("positions_saved")
 
 	// Stop API server
 	if t.APIServer != nil {
@@ -888,6 +1012,13 @@ func (t *Trader) Stop() error {
 		}
 	}
 
+	// Close order journal (引擎停止后不再有回报)
+	if t.orderJournal != nil {
+		if err := t.orderJournal.Close(); err != nil {
+			log.Printf("[Trader] Error closing order journal: %v", err)
+		}
+	}
+
 	// Stop portfolio manager
 	if t.Portfolio != nil {
 		if err := t.Portfolio.Stop(); err != nil {
@@ -1009,6 +1140,11 @@ func (t *Trader) getAllSymbols() []string {
 
 // runSessionManager monitors trading sessions
 func (t *Trader) runSessionManager() {
+	if t.SessionMgr.Calendar() != nil {
+		t.runCalendarSessions()
+		return
+	}
+
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -1039,6 +1175,104 @@ func (t *Trader) runSessionManager() {
 	}
 }
 
+// runCalendarSessions drives strategies from the trading calendar
+// 每个策略按首个品种的时段模板跟踪阶段：非 closed 自动启动，closed 自动停止；
+// 默认模板收盘时执行交易日切换（每个交易日一次）
+func (t *Trader) runCalendarSessions() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	trackers := make(map[string]*calendar.Tracker)
+	dayTracker := t.SessionMgr.NewTracker("")
+	var lastRollover time.Time
+
+	for t.IsRunning() {
+		now := <-ticker.C
+
+		if ev, ok := dayTracker.Poll(now); ok {
+			log.Printf("[Trader] Session %s: %s (trading day %s)",
+				ev.Template, ev.Phase, calendar.FormatDay(ev.TradingDay))
+			if ev.Phase == calendar.PhaseClosed && !ev.Initial && !ev.TradingDay.Equal(lastRollover) {
+				t.RolloverTradingDay(ev.TradingDay)
+				lastRollover = ev.TradingDay
+			}
+		}
+
+		if t.StrategyMgr == nil {
+			continue
+		}
+		t.StrategyMgr.ForEach(func(id string, strat strategy.Strategy) {
+			tr, ok := trackers[id]
+			if !ok {
+				symbol := ""
+				if cfg := strat.GetConfig(); cfg != nil && len(cfg.Symbols) > 0 {
+					symbol = cfg.Symbols[0]
+				}
+				tr = t.SessionMgr.NewTracker(symbol)
+				trackers[id] = tr
+			}
+
+			ev, changed := tr.Poll(now)
+			if !changed {
+				return
+			}
+			if sa, ok := strat.(strategy.SessionAware); ok {
+				sa.OnSessionEvent(ev)
+			}
+
+			running := strat.IsRunning()
+			if ev.Phase != calendar.PhaseClosed && !running && t.Config.Session.AutoStart {
+				log.Printf("[Trader] Session %s (%s) - starting strategy %s", ev.Phase, ev.Template, id)
+				if err := strat.Start(); err != nil {
+					log.Printf("[Trader] Error starting strategy %s: %v", id, err)
+				}
+			}
+			if ev.Phase == calendar.PhaseClosed && running && t.Config.Session.AutoStop {
+				log.Printf("[Trader] Session closed (%s) - stopping strategy %s", ev.Template, id)
+				if err := strat.Stop(); err != nil {
+					log.Printf("[Trader] Error stopping strategy %s: %v", id, err)
+				}
+			}
+		})
+	}
+}
+
+// RolloverTradingDay closes the books for a trading day
+// 各策略日终快照、今仓转昨仓、重写 daily_init，并重置风控日内统计；
+// 策略按日终快照判重，重复调用不会二次结转
+func (t *Trader) RolloverTradingDay(tradingDay time.Time) {
+	day := calendar.FormatDay(tradingDay)
+	log.Printf("[Trader] Trading day %s closed, rolling over", day)
+
+	if t.StrategyMgr != nil {
+		t.StrategyMgr.ForEach(func(id string, strat strategy.Strategy) {
+			roller, ok := strat.(strategy.TradingDayRoller)
+			if !ok {
+				return
+			}
+			if _, err := roller.RolloverTradingDay(day); err != nil {
+				log.Printf("[Trader] Error rolling over strategy %s: %v", id, err)
+			}
+		})
+	}
+	if t.Engine != nil {
+		if planner := t.Engine.OffsetPlanner(); planner != nil {
+			planner.Rollover()
+		}
+	}
+	if t.fillJournal != nil {
+		t.rolloverReconcile(tradingDay)
+	}
+	if t.orderJournal != nil {
+		t.rolloverJournal(day)
+	}
+	if t.Accounts != nil {
+		t.Accounts.Rollover()
+		t.saveAccounts(day)
+	}
+	t.RiskManager.ResetDaily()
+}
+
 // runRiskMonitoring monitors risk continuously
 func (t *Trader) runRiskMonitoring() {
 	ticker := time.NewTicker(time.Duration(t.Config.Risk.CheckIntervalMs) * time.Millisecond)
@@ -1164,6 +1398,25 @@ func (t *Trader) onModelReload(newParams map[string]interface{}) error {
 		return fmt.Errorf("strategy manager not initialized")
 	}
 
+	// 经参数工作流生效：原子应用到全部策略并生成可回滚的版本
+	if t.Params != nil {
+		c, err := t.Params.Stage(paramset.StageRequest{
+			Params:        newParams,
+			Author:        "model_watcher",
+			Comment:       "model reload",
+			Source:        t.Config.Strategy.ModelFile,
+			IgnoreUnknown: true,
+		})
+		if err != nil {
+			return err
+		}
+		if c, err = t.applyStaged(c, "model_watcher"); err != nil {
+			return err
+		}
+		log.Printf("[Trader] ✓ Model parameters applied as version %d (change %s)", c.Version, c.ID)
+		return nil
+	}
+
 	log.Printf("[Trader] Applying new parameters to all %d strategies...", t.StrategyMgr.GetStrategyCount())
 	var errs []error
 	t.StrategyMgr.ForEach(func(id string, strat strategy.Strategy) {
@@ -474,6 +474,10 @@ echo ""
 
 mkdir -p log "${DATA_DIR}"
 
+# 交易日历：收盘时自动切换交易日（缺少节假日文件时只排除周末）
+HOLIDAY_ARGS=()
+[ -f config/holidays.txt ] && HOLIDAY_ARGS=(-holidayFile config/holidays.txt)
+
 if [ "$FOREGROUND" = true ]; then
     echo -e "${YELLOW}[INFO]${NC} 前台模式 (Ctrl+C 停止)"
     ./bin/trader --Live \
@@ -486,6 +490,7 @@ if [ "$FOREGROUND" = true ]; then
         -printMod 1 \
         -updateInterval 300000 \
         -logFile "$LOG_FILE" \
+        "${HOLIDAY_ARGS[@]}" \
         2>&1 | tee -a "$LOG_FILE"
 else
     ulimit -c unlimited 2>/dev/null || true
@@ -499,6 +504,7 @@ else
         -printMod 1 \
         -updateInterval 300000 \
         -logFile "$LOG_FILE" \
+        "${HOLIDAY_ARGS[@]}" \
         >> "nohup.out.${STRATEGY_ID}" 2>&1 &
 
     PID=$!
@@ -699,6 +705,13 @@ if [ -d "${MODE_DIR}/config" ]; then
     log_info "  ${DEPLOY_MODE}/config/"
 fi
 
+# 交易所节假日（trader 收盘自动切换交易日使用）
+if [ -f "${PROJECT_ROOT}/config/holidays.txt" ]; then
+    mkdir -p "${DEPLOY_DIR}/config"
+    cp "${PROJECT_ROOT}/config/holidays.txt" "${DEPLOY_DIR}/config/"
+    log_info "  config/holidays.txt"
+fi
+
 # 3. 复制两种模式的数据到 data/sim/ 和 data/live/（保留已有运行时数据）
 for data_mode in sim live; do
     src_data="${DATA_DIR}/${data_mode}/data"
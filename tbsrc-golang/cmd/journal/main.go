@@ -0,0 +1,149 @@
+// journal 写前日志查看/导出工具（Go 扩展）
+// 运行:
+//
+//	go run ./cmd/journal inspect data/journal.92201
+//	go run ./cmd/journal export -format csv data/journal.92201 > journal.csv
+//	go run ./cmd/journal export -after-checkpoint data/journal.92201
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"tbsrc-golang/pkg/journal"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage:
+  journal inspect <file>
+  journal export [-format jsonl|csv] [-after-checkpoint] <file>
+`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "inspect":
+		err = inspect(os.Args[2:])
+	case "export":
+		err = export(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "journal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func inspect(args []string) error {
+	if len(args) != 1 {
+		usage()
+	}
+	recs, res, err := journal.ReadAll(args[0])
+	if err != nil {
+		return err
+	}
+	sum := journal.Summarize(recs)
+
+	fmt.Printf("file:        %s\n", args[0])
+	fmt.Printf("records:     %d (%d bytes)\n", sum.Records, res.ValidSize)
+	if res.TailBytes > 0 {
+		fmt.Printf("torn tail:   %d bytes (%s), truncated on next open\n", res.TailBytes, res.TailError)
+	}
+	if sum.Errors > 0 {
+		fmt.Printf("undecodable: %d\n", sum.Errors)
+	}
+	if sum.Records > 0 {
+		fmt.Printf("time range:  %s — %s\n",
+			sum.First.Format("2006-01-02 15:04:05.000"), sum.Last.Format("2006-01-02 15:04:05.000"))
+	}
+
+	types := make([]string, 0, len(sum.ByType))
+	for t := range sum.ByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Printf("  %-30s %d\n", t, sum.ByType[t])
+	}
+
+	fmt.Printf("checkpoints: %d\n", sum.Checkpoints)
+	if cp := sum.LastCheckpoint; cp != nil {
+		fmt.Printf("last checkpoint: %s day=%s at %s (offset %d)\n",
+			cp.Type, cp.TradingDay, cp.Time.Format("2006-01-02 15:04:05.000"), cp.Offset)
+	}
+	if sum.AfterCheckpoint > 0 {
+		fmt.Printf("pending replay:  %d records after last checkpoint\n", sum.AfterCheckpoint)
+	}
+
+	fmt.Printf("open orders: %d\n", len(sum.OpenOrders))
+	for _, id := range sum.OpenOrders {
+		fmt.Printf("  %d\n", id)
+	}
+
+	syms := make([]string, 0, len(sum.NetTraded))
+	for s := range sum.NetTraded {
+		syms = append(syms, s)
+	}
+	sort.Strings(syms)
+	fmt.Println("net traded:")
+	for _, s := range syms {
+		fmt.Printf("  %-12s %+d\n", s, sum.NetTraded[s])
+	}
+	return nil
+}
+
+func export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "jsonl", "输出格式: jsonl | csv")
+	afterCP := fs.Bool("after-checkpoint", false, "只导出最后一个 checkpoint 之后的记录")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	recs, _, err := journal.ReadAll(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *afterCP {
+		recs = journal.AfterCheckpoint(recs)
+	}
+
+	switch *format {
+	case "jsonl":
+		enc := json.NewEncoder(os.Stdout)
+		for _, rec := range recs {
+			e, err := journal.Decode(rec)
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(journal.EntryHeader)
+		for _, rec := range recs {
+			e, err := journal.Decode(rec)
+			if err != nil {
+				return err
+			}
+			w.Write(e.Fields())
+		}
+		w.Flush()
+		return w.Error()
+	}
+	return fmt.Errorf("unknown format %q", *format)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/fs"
@@ -13,12 +14,17 @@ import (
 	"time"
 
 	"tbsrc-golang/pkg/api"
+	"tbsrc-golang/pkg/attribution"
+	"tbsrc-golang/pkg/calendar"
 	"tbsrc-golang/pkg/client"
 	"tbsrc-golang/pkg/config"
 	"tbsrc-golang/pkg/connector"
 	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/journal"
+	"tbsrc-golang/pkg/offset"
 	"tbsrc-golang/pkg/shm"
 	"tbsrc-golang/pkg/strategy"
+	"tbsrc-golang/pkg/tca"
 	"tbsrc-golang/pkg/types"
 )
 
@@ -51,8 +57,18 @@ func main() {
 	updateInterval := flag.Int("updateInterval", 0, "更新间隔 (C++ --updateInterval)")
 	logFile := flag.String("logFile", "", "日志文件路径 (C++ --logFile)")
 	apiPort := flag.Int("apiPort", 9201, "Web UI / REST API 端口")
+	apiAuthFile := flag.String("apiAuthFile", "", "控制 API 认证配置 (YAML: users/permissions/allowed_origins/tls/audit_file)，空 = 不认证且只监听 127.0.0.1 (Go 扩展)")
 	yearPrefix := flag.String("yearPrefix", "", "年份后两位 (e.g. 26)，用于 baseName→symbol 映射")
 	dataDir := flag.String("dataDir", "./data", "数据目录 (daily_init 等运行时状态，如 ./data/sim 或 ./data/live)")
+	journalOn := flag.Bool("journal", false, "启用写前日志: 记录全部请求/回报，崩溃后重放恢复 (Go 扩展)")
+	journalSyncMs := flag.Int("journalSyncMs", 5, "写前日志定时 fsync 间隔（毫秒）")
+	journalSyncBatch := flag.Int("journalSyncBatch", 64, "写前日志累计多少条记录立即 fsync")
+	orphanPolicy := flag.String("orphanPolicy", "adopt", "启动时柜台遗留在途订单的处理: adopt|cancel|ignore (Go 扩展)")
+	paramsRequireApproval := flag.Bool("paramsRequireApproval", false, "阈值变更需经另一用户审批后才能生效 (Go 扩展)")
+	paramsReapply := flag.Bool("paramsReapply", false, "启动时 model 文件阈值与最新参数版本不一致时恢复最新版本")
+	paramsMaxChangePct := flag.Float64("paramsMaxChangePct", 50, "阈值相对变化超过该百分比时给出风险提示")
+	orphanQueryMs := flag.Int("orphanQueryMs", 3000, "启动时在途订单查询超时（毫秒），0 = 不查询")
+	holidayFile := flag.String("holidayFile", "", "交易所节假日文件（每行 YYYYMMDD），收盘时按交易日历自动切换交易日；空 = 只排除周末 (Go 扩展)")
 
 	flag.Parse()
 
@@ -61,6 +77,10 @@ func main() {
 	_ = updateInterval
 
 	// ---- 验证必须参数 ----
+	orphans, err := strategy.ParseOrphanPolicy(*orphanPolicy)
+	if err != nil {
+		log.Fatalf("[main] --orphanPolicy: %v", err)
+	}
 	if *controlFile == "" {
 		log.Fatal("[main] --controlFile 参数必须")
 	}
@@ -212,6 +232,44 @@ func main() {
 		daily.AvgSpreadOri, daily.NetposYtd1, daily.Netpos2day1, daily.NetposAgg2,
 		daily.OrigBaseName1, daily.OrigBaseName2)
 
+	// ---- 开平仓规划（Go 扩展）----
+	// C++ 由 ORS SetCombOffsetFlag 推断开平；启用后由 Client 按台账和品种费率填写 PosDirection
+	if cfg.Offset.Enabled {
+		planner, err := newOffsetPlanner(&cfg.Offset)
+		if err != nil {
+			log.Fatalf("[main] offset 配置错误: %v", err)
+		}
+		// daily_init 中 ytd 为昨仓；2day 为重启前的今仓（第二腿只有合计，按昨仓处理）
+		planner.SetNetYesterday(sym1, daily.NetposYtd1)
+		if daily.Netpos2day1 != 0 {
+			pos := planner.Position(sym1)
+			if daily.Netpos2day1 > 0 {
+				pos.LongTd = daily.Netpos2day1
+			} else {
+				pos.ShortTd = -daily.Netpos2day1
+			}
+			planner.SetPosition(sym1, pos)
+		}
+		planner.SetNetYesterday(sym2, daily.NetposAgg2)
+		cli.SetOffsetPlanner(planner)
+		log.Printf("[main] 开平仓规划已启用: lock=%s", planner.LockMode())
+	}
+
+	// ---- 写前日志 + 崩溃恢复（Go 扩展）----
+	// C++ 崩溃后只能从上次 SaveMatrix2 的 daily_init 恢复；启用后重放最后一个 checkpoint
+	// （daily_init 保存点）之后的请求/回报，重建两腿订单和持仓，再固化为新的 daily_init
+	if *journalOn {
+		jw, err := openJournal(journal.DefaultPath(*dataDir, cfg.Strategy.StrategyID),
+			journal.Options{
+				SyncInterval: time.Duration(*journalSyncMs) * time.Millisecond,
+				SyncBatch:    *journalSyncBatch,
+			}, pas)
+		if err != nil {
+			log.Fatalf("[main] 写前日志打开失败: %v", err)
+		}
+		defer jw.Close()
+	}
+
 	// ---- 打开 tvar SHM ----
 	var tvar *shm.TVar
 	if thold1.TVarKey > 0 {
@@ -246,6 +304,29 @@ func main() {
 		}
 	}
 	apiServer := api.NewServer(srvPort, webFS)
+	authCfg := &api.AuthConfig{}
+	if *apiAuthFile != "" {
+		if authCfg, err = api.LoadAuthConfig(*apiAuthFile); err != nil {
+			log.Fatalf("[main] --apiAuthFile: %v", err)
+		}
+	}
+	auditFile := filepath.Join(*dataDir, "audit", fmt.Sprintf("api_audit_%d.jsonl", cfg.Strategy.StrategyID))
+	if err := apiServer.ConfigureAuth(authCfg, auditFile); err != nil {
+		log.Fatalf("[main] API 认证配置失败: %v", err)
+	}
+	params, err := newParamsWorkflow(pas, *dataDir, controlCfg, *paramsRequireApproval, *paramsReapply, *paramsMaxChangePct)
+	if err != nil {
+		log.Fatalf("[main] 参数工作流初始化失败: %v", err)
+	}
+	apiServer.SetParams(params.apiConfig())
+	// 逐笔 P&L 归因（Go 扩展），GET /api/v1/attribution
+	pas.Attribution = attribution.NewEngine(0)
+	apiServer.SetAttribution(pas.Attribution)
+	// 成交质量分析（Go 扩展），GET /api/v1/tca
+	tcaEngine := tca.NewEngine(tca.Config{})
+	pas.Leg1.EnableTCA(tcaEngine)
+	pas.Leg2.EnableTCA(tcaEngine)
+	apiServer.SetTCA(tcaEngine)
 	apiServer.Start()
 	defer apiServer.Stop()
 	log.Printf("[main] API Server 已启动: http://localhost:%d/", srvPort)
@@ -254,6 +335,14 @@ func main() {
 	conn.Start()
 	log.Printf("[main] Connector 已启动，开始接收行情和回报")
 
+	// ---- 在途订单恢复（Go 扩展）----
+	// 重启后上一进程的挂单仍在柜台上，但 OrderManager 为空（或仅有写前日志恢复的部分），
+	// 激活前经 ORDERSTATUS 查询，按 -orphanPolicy 接管或撤单，报告写入 dataDir
+	if *orphanQueryMs > 0 {
+		reconcileLiveOrders(pas, conn, orphans, time.Duration(*orphanQueryMs)*time.Millisecond,
+			orphanReportPath(*dataDir, cfg.Strategy.StrategyID))
+	}
+
 	// ---- 激活策略 ----
 	// C++: ExecutionStrategy.cpp:377-380
 	// C++: if (m_configParams->m_modeType == ModeType_Sim) m_Active = true; else m_Active = false;
@@ -265,31 +354,17 @@ func main() {
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM,
 		syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGTSTP)
 
+	// ---- 交易日历（Go 扩展）----
+	// 收盘（当日最后一个时段结束）时自动切换交易日，与 golang trader runCalendarSessions 一致
+	sessions, err := newSessionTracker(*holidayFile, sym1)
+	if err != nil {
+		log.Fatalf("[main] 交易日历加载失败: %v", err)
+	}
+
 	// ---- 快照 ticker (1秒) ----
 	snapshotTicker := time.NewTicker(1 * time.Second)
 	defer snapshotTicker.Stop()
 
-	// ---- 阈值热加载函数 ----
-	// C++: SIGUSR2 触发 LoadThresholds(simConfig) 重新读取 model file
-	modelFilePath := controlCfg.ModelFile
-	reloadThresholds := func() {
-		mc, err := config.ParseModelFile(modelFilePath)
-		if err != nil {
-			log.Printf("[main] 阈值重载失败: %v", err)
-			return
-		}
-		tholdMap := make(map[string]float64, len(mc.Thresholds))
-		for k, v := range mc.Thresholds {
-			f, _ := fmt.Sscanf(v, "%f", new(float64))
-			if f > 0 {
-				var val float64
-				fmt.Sscanf(v, "%f", &val)
-				tholdMap[config.UpperToSnake(k)] = val
-			}
-		}
-		pas.ReloadThresholds(tholdMap, tholdMap)
-	}
-
 	// ---- 主事件循环 ----
 	log.Printf("[main] 进入主事件循环 (信号 + Web UI + 快照)")
 	for {
@@ -303,7 +378,7 @@ func main() {
 			case syscall.SIGUSR2:
 				// C++: LoadThresholds(simConfig)
 				log.Printf("[main] 收到 SIGUSR2，热加载阈值")
-				reloadThresholds()
+				params.reload("signal")
 			case syscall.SIGTSTP:
 				// C++: HandleSquareoff()
 				log.Printf("[main] 收到 SIGTSTP，平仓退出")
@@ -314,9 +389,17 @@ func main() {
 				goto shutdown
 			}
 
-		case <-snapshotTicker.C:
+		case now := <-snapshotTicker.C:
 			snap := api.CollectSnapshot(pas)
 			apiServer.UpdateSnapshot(snap)
+			params.applyDue(now)
+
+			if ev, ok := sessions.Poll(now); ok {
+				log.Printf("[main] 交易时段 %s: %s (交易日 %s)", ev.Template, ev.Phase, calendar.FormatDay(ev.TradingDay))
+				if ev.Phase == calendar.PhaseClosed && !ev.Initial {
+					rolloverTradingDay(pas, cli, calendar.FormatDay(ev.TradingDay))
+				}
+			}
 
 		case cmd := <-apiServer.CommandChan():
 			switch cmd.Type {
@@ -331,7 +414,15 @@ func main() {
 				pas.HandleSquareoff()
 			case "reload_thresholds":
 				log.Printf("[main] Web UI: 热加载阈值")
-				reloadThresholds()
+				params.reload(cmd.User)
+			case "rollover":
+				// Go 扩展：不重启进程完成交易日切换（收盘后由调度脚本调用）
+				day := cmd.Arg
+				if day == "" {
+					day = time.Now().Format("20060102")
+				}
+				log.Printf("[main] Web UI: 交易日切换 %s", day)
+				rolloverTradingDay(pas, cli, day)
 			}
 		}
 	}
@@ -363,6 +454,128 @@ shutdown:
 	log.Printf("[main] 系统关闭完成")
 }
 
+// rolloverTradingDay 交易日切换：策略日终 + 今仓转昨仓，随后开平台账转昨仓并归档写前日志
+// 同一交易日重复调用（收盘自动切换后又手动切换）不做任何修改
+func rolloverTradingDay(pas *strategy.PairwiseArbStrategy, cli *client.Client, day string) {
+	if !pas.RolloverTradingDay(day) {
+		return
+	}
+	if planner := cli.OffsetPlanner(); planner != nil {
+		planner.Rollover()
+	}
+	// daily_init 已保存并写入 checkpoint，归档当日日志
+	if jw := cli.Journal(); jw != nil {
+		if err := jw.Rotate(journal.ArchivePath(jw.Path(), day)); err != nil {
+			log.Printf("[main] 写前日志归档失败: %v", err)
+		}
+	}
+}
+
+// newSessionTracker 按节假日文件和 leg1 品种的时段模板创建收盘跟踪器
+// 节假日文件缺少当年条目时拒绝启动（节假日会被当作交易日，提前或重复切换）
+func newSessionTracker(holidayFile, symbol string) (*calendar.Tracker, error) {
+	cal := calendar.New(nil)
+	if holidayFile != "" {
+		if err := cal.LoadHolidays(holidayFile); err != nil {
+			return nil, err
+		}
+		year := time.Now().In(cal.Location()).Year()
+		if !cal.CoversYear(year) {
+			return nil, fmt.Errorf("holiday file %s has no entries for %d", holidayFile, year)
+		}
+	} else {
+		log.Printf("[main] WARNING: 未配置 -holidayFile，交易日历只排除周末")
+	}
+
+	name, ok := calendar.DefaultProductTemplates[calendar.ProductOf(symbol)]
+	if !ok {
+		name = "day"
+	}
+	tmpl := calendar.DefaultTemplates[name]
+	log.Printf("[main] 交易日历: %s 时段模板 %s，%d 个节假日", symbol, name, cal.NumHolidays())
+	return calendar.NewTracker(cal, tmpl, 0), nil
+}
+
+// openJournal 打开写前日志；上次未正常退出时（最后一个 checkpoint 之后仍有记录）先重放恢复
+func openJournal(path string, opts journal.Options, pas *strategy.PairwiseArbStrategy) (*journal.Writer, error) {
+	recs, res, err := journal.ReadAll(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if res.TailBytes > 0 {
+		log.Printf("[main] 写前日志尾部 %d 字节不完整（%s），已丢弃", res.TailBytes, res.TailError)
+	}
+
+	jw, err := journal.Open(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	pas.Client.SetJournal(jw)
+
+	pending := journal.AfterCheckpoint(recs)
+	if len(pending) == 0 {
+		log.Printf("[main] 写前日志: %s (%d 条记录，无需恢复)", path, res.Records)
+		return jw, nil
+	}
+
+	log.Printf("[main] 写前日志: 上次未正常退出，重放 checkpoint 之后的 %d 条记录", len(pending))
+	st := pas.Recover(pending)
+	if st.OpenOrders > 0 {
+		log.Printf("[main] 恢复后仍有 %d 个在途订单，等待回报", st.OpenOrders)
+	}
+	// 固化恢复结果：写 daily_init 并 checkpoint，之后再崩溃从这里开始重放
+	pas.SaveDailyInit()
+	return jw, nil
+}
+
+// reconcileLiveOrders 查询柜台在途订单并按策略接管/撤单，记录报告
+// 查询失败（如 counter_bridge 不支持 ORDERSTATUS）只告警，不阻止启动
+func reconcileLiveOrders(pas *strategy.PairwiseArbStrategy, conn *connector.Connector,
+	policy strategy.OrphanPolicy, timeout time.Duration, reportPath string) {
+
+	live, err := pas.Client.QueryLiveOrders(timeout)
+	if err != nil {
+		log.Printf("[main] 在途订单查询失败，跳过恢复: %v", err)
+		return
+	}
+	report := pas.ReconcileLiveOrders(live, conn.FirstClientID(), conn.ClientID(), policy)
+	for _, o := range report.Orders {
+		log.Printf("[main] 在途订单 %-8s orderID=%d client=%d %s side=%d price=%.4f open=%d %s",
+			o.Action, o.OrderID, o.ClientID, o.Symbol, o.Side, o.Price, o.OpenQty, o.Reason)
+	}
+	log.Printf("[main] 在途订单恢复: %s", report.Summary())
+
+	data, _ := json.MarshalIndent(report, "", "  ")
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		log.Printf("[main] 在途订单报告写入失败: %v", err)
+	} else {
+		log.Printf("[main] 在途订单报告: %s", reportPath)
+	}
+}
+
+// orphanReportPath 在途订单报告路径：<dataDir>/orphans.<strategyID>.json
+func orphanReportPath(dataDir string, strategyID int) string {
+	return fmt.Sprintf("%s/orphans.%d.json", dataDir, strategyID)
+}
+
+// newOffsetPlanner 按配置创建开平仓规划器（内置费率表 + 配置覆盖）
+func newOffsetPlanner(cfg *config.OffsetConfig) (*offset.Planner, error) {
+	lock, err := offset.ParseLockMode(cfg.Lock)
+	if err != nil {
+		return nil, err
+	}
+	planner := offset.NewPlanner(lock)
+	for product, pc := range cfg.Products {
+		planner.SetProduct(product, offset.Product{
+			Multiplier:     pc.Multiplier,
+			Open:           offset.Fee{Ratio: pc.OpenRatio, PerLot: pc.OpenPerLot},
+			CloseYesterday: offset.Fee{Ratio: pc.CloseYdRatio, PerLot: pc.CloseYdPerLot},
+			CloseToday:     offset.Fee{Ratio: pc.CloseTdRatio, PerLot: pc.CloseTdPerLot},
+		})
+	}
+	return planner, nil
+}
+
 // exchangeTypeFromString 将交易所名称转换为 SHM 代码
 func exchangeTypeFromString(exchange string) uint8 {
 	switch exchange {
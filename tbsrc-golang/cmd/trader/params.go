@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"tbsrc-golang/pkg/api"
+	"tbsrc-golang/pkg/config"
+	"tbsrc-golang/pkg/paramset"
+	"tbsrc-golang/pkg/strategy"
+)
+
+// paramsWorkflow 阈值参数变更工作流（Go 扩展）
+// SIGUSR2 / Web UI 重载与 REST 提交的变更都经过 预览 → (审批) → 生效，生效后生成版本，可回滚
+type paramsWorkflow struct {
+	wf              *paramset.Workflow
+	modelFile       string
+	requireApproval bool
+	startTime       string // HHMM，controlFile 交易开始时间
+	endTime         string // HHMM，controlFile 交易结束时间
+}
+
+// newParamsWorkflow 创建工作流，版本记录保存在 <dataDir>/params/<strategyID>
+// 启动时阈值来自 model 文件，可能与最新版本不一致：reapply 时恢复最新版本，否则只告警
+func newParamsWorkflow(pas *strategy.PairwiseArbStrategy, dataDir string, controlCfg *config.ControlConfig,
+	requireApproval, reapply bool, maxChangePct float64) (*paramsWorkflow, error) {
+
+	dir := filepath.Join(dataDir, "params", strconv.Itoa(int(pas.StrategyID)))
+	maxSize := int64(pas.Thold1.MaxSize)
+	wf, err := paramset.New(strategy.NewParamsTarget(pas), paramset.Options{
+		Dir:             dir,
+		RequireApproval: requireApproval,
+		Limits: func(string) paramset.Limits {
+			return paramset.Limits{MaxPosition: maxSize, MaxChangePct: maxChangePct}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &paramsWorkflow{
+		wf:              wf,
+		modelFile:       controlCfg.ModelFile,
+		requireApproval: requireApproval,
+		startTime:       controlCfg.StartTime,
+		endTime:         controlCfg.EndTime,
+	}
+
+	if drift := wf.Drift(); len(drift) > 0 {
+		if reapply {
+			c, err := wf.Restore("startup")
+			if err != nil {
+				return nil, fmt.Errorf("reapply parameter version: %w", err)
+			}
+			log.Printf("[main] 参数版本已恢复: change=%s version=%d", c.ID, c.Version)
+		} else {
+			for id, diffs := range drift {
+				log.Printf("[main] ⚠️ 策略 %s 阈值与最新版本不一致: %d 项（POST /api/v1/params/rollback 恢复）", id, len(diffs))
+			}
+		}
+	}
+	log.Printf("[main] 参数工作流: %s (versions=%d, require_approval=%v)", dir, len(wf.Versions()), requireApproval)
+	return p, nil
+}
+
+// apiConfig 供 api.Server.SetParams 使用
+func (p *paramsWorkflow) apiConfig() *api.ParamsConfig {
+	return &api.ParamsConfig{
+		Workflow:        p.wf,
+		RequireApproval: p.requireApproval,
+		ResolveApplyAt:  p.resolveApplyAt,
+		StageModelFile:  p.stageModelFile,
+	}
+}
+
+// stageModelFile 解析 model 文件并提交变更（只预览）；path 为空时使用 controlFile 中的 model 文件
+// model 文件中 ThresholdSet 不支持的参数被忽略
+func (p *paramsWorkflow) stageModelFile(path, author, comment string) (*paramset.Change, error) {
+	if path == "" {
+		path = p.modelFile
+	}
+	m, err := loadModelThresholds(path)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		params[k] = v
+	}
+	return p.wf.Stage(paramset.StageRequest{
+		Params:        params,
+		Author:        author,
+		Comment:       comment,
+		Source:        path,
+		IgnoreUnknown: true,
+	})
+}
+
+// reload 重载 model 文件（SIGUSR2 / Web UI），需要审批时只提交不生效
+// C++: LoadThresholds(simConfig)
+func (p *paramsWorkflow) reload(user string) {
+	c, err := p.stageModelFile("", user, "reload thresholds")
+	if err != nil {
+		log.Printf("[main] 阈值重载失败: %v", err)
+		return
+	}
+	for _, diffs := range c.Diffs {
+		for _, d := range diffs {
+			log.Printf("[main]   %s: %v → %v", d.Key, d.Live, d.New)
+		}
+	}
+	if p.requireApproval {
+		log.Printf("[main] 阈值变更 %s 已提交，等待审批 (POST /api/v1/params/changes/%s/approve)", c.ID, c.ID)
+		return
+	}
+	id := c.ID
+	if c, err = p.wf.Apply(id, user, time.Time{}); err != nil {
+		log.Printf("[main] 阈值变更 %s 未生效: %v", id, err)
+		return
+	}
+	log.Printf("[main] 阈值变更 %s 已生效: version=%d", c.ID, c.Version)
+}
+
+// applyDue 执行到期的定时变更（主循环快照 ticker 调用）
+func (p *paramsWorkflow) applyDue(now time.Time) {
+	for _, c := range p.wf.ApplyDue(now) {
+		log.Printf("[main] 定时阈值变更 %s: %s %s", c.ID, c.Status, c.Error)
+	}
+}
+
+// resolveApplyAt now / session_start（下一次 StartTime）/ session_end（下一次 EndTime）/ RFC3339
+func (p *paramsWorkflow) resolveApplyAt(s string) (time.Time, error) {
+	switch s {
+	case "", "now":
+		return time.Time{}, nil
+	case "session_start":
+		return nextClock(p.startTime, time.Now())
+	case "session_end":
+		return nextClock(p.endTime, time.Now())
+	}
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("apply_at must be now, session_start, session_end or RFC3339: %w", err)
+	}
+	return at, nil
+}
+
+// nextClock 返回 now 之后第一个 HHMM 时刻（本地时区）
+func nextClock(hhmm string, now time.Time) (time.Time, error) {
+	t, err := time.Parse("1504", hhmm)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid session time %q: %w", hhmm, err)
+	}
+	at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, nil
+}
+
+// loadModelThresholds 读取 model 文件阈值，键为 ThresholdSet.LoadFromMap 使用的 snake_case
+func loadModelThresholds(path string) (map[string]float64, error) {
+	mc, err := config.ParseModelFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tholdMap := make(map[string]float64, len(mc.Thresholds))
+	for k, v := range mc.Thresholds {
+		var val float64
+		if n, _ := fmt.Sscanf(v, "%f", &val); n > 0 {
+			tholdMap[config.UpperToSnake(k)] = val
+		}
+	}
+	return tholdMap, nil
+}
@@ -6,4 +6,4 @@ toolchain go1.24.5
 
 require gopkg.in/yaml.v3 v3.0.1
 
-require golang.org/x/net v0.50.0 // indirect
+require golang.org/x/net v0.50.0
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"tbsrc-golang/pkg/attribution"
+)
+
+// SetAttribution 启用 /api/v1/attribution 端点（Go 扩展），须在 Start 之前调用
+func (s *Server) SetAttribution(e *attribution.Engine) {
+	s.attribution = e
+}
+
+// GET /api/v1/attribution?limit= — 逐笔 P&L 归因：总计、按策略/小时/阈值档位汇总及最近 round trip
+func (s *Server) handleAttribution(w http.ResponseWriter, r *http.Request) {
+	if s.attribution == nil {
+		writeJSON(w, http.StatusServiceUnavailable, jsonResponse{
+			Success: false,
+			Message: "attribution not configured",
+		})
+		return
+	}
+	rep := s.attribution.Report()
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n < len(rep.RoundTrips) {
+			rep.RoundTrips = rep.RoundTrips[len(rep.RoundTrips)-n:]
+		}
+	}
+	writeJSON(w, http.StatusOK, jsonResponse{Success: true, Data: rep})
+}
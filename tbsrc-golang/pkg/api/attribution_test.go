@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tbsrc-golang/pkg/attribution"
+)
+
+func TestServerAttribution(t *testing.T) {
+	s := NewServer(0, nil)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.handleAttribution(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	if w := get("/api/v1/attribution"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured: code = %d, want 503", w.Code)
+	}
+
+	e := attribution.NewEngine(0)
+	for i := 0; i < 2; i++ {
+		e.OnFill(attribution.Fill{Strategy: "92201", Leg: 1, Buy: true, Qty: 1, Price: 1, Mid: 1})
+		e.OnFill(attribution.Fill{Strategy: "92201", Leg: 2, Qty: 1, Price: 1, Mid: 1})
+		e.OnFill(attribution.Fill{Strategy: "92201", Leg: 1, Qty: 1, Price: 2, Mid: 2})
+		e.OnFill(attribution.Fill{Strategy: "92201", Leg: 2, Buy: true, Qty: 1, Price: 1, Mid: 1})
+	}
+	s.SetAttribution(e)
+
+	w := get("/api/v1/attribution?limit=1")
+	var resp struct {
+		Data attribution.Report `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("code = %d err = %v", w.Code, err)
+	}
+	if resp.Data.Total.RoundTrips != 2 || len(resp.Data.RoundTrips) != 1 || resp.Data.Total.PnL != 2 {
+		t.Errorf("report = %+v", resp.Data)
+	}
+}
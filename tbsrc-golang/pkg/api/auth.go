@@ -0,0 +1,227 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"tbsrc-golang/pkg/auth"
+)
+
+// AuthConfig 控制 API 认证配置（-apiAuthFile，YAML）（Go 扩展）
+// C++ TradeBot 只接受本机信号（kill -10/-12/-20），无网络控制接口
+type AuthConfig struct {
+	Enabled        bool                `yaml:"enabled"`
+	Users          []AuthUserConfig    `yaml:"users"`
+	Permissions    map[string][]string `yaml:"permissions"` // 权限 → 角色列表，覆盖默认表
+	AllowedOrigins []string            `yaml:"allowed_origins"`
+	AuditFile      string              `yaml:"audit_file"`
+	TLS            AuthTLSConfig       `yaml:"tls"`
+}
+
+// AuthUserConfig 用户：token_sha256 / token_env / cert_cn 至少一个
+type AuthUserConfig struct {
+	Name        string `yaml:"name"`
+	Role        string `yaml:"role"` // viewer | trader | risk_officer | admin
+	TokenSHA256 string `yaml:"token_sha256"`
+	TokenEnv    string `yaml:"token_env"` // 从环境变量读取明文 token
+	CertCN      string `yaml:"cert_cn"`
+}
+
+// AuthTLSConfig HTTPS / mTLS
+type AuthTLSConfig struct {
+	CertFile          string `yaml:"cert_file"`
+	KeyFile           string `yaml:"key_file"`
+	ClientCAFile      string `yaml:"client_ca_file"`
+	RequireClientCert bool   `yaml:"require_client_cert"`
+}
+
+// LoadAuthConfig 读取认证配置文件
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read auth config: %w", err)
+	}
+	var cfg AuthConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse auth config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// ConfigureAuth 启用认证、授权与审计日志，须在 Start 之前调用
+// cfg.AuditFile 为空时使用 defaultAuditFile；认证关闭时只监听 127.0.0.1、不返回 CORS 头，
+// 所有本机请求按 admin 处理，控制请求仍写入审计日志
+func (s *Server) ConfigureAuth(cfg *AuthConfig, defaultAuditFile string) error {
+	users := make([]auth.User, 0, len(cfg.Users))
+	for _, u := range cfg.Users {
+		role, err := auth.ParseRole(u.Role)
+		if err != nil {
+			return fmt.Errorf("auth users[%s]: %w", u.Name, err)
+		}
+		hash := u.TokenSHA256
+		if u.TokenEnv != "" {
+			if hash, err = auth.TokenFromEnv(u.TokenEnv); err != nil {
+				return fmt.Errorf("auth users[%s]: %w", u.Name, err)
+			}
+		}
+		users = append(users, auth.User{Name: u.Name, Role: role, TokenSHA256: hash, CertCN: u.CertCN})
+	}
+
+	overrides := make(map[auth.Permission][]auth.Role, len(cfg.Permissions))
+	for perm, names := range cfg.Permissions {
+		for _, n := range names {
+			role, err := auth.ParseRole(n)
+			if err != nil {
+				return fmt.Errorf("auth permissions[%s]: %w", perm, err)
+			}
+			overrides[auth.Permission(perm)] = append(overrides[auth.Permission(perm)], role)
+		}
+	}
+
+	authn, err := auth.NewAuthenticator(cfg.Enabled, users, overrides)
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+
+	auditPath := cfg.AuditFile
+	if auditPath == "" {
+		auditPath = defaultAuditFile
+	}
+	audit, err := auth.OpenAuditLog(auditPath)
+	if err != nil {
+		return err
+	}
+	if n, err := audit.Verify(); err != nil {
+		log.Printf("[API] 审计日志 %s 校验失败（前 %d 条正常）: %v", auditPath, n, err)
+	}
+
+	if cfg.TLS.CertFile != "" {
+		tlsCfg, err := auth.ServerTLSConfig(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, cfg.TLS.RequireClientCert)
+		if err != nil {
+			audit.Close()
+			return err
+		}
+		s.tlsConfig = tlsCfg
+	}
+
+	s.audit = audit
+	s.guard = &auth.Guard{
+		Auth:           authn,
+		Audit:          audit,
+		AllowedOrigins: cfg.AllowedOrigins,
+		Public:         map[string]bool{"/api/v1/health": true},
+		Resolve:        routePermission,
+	}
+
+	if cfg.Enabled {
+		log.Printf("[API] 认证已启用: users=%d tls=%v mtls=%v audit=%s",
+			len(users), s.tlsConfig != nil, cfg.TLS.ClientCAFile != "", auditPath)
+	} else {
+		log.Printf("[API] 认证未启用，控制端点只监听 %s (audit=%s)", auth.LoopbackHost, auditPath)
+	}
+	return nil
+}
+
+// defaultGuard 未调用 ConfigureAuth 时的中间件：不认证、不审计、无 CORS，服务只监听本机
+func defaultGuard() *auth.Guard {
+	authn, _ := auth.NewAuthenticator(false, nil, nil)
+	return &auth.Guard{Auth: authn, Resolve: routePermission}
+}
+
+// routePermission 端点 → 所需权限
+func routePermission(r *http.Request) auth.Permission {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		if r.URL.Path == "/api/v1/audit" {
+			return auth.PermAuditRead
+		}
+		return auth.PermRead
+	}
+	switch r.URL.Path {
+	case "/api/v1/strategy/activate":
+		return auth.PermActivate
+	case "/api/v1/strategy/deactivate":
+		return auth.PermDeactivate
+	case "/api/v1/strategy/squareoff":
+		return auth.PermSquareoff
+	case "/api/v1/strategy/reload-thresholds":
+		return auth.PermThresholdsReload
+	case "/api/v1/strategy/rollover":
+		return auth.PermRollover
+	case "/api/v1/params/rollback":
+		return auth.PermParamsRollback
+	}
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/api/v1/params/changes"); ok {
+		if strings.HasSuffix(rest, "/approve") {
+			return auth.PermParamsApprove
+		}
+		return auth.PermThresholdsReload
+	}
+	return auth.PermAdmin
+}
+
+// GET /api/v1/audit?user=&action=&since=&until=&limit=&verify=true — 查询审计日志
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if s.audit == nil {
+		writeJSON(w, http.StatusServiceUnavailable, jsonResponse{
+			Success: false,
+			Message: "audit log not configured",
+		})
+		return
+	}
+
+	q := r.URL.Query()
+	filter := auth.AuditFilter{User: q.Get("user"), Action: auth.Permission(q.Get("action")), Limit: 100}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, jsonResponse{
+					Success: false,
+					Message: name + " must be RFC3339",
+				})
+				return
+			}
+			*dst = t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, jsonResponse{
+				Success: false,
+				Message: "limit must be a non-negative integer",
+			})
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, err := s.audit.Query(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, jsonResponse{
+			Success: false,
+			Message: fmt.Sprintf("read audit log: %v", err),
+		})
+		return
+	}
+	data := map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	}
+	if q.Get("verify") == "true" {
+		n, err := s.audit.Verify()
+		data["verified_entries"] = n
+		data["chain_ok"] = err == nil
+		if err != nil {
+			data["chain_error"] = err.Error()
+		}
+	}
+	writeJSON(w, http.StatusOK, jsonResponse{Success: true, Data: data})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"tbsrc-golang/pkg/auth"
+)
+
+func TestServerAuth(t *testing.T) {
+	s := NewServer(0, nil)
+	cfg := &AuthConfig{
+		Enabled: true,
+		Users: []AuthUserConfig{
+			{Name: "vic", Role: "viewer", TokenSHA256: auth.HashToken("t-vic")},
+			{Name: "rob", Role: "risk-officer", TokenSHA256: auth.HashToken("t-rob")},
+		},
+	}
+	if err := s.ConfigureAuth(cfg, filepath.Join(t.TempDir(), "audit.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	defer s.audit.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/strategy/squareoff", s.handleSquareoff)
+	mux.HandleFunc("POST /api/v1/strategy/reload-thresholds", s.handleReloadThresholds)
+	mux.HandleFunc("GET /api/v1/audit", s.handleAudit)
+	h := s.guard.Wrap(mux)
+
+	do := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := do("POST", "/api/v1/strategy/squareoff", "t-vic"); code != http.StatusForbidden {
+		t.Errorf("viewer squareoff = %d", code)
+	}
+	if code := do("POST", "/api/v1/strategy/squareoff", "t-rob"); code != http.StatusOK {
+		t.Errorf("risk officer squareoff = %d", code)
+	}
+	if cmd := <-s.CommandChan(); cmd.Type != "squareoff" {
+		t.Errorf("command = %+v", cmd)
+	}
+	if code := do("POST", "/api/v1/strategy/reload-thresholds", "t-rob"); code != http.StatusForbidden {
+		t.Errorf("risk officer reload = %d", code)
+	}
+	if code := do("GET", "/api/v1/audit", "t-vic"); code != http.StatusForbidden {
+		t.Errorf("viewer audit = %d", code)
+	}
+	if code := do("GET", "/api/v1/audit?verify=true", "t-rob"); code != http.StatusOK {
+		t.Errorf("risk officer audit = %d", code)
+	}
+
+	entries, _ := s.audit.Query(auth.AuditFilter{})
+	if len(entries) != 3 || entries[1].User != "rob" || !entries[1].Success || entries[2].Success {
+		t.Errorf("audit entries = %+v", entries)
+	}
+}
@@ -3,6 +3,9 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
+
+	"tbsrc-golang/pkg/auth"
 )
 
 // jsonResponse 通用 JSON 响应
@@ -14,7 +17,6 @@ type jsonResponse struct {
 
 func writeJSON(w http.ResponseWriter, status int, resp jsonResponse) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(resp)
 }
@@ -116,7 +118,7 @@ func (s *Server) handleSquareoff(w http.ResponseWriter, r *http.Request) {
 // POST /api/v1/strategy/reload-thresholds — 对应 kill -12 (SIGUSR2)
 func (s *Server) handleReloadThresholds(w http.ResponseWriter, r *http.Request) {
 	select {
-	case s.cmdChan <- Command{Type: "reload_thresholds"}:
+	case s.cmdChan <- Command{Type: "reload_thresholds", User: auth.IdentityFrom(r).Name}:
 		writeJSON(w, http.StatusOK, jsonResponse{
 			Success: true,
 			Message: "reload_thresholds command sent",
@@ -128,3 +130,29 @@ func (s *Server) handleReloadThresholds(w http.ResponseWriter, r *http.Request)
 		})
 	}
 }
+
+// POST /api/v1/strategy/rollover?trading_day=YYYYMMDD — 交易日切换（今仓转昨仓、重写 daily_init）
+func (s *Server) handleRollover(w http.ResponseWriter, r *http.Request) {
+	day := r.URL.Query().Get("trading_day")
+	if day != "" {
+		if _, err := time.Parse("20060102", day); err != nil {
+			writeJSON(w, http.StatusBadRequest, jsonResponse{
+				Success: false,
+				Message: "trading_day must be YYYYMMDD",
+			})
+			return
+		}
+	}
+	select {
+	case s.cmdChan <- Command{Type: "rollover", Arg: day}:
+		writeJSON(w, http.StatusOK, jsonResponse{
+			Success: true,
+			Message: "rollover command sent",
+		})
+	default:
+		writeJSON(w, http.StatusServiceUnavailable, jsonResponse{
+			Success: false,
+			Message: "command channel full",
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tbsrc-golang/pkg/auth"
+	"tbsrc-golang/pkg/paramset"
+)
+
+// ParamsConfig 参数变更工作流（Go 扩展，见 SetParams）
+type ParamsConfig struct {
+	Workflow        *paramset.Workflow
+	RequireApproval bool
+	// ResolveApplyAt 解析 apply_at（now / session_start / session_end / RFC3339），由 main 按交易时段实现
+	ResolveApplyAt func(string) (time.Time, error)
+	// StageModelFile 从 model 文件提交变更（空 path = 当前 model 文件）
+	StageModelFile func(path, author, comment string) (*paramset.Change, error)
+}
+
+// SetParams 启用 /api/v1/params 端点，须在 Start 之前调用
+func (s *Server) SetParams(cfg *ParamsConfig) {
+	s.params = cfg
+}
+
+// stageParamsRequest 提交参数变更：params 与 model_file 互斥
+type stageParamsRequest struct {
+	Params    map[string]interface{} `json:"params"`
+	ModelFile string                 `json:"model_file"`
+	UseModel  bool                   `json:"use_model"` // 使用当前 model 文件
+	Comment   string                 `json:"comment"`
+}
+
+type applyParamsRequest struct {
+	ApplyAt string `json:"apply_at"`
+}
+
+type rollbackParamsRequest struct {
+	Version int    `json:"version"`
+	Comment string `json:"comment"`
+}
+
+func (s *Server) paramsUnavailable(w http.ResponseWriter) bool {
+	if s.params != nil && s.params.Workflow != nil {
+		return false
+	}
+	writeJSON(w, http.StatusServiceUnavailable, jsonResponse{
+		Success: false,
+		Message: "parameter workflow not configured",
+	})
+	return true
+}
+
+// GET /api/v1/params?limit= — 最近的变更、全部版本与漂移
+func (s *Server) handleParams(w http.ResponseWriter, r *http.Request) {
+	if s.paramsUnavailable(w) {
+		return
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			limit = n
+		}
+	}
+	wf := s.params.Workflow
+	writeJSON(w, http.StatusOK, jsonResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"require_approval": s.params.RequireApproval,
+			"changes":          wf.Changes(limit),
+			"versions":         wf.Versions(),
+			"drift":            wf.Drift(),
+		},
+	})
+}
+
+// POST /api/v1/params/changes — 提交变更（只预览：返回 diff、校验与风险提示）
+func (s *Server) handleStageParams(w http.ResponseWriter, r *http.Request) {
+	if s.paramsUnavailable(w) {
+		return
+	}
+	var req stageParamsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, jsonResponse{
+			Success: false,
+			Message: fmt.Sprintf("invalid request body: %v", err),
+		})
+		return
+	}
+	user := auth.IdentityFrom(r).Name
+
+	var c *paramset.Change
+	var err error
+	switch {
+	case (req.ModelFile != "" || req.UseModel) && len(req.Params) > 0:
+		err = fmt.Errorf("model_file cannot be combined with params")
+	case req.ModelFile != "" || req.UseModel:
+		c, err = s.params.StageModelFile(req.ModelFile, user, req.Comment)
+	default:
+		c, err = s.params.Workflow.Stage(paramset.StageRequest{
+			Params:  req.Params,
+			Author:  user,
+			Comment: req.Comment,
+			Source:  "api",
+		})
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, jsonResponse{
+			Success: false,
+			Message: fmt.Sprintf("stage change: %v", err),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonResponse{
+		Success: true,
+		Message: fmt.Sprintf("change %s staged", c.ID),
+		Data:    c,
+	})
+}
+
+// GET /api/v1/params/changes/{id}
+func (s *Server) handleParamsChange(w http.ResponseWriter, r *http.Request) {
+	if s.paramsUnavailable(w) {
+		return
+	}
+	c, err := s.params.Workflow.Change(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, jsonResponse{Success: false, Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonResponse{Success: true, Data: c})
+}
+
+// POST /api/v1/params/changes/{id}/{action} — approve / apply（body: apply_at）/ cancel
+func (s *Server) handleParamsAction(w http.ResponseWriter, r *http.Request) {
+	if s.paramsUnavailable(w) {
+		return
+	}
+	wf := s.params.Workflow
+	id, user := r.PathValue("id"), auth.IdentityFrom(r).Name
+
+	var c *paramset.Change
+	var err error
+	status := http.StatusBadRequest
+	switch action := r.PathValue("action"); action {
+	case "approve":
+		c, err = wf.Approve(id, user)
+	case "cancel":
+		c, err = wf.Cancel(id, user)
+	case "apply":
+		var req applyParamsRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, jsonResponse{
+					Success: false,
+					Message: fmt.Sprintf("invalid request body: %v", err),
+				})
+				return
+			}
+		}
+		var at time.Time
+		if at, err = s.params.ResolveApplyAt(req.ApplyAt); err == nil {
+			c, err = wf.Apply(id, user, at)
+			if c != nil && c.Status == paramset.StatusFailed {
+				status = http.StatusConflict
+			}
+		}
+	default:
+		writeJSON(w, http.StatusNotFound, jsonResponse{
+			Success: false,
+			Message: fmt.Sprintf("unknown action: %s", action),
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, status, jsonResponse{Success: false, Message: err.Error(), Data: c})
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonResponse{
+		Success: true,
+		Message: fmt.Sprintf("change %s %s", id, c.Status),
+		Data:    c,
+	})
+}
+
+// POST /api/v1/params/rollback — 回滚到指定版本（0 = 工作流启用前的初始值）
+func (s *Server) handleParamsRollback(w http.ResponseWriter, r *http.Request) {
+	if s.paramsUnavailable(w) {
+		return
+	}
+	var req rollbackParamsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, jsonResponse{
+			Success: false,
+			Message: fmt.Sprintf("invalid request body: %v", err),
+		})
+		return
+	}
+	c, err := s.params.Workflow.Rollback(req.Version, auth.IdentityFrom(r).Name, req.Comment)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, jsonResponse{
+			Success: false,
+			Message: fmt.Sprintf("rollback: %v", err),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonResponse{
+		Success: true,
+		Message: fmt.Sprintf("rolled back to version %d", req.Version),
+		Data:    c,
+	})
+}
+
+// GET /api/v1/params/versions
+func (s *Server) handleParamsVersions(w http.ResponseWriter, r *http.Request) {
+	if s.paramsUnavailable(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonResponse{Success: true, Data: s.params.Workflow.Versions()})
+}
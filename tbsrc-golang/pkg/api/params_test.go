@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tbsrc-golang/pkg/paramset"
+)
+
+type fakeThresholds map[string]interface{}
+
+func (f fakeThresholds) StrategyIDs() []string { return []string{"92201"} }
+
+func (f fakeThresholds) Parameters(id string) (map[string]interface{}, error) {
+	if id != "92201" {
+		return nil, fmt.Errorf("strategy %s not found", id)
+	}
+	out := make(map[string]interface{}, len(f))
+	for k, v := range f {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (f fakeThresholds) Apply(id string, params map[string]interface{}) error {
+	for k, v := range params {
+		f[k] = v
+	}
+	return nil
+}
+
+func TestServerParams(t *testing.T) {
+	target := fakeThresholds{"begin_place": 0.5, "begin_remove": 0.2, "size": 1.0, "max_size": 10.0}
+	wf, err := paramset.New(target, paramset.Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(0, nil)
+	s.SetParams(&ParamsConfig{
+		Workflow:       wf,
+		ResolveApplyAt: func(string) (time.Time, error) { return time.Time{}, nil },
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/params/changes", s.handleStageParams)
+	mux.HandleFunc("POST /api/v1/params/changes/{id}/{action}", s.handleParamsAction)
+	mux.HandleFunc("POST /api/v1/params/rollback", s.handleParamsRollback)
+
+	post := func(path string, body interface{}) (int, paramset.Change) {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+		var resp struct {
+			Data paramset.Change `json:"data"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp.Data
+	}
+
+	code, c := post("/api/v1/params/changes", stageParamsRequest{Params: map[string]interface{}{"begin_place": 0.6}})
+	if code != http.StatusOK || len(c.Diffs["92201"]) != 1 || target["begin_place"] != 0.5 {
+		t.Fatalf("stage = %d, %+v", code, c)
+	}
+	if code, c = post("/api/v1/params/changes/"+c.ID+"/apply", nil); code != http.StatusOK || target["begin_place"] != 0.6 {
+		t.Fatalf("apply = %d, %+v", code, c)
+	}
+	if code, _ = post("/api/v1/params/rollback", rollbackParamsRequest{Version: 0}); code != http.StatusOK || target["begin_place"] != 0.5 {
+		t.Fatalf("rollback = %d, begin_place = %v", code, target["begin_place"])
+	}
+
+	// begin_place <= begin_remove：校验失败，不能生效
+	_, bad := post("/api/v1/params/changes", stageParamsRequest{Params: map[string]interface{}{"begin_place": 0.1}})
+	if code, _ = post("/api/v1/params/changes/"+bad.ID+"/apply", nil); code == http.StatusOK || target["begin_place"] != 0.5 {
+		t.Errorf("invalid change applied: %d", code)
+	}
+}
@@ -2,7 +2,7 @@ package api
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
 	"io/fs"
 	"log"
 	"net/http"
@@ -10,11 +10,17 @@ import (
 	"time"
 
 	"golang.org/x/net/websocket"
+
+	"tbsrc-golang/pkg/attribution"
+	"tbsrc-golang/pkg/auth"
+	"tbsrc-golang/pkg/tca"
 )
 
 // Command 从 Web UI 发到 main goroutine 的控制命令
 type Command struct {
-	Type string // "activate", "deactivate", "squareoff", "reload_thresholds"
+	Type string // "activate", "deactivate", "squareoff", "reload_thresholds", "rollover"
+	Arg  string // rollover: 交易日 YYYYMMDD（空表示当天）
+	User string // 发起请求的 API 用户（reload_thresholds 记入参数版本）
 }
 
 // Server HTTP + WebSocket 服务
@@ -26,6 +32,20 @@ type Server struct {
 	port       int
 	webFS      fs.FS // 静态文件（从外部传入，由 main 包 embed）
 
+	// 认证、授权与审计（Go 扩展，见 ConfigureAuth）
+	guard     *auth.Guard
+	audit     *auth.AuditLog
+	tlsConfig *tls.Config
+
+	// 参数变更工作流（Go 扩展，见 SetParams）
+	params *ParamsConfig
+
+	// 逐笔 P&L 归因（Go 扩展，见 SetAttribution）
+	attribution *attribution.Engine
+
+	// 成交质量分析（Go 扩展，见 SetTCA）
+	tca *tca.Engine
+
 	// 订单历史追踪器（每个 leg 各一个）
 	leg1History *OrderHistoryTracker
 	leg2History *OrderHistoryTracker
@@ -58,6 +78,16 @@ func (s *Server) Start() {
 	mux.HandleFunc("POST /api/v1/strategy/deactivate", s.handleDeactivate)
 	mux.HandleFunc("POST /api/v1/strategy/squareoff", s.handleSquareoff)
 	mux.HandleFunc("POST /api/v1/strategy/reload-thresholds", s.handleReloadThresholds)
+	mux.HandleFunc("POST /api/v1/strategy/rollover", s.handleRollover)
+	mux.HandleFunc("GET /api/v1/audit", s.handleAudit)
+	mux.HandleFunc("GET /api/v1/params", s.handleParams)
+	mux.HandleFunc("POST /api/v1/params/changes", s.handleStageParams)
+	mux.HandleFunc("GET /api/v1/params/changes/{id}", s.handleParamsChange)
+	mux.HandleFunc("POST /api/v1/params/changes/{id}/{action}", s.handleParamsAction)
+	mux.HandleFunc("GET /api/v1/params/versions", s.handleParamsVersions)
+	mux.HandleFunc("POST /api/v1/params/rollback", s.handleParamsRollback)
+	mux.HandleFunc("GET /api/v1/attribution", s.handleAttribution)
+	mux.HandleFunc("GET /api/v1/tca", s.handleTCA)
 
 	// WebSocket
 	mux.Handle("/ws", websocket.Handler(s.hub.HandleWebSocket))
@@ -68,14 +98,24 @@ func (s *Server) Start() {
 		mux.Handle("/", fileServer)
 	}
 
+	if s.guard == nil {
+		s.guard = defaultGuard()
+	}
 	s.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
+		Addr:      auth.ListenAddr("", s.guard.Auth.Enabled(), s.port),
+		Handler:   s.guard.Wrap(mux),
+		TLSConfig: s.tlsConfig,
 	}
 
 	go func() {
-		log.Printf("[API] Server starting on :%d", s.port)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("[API] Server starting on %s (tls=%v)", s.httpServer.Addr, s.tlsConfig != nil)
+		var err error
+		if s.tlsConfig != nil {
+			err = s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = s.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Printf("[API] Server error: %v", err)
 		}
 	}()
@@ -90,6 +130,9 @@ func (s *Server) Stop() {
 		s.httpServer.Shutdown(ctx)
 		log.Printf("[API] Server stopped")
 	}
+	if s.audit != nil {
+		s.audit.Close()
+	}
 }
 
 // UpdateSnapshot 原子更新快照并广播到 WebSocket
@@ -19,6 +19,10 @@ type DashboardSnapshot struct {
 	Leg1       LegSnapshot    `json:"leg1"`
 	Leg2       LegSnapshot    `json:"leg2"`
 	Exposure   int32          `json:"exposure"` // NetExposure()
+
+	// 价差作为一个对象的货值敞口（ExposureValue()）
+	ExposureValue float64 `json:"exposure_value"`
+	GrossExposure float64 `json:"gross_exposure"`
 }
 
 // SpreadSnapshot 价差分析
@@ -30,6 +34,13 @@ type SpreadSnapshot struct {
 	Deviation float64 `json:"deviation"`
 	IsValid   bool    `json:"is_valid"`
 	Alpha     float64 `json:"alpha"`
+
+	// 卡尔曼价差模式（SPREAD_MODE=1），EWA 模式下 Beta=1、残差为 0
+	Type        int     `json:"type"` // SPREAD_TYPE: 0 diff, 1 ratio, 2 log
+	Mode        int     `json:"mode"`
+	Beta        float64 `json:"beta"`
+	Residual    float64 `json:"residual"`
+	ResidualVar float64 `json:"residual_var"`
 }
 
 // LegSnapshot 单腿完整状态
@@ -174,6 +185,9 @@ func CollectSnapshot(pas *strategy.PairwiseArbStrategy) *DashboardSnapshot {
 		Account:    pas.Account,
 		Exposure:   pas.NetExposure(),
 	}
+	if pas.Def != nil {
+		snap.ExposureValue, snap.GrossExposure = pas.ExposureValue()
+	}
 
 	// 价差快照
 	if pas.Spread != nil {
@@ -185,6 +199,12 @@ func CollectSnapshot(pas *strategy.PairwiseArbStrategy) *DashboardSnapshot {
 			Deviation: pas.Spread.Deviation(),
 			IsValid:   pas.Spread.IsValid,
 			Alpha:     pas.Spread.Alpha,
+
+			Type:        pas.Spread.Type,
+			Mode:        pas.Spread.Mode,
+			Beta:        pas.Spread.Beta,
+			Residual:    pas.Spread.Residual,
+			ResidualVar: pas.Spread.ResidualVar,
 		}
 	}
 
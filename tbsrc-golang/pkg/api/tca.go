@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"tbsrc-golang/pkg/tca"
+)
+
+// SetTCA 启用 /api/v1/tca 端点（Go 扩展），须在 Start 之前调用
+func (s *Server) SetTCA(e *tca.Engine) {
+	s.tca = e
+}
+
+// GET /api/v1/tca?format=json|csv|fills_csv&limit= — 成交质量分析
+// json: 汇总与最近 limit 笔成交；csv: 汇总表；fills_csv: 逐笔成交
+func (s *Server) handleTCA(w http.ResponseWriter, r *http.Request) {
+	if s.tca == nil {
+		writeJSON(w, http.StatusServiceUnavailable, jsonResponse{
+			Success: false,
+			Message: "tca not configured",
+		})
+		return
+	}
+	rep := s.tca.Report()
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n < len(rep.Fills) {
+			rep.Fills = rep.Fills[len(rep.Fills)-n:]
+		}
+	}
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, jsonResponse{Success: true, Data: rep})
+	case "csv", "fills_csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		write := tca.WriteSummaryCSV
+		if format == "fills_csv" {
+			write = tca.WriteFillsCSV
+		}
+		write(w, rep)
+	default:
+		writeJSON(w, http.StatusBadRequest, jsonResponse{
+			Success: false,
+			Message: "format must be json, csv or fills_csv",
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tbsrc-golang/pkg/tca"
+)
+
+func TestServerTCA(t *testing.T) {
+	s := NewServer(0, nil)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.handleTCA(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	if w := get("/api/v1/tca"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unconfigured: code = %d, want 503", w.Code)
+	}
+
+	e := tca.NewEngine(tca.Config{})
+	e.OnOrder(tca.Order{ID: "1", Symbol: "ag2506", Buy: true, Qty: 1, Price: 100, Time: time.Now(), Bid: 100, Ask: 102})
+	e.OnFill("1", time.Now(), 1, 100, 101)
+	s.SetTCA(e)
+
+	if w := get("/api/v1/tca"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"liquidity":"passive"`) {
+		t.Errorf("json: code = %d body = %s", w.Code, w.Body.String())
+	}
+	w := get("/api/v1/tca?format=fills_csv")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") || !strings.HasPrefix(w.Body.String(), "order_id,") {
+		t.Errorf("fills_csv: content-type = %q body = %s", ct, w.Body.String())
+	}
+	if w := get("/api/v1/tca?format=xml"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad format: code = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,315 @@
+// Package attribution 配对套利逐笔 P&L 归因（Go 扩展）
+//
+// 被动腿（leg1）成交开出一笔敞口，对冲腿（leg2）成交按 FIFO 配对到等待对冲的敞口；
+// leg1 反向成交平掉最早的敞口，再由 leg2 反向成交完成平仓对冲，四次成交齐全即为一个 round trip。
+// 每个 round trip 分解为（均以金额计，买卖方向 s=+1/-1，合约乘数 m，成交时本腿 mid）：
+//
+//	SpreadCapture  = Σ leg1 成交 s·m·q·(mid − price)    被动报价相对 mid 的收益
+//	HedgeSlippage  = Σ leg2 成交 s·m·q·(mid − price)    对冲相对 mid 的滑点（通常为负）
+//	Drift          = Σ 全部成交 −s·m·q·mid              持仓期间两腿 mid 变动（mark-to-market）
+//	Fees           = Σ 手续费
+//	PnL            = SpreadCapture + HedgeSlippage + Drift − Fees
+//
+// EntryEdge = s·m·q·(公允价差 − 开仓价差) 是开仓阈值带来的理论优势，不计入 PnL，
+// 与 Drift 对比可判断亏损来自阈值过窄还是持仓期间价差不回归。
+package attribution
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fill 一次成交及成交时的市场状态
+type Fill struct {
+	Time       time.Time
+	Strategy   string
+	Leg        int // 1 = 被动腿（报价），2 = 对冲腿
+	Buy        bool
+	Qty        int32
+	Price      float64
+	Mid        float64 // 成交时本腿 mid
+	Multiplier float64 // 合约乘数，0 视为 1
+	Fee        float64
+	Passive    bool
+
+	// 仅 leg1 开仓时使用
+	Spread     float64 // 成交时价差（按 mid）
+	FairSpread float64 // 成交时价差均值（avgSpread）
+	Level      string  // 阈值档位（如开仓阈值），用于分组
+}
+
+// RoundTrip 一个完整的开平仓配对
+type RoundTrip struct {
+	Strategy      string    `json:"strategy"`
+	Side          string    `json:"side"` // long = leg1 买入开仓
+	Qty           int32     `json:"qty"`
+	OpenTime      time.Time `json:"open_time"`
+	CloseTime     time.Time `json:"close_time"`
+	Level         string    `json:"level"`
+	Passive       bool      `json:"passive"` // leg1 开仓为被动成交
+	EntryEdge     float64   `json:"entry_edge"`
+	SpreadCapture float64   `json:"spread_capture"`
+	HedgeSlippage float64   `json:"hedge_slippage"`
+	Drift         float64   `json:"drift"`
+	Fees          float64   `json:"fees"`
+	PnL           float64   `json:"pnl"`
+}
+
+// Bucket 分组汇总
+type Bucket struct {
+	Key           string  `json:"key"`
+	RoundTrips    int     `json:"round_trips"`
+	Qty           int64   `json:"qty"`
+	EntryEdge     float64 `json:"entry_edge"`
+	SpreadCapture float64 `json:"spread_capture"`
+	HedgeSlippage float64 `json:"hedge_slippage"`
+	Drift         float64 `json:"drift"`
+	Fees          float64 `json:"fees"`
+	PnL           float64 `json:"pnl"`
+}
+
+func (b *Bucket) add(rt *RoundTrip) {
+	b.RoundTrips++
+	b.Qty += int64(rt.Qty)
+	b.EntryEdge += rt.EntryEdge
+	b.SpreadCapture += rt.SpreadCapture
+	b.HedgeSlippage += rt.HedgeSlippage
+	b.Drift += rt.Drift
+	b.Fees += rt.Fees
+	b.PnL += rt.PnL
+}
+
+// Report 归因报告
+type Report struct {
+	Total       Bucket      `json:"total"`
+	ByStrategy  []Bucket    `json:"by_strategy"`
+	ByHour      []Bucket    `json:"by_hour"`  // 开仓时刻所在小时（"09" …）
+	ByLevel     []Bucket    `json:"by_level"` // 开仓阈值档位
+	RoundTrips  []RoundTrip `json:"round_trips"`
+	OpenQty     int64       `json:"open_qty"`     // 尚未完成配对的 leg1 数量
+	UnpairedQty int64       `json:"unpaired_qty"` // 找不到对应敞口的 leg2 成交数量
+}
+
+// lot 一段数量相同、状态相同的敞口；部分成交时按数量拆分
+type lot struct {
+	side      int // leg1 开仓方向 +1 买 / -1 卖
+	qty       int32
+	openTime  time.Time
+	level     string
+	passive   bool
+	entryHdg  bool // 开仓已对冲
+	exitLeg1  bool // leg1 已平
+	exitHdg   bool // 平仓已对冲
+	closeTime time.Time
+
+	entryEdge, capture, slippage, drift, fees float64
+}
+
+// split 拆出 q 手（累计金额按数量比例分配），原 lot 保留剩余部分
+func (l *lot) split(q int32) *lot {
+	f := float64(q) / float64(l.qty)
+	n := *l
+	n.qty = q
+	n.entryEdge, n.capture, n.slippage, n.drift, n.fees =
+		l.entryEdge*f, l.capture*f, l.slippage*f, l.drift*f, l.fees*f
+	l.qty -= q
+	l.entryEdge -= n.entryEdge
+	l.capture -= n.capture
+	l.slippage -= n.slippage
+	l.drift -= n.drift
+	l.fees -= n.fees
+	return &n
+}
+
+// Engine 归因引擎，按策略维护未完成的敞口；并发安全
+type Engine struct {
+	mu       sync.Mutex
+	lots     map[string][]*lot
+	done     []RoundTrip
+	maxKept  int
+	total    Bucket
+	byStrat  map[string]*Bucket
+	byHour   map[string]*Bucket
+	byLevel  map[string]*Bucket
+	unpaired int64
+}
+
+// NewEngine 创建归因引擎；maxKept 为报告中保留的最近 round trip 数（汇总不受影响），<=0 时为 500
+func NewEngine(maxKept int) *Engine {
+	if maxKept <= 0 {
+		maxKept = 500
+	}
+	return &Engine{
+		lots:    make(map[string][]*lot),
+		maxKept: maxKept,
+		byStrat: make(map[string]*Bucket),
+		byHour:  make(map[string]*Bucket),
+		byLevel: make(map[string]*Bucket),
+	}
+}
+
+// OnFill 记录一次成交
+func (e *Engine) OnFill(f Fill) {
+	if f.Qty <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := -1
+	if f.Buy {
+		s = 1
+	}
+	m := f.Multiplier
+	if m == 0 {
+		m = 1
+	}
+	// 每手的各项金额
+	edge := float64(s) * m * (f.Mid - f.Price)
+	drift := -float64(s) * m * f.Mid
+	fee := f.Fee / float64(f.Qty)
+
+	lots := e.lots[f.Strategy]
+	remaining := f.Qty
+	var out []*lot
+	// 依次匹配最早的符合条件的敞口
+	match := func(ok func(*lot) bool, apply func(*lot)) {
+		for i := 0; i < len(lots) && remaining > 0; i++ {
+			l := lots[i]
+			if !ok(l) {
+				continue
+			}
+			if l.qty > remaining {
+				// 拆出匹配部分放在原位置之前，保持 FIFO
+				head := l.split(remaining)
+				lots = append(lots[:i], append([]*lot{head}, lots[i:]...)...)
+				l = head
+			}
+			q := float64(l.qty)
+			apply(l)
+			l.drift += drift * q
+			l.fees += fee * q
+			remaining -= l.qty
+		}
+	}
+
+	if f.Leg == 1 {
+		// 反向成交：平掉最早的开仓
+		match(func(l *lot) bool { return l.side == -s && !l.exitLeg1 }, func(l *lot) {
+			l.exitLeg1 = true
+			l.closeTime = f.Time
+			l.capture += edge * float64(l.qty)
+		})
+		if remaining > 0 {
+			q := float64(remaining)
+			lots = append(lots, &lot{
+				side:      s,
+				qty:       remaining,
+				openTime:  f.Time,
+				level:     f.Level,
+				passive:   f.Passive,
+				entryEdge: float64(s) * m * (f.FairSpread - f.Spread) * q,
+				capture:   edge * q,
+				drift:     drift * q,
+				fees:      fee * q,
+			})
+			remaining = 0
+		}
+	} else {
+		// 开仓对冲方向与 leg1 相反，平仓对冲方向与 leg1 相同
+		match(func(l *lot) bool {
+			return (!l.entryHdg && l.side == -s) || (l.exitLeg1 && !l.exitHdg && l.side == s)
+		}, func(l *lot) {
+			if !l.entryHdg && l.side == -s {
+				l.entryHdg = true
+			} else {
+				l.exitHdg = true
+			}
+			l.slippage += edge * float64(l.qty)
+		})
+		e.unpaired += int64(remaining)
+	}
+
+	// 完成的 round trip 移出
+	for _, l := range lots {
+		if l.entryHdg && l.exitLeg1 && l.exitHdg {
+			e.complete(f.Strategy, l)
+		} else {
+			out = append(out, l)
+		}
+	}
+	e.lots[f.Strategy] = out
+}
+
+func (e *Engine) complete(strategy string, l *lot) {
+	side := "long"
+	if l.side < 0 {
+		side = "short"
+	}
+	rt := RoundTrip{
+		Strategy:      strategy,
+		Side:          side,
+		Qty:           l.qty,
+		OpenTime:      l.openTime,
+		CloseTime:     l.closeTime,
+		Level:         l.level,
+		Passive:       l.passive,
+		EntryEdge:     l.entryEdge,
+		SpreadCapture: l.capture,
+		HedgeSlippage: l.slippage,
+		Drift:         l.drift,
+		Fees:          l.fees,
+		PnL:           l.capture + l.slippage + l.drift - l.fees,
+	}
+	e.total.add(&rt)
+	bucket(e.byStrat, strategy).add(&rt)
+	bucket(e.byHour, fmt.Sprintf("%02d", l.openTime.Hour())).add(&rt)
+	bucket(e.byLevel, l.level).add(&rt)
+
+	e.done = append(e.done, rt)
+	if n := len(e.done) - e.maxKept; n > 0 {
+		e.done = e.done[n:]
+	}
+}
+
+func bucket(m map[string]*Bucket, key string) *Bucket {
+	b, ok := m[key]
+	if !ok {
+		b = &Bucket{Key: key}
+		m[key] = b
+	}
+	return b
+}
+
+// Report 生成当前归因报告（分组按 key 排序）
+func (e *Engine) Report() *Report {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := &Report{
+		Total:       e.total,
+		ByStrategy:  sortedBuckets(e.byStrat),
+		ByHour:      sortedBuckets(e.byHour),
+		ByLevel:     sortedBuckets(e.byLevel),
+		RoundTrips:  append([]RoundTrip(nil), e.done...),
+		UnpairedQty: e.unpaired,
+	}
+	r.Total.Key = "total"
+	for _, lots := range e.lots {
+		for _, l := range lots {
+			r.OpenQty += int64(l.qty)
+		}
+	}
+	return r
+}
+
+func sortedBuckets(m map[string]*Bucket) []Bucket {
+	out := make([]Bucket, 0, len(m))
+	for _, b := range m {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
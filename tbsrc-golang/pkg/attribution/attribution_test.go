@@ -0,0 +1,88 @@
+package attribution
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestEngine_RoundTripDecomposition(t *testing.T) {
+	e := NewEngine(0)
+	t0 := time.Date(2026, 3, 2, 9, 15, 0, 0, time.Local)
+
+	// 被动买入 leg1（低于 mid 0.5），价差低于均值 0.5
+	e.OnFill(Fill{Time: t0, Strategy: "92201", Leg: 1, Buy: true, Qty: 2, Price: 100, Mid: 100.5,
+		Multiplier: 10, Fee: 1, Passive: true, Spread: -99.5, FairSpread: -99, Level: "0.50"})
+	// 主动卖出对冲，低于 mid 0.1
+	e.OnFill(Fill{Time: t0, Strategy: "92201", Leg: 2, Qty: 2, Price: 199.9, Mid: 200, Multiplier: 10, Fee: 1})
+	if r := e.Report(); r.Total.RoundTrips != 0 || r.OpenQty != 2 {
+		t.Fatalf("open round trip reported: %+v", r.Total)
+	}
+	// 被动卖出平仓，高于 mid 0.5；对冲买入高于 mid 0.1
+	t1 := t0.Add(10 * time.Minute)
+	e.OnFill(Fill{Time: t1, Strategy: "92201", Leg: 1, Qty: 2, Price: 101.5, Mid: 101, Multiplier: 10, Fee: 1, Passive: true})
+	e.OnFill(Fill{Time: t1, Strategy: "92201", Leg: 2, Buy: true, Qty: 2, Price: 200.1, Mid: 200, Multiplier: 10, Fee: 1})
+
+	r := e.Report()
+	if len(r.RoundTrips) != 1 || r.OpenQty != 0 || r.UnpairedQty != 0 {
+		t.Fatalf("report = %+v", r)
+	}
+	rt := r.RoundTrips[0]
+	// leg1 (101.5-100)*10*2 = 30，leg2 (199.9-200.1)*10*2 = -4，手续费 4
+	if !near(rt.PnL, 22) || !near(rt.SpreadCapture, 20) || !near(rt.HedgeSlippage, -4) ||
+		!near(rt.Drift, 10) || !near(rt.Fees, 4) || !near(rt.EntryEdge, 10) {
+		t.Errorf("round trip = %+v", rt)
+	}
+	if rt.Side != "long" || rt.Level != "0.50" || !rt.Passive || !rt.CloseTime.Equal(t1) {
+		t.Errorf("round trip meta = %+v", rt)
+	}
+	if len(r.ByHour) != 1 || r.ByHour[0].Key != "09" || len(r.ByLevel) != 1 || r.ByStrategy[0].Key != "92201" {
+		t.Errorf("buckets = %+v / %+v / %+v", r.ByHour, r.ByLevel, r.ByStrategy)
+	}
+}
+
+func TestEngine_PartialFillsSplitFIFO(t *testing.T) {
+	e := NewEngine(0)
+	now := time.Now()
+	fill := func(leg int, buy bool, qty int32, price float64) {
+		e.OnFill(Fill{Time: now, Strategy: "s", Leg: leg, Buy: buy, Qty: qty, Price: price, Mid: price})
+	}
+
+	fill(1, false, 3, 100) // 卖出开仓 3 手
+	fill(2, true, 1, 50)   // 分两次对冲
+	fill(2, true, 2, 50)
+	fill(1, true, 2, 99) // 平 2 手
+	fill(2, false, 2, 50)
+
+	r := e.Report()
+	// 对冲分两次成交，开仓被拆成 1+2 手，平仓 2 手对应两段
+	if r.Total.RoundTrips != 2 || r.Total.Qty != 2 || r.OpenQty != 1 {
+		t.Fatalf("total = %+v open=%d", r.Total, r.OpenQty)
+	}
+	// 价格等于 mid：全部收益来自 drift，(100-99)*2 = 2
+	if !near(r.Total.PnL, 2) || !near(r.Total.Drift, 2) || r.RoundTrips[0].Side != "short" {
+		t.Errorf("total = %+v", r.Total)
+	}
+
+	// 剩余 1 手已对冲，没有等待对冲的敞口
+	fill(2, true, 4, 50)
+	if r := e.Report(); r.UnpairedQty != 4 {
+		t.Errorf("unpaired = %d, want 4", r.UnpairedQty)
+	}
+}
+
+func TestEngine_KeepsRecentRoundTrips(t *testing.T) {
+	e := NewEngine(2)
+	for i := 0; i < 3; i++ {
+		e.OnFill(Fill{Strategy: "s", Leg: 1, Buy: true, Qty: 1, Price: 1, Mid: 1})
+		e.OnFill(Fill{Strategy: "s", Leg: 2, Qty: 1, Price: 1, Mid: 1})
+		e.OnFill(Fill{Strategy: "s", Leg: 1, Qty: 1, Price: 2, Mid: 2})
+		e.OnFill(Fill{Strategy: "s", Leg: 2, Buy: true, Qty: 1, Price: 1, Mid: 1})
+	}
+	r := e.Report()
+	if len(r.RoundTrips) != 2 || r.Total.RoundTrips != 3 || !near(r.Total.PnL, 3) {
+		t.Errorf("kept %d, total %+v", len(r.RoundTrips), r.Total)
+	}
+}
@@ -0,0 +1,114 @@
+// Package auction 集合竞价撮合（Go 扩展）
+//
+// 按交易所集合竞价规则由买卖盘计算指示性均衡价：
+//
+//  1. 取成交量最大的价格；
+//  2. 多个价格成交量相同时，取未成交量（|买量 − 卖量|）最小者；
+//  3. 仍有多个时取最接近参考价（昨收/最新价）者，无参考价时取这些价格的中间。
+//
+// 实盘策略用它估算开盘价和买卖失衡，回测撮合用同一套规则模拟开盘撮合。
+package auction
+
+import (
+	"math"
+	"sort"
+)
+
+// Level 一档委托
+type Level struct {
+	Price float64
+	Qty   int64
+}
+
+// Result 指示性撮合结果
+type Result struct {
+	Price     float64 // 均衡价（Matched == 0 时为 0）
+	Matched   int64   // 该价格可成交量
+	Imbalance int64   // 该价格的未成交量，正数为买方剩余，负数为卖方剩余
+}
+
+// Valid 是否存在可成交的均衡价
+func (r Result) Valid() bool {
+	return r.Matched > 0 && r.Price > 0
+}
+
+// ImbalanceRatio 失衡比例 Imbalance / (Matched + |Imbalance|)，范围 [-1, 1]
+func (r Result) ImbalanceRatio() float64 {
+	total := r.Matched + abs64(r.Imbalance)
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Imbalance) / float64(total)
+}
+
+// Uncross 计算指示性均衡价，bids/asks 无需排序，ref <= 0 表示无参考价
+func Uncross(bids, asks []Level, ref float64) Result {
+	prices := make([]float64, 0, len(bids)+len(asks))
+	for _, l := range bids {
+		if l.Price > 0 && l.Qty > 0 {
+			prices = append(prices, l.Price)
+		}
+	}
+	for _, l := range asks {
+		if l.Price > 0 && l.Qty > 0 {
+			prices = append(prices, l.Price)
+		}
+	}
+	sort.Float64s(prices)
+
+	var best Result
+	var tied []Result
+	for i, p := range prices {
+		if i > 0 && p == prices[i-1] {
+			continue
+		}
+		var demand, supply int64
+		for _, l := range bids {
+			if l.Qty > 0 && l.Price >= p {
+				demand += l.Qty
+			}
+		}
+		for _, l := range asks {
+			if l.Qty > 0 && l.Price > 0 && l.Price <= p {
+				supply += l.Qty
+			}
+		}
+		r := Result{Price: p, Matched: min(demand, supply), Imbalance: demand - supply}
+		switch {
+		case r.Matched == 0:
+			continue
+		case len(tied) == 0 || r.Matched > best.Matched ||
+			(r.Matched == best.Matched && abs64(r.Imbalance) < abs64(best.Imbalance)):
+			best = r
+			tied = append(tied[:0], r)
+		case r.Matched == best.Matched && abs64(r.Imbalance) == abs64(best.Imbalance):
+			tied = append(tied, r)
+		}
+	}
+	if len(tied) <= 1 {
+		return best
+	}
+
+	if ref <= 0 {
+		ref = (tied[0].Price + tied[len(tied)-1].Price) / 2
+	}
+	best = tied[0]
+	for _, r := range tied[1:] {
+		if math.Abs(r.Price-ref) < math.Abs(best.Price-ref) {
+			best = r
+		}
+	}
+	return best
+}
+
+// Crossed 买一 >= 卖一：连续交易中不会出现，只在集合竞价的指示性盘口中出现
+func Crossed(bid, ask float64) bool {
+	return bid > 0 && ask > 0 && bid >= ask
+}
+
+func abs64(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
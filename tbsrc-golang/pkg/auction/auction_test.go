@@ -0,0 +1,59 @@
+package auction
+
+import (
+	"math"
+	"testing"
+)
+
+// TestUncross_MaxVolume 取成交量最大的价格，记录剩余量方向
+func TestUncross_MaxVolume(t *testing.T) {
+	bids := []Level{{101, 10}, {100, 20}, {99, 30}}
+	asks := []Level{{98, 15}, {100, 10}, {102, 40}}
+	r := Uncross(bids, asks, 0)
+	if r.Price != 100 || r.Matched != 25 || r.Imbalance != 5 {
+		t.Fatalf("got %+v, want 100/25/+5", r)
+	}
+	if math.Abs(r.ImbalanceRatio()-5.0/30) > 1e-9 {
+		t.Errorf("ImbalanceRatio = %.4f", r.ImbalanceRatio())
+	}
+}
+
+// TestUncross_MinImbalance 成交量相同时取未成交量最小者
+func TestUncross_MinImbalance(t *testing.T) {
+	bids := []Level{{102, 10}, {100, 5}}
+	asks := []Level{{99, 10}, {101, 5}}
+	// 100: 买 15 卖 10 → 10/+5；101: 买 10 卖 15 → 10/-5；102: 买 10 卖 15 → 10/-5；99: 买 15 卖 10 → 10/+5
+	// 全部成交 10、失衡 5，按中间价 100.5 取最接近者（先出现的 100）
+	r := Uncross(bids, asks, 0)
+	if r.Price != 100 || r.Matched != 10 {
+		t.Fatalf("got %+v, want 100/10", r)
+	}
+	bids = append(bids, Level{101, 5})
+	// 101: 买 15 卖 15 → 15/0 唯一最优
+	if r = Uncross(bids, asks, 0); r.Price != 101 || r.Matched != 15 || r.Imbalance != 0 {
+		t.Fatalf("got %+v, want 101/15/0", r)
+	}
+}
+
+// TestUncross_Reference 多个价格完全相同时取最接近参考价者
+func TestUncross_Reference(t *testing.T) {
+	bids := []Level{{100, 10}}
+	asks := []Level{{98, 10}}
+	if r := Uncross(bids, asks, 99.9); r.Price != 100 {
+		t.Errorf("ref 99.9: got %.1f, want 100", r.Price)
+	}
+	if r := Uncross(bids, asks, 98.2); r.Price != 98 {
+		t.Errorf("ref 98.2: got %.1f, want 98", r.Price)
+	}
+}
+
+// TestUncross_NoCross 买卖不交叉时无均衡价
+func TestUncross_NoCross(t *testing.T) {
+	r := Uncross([]Level{{99, 10}}, []Level{{100, 10}}, 0)
+	if r.Valid() {
+		t.Errorf("got %+v, want invalid", r)
+	}
+	if !Crossed(100, 100) || Crossed(99, 100) || Crossed(0, 100) {
+		t.Error("Crossed mismatch")
+	}
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEntry 审计记录（每个控制请求一条，包括被拒绝的请求）
+// Hash = sha256(PrevHash + 记录 JSON（Hash 为空）)，任何记录被修改或删除都会使后续链校验失败
+type AuditEntry struct {
+	Seq      int64             `json:"seq"`
+	Time     time.Time         `json:"time"`
+	User     string            `json:"user"`
+	Role     Role              `json:"role,omitempty"`
+	AuthBy   string            `json:"auth_by,omitempty"` // token / mtls / none
+	Remote   string            `json:"remote"`
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Action   Permission        `json:"action"`
+	Params   map[string]string `json:"params,omitempty"` // 查询参数
+	Body     string            `json:"body,omitempty"`   // 请求体（截断）
+	Status   int               `json:"status"`
+	Success  bool              `json:"success"`
+	Result   string            `json:"result,omitempty"` // 响应消息/错误（截断）
+	PrevHash string            `json:"prev_hash"`
+	Hash     string            `json:"hash"`
+}
+
+// AuditFilter 审计日志查询条件（零值 = 不限）
+type AuditFilter struct {
+	User   string
+	Action Permission
+	Since  time.Time
+	Until  time.Time
+	Limit  int // 返回最近的 Limit 条
+}
+
+func (f *AuditFilter) match(e *AuditEntry) bool {
+	switch {
+	case f.User != "" && e.User != f.User:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && e.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// AuditLog 只追加的审计日志（JSONL，每条写入后 fsync）
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	seq  int64
+	last string // 最后一条记录的 Hash
+}
+
+// OpenAuditLog 打开（或创建）审计日志，从最后一条记录继续序号和哈希链
+func OpenAuditLog(path string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create audit dir: %w", err)
+	}
+	l := &AuditLog{path: path}
+	err := scanAudit(path, func(e *AuditEntry) error {
+		l.seq, l.last = e.Seq, e.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	l.f = f
+	return l, nil
+}
+
+// Path 返回日志文件路径
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Append 追加一条记录（填充 Seq/PrevHash/Hash）
+func (l *AuditLog) Append(e AuditEntry) (AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return e, errors.New("audit log closed")
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Seq = l.seq + 1
+	e.PrevHash = l.last
+	e.Hash = ""
+	h, err := entryHash(&e)
+	if err != nil {
+		return e, err
+	}
+	e.Hash = h
+
+	data, err := json.Marshal(&e)
+	if err != nil {
+		return e, err
+	}
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		return e, fmt.Errorf("write audit log: %w", err)
+	}
+	if err := l.f.Sync(); err != nil {
+		return e, fmt.Errorf("sync audit log: %w", err)
+	}
+	l.seq, l.last = e.Seq, e.Hash
+	return e, nil
+}
+
+// Query 按条件查询，按时间顺序返回
+func (l *AuditLog) Query(f AuditFilter) ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []AuditEntry
+	err := scanAudit(l.path, func(e *AuditEntry) error {
+		if f.match(e) {
+			out = append(out, *e)
+		}
+		return nil
+	})
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[len(out)-f.Limit:]
+	}
+	return out, err
+}
+
+// Verify 校验哈希链，返回记录数；链断裂时返回第一条异常记录的错误
+func (l *AuditLog) Verify() (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return VerifyAuditFile(l.path)
+}
+
+// Close 关闭日志
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// VerifyAuditFile 校验审计日志文件的序号与哈希链
+func VerifyAuditFile(path string) (int64, error) {
+	var n int64
+	prev := ""
+	err := scanAudit(path, func(e *AuditEntry) error {
+		n++
+		if e.Seq != n {
+			return fmt.Errorf("entry %d: seq %d out of order", n, e.Seq)
+		}
+		if e.PrevHash != prev {
+			return fmt.Errorf("entry %d: prev_hash mismatch", e.Seq)
+		}
+		want := e.Hash
+		e.Hash = ""
+		h, err := entryHash(e)
+		if err != nil {
+			return err
+		}
+		if h != want {
+			return fmt.Errorf("entry %d: hash mismatch (record modified)", e.Seq)
+		}
+		prev = want
+		return nil
+	})
+	return n, err
+}
+
+func entryHash(e *AuditEntry) (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(e.PrevHash), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// scanAudit 逐条读取审计日志；文件不存在时不报错
+func scanAudit(path string, fn func(*AuditEntry) error) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,261 @@
+// Package auth provides role-based authentication for the trader control API
+// and a tamper-evident audit log of control actions (Go 扩展)
+//
+// 身份来源：Authorization: Bearer <token>（配置中只保存 token 的 SHA-256），
+// 或 mTLS 客户端证书的 CommonName。每个端点对应一个权限，权限 → 角色表可由配置覆盖。
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Role 角色
+type Role string
+
+const (
+	RoleViewer      Role = "viewer"       // 只读
+	RoleTrader      Role = "trader"       // 策略启停、模型/阈值重载、平仓
+	RoleRiskOfficer Role = "risk_officer" // 停止策略、平仓、对账、查看审计日志
+	RoleAdmin       Role = "admin"        // 全部权限
+)
+
+// ParseRole 解析角色名（risk-officer 与 risk_officer 等价）
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ReplaceAll(strings.ToLower(s), "-", "_")); r {
+	case RoleViewer, RoleTrader, RoleRiskOfficer, RoleAdmin:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q (viewer|trader|risk_officer|admin)", s)
+}
+
+// Permission 端点权限
+type Permission string
+
+const (
+	PermRead             Permission = "read"                 // 状态/持仓/行情查询
+	PermActivate         Permission = "strategy.activate"    // 激活策略
+	PermDeactivate       Permission = "strategy.deactivate"  // 停止策略（不平仓）
+	PermSquareoff        Permission = "strategy.squareoff"   // 平仓
+	PermModelReload      Permission = "model.reload"         // 模型/参数热加载
+	PermThresholdsReload Permission = "thresholds.reload"    // 阈值重载
+	PermRollover         Permission = "trading_day.rollover" // 交易日切换
+	PermReconcile        Permission = "reconcile"            // 立即对账
+	PermAuditRead        Permission = "audit.read"           // 查询审计日志
+	PermParamsApprove    Permission = "params.approve"       // 审批参数变更
+	PermParamsRollback   Permission = "params.rollback"      // 参数回滚
+	PermAlgoSubmit       Permission = "algo.submit"          // 下达执行算法母单
+	PermAlgoCancel       Permission = "algo.cancel"          // 撤销执行算法母单
+	PermAdmin            Permission = "admin"                // 测试/调试端点
+)
+
+// DefaultPermissions 默认权限表（admin 拥有全部权限，无需列出）
+func DefaultPermissions() map[Permission][]Role {
+	return map[Permission][]Role{
+		PermRead:             {RoleViewer, RoleTrader, RoleRiskOfficer},
+		PermActivate:         {RoleTrader},
+		PermDeactivate:       {RoleTrader, RoleRiskOfficer},
+		PermSquareoff:        {RoleTrader, RoleRiskOfficer},
+		PermModelReload:      {RoleTrader},
+		PermThresholdsReload: {RoleTrader},
+		PermRollover:         {RoleRiskOfficer},
+		PermReconcile:        {RoleRiskOfficer},
+		PermAuditRead:        {RoleRiskOfficer},
+		PermParamsApprove:    {RoleRiskOfficer},
+		PermParamsRollback:   {RoleTrader, RoleRiskOfficer},
+		PermAlgoSubmit:       {RoleTrader},
+		PermAlgoCancel:       {RoleTrader, RoleRiskOfficer},
+		PermAdmin:            {},
+	}
+}
+
+// Identity 已认证的调用方
+type Identity struct {
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	Method string `json:"method"` // token / mtls / none
+}
+
+// User 配置的用户：token_sha256 与 cert_cn 至少一个
+type User struct {
+	Name        string
+	Role        Role
+	TokenSHA256 string // hex(sha256(token))
+	CertCN      string // mTLS 客户端证书 CommonName
+}
+
+var (
+	// ErrUnauthenticated 缺少或无效的凭证
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden 角色无此权限
+	ErrForbidden = errors.New("permission denied")
+)
+
+// Authenticator 认证与授权
+type Authenticator struct {
+	enabled     bool
+	byToken     map[string]User // sha256 hex → user
+	byCN        map[string]User
+	permissions map[Permission][]Role
+}
+
+// NewAuthenticator 创建认证器；enabled=false 时所有请求以 admin 身份通过（兼容旧部署）
+// overrides 覆盖默认权限表中的对应权限
+func NewAuthenticator(enabled bool, users []User, overrides map[Permission][]Role) (*Authenticator, error) {
+	a := &Authenticator{
+		enabled:     enabled,
+		byToken:     make(map[string]User),
+		byCN:        make(map[string]User),
+		permissions: DefaultPermissions(),
+	}
+	for p, roles := range overrides {
+		if _, ok := a.permissions[p]; !ok {
+			return nil, fmt.Errorf("unknown permission %q", p)
+		}
+		a.permissions[p] = roles
+	}
+
+	names := make(map[string]bool, len(users))
+	for _, u := range users {
+		if u.Name == "" {
+			return nil, fmt.Errorf("user name is required")
+		}
+		if names[u.Name] {
+			return nil, fmt.Errorf("duplicate user %s", u.Name)
+		}
+		names[u.Name] = true
+		if u.TokenSHA256 == "" && u.CertCN == "" {
+			return nil, fmt.Errorf("user %s: token_sha256 or cert_cn is required", u.Name)
+		}
+		if u.TokenSHA256 != "" {
+			h := strings.ToLower(u.TokenSHA256)
+			if b, err := hex.DecodeString(h); err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("user %s: token_sha256 must be 64 hex characters", u.Name)
+			}
+			a.byToken[h] = u
+		}
+		if u.CertCN != "" {
+			a.byCN[u.CertCN] = u
+		}
+	}
+	if enabled && len(users) == 0 {
+		return nil, fmt.Errorf("auth enabled but no users configured")
+	}
+	return a, nil
+}
+
+// Enabled 是否启用认证
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// HashToken 返回 token 的 SHA-256（hex），用于生成配置
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenCookie 浏览器页面使用的 token cookie（以 ?token= 打开页面后由 Guard 设置）
+const TokenCookie = "api_token"
+
+// Authenticate 识别请求的调用方
+// 顺序：mTLS 已验证证书 → Authorization: Bearer → ?token= → cookie（WebSocket/浏览器页面无法设置请求头）
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if !a.enabled {
+		return &Identity{Name: "anonymous", Role: RoleAdmin, Method: "none"}, nil
+	}
+
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		if u, ok := a.byCN[cn]; ok {
+			return &Identity{Name: u.Name, Role: u.Role, Method: "mtls"}, nil
+		}
+	}
+
+	token := ""
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		token = strings.TrimSpace(h[len("Bearer "):])
+	} else if q := r.URL.Query().Get("token"); q != "" {
+		token = q
+	} else if c, err := r.Cookie(TokenCookie); err == nil {
+		token = c.Value
+	}
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	sum := HashToken(token)
+	for h, u := range a.byToken {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(sum)) == 1 {
+			return &Identity{Name: u.Name, Role: u.Role, Method: "token"}, nil
+		}
+	}
+	return nil, ErrUnauthenticated
+}
+
+type identityKey struct{}
+
+// WithIdentity 将调用方身份存入 context（Guard 授权通过后设置）
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFrom 返回请求的调用方身份
+// 未经 Guard 的请求返回无角色的未认证身份（不具备任何权限），是否放行由 Guard 决定
+func IdentityFrom(r *http.Request) *Identity {
+	if id, ok := r.Context().Value(identityKey{}).(*Identity); ok {
+		return id
+	}
+	return &Identity{Name: "unauthenticated", Method: "none"}
+}
+
+// Allowed 角色是否拥有权限
+func (a *Authenticator) Allowed(role Role, perm Permission) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	for _, r := range a.permissions[perm] {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize 认证并检查权限
+func (a *Authenticator) Authorize(r *http.Request, perm Permission) (*Identity, error) {
+	id, err := a.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Allowed(id.Role, perm) {
+		return id, fmt.Errorf("%w: role %s lacks %s", ErrForbidden, id.Role, perm)
+	}
+	return id, nil
+}
+
+// Permissions 返回当前权限表（API 展示用）
+func (a *Authenticator) Permissions() map[Permission][]Role {
+	out := make(map[Permission][]Role, len(a.permissions))
+	for p, roles := range a.permissions {
+		out[p] = append([]Role{RoleAdmin}, roles...)
+		sort.Slice(out[p], func(i, j int) bool { return out[p][i] < out[p][j] })
+	}
+	return out
+}
+
+// TokenFromEnv 读取环境变量中的明文 token 并返回其 SHA-256（配置 token_env 时使用）
+func TokenFromEnv(name string) (string, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return "", fmt.Errorf("environment variable %s is empty", name)
+	}
+	return HashToken(v), nil
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestGuard(t *testing.T) (*Guard, *AuditLog) {
+	t.Helper()
+	a, err := NewAuthenticator(true, []User{
+		{Name: "alice", Role: RoleTrader, TokenSHA256: HashToken("t-alice")},
+		{Name: "rob", Role: RoleRiskOfficer, TokenSHA256: HashToken("t-rob")},
+		{Name: "vic", Role: RoleViewer, TokenSHA256: HashToken("t-vic")},
+	}, map[Permission][]Role{PermActivate: {RoleTrader, RoleRiskOfficer}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err := OpenAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { audit.Close() })
+	return &Guard{
+		Auth:           a,
+		Audit:          audit,
+		AllowedOrigins: []string{"http://ops.local"},
+		Public:         map[string]bool{"/health": true},
+		Resolve: func(r *http.Request) Permission {
+			switch r.URL.Path {
+			case "/squareoff":
+				return PermSquareoff
+			case "/activate":
+				return PermActivate
+			case "/reload":
+				return PermModelReload
+			}
+			return PermRead
+		},
+	}, audit
+}
+
+func TestGuard(t *testing.T) {
+	g, audit := newTestGuard(t)
+	h := g.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"message":"done"}`))
+	}))
+
+	cases := []struct {
+		method, path, token string
+		want                int
+	}{
+		{"GET", "/health", "", http.StatusOK},
+		{"GET", "/status", "", http.StatusUnauthorized},
+		{"GET", "/status", "bad", http.StatusUnauthorized},
+		{"GET", "/status", "t-vic", http.StatusOK},
+		{"POST", "/squareoff?leg=all", "t-vic", http.StatusForbidden},
+		{"POST", "/squareoff?leg=all", "t-rob", http.StatusOK},
+		{"POST", "/activate", "t-rob", http.StatusOK}, // 配置覆盖
+		{"POST", "/reload", "t-rob", http.StatusForbidden},
+		{"POST", "/reload", "t-alice", http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(`{"x":1}`))
+		req.Header.Set("Origin", "http://evil.local")
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Errorf("%s %s as %q = %d, want %d", c.method, c.path, c.token, w.Code, c.want)
+		}
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("origin not in allow list should not get CORS headers")
+		}
+	}
+
+	// 5 次控制请求（含 2 次被拒），只读请求不记录
+	all, err := audit.Query(AuditFilter{})
+	if err != nil || len(all) != 5 {
+		t.Fatalf("audit entries = %d, %v", len(all), err)
+	}
+	e := all[1]
+	if e.User != "rob" || e.Action != PermSquareoff || !e.Success || e.Params["leg"] != "all" ||
+		e.Body != `{"x":1}` || e.Result != "done" {
+		t.Errorf("entry = %+v", e)
+	}
+	if all[0].Success || all[0].Status != http.StatusForbidden || all[0].User != "vic" {
+		t.Errorf("denied entry = %+v", all[0])
+	}
+	if got, _ := audit.Query(AuditFilter{User: "alice"}); len(got) != 1 {
+		t.Errorf("query by user = %d entries", len(got))
+	}
+	if got, _ := audit.Query(AuditFilter{Limit: 2}); len(got) != 2 || got[1].Seq != 5 {
+		t.Errorf("query limit = %+v", got)
+	}
+}
+
+func TestAuditLogChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{"a", "b"} {
+		if _, err := l.Append(AuditEntry{User: u, Action: PermSquareoff}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	// 重新打开后继续序号与哈希链
+	l, err = OpenAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, err := l.Append(AuditEntry{User: "c", Action: PermActivate}); err != nil || e.Seq != 3 {
+		t.Fatalf("append after reopen = %+v, %v", e, err)
+	}
+	if n, err := l.Verify(); err != nil || n != 3 {
+		t.Fatalf("Verify = %d, %v", n, err)
+	}
+	l.Close()
+
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), `"user":"b"`, `"user":"x"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyAuditFile(path); err == nil || !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("tampered log Verify err = %v", err)
+	}
+}
+
+func TestNewAuthenticatorValidation(t *testing.T) {
+	if _, err := NewAuthenticator(true, nil, nil); err == nil {
+		t.Error("expected error: enabled without users")
+	}
+	if _, err := NewAuthenticator(true, []User{{Name: "x", Role: RoleViewer, TokenSHA256: "abc"}}, nil); err == nil {
+		t.Error("expected error: bad token hash")
+	}
+	if _, err := NewAuthenticator(false, nil, map[Permission][]Role{"nope": nil}); err == nil {
+		t.Error("expected error: unknown permission")
+	}
+	if r, err := ParseRole("Risk-Officer"); err != nil || r != RoleRiskOfficer {
+		t.Errorf("ParseRole = %q, %v", r, err)
+	}
+}
+
+func TestIdentityFromUnguarded(t *testing.T) {
+	g, _ := newTestGuard(t)
+	a := g.Auth
+	id := IdentityFrom(httptest.NewRequest(http.MethodPost, "/api/v1/strategy/activate", nil))
+	if id.Role == RoleAdmin {
+		t.Fatal("unguarded request must not be admin")
+	}
+	for _, perm := range []Permission{PermRead, PermActivate, PermAlgoSubmit, PermAdmin} {
+		if a.Allowed(id.Role, perm) {
+			t.Errorf("unguarded identity allowed %s", perm)
+		}
+	}
+}
+
+func TestAuthDisabledFailsClosed(t *testing.T) {
+	if got := ListenAddr("0.0.0.0", false, 9201); got != "127.0.0.1:9201" {
+		t.Errorf("ListenAddr without auth = %s, want loopback", got)
+	}
+	if got := ListenAddr("", true, 9201); got != ":9201" {
+		t.Errorf("ListenAddr with auth = %s, want :9201", got)
+	}
+
+	authn, err := NewAuthenticator(false, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Guard{Auth: authn, Resolve: func(r *http.Request) Permission { return PermRead }}
+	h := g.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	req.Header.Set("Origin", "http://evil.local")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("auth disabled without allowed_origins returned CORS %q", got)
+	}
+}
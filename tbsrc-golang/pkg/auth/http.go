@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+const maxAuditBody = 4096
+
+// LoopbackHost 认证关闭时控制 API 只监听本机
+const LoopbackHost = "127.0.0.1"
+
+// ListenAddr 控制 API 监听地址：启用认证时监听 host（空 = 所有网卡），
+// 认证关闭时忽略 host 只监听本机，控制端点不对网络开放
+func ListenAddr(host string, authEnabled bool, port int) string {
+	if !authEnabled {
+		host = LoopbackHost
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Guard HTTP 中间件：CORS、认证、按端点授权，并审计所有控制请求（非只读权限）
+type Guard struct {
+	Auth           *Authenticator
+	Audit          *AuditLog // nil = 不记录
+	AllowedOrigins []string  // CORS 白名单；为空时不返回 CORS 头（只允许同源页面）
+	Public         map[string]bool
+	Resolve        func(r *http.Request) Permission // 请求 → 所需权限
+}
+
+// Wrap 包装 handler
+func (g *Guard) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.cors(w, r)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if g.Public[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		perm := g.Resolve(r)
+		audited := g.Audit != nil && perm != PermRead && perm != PermAuditRead
+		var body []byte
+		if audited && r.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, 1<<20))
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		id, err := g.Auth.Authorize(r, perm)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrForbidden) {
+				status = http.StatusForbidden
+			}
+			if audited {
+				g.record(r, id, perm, body, status, err.Error())
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="trader"`)
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": err.Error()})
+			return
+		}
+
+		if tok := r.URL.Query().Get("token"); tok != "" && g.Auth.Enabled() {
+			// 页面内的 fetch/WebSocket 请求随 cookie 携带 token（SameSite=Strict 防跨站提交）
+			http.SetCookie(w, &http.Cookie{Name: TokenCookie, Value: tok, Path: "/",
+				HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteStrictMode})
+		}
+
+		r = r.WithContext(WithIdentity(r.Context(), id))
+		if !audited {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		g.record(r, id, perm, body, rec.status, resultMessage(rec.body.Bytes()))
+	})
+}
+
+func (g *Guard) cors(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	allowed := ""
+	for _, o := range g.AllowedOrigins {
+		if o == "*" || o == origin {
+			allowed = origin
+			break
+		}
+	}
+	if allowed == "" {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowed)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Add("Vary", "Origin")
+}
+
+func (g *Guard) record(r *http.Request, id *Identity, perm Permission, body []byte, status int, result string) {
+	e := AuditEntry{
+		Remote:  remoteHost(r),
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Action:  perm,
+		Status:  status,
+		Success: status < 300,
+		Result:  truncate(result, 512),
+	}
+	if id != nil {
+		e.User, e.Role, e.AuthBy = id.Name, id.Role, id.Method
+	}
+	if q := r.URL.Query(); len(q) > 0 {
+		e.Params = make(map[string]string, len(q))
+		for k, v := range q {
+			if k == "token" {
+				continue
+			}
+			e.Params[k] = v[0]
+		}
+	}
+	if len(body) > 0 {
+		e.Body = truncate(string(body), maxAuditBody)
+	}
+	if _, err := g.Audit.Append(e); err != nil {
+		log.Printf("[Audit] Failed to record %s %s by %s: %v", r.Method, r.URL.Path, e.User, err)
+	}
+}
+
+// recorder 记录响应状态码与响应体（截断）
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if room := maxAuditBody - r.body.Len(); room > 0 {
+		r.body.Write(b[:min(len(b), room)])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// resultMessage 从 JSON 响应中提取 error/message 字段
+func resultMessage(body []byte) string {
+	var resp struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return string(body)
+	}
+	if resp.Error != "" {
+		return resp.Error
+	}
+	return resp.Message
+}
+
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
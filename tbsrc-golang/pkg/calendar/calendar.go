@@ -0,0 +1,218 @@
+// Package calendar provides the exchange trading calendar and session schedules
+// 交易日历：交易所节假日、交易日（含夜盘归属）、按品种的交易时段模板
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 夜盘归属边界（墙钟时间）
+// >= 18:00 的行情/时段属于下一个交易日；< 06:00 属于前一晚夜盘所在的交易日
+const (
+	nightBoundary   = 18 * 3600
+	morningBoundary = 6 * 3600
+
+	maxHolidayRun = 366 // 查找相邻交易日的最大天数
+)
+
+// Calendar 交易所交易日历
+// 交易日 = 周一至周五且不在节假日表中
+type Calendar struct {
+	loc      *time.Location
+	holidays map[int]bool // YYYYMMDD
+	mu       sync.RWMutex
+}
+
+// New 创建交易日历，loc 为 nil 时使用 Asia/Shanghai（加载失败退回 UTC）
+func New(loc *time.Location) *Calendar {
+	if loc == nil {
+		var err error
+		loc, err = time.LoadLocation("Asia/Shanghai")
+		if err != nil {
+			loc = time.UTC
+		}
+	}
+	return &Calendar{
+		loc:      loc,
+		holidays: make(map[int]bool),
+	}
+}
+
+// Location 返回日历时区
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// LoadHolidays 从文件加载节假日
+func (c *Calendar) LoadHolidays(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open holiday file: %w", err)
+	}
+	defer f.Close()
+	if err := c.ReadHolidays(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadHolidays 读取节假日列表
+// 每行一个日期（YYYYMMDD 或 YYYY-MM-DD），# 之后为注释，空行忽略
+func (c *Calendar) ReadHolidays(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		d, err := parseDate(line, c.loc)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		c.AddHoliday(d)
+	}
+	return scanner.Err()
+}
+
+// AddHoliday 添加一个节假日
+func (c *Calendar) AddHoliday(d time.Time) {
+	c.mu.Lock()
+	c.holidays[dateKey(d.In(c.loc))] = true
+	c.mu.Unlock()
+}
+
+// NumHolidays 已加载的节假日数量
+func (c *Calendar) NumHolidays() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.holidays)
+}
+
+// CoversYear 节假日表是否包含 year 年的日期
+// 节假日表按年度公告维护，某年没有任何条目通常意味着忘记更新，而不是该年无节假日
+func (c *Calendar) CoversYear(year int) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for key := range c.holidays {
+		if key/10000 == year {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHoliday d 所在日期是否在节假日表中
+func (c *Calendar) IsHoliday(d time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.holidays[dateKey(d.In(c.loc))]
+}
+
+// IsTradingDay d 所在日期是否为交易日
+func (c *Calendar) IsTradingDay(d time.Time) bool {
+	d = d.In(c.loc)
+	if wd := d.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	return !c.IsHoliday(d)
+}
+
+// NextTradingDay d 之后（不含 d）的第一个交易日（零点）
+func (c *Calendar) NextTradingDay(d time.Time) time.Time {
+	day := c.Date(d)
+	for i := 0; i < maxHolidayRun; i++ {
+		day = day.AddDate(0, 0, 1)
+		if c.IsTradingDay(day) {
+			return day
+		}
+	}
+	return day
+}
+
+// PrevTradingDay d 之前（不含 d）的最后一个交易日（零点）
+func (c *Calendar) PrevTradingDay(d time.Time) time.Time {
+	day := c.Date(d)
+	for i := 0; i < maxHolidayRun; i++ {
+		day = day.AddDate(0, 0, -1)
+		if c.IsTradingDay(day) {
+			return day
+		}
+	}
+	return day
+}
+
+// HasNightSession 交易日 d 当晚是否有夜盘
+// 节假日前最后一个交易日无夜盘；周五夜盘（归属下周一）正常，除非下周一放假
+func (c *Calendar) HasNightSession(d time.Time) bool {
+	day := c.Date(d)
+	if !c.IsTradingDay(day) {
+		return false
+	}
+	next := c.NextTradingDay(day)
+	for x := day.AddDate(0, 0, 1); x.Before(next); x = x.AddDate(0, 0, 1) {
+		if c.IsHoliday(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// TradingDay t 所属的交易日（零点）
+// 18:00 之后属于下一交易日（夜盘），06:00 之前属于前一晚夜盘的交易日，
+// 其余时间为当天（非交易日顺延到下一交易日）
+func (c *Calendar) TradingDay(t time.Time) time.Time {
+	t = t.In(c.loc)
+	day := c.Date(t)
+	clock := clockOf(t)
+	switch {
+	case clock >= nightBoundary:
+		return c.NextTradingDay(day)
+	case clock < morningBoundary:
+		return c.NextTradingDay(day.AddDate(0, 0, -1))
+	case c.IsTradingDay(day):
+		return day
+	default:
+		return c.NextTradingDay(day)
+	}
+}
+
+// Date 返回 t 所在日期的零点（日历时区）
+func (c *Calendar) Date(t time.Time) time.Time {
+	t = t.In(c.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// FormatDay 交易日格式 YYYYMMDD
+func FormatDay(d time.Time) string {
+	return d.Format("20060102")
+}
+
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"20060102", "2006-01-02"} {
+		if d, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYYMMDD or YYYY-MM-DD)", s)
+}
+
+func dateKey(d time.Time) int {
+	return d.Year()*10000 + int(d.Month())*100 + d.Day()
+}
+
+// clockOf 墙钟时间（当天零点起的秒数）
+func clockOf(t time.Time) int {
+	return t.Hour()*3600 + t.Minute()*60 + t.Second()
+}
@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testLoc = time.FixedZone("CST", 8*3600)
+
+func at(y int, m time.Month, d, hh, mm int) time.Time {
+	return time.Date(y, m, d, hh, mm, 0, 0, testLoc)
+}
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, testLoc)
+}
+
+// newTestCalendar 2026 国庆: 10-01 ~ 10-07
+func newTestCalendar(t *testing.T) *Calendar {
+	c := New(testLoc)
+	err := c.ReadHolidays(strings.NewReader(`
+# 国庆
+20261001
+20261002
+2026-10-05   # 周一
+20261006
+20261007
+`))
+	if err != nil {
+		t.Fatalf("ReadHolidays: %v", err)
+	}
+	return c
+}
+
+func TestReadHolidays(t *testing.T) {
+	c := newTestCalendar(t)
+	if c.NumHolidays() != 5 {
+		t.Errorf("NumHolidays = %d, want 5", c.NumHolidays())
+	}
+	if !c.IsHoliday(day(2026, 10, 5)) {
+		t.Error("2026-10-05 should be a holiday")
+	}
+	if err := c.ReadHolidays(strings.NewReader("2026/10/01\n")); err == nil {
+		t.Error("expected error for bad date format")
+	}
+}
+
+func TestCoversYear(t *testing.T) {
+	c := newTestCalendar(t)
+	if !c.CoversYear(2026) {
+		t.Error("CoversYear(2026) = false")
+	}
+	if c.CoversYear(2027) {
+		t.Error("CoversYear(2027) = true")
+	}
+}
+
+func TestTradingDays(t *testing.T) {
+	c := newTestCalendar(t)
+	if c.IsTradingDay(day(2026, 10, 3)) || c.IsTradingDay(day(2026, 10, 1)) {
+		t.Error("weekend and holiday should not be trading days")
+	}
+	if !c.IsTradingDay(day(2026, 9, 30)) {
+		t.Error("2026-09-30 should be a trading day")
+	}
+	if got := c.NextTradingDay(day(2026, 9, 30)); !got.Equal(day(2026, 10, 8)) {
+		t.Errorf("NextTradingDay(09-30) = %v, want 10-08", got)
+	}
+	if got := c.PrevTradingDay(day(2026, 10, 8)); !got.Equal(day(2026, 9, 30)) {
+		t.Errorf("PrevTradingDay(10-08) = %v, want 09-30", got)
+	}
+}
+
+func TestHasNightSession(t *testing.T) {
+	c := newTestCalendar(t)
+	cases := []struct {
+		d    time.Time
+		want bool
+	}{
+		{day(2026, 9, 29), true},  // 周二
+		{day(2026, 9, 25), true},  // 周五 → 周一夜盘正常
+		{day(2026, 9, 30), false}, // 国庆前
+		{day(2026, 10, 3), false}, // 周六
+	}
+	for _, tc := range cases {
+		if got := c.HasNightSession(tc.d); got != tc.want {
+			t.Errorf("HasNightSession(%s) = %v, want %v", FormatDay(tc.d), got, tc.want)
+		}
+	}
+}
+
+func TestTradingDay_NightRollover(t *testing.T) {
+	c := newTestCalendar(t)
+	cases := []struct {
+		t    time.Time
+		want time.Time
+	}{
+		{at(2026, 9, 29, 10, 0), day(2026, 9, 29)},  // 日盘
+		{at(2026, 9, 29, 21, 30), day(2026, 9, 30)}, // 夜盘归属次日
+		{at(2026, 9, 30, 1, 30), day(2026, 9, 30)},  // 凌晨夜盘
+		{at(2026, 9, 25, 21, 30), day(2026, 9, 28)}, // 周五夜盘 → 周一
+		{at(2026, 9, 26, 2, 0), day(2026, 9, 28)},   // 周六凌晨 → 周一
+		{at(2026, 10, 4, 12, 0), day(2026, 10, 8)},  // 假期中 → 节后
+	}
+	for _, tc := range cases {
+		if got := c.TradingDay(tc.t); !got.Equal(tc.want) {
+			t.Errorf("TradingDay(%v) = %s, want %s", tc.t, FormatDay(got), FormatDay(tc.want))
+		}
+	}
+}
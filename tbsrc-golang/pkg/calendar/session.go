@@ -0,0 +1,359 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 集合竞价窗口：开盘前 5 分钟开始，开盘前 1 分钟撮合
+// SHFE/DCE/CZCE: 20:55-20:59（夜盘）或 08:55-08:59（无夜盘时）；CFFEX: 09:25-09:29
+const (
+	auctionLead = 5 * time.Minute
+	auctionEnd  = 1 * time.Minute
+
+	// DefaultPreOpenLead 集合竞价前的盘前准备时间
+	DefaultPreOpenLead = 5 * time.Minute
+)
+
+// Segment 一个连续交易时段（墙钟秒数），End <= Start 表示跨零点（如 21:00-02:30）
+type Segment struct {
+	Start int
+	End   int
+}
+
+// Night 是否为夜盘时段（开始于 18:00 之后）
+func (s Segment) Night() bool {
+	return s.Start >= nightBoundary
+}
+
+// String HH:MM-HH:MM
+func (s Segment) String() string {
+	return formatClock(s.Start) + "-" + formatClock(s.End)
+}
+
+// ParseSegment 解析 "HH:MM-HH:MM" 或 "HH:MM:SS-HH:MM:SS"
+func ParseSegment(spec string) (Segment, error) {
+	parts := strings.Split(spec, "-")
+	if len(parts) != 2 {
+		return Segment{}, fmt.Errorf("invalid segment %q (expected HH:MM-HH:MM)", spec)
+	}
+	start, err := parseClock(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Segment{}, err
+	}
+	end, err := parseClock(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return Segment{}, err
+	}
+	if start == end {
+		return Segment{}, fmt.Errorf("empty segment %q", spec)
+	}
+	return Segment{Start: start, End: end}, nil
+}
+
+// Template 品种交易时段模板，Segments 按交易日内顺序排列（夜盘在前）
+type Template struct {
+	Name     string
+	Segments []Segment
+}
+
+// NewTemplate 从 "HH:MM-HH:MM" 列表创建模板，夜盘时段自动排到日盘之前
+func NewTemplate(name string, specs ...string) (*Template, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("template %s: no segments", name)
+	}
+	var night, day []Segment
+	for _, spec := range specs {
+		seg, err := ParseSegment(spec)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		if seg.Night() {
+			night = append(night, seg)
+		} else {
+			if seg.End < seg.Start {
+				return nil, fmt.Errorf("template %s: day segment %s crosses midnight", name, spec)
+			}
+			day = append(day, seg)
+		}
+	}
+	if len(night) > 1 {
+		return nil, fmt.Errorf("template %s: at most one night segment", name)
+	}
+	return &Template{Name: name, Segments: append(night, day...)}, nil
+}
+
+func mustTemplate(name string, specs ...string) *Template {
+	t, err := NewTemplate(name, specs...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// HasNight 模板是否包含夜盘
+func (t *Template) HasNight() bool {
+	return len(t.Segments) > 0 && t.Segments[0].Night()
+}
+
+// 商品期货日盘（10:15-10:30 小节休息，11:30-13:30 午休）
+var commodityDay = []string{"09:00-10:15", "10:30-11:30", "13:30-15:00"}
+
+// DefaultTemplates 内置时段模板
+var DefaultTemplates = map[string]*Template{
+	"day":         mustTemplate("day", commodityDay...),
+	"night_2300":  mustTemplate("night_2300", append([]string{"21:00-23:00"}, commodityDay...)...),
+	"night_0100":  mustTemplate("night_0100", append([]string{"21:00-01:00"}, commodityDay...)...),
+	"night_0230":  mustTemplate("night_0230", append([]string{"21:00-02:30"}, commodityDay...)...),
+	"cffex_index": mustTemplate("cffex_index", "09:30-11:30", "13:00-15:00"),
+	"cffex_bond":  mustTemplate("cffex_bond", "09:30-11:30", "13:00-15:15"),
+}
+
+// DefaultProductTemplates 品种 → 时段模板（未列出的品种使用 "day"）
+var DefaultProductTemplates = map[string]string{
+	// SHFE/INE 贵金属、原油: 21:00-02:30
+	"ag": "night_0230", "au": "night_0230", "sc": "night_0230",
+	// SHFE/INE 有色: 21:00-01:00
+	"cu": "night_0100", "al": "night_0100", "zn": "night_0100", "pb": "night_0100",
+	"ni": "night_0100", "sn": "night_0100", "ss": "night_0100", "ao": "night_0100", "bc": "night_0100",
+	// SHFE 黑色/能化
+	"rb": "night_2300", "hc": "night_2300", "bu": "night_2300", "ru": "night_2300",
+	"fu": "night_2300", "sp": "night_2300", "br": "night_2300", "nr": "night_2300", "lu": "night_2300",
+	// DCE
+	"i": "night_2300", "j": "night_2300", "jm": "night_2300", "m": "night_2300", "y": "night_2300",
+	"p": "night_2300", "a": "night_2300", "b": "night_2300", "c": "night_2300", "cs": "night_2300",
+	"l": "night_2300", "v": "night_2300", "pp": "night_2300", "eg": "night_2300", "eb": "night_2300",
+	"pg": "night_2300", "rr": "night_2300",
+	// CZCE
+	"MA": "night_2300", "TA": "night_2300", "SR": "night_2300", "CF": "night_2300", "RM": "night_2300",
+	"OI": "night_2300", "FG": "night_2300", "SA": "night_2300", "PF": "night_2300", "CY": "night_2300",
+	"ZC": "night_2300", "SH": "night_2300", "PX": "night_2300",
+	// CFFEX
+	"IF": "cffex_index", "IC": "cffex_index", "IH": "cffex_index", "IM": "cffex_index",
+	"T": "cffex_bond", "TF": "cffex_bond", "TS": "cffex_bond", "TL": "cffex_bond",
+}
+
+// ProductOf 合约代码的品种前缀，如 ag2506 → ag，SR605 → SR
+func ProductOf(symbol string) string {
+	for i, r := range symbol {
+		if !unicode.IsLetter(r) {
+			return symbol[:i]
+		}
+	}
+	return symbol
+}
+
+// Phase 交易阶段
+type Phase int
+
+const (
+	PhaseClosed  Phase = iota // 非交易时间（当日收盘后 / 开盘准备前）
+	PhasePreOpen              // 盘前准备（集合竞价前 PreOpenLead）
+	PhaseAuction              // 集合竞价
+	PhaseOpen                 // 连续交易
+	PhaseBreak                // 交易日内休息（小节、午休、夜盘收盘到日盘开盘）
+)
+
+// String 阶段名称
+func (p Phase) String() string {
+	switch p {
+	case PhasePreOpen:
+		return "pre_open"
+	case PhaseAuction:
+		return "auction"
+	case PhaseOpen:
+		return "open"
+	case PhaseBreak:
+		return "break"
+	default:
+		return "closed"
+	}
+}
+
+// Window 一个具体的连续交易时段 [Start, End)
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Schedule 某模板在某交易日的具体时段
+type Schedule struct {
+	TradingDay   time.Time
+	PreOpen      time.Time
+	AuctionStart time.Time
+	AuctionEnd   time.Time
+	Windows      []Window // 空表示非交易日
+}
+
+// Schedule 生成模板在交易日 tradingDay 的具体时段
+// 夜盘落在前一交易日晚上，前一交易日无夜盘（节假日前）时跳过；preOpenLead <= 0 使用默认值
+func (c *Calendar) Schedule(tmpl *Template, tradingDay time.Time, preOpenLead time.Duration) Schedule {
+	day := c.Date(tradingDay)
+	s := Schedule{TradingDay: day}
+	if !c.IsTradingDay(day) {
+		return s
+	}
+	if preOpenLead <= 0 {
+		preOpenLead = DefaultPreOpenLead
+	}
+
+	prev := c.PrevTradingDay(day)
+	for _, seg := range tmpl.Segments {
+		base := day
+		if seg.Night() {
+			if !c.HasNightSession(prev) {
+				continue
+			}
+			base = prev
+		}
+		start := base.Add(time.Duration(seg.Start) * time.Second)
+		endBase := base
+		if seg.End <= seg.Start {
+			endBase = base.AddDate(0, 0, 1)
+		}
+		end := endBase.Add(time.Duration(seg.End) * time.Second)
+		s.Windows = append(s.Windows, Window{Start: start, End: end})
+	}
+	if len(s.Windows) > 0 {
+		open := s.Windows[0].Start
+		s.AuctionStart = open.Add(-auctionLead)
+		s.AuctionEnd = open.Add(-auctionEnd)
+		s.PreOpen = s.AuctionStart.Add(-preOpenLead)
+	}
+	return s
+}
+
+// PhaseAt t 在该交易日时段中的阶段
+func (s *Schedule) PhaseAt(t time.Time) Phase {
+	if len(s.Windows) == 0 || t.Before(s.PreOpen) {
+		return PhaseClosed
+	}
+	if t.Before(s.AuctionStart) {
+		return PhasePreOpen
+	}
+	if t.Before(s.Windows[0].Start) {
+		return PhaseAuction
+	}
+	for _, w := range s.Windows {
+		if t.Before(w.Start) {
+			return PhaseBreak
+		}
+		if t.Before(w.End) {
+			return PhaseOpen
+		}
+	}
+	return PhaseClosed
+}
+
+// Close 该交易日最后收盘时间
+func (s *Schedule) Close() time.Time {
+	if len(s.Windows) == 0 {
+		return time.Time{}
+	}
+	return s.Windows[len(s.Windows)-1].End
+}
+
+// Phase 模板在时刻 t 的交易阶段
+func (c *Calendar) Phase(tmpl *Template, t time.Time, preOpenLead time.Duration) Phase {
+	s := c.Schedule(tmpl, c.TradingDay(t), preOpenLead)
+	return s.PhaseAt(t)
+}
+
+// NextOpen t 之后下一个连续交易时段的开始时间（不含正在进行的时段）
+func (c *Calendar) NextOpen(tmpl *Template, t time.Time) time.Time {
+	return c.nextBoundary(tmpl, t, func(w Window) time.Time { return w.Start })
+}
+
+// NextClose t 之后最近的时段结束时间（在时段内为本时段结束）
+func (c *Calendar) NextClose(tmpl *Template, t time.Time) time.Time {
+	return c.nextBoundary(tmpl, t, func(w Window) time.Time { return w.End })
+}
+
+func (c *Calendar) nextBoundary(tmpl *Template, t time.Time, edge func(Window) time.Time) time.Time {
+	day := c.TradingDay(t)
+	for i := 0; i < maxHolidayRun; i++ {
+		s := c.Schedule(tmpl, day, 0)
+		for _, w := range s.Windows {
+			if e := edge(w); e.After(t) {
+				return e
+			}
+		}
+		day = c.NextTradingDay(day)
+	}
+	return time.Time{}
+}
+
+// Event 交易阶段切换事件，Phase 为进入的阶段（PhaseClosed 即收盘事件）
+type Event struct {
+	Phase      Phase
+	Template   string
+	TradingDay time.Time
+	Time       time.Time
+	Initial    bool // 跟踪器首次 Poll 的当前阶段（启动时），不是阶段切换
+}
+
+// Tracker 轮询交易阶段，阶段切换时产生事件
+type Tracker struct {
+	cal         *Calendar
+	tmpl        *Template
+	preOpenLead time.Duration
+
+	phase      Phase
+	tradingDay time.Time
+	started    bool
+}
+
+// NewTracker 创建阶段跟踪器
+func NewTracker(cal *Calendar, tmpl *Template, preOpenLead time.Duration) *Tracker {
+	return &Tracker{cal: cal, tmpl: tmpl, preOpenLead: preOpenLead}
+}
+
+// Poll 计算 now 的阶段，与上次不同（或首次调用）时返回事件
+// 收盘事件的 TradingDay 为刚结束的交易日
+func (tr *Tracker) Poll(now time.Time) (Event, bool) {
+	td := tr.cal.TradingDay(now)
+	s := tr.cal.Schedule(tr.tmpl, td, tr.preOpenLead)
+	phase := s.PhaseAt(now)
+
+	if tr.started && phase == tr.phase {
+		return Event{}, false
+	}
+	initial := !tr.started
+	if phase == PhaseClosed && !initial {
+		td = tr.tradingDay // 收盘事件归属刚结束的交易日
+	}
+	tr.started = true
+	tr.phase = phase
+	tr.tradingDay = td
+	return Event{Phase: phase, Template: tr.tmpl.Name, TradingDay: td, Time: now, Initial: initial}, true
+}
+
+// Phase 最近一次 Poll 的阶段
+func (tr *Tracker) Phase() Phase {
+	return tr.phase
+}
+
+// Template 跟踪的时段模板
+func (tr *Tracker) Template() *Template {
+	return tr.tmpl
+}
+
+func parseClock(s string) (int, error) {
+	var h, m, sec int
+	if _, err := fmt.Sscanf(s, "%d:%d:%d", &h, &m, &sec); err != nil {
+		sec = 0
+		if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil {
+			return 0, fmt.Errorf("invalid time %q (expected HH:MM or HH:MM:SS)", s)
+		}
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 || sec < 0 || sec > 59 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*3600 + m*60 + sec, nil
+}
+
+func formatClock(sec int) string {
+	return fmt.Sprintf("%02d:%02d", sec/3600, sec%3600/60)
+}
@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProductOf(t *testing.T) {
+	cases := map[string]string{"ag2506": "ag", "SR605": "SR", "IF2512": "IF", "i2601": "i", "au": "au"}
+	for sym, want := range cases {
+		if got := ProductOf(sym); got != want {
+			t.Errorf("ProductOf(%s) = %s, want %s", sym, got, want)
+		}
+	}
+}
+
+func TestNewTemplate(t *testing.T) {
+	tmpl, err := NewTemplate("x", "09:00-11:30", "21:00-02:30")
+	if err != nil {
+		t.Fatalf("NewTemplate: %v", err)
+	}
+	if !tmpl.HasNight() || tmpl.Segments[0].String() != "21:00-02:30" {
+		t.Errorf("segments = %v, want night first", tmpl.Segments)
+	}
+	if _, err := NewTemplate("bad", "09:00"); err == nil {
+		t.Error("expected error for malformed segment")
+	}
+	if _, err := NewTemplate("bad", "10:00-09:00"); err == nil {
+		t.Error("expected error for day segment crossing midnight")
+	}
+}
+
+func TestSchedule_Night0230(t *testing.T) {
+	c := newTestCalendar(t)
+	tmpl := DefaultTemplates["night_0230"]
+
+	s := c.Schedule(tmpl, day(2026, 9, 29), 0)
+	if len(s.Windows) != 4 {
+		t.Fatalf("windows = %d, want 4", len(s.Windows))
+	}
+	if !s.Windows[0].Start.Equal(at(2026, 9, 28, 21, 0)) || !s.Windows[0].End.Equal(at(2026, 9, 29, 2, 30)) {
+		t.Errorf("night window = %v - %v", s.Windows[0].Start, s.Windows[0].End)
+	}
+	if !s.AuctionStart.Equal(at(2026, 9, 28, 20, 55)) || !s.PreOpen.Equal(at(2026, 9, 28, 20, 50)) {
+		t.Errorf("auction = %v, preopen = %v", s.AuctionStart, s.PreOpen)
+	}
+
+	// 节后首日：前一交易日（国庆前）无夜盘，集合竞价在 08:55
+	s = c.Schedule(tmpl, day(2026, 10, 8), 0)
+	if len(s.Windows) != 3 || !s.AuctionStart.Equal(at(2026, 10, 8, 8, 55)) {
+		t.Errorf("post-holiday windows = %d, auction = %v, want 3, 08:55", len(s.Windows), s.AuctionStart)
+	}
+}
+
+func TestPhase(t *testing.T) {
+	c := newTestCalendar(t)
+	tmpl := DefaultTemplates["night_2300"]
+	cases := []struct {
+		t    time.Time
+		want Phase
+	}{
+		{at(2026, 9, 29, 20, 0), PhaseClosed},
+		{at(2026, 9, 29, 20, 52), PhasePreOpen},
+		{at(2026, 9, 29, 20, 56), PhaseAuction},
+		{at(2026, 9, 29, 22, 0), PhaseOpen},
+		{at(2026, 9, 30, 0, 30), PhaseBreak}, // 夜盘收盘到日盘
+		{at(2026, 9, 30, 10, 20), PhaseBreak},
+		{at(2026, 9, 30, 14, 0), PhaseOpen},
+		{at(2026, 9, 30, 15, 30), PhaseClosed},
+		{at(2026, 9, 30, 21, 30), PhaseClosed}, // 国庆前无夜盘
+		{at(2026, 10, 8, 8, 52), PhasePreOpen},
+	}
+	for _, tc := range cases {
+		if got := c.Phase(tmpl, tc.t, 0); got != tc.want {
+			t.Errorf("Phase(%v) = %s, want %s", tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestNextOpenClose(t *testing.T) {
+	c := newTestCalendar(t)
+	tmpl := DefaultTemplates["night_0100"]
+
+	if got := c.NextOpen(tmpl, at(2026, 9, 29, 15, 10)); !got.Equal(at(2026, 9, 29, 21, 0)) {
+		t.Errorf("NextOpen = %v, want 09-29 21:00", got)
+	}
+	if got := c.NextClose(tmpl, at(2026, 9, 29, 22, 0)); !got.Equal(at(2026, 9, 30, 1, 0)) {
+		t.Errorf("NextClose = %v, want 09-30 01:00", got)
+	}
+	// 国庆前收盘后，下一次开盘为节后 09:00
+	if got := c.NextOpen(tmpl, at(2026, 9, 30, 15, 10)); !got.Equal(at(2026, 10, 8, 9, 0)) {
+		t.Errorf("NextOpen before holiday = %v, want 10-08 09:00", got)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	c := newTestCalendar(t)
+	tr := NewTracker(c, DefaultTemplates["cffex_index"], 0)
+
+	var got []Phase
+	for tm := at(2026, 9, 29, 9, 0); tm.Before(at(2026, 9, 29, 16, 0)); tm = tm.Add(time.Minute) {
+		if ev, ok := tr.Poll(tm); ok {
+			if ev.Initial != (len(got) == 0) {
+				t.Errorf("event %d Initial = %v", len(got), ev.Initial)
+			}
+			got = append(got, ev.Phase)
+			if ev.Phase == PhaseClosed && ev.Time.Hour() == 15 && !ev.TradingDay.Equal(day(2026, 9, 29)) {
+				t.Errorf("close event trading day = %s, want 20260929", FormatDay(ev.TradingDay))
+			}
+		}
+	}
+	want := []Phase{PhaseClosed, PhasePreOpen, PhaseAuction, PhaseOpen, PhaseBreak, PhaseOpen, PhaseClosed}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"tbsrc-golang/pkg/connector"
 	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/journal"
+	"tbsrc-golang/pkg/offset"
 	"tbsrc-golang/pkg/shm"
 	"tbsrc-golang/pkg/types"
 )
@@ -28,6 +30,9 @@ type Client struct {
 	product      string
 	exchangeType uint8 // C++: m_exchangeType（来自 FillReqInfo）
 	reqMsg       shm.RequestMsg // 复用的请求缓冲区
+	offsets      *offset.Planner // Go 扩展：开平仓规划（nil = PosDirection 不填，由 counter_bridge 推断）
+	journal      *journal.Writer // Go 扩展：写前日志（nil = 不记录）
+	status       statusQuery     // Go 扩展：重启时的在途订单查询
 }
 
 // NewClient 创建 Client
@@ -44,6 +49,11 @@ func NewClient(conn *connector.Connector, strategyID int32, account, product str
 	}
 }
 
+// StrategyID 返回 Client 所属策略 ID（填入 RequestMsg.StrategyID）
+func (c *Client) StrategyID() int32 {
+	return c.strategyID
+}
+
 // RegisterInstrument 注册合约
 func (c *Client) RegisterInstrument(inst *instrument.Instrument) {
 	c.instruments[inst.Symbol] = inst
@@ -54,6 +64,33 @@ func (c *Client) RegisterStrategy(symbol string, cb StrategyCallback) {
 	c.strategies[symbol] = cb
 }
 
+// SetOffsetPlanner 设置开平仓规划器
+// 启用后 SendNewOrder 按持仓台账和品种费率填写 PosDirection（平今/平昨/开仓）
+func (c *Client) SetOffsetPlanner(p *offset.Planner) {
+	c.offsets = p
+}
+
+// OffsetPlanner 返回开平仓规划器（未启用时为 nil）
+func (c *Client) OffsetPlanner() *offset.Planner {
+	return c.offsets
+}
+
+// SetJournal 设置写前日志，启用后记录所有发出的请求和收到的回报
+func (c *Client) SetJournal(j *journal.Writer) {
+	c.journal = j
+}
+
+// Journal 返回写前日志（未启用时为 nil）
+func (c *Client) Journal() *journal.Writer {
+	return c.journal
+}
+
+// RestoreOrderID 崩溃恢复时重新登记 orderID → callback（不发送任何请求）
+// 日志中的订单属于上一进程的 clientID，同时放行该区间的回报
+func (c *Client) RestoreOrderID(orderID uint32, cb StrategyCallback) {
+	c.AdoptOrder(orderID, cb)
+}
+
 // OnMDUpdate 作为 Connector 的 MDCallback
 // 参考: CommonClient.cpp SendINDUpdate()
 // 根据 symbol 查找 Instrument 并更新，然后路由到对应策略
@@ -79,6 +116,20 @@ func (c *Client) OnMDUpdate(md *shm.MarketUpdateNew) {
 // 参考: CommonClient.cpp SendInfraORSUpdate()
 // 根据 orderID 查找策略并路由
 func (c *Client) OnORSUpdate(resp *shm.ResponseMsg) {
+	// Go 扩展：在途订单查询应答不是订单状态变化，不记日志也不路由
+	if c.onStatusResponse(resp) {
+		return
+	}
+	// 先落日志再处理：崩溃后重放与实际处理顺序一致
+	if c.journal != nil {
+		if err := c.journal.RecordResponse(resp); err != nil {
+			log.Printf("[Client] journal response orderID=%d: %v", resp.OrderID, err)
+		}
+	}
+	if c.offsets != nil {
+		c.offsets.OnResponse(resp)
+	}
+
 	cb, ok := c.orderIDMap[resp.OrderID]
 	if !ok {
 		log.Printf("[Client] unknown orderID=%d responseType=%d", resp.OrderID, resp.Response_Type)
@@ -92,6 +143,8 @@ func (c *Client) OnORSUpdate(resp *shm.ResponseMsg) {
 //
 // C++: 填充 Request_Type=NEWORDER, Token, Transaction_Type, Price, Quantity,
 //      AccountID, Product, StrategyID, 然后调用 FillReqInfo()+connector.SendNewOrder
+//
+// Go 扩展：先预分配 OrderID 并写入日志，落盘失败时不发送，返回 0
 func (c *Client) SendNewOrder(inst *instrument.Instrument, side types.TransactionType,
 	price float64, qty int32, ordHitType types.OrderHitType, cb StrategyCallback) uint32 {
 
@@ -141,8 +194,27 @@ func (c *Client) SendNewOrder(inst *instrument.Instrument, side types.Transactio
 	// C++: FillReqInfo() — 设置 LIMIT, PERUNIT, Exchange_Type
 	c.fillReqInfo()
 
-	// 发送
-	orderID := c.conn.SendNewOrder(&c.reqMsg)
+	// Go 扩展：开平仓选择（C++ 由 ORS SetCombOffsetFlag 决定）
+	var piece offset.Piece
+	if c.offsets != nil {
+		piece = c.offsets.Plan(inst.Symbol, c.exchangeType, side, qty, price)
+		c.reqMsg.PosDirection = piece.Offset.PosDirection()
+	}
+
+	// 写前日志：先落盘再发送
+	orderID := c.conn.ReserveOrderID()
+	c.reqMsg.OrderID = orderID
+	c.reqMsg.Request_Type = shm.NEWORDER
+	if !c.record(ordHitType) {
+		if c.offsets != nil {
+			c.offsets.Abort(piece)
+		}
+		return 0
+	}
+	c.conn.SendReservedOrder(&c.reqMsg)
+	if c.offsets != nil {
+		c.offsets.Track(orderID, piece)
+	}
 
 	// 注册 orderID → callback
 	c.orderIDMap[orderID] = cb
@@ -152,8 +224,10 @@ func (c *Client) SendNewOrder(inst *instrument.Instrument, side types.Transactio
 
 // SendModifyOrder 发送改单请求
 // 参考: CommonClient.cpp:991-1051 SendModifyOrder()
+// Go 扩展：ordHitType 只写入日志（改单可能改变订单类型，重放时需要）；日志落盘失败时不发送，返回 false
 func (c *Client) SendModifyOrder(inst *instrument.Instrument, orderID uint32,
-	side types.TransactionType, price float64, doneQty, qty int32, cb StrategyCallback) {
+	side types.TransactionType, price float64, doneQty, qty int32,
+	ordHitType types.OrderHitType, cb StrategyCallback) bool {
 
 	c.clearReqMsg()
 
@@ -179,7 +253,12 @@ func (c *Client) SendModifyOrder(inst *instrument.Instrument, orderID uint32,
 
 	c.fillReqInfo()
 
+	c.reqMsg.Request_Type = shm.MODIFYORDER
+	if !c.record(ordHitType) {
+		return false
+	}
 	c.conn.SendModifyOrder(&c.reqMsg)
+	return true
 }
 
 // SendCancelOrder 发送撤单请求
@@ -210,6 +289,9 @@ func (c *Client) SendCancelOrder(inst *instrument.Instrument, orderID uint32,
 
 	c.fillReqInfo()
 
+	// 撤单降低风险，日志落盘失败也照常发送；重放时该订单在撤单确认回报前仍按在途处理
+	c.reqMsg.Request_Type = shm.CANCELORDER
+	c.record(types.HitStandard)
 	c.conn.SendCancelOrder(&c.reqMsg)
 }
 
@@ -223,6 +305,20 @@ func (c *Client) Connector() *connector.Connector {
 	return c.conn
 }
 
+// record 发送前把请求写入日志，返回 false 表示写入失败（未启用日志时总是 true）
+// OrderID 由 Connector.ReserveOrderID 预分配，日志中的请求与发出的请求一致
+func (c *Client) record(ordHitType types.OrderHitType) bool {
+	if c.journal == nil {
+		return true
+	}
+	if err := c.journal.RecordRequest(&c.reqMsg, ordHitType); err != nil {
+		log.Printf("[Client] journal request orderID=%d type=%d failed, not sent: %v",
+			c.reqMsg.OrderID, c.reqMsg.Request_Type, err)
+		return false
+	}
+	return true
+}
+
 // fillReqInfo 对应 C++ CommonClient::FillReqInfo()
 // 参考: CommonClient.cpp:1113
 func (c *Client) fillReqInfo() {
@@ -1,12 +1,15 @@
 package client
 
 import (
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
 	"tbsrc-golang/pkg/connector"
 	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/journal"
+	"tbsrc-golang/pkg/offset"
 	"tbsrc-golang/pkg/shm"
 	"tbsrc-golang/pkg/types"
 )
@@ -217,6 +220,97 @@ func TestClient_RequestMsgFilling(t *testing.T) {
 	}
 }
 
+// TestClient_JournalBeforeSend 请求先以预分配的 OrderID 写入日志再发送，日志失败时拒绝发送
+func TestClient_JournalBeforeSend(t *testing.T) {
+	_, cl, cleanup := setupTestConnAndClient(t)
+	defer cleanup()
+
+	inst := &instrument.Instrument{Symbol: "ag2506", Exchange: "SHFE", Token: 1}
+	cl.RegisterInstrument(inst)
+	strat := &mockStrategy{}
+
+	path := filepath.Join(t.TempDir(), "journal.92201")
+	w, err := journal.Open(path, journal.Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	cl.SetJournal(w)
+
+	orderID := cl.SendNewOrder(inst, types.Buy, 5810.0, 2, types.HitStandard, strat)
+	if orderID == 0 {
+		t.Fatal("orderID should not be 0")
+	}
+	if !cl.SendModifyOrder(inst, orderID, types.Buy, 5811.0, 0, 2, types.HitStandard, strat) {
+		t.Fatal("modify should be sent")
+	}
+	w.Close()
+
+	recs, _, err := journal.ReadAll(path)
+	if err != nil || len(recs) != 2 {
+		t.Fatalf("ReadAll = %d records, %v", len(recs), err)
+	}
+	for i, want := range []shm.RequestType{shm.NEWORDER, shm.MODIFYORDER} {
+		req, _, err := journal.DecodeRequest(recs[i].Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.OrderID != orderID || req.Request_Type != want {
+			t.Errorf("record %d = orderID %d type %d, want %d/%d", i, req.OrderID, req.Request_Type, orderID, want)
+		}
+	}
+
+	// 日志已关闭：新单和改单都不发送，开平规划的平仓占用随之恢复
+	planner := offset.NewPlanner(offset.LockNever)
+	planner.SetPosition("ag2506", offset.Position{ShortYd: 3})
+	cl.SetOffsetPlanner(planner)
+	if id := cl.SendNewOrder(inst, types.Buy, 5809.0, 1, types.HitStandard, strat); id != 0 {
+		t.Errorf("SendNewOrder with failed journal = %d, want 0", id)
+	}
+	if got := planner.Position("ag2506").ShortYd; got != 3 {
+		t.Errorf("ShortYd = %d after refused order, want 3", got)
+	}
+	if len(cl.orderIDMap) != 1 {
+		t.Errorf("orderIDMap = %d entries, want 1", len(cl.orderIDMap))
+	}
+	if cl.SendModifyOrder(inst, orderID, types.Buy, 5812.0, 0, 2, types.HitStandard, strat) {
+		t.Error("modify with failed journal should not be sent")
+	}
+}
+
+// TestClient_OffsetPlanner 启用开平规划后填写 PosDirection 并按回报更新台账
+func TestClient_OffsetPlanner(t *testing.T) {
+	_, cl, cleanup := setupTestConnAndClient(t)
+	defer cleanup()
+
+	inst := &instrument.Instrument{Symbol: "ag2506", Exchange: "SHFE", Token: 1}
+	cl.RegisterInstrument(inst)
+	strat := &mockStrategy{}
+
+	planner := offset.NewPlanner(offset.LockNever)
+	planner.SetPosition("ag2506", offset.Position{LongTd: 5})
+	cl.SetOffsetPlanner(planner)
+
+	orderID := cl.SendNewOrder(inst, types.Sell, 5820.0, 3, types.HitStandard, strat)
+	if cl.reqMsg.PosDirection != shm.POS_CLOSE_INTRADAY {
+		t.Errorf("PosDirection = %d, want %d", cl.reqMsg.PosDirection, shm.POS_CLOSE_INTRADAY)
+	}
+	if got := planner.Position("ag2506").LongTd; got != 2 {
+		t.Errorf("LongTd = %d, want 2", got)
+	}
+
+	// 撤单恢复平今占用
+	cl.OnORSUpdate(&shm.ResponseMsg{Response_Type: shm.CANCEL_ORDER_CONFIRM, OrderID: orderID, Quantity: 3})
+	if got := planner.Position("ag2506").LongTd; got != 5 {
+		t.Errorf("LongTd after cancel = %d, want 5", got)
+	}
+
+	// 数量超过今仓：开仓
+	cl.SendNewOrder(inst, types.Sell, 5820.0, 6, types.HitStandard, strat)
+	if cl.reqMsg.PosDirection != shm.POS_OPEN {
+		t.Errorf("PosDirection = %d, want %d", cl.reqMsg.PosDirection, shm.POS_OPEN)
+	}
+}
+
 // TestClient_UnknownSymbolMD 未注册 symbol 不崩溃
 func TestClient_UnknownSymbolMD(t *testing.T) {
 	conn, _, cleanup := setupTestConnAndClient(t)
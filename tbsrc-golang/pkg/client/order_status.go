@@ -0,0 +1,134 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"tbsrc-golang/pkg/connector"
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// LiveOrder 柜台上仍在途的订单（ORDERSTATUS 应答，Go 扩展）
+type LiveOrder struct {
+	OrderID  uint32                `json:"order_id"`
+	ClientID uint32                `json:"client_id"` // OrderID / OrderIDRange
+	Symbol   string                `json:"symbol"`
+	Side     types.TransactionType `json:"side"`
+	Price    float64               `json:"price"`
+	OpenQty  int32                 `json:"open_qty"`
+	Time     uint64                `json:"time"` // 报单时间（counter_bridge insert_time）
+}
+
+// statusQuery 进行中的 ORDERSTATUS 查询
+type statusQuery struct {
+	mu     sync.Mutex
+	id     uint32 // 查询ID（结束标记的 OrderID），0 = 无查询
+	orders []LiveOrder
+	done   chan struct{}
+}
+
+// QueryLiveOrders 通过 ORS 查询本 StrategyID 在柜台上的在途订单（Go 扩展）
+// 请求: Request_Type=ORDERSTATUS, OrderID=查询ID
+// 应答: 每个在途订单一条 NULL_RESPONSE（OrderID=原订单，Quantity=未成交量），
+//
+//	最后一条 OrderID=查询ID 的 NULL_RESPONSE 作为结束标记（Quantity=订单数）
+//
+// 必须在 Connector.Start 之后调用；超时通常表示 counter_bridge 不支持 ORDERSTATUS
+func (c *Client) QueryLiveOrders(timeout time.Duration) ([]LiveOrder, error) {
+	var req shm.RequestMsg
+	req.StrategyID = c.strategyID
+	copyStringToBytes(req.Product[:], c.product)
+	copyStringToBytes(req.AccountID[:], c.account)
+	req.ExchangeType = c.exchangeType
+
+	q := &c.status
+	q.mu.Lock()
+	q.orders = nil
+	q.done = make(chan struct{})
+	done := q.done
+	q.id = c.conn.QueryOrderStatus(&req)
+	q.mu.Unlock()
+
+	defer func() {
+		c.conn.EndOrderStatus()
+		q.mu.Lock()
+		q.id = 0
+		q.mu.Unlock()
+	}()
+
+	select {
+	case <-done:
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return q.orders, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("client: ORDERSTATUS query timed out after %v (counter_bridge may not support ORDERSTATUS)", timeout)
+	}
+}
+
+// onStatusResponse 处理查询期间的 NULL_RESPONSE，返回 true 表示已消费
+func (c *Client) onStatusResponse(resp *shm.ResponseMsg) bool {
+	if resp.Response_Type != shm.NULL_RESPONSE {
+		return false
+	}
+	q := &c.status
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.id == 0 || resp.StrategyID != c.strategyID {
+		return false
+	}
+	if resp.OrderID == q.id {
+		close(q.done)
+		q.id = 0
+		return true
+	}
+	side := types.Sell
+	if resp.Side == shm.SideBuy {
+		side = types.Buy
+	}
+	q.orders = append(q.orders, LiveOrder{
+		OrderID:  resp.OrderID,
+		ClientID: resp.OrderID / connector.OrderIDRange,
+		Symbol:   cString(resp.Symbol[:]),
+		Side:     side,
+		Price:    resp.Price,
+		OpenQty:  resp.Quantity,
+		Time:     resp.TimeStamp,
+	})
+	return true
+}
+
+// AdoptOrder 认领上一进程的在途订单：登记 orderID → callback，并放行其 clientID 的回报
+func (c *Client) AdoptOrder(orderID uint32, cb StrategyCallback) {
+	c.orderIDMap[orderID] = cb
+	if c.conn != nil {
+		c.conn.AdoptClientID(orderID / connector.OrderIDRange)
+	}
+}
+
+// cString 截取 C 字符串（到第一个 0 字节）
+func cString(b []byte) string {
+	for i, ch := range b {
+		if ch == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+// CancelLiveOrder 撤销不由策略接管的在途订单（Go 扩展）
+// 放行其 clientID 的回报但不登记 orderID 路由：撤单确认/成交按未知订单记录日志
+func (c *Client) CancelLiveOrder(o LiveOrder) {
+	inst, ok := c.instruments[o.Symbol]
+	if !ok {
+		// counter_bridge 按 OrderID 撤单，合约只用于填写 Symbol
+		inst = &instrument.Instrument{Symbol: o.Symbol}
+	}
+	if c.conn != nil {
+		c.conn.AdoptClientID(o.ClientID)
+	}
+	c.SendCancelOrder(inst, o.OrderID, o.Side, o.Price, 0, o.OpenQty)
+}
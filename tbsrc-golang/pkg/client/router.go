@@ -0,0 +1,80 @@
+package client
+
+import (
+	"sync"
+
+	"tbsrc-golang/pkg/shm"
+)
+
+// Router 多个策略共享一个 Connector（Go 扩展）
+// C++ 每个 TradeBot 进程一个策略、一个 clientID；同一进程运行多个策略时，
+// 行情分发给所有 Client（各自按注册的合约过滤），回报按 ResponseMsg.StrategyID 路由。
+// 各 Client 共用 Connector 的 OrderID 序列，OrderID 在进程内唯一
+type Router struct {
+	mu      sync.RWMutex
+	clients map[int32]*Client // StrategyID → Client
+	order   []*Client         // 行情分发顺序 = 注册顺序
+}
+
+// NewRouter 创建 Router
+func NewRouter() *Router {
+	return &Router{clients: make(map[int32]*Client)}
+}
+
+// Add 注册 Client；StrategyID 重复时返回 false
+func (r *Router) Add(c *Client) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[c.strategyID]; ok {
+		return false
+	}
+	r.clients[c.strategyID] = c
+	r.order = append(r.order, c)
+	return true
+}
+
+// Remove 注销 Client
+func (r *Router) Remove(strategyID int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.clients[strategyID]
+	if !ok {
+		return
+	}
+	delete(r.clients, strategyID)
+	for i, o := range r.order {
+		if o == c {
+			r.order = append(r.order[:i:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Has 是否有该 StrategyID 的 Client
+func (r *Router) Has(strategyID int32) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.clients[strategyID]
+	return ok
+}
+
+// OnMDUpdate 作为 Connector 的 MDCallback：分发给所有 Client
+func (r *Router) OnMDUpdate(md *shm.MarketUpdateNew) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.order {
+		c.OnMDUpdate(md)
+	}
+}
+
+// OnORSUpdate 作为 Connector 的 ORSCallback：按 StrategyID 路由，无对应 Client 时返回 false
+func (r *Router) OnORSUpdate(resp *shm.ResponseMsg) bool {
+	r.mu.RLock()
+	c, ok := r.clients[resp.StrategyID]
+	r.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	c.OnORSUpdate(resp)
+	return true
+}
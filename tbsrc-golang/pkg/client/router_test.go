@@ -0,0 +1,49 @@
+package client
+
+import (
+	"testing"
+
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/shm"
+)
+
+func TestRouter(t *testing.T) {
+	r := NewRouter()
+	c1 := NewClient(nil, 1001, "acct", "ag", shm.ChinaSHFE)
+	c2 := NewClient(nil, 1002, "acct", "au", shm.ChinaSHFE)
+	s1, s2 := &mockStrategy{}, &mockStrategy{}
+	for _, x := range []struct {
+		c   *Client
+		sym string
+		s   *mockStrategy
+	}{{c1, "ag2603", s1}, {c2, "au2604", s2}} {
+		x.c.RegisterInstrument(instrument.NewFromConfig(x.sym, "SHFE", 1, 1, 1, 15, 1, true, 0, 0))
+		x.c.RegisterStrategy(x.sym, x.s)
+		if !r.Add(x.c) {
+			t.Fatalf("Add(%d) failed", x.c.StrategyID())
+		}
+	}
+	if r.Add(NewClient(nil, 1001, "", "", 0)) {
+		t.Error("duplicate StrategyID should be rejected")
+	}
+
+	var md shm.MarketUpdateNew
+	copy(md.Header.Symbol[:], "au2604")
+	r.OnMDUpdate(&md)
+	if s1.mdCount != 0 || s2.mdCount != 1 {
+		t.Errorf("md counts = %d/%d, want 0/1", s1.mdCount, s2.mdCount)
+	}
+
+	c1.orderIDMap[7] = s1
+	if !r.OnORSUpdate(&shm.ResponseMsg{OrderID: 7, StrategyID: 1001}) || s1.orsCount != 1 {
+		t.Error("response for 1001 not routed")
+	}
+	if r.OnORSUpdate(&shm.ResponseMsg{OrderID: 8, StrategyID: 9999}) {
+		t.Error("response for unknown strategy should not be routed")
+	}
+
+	r.Remove(1001)
+	if r.Has(1001) || !r.Has(1002) {
+		t.Error("Remove(1001) failed")
+	}
+}
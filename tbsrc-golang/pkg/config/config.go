@@ -12,6 +12,7 @@ type Config struct {
 	ORS      ORSConfig      `yaml:"ors"`
 	Strategy StrategyConfig `yaml:"strategy"`
 	System   SystemConfig   `yaml:"system"`
+	Offset   OffsetConfig   `yaml:"offset"`
 }
 
 // StrategyConfig holds strategy-level parameters.
@@ -46,6 +47,25 @@ type ExchangeCostsConfig struct {
 	SellExchContractTx float64 `yaml:"sell_exch_contract_tx"`
 }
 
+// OffsetConfig holds open/close offset planning parameters (Go 扩展).
+// 启用后 SendNewOrder 填写 PosDirection：平今/平昨/开仓
+type OffsetConfig struct {
+	Enabled  bool                           `yaml:"enabled"`
+	Lock     string                         `yaml:"lock"`     // never / auto（平今费高于开仓时锁仓）/ always
+	Products map[string]OffsetProductConfig `yaml:"products"` // 品种 → 费率，覆盖内置表
+}
+
+// OffsetProductConfig holds per-product fee rates (ratio = 成交金额比例, per_lot = 元/手).
+type OffsetProductConfig struct {
+	Multiplier    float64 `yaml:"multiplier"`
+	OpenRatio     float64 `yaml:"open_ratio"`
+	OpenPerLot    float64 `yaml:"open_per_lot"`
+	CloseYdRatio  float64 `yaml:"close_yd_ratio"`
+	CloseYdPerLot float64 `yaml:"close_yd_per_lot"`
+	CloseTdRatio  float64 `yaml:"close_td_ratio"`
+	CloseTdPerLot float64 `yaml:"close_td_per_lot"`
+}
+
 // SystemConfig holds system-level parameters.
 type SystemConfig struct {
 	LogLevel string `yaml:"log_level"`
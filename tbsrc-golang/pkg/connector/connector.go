@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"runtime"
+	"sync"
 	"sync/atomic"
 
 	"tbsrc-golang/pkg/shm"
@@ -47,6 +48,12 @@ type Connector struct {
 	mdCallback  MDCallback
 	orsCallback ORSCallback
 	running     atomic.Bool
+
+	// Go 扩展：重启后认领上一进程的在途订单
+	adoptMu       sync.RWMutex
+	adoptedIDs    map[uint32]bool // 额外接收回报的 clientID（上一进程的订单区间）
+	statusPending atomic.Bool     // ORDERSTATUS 查询进行中
+	statusStrat   atomic.Int32    // 查询的 StrategyID
 }
 
 // New creates a Connector that attaches to existing SHM queues.
@@ -196,6 +203,12 @@ func (c *Connector) ClientID() uint32 {
 	return c.clientID
 }
 
+// FirstClientID returns the ClientStore's initial client ID (Go 扩展).
+// [FirstClientID, ClientID) 是同一 ClientStore 生命周期内此前各进程分配的 clientID。
+func (c *Connector) FirstClientID() uint32 {
+	return uint32(c.clientStore.GetFirstClientIDValue())
+}
+
 // SendNewOrder enqueues a new order request to ORS.
 // It sets OrderID, Request_Type=NEWORDER, and enqueues.
 // C++: OrderID = clientID * ORDERID_RANGE + seq
@@ -207,6 +220,18 @@ func (c *Connector) SendNewOrder(req *shm.RequestMsg) uint32 {
 	return orderID
 }
 
+// ReserveOrderID allocates the OrderID of the next new order (Go 扩展).
+// Client 先用它填好请求写入日志，落盘成功后再 SendReservedOrder 发送。
+func (c *Connector) ReserveOrderID() uint32 {
+	return c.nextOrderID()
+}
+
+// SendReservedOrder enqueues a new order whose OrderID came from ReserveOrderID (Go 扩展).
+func (c *Connector) SendReservedOrder(req *shm.RequestMsg) {
+	req.Request_Type = shm.NEWORDER
+	c.reqQueue.Enqueue(req)
+}
+
 // SendCancelOrder enqueues a cancel order request to ORS.
 func (c *Connector) SendCancelOrder(req *shm.RequestMsg) {
 	req.Request_Type = shm.CANCELORDER
@@ -219,6 +244,50 @@ func (c *Connector) SendModifyOrder(req *shm.RequestMsg) {
 	c.reqQueue.Enqueue(req)
 }
 
+// QueryOrderStatus enqueues an ORDERSTATUS request (Go 扩展).
+// OrderID 取自本 client 区间作为查询ID，counter_bridge 以同一 OrderID 的 NULL_RESPONSE 结束应答。
+// 查询期间接收该 StrategyID 的 NULL_RESPONSE（在途订单属于上一进程的 clientID 区间），
+// 直到 EndOrderStatus。
+func (c *Connector) QueryOrderStatus(req *shm.RequestMsg) uint32 {
+	queryID := c.nextOrderID()
+	req.OrderID = queryID
+	req.Request_Type = shm.ORDERSTATUS
+	c.statusStrat.Store(req.StrategyID)
+	c.statusPending.Store(true)
+	c.reqQueue.Enqueue(req)
+	return queryID
+}
+
+// EndOrderStatus stops accepting ORDERSTATUS replies for other client IDs.
+func (c *Connector) EndOrderStatus() {
+	c.statusPending.Store(false)
+}
+
+// AdoptClientID routes responses of another client ID to this connector (Go 扩展).
+// 认领上一进程的在途订单后，其成交/撤单回报仍带原 OrderID，需要放行。
+func (c *Connector) AdoptClientID(clientID uint32) {
+	if clientID == c.clientID {
+		return
+	}
+	c.adoptMu.Lock()
+	defer c.adoptMu.Unlock()
+	if c.adoptedIDs == nil {
+		c.adoptedIDs = make(map[uint32]bool)
+	}
+	c.adoptedIDs[clientID] = true
+}
+
+// accepts 非本 clientID 的回报是否放行：已认领的区间，或查询中的在途订单应答
+func (c *Connector) accepts(resp *shm.ResponseMsg) bool {
+	if c.statusPending.Load() && resp.Response_Type == shm.NULL_RESPONSE &&
+		resp.StrategyID == c.statusStrat.Load() {
+		return true
+	}
+	c.adoptMu.RLock()
+	defer c.adoptMu.RUnlock()
+	return c.adoptedIDs[resp.OrderID/OrderIDRange]
+}
+
 // EnqueueMD enqueues a market data update (for tests / simulator).
 func (c *Connector) EnqueueMD(md *shm.MarketUpdateNew) {
 	c.mdQueue.Enqueue(md)
@@ -255,7 +324,7 @@ func (c *Connector) pollORS() {
 	for c.running.Load() {
 		if c.respQueue.Dequeue(&resp) {
 			// Filter: only process responses belonging to this client
-			if resp.OrderID/OrderIDRange == c.clientID {
+			if resp.OrderID/OrderIDRange == c.clientID || c.accepts(&resp) {
 				c.orsCallback(&resp)
 			}
 		} else {
@@ -203,3 +203,62 @@ func TestConnectorOrderIDGeneration(t *testing.T) {
 		t.Errorf("id3 = %d, want %d", id3, id2+1)
 	}
 }
+
+func TestConnectorOrderStatusAndAdopt(t *testing.T) {
+	var mu sync.Mutex
+	var received []uint32
+
+	orsCb := func(resp *shm.ResponseMsg) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, resp.OrderID)
+	}
+
+	conn, err := NewForTest(testConfig(), func(md *shm.MarketUpdateNew) {}, orsCb)
+	if err != nil {
+		t.Fatalf("NewForTest: %v", err)
+	}
+	defer conn.Destroy()
+
+	if conn.FirstClientID() != 1 {
+		t.Errorf("FirstClientID = %d, want 1", conn.FirstClientID())
+	}
+
+	conn.Start()
+
+	req := shm.RequestMsg{StrategyID: 92201}
+	queryID := conn.QueryOrderStatus(&req)
+	if req.Request_Type != shm.ORDERSTATUS || queryID/OrderIDRange != conn.ClientID() {
+		t.Errorf("query request type=%d id=%d", req.Request_Type, queryID)
+	}
+
+	old := uint32(999*OrderIDRange + 7) // 上一进程的在途订单
+	send := func(orderID uint32, respType shm.ResponseType, strategyID int32) {
+		resp := shm.ResponseMsg{Response_Type: respType, OrderID: orderID, StrategyID: strategyID}
+		conn.EnqueueResponse(&resp)
+		time.Sleep(50 * time.Millisecond)
+	}
+	send(old, shm.NULL_RESPONSE, 92201)         // 查询应答 → 放行
+	send(old+1, shm.NULL_RESPONSE, 92202)       // 其他策略 → 过滤
+	send(old, shm.TRADE_CONFIRM, 92201)         // 未认领 → 过滤
+	send(queryID, shm.NULL_RESPONSE, 92201)     // 结束标记（本 clientID）
+	conn.EndOrderStatus()
+	send(old, shm.NULL_RESPONSE, 92201)         // 查询结束 → 过滤
+	conn.AdoptClientID(999)
+	send(old, shm.CANCEL_ORDER_CONFIRM, 92201)  // 已认领 → 放行
+
+	conn.Stop()
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []uint32{old, queryID, old}
+	if len(received) != len(want) {
+		t.Fatalf("received %v, want %v", received, want)
+	}
+	for i := range want {
+		if received[i] != want[i] {
+			t.Errorf("received[%d] = %d, want %d", i, received[i], want[i])
+		}
+	}
+}
@@ -52,6 +52,41 @@ func TestStateReset(t *testing.T) {
 	}
 }
 
+func TestStateRollTradingDay(t *testing.T) {
+	s := &ExecutionState{
+		Netpos:        4,
+		NetposPass:    6,
+		NetposPassYtd: 2,
+		NetposAgg:     -2,
+		BuyTotalQty:   10,
+		SellTotalQty:  6,
+		RealisedPNL:   300,
+		NetPNL:        250,
+		MaxPNL:        400,
+		TradeCount:    12,
+		Active:        true,
+		BuyOpenOrders: 1,
+		TholdMaxPos:   20,
+	}
+
+	s.RollTradingDay()
+
+	if s.NetposPassYtd != 6 || s.NetposPass != 6 || s.NetposAgg != -2 {
+		t.Errorf("netpos pass/ytd/agg = %d/%d/%d, want 6/6/-2", s.NetposPass, s.NetposPassYtd, s.NetposAgg)
+	}
+	if s.Netpos != 0 || s.BuyTotalQty != 0 || s.SellTotalQty != 0 {
+		t.Errorf("Netpos = %d, BuyTotalQty = %f, SellTotalQty = %f, want 0", s.Netpos, s.BuyTotalQty, s.SellTotalQty)
+	}
+	if s.RealisedPNL != 0 || s.NetPNL != 0 || s.MaxPNL != 0 || s.TradeCount != 0 {
+		t.Errorf("RealisedPNL = %f, NetPNL = %f, MaxPNL = %f, TradeCount = %d, want 0",
+			s.RealisedPNL, s.NetPNL, s.MaxPNL, s.TradeCount)
+	}
+	// 状态标志、挂单、阈值保留
+	if !s.Active || s.BuyOpenOrders != 1 || s.TholdMaxPos != 20 {
+		t.Errorf("Active = %v, BuyOpenOrders = %d, TholdMaxPos = %d, want preserved", s.Active, s.BuyOpenOrders, s.TholdMaxPos)
+	}
+}
+
 func TestCalculatePNL_Long(t *testing.T) {
 	// 场景：持有多头 10 手，买入均价 5800，当前 bid=5810
 	// priceMultiplier = 15
@@ -0,0 +1,92 @@
+package execution
+
+import (
+	"time"
+
+	"tbsrc-golang/pkg/types"
+)
+
+// 追单参数，与 PairwiseArb.SendAggressiveOrder 一致
+// 参考: PairwiseArbStrategy.cpp:701-800
+const (
+	aggRetryIntervalNs = uint64(500 * time.Millisecond) // >500ms 回到 BBO 重新吃单
+	aggMaxRepeat       = int32(3)                       // 第 3 次用 SLOP 大跳，超过后放弃
+)
+
+// HedgePrice 主动追单价格（Go 扩展，SpreadExecutor / PairwiseArb / BasketArb 共用）
+// 阶梯与 C++ SendAggressiveOrder 一致: 首单以对价 bbo 下单，第 1-2 次重试 bbo ± repeat tick，
+// 第 3 次 bbo ± SLOP tick；maxSlip > 0 时价格不超过本轮首单对价 refPx ± maxSlip tick（HEDGE_MAX_SLIP）
+func HedgePrice(side types.TransactionType, bbo, tick float64, first bool, repeat int32, slop int,
+	refPx float64, maxSlip int32) float64 {
+
+	sign := 1.0
+	if side == types.Sell {
+		sign = -1.0
+	}
+	price := bbo
+	if !first {
+		if repeat < aggMaxRepeat {
+			price = bbo + sign*tick*float64(repeat)
+		} else {
+			price = bbo + sign*tick*float64(slop)
+		}
+	}
+	if maxSlip > 0 && refPx > 0 {
+		limit := refPx + sign*tick*float64(maxSlip)
+		if sign*(price-limit) > 0 {
+			price = limit
+		}
+	}
+	return price
+}
+
+// HedgeLadder 一条对冲腿的追单状态 — 对应 C++ agg_repeat / last_agg_time / last_agg_side
+type HedgeLadder struct {
+	AggRepeat   int32
+	LastAggTS   uint64 // 纳秒
+	LastAggSide types.TransactionType
+	RefPx       float64 // 本轮对冲首单的 BBO 价格（HEDGE_MAX_SLIP 基准），0 表示无进行中的对冲
+}
+
+// Next 下一笔追单价格；first 表示首单（换方向或距上次超过 500ms），
+// giveUp 表示重试次数用尽（C++: agg_repeat > 3）
+func (h *HedgeLadder) Next(side types.TransactionType, bbo, tick float64, slop int, maxSlip int32,
+	now uint64) (price float64, first, giveUp bool) {
+
+	first = h.LastAggSide != side || now-h.LastAggTS > aggRetryIntervalNs
+	if !first && h.AggRepeat > aggMaxRepeat {
+		return 0, false, true
+	}
+	if h.RefPx == 0 {
+		h.RefPx = bbo
+	}
+	return HedgePrice(side, bbo, tick, first, h.AggRepeat, slop, h.RefPx, maxSlip), first, false
+}
+
+// Sent 追单报出后记录，重试单递增 AggRepeat
+func (h *HedgeLadder) Sent(side types.TransactionType, first bool, now uint64) {
+	if !first {
+		h.AggRepeat++
+	}
+	h.LastAggTS = now
+	h.LastAggSide = side
+}
+
+// Reset 成交后重置重试次数（C++: TRADE_CONFIRM 时 agg_repeat = 1）
+func (h *HedgeLadder) Reset() {
+	h.AggRepeat = 1
+}
+
+// Settle 对冲配齐，结束本轮（清除滑点基准）
+func (h *HedgeLadder) Settle() {
+	h.RefPx = 0
+}
+
+// LegTimedOut 瘸腿是否已超过 LEG_TIMEOUT（毫秒，0 表示不限制）
+// since 为出现敞口的时间（纳秒，0 表示已配齐）
+func LegTimedOut(since, now uint64, timeoutMs int64) bool {
+	if since == 0 || timeoutMs <= 0 || now < since {
+		return false
+	}
+	return now-since > uint64(timeoutMs)*uint64(time.Millisecond)
+}
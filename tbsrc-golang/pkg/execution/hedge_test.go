@@ -0,0 +1,82 @@
+package execution
+
+import (
+	"testing"
+	"time"
+
+	"tbsrc-golang/pkg/types"
+)
+
+func TestHedgePrice(t *testing.T) {
+	cases := []struct {
+		name    string
+		side    types.TransactionType
+		first   bool
+		repeat  int32
+		refPx   float64
+		maxSlip int32
+		want    float64
+	}{
+		{"buy first", types.Buy, true, 1, 100, 0, 100},
+		{"buy repeat 2", types.Buy, false, 2, 100, 0, 102},
+		{"buy slop", types.Buy, false, 3, 100, 0, 110},
+		{"sell repeat 1", types.Sell, false, 1, 100, 0, 99},
+		{"sell slop", types.Sell, false, 3, 100, 0, 90},
+		{"buy slop capped", types.Buy, false, 3, 99, 4, 103},
+		{"sell slop capped", types.Sell, false, 3, 101, 4, 97},
+	}
+	for _, c := range cases {
+		got := HedgePrice(c.side, 100, 1, c.first, c.repeat, 10, c.refPx, c.maxSlip)
+		if got != c.want {
+			t.Errorf("%s: price = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestHedgeLadder(t *testing.T) {
+	var h HedgeLadder
+	h.Reset()
+	now := uint64(time.Second)
+
+	price, first, giveUp := h.Next(types.Buy, 100, 1, 10, 0, now)
+	if !first || giveUp || price != 100 || h.RefPx != 100 {
+		t.Fatalf("first = (%v, %v, %v) ref=%v", price, first, giveUp, h.RefPx)
+	}
+	h.Sent(types.Buy, first, now)
+
+	for i, want := range []float64{101, 102, 110} {
+		now += uint64(100 * time.Millisecond)
+		price, first, giveUp = h.Next(types.Buy, 100, 1, 10, 0, now)
+		if first || giveUp || price != want {
+			t.Fatalf("retry %d = (%v, %v, %v), want %v", i+1, price, first, giveUp, want)
+		}
+		h.Sent(types.Buy, first, now)
+	}
+	if _, _, giveUp = h.Next(types.Buy, 100, 1, 10, 0, now+1); !giveUp {
+		t.Error("expected give up after 3 retries")
+	}
+	// 超过 500ms 回到对价
+	if price, first, giveUp = h.Next(types.Buy, 100, 1, 10, 0, now+uint64(time.Second)); !first || giveUp || price != 100 {
+		t.Errorf("after interval = (%v, %v, %v), want first at bbo", price, first, giveUp)
+	}
+	h.Settle()
+	if h.RefPx != 0 {
+		t.Errorf("RefPx after Settle = %v", h.RefPx)
+	}
+}
+
+func TestLegTimedOut(t *testing.T) {
+	ms := uint64(time.Millisecond)
+	if LegTimedOut(0, 10_000*ms, 100) {
+		t.Error("balanced leg should not time out")
+	}
+	if LegTimedOut(ms, 10_000*ms, 0) {
+		t.Error("LEG_TIMEOUT=0 should disable the check")
+	}
+	if LegTimedOut(ms, 101*ms, 100) {
+		t.Error("100ms elapsed should not exceed 100ms timeout")
+	}
+	if !LegTimedOut(ms, 102*ms, 100) {
+		t.Error("101ms elapsed should exceed 100ms timeout")
+	}
+}
@@ -0,0 +1,323 @@
+package execution
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"tbsrc-golang/pkg/client"
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/types"
+)
+
+// 冰山/智能被动报价（Go 扩展，C++ 无对应）
+//
+// 薄盘远月合约上一次挂出全部 Size 会暴露意图。ICEBERG_DISPLAY > 0 时，LegManager 的 STANDARD
+// 新报价只显示部分数量，其余作为该价位的隐藏储备：显示单全部成交后，经
+// [ICEBERG_DELAY_MIN, ICEBERG_DELAY_MAX] 毫秒随机延迟，以 ICEBERG_DISPLAY*(1±ICEBERG_VARIANCE)
+// 的随机数量在同价补单，直到储备用完。
+//
+// 显示单就是普通订单，照常登记在 OrdMap/BidMap/AskMap，按价格/ID 撤单的原有逻辑不变：
+// 撤单、改单、拒单或平仓时丢弃该价位的储备。等待补单期间该价位视为已占用（SendNewOrder 拒绝同价新单，
+// SendCancelOrderByPrice 丢弃储备）。储备数量计入 BuyOpenQty/SellOpenQty，策略的持仓上限检查看到的仍是完整意图。
+
+// reserveKey 储备按方向+价格索引，与 BidMap/AskMap 一致
+type reserveKey struct {
+	side  types.TransactionType
+	price float64
+}
+
+// reserve 一个价位的冰山储备
+type reserve struct {
+	side     types.TransactionType
+	price    float64
+	level    int32
+	ordType  types.OrderHitType
+	hidden   int32 // 未显示的剩余数量
+	display  int32 // 基准显示数量（已按 lot 换算）
+	lot      int32 // 显示数量取整单位
+	variance float64
+	delayMin uint64 // 纳秒
+	delayMax uint64
+	childID  uint32 // 当前显示单，0 表示等待补单
+	refillAt uint64 // 补单时间（交易所纳秒时间戳）
+	cb       client.StrategyCallback
+}
+
+// nextDisplay 下一笔显示数量：基准量按 variance 随机浮动，按 lot 取整，不超过 limit
+func (r *reserve) nextDisplay(limit int32) int32 {
+	d := float64(r.display)
+	if r.variance > 0 {
+		d *= 1 + r.variance*(2*rand.Float64()-1)
+	}
+	qty := int32(math.Round(d/float64(r.lot))) * r.lot
+	if qty < r.lot {
+		qty = r.lot
+	}
+	if qty > limit {
+		qty = limit
+	}
+	return qty
+}
+
+// nextDelay 补单随机延迟（纳秒）
+func (r *reserve) nextDelay() uint64 {
+	if r.delayMax > r.delayMin {
+		return r.delayMin + rand.Uint64N(r.delayMax-r.delayMin+1)
+	}
+	return r.delayMin
+}
+
+// lotUnits 一手对应的下单数量（SendInLots 时为 1）
+func (lm *LegManager) lotUnits() int32 {
+	if lm.Inst.SendInLots || lm.Inst.LotSize <= 1 {
+		return 1
+	}
+	return int32(lm.Inst.LotSize)
+}
+
+// sendNewQuote 新报价单：STANDARD 单按 SMART_QUOTE 调整价格、按 ICEBERG_DISPLAY 拆出显示量，
+// 其他类型（CROSS/MATCH 等）原样发送
+func (lm *LegManager) sendNewQuote(side types.TransactionType, price float64, qty int32,
+	level int32, ordType types.OrderHitType) (uint32, bool) {
+
+	thold := lm.Thold
+	if ordType != types.HitStandard || thold == nil {
+		return lm.Orders.SendNewOrder(side, price, qty, level, lm.Inst, types.Quote, ordType, lm)
+	}
+	if thold.SmartQuote {
+		price = lm.quotePrice(side, price, level)
+	}
+	lot := lm.lotUnits()
+	display := thold.IcebergDisplay * lot
+	if display <= 0 || qty <= display {
+		return lm.Orders.SendNewOrder(side, price, qty, level, lm.Inst, types.Quote, ordType, lm)
+	}
+
+	r := &reserve{
+		side:     side,
+		price:    price,
+		level:    level,
+		ordType:  ordType,
+		display:  display,
+		lot:      lot,
+		variance: math.Min(math.Max(thold.IcebergVariance, 0), 1),
+		delayMin: uint64(max(thold.IcebergDelayMin, 0)) * uint64(time.Millisecond),
+		delayMax: uint64(max(thold.IcebergDelayMax, 0)) * uint64(time.Millisecond),
+		cb:       lm,
+	}
+	return lm.Orders.sendIceberg(lm.Inst, r, qty)
+}
+
+// sendIceberg 发送冰山单的首个显示单并登记储备
+func (om *OrderManager) sendIceberg(inst *instrument.Instrument, r *reserve, qty int32) (uint32, bool) {
+	show := r.nextDisplay(qty)
+	orderID, ok := om.SendNewOrder(r.side, r.price, show, r.level, inst, types.Quote, r.ordType, r.cb)
+	if !ok || show >= qty {
+		return orderID, ok
+	}
+
+	r.hidden = qty - show
+	r.childID = orderID
+	om.adjustReserveQty(r.side, r.hidden)
+	if om.reserves == nil {
+		om.reserves = make(map[reserveKey]*reserve)
+	}
+	om.reserves[reserveKey{r.side, r.price}] = r
+
+	log.Printf("[Iceberg] %s side=%d price=%.2f display=%d hidden=%d orderID=%d",
+		inst.Symbol, r.side, r.price, show, r.hidden, orderID)
+	return orderID, true
+}
+
+// adjustReserveQty 储备计入/移出挂单量
+func (om *OrderManager) adjustReserveQty(side types.TransactionType, qty int32) {
+	if side == types.Buy {
+		om.State.BuyOpenQty += float64(qty)
+	} else {
+		om.State.SellOpenQty += float64(qty)
+	}
+}
+
+// reservePending 价位上有等待补单的储备（此时 priceMap 中无对应订单）
+func (om *OrderManager) reservePending(side types.TransactionType, price float64) bool {
+	r, ok := om.reserves[reserveKey{side, price}]
+	return ok && r.childID == 0
+}
+
+// dropReserve 丢弃价位的储备
+func (om *OrderManager) dropReserve(side types.TransactionType, price float64) bool {
+	key := reserveKey{side, price}
+	r, ok := om.reserves[key]
+	if !ok {
+		return false
+	}
+	om.adjustReserveQty(side, -r.hidden)
+	delete(om.reserves, key)
+	log.Printf("[Iceberg] dropped reserve side=%d price=%.2f hidden=%d", side, price, r.hidden)
+	return true
+}
+
+// dropChildReserve 丢弃以 ord 为显示单的储备
+func (om *OrderManager) dropChildReserve(ord *types.OrderStats) {
+	if r, ok := om.reserves[reserveKey{ord.Side, ord.Price}]; ok && r.childID == ord.OrderID {
+		om.dropReserve(ord.Side, ord.Price)
+	}
+}
+
+// DropReserves 丢弃全部冰山储备（平仓/撤销全部挂单时调用，已显示的订单不受影响）
+func (om *OrderManager) DropReserves() {
+	for key := range om.reserves {
+		om.dropReserve(key.side, key.price)
+	}
+}
+
+// HiddenQty 返回指定方向的冰山储备总量
+func (om *OrderManager) HiddenQty(side types.TransactionType) int32 {
+	var n int32
+	for key, r := range om.reserves {
+		if key.side == side {
+			n += r.hidden
+		}
+	}
+	return n
+}
+
+// onChildRemoved 显示单移出 maps：全部成交则安排补单，否则（撤单/拒单）丢弃储备
+func (om *OrderManager) onChildRemoved(ord *types.OrderStats) {
+	r, ok := om.reserves[reserveKey{ord.Side, ord.Price}]
+	if !ok || r.childID != ord.OrderID {
+		return
+	}
+	if ord.Status != types.StatusTraded {
+		om.dropReserve(ord.Side, ord.Price)
+		return
+	}
+	r.childID = 0
+	r.refillAt = om.State.ExchTS + r.nextDelay()
+}
+
+// refillReserves 到期的储备在原价位补显示单（行情回调与成交回报时调用）
+func (om *OrderManager) refillReserves(inst *instrument.Instrument) {
+	for key, r := range om.reserves {
+		if r.childID != 0 || om.State.ExchTS < r.refillAt {
+			continue
+		}
+		// 储备转为显示单：先移出储备量，addOrder 重新计入显示量
+		show := r.nextDisplay(r.hidden)
+		om.adjustReserveQty(r.side, -show)
+		r.hidden -= show
+		delete(om.reserves, key)
+
+		orderID, ok := om.SendNewOrder(r.side, r.price, show, r.level, inst, types.Quote, r.ordType, r.cb)
+		if !ok {
+			om.adjustReserveQty(r.side, -r.hidden)
+			log.Printf("[Iceberg] refill failed side=%d price=%.2f, dropped hidden=%d", r.side, r.price, r.hidden)
+			continue
+		}
+		if r.hidden > 0 {
+			r.childID = orderID
+			om.reserves[key] = r
+		}
+		log.Printf("[Iceberg] %s refill side=%d price=%.2f display=%d hidden=%d orderID=%d",
+			inst.Symbol, r.side, r.price, show, r.hidden, orderID)
+	}
+}
+
+// bookImbalance 一档盘口失衡 (bidQty-askQty)/(bidQty+askQty)，范围 [-1, 1]；正值买压占优
+func bookImbalance(inst *instrument.Instrument) float64 {
+	bq, aq := inst.BidQty[0], inst.AskQty[0]
+	if bq <= 0 || aq <= 0 {
+		return 0
+	}
+	return (bq - aq) / (bq + aq)
+}
+
+// queueAhead 价位上排在本方之前的数量估计（盘口量扣除本方在该价位的挂单）；价位不在行情簿中时 ok=false
+func (lm *LegManager) queueAhead(side types.TransactionType, price float64) (float64, bool) {
+	inst := lm.Inst
+	px, qty, valid := &inst.BidPx, &inst.BidQty, inst.ValidBids
+	ord := lm.Orders.BidMap[price]
+	if side == types.Sell {
+		px, qty, valid = &inst.AskPx, &inst.AskQty, inst.ValidAsks
+		ord = lm.Orders.AskMap[price]
+	}
+	for i := int32(0); i < valid && i < int32(instrument.BookDepth); i++ {
+		if px[i] != price {
+			continue
+		}
+		ahead := qty[i]
+		if ord != nil {
+			ahead -= float64(ord.OpenQty)
+		}
+		return math.Max(ahead, 0), true
+	}
+	return 0, false
+}
+
+// quotePrice 智能被动报价（SMART_QUOTE），只调整挂在一档的报价：
+//   - 对方失衡 >= QUOTE_BACKOFF_IMB：退后一档，避免在即将被打穿的价位排队
+//   - 本方失衡 >= QUOTE_IMPROVE_IMB 且前方排队 >= QUOTE_QUEUE_AHEAD 手：改善一档
+//     （不穿越对价，且须通过 QuoteImproveOK 的门槛复核）
+//   - 否则跟价
+func (lm *LegManager) quotePrice(side types.TransactionType, price float64, level int32) float64 {
+	inst := lm.Inst
+	thold := lm.Thold
+	tick := inst.TickSize
+	if level != 0 || tick <= 0 {
+		return price
+	}
+
+	imb := bookImbalance(inst)
+	touch, opp, dir := inst.BidPx[0], inst.AskPx[0], 1.0
+	if side == types.Sell {
+		touch, opp, dir = inst.AskPx[0], inst.BidPx[0], -1.0
+		imb = -imb
+	}
+	if price != touch || touch <= 0 || opp <= 0 {
+		return price
+	}
+
+	if thold.QuoteBackoffImb > 0 && -imb >= thold.QuoteBackoffImb {
+		return price - dir*tick
+	}
+	if thold.QuoteImproveImb > 0 && imb >= thold.QuoteImproveImb {
+		ahead, _ := lm.queueAhead(side, price)
+		improved := price + dir*tick
+		if ahead >= thold.QuoteQueueAhead*float64(lm.lotUnits()) && (improved-opp)*dir < 0 &&
+			(lm.QuoteImproveOK == nil || lm.QuoteImproveOK(side, improved)) {
+			return improved
+		}
+	}
+	return price
+}
+
+// manageQuotes 行情更新后维护被动挂单（SMART_QUOTE）：
+// 按盘口收缩前方排队量估计（新挂单只会排在本方之后）；对方失衡 >= QUOTE_BACKOFF_IMB 时
+// 撤掉排在队首（前方排队 < QUOTE_QUEUE_AHEAD 手）的一档挂单，由策略下次报价按 quotePrice 退后重挂
+func (lm *LegManager) manageQuotes() {
+	inst := lm.Inst
+	thold := lm.Thold
+	imb := bookImbalance(inst)
+	front := thold.QuoteQueueAhead * float64(lm.lotUnits())
+
+	for _, ord := range lm.Orders.OrdMap {
+		if ord.OrdType != types.HitStandard ||
+			(ord.Status != types.StatusNewConfirm && ord.Status != types.StatusModifyConfirm) {
+			continue
+		}
+		if ahead, ok := lm.queueAhead(ord.Side, ord.Price); ok && ahead < ord.QuantAhead {
+			ord.QuantAhead = ahead
+		}
+
+		touch, adverse := inst.BidPx[0], -imb
+		if ord.Side == types.Sell {
+			touch, adverse = inst.AskPx[0], imb
+		}
+		if thold.QuoteBackoffImb > 0 && ord.Price == touch && adverse >= thold.QuoteBackoffImb &&
+			ord.QuantAhead < front {
+			lm.Orders.SendCancelOrderByID(inst, ord.OrderID)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package execution
+
+import (
+	"testing"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+func confirmAndFill(lm *LegManager, orderID uint32, price float64, qty int32) {
+	lm.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM, OrderID: orderID})
+	lm.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: orderID, Price: price, Quantity: qty})
+}
+
+// TestIceberg_DisplayAndRefill 显示单成交后延迟补单，储备计入挂单量
+func TestIceberg_DisplayAndRefill(t *testing.T) {
+	lm, inst := newTestLegManager()
+	lm.Thold.IcebergDisplay = 2
+	lm.Thold.IcebergDelayMin = 100
+	lm.Thold.IcebergDelayMax = 100
+	lm.State.ExchTS = 1_000_000_000
+
+	if !lm.SendBidOrder2(shm.NEWORDER, 0, 5819, types.HitStandard, 5, 0, 0) {
+		t.Fatal("SendBidOrder2 failed")
+	}
+	ord := lm.Orders.BidMap[5819]
+	if ord == nil || ord.Qty != 2 {
+		t.Fatalf("display order = %+v, want qty 2", ord)
+	}
+	if lm.State.BuyOpenQty != 5 || lm.Orders.HiddenQty(types.Buy) != 3 {
+		t.Errorf("BuyOpenQty = %.0f, hidden = %d, want 5, 3", lm.State.BuyOpenQty, lm.Orders.HiddenQty(types.Buy))
+	}
+
+	confirmAndFill(lm, ord.OrderID, 5819, 2)
+	if _, ok := lm.Orders.BidMap[5819]; ok {
+		t.Fatal("refill should wait for the delay")
+	}
+	// 等待补单期间该价位被占用
+	if lm.SendBidOrder2(shm.NEWORDER, 0, 5819, types.HitStandard, 5, 0, 0) {
+		t.Error("new order at a pending iceberg price should be rejected")
+	}
+
+	lm.State.ExchTS += 50_000_000
+	lm.Orders.refillReserves(inst)
+	if _, ok := lm.Orders.BidMap[5819]; ok {
+		t.Fatal("refilled before delay elapsed")
+	}
+	lm.State.ExchTS += 50_000_000
+	lm.Orders.refillReserves(inst)
+	ord = lm.Orders.BidMap[5819]
+	if ord == nil || ord.Qty != 2 {
+		t.Fatalf("refill = %+v, want qty 2", ord)
+	}
+	if lm.State.BuyOpenQty != 3 || lm.Orders.HiddenQty(types.Buy) != 1 {
+		t.Errorf("BuyOpenQty = %.0f, hidden = %d, want 3, 1", lm.State.BuyOpenQty, lm.Orders.HiddenQty(types.Buy))
+	}
+
+	// 最后一笔显示剩余 1 手，储备用完
+	confirmAndFill(lm, ord.OrderID, 5819, 2)
+	lm.State.ExchTS += 100_000_000
+	lm.Orders.refillReserves(inst)
+	ord = lm.Orders.BidMap[5819]
+	if ord == nil || ord.Qty != 1 {
+		t.Fatalf("last refill = %+v, want qty 1", ord)
+	}
+	confirmAndFill(lm, ord.OrderID, 5819, 1)
+	if lm.State.Netpos != 5 || lm.State.BuyOpenQty != 0 || len(lm.Orders.reserves) != 0 {
+		t.Errorf("Netpos = %d, BuyOpenQty = %.0f, reserves = %d, want 5, 0, 0",
+			lm.State.Netpos, lm.State.BuyOpenQty, len(lm.Orders.reserves))
+	}
+}
+
+// TestIceberg_CancelDropsReserve 按价格/ID 撤单丢弃储备；撤单在途时成交不再补单
+func TestIceberg_CancelDropsReserve(t *testing.T) {
+	lm, inst := newTestLegManager()
+	lm.Thold.IcebergDisplay = 1
+
+	lm.SendAskOrder2(shm.NEWORDER, 0, 5820, types.HitStandard, 4, 0, 0)
+	ord := lm.Orders.AskMap[5820]
+	lm.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM, OrderID: ord.OrderID})
+	if !lm.Orders.SendCancelOrderByPrice(inst, 5820, types.Sell) {
+		t.Fatal("cancel by price failed")
+	}
+	if lm.Orders.HiddenQty(types.Sell) != 0 || lm.State.SellOpenQty != 1 {
+		t.Errorf("hidden = %d, SellOpenQty = %.0f, want 0, 1", lm.Orders.HiddenQty(types.Sell), lm.State.SellOpenQty)
+	}
+	lm.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: ord.OrderID, Price: 5820, Quantity: 1})
+	lm.Orders.refillReserves(inst)
+	if len(lm.Orders.AskMap) != 0 || lm.State.SellOpenQty != 0 {
+		t.Errorf("AskMap = %d, SellOpenQty = %.0f, want no refill", len(lm.Orders.AskMap), lm.State.SellOpenQty)
+	}
+
+	// 等待补单的价位按价格撤单
+	lm.Thold.IcebergDelayMin = 100
+	lm.SendAskOrder2(shm.NEWORDER, 0, 5821, types.HitStandard, 3, 0, 0)
+	confirmAndFill(lm, lm.Orders.AskMap[5821].OrderID, 5821, 1)
+	if !lm.Orders.SendCancelOrderByPrice(inst, 5821, types.Sell) {
+		t.Error("cancel by price on pending reserve should drop it")
+	}
+	lm.State.ExchTS += 200_000_000
+	lm.Orders.refillReserves(inst)
+	if len(lm.Orders.AskMap) != 0 || lm.State.SellOpenQty != 0 {
+		t.Errorf("AskMap = %d, SellOpenQty = %.0f after dropping pending reserve", len(lm.Orders.AskMap), lm.State.SellOpenQty)
+	}
+}
+
+// TestIceberg_CrossNotSplit 对冲 CROSS 单不拆分
+func TestIceberg_CrossNotSplit(t *testing.T) {
+	lm, _ := newTestLegManager()
+	lm.Thold.IcebergDisplay = 1
+	lm.SendBidOrder2(shm.NEWORDER, 0, 5820, types.HitCross, 5, 0, 0)
+	if ord := lm.Orders.BidMap[5820]; ord == nil || ord.Qty != 5 {
+		t.Errorf("cross order = %+v, want full qty 5", ord)
+	}
+}
+
+// TestSmartQuote_ImproveBackoffJoin 按盘口失衡与前方排队量改善/退后/跟价
+func TestSmartQuote_ImproveBackoffJoin(t *testing.T) {
+	lm, inst := newTestLegManager()
+	lm.Thold.SmartQuote = true
+	lm.Thold.QuoteQueueAhead = 50
+	lm.Thold.QuoteImproveImb = 0.3
+	lm.Thold.QuoteBackoffImb = 0.5
+	inst.AskPx[0] = 5822 // 价差 3 tick，可改善
+
+	// 买压占优 (100-40)/140 ≈ 0.43，前方 100 手 → 改善一档
+	inst.AskQty[0] = 40
+	if got := lm.quotePrice(types.Buy, 5819, 0); got != 5820 {
+		t.Errorf("improve: got %.0f, want 5820", got)
+	}
+	// 父策略门槛复核不通过 → 跟价
+	var checked float64
+	lm.QuoteImproveOK = func(side types.TransactionType, price float64) bool { checked = price; return false }
+	if got := lm.quotePrice(types.Buy, 5819, 0); got != 5819 || checked != 5820 {
+		t.Errorf("improve rejected by strategy: got %.0f (checked %.0f), want 5819", got, checked)
+	}
+	lm.QuoteImproveOK = nil
+	// 前方排队不足 → 跟价
+	inst.BidQty[0] = 45
+	inst.AskQty[0] = 10
+	if got := lm.quotePrice(types.Buy, 5819, 0); got != 5819 {
+		t.Errorf("join: got %.0f, want 5819", got)
+	}
+	// 卖压占优 (10-100)/110 ≈ -0.82 → 买单退后一档，卖单改善受价差限制
+	inst.BidQty[0] = 10
+	inst.AskQty[0] = 100
+	if got := lm.quotePrice(types.Buy, 5819, 0); got != 5818 {
+		t.Errorf("backoff: got %.0f, want 5818", got)
+	}
+	if got := lm.quotePrice(types.Sell, 5822, 0); got != 5821 {
+		t.Errorf("sell improve: got %.0f, want 5821", got)
+	}
+	// 非一档不调整；改善不穿越对价
+	if got := lm.quotePrice(types.Buy, 5819, 1); got != 5819 {
+		t.Errorf("level 1: got %.0f, want 5819", got)
+	}
+	inst.AskPx[0] = 5820
+	if got := lm.quotePrice(types.Sell, 5820, 0); got != 5820 {
+		t.Errorf("no cross: got %.0f, want 5820", got)
+	}
+}
+
+// TestSmartQuote_BackoffCancelsFrontOfQueue 对方失衡时撤掉排在队首的挂单
+func TestSmartQuote_BackoffCancelsFrontOfQueue(t *testing.T) {
+	lm, inst := newTestLegManager()
+	lm.Thold.SmartQuote = true
+	lm.Thold.QuoteQueueAhead = 20
+	lm.Thold.QuoteBackoffImb = 0.5
+
+	front := insertOrder(lm.Orders, 11, types.Buy, 5819, 1, types.HitStandard)
+	front.Status = types.StatusNewConfirm
+	front.QuantAhead = 100
+
+	// 盘口收缩到 10（含本方 1 手）→ 前方估计 9，排在队首；卖压 (10-80)/90 < -0.5 → 撤单
+	inst.BidQty[0] = 10
+	lm.manageQuotes()
+	if front.QuantAhead != 9 {
+		t.Errorf("QuantAhead = %.0f, want 9", front.QuantAhead)
+	}
+	if front.Status != types.StatusCancelOrder {
+		t.Errorf("Status = %d, want CancelOrder", front.Status)
+	}
+
+	// 排队靠后的挂单保留
+	back := insertOrder(lm.Orders, 12, types.Buy, 5818, 1, types.HitStandard)
+	back.Status = types.StatusNewConfirm
+	back.QuantAhead = 30
+	inst.BidPx[0] = 5818
+	inst.BidQty[0] = 40
+	inst.AskQty[0] = 200
+	lm.manageQuotes()
+	if back.Status != types.StatusNewConfirm {
+		t.Errorf("Status = %d, want NewConfirm", back.Status)
+	}
+}
@@ -27,6 +27,10 @@ type LegManager struct {
 	// 再委托给 ExtraStrategy。Go 中 client.orderIDMap 注册的是 LegManager，
 	// 设置此字段后 LegManager.ORSCallBack 会转发到父策略而非直接处理。
 	ORSCallbackOverride client.StrategyCallback
+
+	// QuoteImproveOK 父策略对 SMART_QUOTE 改善一档后价格的复核（Go 扩展），nil 表示不限制
+	// 策略只按原价检查了挂单门槛，改善后的价格须由策略按同一门槛重新确认
+	QuoteImproveOK func(side types.TransactionType, price float64) bool
 }
 
 // NewLegManager 创建 LegManager
@@ -77,8 +81,7 @@ func (lm *LegManager) SendBidOrder(reqType shm.RequestType, level int32,
 	}
 
 	if reqType == shm.NEWORDER {
-		lm.Orders.SendNewOrder(types.Buy, price, actualQty, level, lm.Inst,
-			types.Quote, ordType, lm)
+		lm.sendNewQuote(types.Buy, price, actualQty, level, ordType)
 	} else {
 		lm.Orders.SendModifyOrder(lm.Inst, ordID, price, actualQty, level,
 			types.Quote, ordType)
@@ -104,8 +107,7 @@ func (lm *LegManager) SendAskOrder(reqType shm.RequestType, level int32,
 	}
 
 	if reqType == shm.NEWORDER {
-		lm.Orders.SendNewOrder(types.Sell, price, actualQty, level, lm.Inst,
-			types.Quote, ordType, lm)
+		lm.sendNewQuote(types.Sell, price, actualQty, level, ordType)
 	} else {
 		lm.Orders.SendModifyOrder(lm.Inst, ordID, price, actualQty, level,
 			types.Quote, ordType)
@@ -132,8 +134,7 @@ func (lm *LegManager) SendBidOrder2(reqType shm.RequestType, level int32,
 	}
 
 	if reqType == shm.NEWORDER {
-		_, ok := lm.Orders.SendNewOrder(types.Buy, price, actualQty, level, lm.Inst,
-			types.Quote, ordType, lm)
+		_, ok := lm.sendNewQuote(types.Buy, price, actualQty, level, ordType)
 		return ok
 	}
 	return lm.Orders.SendModifyOrder(lm.Inst, ordID, price, actualQty, level,
@@ -160,8 +161,7 @@ func (lm *LegManager) SendAskOrder2(reqType shm.RequestType, level int32,
 	}
 
 	if reqType == shm.NEWORDER {
-		_, ok := lm.Orders.SendNewOrder(types.Sell, price, actualQty, level, lm.Inst,
-			types.Quote, ordType, lm)
+		_, ok := lm.sendNewQuote(types.Sell, price, actualQty, level, ordType)
 		return ok
 	}
 	return lm.Orders.SendModifyOrder(lm.Inst, ordID, price, actualQty, level,
@@ -174,6 +174,8 @@ func (lm *LegManager) SendAskOrder2(reqType shm.RequestType, level int32,
 // C++ 逻辑:
 //   1. 更新 LTP
 //   2. 仅在 BBO 变化时重算 PNL
+//
+// Go 扩展: 到期的冰山储备补单；SMART_QUOTE 时维护被动挂单（见 iceberg.go）
 func (lm *LegManager) MDCallBack(inst *instrument.Instrument, md *shm.MarketUpdateNew) {
 	// C++: 更新 LTP（成交类型更新）
 	if md.Data.LastTradedPrice > 0 {
@@ -188,6 +190,11 @@ func (lm *LegManager) MDCallBack(inst *instrument.Instrument, md *shm.MarketUpda
 		lm.State.BestBidLastPNL = inst.BidPx[0]
 		lm.State.BestAskLastPNL = inst.AskPx[0]
 	}
+	lm.Orders.tcaQuote(inst)
+	lm.Orders.refillReserves(inst)
+	if lm.Thold != nil && lm.Thold.SmartQuote {
+		lm.manageQuotes()
+	}
 }
 
 // ORSCallBack ORS 回调
@@ -218,6 +225,9 @@ func (lm *LegManager) HandleSquareoff() {
 	inst := lm.Inst
 	state := lm.State
 
+	// Go 扩展: 平仓时不再补冰山单
+	lm.Orders.DropReserves()
+
 	// C++: 如果已平且退出中且无挂单，停用
 	if state.Netpos == 0 && state.OnExit && len(lm.Orders.AskMap) == 0 && len(lm.Orders.BidMap) == 0 {
 		if state.Active {
@@ -318,6 +328,7 @@ func (lm *LegManager) HandleTimeLimitSquareoff(sqrOffAgg int) {
 	if state.Netpos == 0 {
 		return
 	}
+	lm.Orders.DropReserves()
 
 	// C++: 根据 SQROFF_AGG 决定定价策略
 	// 参考: ExecutionStrategy.cpp:2449-2455
@@ -371,4 +382,5 @@ func (lm *LegManager) Reset() {
 	lm.Orders.OrdMap = make(map[uint32]*types.OrderStats)
 	lm.Orders.BidMap = make(map[float64]*types.OrderStats)
 	lm.Orders.AskMap = make(map[float64]*types.OrderStats)
+	lm.Orders.reserves = nil
 }
@@ -2,9 +2,11 @@ package execution
 
 import (
 	"log"
+	"strconv"
 
 	"tbsrc-golang/pkg/client"
 	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/tca"
 	"tbsrc-golang/pkg/types"
 )
 
@@ -29,6 +31,13 @@ type OrderManager struct {
 	// C++: fillOnCxlReject — 撤单拒绝时量为 0 表示已成交
 	// 参考: ExecutionStrategy.cpp:1874-1880
 	FillOnCxlReject bool // m_configParams->m_fillOnCxlReject
+
+	// 成交质量分析（Go 扩展，见 LegManager.EnableTCA），nil 表示不记录
+	TCA         *tca.Engine
+	TCAStrategy string
+
+	// 冰山储备（Go 扩展，见 iceberg.go），按方向+价格索引
+	reserves map[reserveKey]*reserve
 }
 
 // NewOrderManager 创建 OrderManager
@@ -55,7 +64,10 @@ func (om *OrderManager) SendNewOrder(side types.TransactionType, price float64,
 	level int32, inst *instrument.Instrument, typeOfOrder types.TypeOfOrder,
 	ordType types.OrderHitType, cb client.StrategyCallback) (uint32, bool) {
 
-	// C++: duplicate price check
+	// C++: duplicate price check（Go 扩展: 等待补单的冰山价位同样视为已占用）
+	if om.reservePending(side, price) {
+		return 0, false
+	}
 	if side == types.Buy {
 		if _, exists := om.BidMap[price]; exists {
 			return 0, false
@@ -63,8 +75,6 @@ func (om *OrderManager) SendNewOrder(side types.TransactionType, price float64,
 		// C++: 取消同价反向挂单，防止自交叉
 		// 参考: ExecutionStrategy.cpp:1347
 		om.SendCancelOrderByPrice(inst, price, types.Sell)
-		om.State.BuyOpenOrders++
-		om.State.BuyOpenQty += float64(qty)
 	} else {
 		if _, exists := om.AskMap[price]; exists {
 			return 0, false
@@ -72,22 +82,21 @@ func (om *OrderManager) SendNewOrder(side types.TransactionType, price float64,
 		// C++: 取消同价反向挂单，防止自交叉
 		// 参考: ExecutionStrategy.cpp:1477
 		om.SendCancelOrderByPrice(inst, price, types.Buy)
-		om.State.SellOpenOrders++
-		om.State.SellOpenQty += float64(qty)
 	}
 
-	// 通过 client 发送
+	// 通过 client 发送（Go 扩展: 写前日志失败时 client 拒绝发送，返回 0）
 	var orderID uint32
 	if om.Client != nil {
-		orderID = om.Client.SendNewOrder(inst, side, price, qty, ordType, cb)
+		if orderID = om.Client.SendNewOrder(inst, side, price, qty, ordType, cb); orderID == 0 {
+			return 0, false
+		}
 	} else {
 		// testing path: generate a local orderID
 		om.nextTestOID++
 		orderID = om.nextTestOID
 	}
 
-	// 创建 OrderStats
-	ordStats := types.NewOrderStats(orderID, side, price, qty, typeOfOrder, ordType)
+	ordStats := om.addOrder(orderID, side, price, qty, typeOfOrder, ordType)
 
 	// C++: 估计 quantAhead
 	if side == types.Buy {
@@ -99,6 +108,25 @@ func (om *OrderManager) SendNewOrder(side types.TransactionType, price float64,
 			ordStats.QuantAhead = inst.AskQty[level]
 		}
 	}
+	om.tcaOrder(ordStats, inst)
+
+	return orderID, true
+}
+
+// addOrder 更新挂单计数，创建 OrderStats 并插入 maps（下单与崩溃恢复共用）
+func (om *OrderManager) addOrder(orderID uint32, side types.TransactionType, price float64, qty int32,
+	typeOfOrder types.TypeOfOrder, ordType types.OrderHitType) *types.OrderStats {
+
+	if side == types.Buy {
+		om.State.BuyOpenOrders++
+		om.State.BuyOpenQty += float64(qty)
+	} else {
+		om.State.SellOpenOrders++
+		om.State.SellOpenQty += float64(qty)
+	}
+
+	// 创建 OrderStats
+	ordStats := types.NewOrderStats(orderID, side, price, qty, typeOfOrder, ordType)
 
 	// 插入 maps
 	om.OrdMap[orderID] = ordStats
@@ -110,7 +138,7 @@ func (om *OrderManager) SendNewOrder(side types.TransactionType, price float64,
 
 	om.State.OrderCount++
 
-	return orderID, true
+	return ordStats
 }
 
 // SendModifyOrder 发送改单请求
@@ -148,6 +176,18 @@ func (om *OrderManager) SendModifyOrder(inst *instrument.Instrument, orderID uin
 		}
 	}
 
+	// 发送改单（Go 扩展: 写前日志失败时不发送，也不改本地状态）
+	if om.Client != nil && !om.Client.SendModifyOrder(inst, orderID, ord.Side, price, ord.DoneQty, qty, ordType, nil) {
+		return false
+	}
+	om.markModify(ord, price, qty, ordType)
+
+	return true
+}
+
+// markModify 改单的本地状态变更（下单与崩溃恢复共用）
+func (om *OrderManager) markModify(ord *types.OrderStats, price float64, qty int32, ordType types.OrderHitType) {
+	om.dropChildReserve(ord)
 	ord.Status = types.StatusModifyOrder
 	ord.NewPrice = price
 	ord.NewQty = qty
@@ -162,11 +202,6 @@ func (om *OrderManager) SendModifyOrder(inst *instrument.Instrument, orderID uin
 		om.State.SellOpenQty += float64(qty - ord.Qty)
 	}
 
-	// 发送改单
-	if om.Client != nil {
-		om.Client.SendModifyOrder(inst, orderID, ord.Side, price, ord.DoneQty, qty, nil)
-	}
-
 	// C++: save old price/qty for rollback
 	if ord.Modify == 0 {
 		ord.OldPrice = ord.Price
@@ -174,8 +209,6 @@ func (om *OrderManager) SendModifyOrder(inst *instrument.Instrument, orderID uin
 	}
 	ord.Modify++
 	ord.ModifyWait = true
-
-	return true
 }
 
 // SendCancelOrderByID 按 orderID 撤单
@@ -225,6 +258,7 @@ func (om *OrderManager) sendCancelOrderInternal(inst *instrument.Instrument, ord
 	}
 
 	ord.Status = types.StatusCancelOrder
+	om.dropChildReserve(ord)
 
 	if om.Client != nil {
 		om.Client.SendCancelOrder(inst, orderID, ord.Side, ord.Price, ord.DoneQty, ord.OpenQty)
@@ -244,7 +278,8 @@ func (om *OrderManager) SendCancelOrderByPrice(inst *instrument.Instrument, pric
 		ord = om.AskMap[price]
 	}
 	if ord == nil {
-		return false
+		// Go 扩展: 等待补单的冰山价位，撤单即丢弃储备
+		return om.reservePending(side, price) && om.dropReserve(side, price)
 	}
 	return om.sendCancelOrderInternal(inst, ord.OrderID, ord)
 }
@@ -271,6 +306,10 @@ func (om *OrderManager) RemoveOrder(orderID uint32) {
 		om.Client.RemoveOrderID(orderID)
 	}
 	delete(om.OrdMap, orderID)
+	om.onChildRemoved(ord)
+	if om.TCA != nil {
+		om.TCA.OnOrderDone(strconv.FormatUint(uint64(orderID), 10))
+	}
 
 	log.Printf("[OrderManager] removed order %d side=%d price=%.2f",
 		orderID, ord.Side, ord.Price)
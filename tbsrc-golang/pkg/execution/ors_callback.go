@@ -163,6 +163,7 @@ func (om *OrderManager) processCancelConfirm(resp *shm.ResponseMsg, ord *types.O
 func (om *OrderManager) processTrade(resp *shm.ResponseMsg, ord *types.OrderStats, inst *instrument.Instrument) {
 	tradeQty := resp.Quantity
 	tradePrice := resp.Price
+	om.tcaFill(resp, inst)
 
 	ord.OpenQty -= tradeQty
 	ord.DoneQty += tradeQty
@@ -265,6 +266,7 @@ func (om *OrderManager) processTrade(resp *shm.ResponseMsg, ord *types.OrderStat
 		}
 		ord.Status = types.StatusTraded
 		om.RemoveOrder(resp.OrderID)
+		om.refillReserves(inst)
 	} else if ord.OpenQty < 0 {
 		log.Printf("[ORS] ERROR: negative openQty=%d for orderID=%d", ord.OpenQty, resp.OrderID)
 	}
@@ -0,0 +1,105 @@
+package execution
+
+import (
+	"log"
+
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// RestoreRequest 崩溃恢复：按写前日志中的请求重建 OrdMap/BidMap/AskMap 和挂单计数（Go 扩展）
+// 与 SendNewOrder/SendModifyOrder/sendCancelOrderInternal 的本地状态变更一致，但不发送任何请求；
+// 自交叉撤单等连带请求本身也在日志中，按顺序重放即可，这里不重复触发。
+// 回报随后经 ProcessORSResponse 重放，更新 ExecutionState（持仓/PNL/计数器）。
+// TypeOfOrder 不在日志中：LegManager 下单一律为 QUOTE
+// 返回 false 表示请求无法对应到本地订单（如改单/撤单的原订单已不在 OrdMap 中）
+func (om *OrderManager) RestoreRequest(req *shm.RequestMsg, ordType types.OrderHitType) bool {
+	side := types.Sell
+	if req.TransactionType == shm.SideBuy {
+		side = types.Buy
+	}
+
+	switch req.Request_Type {
+	case shm.NEWORDER:
+		om.addOrder(req.OrderID, side, req.Price, req.Quantity, types.Quote, ordType)
+		return true
+
+	case shm.MODIFYORDER:
+		ord, ok := om.OrdMap[req.OrderID]
+		if !ok {
+			return false
+		}
+		om.markModify(ord, req.Price, req.Quantity, ordType)
+		return true
+
+	case shm.CANCELORDER:
+		ord, ok := om.OrdMap[req.OrderID]
+		if !ok {
+			return false
+		}
+		ord.Status = types.StatusCancelOrder
+		om.State.CancelCount++
+		return true
+	}
+
+	log.Printf("[OrderManager] restore: unknown request type %d orderID=%d", req.Request_Type, req.OrderID)
+	return false
+}
+
+// RestoreFill 重放日志中找不到对应请求的成交回报（Go 扩展）
+// 按回报中的方向/价格/数量计入持仓和成交统计，不登记挂单；ordType 决定计入 netpos_pass 还是 netpos_agg
+func (om *OrderManager) RestoreFill(resp *shm.ResponseMsg, inst *instrument.Instrument, ordType types.OrderHitType) {
+	side := types.Sell
+	if resp.Side == shm.SideBuy {
+		side = types.Buy
+	}
+	ord := types.NewOrderStats(resp.OrderID, side, resp.Price, resp.Quantity, types.Quote, ordType)
+	ord.Status = types.StatusNewConfirm
+	// processTrade 按成交量扣减挂单量，这里先补上
+	if side == types.Buy {
+		om.State.BuyOpenQty += float64(resp.Quantity)
+	} else {
+		om.State.SellOpenQty += float64(resp.Quantity)
+	}
+	om.processTrade(resp, ord, inst)
+}
+
+// AdoptLiveOrder 认领柜台上仍在途、但本地没有记录的订单（重启后 ORDERSTATUS 查询结果，Go 扩展）
+// 按已确认订单登记（Status=NEW_CONFIRM），数量为柜台剩余未成交量；
+// 同价位已有本方向订单或 orderID 已存在时返回 nil（价位 map 一价一单）
+func (om *OrderManager) AdoptLiveOrder(orderID uint32, side types.TransactionType, price float64,
+	openQty int32, ordType types.OrderHitType) *types.OrderStats {
+
+	if _, ok := om.OrdMap[orderID]; ok {
+		return nil
+	}
+	if side == types.Buy && om.BidMap[price] != nil || side == types.Sell && om.AskMap[price] != nil {
+		return nil
+	}
+	ord := om.addOrder(orderID, side, price, openQty, types.Quote, ordType)
+	ord.Status = types.StatusNewConfirm
+	return ord
+}
+
+// DropStaleOrder 移除本地在途但柜台已不存在的订单（终态回报在停机期间丢失，Go 扩展）
+// 与 processCancelConfirm 一致地扣减挂单量后 RemoveOrder
+func (om *OrderManager) DropStaleOrder(orderID uint32) bool {
+	ord, ok := om.OrdMap[orderID]
+	if !ok {
+		return false
+	}
+	if ord.Side == types.Buy {
+		om.State.BuyOpenQty -= float64(ord.OpenQty)
+		if ord.Status == types.StatusModifyOrder {
+			delete(om.BidMap, ord.NewPrice)
+		}
+	} else {
+		om.State.SellOpenQty -= float64(ord.OpenQty)
+		if ord.Status == types.StatusModifyOrder {
+			delete(om.AskMap, ord.NewPrice)
+		}
+	}
+	om.RemoveOrder(orderID)
+	return true
+}
@@ -0,0 +1,482 @@
+package execution
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// 价差执行状态（Go 扩展）
+const (
+	SpreadExecIdle      = 0 // 各腿已按比例配齐
+	SpreadExecHedging   = 1 // 报价腿成交后，对冲腿主动追单中
+	SpreadExecUnwinding = 2 // 瘸腿超时/追单失败，回退多余腿到已配齐的单位数
+)
+
+// SpreadExecLeg N 腿价差中的一条执行腿
+// 每腿复用 LegManager（ExecutionState + OrderManager），额外记录对冲追单状态
+type SpreadExecLeg struct {
+	Leg     *LegManager
+	Ratio   int32 // 每单位价差的下单手数（带符号），如蝶式 +1 / -2 / +1
+	Quoting bool  // 报价腿（策略被动挂单）；否则为对冲腿（按比例主动追单）
+
+	// 追单状态 — 对应 C++ PairwiseArb 的 agg_repeat / last_agg_time / last_agg_side
+	HedgeLadder
+	BuyAggOrder  int32  // C++: buyAggOrder
+	SellAggOrder int32  // C++: sellAggOrder
+	ImbalanceTS  uint64 // 本腿出现未对冲敞口的时间（纳秒），0 表示已配齐
+}
+
+// SpreadExecutor N 腿价差执行管理（Go 扩展，泛化 PairwiseArb 的 leg1 报价 / leg2 对冲）
+//
+//   - 报价腿由策略按信号被动挂单（直接调用 Leg.SendBidOrder2 等）
+//   - 对冲腿按 Ratio 跟随报价腿成交主动追单，阶梯与 SendAggressiveOrder 一致，
+//     价格不超过首单 BBO ± HEDGE_MAX_SLIP tick
+//   - 某条对冲腿超过 LEG_TIMEOUT 仍未配齐或追单次数用尽时进入回退：
+//     撤报价腿挂单，所有腿主动成交回到已配齐的单位数
+//   - 回退仍失败时对所有腿执行 HandleSquareoff
+//
+// 与 LegManager 一样不加锁，由父策略持锁调用（包括 ORSCallBack/MDCallBack）
+type SpreadExecutor struct {
+	Legs   []*SpreadExecLeg
+	Status int // SpreadExecIdle / SpreadExecHedging / SpreadExecUnwinding
+	Active bool
+	OnExit bool // 回退失败，已转为全腿平仓
+
+	// Now 返回当前纳秒时间，默认 time.Now；回测/测试可替换
+	Now func() uint64
+
+	combined ExecutionState
+}
+
+// NewSpreadExecutor 创建 N 腿执行管理，至少一条报价腿
+func NewSpreadExecutor(legs []*SpreadExecLeg) (*SpreadExecutor, error) {
+	if len(legs) < 2 {
+		return nil, fmt.Errorf("spread executor needs at least 2 legs, got %d", len(legs))
+	}
+	quoting := 0
+	for i, leg := range legs {
+		if leg.Leg == nil {
+			return nil, fmt.Errorf("spread leg %d has no LegManager", i)
+		}
+		if leg.Ratio == 0 {
+			return nil, fmt.Errorf("spread leg %s has zero ratio", leg.Leg.Inst.Symbol)
+		}
+		if leg.Quoting {
+			quoting++
+		}
+		if leg.AggRepeat == 0 {
+			leg.AggRepeat = 1 // C++: agg_repeat 初值 1
+		}
+	}
+	if quoting == 0 {
+		return nil, fmt.Errorf("spread executor needs at least 1 quoting leg")
+	}
+	return &SpreadExecutor{
+		Legs:   legs,
+		Active: true,
+		Now:    func() uint64 { return uint64(time.Now().UnixNano()) },
+	}, nil
+}
+
+// LegIndex 按合约查找腿下标，不存在返回 -1
+func (se *SpreadExecutor) LegIndex(inst *instrument.Instrument) int {
+	for i, leg := range se.Legs {
+		if leg.Leg.Inst == inst {
+			return i
+		}
+	}
+	return -1
+}
+
+// orderLeg 按 orderID 查找所属腿下标，不存在返回 -1
+func (se *SpreadExecutor) orderLeg(orderID uint32) int {
+	for i, leg := range se.Legs {
+		if _, ok := leg.Leg.Orders.OrdMap[orderID]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// QuotedUnits 报价腿成交折算的价差单位数
+// 单报价腿即 netpos / Ratio；多报价腿取最小二乘 Σ netpos_i*Ratio_i / Σ Ratio_i²
+func (se *SpreadExecutor) QuotedUnits() float64 {
+	var num, den float64
+	for _, leg := range se.Legs {
+		if !leg.Quoting {
+			continue
+		}
+		r := float64(leg.Ratio)
+		num += float64(leg.Leg.State.Netpos) * r
+		den += r * r
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+// Units 各腿已配齐的价差单位数（带符号）：各腿 netpos_i / Ratio_i 同号时取绝对值最小者，否则为 0
+func (se *SpreadExecutor) Units() int32 {
+	var units int32
+	for i, leg := range se.Legs {
+		u := leg.Leg.State.Netpos / leg.Ratio
+		if u == 0 {
+			return 0
+		}
+		if i == 0 {
+			units = u
+			continue
+		}
+		if (u > 0) != (units > 0) {
+			return 0
+		}
+		if abs32(u) < abs32(units) {
+			units = u
+		}
+	}
+	return units
+}
+
+// Target 第 i 腿目标持仓
+// 正常: 报价腿为当前持仓，对冲腿为 round(QuotedUnits * Ratio)
+// 回退: 所有腿为 Units * Ratio
+func (se *SpreadExecutor) Target(i int) int32 {
+	leg := se.Legs[i]
+	if se.Status == SpreadExecUnwinding {
+		return se.Units() * leg.Ratio
+	}
+	if leg.Quoting {
+		return leg.Leg.State.Netpos
+	}
+	return int32(math.Round(se.QuotedUnits() * float64(leg.Ratio)))
+}
+
+// Exposure 第 i 腿还需主动成交的手数（目标 - 持仓 - 在途主动单），>0 买入，<0 卖出
+func (se *SpreadExecutor) Exposure(i int) int32 {
+	leg := se.Legs[i]
+	return se.Target(i) - leg.Leg.State.Netpos - pendingAgg(leg.Leg)
+}
+
+// Imbalance 第 i 腿相对目标的瘸腿手数（不计在途单）
+func (se *SpreadExecutor) Imbalance(i int) int32 {
+	return se.Legs[i].Leg.State.Netpos - se.Target(i)
+}
+
+// CanQuote 报价腿是否允许挂新单（回退/平仓中禁止）
+func (se *SpreadExecutor) CanQuote() bool {
+	return se.Active && !se.OnExit && se.Status != SpreadExecUnwinding
+}
+
+// MDCallBack 行情回调：更新对应腿 LTP/PNL，检查瘸腿超时
+func (se *SpreadExecutor) MDCallBack(inst *instrument.Instrument, md *shm.MarketUpdateNew) {
+	i := se.LegIndex(inst)
+	if i < 0 {
+		return
+	}
+	se.Legs[i].Leg.MDCallBack(inst, md)
+
+	if !se.Active {
+		return
+	}
+	if se.checkLegging() {
+		se.Hedge() // 刚进入回退，立即下回退单
+	}
+	if se.OnExit {
+		se.squareoffLegs()
+	}
+}
+
+// ORSCallBack 订单回报：更新追单计数器，委托给对应腿，然后补对冲
+// 参考: PairwiseArbStrategy.cpp:428-477
+func (se *SpreadExecutor) ORSCallBack(resp *shm.ResponseMsg) {
+	i := se.orderLeg(resp.OrderID)
+	if i < 0 {
+		log.Printf("[SpreadExec] unknown orderID=%d responseType=%d", resp.OrderID, resp.Response_Type)
+		return
+	}
+	leg := se.Legs[i]
+
+	se.handleAggOrder(leg, resp)
+	leg.Leg.ProcessORSDirectly(resp)
+
+	if resp.Response_Type == shm.TRADE_CONFIRM {
+		// C++: 成交时重置 agg_repeat；报价腿成交开启新一轮对冲
+		leg.Reset()
+		if leg.Quoting && se.Status != SpreadExecUnwinding {
+			for _, l := range se.Legs {
+				if !l.Quoting {
+					l.Reset()
+				}
+			}
+		}
+	}
+	if resp.Response_Type == shm.RMS_REJECT {
+		leg.Leg.State.HandleRMSReject(leg.Leg.Inst)
+	}
+
+	if se.OnExit {
+		se.squareoffLegs()
+		return
+	}
+	if se.Active {
+		se.Hedge()
+	}
+}
+
+// Hedge 对所有未配齐的腿主动追单，并更新执行状态
+// 正常状态只追对冲腿；回退状态所有腿都追向 Units * Ratio
+func (se *SpreadExecutor) Hedge() {
+	if !se.Active || se.OnExit {
+		return
+	}
+	se.checkLegging()
+	if se.OnExit {
+		se.squareoffLegs()
+		return
+	}
+
+	now := se.Now()
+	status := se.Status
+	for i, leg := range se.Legs {
+		if leg.Quoting && status != SpreadExecUnwinding {
+			continue
+		}
+		if need := se.Exposure(i); need != 0 {
+			se.sendAggressive(leg, need, now)
+		}
+		if se.OnExit {
+			se.squareoffLegs()
+			return
+		}
+		if se.Status != status {
+			return // 刚转入回退，等撤单回报后再下回退单
+		}
+	}
+}
+
+// checkLegging 更新各腿瘸腿计时和执行状态
+// 对冲超过 LEG_TIMEOUT 开始回退，回退也超时则转全腿平仓；返回本次是否开始回退
+func (se *SpreadExecutor) checkLegging() bool {
+	now := se.Now()
+	imbalanced := false
+	for i, leg := range se.Legs {
+		if leg.Quoting && se.Status != SpreadExecUnwinding {
+			continue
+		}
+		if se.Imbalance(i) == 0 {
+			leg.ImbalanceTS = 0
+			leg.Settle()
+			continue
+		}
+		imbalanced = true
+		if leg.ImbalanceTS == 0 {
+			leg.ImbalanceTS = now
+		}
+		if !LegTimedOut(leg.ImbalanceTS, now, leg.Leg.Thold.LegTimeout) {
+			continue
+		}
+		log.Printf("[SpreadExec] leg %s unbalanced for %dms (imbalance=%d, status=%d)",
+			leg.Leg.Inst.Symbol, (now-leg.ImbalanceTS)/uint64(time.Millisecond), se.Imbalance(i), se.Status)
+		se.giveUp(leg)
+		return se.Status == SpreadExecUnwinding && !se.OnExit
+	}
+
+	switch {
+	case !imbalanced && se.Status != SpreadExecIdle:
+		log.Printf("[SpreadExec] legs balanced: units=%d", se.Units())
+		se.Status = SpreadExecIdle
+	case imbalanced && se.Status == SpreadExecIdle:
+		se.Status = SpreadExecHedging
+	}
+	return false
+}
+
+// startUnwind 进入回退：撤掉报价腿挂单和在途对冲单，所有腿追向已配齐的单位数
+func (se *SpreadExecutor) startUnwind() {
+	if se.Status == SpreadExecUnwinding {
+		return
+	}
+	se.Status = SpreadExecUnwinding
+	now := se.Now()
+	for _, leg := range se.Legs {
+		for _, ord := range leg.Leg.Orders.OrdMap {
+			leg.Leg.Orders.SendCancelOrderByIDForce(leg.Leg.Inst, ord.OrderID)
+		}
+		leg.Reset()
+		leg.Settle()
+		leg.ImbalanceTS = now
+	}
+	log.Printf("[SpreadExec] unwinding to units=%d", se.Units())
+}
+
+// sendAggressive 第 i 腿主动追单 need 手（>0 买入，<0 卖出）
+// 阶梯与 PairwiseArb.SendAggressiveOrder 一致，价格受 HEDGE_MAX_SLIP 限制；
+// 次数用尽时对冲腿转回退，回退中则转全腿平仓
+// 参考: PairwiseArbStrategy.cpp:701-800
+func (se *SpreadExecutor) sendAggressive(leg *SpreadExecLeg, need int32, now uint64) {
+	inst := leg.Leg.Inst
+	thold := leg.Leg.Thold
+
+	side := types.Buy
+	bbo := inst.AskPx[0]
+	outstanding := leg.BuyAggOrder
+	qty := need
+	if need < 0 {
+		side = types.Sell
+		bbo = inst.BidPx[0]
+		outstanding = leg.SellAggOrder
+		qty = -need
+	}
+	if bbo <= 0 || outstanding > thold.SupportingOrders {
+		return
+	}
+
+	price, first, giveUp := leg.Next(side, bbo, inst.TickSize, thold.Slop, thold.HedgeMaxSlip, now)
+	if giveUp {
+		se.giveUp(leg)
+		return
+	}
+
+	var ok bool
+	if side == types.Buy {
+		ok = leg.Leg.SendBidOrder2(shm.NEWORDER, 0, price, types.HitCross, qty, 0, 0)
+	} else {
+		ok = leg.Leg.SendAskOrder2(shm.NEWORDER, 0, price, types.HitCross, qty, 0, 0)
+	}
+	if !ok {
+		return
+	}
+	leg.Sent(side, first, now)
+	if side == types.Buy {
+		leg.BuyAggOrder++
+	} else {
+		leg.SellAggOrder++
+	}
+}
+
+// giveUp 追单失败（次数用尽或超时）：对冲中转回退，回退中转全腿平仓
+func (se *SpreadExecutor) giveUp(leg *SpreadExecLeg) {
+	if se.Status != SpreadExecUnwinding {
+		log.Printf("[SpreadExec] leg %s hedge failed (repeat=%d), unwind", leg.Leg.Inst.Symbol, leg.AggRepeat)
+		se.startUnwind()
+		return
+	}
+	log.Printf("[SpreadExec] leg %s unwind failed (repeat=%d), squareoff", leg.Leg.Inst.Symbol, leg.AggRepeat)
+	se.OnExit = true
+	for _, l := range se.Legs {
+		l.Leg.State.OnExit = true
+		l.Leg.State.OnCancel = true
+		l.Leg.State.OnFlat = true
+	}
+}
+
+// HandleSquareoff 所有腿平仓退出
+func (se *SpreadExecutor) HandleSquareoff() {
+	se.OnExit = true
+	for _, leg := range se.Legs {
+		leg.Leg.State.OnExit = true
+		leg.Leg.State.OnCancel = true
+		leg.Leg.State.OnFlat = true
+	}
+	se.squareoffLegs()
+}
+
+func (se *SpreadExecutor) squareoffLegs() {
+	for _, leg := range se.Legs {
+		leg.Leg.HandleSquareoff()
+	}
+	se.Active = false
+}
+
+// handleAggOrder 对冲单终态时减少追单计数器
+// 参考: PairwiseArbStrategy.cpp:402-426
+func (se *SpreadExecutor) handleAggOrder(leg *SpreadExecLeg, resp *shm.ResponseMsg) {
+	ord, ok := leg.Leg.Orders.OrdMap[resp.OrderID]
+	if !ok || (ord.OrdType != types.HitCross && ord.OrdType != types.HitMatch) {
+		return
+	}
+
+	isTerminal := false
+	switch resp.Response_Type {
+	case shm.TRADE_CONFIRM:
+		isTerminal = ord.OpenQty-resp.Quantity <= 0
+	case shm.CANCEL_ORDER_CONFIRM, shm.ORS_REJECT, shm.RMS_REJECT, shm.NEW_ORDER_FREEZE:
+		isTerminal = true
+	}
+	if !isTerminal {
+		return
+	}
+	if ord.Side == types.Buy {
+		if leg.BuyAggOrder > 0 {
+			leg.BuyAggOrder--
+		}
+	} else if leg.SellAggOrder > 0 {
+		leg.SellAggOrder--
+	}
+}
+
+// CombinedState 汇总各腿为一个价差级 ExecutionState
+// Netpos 为已配齐的价差单位数；PNL、手续费、挂单和计数器为各腿之和；
+// MaxPNL/Drawdown 按合并后的 NetPNL 跟踪高水位
+func (se *SpreadExecutor) CombinedState() *ExecutionState {
+	c := &se.combined
+	maxPNL := c.MaxPNL
+	*c = ExecutionState{}
+	c.Netpos = se.Units()
+	c.Active = se.Active
+	c.OnExit = se.OnExit
+	for _, leg := range se.Legs {
+		s := leg.Leg.State
+		c.RealisedPNL += s.RealisedPNL
+		c.UnrealisedPNL += s.UnrealisedPNL
+		c.GrossPNL += s.GrossPNL
+		c.NetPNL += s.NetPNL
+		c.TransTotalValue += s.TransTotalValue
+		c.BuyOpenOrders += s.BuyOpenOrders
+		c.SellOpenOrders += s.SellOpenOrders
+		c.BuyOpenQty += s.BuyOpenQty
+		c.SellOpenQty += s.SellOpenQty
+		c.TradeCount += s.TradeCount
+		c.OrderCount += s.OrderCount
+		c.CancelCount += s.CancelCount
+		c.RejectCount += s.RejectCount
+		c.CrossCount += s.CrossCount
+		c.ConfirmCount += s.ConfirmCount
+		if s.ExchTS > c.ExchTS {
+			c.ExchTS = s.ExchTS
+		}
+	}
+	c.MaxPNL = math.Max(maxPNL, c.NetPNL)
+	c.Drawdown = c.NetPNL - c.MaxPNL
+	return c
+}
+
+// pendingAgg 腿上在途主动单（CROSS/MATCH）的净数量
+// 参考: PairwiseArbStrategy.cpp:688-699 CalcPendingNetposAgg
+func pendingAgg(lm *LegManager) int32 {
+	var pending int32
+	for _, ord := range lm.Orders.OrdMap {
+		if ord.OrdType == types.HitCross || ord.OrdType == types.HitMatch {
+			if ord.Side == types.Buy {
+				pending += ord.OpenQty
+			} else {
+				pending -= ord.OpenQty
+			}
+		}
+	}
+	return pending
+}
+
+func abs32(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,220 @@
+package execution
+
+import (
+	"testing"
+	"time"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// newTestSpreadLeg 创建测试执行腿，oidBase 区分各腿本地 orderID
+func newTestSpreadLeg(symbol string, bid, ask float64, ratio int32, quoting bool, oidBase uint32) *SpreadExecLeg {
+	lm, inst := newTestLegManager()
+	inst.Symbol = symbol
+	inst.BidPx[0] = bid
+	inst.AskPx[0] = ask
+	lm.Orders.nextTestOID = oidBase
+	return &SpreadExecLeg{Leg: lm, Ratio: ratio, Quoting: quoting}
+}
+
+// fillLeg 在腿上插入一笔已确认订单并全部成交
+func fillLeg(se *SpreadExecutor, leg *SpreadExecLeg, orderID uint32, side types.TransactionType,
+	price float64, qty int32, ordType types.OrderHitType) {
+	insertOrder(leg.Leg.Orders, orderID, side, price, qty, ordType)
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM, OrderID: orderID})
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: orderID, Price: price, Quantity: qty})
+}
+
+// aggOrders 返回腿上的 CROSS 订单
+func aggOrders(leg *SpreadExecLeg) []*types.OrderStats {
+	var out []*types.OrderStats
+	for _, ord := range leg.Leg.Orders.OrdMap {
+		if ord.OrdType == types.HitCross {
+			out = append(out, ord)
+		}
+	}
+	return out
+}
+
+func newTestClock() (*uint64, func() uint64) {
+	now := uint64(1_000_000_000)
+	return &now, func() uint64 { return now }
+}
+
+func TestSpreadExecutor_Validation(t *testing.T) {
+	a := newTestSpreadLeg("a", 100, 101, 1, true, 0)
+	b := newTestSpreadLeg("b", 100, 101, -1, false, 100)
+	if _, err := NewSpreadExecutor([]*SpreadExecLeg{a}); err == nil {
+		t.Error("expected error for single leg")
+	}
+	if _, err := NewSpreadExecutor([]*SpreadExecLeg{a, {Leg: b.Leg, Ratio: 0}}); err == nil {
+		t.Error("expected error for zero ratio")
+	}
+	if _, err := NewSpreadExecutor([]*SpreadExecLeg{{Leg: a.Leg, Ratio: 1}, b}); err == nil {
+		t.Error("expected error without quoting leg")
+	}
+}
+
+func TestSpreadExecutor_PairHedge(t *testing.T) {
+	q := newTestSpreadLeg("ag2506", 5810, 5811, 1, true, 0)
+	h := newTestSpreadLeg("ag2512", 5800, 5801, -1, false, 100)
+	se, err := NewSpreadExecutor([]*SpreadExecLeg{q, h})
+	if err != nil {
+		t.Fatalf("NewSpreadExecutor: %v", err)
+	}
+	_, se.Now = newTestClock()
+
+	// 报价腿买入 2 手 → 对冲腿以 bid 卖出 2 手
+	fillLeg(se, q, 1001, types.Buy, 5810, 2, types.HitStandard)
+	if se.Status != SpreadExecHedging {
+		t.Errorf("Status = %d, want Hedging", se.Status)
+	}
+	orders := aggOrders(h)
+	if len(orders) != 1 || orders[0].Side != types.Sell || orders[0].Qty != 2 || orders[0].Price != 5800 {
+		t.Fatalf("hedge orders = %+v, want 1 sell 2@5800", orders)
+	}
+	if se.Exposure(1) != 0 {
+		t.Errorf("Exposure = %d, want 0 (pending counted)", se.Exposure(1))
+	}
+
+	// 对冲成交 → 配齐
+	oid := orders[0].OrderID
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM, OrderID: oid})
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: oid, Price: 5800, Quantity: 2})
+	if se.Status != SpreadExecIdle {
+		t.Errorf("Status = %d, want Idle", se.Status)
+	}
+	if se.Units() != 2 {
+		t.Errorf("Units = %d, want 2", se.Units())
+	}
+	if h.SellAggOrder != 0 {
+		t.Errorf("SellAggOrder = %d, want 0 after full fill", h.SellAggOrder)
+	}
+}
+
+func TestSpreadExecutor_ButterflyRatio(t *testing.T) {
+	w1 := newTestSpreadLeg("ag2506", 5800, 5801, 1, false, 100)
+	body := newTestSpreadLeg("ag2508", 5810, 5811, -2, true, 0)
+	w2 := newTestSpreadLeg("ag2510", 5830, 5831, 1, false, 200)
+	se, err := NewSpreadExecutor([]*SpreadExecLeg{w1, body, w2})
+	if err != nil {
+		t.Fatalf("NewSpreadExecutor: %v", err)
+	}
+	_, se.Now = newTestClock()
+
+	// 蝶身卖出 4 手 = 2 个单位 → 两翼各买 2 手
+	fillLeg(se, body, 1001, types.Sell, 5811, 4, types.HitStandard)
+	for _, w := range []*SpreadExecLeg{w1, w2} {
+		orders := aggOrders(w)
+		if len(orders) != 1 || orders[0].Side != types.Buy || orders[0].Qty != 2 {
+			t.Errorf("%s hedge orders = %+v, want buy 2", w.Leg.Inst.Symbol, orders)
+		}
+		if orders[0].Price != w.Leg.Inst.AskPx[0] {
+			t.Errorf("%s price = %f, want ask %f", w.Leg.Inst.Symbol, orders[0].Price, w.Leg.Inst.AskPx[0])
+		}
+	}
+}
+
+func TestSpreadExecutor_MaxSlip(t *testing.T) {
+	q := newTestSpreadLeg("ag2506", 5810, 5811, 1, true, 0)
+	h := newTestSpreadLeg("ag2512", 5800, 5801, -1, false, 100)
+	h.Leg.Thold.SupportingOrders = 10
+	h.Leg.Thold.HedgeMaxSlip = 2
+	se, _ := NewSpreadExecutor([]*SpreadExecLeg{q, h})
+	now, clock := newTestClock()
+	se.Now = clock
+
+	fillLeg(se, q, 1001, types.Buy, 5810, 1, types.HitStandard) // 首单 5800
+
+	// 首单被拒 → 重试阶梯 5800-1、5800-2、5800-SLOP，后两次被 HEDGE_MAX_SLIP 限制在 5798
+	want := []float64{5799, 5798, 5798}
+	for i, px := range want {
+		orders := aggOrders(h)
+		if len(orders) != 1 {
+			t.Fatalf("step %d: %d hedge orders, want 1", i, len(orders))
+		}
+		*now += uint64(100 * time.Millisecond)
+		se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.ORS_REJECT, OrderID: orders[0].OrderID})
+		orders = aggOrders(h)
+		if len(orders) != 1 || orders[0].Price != px {
+			t.Fatalf("step %d: orders = %+v, want price %f", i, orders, px)
+		}
+	}
+
+	// 次数用尽 → 回退，报价腿禁止挂新单
+	orders := aggOrders(h)
+	*now += uint64(100 * time.Millisecond)
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.ORS_REJECT, OrderID: orders[0].OrderID})
+	if se.Status != SpreadExecUnwinding {
+		t.Fatalf("Status = %d, want Unwinding", se.Status)
+	}
+	if se.CanQuote() {
+		t.Error("CanQuote should be false while unwinding")
+	}
+}
+
+func TestSpreadExecutor_LegTimeoutUnwind(t *testing.T) {
+	q := newTestSpreadLeg("ag2506", 5810, 5811, 1, true, 0)
+	h := newTestSpreadLeg("ag2512", 5800, 5801, -1, false, 100)
+	h.Leg.Thold.LegTimeout = 200
+	se, _ := NewSpreadExecutor([]*SpreadExecLeg{q, h})
+	now, clock := newTestClock()
+	se.Now = clock
+
+	fillLeg(se, q, 1001, types.Buy, 5810, 2, types.HitStandard)
+	hedge := aggOrders(h)[0]
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM, OrderID: hedge.OrderID})
+	// 部分成交 1 手
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: hedge.OrderID, Price: 5800, Quantity: 1})
+
+	// 未超时：行情回调不改变状态
+	*now += uint64(100 * time.Millisecond)
+	se.MDCallBack(h.Leg.Inst, &shm.MarketUpdateNew{})
+	if se.Status != SpreadExecHedging {
+		t.Fatalf("Status = %d, want Hedging", se.Status)
+	}
+
+	// 超时 → 回退：撤掉在途对冲单
+	*now += uint64(200 * time.Millisecond)
+	se.MDCallBack(h.Leg.Inst, &shm.MarketUpdateNew{})
+	if se.Status != SpreadExecUnwinding {
+		t.Fatalf("Status = %d, want Unwinding", se.Status)
+	}
+	if hedge.Status != types.StatusCancelOrder {
+		t.Errorf("hedge order status = %d, want cancel sent", hedge.Status)
+	}
+
+	// 撤单确认 → 报价腿卖出多余的 1 手回到 1 个单位
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.CANCEL_ORDER_CONFIRM, OrderID: hedge.OrderID})
+	unwind := aggOrders(q)
+	if len(unwind) != 1 || unwind[0].Side != types.Sell || unwind[0].Qty != 1 || unwind[0].Price != 5810 {
+		t.Fatalf("unwind orders = %+v, want sell 1@5810", unwind)
+	}
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.NEW_ORDER_CONFIRM, OrderID: unwind[0].OrderID})
+	se.ORSCallBack(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: unwind[0].OrderID, Price: 5810, Quantity: 1})
+	if se.Status != SpreadExecIdle || se.Units() != 1 {
+		t.Errorf("Status = %d, Units = %d, want Idle, 1", se.Status, se.Units())
+	}
+}
+
+func TestSpreadExecutor_CombinedState(t *testing.T) {
+	q := newTestSpreadLeg("ag2506", 5810, 5811, 1, true, 0)
+	h := newTestSpreadLeg("ag2512", 5800, 5801, -1, false, 100)
+	se, _ := NewSpreadExecutor([]*SpreadExecLeg{q, h})
+
+	q.Leg.State.Netpos, h.Leg.State.Netpos = 3, -3
+	q.Leg.State.NetPNL, h.Leg.State.NetPNL = 100, -40
+	q.Leg.State.TradeCount, h.Leg.State.TradeCount = 2, 3
+	c := se.CombinedState()
+	if c.Netpos != 3 || c.NetPNL != 60 || c.TradeCount != 5 || c.MaxPNL != 60 {
+		t.Errorf("combined = netpos %d pnl %f trades %d max %f, want 3, 60, 5, 60",
+			c.Netpos, c.NetPNL, c.TradeCount, c.MaxPNL)
+	}
+
+	q.Leg.State.NetPNL = 50
+	c = se.CombinedState()
+	if c.MaxPNL != 60 || c.Drawdown != -50 {
+		t.Errorf("MaxPNL = %f, Drawdown = %f, want 60, -50", c.MaxPNL, c.Drawdown)
+	}
+}
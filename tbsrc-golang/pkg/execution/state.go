@@ -229,3 +229,49 @@ func (s *ExecutionState) Reset() {
 	// 止损时间戳
 	s.StopLossTS = 0
 }
+
+// RollTradingDay 交易日切换：被动仓转昨仓，日内字段归零（Go 扩展）
+// 结果等同于 C++ 次日重启后从 daily_init 恢复的状态:
+//   m_netpos_pass_ytd = m_netpos_pass，m_netpos_pass / m_netpos_agg 保留，
+//   其余成交量、价值、均价、PNL、计数器从零开始
+// 保留挂单数量、状态标志、阈值派生字段和费率
+func (s *ExecutionState) RollTradingDay() {
+	s.NetposPassYtd = s.NetposPass
+	s.Netpos = 0
+
+	s.BuyQty = 0
+	s.SellQty = 0
+	s.BuyTotalQty = 0
+	s.SellTotalQty = 0
+
+	s.BuyValue = 0
+	s.SellValue = 0
+	s.TransValue = 0
+	s.BuyTotalValue = 0
+	s.SellTotalValue = 0
+	s.TransTotalValue = 0
+
+	s.BuyPrice = 0
+	s.SellPrice = 0
+	s.BuyAvgPrice = 0
+	s.SellAvgPrice = 0
+
+	s.RealisedPNL = 0
+	s.UnrealisedPNL = 0
+	s.NetPNL = 0
+	s.GrossPNL = 0
+	s.MaxPNL = 0
+	s.Drawdown = 0
+
+	s.CancelConfirmCnt = 0
+	s.ConfirmCount = 0
+	s.ImproveCount = 0
+	s.CrossCount = 0
+	s.TradeCount = 0
+	s.RejectCount = 0
+	s.OrderCount = 0
+	s.CancelCount = 0
+
+	s.BestBidLastPNL = 0
+	s.BestAskLastPNL = 0
+}
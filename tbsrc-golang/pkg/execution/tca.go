@@ -0,0 +1,71 @@
+package execution
+
+import (
+	"strconv"
+	"time"
+
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/tca"
+	"tbsrc-golang/pkg/types"
+)
+
+// EnableTCA 为本腿开启成交质量分析（Go 扩展），须在下单前调用
+func (lm *LegManager) EnableTCA(e *tca.Engine) {
+	lm.Orders.TCA = e
+	lm.Orders.TCAStrategy = strconv.Itoa(int(lm.StrategyID))
+}
+
+// tcaTime 交易所时间戳（行情尚未到达时取本地时间）
+func (om *OrderManager) tcaTime() time.Time {
+	if om.State.ExchTS > 0 {
+		return time.Unix(0, int64(om.State.ExchTS))
+	}
+	return time.Now()
+}
+
+// tcaOrder 记录下单时的到达价和盘口
+func (om *OrderManager) tcaOrder(ord *types.OrderStats, inst *instrument.Instrument) {
+	if om.TCA == nil {
+		return
+	}
+	om.TCA.OnOrder(tca.Order{
+		ID:        strconv.FormatUint(uint64(ord.OrderID), 10),
+		Strategy:  om.TCAStrategy,
+		Symbol:    inst.Symbol,
+		OrderType: ord.OrdType.String(),
+		Buy:       ord.Side == types.Buy,
+		Qty:       ord.Qty,
+		Price:     ord.Price,
+		Time:      om.tcaTime(),
+		Bid:       inst.BidPx[0],
+		Ask:       inst.AskPx[0],
+		BidQty:    inst.BidQty[0],
+		AskQty:    inst.AskQty[0],
+	})
+}
+
+// tcaFill 记录成交
+func (om *OrderManager) tcaFill(resp *shm.ResponseMsg, inst *instrument.Instrument) {
+	if om.TCA == nil {
+		return
+	}
+	om.TCA.OnFill(strconv.FormatUint(uint64(resp.OrderID), 10), om.tcaTime(),
+		resp.Quantity, resp.Price, instMid(inst))
+}
+
+// tcaQuote 行情更新，结算到期的 markout
+func (om *OrderManager) tcaQuote(inst *instrument.Instrument) {
+	if om.TCA == nil {
+		return
+	}
+	om.TCA.OnQuote(inst.Symbol, om.tcaTime(), instMid(inst))
+}
+
+// instMid 一档 mid；单边无报价时返回 0（TCA 取最近一笔 mid）
+func instMid(inst *instrument.Instrument) float64 {
+	if inst.BidPx[0] > 0 && inst.AskPx[0] > 0 {
+		return (inst.BidPx[0] + inst.AskPx[0]) / 2
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package execution
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/tca"
+	"tbsrc-golang/pkg/types"
+)
+
+// TestLegManager_TCA 验证下单记录到达价、成交计算指标、行情结算 markout
+func TestLegManager_TCA(t *testing.T) {
+	lm, inst := newTestLegManager()
+	e := tca.NewEngine(tca.Config{Horizons: []time.Duration{time.Second}})
+	lm.EnableTCA(e)
+	t0 := uint64(time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local).UnixNano())
+	lm.State.ExchTS = t0
+
+	// 买一挂单（被动），到达 mid 5819.5
+	id, ok := lm.Orders.SendNewOrder(types.Buy, 5819, 2, 0, inst, types.Quote, types.HitStandard, nil)
+	if !ok {
+		t.Fatal("SendNewOrder failed")
+	}
+
+	resp := &shm.ResponseMsg{}
+	resp.OrderID = id
+	resp.Response_Type = shm.TRADE_CONFIRM
+	resp.Price = 5819
+	resp.Quantity = 2
+	lm.ProcessORSDirectly(resp)
+
+	// 1s 后 mid 上移到 5821.5
+	inst.BidPx[0], inst.AskPx[0] = 5821, 5822
+	lm.MDCallBack(inst, &shm.MarketUpdateNew{Header: shm.MDHeaderPart{ExchTS: t0 + uint64(time.Second)}})
+
+	r := e.Report()
+	if len(r.Fills) != 1 {
+		t.Fatalf("fills = %d, want 1", len(r.Fills))
+	}
+	f := r.Fills[0]
+	if f.Strategy != "92201" || f.OrderType != "STANDARD" || f.Liquidity != tca.Passive || f.Side != "buy" {
+		t.Errorf("fill meta = %+v", f)
+	}
+	if math.Abs(f.Shortfall+0.5) > 1e-9 || math.Abs(f.Markouts["1s"]-2.5) > 1e-9 {
+		t.Errorf("fill metrics = %+v", f)
+	}
+	// 全部成交后订单移除，到达记录随之释放：重复回报不再计入
+	e.OnFill(f.OrderID, time.Now(), 1, 5819, 0)
+	if n := len(e.Report().Fills); n != 1 {
+		t.Errorf("fills after order done = %d, want 1", n)
+	}
+}
@@ -0,0 +1,176 @@
+package journal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// Entry 记录的可读形式（日志查看/导出用）
+type Entry struct {
+	Offset     int64     `json:"offset"`
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`
+	Type       string    `json:"type,omitempty"` // NEWORDER / TRADE_CONFIRM / checkpoint reason ...
+	OrderID    uint32    `json:"order_id,omitempty"`
+	Symbol     string    `json:"symbol,omitempty"`
+	Side       string    `json:"side,omitempty"` // B / S
+	Price      float64   `json:"price,omitempty"`
+	Qty        int32     `json:"qty,omitempty"`
+	OrdType    string    `json:"ord_type,omitempty"`
+	TradeID    string    `json:"trade_id,omitempty"`
+	ErrorCode  uint32    `json:"error_code,omitempty"`
+	TradingDay string    `json:"trading_day,omitempty"`
+	StrategyID int32     `json:"strategy_id,omitempty"`
+}
+
+// EntryHeader CSV 列名，与 Entry.Fields 一致
+var EntryHeader = []string{"offset", "time", "kind", "type", "order_id", "symbol", "side",
+	"price", "qty", "ord_type", "trade_id", "error_code", "trading_day", "strategy_id"}
+
+// Fields CSV 行
+func (e Entry) Fields() []string {
+	return []string{
+		strconv.FormatInt(e.Offset, 10),
+		e.Time.Format("2006-01-02 15:04:05.000000"),
+		e.Kind,
+		e.Type,
+		strconv.FormatUint(uint64(e.OrderID), 10),
+		e.Symbol,
+		e.Side,
+		strconv.FormatFloat(e.Price, 'f', -1, 64),
+		strconv.FormatInt(int64(e.Qty), 10),
+		e.OrdType,
+		e.TradeID,
+		strconv.FormatUint(uint64(e.ErrorCode), 10),
+		e.TradingDay,
+		strconv.FormatInt(int64(e.StrategyID), 10),
+	}
+}
+
+// Decode 解析记录为可读形式
+func Decode(rec Record) (Entry, error) {
+	e := Entry{Offset: rec.Offset, Time: rec.Time, Kind: rec.Kind.String()}
+	switch rec.Kind {
+	case KindRequest:
+		req, ordType, err := DecodeRequest(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.Type = RequestTypeName(req.Request_Type)
+		e.OrderID = req.OrderID
+		e.Symbol = CString(req.ContractDesc.Symbol[:])
+		e.Side = sideName(req.TransactionType)
+		e.Price = req.Price
+		e.Qty = req.Quantity
+		e.OrdType = OrdTypeName(ordType)
+		e.StrategyID = req.StrategyID
+	case KindResponse:
+		resp, err := DecodeResponse(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.Type = ResponseTypeName(resp.Response_Type)
+		e.OrderID = resp.OrderID
+		e.Symbol = CString(resp.Symbol[:])
+		e.Side = sideName(resp.Side)
+		e.Price = resp.Price
+		e.Qty = resp.Quantity
+		e.TradeID = CString(resp.ExchangeTradeId[:])
+		e.ErrorCode = resp.ErrorCode
+		e.StrategyID = resp.StrategyID
+	case KindCheckpoint:
+		cp, err := DecodeCheckpoint(rec.Payload)
+		if err != nil {
+			return e, err
+		}
+		e.Type = cp.Reason
+		e.TradingDay = cp.TradingDay
+	default:
+		return e, fmt.Errorf("journal: unknown record kind %d at offset %d", rec.Kind, rec.Offset)
+	}
+	return e, nil
+}
+
+// RequestTypeName 请求类型名称（与 C++ enum 一致）
+func RequestTypeName(t shm.RequestType) string {
+	switch t {
+	case shm.NEWORDER:
+		return "NEWORDER"
+	case shm.MODIFYORDER:
+		return "MODIFYORDER"
+	case shm.CANCELORDER:
+		return "CANCELORDER"
+	case shm.ORDERSTATUS:
+		return "ORDERSTATUS"
+	}
+	return fmt.Sprintf("REQUEST(%d)", t)
+}
+
+var responseTypeNames = map[shm.ResponseType]string{
+	shm.NEW_ORDER_CONFIRM:            "NEW_ORDER_CONFIRM",
+	shm.NEW_ORDER_FREEZE:             "NEW_ORDER_FREEZE",
+	shm.MODIFY_ORDER_CONFIRM:         "MODIFY_ORDER_CONFIRM",
+	shm.CANCEL_ORDER_CONFIRM:         "CANCEL_ORDER_CONFIRM",
+	shm.TRADE_CONFIRM:                "TRADE_CONFIRM",
+	shm.ORDER_ERROR:                  "ORDER_ERROR",
+	shm.MODIFY_ORDER_REJECT:          "MODIFY_ORDER_REJECT",
+	shm.CANCEL_ORDER_REJECT:          "CANCEL_ORDER_REJECT",
+	shm.ORS_REJECT:                   "ORS_REJECT",
+	shm.RMS_REJECT:                   "RMS_REJECT",
+	shm.SIM_REJECT:                   "SIM_REJECT",
+	shm.BUSINESS_REJECT:              "BUSINESS_REJECT",
+	shm.MODIFY_ORDER_PENDING:         "MODIFY_ORDER_PENDING",
+	shm.CANCEL_ORDER_PENDING:         "CANCEL_ORDER_PENDING",
+	shm.ORDERS_PER_DAY_LIMIT_REJECT:  "ORDERS_PER_DAY_LIMIT_REJECT",
+	shm.ORDERS_PER_DAY_LIMIT_WARNING: "ORDERS_PER_DAY_LIMIT_WARNING",
+	shm.ORDER_EXPIRED:                "ORDER_EXPIRED",
+	shm.STOP_LOSS_WARNING:            "STOP_LOSS_WARNING",
+	shm.NULL_RESPONSE:                "NULL_RESPONSE",
+}
+
+// ResponseTypeName 回报类型名称（与 C++ enum 一致）
+func ResponseTypeName(t shm.ResponseType) string {
+	if name, ok := responseTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("RESPONSE(%d)", t)
+}
+
+// OrdTypeName 订单类型名称
+func OrdTypeName(t types.OrderHitType) string {
+	switch t {
+	case types.HitStandard:
+		return "STANDARD"
+	case types.HitImprove:
+		return "IMPROVE"
+	case types.HitCross:
+		return "CROSS"
+	case types.HitDetect:
+		return "DETECT"
+	case types.HitMatch:
+		return "MATCH"
+	}
+	return "UNKNOWN"
+}
+
+// sideName 'B'/'S' → "B"/"S"（未填为空）
+func sideName(b uint8) string {
+	if b == 0 {
+		return ""
+	}
+	return string(rune(b))
+}
+
+// CString 截取 C 字符串（到第一个 0 字节）
+func CString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
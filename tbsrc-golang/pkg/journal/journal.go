@@ -0,0 +1,256 @@
+// Package journal implements the append-only write-ahead journal of ORS
+// requests and responses used for crash recovery (Go 扩展).
+//
+// C++ TradeBot 不落盘订单/成交，崩溃后只能依赖上次 SaveMatrix2 写出的 daily_init；
+// 这里把每条 RequestMsg/ResponseMsg 按原始二进制布局追加写入日志，批量 fsync，
+// 重启时从最后一个 checkpoint（daily_init 保存点）之后重放，重建 OrdMap 和 ExecutionState。
+//
+// 记录格式（小端）:
+//
+//	u32 payload 长度 | u32 CRC32C(kind..payload) | u8 kind | u64 写入时间(ns) | payload
+//
+// 帧格式、批量 fsync 和读取与 kind 无关，golang/pkg/journal 复用本包的 Writer/Scan，
+// 只定义自己的 kind 和 payload（protobuf）。
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"sync"
+	"time"
+)
+
+// Kind 记录类型
+type Kind uint8
+
+const (
+	KindRequest    Kind = 1 // shm.RequestMsg + 订单类型
+	KindResponse   Kind = 2 // shm.ResponseMsg
+	KindCheckpoint Kind = 3 // 状态已持久化（daily_init），重放从其后开始
+)
+
+// String 返回记录类型名称
+func (k Kind) String() string {
+	switch k {
+	case KindRequest:
+		return "REQUEST"
+	case KindResponse:
+		return "RESPONSE"
+	case KindCheckpoint:
+		return "CHECKPOINT"
+	default:
+		return fmt.Sprintf("KIND(%d)", uint8(k))
+	}
+}
+
+const (
+	headerSize = 17      // len(4) + crc(4) + kind(1) + ts(8)
+	maxPayload = 1 << 20 // 单条记录上限，超过视为损坏
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Options 写入参数
+type Options struct {
+	SyncInterval time.Duration // 定时 fsync 间隔，默认 5ms
+	SyncBatch    int           // 未 fsync 记录数达到该值时立即 fsync，默认 64
+}
+
+func (o *Options) setDefaults() {
+	if o.SyncInterval <= 0 {
+		o.SyncInterval = 5 * time.Millisecond
+	}
+	if o.SyncBatch <= 0 {
+		o.SyncBatch = 64
+	}
+}
+
+// Writer 日志写入器，并发安全
+// Append 只写入用户态缓冲；缓冲在累计 SyncBatch 条或每 SyncInterval 时刷盘并 fsync，
+// 崩溃最多丢失最近一个批次（不会破坏已 fsync 的记录，残缺尾部在下次 Open 时截断）
+type Writer struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	buf     *bufio.Writer
+	opts    Options
+	pending int
+	records int64
+	err     error // 第一次刷盘错误，之后的 Append 都返回它
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open 打开（或创建）日志文件并启动后台 fsync
+// 已有文件先校验，截断崩溃时写了一半的尾部记录
+func Open(path string, opts Options) (*Writer, error) {
+	opts.setDefaults()
+
+	res, err := Scan(path, nil)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("journal: open %s: %w", path, err)
+	}
+	if res.TailBytes > 0 {
+		if err := f.Truncate(res.ValidSize); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("journal: truncate %s: %w", path, err)
+		}
+	}
+	if _, err := f.Seek(res.ValidSize, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("journal: seek %s: %w", path, err)
+	}
+
+	w := &Writer{
+		path:    path,
+		f:       f,
+		buf:     bufio.NewWriterSize(f, 64*1024),
+		opts:    opts,
+		records: int64(res.Records),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.syncLoop()
+	return w, nil
+}
+
+// Path 返回日志文件路径
+func (w *Writer) Path() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.path
+}
+
+// Records 返回文件中的记录数（含打开前已有的）
+func (w *Writer) Records() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.records
+}
+
+// Append 追加一条记录
+func (w *Writer) Append(kind Kind, payload []byte) error {
+	if len(payload) > maxPayload {
+		return fmt.Errorf("journal: payload too large (%d bytes)", len(payload))
+	}
+	var hdr [headerSize]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(len(payload)))
+	hdr[8] = byte(kind)
+	binary.LittleEndian.PutUint64(hdr[9:17], uint64(time.Now().UnixNano()))
+	crc := crc32.Update(0, crcTable, hdr[8:])
+	crc = crc32.Update(crc, crcTable, payload)
+	binary.LittleEndian.PutUint32(hdr[4:8], crc)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if w.f == nil {
+		return fmt.Errorf("journal: %s is closed", w.path)
+	}
+	w.buf.Write(hdr[:])
+	w.buf.Write(payload)
+	w.records++
+	w.pending++
+	if w.pending >= w.opts.SyncBatch {
+		return w.syncLocked()
+	}
+	return nil
+}
+
+// Sync 立即刷盘并 fsync
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.syncLocked()
+}
+
+// Rotate 把当前文件归档到 archivePath 并从空文件重新开始（交易日切换）
+func (w *Writer) Rotate(archivePath string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.syncLocked(); err != nil {
+		return err
+	}
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+	if err := os.Rename(w.path, archivePath); err != nil {
+		return fmt.Errorf("journal: archive %s: %w", w.path, err)
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("journal: reopen %s: %w", w.path, err)
+	}
+	w.f = f
+	w.buf.Reset(f)
+	w.records = 0
+	return nil
+}
+
+// Close 刷盘并关闭
+func (w *Writer) Close() error {
+	close(w.stop)
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return nil
+	}
+	err := w.syncLocked()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	w.f = nil
+	return err
+}
+
+// syncLoop 定时 fsync
+func (w *Writer) syncLoop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.pending > 0 && w.f != nil {
+				w.syncLocked()
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// syncLocked 刷盘，调用方持锁
+func (w *Writer) syncLocked() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.f == nil || w.pending == 0 && w.buf.Buffered() == 0 {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.err = fmt.Errorf("journal: write %s: %w", w.path, err)
+		return w.err
+	}
+	if err := w.f.Sync(); err != nil {
+		w.err = fmt.Errorf("journal: fsync %s: %w", w.path, err)
+		return w.err
+	}
+	w.pending = 0
+	return nil
+}
@@ -0,0 +1,194 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+func newRequest(id uint32, reqType shm.RequestType, symbol string, side uint8, price float64, qty int32) *shm.RequestMsg {
+	req := &shm.RequestMsg{}
+	req.Request_Type = reqType
+	req.OrderID = id
+	copy(req.ContractDesc.Symbol[:], symbol)
+	req.TransactionType = side
+	req.Price = price
+	req.Quantity = qty
+	return req
+}
+
+func newResponse(id uint32, respType shm.ResponseType, symbol string, side uint8, price float64, qty int32) *shm.ResponseMsg {
+	resp := &shm.ResponseMsg{}
+	resp.Response_Type = respType
+	resp.OrderID = id
+	copy(resp.Symbol[:], symbol)
+	resp.Side = side
+	resp.Price = price
+	resp.Quantity = qty
+	return resp
+}
+
+func openTemp(t *testing.T) (*Writer, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.92201")
+	w, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return w, path
+}
+
+func TestJournal_RoundTrip(t *testing.T) {
+	w, path := openTemp(t)
+	w.RecordRequest(newRequest(1000001, shm.NEWORDER, "ag2506", shm.SideBuy, 5810, 2), types.HitCross)
+	w.RecordResponse(newResponse(1000001, shm.TRADE_CONFIRM, "ag2506", shm.SideBuy, 5810, 2))
+	w.Checkpoint("daily_init", "20250102")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	recs, res, err := ReadAll(path)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(recs) != 3 || res.TailBytes != 0 {
+		t.Fatalf("records=%d tail=%d, want 3/0", len(recs), res.TailBytes)
+	}
+
+	req, ordType, err := DecodeRequest(recs[0].Payload)
+	if err != nil {
+		t.Fatalf("DecodeRequest: %v", err)
+	}
+	if req.OrderID != 1000001 || req.Quantity != 2 || ordType != types.HitCross ||
+		CString(req.ContractDesc.Symbol[:]) != "ag2506" {
+		t.Errorf("request = %+v ordType=%v", req, ordType)
+	}
+
+	e, err := Decode(recs[1])
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if e.Type != "TRADE_CONFIRM" || e.Side != "B" || e.Price != 5810 || e.Qty != 2 {
+		t.Errorf("entry = %+v", e)
+	}
+
+	e, _ = Decode(recs[2])
+	if e.Kind != "CHECKPOINT" || e.Type != "daily_init" || e.TradingDay != "20250102" {
+		t.Errorf("checkpoint entry = %+v", e)
+	}
+}
+
+func TestJournal_TornTailTruncatedOnOpen(t *testing.T) {
+	w, path := openTemp(t)
+	w.RecordRequest(newRequest(1, shm.NEWORDER, "ag2506", shm.SideBuy, 5810, 1), types.HitStandard)
+	w.RecordRequest(newRequest(2, shm.NEWORDER, "ag2506", shm.SideSell, 5811, 1), types.HitStandard)
+	w.Close()
+
+	// 模拟崩溃：最后一条记录只写了一半
+	info, _ := os.Stat(path)
+	os.Truncate(path, info.Size()-10)
+
+	recs, res, _ := ReadAll(path)
+	if len(recs) != 1 || res.TailBytes == 0 {
+		t.Fatalf("records=%d tail=%d, want 1 record and a torn tail", len(recs), res.TailBytes)
+	}
+
+	w, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if w.Records() != 1 {
+		t.Errorf("Records() = %d, want 1", w.Records())
+	}
+	w.RecordRequest(newRequest(3, shm.NEWORDER, "ag2506", shm.SideSell, 5812, 1), types.HitStandard)
+	w.Close()
+
+	recs, res, _ = ReadAll(path)
+	if len(recs) != 2 || res.TailBytes != 0 {
+		t.Fatalf("after reopen records=%d tail=%d, want 2/0", len(recs), res.TailBytes)
+	}
+	if req, _, _ := DecodeRequest(recs[1].Payload); req.OrderID != 3 {
+		t.Errorf("second record orderID = %d, want 3", req.OrderID)
+	}
+}
+
+func TestJournal_ChecksumMismatchStopsScan(t *testing.T) {
+	w, path := openTemp(t)
+	w.RecordRequest(newRequest(1, shm.NEWORDER, "ag2506", shm.SideBuy, 5810, 1), types.HitStandard)
+	w.RecordRequest(newRequest(2, shm.NEWORDER, "ag2506", shm.SideBuy, 5809, 1), types.HitStandard)
+	w.Close()
+
+	data, _ := os.ReadFile(path)
+	data[len(data)-20] ^= 0xff // 破坏第二条记录的 payload
+	os.WriteFile(path, data, 0644)
+
+	recs, res, _ := ReadAll(path)
+	if len(recs) != 1 || res.TailError == "" {
+		t.Errorf("records=%d tailError=%q, want 1 record and checksum error", len(recs), res.TailError)
+	}
+}
+
+func TestJournal_RotateAndAfterCheckpoint(t *testing.T) {
+	w, path := openTemp(t)
+	w.RecordRequest(newRequest(1, shm.NEWORDER, "ag2506", shm.SideBuy, 5810, 1), types.HitStandard)
+	w.Checkpoint("daily_init", "20250102")
+	w.RecordRequest(newRequest(2, shm.NEWORDER, "ag2506", shm.SideBuy, 5809, 1), types.HitStandard)
+
+	w.Sync()
+	recs, _, _ := ReadAll(path)
+	pending := AfterCheckpoint(recs)
+	if len(pending) != 1 {
+		t.Fatalf("AfterCheckpoint = %d records, want 1", len(pending))
+	}
+
+	archive := ArchivePath(path, "20250102")
+	if err := w.Rotate(archive); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	w.Checkpoint("rollover", "20250103")
+	w.Close()
+
+	if recs, _, _ := ReadAll(archive); len(recs) != 3 {
+		t.Errorf("archive records = %d, want 3", len(recs))
+	}
+	recs, _, _ = ReadAll(path)
+	if len(recs) != 1 || len(AfterCheckpoint(recs)) != 0 {
+		t.Errorf("new journal records = %d, want 1 checkpoint", len(recs))
+	}
+}
+
+func TestSummarize_OpenOrdersAndNetTraded(t *testing.T) {
+	w, path := openTemp(t)
+	w.Checkpoint("daily_init", "20250102")
+	// 1: 全部成交
+	w.RecordRequest(newRequest(1, shm.NEWORDER, "ag2506", shm.SideBuy, 5810, 2), types.HitStandard)
+	w.RecordResponse(newResponse(1, shm.NEW_ORDER_CONFIRM, "ag2506", shm.SideBuy, 5810, 2))
+	w.RecordResponse(newResponse(1, shm.TRADE_CONFIRM, "ag2506", shm.SideBuy, 5810, 1))
+	w.RecordResponse(newResponse(1, shm.TRADE_CONFIRM, "ag2506", shm.SideBuy, 5810, 1))
+	// 2: 撤单
+	w.RecordRequest(newRequest(2, shm.NEWORDER, "ag2512", shm.SideSell, 5801, 1), types.HitStandard)
+	w.RecordRequest(newRequest(2, shm.CANCELORDER, "ag2512", shm.SideSell, 5801, 1), types.HitStandard)
+	w.RecordResponse(newResponse(2, shm.CANCEL_ORDER_CONFIRM, "ag2512", shm.SideSell, 5801, 1))
+	// 3: 部分成交后仍在途
+	w.RecordRequest(newRequest(3, shm.NEWORDER, "ag2512", shm.SideSell, 5800, 3), types.HitCross)
+	w.RecordResponse(newResponse(3, shm.TRADE_CONFIRM, "ag2512", shm.SideSell, 5800, 1))
+	w.Close()
+
+	recs, _, _ := ReadAll(path)
+	sum := Summarize(recs)
+	if sum.Checkpoints != 1 || sum.AfterCheckpoint != 9 {
+		t.Errorf("checkpoints=%d after=%d, want 1/9", sum.Checkpoints, sum.AfterCheckpoint)
+	}
+	if len(sum.OpenOrders) != 1 || sum.OpenOrders[0] != 3 {
+		t.Errorf("OpenOrders = %v, want [3]", sum.OpenOrders)
+	}
+	if sum.NetTraded["ag2506"] != 2 || sum.NetTraded["ag2512"] != -1 {
+		t.Errorf("NetTraded = %v, want ag2506=+2 ag2512=-1", sum.NetTraded)
+	}
+	if sum.ByType["TRADE_CONFIRM"] != 3 {
+		t.Errorf("TRADE_CONFIRM count = %d, want 3", sum.ByType["TRADE_CONFIRM"])
+	}
+}
@@ -0,0 +1,121 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+)
+
+// Record 一条日志记录
+type Record struct {
+	Offset  int64 // 记录在文件中的起始偏移
+	Kind    Kind
+	Time    time.Time
+	Payload []byte
+}
+
+// ScanResult 扫描结果
+type ScanResult struct {
+	Records   int    // 完整且校验通过的记录数
+	ValidSize int64  // 有效数据长度（之后为残缺/损坏尾部）
+	TailBytes int64  // 被丢弃的尾部字节数
+	TailError string // 尾部丢弃原因（残缺记录 / 校验失败）
+}
+
+// errStop fn 返回它时提前结束扫描
+var errStop = errors.New("journal: stop")
+
+// Scan 顺序读取日志，对每条完整记录调用 fn（fn 为 nil 时只校验）
+// 遇到残缺记录或校验失败即停止：崩溃只可能破坏最后一个未 fsync 的批次，
+// 其后的数据不可信，统一计入 TailBytes，由 Open 截断
+func Scan(path string, fn func(Record) error) (ScanResult, error) {
+	var res ScanResult
+	f, err := os.Open(path)
+	if err != nil {
+		return res, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return res, err
+	}
+	size := info.Size()
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	var hdr [headerSize]byte
+	var off int64
+	for off < size {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			res.TailError = "truncated header"
+			break
+		}
+		n := binary.LittleEndian.Uint32(hdr[0:4])
+		if n > maxPayload || off+headerSize+int64(n) > size {
+			res.TailError = "truncated record"
+			break
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			res.TailError = "truncated record"
+			break
+		}
+		crc := crc32.Update(0, crcTable, hdr[8:])
+		crc = crc32.Update(crc, crcTable, payload)
+		if crc != binary.LittleEndian.Uint32(hdr[4:8]) {
+			res.TailError = fmt.Sprintf("checksum mismatch at offset %d", off)
+			break
+		}
+
+		rec := Record{
+			Offset:  off,
+			Kind:    Kind(hdr[8]),
+			Time:    time.Unix(0, int64(binary.LittleEndian.Uint64(hdr[9:17]))),
+			Payload: payload,
+		}
+		off += headerSize + int64(n)
+		res.Records++
+		res.ValidSize = off
+		if fn != nil {
+			if err := fn(rec); err != nil {
+				if err == errStop {
+					return res, nil
+				}
+				return res, err
+			}
+		}
+	}
+	res.TailBytes = size - res.ValidSize
+	return res, nil
+}
+
+// ReadAll 读取全部有效记录
+func ReadAll(path string) ([]Record, ScanResult, error) {
+	var recs []Record
+	res, err := Scan(path, func(r Record) error {
+		recs = append(recs, r)
+		return nil
+	})
+	return recs, res, err
+}
+
+// AfterCheckpoint 返回最后一个 checkpoint 之后的记录（没有 checkpoint 时返回全部）
+func AfterCheckpoint(recs []Record) []Record {
+	return AfterKind(recs, KindCheckpoint)
+}
+
+// AfterKind 返回最后一条 kind 类型记录之后的记录（没有时返回全部）
+// 供自定义记录类型的日志（golang/pkg/journal）按自己的 checkpoint 类型截取
+func AfterKind(recs []Record, kind Kind) []Record {
+	for i := len(recs) - 1; i >= 0; i-- {
+		if recs[i].Kind == kind {
+			return recs[i+1:]
+		}
+	}
+	return recs
+}
@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+var (
+	requestSize  = int(unsafe.Sizeof(shm.RequestMsg{}))
+	responseSize = int(unsafe.Sizeof(shm.ResponseMsg{}))
+)
+
+// Checkpoint checkpoint 记录内容
+type Checkpoint struct {
+	Reason     string `json:"reason"`                // daily_init / rollover / recovery
+	TradingDay string `json:"trading_day,omitempty"` // YYYYMMDD
+}
+
+// EncodeRequest RequestMsg 原始字节（与 SHM 布局一致）+ 订单类型
+// 订单类型（STANDARD/CROSS/...）不在 RequestMsg 中，但决定成交计入被动仓还是主动仓，重放时需要
+func EncodeRequest(req *shm.RequestMsg, ordType types.OrderHitType) []byte {
+	buf := make([]byte, requestSize+4)
+	copy(buf, unsafe.Slice((*byte)(unsafe.Pointer(req)), requestSize))
+	binary.LittleEndian.PutUint32(buf[requestSize:], uint32(ordType))
+	return buf
+}
+
+// DecodeRequest 解析 KindRequest 记录
+func DecodeRequest(p []byte) (shm.RequestMsg, types.OrderHitType, error) {
+	var req shm.RequestMsg
+	if len(p) != requestSize+4 {
+		return req, 0, fmt.Errorf("journal: request record size %d, want %d", len(p), requestSize+4)
+	}
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&req)), requestSize), p)
+	return req, types.OrderHitType(binary.LittleEndian.Uint32(p[requestSize:])), nil
+}
+
+// EncodeResponse ResponseMsg 原始字节
+func EncodeResponse(resp *shm.ResponseMsg) []byte {
+	buf := make([]byte, responseSize)
+	copy(buf, unsafe.Slice((*byte)(unsafe.Pointer(resp)), responseSize))
+	return buf
+}
+
+// DecodeResponse 解析 KindResponse 记录
+func DecodeResponse(p []byte) (shm.ResponseMsg, error) {
+	var resp shm.ResponseMsg
+	if len(p) != responseSize {
+		return resp, fmt.Errorf("journal: response record size %d, want %d", len(p), responseSize)
+	}
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&resp)), responseSize), p)
+	return resp, nil
+}
+
+// DecodeCheckpoint 解析 KindCheckpoint 记录
+func DecodeCheckpoint(p []byte) (Checkpoint, error) {
+	var cp Checkpoint
+	err := json.Unmarshal(p, &cp)
+	return cp, err
+}
+
+// RecordRequest 记录已发出的请求
+func (w *Writer) RecordRequest(req *shm.RequestMsg, ordType types.OrderHitType) error {
+	return w.Append(KindRequest, EncodeRequest(req, ordType))
+}
+
+// RecordResponse 记录收到的回报
+func (w *Writer) RecordResponse(resp *shm.ResponseMsg) error {
+	return w.Append(KindResponse, EncodeResponse(resp))
+}
+
+// Checkpoint 记录状态已持久化并立即 fsync，重放从此之后开始
+func (w *Writer) Checkpoint(reason, tradingDay string) error {
+	data, _ := json.Marshal(Checkpoint{Reason: reason, TradingDay: tradingDay})
+	if err := w.Append(KindCheckpoint, data); err != nil {
+		return err
+	}
+	return w.Sync()
+}
+
+// DefaultPath 日志路径：<dataDir>/journal.<strategyID>（与 daily_init.<strategyID> 同目录）
+func DefaultPath(dataDir string, strategyID int) string {
+	return fmt.Sprintf("%s/journal.%d", dataDir, strategyID)
+}
+
+// ArchivePath 交易日归档文件名：<path>.<YYYYMMDD>
+func ArchivePath(path, tradingDay string) string {
+	if tradingDay == "" {
+		tradingDay = time.Now().Format("20060102")
+	}
+	return path + "." + tradingDay
+}
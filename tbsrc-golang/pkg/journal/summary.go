@@ -0,0 +1,102 @@
+package journal
+
+import (
+	"sort"
+	"time"
+
+	"tbsrc-golang/pkg/shm"
+)
+
+// Summary 日志概要（journal inspect 输出）
+type Summary struct {
+	Records         int
+	ByType          map[string]int // 请求/回报类型 → 条数
+	First, Last     time.Time
+	Checkpoints     int
+	LastCheckpoint  *Entry
+	AfterCheckpoint int              // 最后一个 checkpoint 之后的记录数（>0 表示上次未正常退出或尚未退出）
+	OpenOrders      []uint32         // 日志末尾仍在途的订单
+	NetTraded       map[string]int32 // 合约 → 成交净量（买为正）
+	Errors          int              // 无法解析的记录
+}
+
+// orderTrack 在途订单跟踪
+type orderTrack struct {
+	qty, filled, modifyQty int32
+}
+
+// Summarize 统计日志记录
+// 在途订单：NEWORDER 之后没有撤单确认/拒单/过期，且成交量未达委托量
+func Summarize(recs []Record) Summary {
+	sum := Summary{
+		Records:   len(recs),
+		ByType:    make(map[string]int),
+		NetTraded: make(map[string]int32),
+	}
+	orders := make(map[uint32]*orderTrack)
+
+	for i, rec := range recs {
+		if i == 0 {
+			sum.First = rec.Time
+		}
+		sum.Last = rec.Time
+
+		e, err := Decode(rec)
+		if err != nil {
+			sum.Errors++
+			continue
+		}
+		sum.ByType[e.Type]++
+		sum.AfterCheckpoint++
+
+		switch rec.Kind {
+		case KindCheckpoint:
+			sum.Checkpoints++
+			cp := e
+			sum.LastCheckpoint = &cp
+			sum.AfterCheckpoint = 0
+
+		case KindRequest:
+			req, _, _ := DecodeRequest(rec.Payload)
+			switch req.Request_Type {
+			case shm.NEWORDER:
+				orders[req.OrderID] = &orderTrack{qty: req.Quantity}
+			case shm.MODIFYORDER:
+				if o, ok := orders[req.OrderID]; ok {
+					o.modifyQty = req.Quantity
+				}
+			}
+
+		case KindResponse:
+			resp, _ := DecodeResponse(rec.Payload)
+			o, ok := orders[resp.OrderID]
+			switch resp.Response_Type {
+			case shm.TRADE_CONFIRM:
+				if resp.Side == shm.SideBuy {
+					sum.NetTraded[e.Symbol] += resp.Quantity
+				} else {
+					sum.NetTraded[e.Symbol] -= resp.Quantity
+				}
+				if ok {
+					o.filled += resp.Quantity
+					if o.filled >= o.qty {
+						delete(orders, resp.OrderID)
+					}
+				}
+			case shm.MODIFY_ORDER_CONFIRM:
+				if ok && o.modifyQty > 0 {
+					o.qty = o.modifyQty
+				}
+			case shm.CANCEL_ORDER_CONFIRM, shm.NEW_ORDER_FREEZE, shm.ORDER_ERROR, shm.ORS_REJECT,
+				shm.RMS_REJECT, shm.SIM_REJECT, shm.BUSINESS_REJECT, shm.ORDER_EXPIRED:
+				delete(orders, resp.OrderID)
+			}
+		}
+	}
+
+	for id := range orders {
+		sum.OpenOrders = append(sum.OpenOrders, id)
+	}
+	sort.Slice(sum.OpenOrders, func(i, j int) bool { return sum.OpenOrders[i] < sum.OpenOrders[j] })
+	return sum
+}
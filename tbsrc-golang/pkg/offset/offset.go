@@ -0,0 +1,196 @@
+// Package offset 开平仓选择（平今/平昨/开仓）
+// SHFE/INE 必须显式平今/平昨；DCE/ZCE/CFFEX 部分品种平今手续费远高于开仓，可按偏好锁仓
+// 参考: gateway/src/counter_bridge.cpp SetCombOffsetFlag / updatePosition
+package offset
+
+import (
+	"fmt"
+	"strings"
+
+	"tbsrc-golang/pkg/calendar"
+	"tbsrc-golang/pkg/shm"
+)
+
+// Offset 开平标志
+type Offset int
+
+const (
+	Open           Offset = iota // 开仓
+	Close                        // 平仓（非 SHFE，交易所先平昨）
+	CloseToday                   // 平今
+	CloseYesterday               // 平昨
+)
+
+// String 返回开平标志名称
+func (o Offset) String() string {
+	switch o {
+	case Open:
+		return "OPEN"
+	case Close:
+		return "CLOSE"
+	case CloseToday:
+		return "CLOSE_TODAY"
+	case CloseYesterday:
+		return "CLOSE_YESTERDAY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// PosDirection 转换为 RequestMsg.PosDirection
+// POS_CLOSE_INTRADAY = 平今，POS_CLOSE = 平昨/平仓
+func (o Offset) PosDirection() shm.PositionDirection {
+	switch o {
+	case CloseToday:
+		return shm.POS_CLOSE_INTRADAY
+	case Close, CloseYesterday:
+		return shm.POS_CLOSE
+	default:
+		return shm.POS_OPEN
+	}
+}
+
+// LockMode 平今偏好
+type LockMode int
+
+const (
+	LockNever  LockMode = iota // 直接平今
+	LockAuto                   // 平今手续费高于开仓时锁仓（反向开仓）
+	LockAlways                 // 今仓一律锁仓
+)
+
+// ParseLockMode 解析配置："" / never / auto / always
+func ParseLockMode(s string) (LockMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "never":
+		return LockNever, nil
+	case "auto":
+		return LockAuto, nil
+	case "always":
+		return LockAlways, nil
+	default:
+		return LockNever, fmt.Errorf("unknown lock mode %q", s)
+	}
+}
+
+// String 返回锁仓偏好名称
+func (m LockMode) String() string {
+	switch m {
+	case LockAuto:
+		return "auto"
+	case LockAlways:
+		return "always"
+	default:
+		return "never"
+	}
+}
+
+// SplitsToday 交易所是否区分平今/平昨
+// C++: isSHFE = (exchangeType == CHINA_SHFE)，INE 在 tbsrc 中按 SHFE 报单
+func SplitsToday(exchangeType uint8) bool {
+	return exchangeType == shm.ChinaSHFE
+}
+
+// 交易所代码（与 golang TradingSignal.Exchange 一致）
+const (
+	ExchangeSHFE  = "SHFE"
+	ExchangeINE   = "INE"
+	ExchangeDCE   = "DCE"
+	ExchangeCZCE  = "CZCE"
+	ExchangeCFFEX = "CFFEX"
+	ExchangeGFEX  = "GFEX"
+)
+
+// Fee 单项手续费：按成交金额比例 + 每手固定金额
+type Fee struct {
+	Ratio  float64 // 成交金额比例，如 0.0001 = 万分之一
+	PerLot float64 // 每手固定金额（元）
+}
+
+// Cost 计算 qty 手手续费，notional 为每手成交金额（价格 × 合约乘数）
+func (f Fee) Cost(notional float64, qty int32) float64 {
+	return (f.Ratio*notional + f.PerLot) * float64(qty)
+}
+
+// Product 品种属性：交易所、合约乘数、开/平昨/平今手续费
+type Product struct {
+	Exchange       string
+	Multiplier     float64
+	Open           Fee
+	CloseYesterday Fee
+	CloseToday     Fee
+}
+
+// DefaultProducts 内置品种表（小写品种代码），tbsrc 和 golang 的开平规划器共用
+// 费率为交易所标准费率的示例值，实际以交易所公告和期货公司加收为准，可通过配置覆盖
+func DefaultProducts() map[string]Product {
+	ratio := func(exch string, mult, open, closeTd float64) Product {
+		return Product{
+			Exchange:       exch,
+			Multiplier:     mult,
+			Open:           Fee{Ratio: open},
+			CloseYesterday: Fee{Ratio: open},
+			CloseToday:     Fee{Ratio: closeTd},
+		}
+	}
+	perLot := func(exch string, mult, open, closeTd float64) Product {
+		return Product{
+			Exchange:       exch,
+			Multiplier:     mult,
+			Open:           Fee{PerLot: open},
+			CloseYesterday: Fee{PerLot: open},
+			CloseToday:     Fee{PerLot: closeTd},
+		}
+	}
+
+	return map[string]Product{
+		// SHFE / INE：必须显式平今/平昨
+		"ag": ratio(ExchangeSHFE, 15, 0.00005, 0.00005),
+		"au": perLot(ExchangeSHFE, 1000, 2, 0),
+		"cu": ratio(ExchangeSHFE, 5, 0.00005, 0.0001),
+		"al": perLot(ExchangeSHFE, 5, 3, 0),
+		"zn": perLot(ExchangeSHFE, 5, 3, 0),
+		"ni": perLot(ExchangeSHFE, 1, 3, 0),
+		"rb": ratio(ExchangeSHFE, 10, 0.0001, 0.0001),
+		"hc": ratio(ExchangeSHFE, 10, 0.0001, 0.0001),
+		"ru": perLot(ExchangeSHFE, 10, 3, 3),
+		"bu": ratio(ExchangeSHFE, 10, 0.0001, 0.0001),
+		"fu": ratio(ExchangeSHFE, 10, 0.00005, 0),
+		"sc": perLot(ExchangeINE, 1000, 20, 0),
+		"lu": ratio(ExchangeINE, 10, 0.0001, 0),
+		"nr": ratio(ExchangeINE, 10, 0.0002, 0),
+
+		// DCE：平仓不区分今昨，但部分品种平今手续费远高于开仓
+		"i":  ratio(ExchangeDCE, 100, 0.0001, 0.0001),
+		"j":  ratio(ExchangeDCE, 100, 0.0001, 0.00014),
+		"jm": ratio(ExchangeDCE, 60, 0.0001, 0.0003),
+		"m":  perLot(ExchangeDCE, 10, 1.5, 1.5),
+		"y":  perLot(ExchangeDCE, 10, 2.5, 2.5),
+		"p":  perLot(ExchangeDCE, 10, 2.5, 2.5),
+		"pp": perLot(ExchangeDCE, 5, 1, 1),
+		"v":  perLot(ExchangeDCE, 5, 1, 1),
+		"eg": perLot(ExchangeDCE, 10, 3, 3),
+
+		// CZCE
+		"ma": perLot(ExchangeCZCE, 10, 2, 6),
+		"ta": perLot(ExchangeCZCE, 5, 3, 0),
+		"sa": perLot(ExchangeCZCE, 20, 3.5, 3.5),
+		"fg": perLot(ExchangeCZCE, 20, 6, 6),
+		"sr": perLot(ExchangeCZCE, 10, 3, 0),
+		"cf": perLot(ExchangeCZCE, 5, 4.3, 0),
+		"ap": perLot(ExchangeCZCE, 10, 5, 20),
+
+		// CFFEX：股指平今手续费为开仓的 10 倍
+		"if": ratio(ExchangeCFFEX, 300, 0.000023, 0.00023),
+		"ih": ratio(ExchangeCFFEX, 300, 0.000023, 0.00023),
+		"ic": ratio(ExchangeCFFEX, 200, 0.000023, 0.00023),
+		"im": ratio(ExchangeCFFEX, 200, 0.000023, 0.00023),
+		"t":  perLot(ExchangeCFFEX, 10000, 3, 0),
+	}
+}
+
+// ProductKey 合约代码 → 品种表 key（ag2506 → ag，MA505 → ma）
+// 品种前缀由 calendar.ProductOf 解析，品种表按小写存放
+func ProductKey(symbol string) string {
+	return strings.ToLower(calendar.ProductOf(symbol))
+}
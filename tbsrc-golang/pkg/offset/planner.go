@@ -0,0 +1,260 @@
+package offset
+
+import (
+	"strings"
+	"sync"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// Position 单合约今/昨可平量（已扣除在途平仓单）
+// C++: contractPos{ONLongPos, todayLongPos, ONShortPos, todayShortPos}
+type Position struct {
+	LongTd  int32
+	LongYd  int32
+	ShortTd int32
+	ShortYd int32
+}
+
+// Piece 一笔订单的开平选择
+type Piece struct {
+	Symbol string
+	Side   types.TransactionType
+	Offset Offset
+	Qty    int32
+
+	fromTd int32 // 占用的今仓
+	fromYd int32 // 占用的昨仓
+}
+
+// Planner 开平仓规划器 + 持仓台账
+// tbsrc 订单路径一个 orderID 对应一笔订单（OrderManager / 价位 map 按单跟踪），
+// 因此与 C++ SetCombOffsetFlag 一样每笔只选一个开平标志，不拆单：
+// 整笔数量能被某一侧（今/昨）覆盖时平仓，否则开仓
+// 与 C++ 的区别：今/昨的先后按品种手续费决定（平今更便宜时先平今），并支持锁仓偏好
+type Planner struct {
+	mu        sync.Mutex
+	products  map[string]Product
+	lock      LockMode
+	positions map[string]*Position
+	orders    map[uint32]*Piece
+}
+
+// NewPlanner 创建规划器，使用内置费率表
+func NewPlanner(lock LockMode) *Planner {
+	return &Planner{
+		products:  DefaultProducts(),
+		lock:      lock,
+		positions: make(map[string]*Position),
+		orders:    make(map[uint32]*Piece),
+	}
+}
+
+// LockMode 返回锁仓偏好
+func (p *Planner) LockMode() LockMode {
+	return p.lock
+}
+
+// SetProduct 覆盖品种费率
+func (p *Planner) SetProduct(product string, prod Product) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.products[strings.ToLower(product)] = prod
+}
+
+// SetPosition 设置合约今/昨持仓
+func (p *Planner) SetPosition(symbol string, pos Position) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cp := pos
+	p.positions[symbol] = &cp
+}
+
+// SetNetYesterday 按昨日净持仓设置昨仓（daily_init 的 ytd 净持仓，今仓清零）
+func (p *Planner) SetNetYesterday(symbol string, netpos int32) {
+	var pos Position
+	if netpos > 0 {
+		pos.LongYd = netpos
+	} else {
+		pos.ShortYd = -netpos
+	}
+	p.SetPosition(symbol, pos)
+}
+
+// Position 返回合约当前可平量
+func (p *Planner) Position(symbol string) Position {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pos, ok := p.positions[symbol]; ok {
+		return *pos
+	}
+	return Position{}
+}
+
+// Plan 为整笔订单选择开平标志并扣减可平量
+// 参考: counter_bridge.cpp SetCombOffsetFlag
+// SHFE/INE 一笔订单只能带一个开平标志：今仓、昨仓都不足以覆盖整笔数量时整笔开仓，
+// 不拆成平昨 + 平今 + 开仓（例：多头 昨 3 + 今 2，卖 5 → 开空 5，与原多头形成锁仓）。
+// 此时今/昨可平量不扣减，锁仓部分由后续不超过单侧可平量的订单平掉；
+// 需要拆单的场景（跟单、手工下单）走 golang/pkg/offset 的拆单规划
+func (p *Planner) Plan(symbol string, exchangeType uint8, side types.TransactionType, qty int32, price float64) Piece {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	piece := Piece{Symbol: symbol, Side: side, Offset: Open, Qty: qty}
+	if qty <= 0 {
+		return piece
+	}
+
+	pos := p.position(symbol)
+	td, yd := &pos.LongTd, &pos.LongYd
+	if side == types.Buy {
+		td, yd = &pos.ShortTd, &pos.ShortYd
+	}
+
+	prod, known := p.products[ProductKey(symbol)]
+	notional := price * prod.Multiplier
+	lockToday := false
+	switch p.lock {
+	case LockAlways:
+		lockToday = true
+	case LockAuto:
+		lockToday = known && prod.CloseToday.Cost(notional, 1) > prod.Open.Cost(notional, 1)
+	}
+
+	if !SplitsToday(exchangeType) {
+		// 非 SHFE：CLOSE 由交易所先平昨，锁仓时只允许平到昨仓为止
+		closable := *yd
+		if !lockToday {
+			closable += *td
+		}
+		if qty <= closable {
+			piece.Offset = Close
+			piece.fromYd = min(qty, *yd)
+			piece.fromTd = qty - piece.fromYd
+			*yd -= piece.fromYd
+			*td -= piece.fromTd
+		}
+		return piece
+	}
+
+	todayFirst := known && prod.CloseToday.Cost(notional, 1) < prod.CloseYesterday.Cost(notional, 1)
+	tryToday := func() bool {
+		if lockToday || qty > *td {
+			return false
+		}
+		*td -= qty
+		piece.Offset, piece.fromTd = CloseToday, qty
+		return true
+	}
+	tryYesterday := func() bool {
+		if qty > *yd {
+			return false
+		}
+		*yd -= qty
+		piece.Offset, piece.fromYd = CloseYesterday, qty
+		return true
+	}
+	if todayFirst {
+		if !tryToday() {
+			tryYesterday()
+		}
+	} else if !tryYesterday() {
+		tryToday()
+	}
+	return piece
+}
+
+// Track 订单已发出，按 orderID 跟踪回报
+func (p *Planner) Track(orderID uint32, piece Piece) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cp := piece
+	p.orders[orderID] = &cp
+}
+
+// OnResponse 处理 ORS 回报
+// C++: updatePosition — TRADE_CONFIRM 开仓计入今仓；拒单/撤单按未成交量恢复平仓占用
+func (p *Planner) OnResponse(resp *shm.ResponseMsg) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	piece, ok := p.orders[resp.OrderID]
+	if !ok {
+		return
+	}
+
+	switch resp.Response_Type {
+	case shm.TRADE_CONFIRM:
+		qty := min(resp.Quantity, piece.Qty)
+		if qty <= 0 {
+			return
+		}
+		piece.Qty -= qty
+		if piece.Offset == Open {
+			pos := p.position(piece.Symbol)
+			if piece.Side == types.Buy {
+				pos.LongTd += qty
+			} else {
+				pos.ShortTd += qty
+			}
+		} else {
+			fromYd := min(qty, piece.fromYd)
+			piece.fromYd -= fromYd
+			piece.fromTd -= qty - fromYd
+		}
+		if piece.Qty <= 0 {
+			delete(p.orders, resp.OrderID)
+		}
+
+	case shm.ORDER_ERROR, shm.ORS_REJECT, shm.RMS_REJECT, shm.SIM_REJECT,
+		shm.BUSINESS_REJECT, shm.CANCEL_ORDER_CONFIRM, shm.ORDER_EXPIRED:
+		delete(p.orders, resp.OrderID)
+		p.restore(piece)
+	}
+}
+
+// Abort 订单未发出（如写前日志失败），恢复 Plan 时扣减的可平量
+func (p *Planner) Abort(piece Piece) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.restore(&piece)
+}
+
+// Rollover 交易日切换：今仓转昨仓
+func (p *Planner) Rollover() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pos := range p.positions {
+		pos.LongYd += pos.LongTd
+		pos.LongTd = 0
+		pos.ShortYd += pos.ShortTd
+		pos.ShortTd = 0
+	}
+}
+
+// position 取合约持仓（不存在时创建），调用方持锁
+func (p *Planner) position(symbol string) *Position {
+	pos, ok := p.positions[symbol]
+	if !ok {
+		pos = &Position{}
+		p.positions[symbol] = pos
+	}
+	return pos
+}
+
+// restore 恢复平仓占用，调用方持锁
+func (p *Planner) restore(piece *Piece) {
+	if piece.fromTd <= 0 && piece.fromYd <= 0 {
+		return
+	}
+	pos := p.position(piece.Symbol)
+	if piece.Side == types.Buy {
+		pos.ShortTd += piece.fromTd
+		pos.ShortYd += piece.fromYd
+	} else {
+		pos.LongTd += piece.fromTd
+		pos.LongYd += piece.fromYd
+	}
+}
@@ -0,0 +1,126 @@
+package offset
+
+import (
+	"testing"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+func TestPlan_SHFEYesterdayThenToday(t *testing.T) {
+	p := NewPlanner(LockNever)
+	p.SetPosition("rb2505", Position{LongTd: 3, LongYd: 2})
+
+	// 平今平昨同费率：先平昨
+	pc := p.Plan("rb2505", shm.ChinaSHFE, types.Sell, 2, 3500)
+	if pc.Offset != CloseYesterday {
+		t.Errorf("Offset = %v, want CLOSE_YESTERDAY", pc.Offset)
+	}
+	// 昨仓不足，今仓足够：平今
+	pc = p.Plan("rb2505", shm.ChinaSHFE, types.Sell, 3, 3500)
+	if pc.Offset != CloseToday {
+		t.Errorf("Offset = %v, want CLOSE_TODAY", pc.Offset)
+	}
+	// 已全部占用：开仓
+	pc = p.Plan("rb2505", shm.ChinaSHFE, types.Sell, 1, 3500)
+	if pc.Offset != Open || pc.Offset.PosDirection() != shm.POS_OPEN {
+		t.Errorf("Offset = %v, want OPEN", pc.Offset)
+	}
+	if pos := p.Position("rb2505"); pos.LongTd != 0 || pos.LongYd != 0 {
+		t.Errorf("position = %+v, want all reserved", pos)
+	}
+}
+
+func TestPlan_CheaperTodayAndLock(t *testing.T) {
+	// au 平今免费：先平今
+	p := NewPlanner(LockNever)
+	p.SetPosition("au2506", Position{ShortTd: 2, ShortYd: 2})
+	if pc := p.Plan("au2506", shm.ChinaSHFE, types.Buy, 2, 600); pc.Offset != CloseToday {
+		t.Errorf("Offset = %v, want CLOSE_TODAY", pc.Offset)
+	}
+
+	// cu 平今费高于开仓，auto 锁仓
+	p = NewPlanner(LockAuto)
+	p.SetPosition("cu2505", Position{LongTd: 4})
+	if pc := p.Plan("cu2505", shm.ChinaSHFE, types.Sell, 2, 75000); pc.Offset != Open {
+		t.Errorf("Offset = %v, want OPEN (lock)", pc.Offset)
+	}
+
+	// 非 SHFE：always 只平昨仓
+	p = NewPlanner(LockAlways)
+	p.SetPosition("IF2506", Position{ShortTd: 3, ShortYd: 1})
+	if pc := p.Plan("IF2506", shm.ChinaCFFEX, types.Buy, 2, 3800); pc.Offset != Open {
+		t.Errorf("Offset = %v, want OPEN", pc.Offset)
+	}
+	if pc := p.Plan("IF2506", shm.ChinaCFFEX, types.Buy, 1, 3800); pc.Offset != Close || pc.Offset.PosDirection() != shm.POS_CLOSE {
+		t.Errorf("Offset = %v, want CLOSE", pc.Offset)
+	}
+}
+
+func TestPlanner_OnResponseAndRollover(t *testing.T) {
+	p := NewPlanner(LockNever)
+	p.SetNetYesterday("ag2506", 3)
+
+	closeID, openID := uint32(1), uint32(2)
+	p.Track(closeID, p.Plan("ag2506", shm.ChinaSHFE, types.Sell, 3, 8000))
+	p.Track(openID, p.Plan("ag2506", shm.ChinaSHFE, types.Buy, 2, 8000))
+
+	// 平昨成交 1 手后撤单，恢复 2 手
+	p.OnResponse(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: closeID, Quantity: 1})
+	p.OnResponse(&shm.ResponseMsg{Response_Type: shm.CANCEL_ORDER_CONFIRM, OrderID: closeID, Quantity: 2})
+	// 开仓成交计入今多仓
+	p.OnResponse(&shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: openID, Quantity: 2})
+
+	pos := p.Position("ag2506")
+	if pos.LongYd != 2 || pos.LongTd != 2 {
+		t.Errorf("position = %+v, want LongYd 2 LongTd 2", pos)
+	}
+
+	p.Rollover()
+	pos = p.Position("ag2506")
+	if pos.LongYd != 4 || pos.LongTd != 0 {
+		t.Errorf("after rollover = %+v, want LongYd 4", pos)
+	}
+}
+
+func TestParseLockMode(t *testing.T) {
+	if m, err := ParseLockMode("auto"); err != nil || m != LockAuto {
+		t.Errorf("ParseLockMode(auto) = %v, %v, want auto", m, err)
+	}
+	if _, err := ParseLockMode("bogus"); err == nil {
+		t.Error("ParseLockMode(bogus) error = nil, want error")
+	}
+}
+
+func TestProductKey(t *testing.T) {
+	products := DefaultProducts()
+	for symbol, want := range map[string]string{"ag2506": ExchangeSHFE, "MA505": ExchangeCZCE, "sc2509": ExchangeINE, "T2506": ExchangeCFFEX} {
+		if prod, ok := products[ProductKey(symbol)]; !ok || prod.Exchange != want {
+			t.Errorf("products[%q] = %+v, %v, want exchange %s", ProductKey(symbol), prod, ok, want)
+		}
+	}
+}
+
+// SHFE 今/昨单侧都不足时整笔开仓（锁仓），不扣减可平量
+func TestPlan_SHFEOpensWhenNeitherBucketCovers(t *testing.T) {
+	p := NewPlanner(LockNever)
+	p.SetPosition("rb2505", Position{LongYd: 3, LongTd: 2})
+
+	pc := p.Plan("rb2505", shm.ChinaSHFE, types.Sell, 5, 3500)
+	if pc.Offset != Open || pc.Qty != 5 {
+		t.Fatalf("piece = %+v, want OPEN 5", pc)
+	}
+	if pos := p.Position("rb2505"); pos.LongYd != 3 || pos.LongTd != 2 {
+		t.Errorf("position = %+v, want unchanged yd=3 td=2", pos)
+	}
+	// 锁仓后，单侧可覆盖的订单仍按平仓处理
+	if pc := p.Plan("rb2505", shm.ChinaSHFE, types.Sell, 3, 3500); pc.Offset != CloseYesterday {
+		t.Errorf("Offset = %v, want CLOSE_YESTERDAY", pc.Offset)
+	}
+
+	// 非 SHFE 不区分今昨：合计可平量覆盖即平仓
+	p.SetPosition("i2509", Position{LongYd: 3, LongTd: 2})
+	if pc := p.Plan("i2509", shm.ChinaDCE, types.Sell, 5, 800); pc.Offset != Close {
+		t.Errorf("Offset = %v, want CLOSE", pc.Offset)
+	}
+}
@@ -0,0 +1,240 @@
+// Package paramset implements a staged parameter change workflow: stage a
+// parameter set, preview the diff against live values with validation and
+// risk hints, approve, apply atomically to one or more strategies (optionally
+// scheduled), and roll back to any previous version (Go 扩展)
+//
+// C++ 中参数只能通过修改 model 文件 + 重启/信号重载生效，没有预览与回滚；
+// 这里所有已生效的变更都以版本形式持久化在磁盘上。
+package paramset
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ParamDiff 单个参数的变化
+type ParamDiff struct {
+	Key      string      `json:"key"`
+	Live     interface{} `json:"live"`
+	New      interface{} `json:"new"`
+	DeltaPct float64     `json:"delta_pct,omitempty"` // 数值参数的相对变化（%），live 为 0 时不计算
+}
+
+// Level 提示级别
+type Level string
+
+const (
+	LevelInfo  Level = "info"
+	LevelWarn  Level = "warn"
+	LevelError Level = "error" // 校验失败，变更不能生效
+)
+
+// Hint 校验结果与风险提示
+type Hint struct {
+	Strategy string `json:"strategy"`
+	Key      string `json:"key,omitempty"`
+	Level    Level  `json:"level"`
+	Message  string `json:"message"`
+}
+
+// Limits 风险限额（0 = 不检查），用于生成风险提示
+type Limits struct {
+	MaxPosition  int64   `json:"max_position,omitempty"`  // 风控/账户允许的最大持仓
+	MaxOrderQty  int64   `json:"max_order_qty,omitempty"` // 账户单笔委托上限
+	StopLoss     float64 `json:"stop_loss,omitempty"`
+	MaxLoss      float64 `json:"max_loss,omitempty"`
+	MaxChangePct float64 `json:"max_change_pct,omitempty"` // 相对变化超过该百分比时提示
+}
+
+// Diff 比较 live 与 proposed，仅返回有变化的参数（按 key 排序）
+func Diff(live, proposed map[string]interface{}) []ParamDiff {
+	keys := make([]string, 0, len(proposed))
+	for k := range proposed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out []ParamDiff
+	for _, k := range keys {
+		nv, lv := proposed[k], live[k]
+		if Equal(lv, nv) {
+			continue
+		}
+		d := ParamDiff{Key: k, Live: lv, New: nv}
+		if a, ok := toFloat(lv); ok && a != 0 {
+			if b, ok := toFloat(nv); ok {
+				d.DeltaPct = math.Round((b-a)/math.Abs(a)*10000) / 100
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// Equal 比较两个参数值，数值按 float64 比较（int/int64/float64 等价）
+func Equal(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && math.Abs(x-y) <= 1e-9*math.Max(1, math.Abs(x))
+	}
+	return a == b
+}
+
+// Normalize 将数值统一为 float64（JSON 上传与持久化版本的类型一致，策略 ApplyParameters 均接受 float64）
+func Normalize(params map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if f, ok := toFloat(v); ok {
+			out[k] = f
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// Check 校验单个策略的新参数并给出风险提示
+// 规则：未知参数/类型不符/负的数量为 error；order_size > max_position_size、
+// entry_zscore <= exit_zscore 为 error；超出风控限额与大幅变化为 warn
+// 组合检查同时识别 Go 策略参数名与 C++ 阈值名（size/max_size/begin_place/begin_remove）
+func Check(strategyID string, live, proposed map[string]interface{}, limits Limits) []Hint {
+	var hints []Hint
+	add := func(key string, level Level, format string, args ...interface{}) {
+		hints = append(hints, Hint{Strategy: strategyID, Key: key, Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+
+	keys := make([]string, 0, len(proposed))
+	for k := range proposed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		lv, known := live[k]
+		nv := proposed[k]
+		if !known {
+			add(k, LevelError, "unknown parameter (strategy would ignore it)")
+			continue
+		}
+		lf, liveNum := toFloat(lv)
+		nf, newNum := toFloat(nv)
+		_, liveBool := lv.(bool)
+		_, newBool := nv.(bool)
+		switch {
+		case liveNum && !newNum, liveBool && !newBool:
+			add(k, LevelError, "type mismatch: live %T, new %T", lv, nv)
+			continue
+		case !newNum:
+			continue
+		}
+		if math.IsNaN(nf) || math.IsInf(nf, 0) {
+			add(k, LevelError, "value must be finite")
+			continue
+		}
+		if sizeKeys[k] && nf < 0 {
+			add(k, LevelError, "must not be negative: %v", nv)
+		}
+		if limits.MaxChangePct > 0 && lf != 0 {
+			if pct := math.Abs(nf-lf) / math.Abs(lf) * 100; pct > limits.MaxChangePct {
+				add(k, LevelWarn, "changes by %.0f%% (%v → %v)", pct, lv, nv)
+			}
+		}
+	}
+
+	// 组合检查使用生效后的值
+	eff := func(k string) (float64, bool) {
+		if v, ok := proposed[k]; ok {
+			return toFloat(v)
+		}
+		return toFloat(live[k])
+	}
+	touched := func(ks ...string) bool {
+		for _, k := range ks {
+			if _, ok := proposed[k]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	sizeKey := pick(live, "order_size", "size")
+	maxKey := pick(live, "max_position_size", "max_size")
+	entryKey := pick(live, "entry_zscore", "begin_place")
+	exitKey := pick(live, "exit_zscore", "begin_remove")
+
+	size, hasSize := eff(sizeKey)
+	maxPos, hasMax := eff(maxKey)
+	if hasSize && hasMax && maxPos > 0 && size > maxPos && touched(sizeKey, maxKey) {
+		add(sizeKey, LevelError, "%s %v exceeds %s %v", sizeKey, size, maxKey, maxPos)
+	}
+	if entry, ok := eff(entryKey); ok && touched(entryKey, exitKey) {
+		if exit, ok := eff(exitKey); ok && entry <= exit {
+			add(entryKey, LevelError, "%s %v must be greater than %s %v", entryKey, entry, exitKey, exit)
+		}
+	}
+	if hasMax && limits.MaxPosition > 0 && maxPos > float64(limits.MaxPosition) && touched(maxKey) {
+		add(maxKey, LevelWarn, "%s %v exceeds risk limit %d", maxKey, maxPos, limits.MaxPosition)
+	}
+	if hasSize && limits.MaxOrderQty > 0 && size > float64(limits.MaxOrderQty) && touched(sizeKey) {
+		add(sizeKey, LevelWarn, "%s %v exceeds account max_order_qty %d (orders will be rejected)", sizeKey, size, limits.MaxOrderQty)
+	}
+	for k, limit := range map[string]float64{"stop_loss": limits.StopLoss, "max_loss": limits.MaxLoss} {
+		if v, ok := eff(k); ok && limit > 0 && v > limit && touched(k) {
+			add(k, LevelWarn, "%s %v exceeds risk limit %v", k, v, limit)
+		}
+	}
+	sort.SliceStable(hints, func(i, j int) bool { return hints[i].Key < hints[j].Key })
+	return hints
+}
+
+// pick 返回 live 中存在的第一个参数名
+func pick(live map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		if _, ok := live[k]; ok {
+			return k
+		}
+	}
+	return keys[0]
+}
+
+// sizeKeys 不允许为负的数量类参数
+var sizeKeys = map[string]bool{
+	"order_size":        true,
+	"max_position_size": true,
+	"lookback_period":   true,
+	"bid_size":          true,
+	"ask_size":          true,
+	"max_inventory":     true,
+	"stop_loss":         true,
+	"max_loss":          true,
+	"size":              true,
+	"max_size":          true,
+	"begin_size":        true,
+}
+
+// HasErrors 是否存在 error 级别提示
+func HasErrors(hints []Hint) bool {
+	for _, h := range hints {
+		if h.Level == LevelError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package paramset
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTarget 模拟策略管理器；reject 中的参数会被策略忽略
+type fakeTarget struct {
+	params map[string]map[string]interface{}
+	reject map[string]bool
+}
+
+func newFakeTarget() *fakeTarget {
+	return &fakeTarget{
+		params: map[string]map[string]interface{}{
+			"s1": {"entry_zscore": 2.0, "exit_zscore": 0.5, "order_size": int64(1), "max_position_size": int64(10)},
+			"s2": {"entry_zscore": 2.5, "exit_zscore": 0.5, "order_size": int64(2), "max_position_size": int64(10)},
+		},
+		reject: map[string]bool{},
+	}
+}
+
+func (f *fakeTarget) StrategyIDs() []string { return []string{"s1", "s2"} }
+
+func (f *fakeTarget) Parameters(id string) (map[string]interface{}, error) {
+	p, ok := f.params[id]
+	if !ok {
+		return nil, fmt.Errorf("strategy %s not found", id)
+	}
+	out := make(map[string]interface{}, len(p))
+	for k, v := range p {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (f *fakeTarget) Apply(id string, params map[string]interface{}) error {
+	for k, v := range params {
+		if f.reject[id+"."+k] {
+			continue
+		}
+		f.params[id][k] = v
+	}
+	return nil
+}
+
+func TestCheckHints(t *testing.T) {
+	live := map[string]interface{}{"entry_zscore": 2.0, "exit_zscore": 0.5, "order_size": 1, "max_position_size": 10}
+	hints := Check("s1", live, map[string]interface{}{
+		"max_position_size": 50.0,
+		"entry_zscore":      0.4,
+		"bogus":             1.0,
+	}, Limits{MaxPosition: 20, MaxChangePct: 100})
+
+	want := map[string]Level{"bogus": LevelError, "entry_zscore": LevelError, "max_position_size": LevelWarn}
+	got := make(map[string]Level)
+	for _, h := range hints {
+		if h.Level == LevelError || got[h.Key] == "" {
+			got[h.Key] = h.Level
+		}
+	}
+	for k, l := range want {
+		if got[k] != l {
+			t.Errorf("hint %s = %q, want %q (hints %+v)", k, got[k], l, hints)
+		}
+	}
+
+	d := Diff(live, map[string]interface{}{"order_size": 1.0, "max_position_size": 15.0})
+	if len(d) != 1 || d[0].Key != "max_position_size" || d[0].DeltaPct != 50 {
+		t.Errorf("Diff = %+v", d)
+	}
+}
+
+func TestWorkflowApplyAndRollback(t *testing.T) {
+	dir := t.TempDir()
+	target := newFakeTarget()
+	w, err := New(target, Options{Dir: dir, RequireApproval: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c1, err := w.Stage(StageRequest{Params: map[string]interface{}{"entry_zscore": 3.0}, Author: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c1.Diffs["s1"]) != 1 || len(c1.Diffs["s2"]) != 1 || HasErrors(c1.Hints) {
+		t.Fatalf("staged change = %+v", c1)
+	}
+	if _, err := w.Apply(c1.ID, "alice", time.Time{}); err == nil {
+		t.Fatal("apply without approval should fail")
+	}
+	if _, err := w.Approve(c1.ID, "alice"); err == nil {
+		t.Fatal("self-approval should fail")
+	}
+	if _, err := w.Approve(c1.ID, "rob"); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := w.Apply(c1.ID, "alice", time.Time{}); err != nil || c.Version != 1 {
+		t.Fatalf("apply = %+v, %v", c, err)
+	}
+
+	// 第二次变更定时生效
+	c2, _ := w.Stage(StageRequest{Strategies: []string{"s1"}, Params: map[string]interface{}{"order_size": 3}, Author: "alice"})
+	w.Approve(c2.ID, "rob")
+	if c, _ := w.Apply(c2.ID, "alice", time.Now().Add(time.Hour)); c.Status != StatusScheduled {
+		t.Fatalf("scheduled status = %s", c.Status)
+	}
+	if due := w.ApplyDue(time.Now()); len(due) != 0 {
+		t.Fatalf("not yet due: %+v", due)
+	}
+	if due := w.ApplyDue(time.Now().Add(2 * time.Hour)); len(due) != 1 || due[0].Version != 2 {
+		t.Fatalf("due = %+v", due)
+	}
+	if target.params["s1"]["order_size"] != 3.0 || target.params["s1"]["entry_zscore"] != 3.0 {
+		t.Fatalf("s1 = %+v", target.params["s1"])
+	}
+
+	// 重新打开：版本从磁盘加载，回滚到版本 0 恢复初始值
+	w, err = New(target, Options{Dir: dir, RequireApproval: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(w.Versions()) != 2 {
+		t.Fatalf("versions = %d", len(w.Versions()))
+	}
+	rb, err := w.Rollback(0, "rob", "")
+	if err != nil || rb.Version != 3 {
+		t.Fatalf("rollback = %+v, %v", rb, err)
+	}
+	if !Equal(target.params["s1"]["entry_zscore"], 2.0) || !Equal(target.params["s1"]["order_size"], 1) ||
+		!Equal(target.params["s2"]["entry_zscore"], 2.5) {
+		t.Errorf("after rollback: %+v", target.params)
+	}
+
+	// 重启后配置文件加载的旧值与最新版本不一致
+	target.params["s2"]["entry_zscore"] = 9.0
+	if drift := w.Drift(); len(drift["s2"]) != 1 {
+		t.Fatalf("drift = %+v", drift)
+	}
+	if _, err := w.Restore("system"); err != nil || !Equal(target.params["s2"]["entry_zscore"], 2.5) {
+		t.Errorf("restore: %v, s2 = %+v", err, target.params["s2"])
+	}
+}
+
+func TestWorkflowAtomicApply(t *testing.T) {
+	target := newFakeTarget()
+	target.reject["s2.order_size"] = true
+	w, err := New(target, Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := w.Stage(StageRequest{Params: map[string]interface{}{"order_size": 5}, Author: "alice"})
+	c, err = w.Apply(c.ID, "alice", time.Time{})
+	if err == nil || c.Status != StatusFailed || !strings.Contains(c.Error, "s2") {
+		t.Fatalf("apply = %+v, %v", c, err)
+	}
+	if !Equal(target.params["s1"]["order_size"], 1) {
+		t.Errorf("s1 not restored: %+v", target.params["s1"])
+	}
+	if len(w.Versions()) != 0 {
+		t.Errorf("failed change must not create a version")
+	}
+
+	// 校验失败的变更不会生效
+	bad, _ := w.Stage(StageRequest{Strategies: []string{"s1"}, Params: map[string]interface{}{"order_size": 50}, Author: "alice"})
+	if !HasErrors(bad.Hints) {
+		t.Fatalf("expected validation error: %+v", bad.Hints)
+	}
+	if _, err := w.Apply(bad.ID, "alice", time.Time{}); err == nil {
+		t.Error("apply with validation errors should fail")
+	}
+}
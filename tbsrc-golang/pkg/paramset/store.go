@@ -0,0 +1,166 @@
+package paramset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status 变更状态
+type Status string
+
+const (
+	StatusStaged    Status = "staged"    // 已提交，等待审批/生效
+	StatusApproved  Status = "approved"  // 已审批，等待生效
+	StatusScheduled Status = "scheduled" // 已安排在 ApplyAt 生效
+	StatusApplied   Status = "applied"
+	StatusFailed    Status = "failed" // 生效失败，已恢复原值
+	StatusCancelled Status = "cancelled"
+)
+
+// Kind 变更类型
+const (
+	KindUpdate   = "update"
+	KindRollback = "rollback"
+)
+
+// Change 一次参数变更（提交 → 审批 → 生效）
+type Change struct {
+	ID         string                            `json:"id"`
+	Kind       string                            `json:"kind"`
+	Status     Status                            `json:"status"`
+	Author     string                            `json:"author"`
+	Comment    string                            `json:"comment,omitempty"`
+	Source     string                            `json:"source,omitempty"` // api / model 文件路径
+	Created    time.Time                         `json:"created"`
+	Params     map[string]map[string]interface{} `json:"params"` // 策略 → 参数
+	Diffs      map[string][]ParamDiff            `json:"diffs"`
+	Hints      []Hint                            `json:"hints,omitempty"`
+	Approver   string                            `json:"approver,omitempty"`
+	ApprovedAt time.Time                         `json:"approved_at,omitempty"`
+	ApplyAt    time.Time                         `json:"apply_at,omitempty"` // 计划生效时间（零值 = 立即）
+	AppliedBy  string                            `json:"applied_by,omitempty"`
+	AppliedAt  time.Time                         `json:"applied_at,omitempty"`
+	Version    int                               `json:"version,omitempty"`     // 生效后生成的版本号
+	RollbackTo int                               `json:"rollback_to,omitempty"` // Kind=rollback 时的目标版本
+	Error      string                            `json:"error,omitempty"`
+}
+
+// Strategies 变更涉及的策略（排序）
+func (c *Change) Strategies() []string {
+	ids := make([]string, 0, len(c.Params))
+	for id := range c.Params {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Version 已生效的参数版本
+// Previous 记录生效前的值，用于回滚到任意更早的版本
+type Version struct {
+	ID       int                               `json:"id"`
+	Time     time.Time                         `json:"time"`
+	ChangeID string                            `json:"change_id"`
+	Kind     string                            `json:"kind"`
+	Author   string                            `json:"author"`
+	Approver string                            `json:"approver,omitempty"`
+	Comment  string                            `json:"comment,omitempty"`
+	Params   map[string]map[string]interface{} `json:"params"`
+	Previous map[string]map[string]interface{} `json:"previous"`
+}
+
+// store 变更与版本的磁盘存储
+// <dir>/changes/<id>.json，<dir>/versions/v<NNNNNN>.json；写入先写临时文件再 rename
+type store struct {
+	dir string
+}
+
+func (s *store) changePath(id string) string {
+	return filepath.Join(s.dir, "changes", id+".json")
+}
+
+func (s *store) versionPath(id int) string {
+	return filepath.Join(s.dir, "versions", fmt.Sprintf("v%06d.json", id))
+}
+
+func (s *store) saveChange(c *Change) error {
+	return writeJSON(s.changePath(c.ID), c)
+}
+
+func (s *store) saveVersion(v *Version) error {
+	return writeJSON(s.versionPath(v.ID), v)
+}
+
+// load 读取全部变更与版本（按 ID 排序）
+func (s *store) load() ([]*Change, []*Version, error) {
+	var changes []*Change
+	err := readDir(filepath.Join(s.dir, "changes"), func(data []byte) error {
+		var c Change
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		changes = append(changes, &c)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var versions []*Version
+	err = readDir(filepath.Join(s.dir, "versions"), func(data []byte) error {
+		var v Version
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		versions = append(versions, &v)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID < versions[j].ID })
+	return changes, versions, nil
+}
+
+func readDir(dir string, fn func([]byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return fmt.Errorf("%s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
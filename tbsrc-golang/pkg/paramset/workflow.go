@@ -0,0 +1,542 @@
+package paramset
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Target 参数变更的作用对象（策略管理器适配）
+type Target interface {
+	StrategyIDs() []string
+	Parameters(strategyID string) (map[string]interface{}, error)
+	Apply(strategyID string, params map[string]interface{}) error
+}
+
+// Options 工作流配置
+type Options struct {
+	Dir             string                         // 变更与版本存储目录
+	RequireApproval bool                           // 普通变更须审批后生效，且审批人不能是提交人（回滚不需要审批）
+	Limits          func(strategyID string) Limits // 风险提示使用的限额，可为 nil
+}
+
+// StageRequest 提交参数变更
+type StageRequest struct {
+	Strategies  []string                          // 作用的策略，空 = 全部
+	Params      map[string]interface{}            // 所有策略相同的参数
+	PerStrategy map[string]map[string]interface{} // 按策略覆盖 Params
+	Author      string
+	Comment     string
+	Source      string
+	// IgnoreUnknown 丢弃策略不支持的参数（model 文件包含所有策略类型的参数）
+	IgnoreUnknown bool
+}
+
+// Workflow 两阶段参数变更：Stage（预览 diff/校验/风险提示）→ Approve → Apply（可定时），
+// 生效的变更生成版本并持久化，可回滚到任意历史版本
+type Workflow struct {
+	mu       sync.Mutex
+	target   Target
+	opts     Options
+	store    store
+	changes  []*Change
+	byID     map[string]*Change
+	versions []*Version
+	nextID   int
+}
+
+// New 创建工作流并加载磁盘上的变更与版本（未到期的定时变更继续有效）
+func New(target Target, opts Options) (*Workflow, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("paramset: dir is required")
+	}
+	w := &Workflow{
+		target: target,
+		opts:   opts,
+		store:  store{dir: opts.Dir},
+		byID:   make(map[string]*Change),
+	}
+	changes, versions, err := w.store.load()
+	if err != nil {
+		return nil, fmt.Errorf("paramset: load %s: %w", opts.Dir, err)
+	}
+	for i, v := range versions {
+		if v.ID != i+1 {
+			return nil, fmt.Errorf("paramset: version %d missing in %s", i+1, opts.Dir)
+		}
+	}
+	w.versions = versions
+	for _, c := range changes {
+		w.changes = append(w.changes, c)
+		w.byID[c.ID] = c
+		var n int
+		if _, err := fmt.Sscanf(c.ID, "C%d", &n); err == nil && n > w.nextID {
+			w.nextID = n
+		}
+	}
+	return w, nil
+}
+
+// Stage 提交变更：计算与当前生效值的 diff、校验结果与风险提示，不修改策略
+func (w *Workflow) Stage(req StageRequest) (*Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ids := req.Strategies
+	if len(ids) == 0 {
+		ids = w.target.StrategyIDs()
+		if len(req.PerStrategy) > 0 && len(req.Params) == 0 {
+			ids = ids[:0]
+			for id := range req.PerStrategy {
+				ids = append(ids, id)
+			}
+		}
+	}
+	known := make(map[string]bool)
+	for _, id := range w.target.StrategyIDs() {
+		known[id] = true
+	}
+
+	params := make(map[string]map[string]interface{}, len(ids))
+	for _, id := range ids {
+		if !known[id] {
+			return nil, fmt.Errorf("strategy %s not found", id)
+		}
+		p := make(map[string]interface{}, len(req.Params))
+		for k, v := range req.Params {
+			p[k] = v
+		}
+		for k, v := range req.PerStrategy[id] {
+			p[k] = v
+		}
+		if req.IgnoreUnknown {
+			live, err := w.target.Parameters(id)
+			if err != nil {
+				return nil, err
+			}
+			for k := range p {
+				if _, ok := live[k]; !ok {
+					delete(p, k)
+				}
+			}
+		}
+		if len(p) > 0 {
+			params[id] = Normalize(p)
+		}
+	}
+	if len(params) == 0 {
+		return nil, fmt.Errorf("no parameters to stage")
+	}
+
+	c := &Change{
+		Kind:    KindUpdate,
+		Status:  StatusStaged,
+		Author:  req.Author,
+		Comment: req.Comment,
+		Source:  req.Source,
+		Created: time.Now(),
+		Params:  params,
+	}
+	if err := w.addLocked(c); err != nil {
+		return nil, err
+	}
+	return cloneChange(c), nil
+}
+
+// Approve 审批变更
+func (w *Workflow) Approve(id, approver string) (*Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	c, err := w.getLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != StatusStaged {
+		return nil, fmt.Errorf("change %s is %s, only staged changes can be approved", id, c.Status)
+	}
+	if w.opts.RequireApproval && approver == c.Author {
+		return nil, fmt.Errorf("change %s must be approved by someone other than its author %s", id, c.Author)
+	}
+	c.Status = StatusApproved
+	c.Approver = approver
+	c.ApprovedAt = time.Now()
+	if err := w.store.saveChange(c); err != nil {
+		return nil, err
+	}
+	log.Printf("[ParamSet] Change %s approved by %s", id, approver)
+	return cloneChange(c), nil
+}
+
+// Apply 生效变更；at 晚于当前时间时安排定时生效（由 ApplyDue 执行）
+// 所有策略要么全部生效，要么全部恢复原值
+func (w *Workflow) Apply(id, user string, at time.Time) (*Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	c, err := w.getLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Status {
+	case StatusStaged:
+		if w.opts.RequireApproval && c.Kind == KindUpdate {
+			return nil, fmt.Errorf("change %s requires approval before it can be applied", id)
+		}
+	case StatusApproved, StatusScheduled:
+	default:
+		return nil, fmt.Errorf("change %s is %s and cannot be applied", id, c.Status)
+	}
+
+	if !at.IsZero() && at.After(time.Now()) {
+		c.Status = StatusScheduled
+		c.ApplyAt = at
+		c.AppliedBy = user
+		if err := w.store.saveChange(c); err != nil {
+			return nil, err
+		}
+		log.Printf("[ParamSet] Change %s scheduled at %s by %s", id, at.Format(time.RFC3339), user)
+		return cloneChange(c), nil
+	}
+
+	err = w.applyLocked(c, user)
+	return cloneChange(c), err
+}
+
+// ApplyDue 执行已到期的定时变更，返回本次执行的变更
+func (w *Workflow) ApplyDue(now time.Time) []*Change {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var out []*Change
+	for _, c := range w.changes {
+		if c.Status != StatusScheduled || c.ApplyAt.After(now) {
+			continue
+		}
+		if err := w.applyLocked(c, c.AppliedBy); err != nil {
+			log.Printf("[ParamSet] Scheduled change %s failed: %v", c.ID, err)
+		}
+		out = append(out, cloneChange(c))
+	}
+	return out
+}
+
+// Cancel 取消未生效的变更
+func (w *Workflow) Cancel(id, user string) (*Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	c, err := w.getLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Status {
+	case StatusStaged, StatusApproved, StatusScheduled:
+	default:
+		return nil, fmt.Errorf("change %s is %s and cannot be cancelled", id, c.Status)
+	}
+	c.Status = StatusCancelled
+	c.Error = "cancelled by " + user
+	if err := w.store.saveChange(c); err != nil {
+		return nil, err
+	}
+	log.Printf("[ParamSet] Change %s cancelled by %s", id, user)
+	return cloneChange(c), nil
+}
+
+// Rollback 回滚到版本 to（0 = 工作流生效前的初始值）：恢复之后所有版本修改过的参数并立即生效
+func (w *Workflow) Rollback(to int, user, comment string) (*Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if to < 0 || to >= len(w.versions) {
+		return nil, fmt.Errorf("cannot roll back to version %d (latest is %d)", to, len(w.versions))
+	}
+	params := make(map[string]map[string]interface{})
+	for i := len(w.versions) - 1; i >= to; i-- {
+		for id, p := range w.versions[i].Previous {
+			if params[id] == nil {
+				params[id] = make(map[string]interface{})
+			}
+			for k, v := range p {
+				params[id][k] = v
+			}
+		}
+	}
+
+	if comment == "" {
+		comment = fmt.Sprintf("rollback to version %d", to)
+	}
+	c := &Change{
+		Kind:       KindRollback,
+		Status:     StatusStaged,
+		Author:     user,
+		Comment:    comment,
+		Source:     fmt.Sprintf("version %d", to),
+		Created:    time.Now(),
+		Params:     params,
+		RollbackTo: to,
+	}
+	if err := w.addLocked(c); err != nil {
+		return nil, err
+	}
+	err := w.applyLocked(c, user)
+	return cloneChange(c), err
+}
+
+// Drift 当前生效值与最新版本记录不一致的参数（例如重启后从配置文件加载了旧值）
+func (w *Workflow) Drift() map[string][]ParamDiff {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	out := make(map[string][]ParamDiff)
+	for id, p := range w.effectiveLocked() {
+		live, err := w.target.Parameters(id)
+		if err != nil {
+			continue
+		}
+		if d := Diff(live, p); len(d) > 0 {
+			out[id] = d
+		}
+	}
+	return out
+}
+
+// Restore 重新应用最新版本记录的参数（Drift 非空时使用），作为一次回滚记录
+func (w *Workflow) Restore(user string) (*Change, error) {
+	drift := w.Drift()
+	if len(drift) == 0 {
+		return nil, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	eff := w.effectiveLocked()
+	params := make(map[string]map[string]interface{}, len(drift))
+	for id, diffs := range drift {
+		params[id] = make(map[string]interface{}, len(diffs))
+		for _, d := range diffs {
+			params[id][d.Key] = eff[id][d.Key]
+		}
+	}
+	c := &Change{
+		Kind:       KindRollback,
+		Status:     StatusStaged,
+		Author:     user,
+		Comment:    fmt.Sprintf("restore version %d after restart", len(w.versions)),
+		Source:     fmt.Sprintf("version %d", len(w.versions)),
+		Created:    time.Now(),
+		Params:     params,
+		RollbackTo: len(w.versions),
+	}
+	if err := w.addLocked(c); err != nil {
+		return nil, err
+	}
+	err := w.applyLocked(c, user)
+	return cloneChange(c), err
+}
+
+// Changes 返回最近的 limit 条变更（0 = 全部），按提交顺序
+func (w *Workflow) Changes(limit int) []*Change {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	start := 0
+	if limit > 0 && len(w.changes) > limit {
+		start = len(w.changes) - limit
+	}
+	out := make([]*Change, 0, len(w.changes)-start)
+	for _, c := range w.changes[start:] {
+		out = append(out, cloneChange(c))
+	}
+	return out
+}
+
+// Change 按 ID 查询变更
+func (w *Workflow) Change(id string) (*Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c, err := w.getLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneChange(c), nil
+}
+
+// Versions 返回全部版本
+func (w *Workflow) Versions() []Version {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]Version, len(w.versions))
+	for i, v := range w.versions {
+		out[i] = *v
+	}
+	return out
+}
+
+// addLocked 分配 ID、计算预览并持久化
+func (w *Workflow) addLocked(c *Change) error {
+	w.nextID++
+	c.ID = fmt.Sprintf("C%06d", w.nextID)
+	w.previewLocked(c)
+	if err := w.store.saveChange(c); err != nil {
+		w.nextID--
+		return fmt.Errorf("save change: %w", err)
+	}
+	w.changes = append(w.changes, c)
+	w.byID[c.ID] = c
+	log.Printf("[ParamSet] Change %s staged by %s (%s, strategies=%v, hints=%d)",
+		c.ID, c.Author, c.Kind, c.Strategies(), len(c.Hints))
+	return nil
+}
+
+// previewLocked 计算 diff 与提示
+func (w *Workflow) previewLocked(c *Change) {
+	c.Diffs = make(map[string][]ParamDiff, len(c.Params))
+	c.Hints = nil
+	changed := false
+	for _, id := range c.Strategies() {
+		live, err := w.target.Parameters(id)
+		if err != nil {
+			c.Hints = append(c.Hints, Hint{Strategy: id, Level: LevelError, Message: err.Error()})
+			continue
+		}
+		c.Diffs[id] = Diff(live, c.Params[id])
+		changed = changed || len(c.Diffs[id]) > 0
+		var limits Limits
+		if w.opts.Limits != nil {
+			limits = w.opts.Limits(id)
+		}
+		c.Hints = append(c.Hints, Check(id, live, c.Params[id], limits)...)
+	}
+	if !changed {
+		c.Hints = append(c.Hints, Hint{Level: LevelInfo, Message: "no parameter differs from live values"})
+	}
+}
+
+// applyLocked 原子生效：逐个策略应用并回读校验，任一失败则把已修改的策略恢复原值
+func (w *Workflow) applyLocked(c *Change, user string) error {
+	w.previewLocked(c)
+	fail := func(err error) error {
+		c.Status = StatusFailed
+		c.Error = err.Error()
+		if serr := w.store.saveChange(c); serr != nil {
+			log.Printf("[ParamSet] Failed to save change %s: %v", c.ID, serr)
+		}
+		log.Printf("[ParamSet] ✗ Change %s failed: %v", c.ID, err)
+		return err
+	}
+	if HasErrors(c.Hints) {
+		return fail(fmt.Errorf("change %s has validation errors", c.ID))
+	}
+
+	ids := c.Strategies()
+	previous := make(map[string]map[string]interface{}, len(ids))
+	for _, id := range ids {
+		live, err := w.target.Parameters(id)
+		if err != nil {
+			return fail(err)
+		}
+		prev := make(map[string]interface{}, len(c.Params[id]))
+		for k := range c.Params[id] {
+			prev[k] = live[k]
+		}
+		previous[id] = Normalize(prev)
+	}
+
+	for i, id := range ids {
+		err := w.target.Apply(id, c.Params[id])
+		if err == nil {
+			err = w.verifyLocked(id, c.Params[id])
+		}
+		if err != nil {
+			for _, done := range ids[:i+1] {
+				if rerr := w.target.Apply(done, previous[done]); rerr != nil {
+					log.Printf("[ParamSet] ✗ Failed to restore strategy %s: %v", done, rerr)
+				}
+			}
+			return fail(fmt.Errorf("strategy %s: %w (all strategies restored)", id, err))
+		}
+	}
+
+	v := &Version{
+		ID:       len(w.versions) + 1,
+		Time:     time.Now(),
+		ChangeID: c.ID,
+		Kind:     c.Kind,
+		Author:   c.Author,
+		Approver: c.Approver,
+		Comment:  c.Comment,
+		Params:   c.Params,
+		Previous: previous,
+	}
+	w.versions = append(w.versions, v)
+	c.Status = StatusApplied
+	c.AppliedBy = user
+	c.AppliedAt = v.Time
+	c.Version = v.ID
+	c.Error = ""
+	log.Printf("[ParamSet] ✓ Change %s applied by %s as version %d (strategies=%v)", c.ID, user, v.ID, ids)
+
+	if err := w.store.saveVersion(v); err != nil {
+		c.Error = fmt.Sprintf("applied but version not persisted: %v", err)
+	}
+	if err := w.store.saveChange(c); err != nil {
+		return fmt.Errorf("applied but change not persisted: %w", err)
+	}
+	if c.Error != "" {
+		return fmt.Errorf("%s", c.Error)
+	}
+	return nil
+}
+
+// verifyLocked 回读参数，确认策略确实接受了新值
+func (w *Workflow) verifyLocked(id string, params map[string]interface{}) error {
+	live, err := w.target.Parameters(id)
+	if err != nil {
+		return err
+	}
+	var ignored []string
+	for k, v := range params {
+		if !Equal(live[k], v) {
+			ignored = append(ignored, k)
+		}
+	}
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		return fmt.Errorf("parameters not accepted: %v", ignored)
+	}
+	return nil
+}
+
+// effectiveLocked 按版本累积的最新参数值
+func (w *Workflow) effectiveLocked() map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{})
+	for _, v := range w.versions {
+		for id, p := range v.Params {
+			if out[id] == nil {
+				out[id] = make(map[string]interface{})
+			}
+			for k, val := range p {
+				out[id][k] = val
+			}
+		}
+	}
+	return out
+}
+
+func (w *Workflow) getLocked(id string) (*Change, error) {
+	c, ok := w.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("change %s not found", id)
+	}
+	return c, nil
+}
+
+// cloneChange 返回浅拷贝（Params/Diffs 生效后不再修改）
+func cloneChange(c *Change) *Change {
+	cp := *c
+	cp.Hints = append([]Hint(nil), c.Hints...)
+	return &cp
+}
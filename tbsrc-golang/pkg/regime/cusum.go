@@ -0,0 +1,137 @@
+package regime
+
+import "math"
+
+// CUSUM 双边累积和变点检测
+//
+//	z = (x - mean) / std（mean/std 为 EWMA 参考值）
+//	S+ = max(0, S+ + z - drift), S- = max(0, S- - z - drift)
+//
+// S+/S- 超过 thresh 时报警，清零并将参考均值锚定到当前值
+type CUSUM struct {
+	thresh float64 // h，以标准差计
+	drift  float64 // k，每次观测的容许漂移
+	period int     // 参考均值/方差的 EWMA 窗口（也是预热长度）
+	alpha  float64
+
+	mean  float64
+	vari  float64
+	count int
+
+	sPos       float64
+	sNeg       float64
+	lastChange int // +1 向上，-1 向下，0 尚无
+	barsSince  int // 距上次变点的观测数，-1 表示尚无
+	signal     int // 本次观测是否报警
+}
+
+// NewCUSUM 创建 CUSUM；thresh <= 0 取 5，drift < 0 取 0.5，period <= 0 取 100
+func NewCUSUM(thresh, drift float64, period int) *CUSUM {
+	if thresh <= 0 {
+		thresh = 5
+	}
+	if drift < 0 {
+		drift = 0.5
+	}
+	if period <= 0 {
+		period = 100
+	}
+	return &CUSUM{
+		thresh:    thresh,
+		drift:     drift,
+		period:    period,
+		alpha:     2.0 / float64(period+1),
+		barsSince: -1,
+	}
+}
+
+// Update 输入一次观测
+func (c *CUSUM) Update(x float64) {
+	c.count++
+	c.signal = 0
+
+	// 预热：累计均值/方差
+	if c.count <= c.period {
+		delta := x - c.mean
+		c.mean += delta / float64(c.count)
+		c.vari += (delta*(x-c.mean) - c.vari) / float64(c.count)
+		return
+	}
+
+	if c.barsSince >= 0 {
+		c.barsSince++
+	}
+
+	if std := math.Sqrt(c.vari); std > 0 {
+		z := (x - c.mean) / std
+		c.sPos = math.Max(0, c.sPos+z-c.drift)
+		c.sNeg = math.Max(0, c.sNeg-z-c.drift)
+		if c.sPos > c.thresh {
+			c.signal = 1
+		} else if c.sNeg > c.thresh {
+			c.signal = -1
+		}
+	}
+
+	if c.signal != 0 {
+		c.lastChange = c.signal
+		c.barsSince = 0
+		c.sPos = 0
+		c.sNeg = 0
+		c.mean = x // 锚定到新水平
+	} else {
+		delta := x - c.mean
+		c.mean += c.alpha * delta
+		c.vari = (1 - c.alpha) * (c.vari + c.alpha*delta*delta)
+	}
+}
+
+// Ready 预热窗口已满
+func (c *CUSUM) Ready() bool {
+	return c.count >= c.period
+}
+
+// Value (S+ - S-) / thresh，报警的那次观测为 ±1
+func (c *CUSUM) Value() float64 {
+	if c.signal != 0 {
+		return float64(c.signal)
+	}
+	return (c.sPos - c.sNeg) / c.thresh
+}
+
+// Signal 本次观测检测到变点时返回 +1/-1，否则 0
+func (c *CUSUM) Signal() int {
+	return c.signal
+}
+
+// LastChange 最近一次变点方向（尚无时为 0）
+func (c *CUSUM) LastChange() int {
+	return c.lastChange
+}
+
+// BarsSinceChange 距上次变点的观测数，尚无时为 -1
+func (c *CUSUM) BarsSinceChange() int {
+	return c.barsSince
+}
+
+// ChangedWithin 最近 n 次观测内是否检测到变点
+func (c *CUSUM) ChangedWithin(n int) bool {
+	return c.barsSince >= 0 && c.barsSince < n
+}
+
+// Sums 当前正负累积和
+func (c *CUSUM) Sums() (float64, float64) {
+	return c.sPos, c.sNeg
+}
+
+// Reset 状态清零，参数不变
+func (c *CUSUM) Reset() {
+	c.mean = 0
+	c.vari = 0
+	c.count = 0
+	c.sPos = 0
+	c.sNeg = 0
+	c.lastChange = 0
+	c.barsSince = -1
+	c.signal = 0
+}
@@ -0,0 +1,177 @@
+// Package regime 市场状态识别算法（Go 扩展）
+// 波动率 HMM、方差比/Hurst、CUSUM 变点检测，输入为任意水平序列（价格、价差）。
+// tbsrc PairwiseArb 的 RegimeDetector 与 golang/pkg/indicators 的
+// VolatilityRegime / VarianceRatio / CUSUM 指标共用这里的实现
+package regime
+
+import "math"
+
+// VolHMM 波动率隐马尔可夫前向滤波
+// 状态 j 的增量 dx ~ N(0, mult[j]*baseVar)
+//
+//	2 状态: [1, highMult]
+//	3 状态: [lowMult, 1, highMult]
+//
+// 转移矩阵: 保持概率 stayProb，其余均分。每次观测做一步前向滤波，后验 P(state | history) 为 O(states)。
+// baseVar 为 dx² 的 EWMA，按 (1 - P(high)) 衰减更新，避免高波动期抬高基准
+type VolHMM struct {
+	numStates int
+	period    int     // baseVar 的 EWMA 窗口（也是预热长度）
+	stayProb  float64 // P(s_t = s_{t-1})
+	mult      [3]float64
+
+	alpha   float64 // EWMA 系数 = 2/(period+1)
+	baseVar float64 // 增量的长期方差
+	prob    [3]float64
+
+	lastX   float64
+	hasLast bool
+	count   int
+	state   int
+}
+
+// NewVolHMM 创建波动率 HMM；参数越界时取默认值（2 状态、100、0.98、0.4、4）
+func NewVolHMM(numStates, period int, stayProb, lowMult, highMult float64) *VolHMM {
+	if numStates != 3 {
+		numStates = 2
+	}
+	if period <= 0 {
+		period = 100
+	}
+	if stayProb <= 0 || stayProb >= 1 {
+		stayProb = 0.98
+	}
+	if lowMult <= 0 || lowMult >= 1 {
+		lowMult = 0.4
+	}
+	if highMult <= 1 {
+		highMult = 4
+	}
+	v := &VolHMM{
+		numStates: numStates,
+		period:    period,
+		stayProb:  stayProb,
+		alpha:     2.0 / float64(period+1),
+	}
+	if numStates == 3 {
+		v.mult = [3]float64{lowMult, 1, highMult}
+	} else {
+		v.mult = [3]float64{1, highMult, 0}
+	}
+	v.resetProb()
+	return v
+}
+
+// Update 输入一次水平观测；返回 false 表示首个观测（尚无增量）
+func (v *VolHMM) Update(x float64) bool {
+	if !v.hasLast {
+		v.lastX = x
+		v.hasLast = true
+		return false
+	}
+	dx := x - v.lastX
+	v.lastX = x
+	sq := dx * dx
+	v.count++
+
+	// 预热：简单平均
+	if v.count <= v.period {
+		v.baseVar += (sq - v.baseVar) / float64(v.count)
+		return true
+	}
+
+	if v.baseVar > 0 {
+		v.forward(sq)
+	}
+	// 先滤波再更新 baseVar，冲击按旧基准判断
+	v.baseVar += v.alpha * (1 - v.HighProb()) * (sq - v.baseVar)
+	return true
+}
+
+// forward 一步前向滤波，发射方差 mult[j]*baseVar
+func (v *VolHMM) forward(sq float64) {
+	k := v.numStates
+	switchProb := (1 - v.stayProb) / float64(k-1)
+
+	var logLik [3]float64
+	maxLog := math.Inf(-1)
+	for j := 0; j < k; j++ {
+		vv := v.mult[j] * v.baseVar
+		// log N(dx; 0, vv)，省略常数项
+		logLik[j] = -0.5 * (sq/vv + math.Log(vv))
+		if logLik[j] > maxLog {
+			maxLog = logLik[j]
+		}
+	}
+
+	var next [3]float64
+	sum := 0.0
+	for j := 0; j < k; j++ {
+		prior := v.stayProb*v.prob[j] + switchProb*(1-v.prob[j])
+		next[j] = prior * math.Exp(logLik[j]-maxLog)
+		sum += next[j]
+	}
+	if sum <= 0 || math.IsNaN(sum) {
+		v.resetProb()
+		return
+	}
+
+	best := 0
+	for j := 0; j < k; j++ {
+		v.prob[j] = next[j] / sum
+		if v.prob[j] > v.prob[best] {
+			best = j
+		}
+	}
+	v.state = best
+}
+
+func (v *VolHMM) resetProb() {
+	v.prob = [3]float64{}
+	for j := 0; j < v.numStates; j++ {
+		v.prob[j] = 1.0 / float64(v.numStates)
+	}
+	v.state = 0
+}
+
+// Ready 预热窗口已满
+func (v *VolHMM) Ready() bool {
+	return v.count >= v.period
+}
+
+// HighProb 最高波动状态的后验概率
+func (v *VolHMM) HighProb() float64 {
+	return v.prob[v.numStates-1]
+}
+
+// State 最可能的状态（0 = 最低波动，NumStates()-1 = 最高波动）
+func (v *VolHMM) State() int {
+	return v.state
+}
+
+// NumStates 隐状态数（2 或 3）
+func (v *VolHMM) NumStates() int {
+	return v.numStates
+}
+
+// StateProb 状态 j 的后验概率
+func (v *VolHMM) StateProb(j int) float64 {
+	if j < 0 || j >= v.numStates {
+		return 0
+	}
+	return v.prob[j]
+}
+
+// BaseVariance 增量的长期方差
+func (v *VolHMM) BaseVariance() float64 {
+	return v.baseVar
+}
+
+// Reset 状态清零，参数不变
+func (v *VolHMM) Reset() {
+	v.baseVar = 0
+	v.lastX = 0
+	v.hasLast = false
+	v.count = 0
+	v.resetProb()
+}
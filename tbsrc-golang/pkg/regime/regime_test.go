@@ -0,0 +1,94 @@
+package regime
+
+import (
+	"math"
+	"testing"
+)
+
+// noise 确定性近似正态噪声（xorshift32，12 个均匀分布求和）
+func noise(n int, seed uint32) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		s := 0.0
+		for j := 0; j < 12; j++ {
+			seed ^= seed << 13
+			seed ^= seed >> 17
+			seed ^= seed << 5
+			s += float64(seed) / float64(math.MaxUint32)
+		}
+		out[i] = s - 6
+	}
+	return out
+}
+
+func TestVolHMM_Burst(t *testing.T) {
+	v := NewVolHMM(3, 50, 0, 0, 0)
+	e := noise(260, 21)
+	if v.Update(10) {
+		t.Fatal("first observation has no increment")
+	}
+	for i := 0; i < 200; i++ {
+		v.Update(10 + 0.5*e[i])
+	}
+	if !v.Ready() || v.HighProb() > 0.5 {
+		t.Fatalf("calm: ready = %v P(high) = %.3f", v.Ready(), v.HighProb())
+	}
+	for i := 200; i < 230; i++ {
+		v.Update(10 + 5*e[i])
+	}
+	if v.HighProb() < 0.9 || v.State() != 2 {
+		t.Errorf("burst: P(high) = %.3f state = %d", v.HighProb(), v.State())
+	}
+	v.Reset()
+	if v.Ready() || math.Abs(v.StateProb(0)-1.0/3) > 1e-12 {
+		t.Errorf("reset: ready = %v P(0) = %.3f", v.Ready(), v.StateProb(0))
+	}
+}
+
+func TestVarianceRatio_MeanRevertingAndTrending(t *testing.T) {
+	e := noise(400, 9)
+	mr := NewVarianceRatio(100, 5)
+	x := 0.0
+	for i := 0; i < 200; i++ {
+		x = 0.3*x + e[i]
+		mr.Update(x)
+	}
+	if !mr.Ready() || mr.Hurst() >= 0.5 || mr.Ratio() >= 1 || mr.ZStat() >= 0 {
+		t.Errorf("AR(1): H = %.3f VR = %.3f z = %.2f", mr.Hurst(), mr.Ratio(), mr.ZStat())
+	}
+
+	tr := NewVarianceRatio(100, 5)
+	d := 0.0
+	for i := 200; i < 400; i++ {
+		d = 0.6*d + e[i]
+		x += d
+		tr.Update(x)
+	}
+	if tr.Hurst() <= 0.6 || tr.Ratio() <= 1 {
+		t.Errorf("trending: H = %.3f VR = %.3f", tr.Hurst(), tr.Ratio())
+	}
+}
+
+func TestCUSUM_MeanShift(t *testing.T) {
+	c := NewCUSUM(5, 0.5, 50)
+	e := noise(120, 29)
+	for i := 0; i < 100; i++ {
+		c.Update(e[i])
+	}
+	if c.LastChange() != 0 || c.BarsSinceChange() != -1 {
+		t.Fatalf("stationary: lastChange = %d barsSince = %d", c.LastChange(), c.BarsSinceChange())
+	}
+	alarmed := false
+	for i := 100; i < 110; i++ {
+		c.Update(4 + e[i])
+		if c.Signal() == 1 {
+			alarmed = true
+			if c.Value() != 1 {
+				t.Errorf("alarm value = %v, want 1", c.Value())
+			}
+		}
+	}
+	if !alarmed || c.LastChange() != 1 || !c.ChangedWithin(10) {
+		t.Errorf("shift: alarmed = %v lastChange = %d barsSince = %d", alarmed, c.LastChange(), c.BarsSinceChange())
+	}
+}
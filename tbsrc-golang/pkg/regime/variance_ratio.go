@@ -0,0 +1,122 @@
+package regime
+
+import "math"
+
+// VarianceRatio 滚动窗口 Lo-MacKinlay 方差比检验
+//
+//	VR(q) = Var(x_t - x_{t-q}) / (q * Var(x_t - x_{t-1}))
+//	H = 0.5 * (1 + ln(VR) / ln(q))
+//
+// 随机游走 VR = 1；VR < 1（H < 0.5）均值回归，VR > 1（H > 0.5）趋势
+type VarianceRatio struct {
+	period int // 水平序列窗口
+	lag    int // q
+	levels []float64
+
+	ratio float64
+	hurst float64
+	zStat float64
+}
+
+// NewVarianceRatio 创建方差比检验；lag < 2 取 5，period <= 2*lag 取 20*lag
+func NewVarianceRatio(period, lag int) *VarianceRatio {
+	if lag < 2 {
+		lag = 5
+	}
+	if period <= lag*2 {
+		period = lag * 20
+	}
+	return &VarianceRatio{
+		period: period,
+		lag:    lag,
+		levels: make([]float64, 0, period+1),
+		ratio:  1,
+		hurst:  0.5,
+	}
+}
+
+// Update 输入一次水平观测；返回 true 表示窗口已满并重新计算
+func (v *VarianceRatio) Update(x float64) bool {
+	v.levels = append(v.levels, x)
+	if len(v.levels) > v.period {
+		// 原地左移，避免重新分配
+		n := copy(v.levels, v.levels[1:])
+		v.levels = v.levels[:n]
+	}
+	if !v.Ready() {
+		return false
+	}
+	v.calculate()
+	return true
+}
+
+func (v *VarianceRatio) calculate() {
+	x := v.levels
+	n := len(x) - 1 // 一步增量个数
+	q := v.lag
+
+	// 增量漂移
+	mu := (x[n] - x[0]) / float64(n)
+
+	var1 := 0.0
+	for i := 1; i <= n; i++ {
+		d := x[i] - x[i-1] - mu
+		var1 += d * d
+	}
+	var1 /= float64(n)
+
+	varq := 0.0
+	for i := q; i <= n; i++ {
+		d := x[i] - x[i-q] - float64(q)*mu
+		varq += d * d
+	}
+	varq /= float64(n - q + 1)
+
+	if var1 <= 0 {
+		v.ratio = 1
+		v.hurst = 0.5
+		v.zStat = 0
+		return
+	}
+
+	v.ratio = varq / (float64(q) * var1)
+
+	// Lo-MacKinlay 同方差标准误
+	se := math.Sqrt(2 * float64(2*q-1) * float64(q-1) / (3 * float64(q) * float64(n)))
+	v.zStat = (v.ratio - 1) / se
+
+	if v.ratio > 0 {
+		v.hurst = 0.5 * (1 + math.Log(v.ratio)/math.Log(float64(q)))
+	} else {
+		v.hurst = 0
+	}
+	v.hurst = math.Max(0, math.Min(1, v.hurst))
+}
+
+// Ready 窗口已满
+func (v *VarianceRatio) Ready() bool {
+	return len(v.levels) >= v.period
+}
+
+// Ratio 方差比 VR(q)
+func (v *VarianceRatio) Ratio() float64 {
+	return v.ratio
+}
+
+// Hurst Hurst 指数估计，范围 [0, 1]
+func (v *VarianceRatio) Hurst() float64 {
+	return v.hurst
+}
+
+// ZStat VR - 1 的 Lo-MacKinlay z 统计量（z < -1.96 在 5% 水平拒绝随机游走，支持均值回归）
+func (v *VarianceRatio) ZStat() float64 {
+	return v.zStat
+}
+
+// Reset 状态清零，参数不变
+func (v *VarianceRatio) Reset() {
+	v.levels = v.levels[:0]
+	v.ratio = 1
+	v.hurst = 0.5
+	v.zStat = 0
+}
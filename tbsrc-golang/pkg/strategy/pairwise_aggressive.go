@@ -4,6 +4,7 @@ import (
 	"log"
 	"time"
 
+	"tbsrc-golang/pkg/execution"
 	"tbsrc-golang/pkg/shm"
 	"tbsrc-golang/pkg/types"
 )
@@ -21,89 +22,92 @@ import (
 //     - retry 1-2: 价格偏移 tickSize * repeat
 //     - retry 3: 价格偏移 tickSize * SLOP (大跳)
 //     - retry >3: HandleSquareoff() 平仓
+//
+// Go 扩展: 价格阶梯由 execution.HedgePrice 计算（与 SpreadExecutor 共用），
+// HEDGE_MAX_SLIP > 0 时不超过本轮首单 BBO ± N tick；LEG_TIMEOUT > 0 时敞口超时未补齐则平仓。
+// 两者默认 0，与 C++ 行为一致
 func (pas *PairwiseArbStrategy) SendAggressiveOrder() {
 	inst2 := pas.Inst2
 	thold2 := pas.Thold2
 
-	pendingAgg := pas.CalcPendingNetposAgg()
-	netExposure := pas.Leg1.State.NetposPass + pas.Leg2.State.NetposAgg + pendingAgg
+	netExposure := pas.netExposure()
 
 	// C++: 获取当前时间（毫秒）
-	nowMS := uint64(time.Now().UnixMilli())
+	now := time.Now()
+	nowMS := uint64(now.UnixMilli())
+
+	if pas.legTimedOut(netExposure, uint64(now.UnixNano())) {
+		log.Printf("[PairwiseArb] leg2 unhedged for more than %dms (exposure=%d), squareoff",
+			thold2.LegTimeout, netExposure)
+		pas.handleSquareoffLocked()
+		return
+	}
 
 	if netExposure > 0 && pas.SellAggOrder <= thold2.SupportingOrders {
 		// C++: NET LONG — 需要在 leg2 卖出
-		qty := netExposure
-
-		if pas.LastAggSide != types.Sell ||
-			(pas.LastAggSide == types.Sell && nowMS-pas.LastAggTS > 500) {
-			// C++: 首次或 >500ms — 以 bidPx[0] 卖出（吃单）
-			pas.Leg2.SendAskOrder2(shm.NEWORDER, 0, inst2.BidPx[0], types.HitCross, qty, 0, 0)
-			pas.SellAggOrder++
-			pas.LastAggTS = nowMS
-			pas.LastAggSide = types.Sell
-		} else {
-			// C++: 重试阶梯
-			if pas.AggRepeat > 3 {
-				// C++: 超过最大重试次数 → 平仓
-				log.Printf("[PairwiseArb] aggressive retry exceeded (repeat=%d), squareoff", pas.AggRepeat)
-				pas.handleSquareoffLocked()
-			} else {
-				// C++: 计算激进价格
-				var aggPrice float64
-				tickSize := inst2.TickSize
-				if pas.AggRepeat < 3 {
-					// C++: bidPx[0] - tickSize * repeat
-					aggPrice = inst2.BidPx[0] - tickSize*float64(pas.AggRepeat)
-				} else {
-					// C++: bidPx[0] - tickSize * SLOP
-					aggPrice = inst2.BidPx[0] - tickSize*float64(thold2.Slop)
-				}
-				ok := pas.Leg2.SendAskOrder2(shm.NEWORDER, 0, aggPrice, types.HitCross, qty, 0, 0)
-				if ok {
-					pas.AggRepeat++
-					pas.SellAggOrder++
-					pas.LastAggTS = nowMS
-					pas.LastAggSide = types.Sell
-				}
-			}
-		}
+		pas.sendAggressiveLocked(types.Sell, inst2.BidPx[0], netExposure, nowMS)
 	} else if netExposure < 0 && pas.BuyAggOrder <= thold2.SupportingOrders {
 		// C++: NET SHORT — 需要在 leg2 买入
-		qty := -netExposure
+		pas.sendAggressiveLocked(types.Buy, inst2.AskPx[0], -netExposure, nowMS)
+	}
+}
 
-		if pas.LastAggSide != types.Buy ||
-			(pas.LastAggSide == types.Buy && nowMS-pas.LastAggTS > 500) {
-			// C++: 首次或 >500ms — 以 askPx[0] 买入（吃单）
-			pas.Leg2.SendBidOrder2(shm.NEWORDER, 0, inst2.AskPx[0], types.HitCross, qty, 0, 0)
-			pas.BuyAggOrder++
-			pas.LastAggTS = nowMS
-			pas.LastAggSide = types.Buy
-		} else {
-			// C++: 重试阶梯
-			if pas.AggRepeat > 3 {
-				log.Printf("[PairwiseArb] aggressive retry exceeded (repeat=%d), squareoff", pas.AggRepeat)
-				pas.handleSquareoffLocked()
-			} else {
-				var aggPrice float64
-				tickSize := inst2.TickSize
-				if pas.AggRepeat < 3 {
-					// C++: askPx[0] + tickSize * repeat
-					aggPrice = inst2.AskPx[0] + tickSize*float64(pas.AggRepeat)
-				} else {
-					// C++: askPx[0] + tickSize * SLOP
-					aggPrice = inst2.AskPx[0] + tickSize*float64(thold2.Slop)
-				}
-				ok := pas.Leg2.SendBidOrder2(shm.NEWORDER, 0, aggPrice, types.HitCross, qty, 0, 0)
-				if ok {
-					pas.AggRepeat++
-					pas.BuyAggOrder++
-					pas.LastAggTS = nowMS
-					pas.LastAggSide = types.Buy
-				}
-			}
-		}
+// sendAggressiveLocked 按阶梯在 leg2 发一笔主动单，bbo 为对手方最优价
+// 参考: PairwiseArbStrategy.cpp:701-800
+func (pas *PairwiseArbStrategy) sendAggressiveLocked(side types.TransactionType, bbo float64, qty int32, nowMS uint64) {
+	// C++: 首次或 >500ms — 以对价下单（吃单）；否则进入重试阶梯
+	first := pas.LastAggSide != side || nowMS-pas.LastAggTS > 500
+	if !first && pas.AggRepeat > 3 {
+		// C++: 超过最大重试次数 → 平仓
+		log.Printf("[PairwiseArb] aggressive retry exceeded (repeat=%d), squareoff", pas.AggRepeat)
+		pas.handleSquareoffLocked()
+		return
+	}
+	if pas.HedgeRefPx == 0 {
+		pas.HedgeRefPx = bbo
+	}
+	price := execution.HedgePrice(side, bbo, pas.Inst2.TickSize, first, int32(pas.AggRepeat),
+		pas.Thold2.Slop, pas.HedgeRefPx, pas.Thold2.HedgeMaxSlip)
+
+	var ok bool
+	if side == types.Buy {
+		ok = pas.Leg2.SendBidOrder2(shm.NEWORDER, 0, price, types.HitCross, qty, 0, 0)
+	} else {
+		ok = pas.Leg2.SendAskOrder2(shm.NEWORDER, 0, price, types.HitCross, qty, 0, 0)
+	}
+	// C++: 首单不检查发送结果，重试单仅成功时推进阶梯
+	if !ok && !first {
+		return
+	}
+	if !first {
+		pas.AggRepeat++
+	}
+	if side == types.Buy {
+		pas.BuyAggOrder++
+	} else {
+		pas.SellAggOrder++
+	}
+	pas.LastAggTS = nowMS
+	pas.LastAggSide = side
+}
+
+// netExposure leg2 未对冲的净敞口: netpos_pass + netpos_agg + pendingNetposAgg
+func (pas *PairwiseArbStrategy) netExposure() int32 {
+	return pas.Leg1.State.NetposPass + pas.Leg2.State.NetposAgg + pas.CalcPendingNetposAgg()
+}
+
+// legTimedOut 更新瘸腿计时（Go 扩展），敞口持续超过 LEG_TIMEOUT 时返回 true
+// 敞口归零时结束本轮对冲，清除 HEDGE_MAX_SLIP 基准价
+func (pas *PairwiseArbStrategy) legTimedOut(exposure int32, nowNs uint64) bool {
+	if exposure == 0 {
+		pas.LegImbalanceTS = 0
+		pas.HedgeRefPx = 0
+		return false
+	}
+	if pas.LegImbalanceTS == 0 {
+		pas.LegImbalanceTS = nowNs
 	}
+	return execution.LegTimedOut(pas.LegImbalanceTS, nowNs, pas.Thold2.LegTimeout)
 }
 
 // CalcPendingNetposAgg 计算 leg2 待成交的净持仓
@@ -158,6 +158,66 @@ func TestSendAggressiveOrder_RetryExceeded_Squareoff(t *testing.T) {
 	}
 }
 
+func TestSendAggressiveOrder_HedgeMaxSlip(t *testing.T) {
+	pas := newTestPAS()
+	pas.Leg1.State.NetposPass = 3
+	pas.AggRepeat = 3
+	pas.LastAggSide = types.Sell
+	pas.LastAggTS = uint64(time.Now().UnixMilli())
+	pas.Thold2.Slop = 20
+	pas.Thold2.HedgeMaxSlip = 5
+	pas.HedgeRefPx = 5802 // 本轮首单时的 bid
+
+	pas.SendAggressiveOrder()
+
+	// SLOP 价 5780 超出 5802 - 5 tick，限制在 5797
+	for _, ord := range pas.Leg2.Orders.OrdMap {
+		if ord.Side == types.Sell && ord.Price != 5797 {
+			t.Errorf("capped sell price = %f, want 5797", ord.Price)
+		}
+	}
+	if len(pas.Leg2.Orders.OrdMap) != 1 {
+		t.Errorf("expected 1 hedge order, got %d", len(pas.Leg2.Orders.OrdMap))
+	}
+}
+
+func TestSendAggressiveOrder_HedgeRefPxLifecycle(t *testing.T) {
+	pas := newTestPAS()
+	pas.Leg1.State.NetposPass = 3
+
+	pas.SendAggressiveOrder()
+	if pas.HedgeRefPx != 5800 {
+		t.Errorf("HedgeRefPx = %f, want 5800 (first hedge bid)", pas.HedgeRefPx)
+	}
+	if pas.LegImbalanceTS == 0 {
+		t.Error("LegImbalanceTS should be set while exposed")
+	}
+
+	// 对冲完成: 敞口归零后结束本轮
+	pas.Leg2.Orders.OrdMap = make(map[uint32]*types.OrderStats)
+	pas.Leg2.State.NetposAgg = -3
+	pas.SendAggressiveOrder()
+	if pas.HedgeRefPx != 0 || pas.LegImbalanceTS != 0 {
+		t.Errorf("hedge round not settled: ref=%f ts=%d", pas.HedgeRefPx, pas.LegImbalanceTS)
+	}
+}
+
+func TestSendAggressiveOrder_LegTimeout_Squareoff(t *testing.T) {
+	pas := newTestPAS()
+	pas.Leg1.State.NetposPass = 3
+	pas.Thold2.LegTimeout = 1000
+	pas.LegImbalanceTS = uint64(time.Now().Add(-2 * time.Second).UnixNano())
+
+	pas.SendAggressiveOrder()
+
+	if pas.Active {
+		t.Error("should be deactivated after LEG_TIMEOUT")
+	}
+	if len(pas.Leg2.Orders.OrdMap) != 0 {
+		t.Errorf("no hedge order expected after timeout, got %d", len(pas.Leg2.Orders.OrdMap))
+	}
+}
+
 func TestSendAggressiveOrder_PendingAggIncluded(t *testing.T) {
 	pas := newTestPAS()
 	pas.Leg1.State.NetposPass = 3
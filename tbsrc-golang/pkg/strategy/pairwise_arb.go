@@ -4,6 +4,8 @@ import (
 	"log"
 	"sync"
 
+	"tbsrc-golang/pkg/attribution"
+	"tbsrc-golang/pkg/auction"
 	"tbsrc-golang/pkg/client"
 	"tbsrc-golang/pkg/config"
 	"tbsrc-golang/pkg/execution"
@@ -24,6 +26,12 @@ type PairwiseArbStrategy struct {
 	// 价差跟踪
 	Spread *SpreadTracker
 
+	// 价差定义（两腿 1:-1，SPREAD_TYPE），用于按一个对象计算货值敞口
+	Def *SpreadDef
+
+	// 市场状态识别（USE_REGIME_THOLD），nil 表示未启用
+	Regime *RegimeDetector
+
 	// 合约信息
 	Inst1 *instrument.Instrument // C++: m_firstinstru
 	Inst2 *instrument.Instrument // C++: m_secondinstru
@@ -48,16 +56,31 @@ type PairwiseArbStrategy struct {
 	LastAggTS   uint64                 // C++: nanoseconds timestamp of last agg order
 	BuyAggOrder  int32                 // C++: m_secondStrat->buyAggOrder
 	SellAggOrder int32                 // C++: m_secondStrat->sellAggOrder
+	HedgeRefPx     float64             // 本轮对冲首单 BBO（HEDGE_MAX_SLIP 基准，Go 扩展），0 表示已配齐
+	LegImbalanceTS uint64              // leg2 出现未对冲敞口的时间（纳秒，LEG_TIMEOUT，Go 扩展）
 
 	// tvar SHM — 外部调整值
 	TVar *shm.TVar // C++: m_tvar — 如果为 nil 则不使用
 
+	// 逐笔 P&L 归因（Go 扩展），nil 表示不记录
+	Attribution *attribution.Engine
+
+	// 集合竞价（Go 扩展，见 pairwise_auction.go）
+	AuctionPhase AuctionPhaseFunc // 交易日历竞价时段判断，nil 时只按指示性盘口交叉识别
+	Auction1     auction.Result   // 第一腿最近一次指示性撮合结果
+	Auction2     auction.Result   // 第二腿最近一次指示性撮合结果
+	InAuction    bool             // 当前处于集合竞价
+	AuctionSent  bool             // 本次竞价已报单
+
 	// 监控
 	LastMonitorTS uint64
 
 	// daily_init 文件路径（用于 HandleSquareoff 时保存状态）
 	DailyInitPath string
 
+	// 最近一次交易日切换的交易日（YYYYMMDD），防止重复切换
+	LastRolloverDay string
+
 	// mu 保护所有策略状态，防止 pollMD 和 pollORS 两个 goroutine 并发修改
 	// C++ 中 SHM 回调在同一线程中序列化，Go 需要显式加锁
 	mu sync.Mutex
@@ -78,6 +101,19 @@ func NewPairwiseArbStrategy(
 
 	// 创建价差跟踪器
 	spread := NewSpreadTracker(thold1.Alpha, inst1.TickSize, int32(thold1.AvgSpreadAway))
+	def, err := NewPairSpreadDef(thold1.SpreadType, inst1, inst2)
+	if err != nil {
+		log.Printf("[PairwiseArb] invalid SPREAD_TYPE %d: %v, fallback to diff", thold1.SpreadType, err)
+		def, _ = NewPairSpreadDef(SpreadTypeDiff, inst1, inst2)
+	}
+	spread.Type = def.Type
+	if thold1.SpreadMode == SpreadModeKalman {
+		if def.Type == SpreadTypeDiff {
+			spread.EnableKalman(thold1.KalmanQAlpha, thold1.KalmanQBeta, thold1.KalmanR)
+		} else {
+			log.Printf("[PairwiseArb] SPREAD_MODE kalman requires SPREAD_TYPE 0, ignored")
+		}
+	}
 
 	maxQuoteLevel := int32(3) // C++ default
 	if thold1.MaxQuoteLevel > 0 {
@@ -88,6 +124,7 @@ func NewPairwiseArbStrategy(
 		Leg1:          leg1,
 		Leg2:          leg2,
 		Spread:        spread,
+		Def:           def,
 		Inst1:         inst1,
 		Inst2:         inst2,
 		Thold1:        thold1,
@@ -99,15 +136,34 @@ func NewPairwiseArbStrategy(
 		AggRepeat:     1,
 	}
 
+	if thold1.UseRegimeThold {
+		pas.Regime = NewRegimeDetector(thold1)
+	}
+
 	// C++: ORS 回调需要先经过 PairwiseArbStrategy.ORSCallBack
 	// （handleAggOrder + SendAggressiveOrder），再委托给各腿处理。
 	// 设置 override 使 client.orderIDMap → LegManager → PairwiseArbStrategy
 	leg1.ORSCallbackOverride = pas
 	leg2.ORSCallbackOverride = pas
 
+	// SMART_QUOTE 改善一档须仍满足 leg1 挂单门槛
+	leg1.QuoteImproveOK = pas.quoteImproveOK
+
 	return pas
 }
 
+// quoteImproveOK 改善后的 leg1 报价是否仍满足 SendOrder 的挂单门槛
+// bid: Combine(price, leg2.bid[0]) < avgSpread - tholdBidPlace
+// ask: Combine(price, leg2.ask[0]) > avgSpread + tholdAskPlace
+func (pas *PairwiseArbStrategy) quoteImproveOK(side types.TransactionType, price float64) bool {
+	state := pas.Leg1.State
+	avg := pas.Spread.AvgSpread
+	if side == types.Buy {
+		return pas.Spread.Combine(price, pas.Inst2.BidPx[0]) < avg-state.TholdBidPlace
+	}
+	return pas.Spread.Combine(price, pas.Inst2.AskPx[0]) > avg+state.TholdAskPlace
+}
+
 // Init 从 daily_init 文件初始化
 // C++ 从 ../data/daily_init.<strategyID> 加载:
 //   avgSpreadRatio_ori, netpos_ytd1, netpos_2day1, netpos_agg2
@@ -152,6 +208,16 @@ func (pas *PairwiseArbStrategy) NetExposure() int32 {
 	return pas.Leg1.State.NetposPass + pas.Leg2.State.NetposAgg + pending
 }
 
+// ExposureValue 把两腿作为一个价差对象计算的净货值敞口与总货值（Go 扩展）
+// 净货值 = Σ netpos_i * mid_i * PriceMultiplier_i，同品种跨期满对冲时接近 0
+func (pas *PairwiseArbStrategy) ExposureValue() (net, gross float64) {
+	if pas.Def == nil {
+		return 0, 0
+	}
+	netpos := [2]int32{pas.Leg1.State.NetposPass, pas.Leg2.State.NetposAgg}
+	return pas.Def.ExposureValue(netpos[:]), pas.Def.GrossExposureValue(netpos[:])
+}
+
 // HandleSquareoff 平仓退出
 // 参考: PairwiseArbStrategy.cpp:586-626
 // 注意：可能从外部 goroutine（如 SIGINT handler）调用，需要加锁
@@ -181,14 +247,19 @@ func (pas *PairwiseArbStrategy) handleSquareoffLocked() {
 	pas.setActiveLocked(false)
 
 	// 保存 daily_init 状态
-	// C++: SaveMatrix2 (PairwiseArbStrategy.cpp:675-676)
-	//   out << strategyID << " " << "0 " << avgSpreadRatio_ori
-	//       << " " << ... << " " << m_firstStrat->m_netpos_pass << " " << m_secondStrat->m_netpos_agg;
-	// ytd1 = total netpos (关机时全部仓位变成"昨仓")，2day = 0
+	pas.saveDailyInitLocked()
+}
+
+// saveDailyInitLocked 写 daily_init（调用者已持有锁）
+// C++: SaveMatrix2 (PairwiseArbStrategy.cpp:675-676)
+//   out << strategyID << " " << "0 " << avgSpreadRatio_ori
+//       << " " << ... << " " << m_firstStrat->m_netpos_pass << " " << m_secondStrat->m_netpos_agg;
+// ytd1 = total netpos (关机时全部仓位变成"昨仓")，2day = 0
+func (pas *PairwiseArbStrategy) saveDailyInitLocked() {
 	if pas.DailyInitPath != "" {
 		saveDaily := &config.DailyInit{
 			StrategyID:    pas.StrategyID,
-			AvgSpreadOri:  pas.Spread.AvgSpreadOri,
+			AvgSpreadOri:  pas.Spread.SeedValue(),
 			OrigBaseName1: pas.Inst1.OrigBaseName, // C++: m_firstStrat->m_instru->m_origbaseName
 			OrigBaseName2: pas.Inst2.OrigBaseName, // C++: m_secondStrat->m_instru->m_origbaseName
 			NetposYtd1:    pas.Leg1.State.NetposPass, // C++: m_firstStrat->m_netpos_pass (total)
@@ -199,10 +270,49 @@ func (pas *PairwiseArbStrategy) handleSquareoffLocked() {
 			log.Printf("[PairwiseArb] daily_init 保存失败: %v", err)
 		} else {
 			log.Printf("[PairwiseArb] daily_init 已保存: %s", pas.DailyInitPath)
+			// Go 扩展：写前日志 checkpoint，崩溃恢复从此之后重放
+			if pas.Client != nil && pas.Client.Journal() != nil {
+				if err := pas.Client.Journal().Checkpoint("daily_init", pas.LastRolloverDay); err != nil {
+					log.Printf("[PairwiseArb] journal checkpoint 失败: %v", err)
+				}
+			}
 		}
 	}
 }
 
+// SaveDailyInit 写 daily_init（崩溃恢复重放后固化状态，Go 扩展）
+func (pas *PairwiseArbStrategy) SaveDailyInit() {
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+	pas.saveDailyInitLocked()
+}
+
+// RolloverTradingDay 交易日切换（Go 扩展）
+// 不重启进程完成 C++ 收盘 SaveMatrix2 + 次日 LoadMatrix2 的效果:
+// 记录日终持仓/盈亏、两腿被动仓转昨仓并清空日内统计、重写 daily_init。
+// 同一交易日重复调用返回 false，不做任何修改
+func (pas *PairwiseArbStrategy) RolloverTradingDay(tradingDay string) bool {
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+
+	if pas.LastRolloverDay == tradingDay {
+		log.Printf("[PairwiseArb] 交易日 %s 已切换，跳过", tradingDay)
+		return false
+	}
+
+	l1, l2 := pas.Leg1.State, pas.Leg2.State
+	log.Printf("[PairwiseArb] 交易日 %s 日终: leg1[netpos_pass=%d ytd=%d pnl=%.2f trades=%d] "+
+		"leg2[netpos_agg=%d pnl=%.2f trades=%d] total_pnl=%.2f",
+		tradingDay, l1.NetposPass, l1.NetposPassYtd, l1.NetPNL, l1.TradeCount,
+		l2.NetposAgg, l2.NetPNL, l2.TradeCount, l1.NetPNL+l2.NetPNL)
+
+	l1.RollTradingDay()
+	l2.RollTradingDay()
+	pas.LastRolloverDay = tradingDay
+	pas.saveDailyInitLocked()
+	return true
+}
+
 // ReloadThresholds 热加载阈值参数（线程安全）
 // 在持有 pas.mu 的情况下更新 ThresholdSet 并同步 SpreadTracker/MaxQuoteLevel 等副本字段
 // 对应 C++: LoadThresholds(simConfig) — 由 SIGUSR2 触发
@@ -237,6 +347,32 @@ func (pas *PairwiseArbStrategy) ReloadThresholds(firstMap, secondMap map[string]
 	if pas.Thold1.MaxQuoteLevel > 0 {
 		pas.MaxQuoteLevel = int32(pas.Thold1.MaxQuoteLevel)
 	}
+	// RegimeDetector — 开关变化时创建/移除，已存在时只更新切换阈值（保留滤波状态）
+	if !pas.Thold1.UseRegimeThold {
+		pas.Regime = nil
+	} else if pas.Regime == nil {
+		pas.Regime = NewRegimeDetector(pas.Thold1)
+	} else {
+		pas.Regime.HighProb = pas.Thold1.RegimeHighProb
+		pas.Regime.HurstMax = pas.Thold1.HurstMax
+		pas.Regime.Hold = pas.Thold1.CusumHold
+	}
+	// SpreadTracker 价差形式 — 改变后价差单位不同，EWA 从当前价差重新初始化
+	if pas.Thold1.SpreadType != pas.Spread.Type {
+		if def, err := NewPairSpreadDef(pas.Thold1.SpreadType, pas.Inst1, pas.Inst2); err == nil {
+			pas.Def = def
+			pas.Spread.Type = def.Type
+			pas.Spread.Initialized = false
+		} else {
+			log.Printf("[PairwiseArb] invalid SPREAD_TYPE %d: %v, keep %d", pas.Thold1.SpreadType, err, pas.Spread.Type)
+		}
+	}
+	// SpreadTracker 价差模式 — 切换模式或更新卡尔曼噪声参数（保留滤波状态）
+	if pas.Thold1.SpreadMode == SpreadModeKalman && pas.Spread.Type == SpreadTypeDiff {
+		pas.Spread.EnableKalman(pas.Thold1.KalmanQAlpha, pas.Thold1.KalmanQBeta, pas.Thold1.KalmanR)
+	} else if pas.Spread.Mode == SpreadModeKalman {
+		pas.Spread.DisableKalman()
+	}
 
 	log.Printf("[PairwiseArb] 阈值热加载完成:")
 	log.Printf("[PairwiseArb]   BeginPlace: %.4f → %.4f", oldBegin, pas.Thold1.BeginPlace)
@@ -247,6 +383,8 @@ func (pas *PairwiseArbStrategy) ReloadThresholds(firstMap, secondMap map[string]
 	log.Printf("[PairwiseArb]   Alpha:      %.10f → %.10f", oldAlpha, pas.Spread.Alpha)
 	log.Printf("[PairwiseArb]   MaxQuoteLevel: %d, AvgSpreadAway: %d",
 		pas.MaxQuoteLevel, pas.Spread.AvgSpreadAway)
+	log.Printf("[PairwiseArb]   UseRegimeThold: %v", pas.Regime != nil)
+	log.Printf("[PairwiseArb]   SpreadType: %d, SpreadMode: %d, Beta: %.6f", pas.Spread.Type, pas.Spread.Mode, pas.Spread.Beta)
 }
 
 // HandleSquareON 恢复策略
@@ -261,6 +399,8 @@ func (pas *PairwiseArbStrategy) HandleSquareON() {
 	pas.Leg2.State.OnCancel = false
 	pas.Leg2.State.OnFlat = false
 	pas.AggRepeat = 1
+	pas.HedgeRefPx = 0
+	pas.LegImbalanceTS = 0
 	pas.setActiveLocked(true)
 
 	log.Printf("[PairwiseArb] HandleSquareON: strategy reactivated")
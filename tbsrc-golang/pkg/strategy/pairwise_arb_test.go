@@ -255,6 +255,53 @@ func TestPairwiseArb_HandleSquareoff_DailyInit(t *testing.T) {
 	}
 }
 
+func TestPairwiseArb_RolloverTradingDay(t *testing.T) {
+	pas := newTestPAS()
+	pas.SetActive(true)
+	pas.DailyInitPath = filepath.Join(t.TempDir(), "daily_init.92201")
+
+	pas.Leg1.State.NetposPassYtd = 2
+	pas.Leg1.State.NetposPass = 5
+	pas.Leg1.State.TradeCount = 3
+	pas.Leg1.State.NetPNL = 120
+	pas.Leg2.State.NetposAgg = -5
+	pas.Leg2.State.TradeCount = 4
+
+	if !pas.RolloverTradingDay("20260930") {
+		t.Fatal("RolloverTradingDay should return true on first call")
+	}
+	if pas.Leg1.State.NetposPassYtd != 5 || pas.Leg1.State.NetposPass != 5 {
+		t.Errorf("leg1 ytd/pass = %d/%d, want 5/5", pas.Leg1.State.NetposPassYtd, pas.Leg1.State.NetposPass)
+	}
+	if pas.Leg2.State.NetposAgg != -5 {
+		t.Errorf("leg2 NetposAgg = %d, want -5", pas.Leg2.State.NetposAgg)
+	}
+	if pas.Leg1.State.TradeCount != 0 || pas.Leg2.State.TradeCount != 0 || pas.Leg1.State.NetPNL != 0 {
+		t.Errorf("daily counters not reset: trades %d/%d pnl %f",
+			pas.Leg1.State.TradeCount, pas.Leg2.State.TradeCount, pas.Leg1.State.NetPNL)
+	}
+	if !pas.IsActive() {
+		t.Error("strategy should stay active across rollover")
+	}
+
+	saved, err := config.LoadMatrix2(pas.DailyInitPath, pas.StrategyID)
+	if err != nil {
+		t.Fatalf("读取 daily_init 失败: %v", err)
+	}
+	if saved.NetposYtd1 != 5 || saved.NetposAgg2 != -5 {
+		t.Errorf("daily_init ytd1/agg2 = %d/%d, want 5/-5", saved.NetposYtd1, saved.NetposAgg2)
+	}
+
+	// 同一交易日重复切换无副作用
+	pas.Leg1.State.TradeCount = 1
+	if pas.RolloverTradingDay("20260930") {
+		t.Error("second RolloverTradingDay for the same day should return false")
+	}
+	if pas.Leg1.State.TradeCount != 1 {
+		t.Errorf("TradeCount = %d, want 1 (no second reset)", pas.Leg1.State.TradeCount)
+	}
+}
+
 func TestPairwiseArb_HandleSquareON(t *testing.T) {
 	pas := newTestPAS()
 	pas.HandleSquareoff() // deactivate first
@@ -0,0 +1,67 @@
+package strategy
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"tbsrc-golang/pkg/attribution"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// recordFillLocked 把成交连同成交时的 mid、价差和开仓阈值送入归因引擎（Go 扩展）
+// 必须在 ProcessORSDirectly 之前调用：全部成交后订单会从 OrdMap 删除
+func (pas *PairwiseArbStrategy) recordFillLocked(resp *shm.ResponseMsg, leg int) {
+	if pas.Attribution == nil || resp.Response_Type != shm.TRADE_CONFIRM {
+		return
+	}
+	lm, inst := pas.Leg1, pas.Inst1
+	if leg == 2 {
+		lm, inst = pas.Leg2, pas.Inst2
+	}
+	ord, ok := lm.Orders.OrdMap[resp.OrderID]
+	if !ok {
+		return
+	}
+
+	qty := float64(resp.Quantity)
+	mid := resp.Price
+	if inst.ValidBids > 0 && inst.ValidAsks > 0 {
+		mid = (inst.BidPx[0] + inst.AskPx[0]) / 2
+	}
+	// 与 ExecutionState.TransValue 相同的费率口径
+	st := lm.State
+	buy := ord.Side == types.Buy
+	fee := st.SellExchTx*resp.Price*qty*inst.PriceMultiplier + st.SellExchContractTx*qty
+	if buy {
+		fee = st.BuyExchTx*resp.Price*qty*inst.PriceMultiplier + st.BuyExchContractTx*qty
+	}
+	ts := time.Now()
+	if st.ExchTS > 0 {
+		ts = time.Unix(0, int64(st.ExchTS))
+	}
+
+	f := attribution.Fill{
+		Time:       ts,
+		Strategy:   strconv.Itoa(int(pas.StrategyID)),
+		Leg:        leg,
+		Buy:        buy,
+		Qty:        resp.Quantity,
+		Price:      resp.Price,
+		Mid:        mid,
+		Multiplier: inst.PriceMultiplier,
+		Fee:        fee,
+		Passive:    ord.OrdType == types.HitStandard,
+	}
+	if leg == 1 {
+		f.Spread = pas.Spread.CurrSpread
+		f.FairSpread = pas.Spread.AvgSpread
+		place := pas.Leg1.State.TholdAskPlace
+		if buy {
+			place = pas.Leg1.State.TholdBidPlace
+		}
+		f.Level = fmt.Sprintf("%.2f", place)
+	}
+	pas.Attribution.OnFill(f)
+}
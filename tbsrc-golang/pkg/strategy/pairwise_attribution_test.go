@@ -0,0 +1,55 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+
+	"tbsrc-golang/pkg/attribution"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// TestRecordFill_RoundTrip 验证 ORS 成交回报送入归因引擎并完成 round trip 配对
+func TestRecordFill_RoundTrip(t *testing.T) {
+	pas := newTestPAS()
+	pas.Attribution = attribution.NewEngine(0)
+
+	fill := func(leg int, id uint32, side types.TransactionType, ordType types.OrderHitType, price float64) {
+		ord := &types.OrderStats{OrderID: id, Side: side, OrdType: ordType, OpenQty: 1, Qty: 1, Price: price,
+			Status: types.StatusNewConfirm}
+		if leg == 1 {
+			pas.Leg1.Orders.OrdMap[id] = ord
+		} else {
+			pas.Leg2.Orders.OrdMap[id] = ord
+		}
+		resp := &shm.ResponseMsg{}
+		resp.OrderID = id
+		resp.Response_Type = shm.TRADE_CONFIRM
+		resp.Price = price
+		resp.Quantity = 1
+		pas.ORSCallBack(resp)
+	}
+
+	// leg1 mid 5810.5，leg2 mid 5800.5，乘数 15
+	fill(1, 100, types.Buy, types.HitStandard, 5810)
+	fill(2, 200, types.Sell, types.HitCross, 5800)
+	if r := pas.Attribution.Report(); r.OpenQty != 1 || r.UnpairedQty != 0 {
+		t.Fatalf("after open: open=%d unpaired=%d, want 1 / 0", r.OpenQty, r.UnpairedQty)
+	}
+	fill(1, 101, types.Sell, types.HitStandard, 5811)
+	fill(2, 201, types.Buy, types.HitCross, 5801)
+
+	r := pas.Attribution.Report()
+	if len(r.RoundTrips) != 1 || r.OpenQty != 0 {
+		t.Fatalf("report = %+v", r)
+	}
+	rt := r.RoundTrips[0]
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	// 两次被动成交各赚半个 tick，两次对冲各付半个 tick
+	if !near(rt.SpreadCapture, 15) || !near(rt.HedgeSlippage, -15) || !near(rt.Drift, 0) || !near(rt.PnL, 0) {
+		t.Errorf("round trip = %+v", rt)
+	}
+	if rt.Strategy != "92201" || rt.Side != "long" || !rt.Passive {
+		t.Errorf("round trip meta = %+v", rt)
+	}
+}
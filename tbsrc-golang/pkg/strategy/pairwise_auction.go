@@ -0,0 +1,118 @@
+package strategy
+
+import (
+	"log"
+
+	"tbsrc-golang/pkg/auction"
+	"tbsrc-golang/pkg/instrument"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// 集合竞价（Go 扩展，C++ 无对应）
+//
+// 竞价期间的盘口是指示性的（买一可能 >= 卖一），按连续交易逻辑计算价差会污染 EWA 均值、
+// 误触发 AVG_SPREAD_AWAY 和穿价报单。MDCallBack 识别到竞价行情后转到 auctionCallBack：
+// 只更新时间戳和两腿指示性均衡价；AUCTION_ENABLE 时按指示性价差相对均值的偏离在第一腿
+// 挂一笔竞价单，开盘撮合成交后由 SendAggressiveOrder 按敞口在第二腿对冲。
+
+// AuctionPhaseFunc 按交易日历判断合约是否处于集合竞价时段，exchTS 为交易所时间戳（纳秒）
+type AuctionPhaseFunc func(symbol string, exchTS uint64) bool
+
+// inAuction 交易日历判定为竞价时段，或盘口交叉（连续交易中不会出现）
+func (pas *PairwiseArbStrategy) inAuction(inst *instrument.Instrument, md *shm.MarketUpdateNew) bool {
+	if pas.AuctionPhase != nil && pas.AuctionPhase(inst.Symbol, md.Header.ExchTS) {
+		return true
+	}
+	return inst.ValidBids > 0 && inst.ValidAsks > 0 && auction.Crossed(inst.BidPx[0], inst.AskPx[0])
+}
+
+// indicativeAuction 由合约指示性盘口计算均衡价，ref 为参考价（最新价）
+func indicativeAuction(inst *instrument.Instrument, ref float64) auction.Result {
+	bids := make([]auction.Level, 0, inst.ValidBids)
+	for i := 0; i < int(inst.ValidBids) && i < instrument.BookDepth; i++ {
+		bids = append(bids, auction.Level{Price: inst.BidPx[i], Qty: int64(inst.BidQty[i])})
+	}
+	asks := make([]auction.Level, 0, inst.ValidAsks)
+	for i := 0; i < int(inst.ValidAsks) && i < instrument.BookDepth; i++ {
+		asks = append(asks, auction.Level{Price: inst.AskPx[i], Qty: int64(inst.AskQty[i])})
+	}
+	return auction.Uncross(bids, asks, ref)
+}
+
+// auctionCallBack 竞价期间的行情处理（调用方已持有 pas.mu）
+func (pas *PairwiseArbStrategy) auctionCallBack(inst *instrument.Instrument, md *shm.MarketUpdateNew, isLeg1 bool) {
+	leg := pas.Leg2
+	if isLeg1 {
+		leg = pas.Leg1
+	}
+	leg.State.ExchTS = md.Header.ExchTS
+	if md.Data.LastTradedPrice > 0 {
+		leg.State.LTP = md.Data.LastTradedPrice
+	}
+	eq := indicativeAuction(inst, leg.State.LTP)
+	if isLeg1 {
+		pas.Auction1 = eq
+	} else {
+		pas.Auction2 = eq
+	}
+	if !pas.InAuction {
+		pas.InAuction = true
+		log.Printf("[PairwiseArb] Auction phase: %s", inst.Symbol)
+	}
+
+	th := pas.Thold1
+	if !pas.Active || pas.AuctionSent || !th.AuctionEnable {
+		return
+	}
+	eq1, eq2 := pas.Auction1, pas.Auction2
+	if !eq1.Valid() || !eq2.Valid() || min(eq1.Matched, eq2.Matched) < th.AuctionMinVolume {
+		return
+	}
+
+	size := th.AuctionSize
+	if size <= 0 {
+		size = th.Size
+	}
+	maxSize := th.AuctionMaxSize
+	if maxSize <= 0 {
+		maxSize = th.MaxSize
+	}
+	netpos := pas.Leg1.State.NetposPass
+	dev := pas.Spread.Combine(eq1.Price, eq2.Price) - pas.Spread.AvgSpread
+	slop := float64(th.AuctionSlop) * pas.Inst1.TickSize
+
+	var side types.TransactionType
+	var price float64
+	var qty int32
+	switch {
+	case dev >= th.AuctionPlace:
+		// 指示性价差偏高：卖第一腿
+		side, price, qty = types.Sell, eq1.Price-slop, min(size, maxSize+netpos)
+	case -dev >= th.AuctionPlace:
+		side, price, qty = types.Buy, eq1.Price+slop, min(size, maxSize-netpos)
+	default:
+		return
+	}
+	if qty <= 0 || price <= 0 {
+		return
+	}
+	if _, ok := pas.Leg1.Orders.SendNewOrder(side, price, qty, 0, pas.Inst1, types.Quote, types.HitStandard, pas.Leg1); !ok {
+		return
+	}
+	pas.AuctionSent = true
+	log.Printf("[PairwiseArb] Auction order: %s side=%d %d@%.2f (eq1=%.2f/%d eq2=%.2f/%d dev=%.4f avg=%.4f)",
+		pas.Inst1.Symbol, side, qty, price, eq1.Price, eq1.Matched, eq2.Price, eq2.Matched, dev, pas.Spread.AvgSpread)
+}
+
+// endAuction 收到连续交易行情时清理竞价状态（调用方已持有 pas.mu）
+func (pas *PairwiseArbStrategy) endAuction() {
+	if !pas.InAuction {
+		return
+	}
+	log.Printf("[PairwiseArb] Auction ended: eq1=%.2f/%d eq2=%.2f/%d sent=%v",
+		pas.Auction1.Price, pas.Auction1.Matched, pas.Auction2.Price, pas.Auction2.Matched, pas.AuctionSent)
+	pas.InAuction = false
+	pas.AuctionSent = false
+	pas.Auction1, pas.Auction2 = auction.Result{}, auction.Result{}
+}
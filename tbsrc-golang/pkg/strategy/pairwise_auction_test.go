@@ -0,0 +1,100 @@
+package strategy
+
+import (
+	"testing"
+
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// setAuctionBook 设置一档指示性盘口
+func setAuctionBook(pas *PairwiseArbStrategy, bid1, ask1, bid2, ask2 float64, qty1, qty2 [2]float64) {
+	pas.Inst1.ValidBids, pas.Inst1.ValidAsks = 1, 1
+	pas.Inst2.ValidBids, pas.Inst2.ValidAsks = 1, 1
+	setBookLevels(pas.Inst1, []float64{bid1}, []float64{ask1}, []float64{qty1[0]}, []float64{qty1[1]})
+	setBookLevels(pas.Inst2, []float64{bid2}, []float64{ask2}, []float64{qty2[0]}, []float64{qty2[1]})
+}
+
+// TestAuction_PlacesLeg1Order 指示性价差偏离均值时在第一腿挂一笔竞价单，不更新价差均值
+func TestAuction_PlacesLeg1Order(t *testing.T) {
+	pas := newTestPAS()
+	pas.Thold1.AuctionEnable = true
+	pas.Thold1.AuctionPlace = 3
+	pas.Thold1.AuctionSize = 2
+	// 第一腿 5820 买 10 卖 8 → 均衡价 5820；第二腿 5800 → 指示性价差 20，均值 10
+	setAuctionBook(pas, 5820, 5820, 5800, 5800, [2]float64{10, 8}, [2]float64{5, 5})
+	md := &shm.MarketUpdateNew{}
+
+	pas.MDCallBack(pas.Inst1, md)
+	if !pas.InAuction || pas.Auction1.Price != 5820 || pas.Auction1.Matched != 8 || pas.Auction1.Imbalance != 2 {
+		t.Fatalf("InAuction = %v, Auction1 = %+v", pas.InAuction, pas.Auction1)
+	}
+	if len(pas.Leg1.Orders.OrdMap) != 0 {
+		t.Fatal("should wait for both legs")
+	}
+
+	pas.MDCallBack(pas.Inst2, md)
+	ord := pas.Leg1.Orders.AskMap[5820]
+	if ord == nil || ord.Qty != 2 || ord.OrdType != types.HitStandard {
+		t.Fatalf("auction order = %+v, want sell 2@5820", ord)
+	}
+	pas.MDCallBack(pas.Inst1, md)
+	if len(pas.Leg1.Orders.OrdMap) != 1 || len(pas.Leg2.Orders.OrdMap) != 0 {
+		t.Errorf("orders leg1 = %d leg2 = %d, want one auction order only",
+			len(pas.Leg1.Orders.OrdMap), len(pas.Leg2.Orders.OrdMap))
+	}
+	if pas.Spread.AvgSpread != 10 {
+		t.Errorf("AvgSpread = %.4f, auction ticks should not update it", pas.Spread.AvgSpread)
+	}
+}
+
+// TestAuction_Thresholds 未启用/偏离不足/可成交量不足时不报单；连续行情到来后清理竞价状态
+func TestAuction_Thresholds(t *testing.T) {
+	pas := newTestPAS()
+	setAuctionBook(pas, 5812, 5812, 5800, 5800, [2]float64{10, 10}, [2]float64{10, 10})
+	md := &shm.MarketUpdateNew{}
+
+	pas.MDCallBack(pas.Inst1, md)
+	pas.MDCallBack(pas.Inst2, md)
+	if len(pas.Leg1.Orders.OrdMap) != 0 {
+		t.Fatal("AUCTION_ENABLE off should not send")
+	}
+
+	pas.Thold1.AuctionEnable = true
+	pas.Thold1.AuctionPlace = 3 // 偏离 2 < 3
+	pas.MDCallBack(pas.Inst2, md)
+	if len(pas.Leg1.Orders.OrdMap) != 0 {
+		t.Fatal("deviation below AUCTION_PLACE should not send")
+	}
+
+	pas.Thold1.AuctionPlace = 1
+	pas.Thold1.AuctionMinVolume = 20
+	pas.MDCallBack(pas.Inst2, md)
+	if len(pas.Leg1.Orders.OrdMap) != 0 {
+		t.Fatal("matched volume below AUCTION_MIN_VOLUME should not send")
+	}
+
+	// 开盘：盘口恢复正常
+	setAuctionBook(pas, 5810, 5811, 5800, 5801, [2]float64{10, 10}, [2]float64{10, 10})
+	pas.MDCallBack(pas.Inst1, md)
+	if pas.InAuction || pas.Auction1.Valid() {
+		t.Errorf("InAuction = %v, Auction1 = %+v after continuous tick", pas.InAuction, pas.Auction1)
+	}
+}
+
+// TestAuction_CalendarPhase 交易日历判定竞价时段时，即使盘口未交叉也按竞价处理
+func TestAuction_CalendarPhase(t *testing.T) {
+	pas := newTestPAS()
+	pas.AuctionPhase = func(symbol string, exchTS uint64) bool { return exchTS < 100 }
+	md := &shm.MarketUpdateNew{}
+	md.Header.ExchTS = 50
+	pas.MDCallBack(pas.Inst1, md)
+	if !pas.InAuction {
+		t.Fatal("calendar auction phase not detected")
+	}
+	md.Header.ExchTS = 200
+	pas.MDCallBack(pas.Inst1, md)
+	if pas.InAuction {
+		t.Fatal("auction should end once calendar phase is over")
+	}
+}
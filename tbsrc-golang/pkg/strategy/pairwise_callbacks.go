@@ -31,6 +31,13 @@ func (pas *PairwiseArbStrategy) MDCallBack(inst *instrument.Instrument, md *shm.
 	// 识别是哪条腿
 	isLeg1 := inst == pas.Inst1
 
+	// Go 扩展: 集合竞价行情不走连续交易逻辑（见 pairwise_auction.go）
+	if pas.inAuction(inst, md) {
+		pas.auctionCallBack(inst, md, isLeg1)
+		return
+	}
+	pas.endAuction()
+
 	// C++: 委托给对应腿的 MDCallBack（更新 LTP、PNL 等）
 	if isLeg1 {
 		pas.Leg1.MDCallBack(inst, md)
@@ -62,6 +69,17 @@ func (pas *PairwiseArbStrategy) MDCallBack(inst *instrument.Instrument, md *shm.
 		return
 	}
 
+	// Go 扩展: 价差市场状态识别（驱动 setThresholds 的 SET_HIGH 切换）
+	if pas.Regime != nil {
+		wasHigh := pas.Regime.High
+		pas.Regime.Update(pas.Spread.CurrSpread)
+		if wasHigh != pas.Regime.High {
+			log.Printf("[PairwiseArb] Regime switch: high=%v (vol=%v p=%.3f, trending=%v H=%.3f, change=%v)",
+				pas.Regime.High, pas.Regime.VolHigh(), pas.Regime.HighProbability(),
+				pas.Regime.Trending(), pas.Regime.Hurst(), pas.Regime.ChangePoint())
+		}
+	}
+
 	// C++: Phase 7 — 时间/亏损/止损检查（每腿独立）
 	// 参考: ExecutionStrategy.cpp:2150-2186, 2279-2339
 	// 传递指针，因为止损触发时 C++ 会翻倍阈值防止 auto-resume 后立即重新触发
@@ -111,8 +129,8 @@ func (pas *PairwiseArbStrategy) MDCallBack(inst *instrument.Instrument, md *shm.
 	nowNs := pas.Leg1.State.ExchTS
 	gap := uint64(1_000_000_000) // 1秒
 	if nowNs-pas.LastMonitorTS > gap {
-		logSpread := pas.Inst1.BidPx[0] - pas.Inst2.BidPx[0]
-		logShort := pas.Inst1.AskPx[0] - pas.Inst2.AskPx[0]
+		logSpread := pas.Spread.Combine(pas.Inst1.BidPx[0], pas.Inst2.BidPx[0])
+		logShort := pas.Spread.Combine(pas.Inst1.AskPx[0], pas.Inst2.AskPx[0])
 		log.Printf("[PairwiseArb] [L/S]%.2f/%.2f [avg]%.4f [B/L/S]%.2f/%.2f/%.2f [Sz/MaxSz]%d/%d netpos=%d",
 			logSpread, logShort, pas.Spread.AvgSpread,
 			pas.Thold1.BeginPlace, pas.Thold1.LongPlace, pas.Thold1.ShortPlace,
@@ -148,6 +166,7 @@ func (pas *PairwiseArbStrategy) ORSCallBack(resp *shm.ResponseMsg) {
 
 	if inLeg1 {
 		// C++: 委托给 leg1（直接处理，绕过 override 避免递归）
+		pas.recordFillLocked(resp, 1)
 		pas.Leg1.ProcessORSDirectly(resp)
 
 		// C++: TRADE_CONFIRM 时重置 agg_repeat
@@ -161,6 +180,7 @@ func (pas *PairwiseArbStrategy) ORSCallBack(resp *shm.ResponseMsg) {
 		pas.handleAggOrder(resp)
 
 		// C++: 委托给 leg2（直接处理，绕过 override 避免递归）
+		pas.recordFillLocked(resp, 2)
 		pas.Leg2.ProcessORSDirectly(resp)
 
 		// C++: TRADE_CONFIRM 时重置 agg_repeat
@@ -0,0 +1,222 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"tbsrc-golang/pkg/client"
+	"tbsrc-golang/pkg/execution"
+	"tbsrc-golang/pkg/types"
+)
+
+// OrphanPolicy 重启后柜台上遗留在途订单的处理策略（Go 扩展）
+type OrphanPolicy string
+
+const (
+	OrphanAdopt  OrphanPolicy = "adopt"  // 接管到 OrderManager，按正常订单继续管理
+	OrphanCancel OrphanPolicy = "cancel" // 撤单（先接管以便撤单前的成交计入持仓）
+	OrphanIgnore OrphanPolicy = "ignore" // 只报告，不处理
+)
+
+// ParseOrphanPolicy 解析 -orphanPolicy 参数
+func ParseOrphanPolicy(s string) (OrphanPolicy, error) {
+	switch p := OrphanPolicy(s); p {
+	case OrphanAdopt, OrphanCancel, OrphanIgnore:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown orphan policy %q (adopt|cancel|ignore)", s)
+}
+
+// OrphanAction 对单个订单采取的动作
+type OrphanAction string
+
+const (
+	OrphanActionKnown    OrphanAction = "known"    // 写前日志已恢复，本地已跟踪
+	OrphanActionAdopted  OrphanAction = "adopted"  // 已接管
+	OrphanActionCanceled OrphanAction = "canceled" // 已发送撤单
+	OrphanActionIgnored  OrphanAction = "ignored"  // 未处理
+	OrphanActionForeign  OrphanAction = "foreign"  // 不属于本 ClientStore 的订单区间，不处理
+	OrphanActionStale    OrphanAction = "stale"    // 本地在途但柜台已不存在，已从 OrderManager 移除
+)
+
+// OrphanOrder 单个订单的处理结果
+type OrphanOrder struct {
+	client.LiveOrder
+	Action OrphanAction `json:"action"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+// OrphanReport 启动时在途订单处理报告
+type OrphanReport struct {
+	Time          time.Time     `json:"time"`
+	StrategyID    int32         `json:"strategy_id"`
+	Policy        OrphanPolicy  `json:"policy"`
+	FirstClientID uint32        `json:"first_client_id"`
+	ClientID      uint32        `json:"client_id"`
+	Live          int           `json:"live"` // 柜台返回的在途订单数
+	Orders        []OrphanOrder `json:"orders"`
+}
+
+// Count 统计某动作的订单数
+func (r *OrphanReport) Count(a OrphanAction) int {
+	n := 0
+	for _, o := range r.Orders {
+		if o.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary 一行汇总
+func (r *OrphanReport) Summary() string {
+	return fmt.Sprintf("policy=%s live=%d known=%d adopted=%d canceled=%d ignored=%d foreign=%d stale=%d",
+		r.Policy, r.Live, r.Count(OrphanActionKnown), r.Count(OrphanActionAdopted),
+		r.Count(OrphanActionCanceled), r.Count(OrphanActionIgnored),
+		r.Count(OrphanActionForeign), r.Count(OrphanActionStale))
+}
+
+// ReconcileLiveOrders 处理重启后柜台上仍在途的订单（Go 扩展）
+// 须在 Connector 启动之后、策略激活之前调用；live 为 Client.QueryLiveOrders 的结果。
+//
+// 归属判定: StrategyID 由 counter_bridge 过滤，另要求 clientID ∈ [firstClientID, clientID)，
+// 即同一 ClientStore 生命周期内本机此前进程分配的区间（ClientStore 重建后旧区间不可信）。
+//
+//	写前日志已恢复的订单 → known（放行回报路由）
+//	合约不属于两腿 → 按 policy 撤单（ignore 时不处理）
+//	adopt: 价位空闲则接管，否则撤单
+//	cancel: 价位空闲则先接管再经 OrderManager 撤单，否则直接撤单
+//
+// 本地在途（日志恢复）但柜台不存在的订单，终态回报在停机期间丢失，从 OrderManager 移除
+func (pas *PairwiseArbStrategy) ReconcileLiveOrders(live []client.LiveOrder,
+	firstClientID, clientID uint32, policy OrphanPolicy) *OrphanReport {
+
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+
+	report := &OrphanReport{
+		Time:          time.Now(),
+		StrategyID:    pas.StrategyID,
+		Policy:        policy,
+		FirstClientID: firstClientID,
+		ClientID:      clientID,
+		Live:          len(live),
+	}
+	seen := make(map[uint32]bool, len(live))
+
+	for _, o := range live {
+		seen[o.OrderID] = true
+		action, reason := pas.reconcileLiveOrder(o, firstClientID, clientID, policy)
+		report.Orders = append(report.Orders, OrphanOrder{LiveOrder: o, Action: action, Reason: reason})
+	}
+
+	for _, leg := range []*execution.LegManager{pas.Leg1, pas.Leg2} {
+		for id, ord := range leg.Orders.OrdMap {
+			if seen[id] {
+				continue
+			}
+			stale := client.LiveOrder{OrderID: id, Symbol: leg.Inst.Symbol, Side: ord.Side,
+				Price: ord.Price, OpenQty: ord.OpenQty}
+			if leg == pas.Leg2 && ord.OrdType == types.HitCross {
+				pas.releaseAggOrder(ord.Side)
+			}
+			leg.Orders.DropStaleOrder(id)
+			report.Orders = append(report.Orders, OrphanOrder{LiveOrder: stale, Action: OrphanActionStale,
+				Reason: "not live at exchange, terminal response missed while down"})
+		}
+	}
+
+	log.Printf("[PairwiseArb] live orders: %s", report.Summary())
+	return report
+}
+
+// reconcileLiveOrder 处理单个在途订单，调用方持 pas.mu
+func (pas *PairwiseArbStrategy) reconcileLiveOrder(o client.LiveOrder,
+	firstClientID, clientID uint32, policy OrphanPolicy) (OrphanAction, string) {
+
+	if o.ClientID < firstClientID || o.ClientID >= clientID {
+		return OrphanActionForeign, fmt.Sprintf("client ID %d outside ClientStore range [%d,%d)",
+			o.ClientID, firstClientID, clientID)
+	}
+
+	var leg *execution.LegManager
+	switch o.Symbol {
+	case pas.Inst1.Symbol:
+		leg = pas.Leg1
+	case pas.Inst2.Symbol:
+		leg = pas.Leg2
+	}
+
+	if leg != nil {
+		if ord, ok := leg.Orders.OrdMap[o.OrderID]; ok {
+			if pas.Client != nil {
+				pas.Client.AdoptOrder(o.OrderID, leg)
+			}
+			if ord.OpenQty != o.OpenQty {
+				return OrphanActionKnown, fmt.Sprintf("restored from journal, local open qty %d != exchange %d",
+					ord.OpenQty, o.OpenQty)
+			}
+			return OrphanActionKnown, "restored from journal"
+		}
+	}
+
+	if policy == OrphanIgnore {
+		return OrphanActionIgnored, "policy=ignore, order is not tracked"
+	}
+	if leg == nil {
+		pas.cancelLiveOrder(o)
+		return OrphanActionCanceled, "symbol is not a leg of this strategy"
+	}
+
+	// C++: leg2 只发 CROSS 对冲单（SendAggressiveOrder），与 restoreRequest 一致地累加 agg 计数
+	ordType := types.HitStandard
+	if leg == pas.Leg2 {
+		ordType = types.HitCross
+	}
+	if leg.Orders.AdoptLiveOrder(o.OrderID, o.Side, o.Price, o.OpenQty, ordType) == nil {
+		pas.cancelLiveOrder(o)
+		return OrphanActionCanceled, fmt.Sprintf("price level %.4f already has a %s order", o.Price, sideLabel(o.Side))
+	}
+	if pas.Client != nil {
+		pas.Client.AdoptOrder(o.OrderID, leg)
+	}
+	if ordType == types.HitCross {
+		if o.Side == types.Buy {
+			pas.BuyAggOrder++
+		} else {
+			pas.SellAggOrder++
+		}
+	}
+
+	if policy == OrphanAdopt {
+		return OrphanActionAdopted, ""
+	}
+	leg.Orders.SendCancelOrderByIDForce(leg.Inst, o.OrderID)
+	return OrphanActionCanceled, "policy=cancel"
+}
+
+// cancelLiveOrder 撤销未接管的在途订单（无本地订单状态，成交不计入持仓）
+func (pas *PairwiseArbStrategy) cancelLiveOrder(o client.LiveOrder) {
+	if pas.Client != nil {
+		pas.Client.CancelLiveOrder(o)
+	}
+}
+
+// releaseAggOrder 移除 leg2 CROSS 单时递减 agg 计数（对应 handleAggOrder）
+func (pas *PairwiseArbStrategy) releaseAggOrder(side types.TransactionType) {
+	if side == types.Buy {
+		if pas.BuyAggOrder > 0 {
+			pas.BuyAggOrder--
+		}
+	} else if pas.SellAggOrder > 0 {
+		pas.SellAggOrder--
+	}
+}
+
+func sideLabel(side types.TransactionType) string {
+	if side == types.Buy {
+		return "buy"
+	}
+	return "sell"
+}
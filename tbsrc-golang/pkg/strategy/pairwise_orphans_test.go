@@ -0,0 +1,100 @@
+package strategy
+
+import (
+	"testing"
+
+	"tbsrc-golang/pkg/client"
+	"tbsrc-golang/pkg/connector"
+	"tbsrc-golang/pkg/types"
+)
+
+func liveOrder(clientID, seq uint32, symbol string, side types.TransactionType, price float64, qty int32) client.LiveOrder {
+	return client.LiveOrder{
+		OrderID:  clientID*connector.OrderIDRange + seq,
+		ClientID: clientID,
+		Symbol:   symbol,
+		Side:     side,
+		Price:    price,
+		OpenQty:  qty,
+	}
+}
+
+// TestReconcileLiveOrders_Adopt 验证 adopt 策略：接管空闲价位、冲突价位撤单、区间外订单不处理、本地过期订单移除
+func TestReconcileLiveOrders_Adopt(t *testing.T) {
+	pas := newTestPAS()
+	pas.Active = false
+
+	// 日志恢复的两张 leg1 单：一张仍在途，一张柜台已不存在
+	known := liveOrder(3, 1, "ag2506", types.Buy, 5808, 1)
+	pas.Leg1.Orders.AdoptLiveOrder(known.OrderID, types.Buy, 5808, 1, types.HitStandard)
+	pas.Leg1.Orders.AdoptLiveOrder(3*connector.OrderIDRange+2, types.Sell, 5815, 1, types.HitStandard)
+
+	live := []client.LiveOrder{
+		known,
+		liveOrder(3, 3, "ag2506", types.Buy, 5809, 2),  // 空闲价位 → 接管
+		liveOrder(4, 1, "ag2506", types.Buy, 5809, 1),  // 同价位已接管 → 撤单
+		liveOrder(4, 2, "ag2512", types.Sell, 5800, 1), // leg2 → 接管为 CROSS
+		liveOrder(4, 3, "cu2506", types.Buy, 70000, 1), // 非本策略合约 → 撤单
+		liveOrder(1, 9, "ag2506", types.Buy, 5807, 1),  // clientID 在区间外 → foreign
+	}
+	report := pas.ReconcileLiveOrders(live, 2, 5, OrphanAdopt)
+
+	want := []OrphanAction{OrphanActionKnown, OrphanActionAdopted, OrphanActionCanceled,
+		OrphanActionAdopted, OrphanActionCanceled, OrphanActionForeign, OrphanActionStale}
+	if len(report.Orders) != len(want) {
+		t.Fatalf("report has %d orders, want %d: %+v", len(report.Orders), len(want), report.Orders)
+	}
+	for i, a := range want {
+		if report.Orders[i].Action != a {
+			t.Errorf("order %d (%d) action = %s, want %s (%s)",
+				i, report.Orders[i].OrderID, report.Orders[i].Action, a, report.Orders[i].Reason)
+		}
+	}
+
+	if n := len(pas.Leg1.Orders.OrdMap); n != 2 {
+		t.Errorf("leg1 orders = %d, want 2", n)
+	}
+	if ord := pas.Leg1.Orders.BidMap[5809]; ord == nil || ord.OpenQty != 2 || ord.Status != types.StatusNewConfirm {
+		t.Errorf("adopted bid at 5809 = %+v", ord)
+	}
+	if pas.Leg1.State.SellOpenQty != 0 {
+		t.Errorf("leg1 SellOpenQty = %v, want 0 after stale removal", pas.Leg1.State.SellOpenQty)
+	}
+	if pas.SellAggOrder != 1 {
+		t.Errorf("SellAggOrder = %d, want 1", pas.SellAggOrder)
+	}
+	if s := report.Summary(); s != "policy=adopt live=6 known=1 adopted=2 canceled=2 ignored=0 foreign=1 stale=1" {
+		t.Errorf("Summary = %q", s)
+	}
+}
+
+// TestReconcileLiveOrders_Cancel 验证 cancel 策略：先接管再撤单，撤单前成交仍计入本地订单
+func TestReconcileLiveOrders_Cancel(t *testing.T) {
+	pas := newTestPAS()
+	pas.Active = false
+
+	o := liveOrder(3, 1, "ag2506", types.Sell, 5812, 1)
+	report := pas.ReconcileLiveOrders([]client.LiveOrder{o}, 1, 4, OrphanCancel)
+
+	if report.Orders[0].Action != OrphanActionCanceled {
+		t.Fatalf("action = %s, want canceled", report.Orders[0].Action)
+	}
+	ord, ok := pas.Leg1.Orders.OrdMap[o.OrderID]
+	if !ok {
+		t.Fatal("order should stay tracked until cancel confirm")
+	}
+	if ord.Status != types.StatusCancelOrder {
+		t.Errorf("status = %v, want CANCEL_ORDER", ord.Status)
+	}
+}
+
+func TestParseOrphanPolicy(t *testing.T) {
+	for _, s := range []string{"adopt", "cancel", "ignore"} {
+		if p, err := ParseOrphanPolicy(s); err != nil || string(p) != s {
+			t.Errorf("ParseOrphanPolicy(%q) = %q, %v", s, p, err)
+		}
+	}
+	if _, err := ParseOrphanPolicy("keep"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
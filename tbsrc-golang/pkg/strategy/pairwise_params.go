@@ -0,0 +1,71 @@
+package strategy
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ParamsTarget 将 PairwiseArbStrategy 的阈值适配为参数变更工作流的作用对象（Go 扩展）
+// 策略 ID 为 strconv.Itoa(StrategyID)；参数名与 LoadFromMap 一致（snake_case），
+// 布尔阈值以 0/1 表示。两腿共用一套阈值，与 SIGUSR2 重载（ReloadThresholds(m, m)）一致
+type ParamsTarget struct {
+	pas *PairwiseArbStrategy
+}
+
+// NewParamsTarget 创建参数工作流适配器
+func NewParamsTarget(pas *PairwiseArbStrategy) ParamsTarget {
+	return ParamsTarget{pas: pas}
+}
+
+func (t ParamsTarget) id() string {
+	return strconv.Itoa(int(t.pas.StrategyID))
+}
+
+// StrategyIDs 返回唯一的策略 ID
+func (t ParamsTarget) StrategyIDs() []string {
+	return []string{t.id()}
+}
+
+// Parameters 返回当前生效的第一腿阈值
+func (t ParamsTarget) Parameters(id string) (map[string]interface{}, error) {
+	if id != t.id() {
+		return nil, fmt.Errorf("strategy %s not found", id)
+	}
+	t.pas.mu.Lock()
+	m := t.pas.Thold1.ToMap()
+	t.pas.mu.Unlock()
+
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// Apply 热加载阈值（同 SIGUSR2 重载路径）
+func (t ParamsTarget) Apply(id string, params map[string]interface{}) error {
+	if id != t.id() {
+		return fmt.Errorf("strategy %s not found", id)
+	}
+	m := make(map[string]float64, len(params))
+	for k, v := range params {
+		switch x := v.(type) {
+		case float64:
+			m[k] = x
+		case int:
+			m[k] = float64(x)
+		case int64:
+			m[k] = float64(x)
+		case bool:
+			if x {
+				m[k] = 1
+			} else {
+				m[k] = 0
+			}
+		default:
+			return fmt.Errorf("parameter %s: unsupported value type %T", k, v)
+		}
+	}
+	t.pas.ReloadThresholds(m, m)
+	return nil
+}
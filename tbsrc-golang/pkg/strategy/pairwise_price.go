@@ -32,7 +32,8 @@ func (pas *PairwiseArbStrategy) GetBidPrice(price float64, ordType types.OrderHi
 	}
 
 	// C++: bidInv = bidPx[level] - leg2.bidPx[0] + tickSize
-	bidInv := price - inst2.BidPx[0] + tickSize
+	// 按改善后的价格组合价差，ratio/log 价差下 tick 自动折算为价差单位
+	bidInv := pas.Spread.Combine(price+tickSize, inst2.BidPx[0])
 
 	// C++: if bidInv <= avgSpreadRatio - BEGIN_PLACE
 	if bidInv <= pas.Spread.AvgSpread-pas.Thold1.BeginPlace {
@@ -75,7 +76,7 @@ func (pas *PairwiseArbStrategy) GetBidPrice2(price float64, ordType types.OrderH
 
 	// C++: bidInv = leg1.bidPx[0] - leg2.bidPx[level] - tickSize
 	// 第二条腿 bid 对应卖价差，用 leg1.bidPx[0] 计算
-	bidInv := inst1.BidPx[0] - inst2.BidPx[level] - tickSize
+	bidInv := pas.Spread.Combine(inst1.BidPx[0], inst2.BidPx[level]+tickSize)
 
 	// C++: if bidInv >= avgSpreadRatio + leg2.thold.BEGIN_PLACE
 	if bidInv >= pas.Spread.AvgSpread+pas.Thold2.BeginPlace {
@@ -115,7 +116,7 @@ func (pas *PairwiseArbStrategy) GetAskPrice2(price float64, ordType types.OrderH
 	}
 
 	// C++: askInv = leg1.askPx[0] - leg2.askPx[level] + tickSize
-	askInv := inst1.AskPx[0] - inst2.AskPx[level] + tickSize
+	askInv := pas.Spread.Combine(inst1.AskPx[0], inst2.AskPx[level]-tickSize)
 
 	// C++: if askInv <= avgSpreadRatio - leg2.thold.BEGIN_PLACE
 	if askInv <= pas.Spread.AvgSpread-pas.Thold2.BeginPlace {
@@ -156,7 +157,7 @@ func (pas *PairwiseArbStrategy) GetAskPrice(price float64, ordType types.OrderHi
 	}
 
 	// C++: askInv = askPx[level] - leg2.askPx[0] - tickSize
-	askInv := price - inst2.AskPx[0] - tickSize
+	askInv := pas.Spread.Combine(price-tickSize, inst2.AskPx[0])
 
 	// C++: if askInv >= avgSpreadRatio + BEGIN_PLACE
 	if askInv >= pas.Spread.AvgSpread+pas.Thold1.BeginPlace {
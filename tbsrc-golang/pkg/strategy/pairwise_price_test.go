@@ -233,3 +233,107 @@ func TestGetAskPrice_InvisibleBook_SmallQueueAhead(t *testing.T) {
 		t.Errorf("price = %f, want 5814 (queue too small, no improvement)", price)
 	}
 }
+
+// ---- ratio / log 价差：改善一个 tick 的价差按价差单位比较 ----
+
+func TestInvisibleBookPrice_RatioAndLogSpread(t *testing.T) {
+	type pricer func(pas *PairwiseArbStrategy, price float64) float64
+	cases := []struct {
+		name     string
+		price    float64 // 空档处的报价
+		improved float64
+		setup    func(pas *PairwiseArbStrategy)
+		get      pricer
+		inv      func(pas *PairwiseArbStrategy) float64 // 改善后价差
+		below    bool                                   // true: inv <= avg - place；false: inv >= avg + place
+		thold    func(pas *PairwiseArbStrategy) *types.ThresholdSet
+	}{
+		{
+			name: "bid1", price: 5807, improved: 5808,
+			setup: func(pas *PairwiseArbStrategy) {
+				pas.Inst1.BidPx[1] = 5807
+				pas.Leg1.Orders.BidMap[5807] = &types.OrderStats{Price: 5807, QuantAhead: 20}
+			},
+			get: func(pas *PairwiseArbStrategy, p float64) float64 {
+				p, _ = pas.GetBidPrice(p, types.HitStandard, 1)
+				return p
+			},
+			inv:   func(pas *PairwiseArbStrategy) float64 { return pas.Spread.Combine(5808, 5800) },
+			below: true,
+			thold: func(pas *PairwiseArbStrategy) *types.ThresholdSet { return pas.Thold1 },
+		},
+		{
+			name: "ask1", price: 5814, improved: 5813,
+			setup: func(pas *PairwiseArbStrategy) {
+				pas.Inst1.AskPx[1] = 5814
+				pas.Leg1.Orders.AskMap[5814] = &types.OrderStats{Price: 5814, QuantAhead: 20}
+			},
+			get: func(pas *PairwiseArbStrategy, p float64) float64 {
+				p, _ = pas.GetAskPrice(p, types.HitStandard, 1)
+				return p
+			},
+			inv:   func(pas *PairwiseArbStrategy) float64 { return pas.Spread.Combine(5813, 5801) },
+			thold: func(pas *PairwiseArbStrategy) *types.ThresholdSet { return pas.Thold1 },
+		},
+		{
+			name: "bid2", price: 5797, improved: 5798,
+			setup: func(pas *PairwiseArbStrategy) {
+				pas.Inst2.BidPx[1] = 5797
+				pas.Leg2.Orders.BidMap[5797] = &types.OrderStats{Price: 5797, QuantAhead: 20}
+			},
+			get: func(pas *PairwiseArbStrategy, p float64) float64 {
+				p, _ = pas.GetBidPrice2(p, types.HitStandard, 1)
+				return p
+			},
+			inv:   func(pas *PairwiseArbStrategy) float64 { return pas.Spread.Combine(5810, 5798) },
+			thold: func(pas *PairwiseArbStrategy) *types.ThresholdSet { return pas.Thold2 },
+		},
+		{
+			name: "ask2", price: 5804, improved: 5803,
+			setup: func(pas *PairwiseArbStrategy) {
+				pas.Inst2.AskPx[1] = 5804
+				pas.Leg2.Orders.AskMap[5804] = &types.OrderStats{Price: 5804, QuantAhead: 20}
+			},
+			get: func(pas *PairwiseArbStrategy, p float64) float64 {
+				p, _ = pas.GetAskPrice2(p, types.HitStandard, 1)
+				return p
+			},
+			inv:   func(pas *PairwiseArbStrategy) float64 { return pas.Spread.Combine(5811, 5803) },
+			below: true,
+			thold: func(pas *PairwiseArbStrategy) *types.ThresholdSet { return pas.Thold2 },
+		},
+	}
+
+	for _, spreadType := range []int{SpreadTypeRatio, SpreadTypeLog} {
+		for _, tc := range cases {
+			// 门槛恰好在改善后价差两侧 1e-5（约 0.06 tick）处：一侧改善，另一侧不改善
+			for _, fire := range []bool{true, false} {
+				pas := newTestPAS()
+				pas.UseInvisibleBook = true
+				pas.Spread.Type = spreadType
+				pas.Spread.Seed(pas.Spread.Combine(5810.5, 5800.5))
+				tc.setup(pas)
+
+				inv, avg := tc.inv(pas), pas.Spread.AvgSpread
+				eps := 1e-5
+				if !fire {
+					eps = -eps
+				}
+				if tc.below {
+					tc.thold(pas).BeginPlace = avg - inv - eps
+				} else {
+					tc.thold(pas).BeginPlace = inv - avg - eps
+				}
+
+				want := tc.price
+				if fire {
+					want = tc.improved
+				}
+				if got := tc.get(pas, tc.price); got != want {
+					t.Errorf("type=%d %s fire=%v: price = %v, want %v (inv=%.6f avg=%.6f place=%.6f)",
+						spreadType, tc.name, fire, got, want, inv, avg, tc.thold(pas).BeginPlace)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package strategy
+
+import (
+	"log"
+
+	"tbsrc-golang/pkg/journal"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// RecoveryStats 写前日志重放统计
+type RecoveryStats struct {
+	Requests   int // 重放的请求
+	Responses  int // 重放的回报
+	Fills      int // 按回报内容补记的未知订单成交（请求未落盘）
+	Skipped    int // 无法对应到本策略订单的记录
+	OpenOrders int // 重放后仍在途的订单
+}
+
+// Recover 从写前日志重建两腿订单和持仓（Go 扩展）
+// 须在 Init(daily_init) 之后、Connector 启动之前调用；records 为最后一个 checkpoint 之后的记录。
+// 请求重建 OrdMap/BidMap/AskMap 并重新登记 orderID 路由，回报经 ORSCallBack 重放更新 ExecutionState。
+// 重放期间策略未激活，回调中的补对冲/平仓不会发出新请求
+func (pas *PairwiseArbStrategy) Recover(records []journal.Record) RecoveryStats {
+	var st RecoveryStats
+	for _, rec := range records {
+		switch rec.Kind {
+		case journal.KindRequest:
+			req, ordType, err := journal.DecodeRequest(rec.Payload)
+			if err != nil {
+				log.Printf("[PairwiseArb] recover: %v", err)
+				st.Skipped++
+				continue
+			}
+			if pas.restoreRequest(&req, ordType) {
+				st.Requests++
+			} else {
+				st.Skipped++
+			}
+
+		case journal.KindResponse:
+			resp, err := journal.DecodeResponse(rec.Payload)
+			if err != nil {
+				log.Printf("[PairwiseArb] recover: %v", err)
+				st.Skipped++
+				continue
+			}
+			pas.mu.Lock()
+			_, inLeg1 := pas.Leg1.Orders.OrdMap[resp.OrderID]
+			_, inLeg2 := pas.Leg2.Orders.OrdMap[resp.OrderID]
+			pas.mu.Unlock()
+			if !inLeg1 && !inLeg2 {
+				if resp.Response_Type == shm.TRADE_CONFIRM && resp.StrategyID == pas.StrategyID && pas.restoreFill(&resp) {
+					st.Fills++
+				} else {
+					st.Skipped++
+				}
+				continue
+			}
+			pas.ORSCallBack(&resp)
+			st.Responses++
+		}
+	}
+
+	pas.mu.Lock()
+	st.OpenOrders = len(pas.Leg1.Orders.OrdMap) + len(pas.Leg2.Orders.OrdMap)
+	pas.mu.Unlock()
+
+	log.Printf("[PairwiseArb] recover: requests=%d responses=%d fills=%d skipped=%d open_orders=%d "+
+		"leg1.netpos_pass=%d leg2.netpos_agg=%d",
+		st.Requests, st.Responses, st.Fills, st.Skipped, st.OpenOrders,
+		pas.Leg1.State.NetposPass, pas.Leg2.State.NetposAgg)
+	return st
+}
+
+// restoreFill 请求未落盘的订单成交：按回报中的合约找到所属腿并计入持仓
+// leg1 只挂被动单（netpos_pass），leg2 只发 CROSS 对冲单（netpos_agg）
+func (pas *PairwiseArbStrategy) restoreFill(resp *shm.ResponseMsg) bool {
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+
+	switch journal.CString(resp.Symbol[:]) {
+	case pas.Inst1.Symbol:
+		pas.Leg1.Orders.RestoreFill(resp, pas.Inst1, types.HitStandard)
+	case pas.Inst2.Symbol:
+		pas.Leg2.Orders.RestoreFill(resp, pas.Inst2, types.HitCross)
+	default:
+		return false
+	}
+	log.Printf("[PairwiseArb] recover: fill of unjournaled orderID=%d %s side=%c qty=%d price=%.2f applied from response",
+		resp.OrderID, journal.CString(resp.Symbol[:]), resp.Side, resp.Quantity, resp.Price)
+	return true
+}
+
+// restoreRequest 按合约找到所属腿并重建订单状态
+func (pas *PairwiseArbStrategy) restoreRequest(req *shm.RequestMsg, ordType types.OrderHitType) bool {
+	pas.mu.Lock()
+	defer pas.mu.Unlock()
+
+	symbol := journal.CString(req.ContractDesc.Symbol[:])
+	leg := pas.Leg1
+	switch symbol {
+	case pas.Inst1.Symbol:
+	case pas.Inst2.Symbol:
+		leg = pas.Leg2
+	default:
+		return false
+	}
+
+	if !leg.Orders.RestoreRequest(req, ordType) {
+		return false
+	}
+	if req.Request_Type != shm.NEWORDER {
+		return true
+	}
+
+	if pas.Client != nil {
+		pas.Client.RestoreOrderID(req.OrderID, leg)
+	}
+	// C++: SendAggressiveOrder 发出 leg2 CROSS 单时累加 buyAggOrder/sellAggOrder，终态回报时由 handleAggOrder 递减
+	if leg == pas.Leg2 && ordType == types.HitCross {
+		if req.TransactionType == shm.SideBuy {
+			pas.BuyAggOrder++
+		} else {
+			pas.SellAggOrder++
+		}
+	}
+	return true
+}
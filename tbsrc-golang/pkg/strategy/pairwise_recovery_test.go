@@ -0,0 +1,88 @@
+package strategy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"tbsrc-golang/pkg/journal"
+	"tbsrc-golang/pkg/shm"
+	"tbsrc-golang/pkg/types"
+)
+
+// TestRecover_RebuildsOrdersAndPositions 验证写前日志重放重建两腿 OrdMap 和净仓
+func TestRecover_RebuildsOrdersAndPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.92201")
+	w, err := journal.Open(path, journal.Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	request := func(id uint32, reqType shm.RequestType, symbol string, side uint8, price float64, qty int32, ordType types.OrderHitType) {
+		req := &shm.RequestMsg{Request_Type: reqType, OrderID: id, TransactionType: side, Price: price, Quantity: qty}
+		copy(req.ContractDesc.Symbol[:], symbol)
+		w.RecordRequest(req, ordType)
+	}
+	response := func(id uint32, respType shm.ResponseType, symbol string, side uint8, price float64, qty int32) {
+		resp := &shm.ResponseMsg{Response_Type: respType, OrderID: id, Side: side, Price: price, Quantity: qty, StrategyID: 92201}
+		copy(resp.Symbol[:], symbol)
+		w.RecordResponse(resp)
+	}
+
+	w.Checkpoint("daily_init", "20250102")
+	// leg1 被动买 2 手全部成交
+	request(101, shm.NEWORDER, "ag2506", shm.SideBuy, 5810, 2, types.HitStandard)
+	response(101, shm.NEW_ORDER_CONFIRM, "ag2506", shm.SideBuy, 5810, 2)
+	response(101, shm.TRADE_CONFIRM, "ag2506", shm.SideBuy, 5810, 2)
+	// leg2 主动卖 2 手对冲
+	request(102, shm.NEWORDER, "ag2512", shm.SideSell, 5800, 2, types.HitCross)
+	response(102, shm.NEW_ORDER_CONFIRM, "ag2512", shm.SideSell, 5800, 2)
+	response(102, shm.TRADE_CONFIRM, "ag2512", shm.SideSell, 5800, 2)
+	// leg1 卖单在途
+	request(103, shm.NEWORDER, "ag2506", shm.SideSell, 5812, 1, types.HitStandard)
+	response(103, shm.NEW_ORDER_CONFIRM, "ag2506", shm.SideSell, 5812, 1)
+	// 请求未落盘的 leg2 成交：按回报补记
+	response(104, shm.TRADE_CONFIRM, "ag2512", shm.SideSell, 5799, 1)
+	// 其他策略的回报
+	other := &shm.ResponseMsg{Response_Type: shm.TRADE_CONFIRM, OrderID: 999, Side: shm.SideBuy, Price: 5810, Quantity: 1, StrategyID: 1}
+	copy(other.Symbol[:], "ag2506")
+	w.RecordResponse(other)
+	w.Close()
+
+	recs, _, err := journal.ReadAll(path)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	pas := newTestPAS()
+	pas.Active = false // 与实盘启动一致：重放期间不下单
+	st := pas.Recover(journal.AfterCheckpoint(recs))
+
+	if st.Requests != 3 || st.Responses != 5 || st.Fills != 1 || st.Skipped != 1 {
+		t.Errorf("stats = %+v, want requests=3 responses=5 fills=1 skipped=1", st)
+	}
+	if st.OpenOrders != 1 {
+		t.Errorf("OpenOrders = %d, want 1", st.OpenOrders)
+	}
+	if _, ok := pas.Leg1.Orders.OrdMap[103]; !ok {
+		t.Error("order 103 should be restored in Leg1.OrdMap")
+	}
+	if pas.Leg1.Orders.AskMap[5812] == nil {
+		t.Error("order 103 should be restored in Leg1.AskMap")
+	}
+	if pas.Leg1.State.NetposPass != 2 {
+		t.Errorf("Leg1.NetposPass = %d, want 2", pas.Leg1.State.NetposPass)
+	}
+	if pas.Leg2.State.NetposAgg != -3 {
+		t.Errorf("Leg2.NetposAgg = %d, want -3 (incl. unjournaled fill)", pas.Leg2.State.NetposAgg)
+	}
+	if pas.Leg2.State.SellOpenQty != 0 || len(pas.Leg2.Orders.OrdMap) != 0 {
+		t.Errorf("unjournaled fill left open state: sellOpenQty=%v orders=%d",
+			pas.Leg2.State.SellOpenQty, len(pas.Leg2.Orders.OrdMap))
+	}
+	if pas.SellAggOrder != 0 {
+		t.Errorf("SellAggOrder = %d, want 0 after fill", pas.SellAggOrder)
+	}
+	if pas.Leg1.State.SellOpenOrders != 1 {
+		t.Errorf("Leg1.SellOpenOrders = %d, want 1", pas.Leg1.State.SellOpenOrders)
+	}
+}
@@ -74,9 +74,10 @@ func (pas *PairwiseArbStrategy) SendOrder() {
 		}
 
 		// C++: LongSpreadRatio1 = leg1.bidPx[level] - leg2.bidPx[0]
-		longSpread := inst1.BidPx[level] - inst2.BidPx[0]
+		// 卡尔曼模式下 Combine = p1 - Beta*p2，EWA 模式下与 C++ 一致
+		longSpread := pas.Spread.Combine(inst1.BidPx[level], inst2.BidPx[0])
 		// C++: ShortSpreadRatio1 = leg1.askPx[level] - leg2.askPx[0]
-		shortSpread := inst1.AskPx[level] - inst2.AskPx[0]
+		shortSpread := pas.Spread.Combine(inst1.AskPx[level], inst2.AskPx[0])
 
 		// ---- ASK (sell) placement ----
 		// C++: if ShortSpreadRatio1 > avgSpreadRatio + m_tholdAskPlace
@@ -177,7 +178,7 @@ func (pas *PairwiseArbStrategy) cancelOutOfRangeOrders(avgSpread, bidRemove, ask
 
 	// C++: cancel bid orders where spread is too tight
 	for _, ord := range pas.Leg1.Orders.BidMap {
-		longSpread := ord.Price - inst2.BidPx[0]
+		longSpread := pas.Spread.Combine(ord.Price, inst2.BidPx[0])
 		if longSpread > avgSpread-bidRemove {
 			if ord.Status == types.StatusNewConfirm ||
 				ord.Status == types.StatusModifyConfirm ||
@@ -189,7 +190,7 @@ func (pas *PairwiseArbStrategy) cancelOutOfRangeOrders(avgSpread, bidRemove, ask
 
 	// C++: cancel ask orders where spread is too tight
 	for _, ord := range pas.Leg1.Orders.AskMap {
-		shortSpread := ord.Price - inst2.AskPx[0]
+		shortSpread := pas.Spread.Combine(ord.Price, inst2.AskPx[0])
 		if shortSpread < avgSpread+askRemove {
 			if ord.Status == types.StatusNewConfirm ||
 				ord.Status == types.StatusModifyConfirm ||
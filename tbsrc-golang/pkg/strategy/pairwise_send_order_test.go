@@ -249,3 +249,24 @@ func TestSendOrder_MultiLevel(t *testing.T) {
 		t.Errorf("expected multiple ask orders, got %d", len(pas.Leg1.Orders.AskMap))
 	}
 }
+
+// TestQuoteImproveOK SMART_QUOTE 改善后的 leg1 报价按挂单门槛复核
+func TestQuoteImproveOK(t *testing.T) {
+	pas := newTestPAS()
+	pas.Leg1.State.TholdBidPlace = 2
+	pas.Leg1.State.TholdAskPlace = 2
+	// avg=10，leg2 bid=5800 / ask=5801
+
+	if !pas.quoteImproveOK(types.Buy, 5807) { // 7 < 8
+		t.Error("bid 5807 should pass the place threshold")
+	}
+	if pas.quoteImproveOK(types.Buy, 5808) { // 8 !< 8
+		t.Error("bid 5808 should fail the place threshold")
+	}
+	if !pas.quoteImproveOK(types.Sell, 5814) { // 13 > 12
+		t.Error("ask 5814 should pass the place threshold")
+	}
+	if pas.quoteImproveOK(types.Sell, 5813) { // 12 !> 12
+		t.Error("ask 5813 should fail the place threshold")
+	}
+}
@@ -8,12 +8,32 @@ package strategy
 //   1. maxPos = max(BID_MAX_SIZE, ASK_MAX_SIZE)（sendInLots 模式）
 //   2. 根据 m_netpos_pass 线性插值 BidPlace/BidRemove/AskPlace/AskRemove
 //   3. 同时设置独立 BidSize/BidMaxPos/AskSize/AskMaxPos
+//
+// Go 扩展: 启用 RegimeDetector 时由其判定 SET_HIGH；SET_HIGH 时
+// BEGIN_PLACE/LONG_PLACE 替换为 BEGIN_PLACE_HIGH/LONG_PLACE_HIGH（未配置则不替换）
 func (pas *PairwiseArbStrategy) setThresholds() {
 	inst1 := pas.Inst1
 	inst2 := pas.Inst2
 	thold := pas.Thold1
 	state := pas.Leg1.State
 
+	if pas.Regime != nil {
+		state.SetHigh = 0
+		if pas.Regime.High {
+			state.SetHigh = 1
+		}
+	}
+	beginPlace := thold.BeginPlace
+	longPlace := thold.LongPlace
+	if state.SetHigh != 0 {
+		if thold.BeginPlaceHigh > 0 {
+			beginPlace = thold.BeginPlaceHigh
+		}
+		if thold.LongPlaceHigh > 0 {
+			longPlace = thold.LongPlaceHigh
+		}
+	}
+
 	// C++: 计算仓位大小参数
 	// 参考: PairwiseArbStrategy.cpp:904-919
 	if inst1.SendInLots && inst2.SendInLots {
@@ -46,9 +66,9 @@ func (pas *PairwiseArbStrategy) setThresholds() {
 
 	// C++: 阈值差值
 	// auto long_place_diff_thold = LONG_PLACE - BEGIN_PLACE
-	longPlaceDiff := thold.LongPlace - thold.BeginPlace
+	longPlaceDiff := longPlace - beginPlace
 	// auto short_place_diff_thold = BEGIN_PLACE - SHORT_PLACE
-	shortPlaceDiff := thold.BeginPlace - thold.ShortPlace
+	shortPlaceDiff := beginPlace - thold.ShortPlace
 	// auto long_remove_diff_thold = LONG_REMOVE - BEGIN_REMOVE
 	longRemoveDiff := thold.LongRemove - thold.BeginRemove
 	// auto short_remove_diff_thold = BEGIN_REMOVE - SHORT_REMOVE
@@ -64,25 +84,25 @@ func (pas *PairwiseArbStrategy) setThresholds() {
 	// 参考: PairwiseArbStrategy.cpp:927-947
 	if state.NetposPass == 0 {
 		// C++: flat position
-		state.TholdBidPlace = thold.BeginPlace
+		state.TholdBidPlace = beginPlace
 		state.TholdBidRemove = thold.BeginRemove
-		state.TholdAskPlace = thold.BeginPlace
+		state.TholdAskPlace = beginPlace
 		state.TholdAskRemove = thold.BeginRemove
 	} else if state.NetposPass > 0 {
 		// C++: 多头 — 线性插值
 		// m_tholdBidPlace = BEGIN_PLACE + long_diff * netpos_pass / maxPos
-		state.TholdBidPlace = thold.BeginPlace + longPlaceDiff*netposPass/maxPos
+		state.TholdBidPlace = beginPlace + longPlaceDiff*netposPass/maxPos
 		state.TholdBidRemove = thold.BeginRemove + longRemoveDiff*netposPass/maxPos
 		// m_tholdAskPlace = BEGIN_PLACE - short_diff * netpos_pass / maxPos
-		state.TholdAskPlace = thold.BeginPlace - shortPlaceDiff*netposPass/maxPos
+		state.TholdAskPlace = beginPlace - shortPlaceDiff*netposPass/maxPos
 		state.TholdAskRemove = thold.BeginRemove - shortRemoveDiff*netposPass/maxPos
 	} else {
 		// C++: 空头 — 线性插值（netpos_pass < 0）
 		// m_tholdBidPlace = BEGIN_PLACE + short_diff * netpos_pass / maxPos
-		state.TholdBidPlace = thold.BeginPlace + shortPlaceDiff*netposPass/maxPos
+		state.TholdBidPlace = beginPlace + shortPlaceDiff*netposPass/maxPos
 		state.TholdBidRemove = thold.BeginRemove + shortRemoveDiff*netposPass/maxPos
 		// m_tholdAskPlace = BEGIN_PLACE - long_diff * netpos_pass / maxPos
-		state.TholdAskPlace = thold.BeginPlace - longPlaceDiff*netposPass/maxPos
+		state.TholdAskPlace = beginPlace - longPlaceDiff*netposPass/maxPos
 		state.TholdAskRemove = thold.BeginRemove - longRemoveDiff*netposPass/maxPos
 	}
 }
@@ -0,0 +1,81 @@
+package strategy
+
+import (
+	"tbsrc-golang/pkg/regime"
+	"tbsrc-golang/pkg/types"
+)
+
+// RegimeDetector 价差市场状态识别，驱动 setThresholds 的高/低阈值组切换
+// 对应 C++ ExecutionStrategy 的 SET_HIGH（BEGIN_PLACE_HIGH / LONG_PLACE_HIGH），
+// C++ 中 SET_HIGH 由外部设置，这里根据价差自动判定（Go 扩展）
+//
+// 三个独立的判据，任一触发即进入高状态：
+//   - 波动率 HMM: 后验 P(high) >= REGIME_HIGH_PROB
+//   - 方差比/Hurst: H > HURST_MAX（价差趋势化，不再均值回归）
+//   - CUSUM: 最近 CUSUM_HOLD 次行情内检测到价差均值突变
+//
+// 判据由 pkg/regime 实现，golang/pkg/indicators 的 VolatilityRegime / VarianceRatio / CUSUM 共用同一实现
+type RegimeDetector struct {
+	Vol   *regime.VolHMM
+	VR    *regime.VarianceRatio
+	Cusum *regime.CUSUM
+
+	HighProb float64
+	HurstMax float64
+	Hold     int
+
+	High bool
+}
+
+// NewRegimeDetector 从阈值配置创建 RegimeDetector
+func NewRegimeDetector(thold *types.ThresholdSet) *RegimeDetector {
+	rd := &RegimeDetector{}
+	rd.Configure(thold)
+	return rd
+}
+
+// Configure 根据阈值配置（重新）初始化，状态清零
+func (rd *RegimeDetector) Configure(thold *types.ThresholdSet) {
+	// 状态保持概率 0.98、3 状态低波动倍数 0.4 与 golang VolatilityRegime 默认值一致
+	rd.Vol = regime.NewVolHMM(thold.RegimeStates, thold.RegimePeriod, 0.98, 0.4, thold.RegimeHighMult)
+	rd.VR = regime.NewVarianceRatio(thold.HurstPeriod, thold.HurstLag)
+	rd.Cusum = regime.NewCUSUM(thold.CusumThresh, thold.CusumDrift, thold.RegimePeriod)
+	rd.HighProb = thold.RegimeHighProb
+	rd.HurstMax = thold.HurstMax
+	rd.Hold = thold.CusumHold
+	rd.High = false
+}
+
+// Update 输入一次价差观测并重新判定状态
+func (rd *RegimeDetector) Update(spread float64) {
+	rd.Vol.Update(spread)
+	rd.VR.Update(spread)
+	rd.Cusum.Update(spread)
+
+	rd.High = rd.VolHigh() || rd.Trending() || rd.ChangePoint()
+}
+
+// VolHigh HMM 判定为高波动
+func (rd *RegimeDetector) VolHigh() bool {
+	return rd.Vol.Ready() && rd.Vol.HighProb() >= rd.HighProb
+}
+
+// Trending 方差比判定价差趋势化
+func (rd *RegimeDetector) Trending() bool {
+	return rd.HurstMax > 0 && rd.VR.Ready() && rd.VR.Hurst() > rd.HurstMax
+}
+
+// ChangePoint CUSUM 在 Hold 次行情内检测到突变
+func (rd *RegimeDetector) ChangePoint() bool {
+	return rd.Hold > 0 && rd.Cusum.ChangedWithin(rd.Hold)
+}
+
+// HighProbability 返回 HMM 后验 P(high)
+func (rd *RegimeDetector) HighProbability() float64 {
+	return rd.Vol.HighProb()
+}
+
+// Hurst 返回 Hurst 指数估计
+func (rd *RegimeDetector) Hurst() float64 {
+	return rd.VR.Hurst()
+}
@@ -0,0 +1,195 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+
+	"tbsrc-golang/pkg/types"
+)
+
+// regimeNoise 确定性近似正态噪声（xorshift32，12 个均匀分布求和）
+func regimeNoise(n int, seed uint32) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		s := 0.0
+		for j := 0; j < 12; j++ {
+			seed ^= seed << 13
+			seed ^= seed >> 17
+			seed ^= seed << 5
+			s += float64(seed) / float64(math.MaxUint32)
+		}
+		out[i] = s - 6
+	}
+	return out
+}
+
+func newRegimeThold() *types.ThresholdSet {
+	thold := types.NewThresholdSet()
+	thold.UseRegimeThold = true
+	thold.RegimePeriod = 50
+	thold.HurstPeriod = 100
+	return thold
+}
+
+func TestRegimeDetector_VolatilityBurst(t *testing.T) {
+	thold := newRegimeThold()
+	thold.HurstMax = 0
+	thold.CusumHold = 0
+	rd := NewRegimeDetector(thold)
+	noise := regimeNoise(300, 21)
+
+	for i := 0; i < 200; i++ {
+		rd.Update(10 + 0.5*noise[i])
+	}
+	if rd.High {
+		t.Fatalf("calm period: High = true, P(high) = %.3f", rd.HighProbability())
+	}
+	for i := 200; i < 230; i++ {
+		rd.Update(10 + 5*noise[i])
+	}
+	if !rd.High || !rd.VolHigh() {
+		t.Errorf("burst: High = %v, P(high) = %.3f, want high", rd.High, rd.HighProbability())
+	}
+}
+
+func TestRegimeDetector_Hurst(t *testing.T) {
+	thold := newRegimeThold()
+	thold.RegimeHighProb = 1.1 // 禁用波动率判据
+	thold.CusumHold = 0
+	rd := NewRegimeDetector(thold)
+	noise := regimeNoise(400, 9)
+
+	// AR(1) 均值回归价差
+	x := 0.0
+	for i := 0; i < 200; i++ {
+		x = 0.3*x + noise[i]
+		rd.Update(x)
+	}
+	if rd.Trending() {
+		t.Errorf("mean-reverting: Hurst = %.3f, want < %.2f", rd.Hurst(), thold.HurstMax)
+	}
+
+	// 增量正相关 → 趋势
+	d := 0.0
+	for i := 200; i < 400; i++ {
+		d = 0.6*d + noise[i]
+		x += d
+		rd.Update(x)
+	}
+	if !rd.Trending() || !rd.High {
+		t.Errorf("trending: Hurst = %.3f, High = %v, want trending", rd.Hurst(), rd.High)
+	}
+}
+
+func TestRegimeDetector_ChangePoint(t *testing.T) {
+	thold := newRegimeThold()
+	thold.RegimeHighProb = 1.1
+	thold.HurstMax = 0
+	thold.CusumHold = 20
+	rd := NewRegimeDetector(thold)
+	noise := regimeNoise(200, 29)
+
+	for i := 0; i < 100; i++ {
+		rd.Update(noise[i])
+	}
+	if rd.High {
+		t.Fatal("stationary: High = true")
+	}
+	for i := 100; i < 110; i++ {
+		rd.Update(4 + noise[i])
+	}
+	if !rd.ChangePoint() || rd.Cusum.LastChange() != 1 {
+		t.Fatalf("mean shift: ChangePoint = %v, lastChange = %d", rd.ChangePoint(), rd.Cusum.LastChange())
+	}
+	for i := 110; i < 200; i++ {
+		rd.Update(4 + noise[i])
+	}
+	if rd.High {
+		t.Errorf("after hold: High = true, barsSince = %d", rd.Cusum.BarsSinceChange())
+	}
+}
+
+func TestSetThresholds_RegimeHigh(t *testing.T) {
+	pas := newTestPAS()
+	pas.Thold1.BeginPlaceHigh = 3.0
+	pas.Thold1.LongPlaceHigh = 4.0
+	pas.Regime = NewRegimeDetector(pas.Thold1)
+	pas.Leg1.State.NetposPass = 30 // 30/75 = 0.4
+
+	// 普通状态 — 与 C++ 一致
+	pas.setThresholds()
+	state := pas.Leg1.State
+	if state.SetHigh != 0 {
+		t.Errorf("SetHigh = %d, want 0", state.SetHigh)
+	}
+	expected := 2.0 + (3.0-2.0)*30.0/75.0
+	if math.Abs(state.TholdBidPlace-expected) > 1e-9 {
+		t.Errorf("TholdBidPlace = %f, want %f", state.TholdBidPlace, expected)
+	}
+
+	// 高状态 — BEGIN_PLACE_HIGH / LONG_PLACE_HIGH
+	pas.Regime.High = true
+	pas.setThresholds()
+	if state.SetHigh != 1 {
+		t.Errorf("SetHigh = %d, want 1", state.SetHigh)
+	}
+	expected = 3.0 + (4.0-3.0)*30.0/75.0
+	if math.Abs(state.TholdBidPlace-expected) > 1e-9 {
+		t.Errorf("TholdBidPlace = %f, want %f", state.TholdBidPlace, expected)
+	}
+	expected = 3.0 - (3.0-1.5)*30.0/75.0
+	if math.Abs(state.TholdAskPlace-expected) > 1e-9 {
+		t.Errorf("TholdAskPlace = %f, want %f", state.TholdAskPlace, expected)
+	}
+	// 撤单阈值不受影响
+	expected = 1.0 + (2.0-1.0)*30.0/75.0
+	if math.Abs(state.TholdBidRemove-expected) > 1e-9 {
+		t.Errorf("TholdBidRemove = %f, want %f", state.TholdBidRemove, expected)
+	}
+
+	// 空仓
+	pas.Leg1.State.NetposPass = 0
+	pas.setThresholds()
+	if state.TholdBidPlace != 3.0 || state.TholdAskPlace != 3.0 {
+		t.Errorf("flat: TholdBidPlace = %f, TholdAskPlace = %f, want 3.0", state.TholdBidPlace, state.TholdAskPlace)
+	}
+}
+
+func TestSetThresholds_RegimeHighUnset(t *testing.T) {
+	pas := newTestPAS()
+	pas.Regime = NewRegimeDetector(pas.Thold1)
+	pas.Regime.High = true
+	pas.Leg1.State.NetposPass = 0
+
+	// BEGIN_PLACE_HIGH 未配置时保持 BEGIN_PLACE
+	pas.setThresholds()
+	if pas.Leg1.State.TholdBidPlace != pas.Thold1.BeginPlace {
+		t.Errorf("TholdBidPlace = %f, want %f", pas.Leg1.State.TholdBidPlace, pas.Thold1.BeginPlace)
+	}
+}
+
+func TestReloadThresholds_Regime(t *testing.T) {
+	pas := newTestPAS()
+	if pas.Regime != nil {
+		t.Fatal("Regime should be nil by default")
+	}
+
+	pas.ReloadThresholds(map[string]float64{"use_regime_thold": 1, "regime_high_prob": 0.8}, nil)
+	if pas.Regime == nil {
+		t.Fatal("Regime should be created after enabling use_regime_thold")
+	}
+	rd := pas.Regime
+
+	pas.ReloadThresholds(map[string]float64{"hurst_max": 0.6}, nil)
+	if pas.Regime != rd {
+		t.Error("Regime should be kept across reloads")
+	}
+	if rd.HurstMax != 0.6 || rd.HighProb != 0.8 {
+		t.Errorf("HurstMax = %f, HighProb = %f, want 0.6, 0.8", rd.HurstMax, rd.HighProb)
+	}
+
+	pas.ReloadThresholds(map[string]float64{"use_regime_thold": 0}, nil)
+	if pas.Regime != nil {
+		t.Error("Regime should be removed after disabling use_regime_thold")
+	}
+}
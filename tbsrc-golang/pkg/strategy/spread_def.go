@@ -0,0 +1,183 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"tbsrc-golang/pkg/instrument"
+)
+
+// 价差形式（Go 扩展，C++ 只有 mid1 - mid2）
+// 对应 ThresholdSet.SpreadType (SPREAD_TYPE)
+const (
+	SpreadTypeDiff  = 0 // Σ w_i * p_i（两腿即 C++ 的 mid1 - mid2）
+	SpreadTypeRatio = 1 // Σ_{w>0} w_i*p_i / Σ_{w<0} |w_i|*p_i（两腿即 mid1 / mid2）
+	SpreadTypeLog   = 2 // Σ w_i * ln(p_i)（两腿即 ln(mid1 / mid2)）
+)
+
+// SpreadLeg 价差的一条腿
+type SpreadLeg struct {
+	Inst   *instrument.Instrument
+	Weight float64 // 价差系数（带符号），如日历蝶式 +1 / -2 / +1
+	Ratio  int32   // 每单位价差的下单手数（带符号），0 表示取 round(Weight)
+}
+
+// SpreadDef N 腿价差定义（蝶式、跨品种组合）
+// 把多条腿作为一个对象：一个价差值、一个价差持仓（单位数）、一个净货值敞口
+// 货值使用 Instrument.PriceMultiplier（C++: m_priceMultiplier）
+type SpreadDef struct {
+	Type int
+	Legs []SpreadLeg
+}
+
+// newSpreadDef 创建并校验价差定义
+// tbsrc 策略目前只有两腿（PairwiseArb 经 NewPairSpreadDef 使用）；
+// 固定权重的 N 腿价差（蝶式、跨品种组合）由 golang basket_arb 的 weights 参数配置
+func newSpreadDef(spreadType int, legs []SpreadLeg) (*SpreadDef, error) {
+	if len(legs) < 2 {
+		return nil, fmt.Errorf("spread needs at least 2 legs, got %d", len(legs))
+	}
+	if spreadType < SpreadTypeDiff || spreadType > SpreadTypeLog {
+		return nil, fmt.Errorf("unknown spread type %d", spreadType)
+	}
+	d := &SpreadDef{Type: spreadType, Legs: make([]SpreadLeg, len(legs))}
+	hasPos, hasNeg := false, false
+	for i, leg := range legs {
+		if leg.Inst == nil {
+			return nil, fmt.Errorf("spread leg %d has no instrument", i)
+		}
+		if leg.Weight == 0 {
+			return nil, fmt.Errorf("spread leg %s has zero weight", leg.Inst.Symbol)
+		}
+		if leg.Ratio == 0 {
+			leg.Ratio = int32(math.Round(leg.Weight))
+		}
+		if leg.Ratio == 0 || (leg.Ratio > 0) != (leg.Weight > 0) {
+			return nil, fmt.Errorf("spread leg %s ratio %d inconsistent with weight %g",
+				leg.Inst.Symbol, leg.Ratio, leg.Weight)
+		}
+		if leg.Weight > 0 {
+			hasPos = true
+		} else {
+			hasNeg = true
+		}
+		d.Legs[i] = leg
+	}
+	if spreadType == SpreadTypeRatio && (!hasPos || !hasNeg) {
+		return nil, fmt.Errorf("ratio spread needs both positive and negative legs")
+	}
+	return d, nil
+}
+
+// NewPairSpreadDef 两腿价差（C++ PairwiseArb: leg1 - leg2，下单 1:-1）
+func NewPairSpreadDef(spreadType int, inst1, inst2 *instrument.Instrument) (*SpreadDef, error) {
+	return newSpreadDef(spreadType, []SpreadLeg{
+		{Inst: inst1, Weight: 1, Ratio: 1},
+		{Inst: inst2, Weight: -1, Ratio: -1},
+	})
+}
+
+// Value 按各腿价格计算价差值，prices 与 Legs 一一对应；任一腿价格无效返回 false
+func (d *SpreadDef) Value(prices []float64) (float64, bool) {
+	if len(prices) != len(d.Legs) {
+		return 0, false
+	}
+	var sum, num, den float64
+	for i := range d.Legs {
+		p := prices[i]
+		if p <= 0 {
+			return 0, false
+		}
+		w := d.Legs[i].Weight
+		switch d.Type {
+		case SpreadTypeRatio:
+			if w > 0 {
+				num += w * p
+			} else {
+				den -= w * p
+			}
+		case SpreadTypeLog:
+			sum += w * math.Log(p)
+		default:
+			sum += w * p
+		}
+	}
+	if d.Type == SpreadTypeRatio {
+		return num / den, true
+	}
+	return sum, true
+}
+
+// legMid 第 i 腿一档 mid 价，无有效行情返回 0
+func (d *SpreadDef) legMid(i int) float64 {
+	inst := d.Legs[i].Inst
+	if inst.BidPx[0] <= 0 || inst.AskPx[0] <= 0 {
+		return 0
+	}
+	return (inst.BidPx[0] + inst.AskPx[0]) / 2
+}
+
+// Units 已配齐的价差单位数（带符号）：各腿 netpos_i / Ratio_i 同号时取绝对值最小者，否则为 0
+func (d *SpreadDef) Units(netpos []int32) int32 {
+	if len(netpos) != len(d.Legs) {
+		return 0
+	}
+	var units int32
+	for i := range d.Legs {
+		u := netpos[i] / d.Legs[i].Ratio
+		if u == 0 {
+			return 0
+		}
+		if i == 0 {
+			units = u
+			continue
+		}
+		if (u > 0) != (units > 0) {
+			return 0
+		}
+		if absInt32(u) < absInt32(units) {
+			units = u
+		}
+	}
+	return units
+}
+
+// LegImbalance 第 i 腿相对于已配齐单位数的多余手数（瘸腿敞口）
+func (d *SpreadDef) LegImbalance(netpos []int32, i int) int32 {
+	if i < 0 || i >= len(d.Legs) || len(netpos) != len(d.Legs) {
+		return 0
+	}
+	return netpos[i] - d.Units(netpos)*d.Legs[i].Ratio
+}
+
+// ExposureValue 按一档 mid 价计算净货值敞口 Σ netpos_i * mid_i * PriceMultiplier_i（带符号）
+// 完全对冲的价差持仓（同品种跨期）接近 0
+func (d *SpreadDef) ExposureValue(netpos []int32) float64 {
+	if len(netpos) != len(d.Legs) {
+		return 0
+	}
+	net := 0.0
+	for i := range d.Legs {
+		net += float64(netpos[i]) * d.legMid(i) * d.Legs[i].Inst.PriceMultiplier
+	}
+	return net
+}
+
+// GrossExposureValue 各腿货值绝对值之和
+func (d *SpreadDef) GrossExposureValue(netpos []int32) float64 {
+	if len(netpos) != len(d.Legs) {
+		return 0
+	}
+	gross := 0.0
+	for i := range d.Legs {
+		gross += float64(absInt32(netpos[i])) * d.legMid(i) * d.Legs[i].Inst.PriceMultiplier
+	}
+	return gross
+}
+
+func absInt32(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,147 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpreadDef_Butterfly(t *testing.T) {
+	a := newTestInstrument("ag2506", 1.0, 15)
+	b := newTestInstrument("ag2508", 1.0, 15)
+	c := newTestInstrument("ag2510", 1.0, 15)
+	def, err := newSpreadDef(SpreadTypeDiff, []SpreadLeg{
+		{Inst: a, Weight: 1},
+		{Inst: b, Weight: -2},
+		{Inst: c, Weight: 1},
+	})
+	if err != nil {
+		t.Fatalf("newSpreadDef: %v", err)
+	}
+	if def.Legs[1].Ratio != -2 {
+		t.Errorf("Ratio = %d, want -2 (round(weight))", def.Legs[1].Ratio)
+	}
+
+	v, ok := def.Value([]float64{5800, 5810, 5830})
+	if !ok || v != 10 {
+		t.Errorf("Value = %f (%v), want 10", v, ok)
+	}
+	if _, ok := def.Value([]float64{5800, 0, 5830}); ok {
+		t.Error("Value should be invalid when a leg has no price")
+	}
+
+	// 2 个完整蝶式 + 第 3 腿多 1 手
+	netpos := []int32{2, -4, 3}
+	if u := def.Units(netpos); u != 2 {
+		t.Errorf("Units = %d, want 2", u)
+	}
+	if imb := def.LegImbalance(netpos, 2); imb != 1 {
+		t.Errorf("LegImbalance = %d, want 1", imb)
+	}
+	if u := def.Units([]int32{2, 4, 2}); u != 0 {
+		t.Errorf("Units with mismatched signs = %d, want 0", u)
+	}
+}
+
+func TestSpreadDef_RatioAndLog(t *testing.T) {
+	inst1 := newTestInstrument("rb2510", 1.0, 10)
+	inst2 := newTestInstrument("hc2510", 1.0, 10)
+
+	ratio, err := NewPairSpreadDef(SpreadTypeRatio, inst1, inst2)
+	if err != nil {
+		t.Fatalf("NewPairSpreadDef: %v", err)
+	}
+	if v, _ := ratio.Value([]float64{3600, 3500}); math.Abs(v-3600.0/3500.0) > 1e-12 {
+		t.Errorf("ratio Value = %f, want %f", v, 3600.0/3500.0)
+	}
+
+	logDef, _ := NewPairSpreadDef(SpreadTypeLog, inst1, inst2)
+	if v, _ := logDef.Value([]float64{3600, 3500}); math.Abs(v-math.Log(3600.0/3500.0)) > 1e-12 {
+		t.Errorf("log Value = %f, want %f", v, math.Log(3600.0/3500.0))
+	}
+}
+
+func TestSpreadDef_Validation(t *testing.T) {
+	a := newTestInstrument("a", 1.0, 1)
+	b := newTestInstrument("b", 1.0, 1)
+	cases := []struct {
+		name string
+		typ  int
+		legs []SpreadLeg
+	}{
+		{"one leg", SpreadTypeDiff, []SpreadLeg{{Inst: a, Weight: 1}}},
+		{"zero weight", SpreadTypeDiff, []SpreadLeg{{Inst: a, Weight: 1}, {Inst: b, Weight: 0}}},
+		{"ratio sign", SpreadTypeDiff, []SpreadLeg{{Inst: a, Weight: 1}, {Inst: b, Weight: -1, Ratio: 1}}},
+		{"ratio one-sided", SpreadTypeRatio, []SpreadLeg{{Inst: a, Weight: 1}, {Inst: b, Weight: 1}}},
+		{"unknown type", 7, []SpreadLeg{{Inst: a, Weight: 1}, {Inst: b, Weight: -1}}},
+		{"nil inst", SpreadTypeDiff, []SpreadLeg{{Inst: a, Weight: 1}, {Weight: -1}}},
+	}
+	for _, c := range cases {
+		if _, err := newSpreadDef(c.typ, c.legs); err == nil {
+			t.Errorf("%s: expected error", c.name)
+		}
+	}
+}
+
+func TestSpreadDef_ExposureValue(t *testing.T) {
+	pas := newTestPAS()
+	pas.Def, _ = NewPairSpreadDef(SpreadTypeDiff, pas.Inst1, pas.Inst2)
+
+	// mid1 = 5810.5, mid2 = 5800.5, PriceMultiplier = 15
+	pas.Leg1.State.NetposPass = 3
+	pas.Leg2.State.NetposAgg = -3
+	net, gross := pas.ExposureValue()
+	if math.Abs(net-3*10*15) > 1e-9 {
+		t.Errorf("net = %f, want %f", net, 3.0*10*15)
+	}
+	if math.Abs(gross-3*(5810.5+5800.5)*15) > 1e-9 {
+		t.Errorf("gross = %f, want %f", gross, 3*(5810.5+5800.5)*15)
+	}
+
+	def, _ := NewPairSpreadDef(SpreadTypeDiff, pas.Inst1, newTestInstrument("x", 1.0, 1))
+	if v := def.ExposureValue([]int32{1, -1}); v != 5810.5*15 {
+		t.Errorf("ExposureValue without leg2 book = %f, want %f", v, 5810.5*15)
+	}
+}
+
+func TestSpreadTracker_RatioType(t *testing.T) {
+	st := NewSpreadTracker(0.1, 1.0, 20)
+	st.Type = SpreadTypeRatio
+
+	if !st.Update(5050, 5000, true) {
+		t.Fatal("should be valid")
+	}
+	if math.Abs(st.CurrSpread-1.01) > 1e-12 {
+		t.Errorf("CurrSpread = %f, want 1.01", st.CurrSpread)
+	}
+	if math.Abs(st.AvgSpread-1.01) > 1e-12 {
+		t.Errorf("AvgSpread = %f, want 1.01 (auto-init)", st.AvgSpread)
+	}
+
+	// AVG_SPREAD_AWAY 换算为比值单位：20 tick / 5000 = 0.004，偏离 0.002 不触发
+	if !st.Update(5060, 5000, true) {
+		t.Error("deviation within AVG_SPREAD_AWAY should be valid")
+	}
+	// 偏离 0.01 > 0.004 触发
+	if st.Update(5110, 5000, true) {
+		t.Error("deviation beyond AVG_SPREAD_AWAY should be invalid")
+	}
+}
+
+func TestReloadThresholds_SpreadType(t *testing.T) {
+	pas := newTestPAS()
+	pas.ReloadThresholds(map[string]float64{"spread_type": 1, "spread_mode": 1}, nil)
+	if pas.Spread.Type != SpreadTypeRatio || pas.Def == nil || pas.Def.Type != SpreadTypeRatio {
+		t.Fatalf("Spread.Type = %d, want ratio", pas.Spread.Type)
+	}
+	if pas.Spread.Initialized {
+		t.Error("EWA should re-initialize after spread type change")
+	}
+	if pas.Spread.Mode != SpreadModeEWA {
+		t.Errorf("Mode = %d, kalman should be ignored for ratio spread", pas.Spread.Mode)
+	}
+
+	pas.ReloadThresholds(map[string]float64{"spread_type": 9}, nil)
+	if pas.Spread.Type != SpreadTypeRatio {
+		t.Errorf("invalid spread_type should keep ratio, got %d", pas.Spread.Type)
+	}
+}
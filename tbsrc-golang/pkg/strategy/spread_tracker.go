@@ -17,8 +17,35 @@ type SpreadTracker struct {
 	AvgSpreadAway int32   // C++: m_thold_first->AVG_SPREAD_AWAY (default 20)
 	IsValid       bool    // C++: is_valid_mkdata — false if spread deviates too far
 	Initialized   bool    // false until first Update call
+	Type          int     // SPREAD_TYPE: SpreadTypeDiff (C++) / SpreadTypeRatio / SpreadTypeLog
+
+	// 卡尔曼滤波动态对冲比率（Go 扩展，SPREAD_MODE=1）
+	// 模型: mid1 = Intercept + Beta*mid2 + e，[Intercept, Beta] 随机游走
+	// CurrSpread = mid1 - Beta*mid2，AvgSpreadOri = Intercept（替代 EWA）
+	// EWA 模式下 Beta 恒为 1，与 C++ 完全一致
+	Mode        int
+	Beta        float64       // 对冲比率
+	Residual    float64       // 最近一次新息 e = mid1 - Intercept - Beta*mid2（先验）
+	ResidualVar float64       // 新息方差 S
+	QAlpha      float64       // KALMAN_Q_ALPHA
+	QBeta       float64       // KALMAN_Q_BETA
+	R           float64       // KALMAN_R
+	P           [2][2]float64 // [Intercept, Beta] 协方差
+	lastMid2    float64
 }
 
+// 价差模式
+const (
+	SpreadModeEWA    = 0 // C++: avgSpreadRatio_ori EWA
+	SpreadModeKalman = 1 // 卡尔曼滤波动态对冲比率
+)
+
+// 卡尔曼初始状态不确定性（与 golang/pkg/stats.KalmanRegression 一致）
+const (
+	kalmanInitInterceptVar = 1e4
+	kalmanInitBetaVar      = 1e-2
+)
+
 // NewSpreadTracker 创建 SpreadTracker
 func NewSpreadTracker(alpha float64, tickSize float64, avgSpreadAway int32) *SpreadTracker {
 	if avgSpreadAway <= 0 {
@@ -29,7 +56,61 @@ func NewSpreadTracker(alpha float64, tickSize float64, avgSpreadAway int32) *Spr
 		TickSize:      tickSize,
 		AvgSpreadAway: avgSpreadAway,
 		IsValid:       true,
+		Beta:          1,
+	}
+}
+
+// EnableKalman 切换到卡尔曼模式；已处于卡尔曼模式时只更新噪声参数，保留滤波状态
+// 截距从当前 AvgSpreadOri（daily_init 种子或 EWA）开始
+func (st *SpreadTracker) EnableKalman(qAlpha, qBeta, r float64) {
+	st.QAlpha = qAlpha
+	st.QBeta = qBeta
+	st.R = r
+	if st.Mode == SpreadModeKalman {
+		return
+	}
+	st.Mode = SpreadModeKalman
+	st.Beta = 1
+	st.P = [2][2]float64{{kalmanInitInterceptVar, 0}, {0, kalmanInitBetaVar}}
+}
+
+// DisableKalman 回到 EWA 模式（Beta = 1），EWA 从当前截距继续
+func (st *SpreadTracker) DisableKalman() {
+	st.Mode = SpreadModeEWA
+	st.Beta = 1
+	st.Residual = 0
+	st.ResidualVar = 0
+}
+
+// SeedValue 返回写入 daily_init 的 avgSpreadRatio_ori
+// 卡尔曼模式下换算为 Beta=1 口径（mid1 - mid2 的均值水平），重启后 Seed 时与 Beta=1 的初始状态一致
+func (st *SpreadTracker) SeedValue() float64 {
+	if st.Mode != SpreadModeKalman {
+		return st.AvgSpreadOri
+	}
+	return st.AvgSpreadOri + (st.Beta-1)*st.lastMid2
+}
+
+// Combine 按价差形式组合两腿价格
+//
+//	diff: p1 - Beta*p2（EWA 模式 Beta=1，等价于 C++ 的 p1 - p2）
+//	ratio: p1 / p2，log: ln(p1 / p2)
+//
+// 所有 "leg1 价格 - leg2 价格" 的价差比较都应通过 Combine
+func (st *SpreadTracker) Combine(p1, p2 float64) float64 {
+	switch st.Type {
+	case SpreadTypeRatio:
+		if p2 <= 0 {
+			return 0
+		}
+		return p1 / p2
+	case SpreadTypeLog:
+		if p1 <= 0 || p2 <= 0 {
+			return 0
+		}
+		return math.Log(p1 / p2)
 	}
+	return p1 - st.Beta*p2
 }
 
 // Seed 从 daily_init 文件初始化 EWA 种子值
@@ -58,8 +139,29 @@ func (st *SpreadTracker) SetTValue(v float64) {
 //  4. avgSpreadRatio = avgSpreadRatio_ori + tValue
 //
 // isLeg1Update: 仅在 leg1 行情更新时才刷新 EWA（C++ 行为）
+//
+// 卡尔曼模式: 步骤 3 改为滤波更新 [Intercept, Beta]，AvgSpreadOri = Intercept，
+// CurrSpread 按更新后的 Beta 重新计算
 func (st *SpreadTracker) Update(mid1, mid2 float64, isLeg1Update bool) bool {
-	st.CurrSpread = mid1 - mid2
+	st.lastMid2 = mid2
+	kalman := st.Mode == SpreadModeKalman
+	if !st.UpdateValue(st.Combine(mid1, mid2), mid2, isLeg1Update && !kalman) {
+		return false
+	}
+
+	if kalman && isLeg1Update {
+		st.kalmanStep(mid1, mid2)
+		st.CurrSpread = st.Combine(mid1, mid2)
+		st.AvgSpread = st.AvgSpreadOri + st.TValue
+	}
+	return true
+}
+
+// UpdateValue 用已算好的价差值更新（N 腿价差由 SpreadDef.Value 计算后调用），返回 true 如果价差有效
+// refPrice: ratio/log 价差下把 AVG_SPREAD_AWAY 的 tick 限额换算到价差单位（tick / refPrice），diff 价差忽略
+// refreshAvg: 是否刷新 EWA（C++: 仅 leg1 行情）
+func (st *SpreadTracker) UpdateValue(spread, refPrice float64, refreshAvg bool) bool {
+	st.CurrSpread = spread
 
 	// 首次更新时用当前价差初始化 EWA
 	if !st.Initialized {
@@ -72,6 +174,9 @@ func (st *SpreadTracker) Update(mid1, mid2 float64, isLeg1Update bool) bool {
 	// 参考: PairwiseArbStrategy.cpp:506-517
 	deviation := math.Abs(st.CurrSpread - st.AvgSpread)
 	maxDeviation := st.TickSize * float64(st.AvgSpreadAway)
+	if st.Type != SpreadTypeDiff && refPrice > 0 {
+		maxDeviation /= refPrice
+	}
 	if maxDeviation > 0 && deviation > maxDeviation {
 		st.IsValid = false
 		return false
@@ -80,7 +185,7 @@ func (st *SpreadTracker) Update(mid1, mid2 float64, isLeg1Update bool) bool {
 
 	// C++: EWA 仅在 leg1 行情更新时刷新
 	// 参考: PairwiseArbStrategy.cpp:519-523
-	if isLeg1Update && st.Alpha > 0 {
+	if refreshAvg && st.Alpha > 0 {
 		st.AvgSpreadOri = (1-st.Alpha)*st.AvgSpreadOri + st.Alpha*st.CurrSpread
 		st.AvgSpread = st.AvgSpreadOri + st.TValue
 	}
@@ -88,6 +193,35 @@ func (st *SpreadTracker) Update(mid1, mid2 float64, isLeg1Update bool) bool {
 	return true
 }
 
+// kalmanStep 卡尔曼滤波一步，状态 [AvgSpreadOri(截距), Beta]，观测 y=mid1, H=[1, mid2]
+func (st *SpreadTracker) kalmanStep(y, x float64) {
+	st.P[0][0] += st.QAlpha
+	st.P[1][1] += st.QBeta
+
+	e := y - st.AvgSpreadOri - st.Beta*x
+	ph0 := st.P[0][0] + st.P[0][1]*x
+	ph1 := st.P[1][0] + st.P[1][1]*x
+	s := ph0 + ph1*x + st.R
+	if s <= 0 || math.IsNaN(s) || math.IsInf(s, 0) {
+		return
+	}
+
+	k0 := ph0 / s
+	k1 := ph1 / s
+	st.AvgSpreadOri += k0 * e
+	st.Beta += k1 * e
+	st.P[0][0] -= k0 * ph0
+	st.P[0][1] -= k0 * ph1
+	st.P[1][0] -= k1 * ph0
+	st.P[1][1] -= k1 * ph1
+	off := 0.5 * (st.P[0][1] + st.P[1][0])
+	st.P[0][1] = off
+	st.P[1][0] = off
+
+	st.Residual = e
+	st.ResidualVar = s
+}
+
 // Deviation 返回当前价差相对于 EWA 的偏差
 func (st *SpreadTracker) Deviation() float64 {
 	return st.CurrSpread - st.AvgSpread
@@ -160,3 +160,85 @@ func TestSpreadTracker_DefaultAvgSpreadAway(t *testing.T) {
 		t.Errorf("AvgSpreadAway = %d, want 20 (default)", st.AvgSpreadAway)
 	}
 }
+
+func TestSpreadTracker_CombineEWA(t *testing.T) {
+	st := NewSpreadTracker(0.1, 1.0, 20)
+	if st.Beta != 1 {
+		t.Errorf("Beta = %f, want 1", st.Beta)
+	}
+	if got := st.Combine(5815.0, 5805.0); got != 10.0 {
+		t.Errorf("Combine = %f, want 10.0", got)
+	}
+	st.Seed(10.0)
+	st.Update(5815.0, 5805.0, true)
+	if st.SeedValue() != st.AvgSpreadOri {
+		t.Errorf("SeedValue = %f, want AvgSpreadOri %f", st.SeedValue(), st.AvgSpreadOri)
+	}
+}
+
+func TestSpreadTracker_Kalman(t *testing.T) {
+	st := NewSpreadTracker(0.01, 1.0, 1000)
+	st.EnableKalman(1e-4, 1e-8, 1.0)
+	if st.Mode != SpreadModeKalman {
+		t.Fatalf("Mode = %d, want %d", st.Mode, SpreadModeKalman)
+	}
+
+	// mid1 = 20 + beta*mid2，beta 从 1.00 漂移到 1.01
+	beta := 1.0
+	mid1, mid2 := 0.0, 0.0
+	for i := 0; i < 3000; i++ {
+		beta = 1.0 + 0.01*float64(i)/3000
+		mid2 = 5000 + 100*math.Sin(float64(i)/40)
+		mid1 = 20 + beta*mid2 + 0.5*math.Sin(float64(i)*1.7)
+		if !st.Update(mid1, mid2, true) {
+			t.Fatalf("step %d: unexpected invalid spread, curr=%f avg=%f", i, st.CurrSpread, st.AvgSpread)
+		}
+		// leg2 行情不刷新滤波
+		st.Update(mid1, mid2, false)
+	}
+
+	if math.Abs(st.Beta-beta) > 0.003 {
+		t.Errorf("Beta = %f, want %f", st.Beta, beta)
+	}
+	if math.Abs(st.Deviation()) > 3 {
+		t.Errorf("Deviation = %f, want ~0", st.Deviation())
+	}
+	if st.ResidualVar <= 0 {
+		t.Errorf("ResidualVar = %f, want > 0", st.ResidualVar)
+	}
+	// daily_init 写 Beta=1 口径的价差水平
+	if math.Abs(st.SeedValue()-(mid1-mid2)) > 3 {
+		t.Errorf("SeedValue = %f, want ~%f", st.SeedValue(), mid1-mid2)
+	}
+	if got, want := st.Combine(mid1, mid2), mid1-st.Beta*mid2; got != want {
+		t.Errorf("Combine = %f, want %f", got, want)
+	}
+
+	st.DisableKalman()
+	if st.Mode != SpreadModeEWA || st.Beta != 1 {
+		t.Errorf("after DisableKalman: Mode = %d, Beta = %f", st.Mode, st.Beta)
+	}
+}
+
+func TestReloadThresholds_SpreadMode(t *testing.T) {
+	pas := newTestPAS()
+	if pas.Spread.Mode != SpreadModeEWA {
+		t.Fatalf("Mode = %d, want EWA", pas.Spread.Mode)
+	}
+
+	pas.ReloadThresholds(map[string]float64{"spread_mode": 1, "kalman_r": 4}, nil)
+	if pas.Spread.Mode != SpreadModeKalman || pas.Spread.R != 4 {
+		t.Fatalf("Mode = %d, R = %f, want kalman, 4", pas.Spread.Mode, pas.Spread.R)
+	}
+	pas.Spread.Beta = 1.002 // 模拟滤波状态
+
+	pas.ReloadThresholds(map[string]float64{"kalman_q_beta": 1e-7}, nil)
+	if pas.Spread.Beta != 1.002 || pas.Spread.QBeta != 1e-7 {
+		t.Errorf("Beta = %f, QBeta = %g, want filter state kept", pas.Spread.Beta, pas.Spread.QBeta)
+	}
+
+	pas.ReloadThresholds(map[string]float64{"spread_mode": 0}, nil)
+	if pas.Spread.Mode != SpreadModeEWA || pas.Spread.Beta != 1 {
+		t.Errorf("Mode = %d, Beta = %f, want EWA, 1", pas.Spread.Mode, pas.Spread.Beta)
+	}
+}
@@ -0,0 +1,81 @@
+package tca
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteSummaryCSV 写出汇总表：每行一个 (维度, key, 流动性) 分组
+func WriteSummaryCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	header := []string{"dimension", "key", "liquidity", "fills", "qty", "avg_time_to_fill_ms",
+		"avg_shortfall", "avg_shortfall_bps", "avg_effective_spread", "avg_realized_spread"}
+	for _, h := range r.Horizons {
+		header = append(header, "markout_"+h)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, g := range []struct {
+		dim  string
+		list []Summary
+	}{{"total", r.Total}, {"symbol", r.BySymbol}, {"strategy", r.ByStrategy}, {"order_type", r.ByOrderType}} {
+		for _, s := range g.list {
+			row := []string{g.dim, s.Key, s.Liquidity, strconv.Itoa(s.Fills), strconv.FormatInt(s.Qty, 10),
+				ftoa(s.AvgTimeToFillMs), ftoa(s.AvgShortfall), ftoa(s.AvgShortfallBps),
+				ftoa(s.AvgEffectiveSpread), ftoa(s.AvgRealizedSpread)}
+			for _, h := range r.Horizons {
+				row = append(row, optional(s.Markouts, h))
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteFillsCSV 写出逐笔成交指标；未到期的 markout 留空
+func WriteFillsCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	header := []string{"order_id", "strategy", "symbol", "order_type", "liquidity", "side", "qty", "price",
+		"order_time", "fill_time", "time_to_fill_ms", "arrival_mid", "arrival_spread", "fill_mid",
+		"shortfall", "shortfall_bps", "effective_spread", "realized_spread"}
+	for _, h := range r.Horizons {
+		header = append(header, "markout_"+h)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, f := range r.Fills {
+		realized := ""
+		if f.RealizedSpread != nil {
+			realized = ftoa(*f.RealizedSpread)
+		}
+		row := []string{f.OrderID, f.Strategy, f.Symbol, f.OrderType, f.Liquidity, f.Side,
+			strconv.Itoa(int(f.Qty)), ftoa(f.Price),
+			f.OrderTime.Format(time.RFC3339Nano), f.FillTime.Format(time.RFC3339Nano), ftoa(f.TimeToFillMs),
+			ftoa(f.ArrivalMid), ftoa(f.ArrivalSpread), ftoa(f.FillMid),
+			ftoa(f.Shortfall), ftoa(f.ShortfallBps), ftoa(f.EffectiveSpread), realized}
+		for _, h := range r.Horizons {
+			row = append(row, optional(f.Markouts, h))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func ftoa(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+
+func optional(m map[string]float64, k string) string {
+	if v, ok := m[k]; ok {
+		return ftoa(v)
+	}
+	return ""
+}
@@ -0,0 +1,414 @@
+// Package tca 成交质量分析（Transaction Cost Analysis，Go 扩展）
+//
+// 下单时记录到达价（arrival mid）和盘口，成交时计算（买卖方向 s=+1/-1，单位为每手价格，正数为成本）：
+//
+//	Shortfall        = s·(price − arrivalMid)        执行差额（implementation shortfall）
+//	EffectiveSpread  = 2·s·(price − mid)             成交时有效价差
+//	RealizedSpread   = 2·s·(price − mid(t+H))        H 后的实现价差（默认 5m）
+//	Markout(h)       = s·(mid(t+h) − price)          成交后 h 的 markout，正数为有利
+//
+// mid(t+h) 取 t+h 之后该品种的第一笔行情；被动/主动按下单时是否穿越对手价判断，
+// 实盘和回测使用同一套口径，结果可以直接对比。
+package tca
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHorizons 默认 markout 观察点
+var DefaultHorizons = []time.Duration{time.Second, 5 * time.Second, 30 * time.Second, 5 * time.Minute}
+
+// 流动性分类
+const (
+	Passive    = "passive"
+	Aggressive = "aggressive"
+	All        = "all"
+)
+
+// Config TCA 配置
+type Config struct {
+	Horizons        []time.Duration // markout 观察点（默认 DefaultHorizons）
+	RealizedHorizon time.Duration   // 实现价差观察点（默认最长的 markout）
+	MaxFills        int             // 报告中保留的最近成交数（默认 1000，汇总不受影响）
+}
+
+func (c Config) withDefaults() Config {
+	if len(c.Horizons) == 0 {
+		c.Horizons = DefaultHorizons
+	}
+	c.Horizons = append([]time.Duration(nil), c.Horizons...)
+	sort.Slice(c.Horizons, func(i, j int) bool { return c.Horizons[i] < c.Horizons[j] })
+	if c.RealizedHorizon <= 0 {
+		c.RealizedHorizon = c.Horizons[len(c.Horizons)-1]
+	}
+	if c.MaxFills <= 0 {
+		c.MaxFills = 1000
+	}
+	return c
+}
+
+// Order 下单时的订单与盘口
+type Order struct {
+	ID        string
+	Strategy  string
+	Symbol    string
+	OrderType string // STANDARD / CROSS / LIMIT ...
+	Buy       bool
+	Qty       int32
+	Price     float64
+	Time      time.Time
+	Bid, Ask  float64 // 下单时一档
+	BidQty    float64
+	AskQty    float64
+}
+
+// crosses 下单价是否穿越对手价（主动单）
+func (o *Order) crosses() bool {
+	if o.Buy {
+		return o.Ask > 0 && o.Price >= o.Ask
+	}
+	return o.Bid > 0 && o.Price <= o.Bid
+}
+
+func (o *Order) mid() float64 {
+	if o.Bid > 0 && o.Ask > 0 {
+		return (o.Bid + o.Ask) / 2
+	}
+	return o.Price
+}
+
+// FillRecord 一笔成交的 TCA 指标
+type FillRecord struct {
+	OrderID         string             `json:"order_id"`
+	Strategy        string             `json:"strategy"`
+	Symbol          string             `json:"symbol"`
+	OrderType       string             `json:"order_type"`
+	Liquidity       string             `json:"liquidity"`
+	Side            string             `json:"side"`
+	Qty             int32              `json:"qty"`
+	Price           float64            `json:"price"`
+	OrderTime       time.Time          `json:"order_time"`
+	FillTime        time.Time          `json:"fill_time"`
+	TimeToFillMs    float64            `json:"time_to_fill_ms"`
+	ArrivalMid      float64            `json:"arrival_mid"`
+	ArrivalSpread   float64            `json:"arrival_spread"` // 下单时盘口价差
+	FillMid         float64            `json:"fill_mid"`
+	Shortfall       float64            `json:"shortfall"`
+	ShortfallBps    float64            `json:"shortfall_bps"`
+	EffectiveSpread float64            `json:"effective_spread"`
+	RealizedSpread  *float64           `json:"realized_spread,omitempty"`
+	Markouts        map[string]float64 `json:"markouts"` // 观察点 → markout，未到期的不出现
+}
+
+// Summary 一组成交的数量加权平均指标
+type Summary struct {
+	Key                string             `json:"key"`
+	Liquidity          string             `json:"liquidity"`
+	Fills              int                `json:"fills"`
+	Qty                int64              `json:"qty"`
+	AvgTimeToFillMs    float64            `json:"avg_time_to_fill_ms"`
+	AvgShortfall       float64            `json:"avg_shortfall"`
+	AvgShortfallBps    float64            `json:"avg_shortfall_bps"`
+	AvgEffectiveSpread float64            `json:"avg_effective_spread"`
+	AvgRealizedSpread  float64            `json:"avg_realized_spread"`
+	Markouts           map[string]float64 `json:"markouts"`
+}
+
+// Report TCA 报告
+type Report struct {
+	Horizons        []string     `json:"horizons"`
+	Total           []Summary    `json:"total"` // passive / aggressive / all
+	BySymbol        []Summary    `json:"by_symbol"`
+	ByStrategy      []Summary    `json:"by_strategy"`
+	ByOrderType     []Summary    `json:"by_order_type"`
+	Fills           []FillRecord `json:"fills"`
+	PendingMarkouts int          `json:"pending_markouts"`
+}
+
+// acc 汇总累加器（数量加权）
+type acc struct {
+	fills                              int
+	qty                                int64
+	ttf, shortfall, bps, eff, realized float64
+	realizedQty                        int64
+	markout                            map[string]float64
+	markoutQty                         map[string]int64
+}
+
+func (a *acc) summary(key, liq string) Summary {
+	s := Summary{Key: key, Liquidity: liq, Fills: a.fills, Qty: a.qty, Markouts: make(map[string]float64)}
+	if a.qty > 0 {
+		q := float64(a.qty)
+		s.AvgTimeToFillMs = a.ttf / q
+		s.AvgShortfall = a.shortfall / q
+		s.AvgShortfallBps = a.bps / q
+		s.AvgEffectiveSpread = a.eff / q
+	}
+	if a.realizedQty > 0 {
+		s.AvgRealizedSpread = a.realized / float64(a.realizedQty)
+	}
+	for h, v := range a.markout {
+		s.Markouts[h] = v / float64(a.markoutQty[h])
+	}
+	return s
+}
+
+type groupKey struct{ dim, key, liq string }
+
+// pending 一个未到期的 markout / 实现价差观察
+type pending struct {
+	fill     *fillState
+	label    string // markout 观察点标签
+	due      time.Time
+	realized bool // 实现价差观察
+}
+
+// fillState 成交及其所属分组（markout 到期时累加到同样的分组）
+type fillState struct {
+	rec    FillRecord
+	groups []*acc
+	buy    bool
+}
+
+// Engine TCA 引擎；并发安全
+type Engine struct {
+	mu      sync.Mutex
+	cfg     Config
+	labels  []string
+	orders  map[string]*Order
+	pending map[string][]pending // symbol → 未到期观察
+	groups  map[groupKey]*acc
+	fills   []*fillState
+	lastMid map[string]float64
+}
+
+// NewEngine 创建 TCA 引擎
+func NewEngine(cfg Config) *Engine {
+	cfg = cfg.withDefaults()
+	e := &Engine{
+		cfg:     cfg,
+		orders:  make(map[string]*Order),
+		pending: make(map[string][]pending),
+		groups:  make(map[groupKey]*acc),
+		lastMid: make(map[string]float64),
+	}
+	for _, h := range cfg.Horizons {
+		e.labels = append(e.labels, HorizonLabel(h))
+	}
+	return e
+}
+
+// HorizonLabel 观察点标签：1s / 30s / 5m / 1h
+func HorizonLabel(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d >= time.Second && d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return d.String()
+}
+
+// OnOrder 记录新订单的到达价和盘口
+func (e *Engine) OnOrder(o Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.orders[o.ID] = &o
+}
+
+// OnOrderDone 订单结束（全部成交 / 撤单 / 拒单），释放到达记录
+func (e *Engine) OnOrderDone(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.orders, id)
+}
+
+// OnFill 记录一笔成交；mid 为成交时本品种 mid（<=0 时取最近一笔行情）
+// 未经 OnOrder 登记的订单（如重启后接管的挂单）忽略
+func (e *Engine) OnFill(id string, t time.Time, qty int32, price, mid float64) {
+	if qty <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o, ok := e.orders[id]
+	if !ok {
+		return
+	}
+	if mid <= 0 {
+		mid = e.lastMid[o.Symbol]
+	}
+	if mid <= 0 {
+		mid = price
+	}
+	s := -1.0
+	side := "sell"
+	if o.Buy {
+		s, side = 1, "buy"
+	}
+	liq := Passive
+	if o.crosses() {
+		liq = Aggressive
+	}
+	arrival := o.mid()
+	rec := FillRecord{
+		OrderID:         o.ID,
+		Strategy:        o.Strategy,
+		Symbol:          o.Symbol,
+		OrderType:       o.OrderType,
+		Liquidity:       liq,
+		Side:            side,
+		Qty:             qty,
+		Price:           price,
+		OrderTime:       o.Time,
+		FillTime:        t,
+		TimeToFillMs:    float64(t.Sub(o.Time)) / float64(time.Millisecond),
+		ArrivalMid:      arrival,
+		FillMid:         mid,
+		Shortfall:       s * (price - arrival),
+		EffectiveSpread: 2 * s * (price - mid),
+		Markouts:        make(map[string]float64),
+	}
+	if o.Bid > 0 && o.Ask > 0 {
+		rec.ArrivalSpread = o.Ask - o.Bid
+	}
+	if arrival > 0 {
+		rec.ShortfallBps = rec.Shortfall / arrival * 1e4
+	}
+
+	fs := &fillState{rec: rec, buy: o.Buy}
+	for _, k := range []groupKey{
+		{"total", All, liq}, {"total", All, All},
+		{"symbol", o.Symbol, liq}, {"strategy", o.Strategy, liq}, {"order_type", o.OrderType, liq},
+	} {
+		a := e.group(k)
+		q := float64(qty)
+		a.fills++
+		a.qty += int64(qty)
+		a.ttf += rec.TimeToFillMs * q
+		a.shortfall += rec.Shortfall * q
+		a.bps += rec.ShortfallBps * q
+		a.eff += rec.EffectiveSpread * q
+		fs.groups = append(fs.groups, a)
+	}
+
+	for i, h := range e.cfg.Horizons {
+		e.pending[o.Symbol] = append(e.pending[o.Symbol], pending{fill: fs, label: e.labels[i], due: t.Add(h)})
+	}
+	e.pending[o.Symbol] = append(e.pending[o.Symbol], pending{fill: fs, due: t.Add(e.cfg.RealizedHorizon), realized: true})
+
+	e.fills = append(e.fills, fs)
+	if n := len(e.fills) - e.cfg.MaxFills; n > 0 {
+		e.fills = e.fills[n:]
+	}
+}
+
+func (e *Engine) group(k groupKey) *acc {
+	a, ok := e.groups[k]
+	if !ok {
+		a = &acc{markout: make(map[string]float64), markoutQty: make(map[string]int64)}
+		e.groups[k] = a
+	}
+	return a
+}
+
+// OnQuote 行情更新：结算到期的 markout 和实现价差
+func (e *Engine) OnQuote(symbol string, t time.Time, mid float64) {
+	if mid <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastMid[symbol] = mid
+	list := e.pending[symbol]
+	if len(list) == 0 {
+		return
+	}
+	kept := list[:0]
+	for _, p := range list {
+		if t.Before(p.due) {
+			kept = append(kept, p)
+			continue
+		}
+		rec := &p.fill.rec
+		s := -1.0
+		if p.fill.buy {
+			s = 1
+		}
+		q := float64(rec.Qty)
+		if p.realized {
+			v := 2 * s * (rec.Price - mid)
+			rec.RealizedSpread = &v
+			for _, a := range p.fill.groups {
+				a.realized += v * q
+				a.realizedQty += int64(rec.Qty)
+			}
+			continue
+		}
+		v := s * (mid - rec.Price)
+		rec.Markouts[p.label] = v
+		for _, a := range p.fill.groups {
+			a.markout[p.label] += v * q
+			a.markoutQty[p.label] += int64(rec.Qty)
+		}
+	}
+	e.pending[symbol] = kept
+}
+
+// Report 生成当前 TCA 报告（分组按 key、流动性排序）
+func (e *Engine) Report() *Report {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := &Report{Horizons: append([]string(nil), e.labels...)}
+	for k, a := range e.groups {
+		s := a.summary(k.key, k.liq)
+		switch k.dim {
+		case "total":
+			s.Key = k.liq
+			r.Total = append(r.Total, s)
+		case "symbol":
+			r.BySymbol = append(r.BySymbol, s)
+		case "strategy":
+			r.ByStrategy = append(r.ByStrategy, s)
+		case "order_type":
+			r.ByOrderType = append(r.ByOrderType, s)
+		}
+	}
+	for _, list := range [][]Summary{r.Total, r.BySymbol, r.ByStrategy, r.ByOrderType} {
+		sortSummaries(list)
+	}
+	r.Fills = make([]FillRecord, 0, len(e.fills))
+	for _, f := range e.fills {
+		rec := f.rec
+		rec.Markouts = make(map[string]float64, len(f.rec.Markouts))
+		for k, v := range f.rec.Markouts {
+			rec.Markouts[k] = v
+		}
+		if f.rec.RealizedSpread != nil {
+			v := *f.rec.RealizedSpread
+			rec.RealizedSpread = &v
+		}
+		r.Fills = append(r.Fills, rec)
+	}
+	for _, list := range e.pending {
+		r.PendingMarkouts += len(list)
+	}
+	return r
+}
+
+func sortSummaries(list []Summary) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Key != list[j].Key {
+			return list[i].Key < list[j].Key
+		}
+		return list[i].Liquidity < list[j].Liquidity
+	})
+}
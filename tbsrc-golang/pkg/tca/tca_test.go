@@ -0,0 +1,114 @@
+package tca
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestEngine_FillMetricsAndMarkouts(t *testing.T) {
+	e := NewEngine(Config{})
+	t0 := time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local)
+
+	// 被动买单挂在买一，盘口 100/102
+	e.OnOrder(Order{ID: "1", Strategy: "92201", Symbol: "ag2506", OrderType: "STANDARD", Buy: true,
+		Qty: 2, Price: 100, Time: t0, Bid: 100, Ask: 102})
+	// 主动卖单穿越买一
+	e.OnOrder(Order{ID: "2", Strategy: "92201", Symbol: "ag2506", OrderType: "CROSS",
+		Qty: 1, Price: 100, Time: t0, Bid: 100, Ask: 102})
+
+	e.OnFill("1", t0.Add(200*time.Millisecond), 2, 100, 101)
+	e.OnFill("2", t0, 1, 100, 101)
+	e.OnFill("unknown", t0, 1, 100, 101) // 未登记订单忽略
+
+	e.OnQuote("ag2506", t0.Add(500*time.Millisecond), 110) // 未到 1s
+	e.OnQuote("ag2506", t0.Add(1200*time.Millisecond), 103)
+	e.OnQuote("ag2506", t0.Add(6*time.Second), 104)
+
+	r := e.Report()
+	// 每笔成交还有 30s、5m 和实现价差三个观察未到期
+	if len(r.Fills) != 2 || r.PendingMarkouts != 6 {
+		t.Fatalf("fills=%d pending=%d, want 2 / 6", len(r.Fills), r.PendingMarkouts)
+	}
+	buy := r.Fills[0]
+	// 到达 mid 101：买入 100 节省 1，有效价差 -2，1s markout 103-100 = 3，5s 104-100 = 4
+	if buy.Liquidity != Passive || !near(buy.Shortfall, -1) || !near(buy.EffectiveSpread, -2) ||
+		!near(buy.Markouts["1s"], 3) || !near(buy.Markouts["5s"], 4) || buy.RealizedSpread != nil {
+		t.Errorf("buy fill = %+v", buy)
+	}
+	if !near(buy.TimeToFillMs, 200) || !near(buy.ArrivalSpread, 2) || !near(buy.ShortfallBps, -1/101.0*1e4) {
+		t.Errorf("buy fill = %+v", buy)
+	}
+	sell := r.Fills[1]
+	// 卖出 100：成本 1，1s markout 100-103 = -3
+	if sell.Liquidity != Aggressive || !near(sell.Shortfall, 1) || !near(sell.Markouts["1s"], -3) {
+		t.Errorf("sell fill = %+v", sell)
+	}
+
+	var all, passive Summary
+	for _, s := range r.Total {
+		switch s.Key {
+		case All:
+			all = s
+		case Passive:
+			passive = s
+		}
+	}
+	// 数量加权：(−1×2 + 1×1)/3
+	if all.Qty != 3 || !near(all.AvgShortfall, -1.0/3) || !near(all.Markouts["1s"], (3*2-3)/3.0) {
+		t.Errorf("total all = %+v", all)
+	}
+	if passive.Fills != 1 || !near(passive.AvgTimeToFillMs, 200) {
+		t.Errorf("total passive = %+v", passive)
+	}
+	if len(r.ByOrderType) != 2 || r.ByOrderType[0].Key != "CROSS" || r.ByOrderType[0].Liquidity != Aggressive {
+		t.Errorf("by order type = %+v", r.ByOrderType)
+	}
+
+	// 5m 到期：markout 与实现价差同时结算
+	e.OnQuote("ag2506", t0.Add(6*time.Minute), 99)
+	r = e.Report()
+	if r.PendingMarkouts != 0 || r.Fills[0].RealizedSpread == nil || !near(*r.Fills[0].RealizedSpread, 2) ||
+		!near(r.Fills[0].Markouts["5m"], -1) {
+		t.Errorf("after 5m: pending=%d fill=%+v", r.PendingMarkouts, r.Fills[0])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	e := NewEngine(Config{Horizons: []time.Duration{time.Second}})
+	t0 := time.Now()
+	e.OnOrder(Order{ID: "1", Symbol: "ag2506", Buy: true, Qty: 1, Price: 100, Time: t0, Bid: 100, Ask: 102})
+	e.OnFill("1", t0, 1, 100, 0)
+	r := e.Report()
+
+	var buf bytes.Buffer
+	if err := WriteSummaryCSV(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || !strings.HasSuffix(lines[0], "markout_1s") {
+		t.Errorf("summary csv:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := WriteFillsCSV(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	// 未到期的 markout 留空
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.HasSuffix(lines[1], ",,") {
+		t.Errorf("fills csv:\n%s", buf.String())
+	}
+}
+
+func TestHorizonLabel(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		time.Second: "1s", 30 * time.Second: "30s", 5 * time.Minute: "5m", time.Hour: "1h", 1500 * time.Millisecond: "1.5s",
+	} {
+		if got := HorizonLabel(d); got != want {
+			t.Errorf("HorizonLabel(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
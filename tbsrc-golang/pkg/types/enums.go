@@ -48,3 +48,20 @@ const (
 	PHedge TypeOfOrder = 1 // PHEDGE
 	AHedge TypeOfOrder = 2 // AHEDGE
 )
+
+// String 返回 C++ 枚举名（TCA 按订单类型分组时使用）
+func (t OrderHitType) String() string {
+	switch t {
+	case HitStandard:
+		return "STANDARD"
+	case HitImprove:
+		return "IMPROVE"
+	case HitCross:
+		return "CROSS"
+	case HitDetect:
+		return "DETECT"
+	case HitMatch:
+		return "MATCH"
+	}
+	return "UNKNOWN"
+}
@@ -146,6 +146,50 @@ type ThresholdSet struct {
 	// tvar/tcache
 	TVarKey   int32 // TVAR_KEY
 	TCacheKey int32 // TCACHE_KEY
+
+	// 市场状态切换（Go 扩展，C++ 无对应）
+	// 高状态时 setThresholds 使用 BEGIN_PLACE_HIGH / LONG_PLACE_HIGH
+	UseRegimeThold bool    // USE_REGIME_THOLD
+	RegimeStates   int     // REGIME_STATES — HMM 状态数 (2/3)
+	RegimePeriod   int     // REGIME_PERIOD — 基准方差 EWMA 窗口
+	RegimeHighMult float64 // REGIME_HIGH_MULT — 高波动状态方差倍数
+	RegimeHighProb float64 // REGIME_HIGH_PROB — P(high) 切换阈值
+	HurstPeriod    int     // HURST_PERIOD — 方差比窗口
+	HurstLag       int     // HURST_LAG — 方差比滞后 q
+	HurstMax       float64 // HURST_MAX — Hurst 上限，0 表示不使用
+	CusumThresh    float64 // CUSUM_THRESH — 突变阈值（标准差）
+	CusumDrift     float64 // CUSUM_DRIFT
+	CusumHold      int     // CUSUM_HOLD — 突变后保持高状态的行情数，0 表示不使用
+
+	// 价差模式（Go 扩展，C++ 无对应）
+	// 0 = EWA（C++ 一致: mid1 - mid2），1 = 卡尔曼滤波动态对冲比率（mid1 - beta*mid2）
+	SpreadMode   int     // SPREAD_MODE
+	SpreadType   int     // SPREAD_TYPE — 0 = mid1 - mid2（C++），1 = mid1 / mid2，2 = ln(mid1 / mid2)
+	KalmanQAlpha float64 // KALMAN_Q_ALPHA — 截距过程噪声方差
+	KalmanQBeta  float64 // KALMAN_Q_BETA — 对冲比率过程噪声方差
+	KalmanR      float64 // KALMAN_R — 观测噪声方差
+
+	// N 腿价差执行（Go 扩展，C++ 无对应），按腿配置
+	HedgeMaxSlip int32 // HEDGE_MAX_SLIP — 对冲单相对首次对冲参考价的最大滑点（tick），0 表示不限制
+	LegTimeout   int64 // LEG_TIMEOUT — 瘸腿超时（毫秒），超时未对冲则回退多余腿，0 表示不限制
+
+	// 冰山/智能被动报价（Go 扩展，C++ 无对应），仅作用于 STANDARD 报价单
+	IcebergDisplay  int32   // ICEBERG_DISPLAY — 显示数量（手），0 表示不启用
+	IcebergVariance float64 // ICEBERG_VARIANCE — 显示数量随机浮动比例（0~1）
+	IcebergDelayMin int64   // ICEBERG_DELAY_MIN — 显示单成交完后补单的随机延迟下限（毫秒）
+	IcebergDelayMax int64   // ICEBERG_DELAY_MAX — 随机延迟上限（毫秒）
+	SmartQuote      bool    // SMART_QUOTE — 按前方排队量和盘口失衡决定跟价/改善一档/退后一档
+	QuoteQueueAhead float64 // QUOTE_QUEUE_AHEAD — 前方排队量（手）达到该值才改善一档；低于该值视为排在队首
+	QuoteImproveImb float64 // QUOTE_IMPROVE_IMB — 本方盘口失衡达到该值时改善一档，0 表示不改善
+	QuoteBackoffImb float64 // QUOTE_BACKOFF_IMB — 对方盘口失衡达到该值时退后一档/撤掉队首挂单，0 表示不退后
+
+	// 集合竞价（Go 扩展，C++ 无对应），仅第一腿生效：竞价期间按指示性价差在第一腿挂单，开盘成交后由第二腿对冲
+	AuctionEnable    bool    // AUCTION_ENABLE — 集合竞价期间参与报单
+	AuctionPlace     float64 // AUCTION_PLACE — 指示性价差偏离均值达到该值时报单（价格单位）
+	AuctionSize      int32   // AUCTION_SIZE — 竞价单数量，0 表示使用 SIZE
+	AuctionMaxSize   int32   // AUCTION_MAX_SIZE — 竞价后净敞口上限，0 表示使用 MAX_SIZE
+	AuctionSlop      int32   // AUCTION_SLOP — 报价相对指示性均衡价向对手方让出的 tick 数（成交价仍为统一开盘价）
+	AuctionMinVolume int64   // AUCTION_MIN_VOLUME — 两腿指示性可成交量下限（手），低于该值不报单
 }
 
 // LoadFromMap 从 YAML 配置 map[string]float64 填充字段
@@ -403,10 +447,227 @@ func (ts *ThresholdSet) LoadFromMap(m map[string]float64) {
 			ts.TVarKey = int32(v)
 		case "tcache_key":
 			ts.TCacheKey = int32(v)
+
+		// 市场状态切换
+		case "use_regime_thold":
+			ts.UseRegimeThold = v != 0
+		case "regime_states":
+			ts.RegimeStates = int(v)
+		case "regime_period":
+			ts.RegimePeriod = int(v)
+		case "regime_high_mult":
+			ts.RegimeHighMult = v
+		case "regime_high_prob":
+			ts.RegimeHighProb = v
+		case "hurst_period":
+			ts.HurstPeriod = int(v)
+		case "hurst_lag":
+			ts.HurstLag = int(v)
+		case "hurst_max":
+			ts.HurstMax = v
+		case "cusum_thresh":
+			ts.CusumThresh = v
+		case "cusum_drift":
+			ts.CusumDrift = v
+		case "cusum_hold":
+			ts.CusumHold = int(v)
+		case "spread_mode":
+			ts.SpreadMode = int(v)
+		case "spread_type":
+			ts.SpreadType = int(v)
+		case "kalman_q_alpha":
+			ts.KalmanQAlpha = v
+		case "kalman_q_beta":
+			ts.KalmanQBeta = v
+		case "kalman_r":
+			ts.KalmanR = v
+		case "hedge_max_slip":
+			ts.HedgeMaxSlip = int32(v)
+		case "leg_timeout":
+			ts.LegTimeout = int64(v)
+		case "iceberg_display":
+			ts.IcebergDisplay = int32(v)
+		case "iceberg_variance":
+			ts.IcebergVariance = v
+		case "iceberg_delay_min":
+			ts.IcebergDelayMin = int64(v)
+		case "iceberg_delay_max":
+			ts.IcebergDelayMax = int64(v)
+		case "smart_quote":
+			ts.SmartQuote = v != 0
+		case "quote_queue_ahead":
+			ts.QuoteQueueAhead = v
+		case "quote_improve_imb":
+			ts.QuoteImproveImb = v
+		case "quote_backoff_imb":
+			ts.QuoteBackoffImb = v
+		case "auction_enable":
+			ts.AuctionEnable = v != 0
+		case "auction_place":
+			ts.AuctionPlace = v
+		case "auction_size":
+			ts.AuctionSize = int32(v)
+		case "auction_max_size":
+			ts.AuctionMaxSize = int32(v)
+		case "auction_slop":
+			ts.AuctionSlop = int32(v)
+		case "auction_min_volume":
+			ts.AuctionMinVolume = int64(v)
 		}
 	}
 }
 
+// ToMap 返回全部阈值（key 与 LoadFromMap 一致，布尔值为 0/1），用于参数变更预览与回滚（Go 扩展）
+func (ts *ThresholdSet) ToMap() map[string]float64 {
+	return map[string]float64{
+		"use_notional":        boolToFloat(ts.UseNotional),
+		"use_percent":         boolToFloat(ts.UsePercent),
+		"use_price_limit":     boolToFloat(ts.UsePriceLimit),
+		"use_ahead_percent":   boolToFloat(ts.UseAheadPercent),
+		"use_close_cross":     boolToFloat(ts.UseCloseCross),
+		"use_passive_thold":   boolToFloat(ts.UsePassiveThold),
+		"use_linear_thold":    boolToFloat(ts.UseLinearThold),
+		"quote_max_qty":       boolToFloat(ts.QuoteMaxQty),
+		"close_pnl":           boolToFloat(ts.ClosePNL),
+		"check_pnl":           boolToFloat(ts.CheckPNL),
+		"news_flat":           boolToFloat(ts.NewsFlat),
+		"begin_place":         ts.BeginPlace,
+		"begin_remove":        ts.BeginRemove,
+		"long_place":          ts.LongPlace,
+		"long_remove":         ts.LongRemove,
+		"short_place":         ts.ShortPlace,
+		"short_remove":        ts.ShortRemove,
+		"long_inc":            ts.LongInc,
+		"begin_place_high":    ts.BeginPlaceHigh,
+		"long_place_high":     ts.LongPlaceHigh,
+		"size":                float64(ts.Size),
+		"ta_size":             float64(ts.TASize),
+		"begin_size":          float64(ts.BeginSize),
+		"max_size":            float64(ts.MaxSize),
+		"percent_size":        float64(ts.PercentSize),
+		"percent_level":       float64(ts.PercentLevel),
+		"notional_size":       float64(ts.NotionalSize),
+		"notional_max_size":   float64(ts.NotionalMaxSz),
+		"sms_ratio":           float64(ts.SMSRatio),
+		"max_os_order":        float64(ts.MaxOSOrder),
+		"bid_size":            float64(ts.BidSize),
+		"bid_max_size":        float64(ts.BidMaxSize),
+		"ask_size":            float64(ts.AskSize),
+		"ask_max_size":        float64(ts.AskMaxSize),
+		"cross":               ts.Cross,
+		"close_cross":         ts.CloseCross,
+		"close_improve":       ts.CloseImprove,
+		"improve":             ts.Improve,
+		"max_cross":           float64(ts.MaxCross),
+		"max_long_cross":      float64(ts.MaxLongCross),
+		"max_short_cross":     float64(ts.MaxShortCross),
+		"cross_target":        float64(ts.CrossTarget),
+		"cross_ticks":         float64(ts.CrossTicks),
+		"agg_cool_off":        float64(ts.AggCoolOff),
+		"place_spread":        ts.PlaceSpread,
+		"pil_factor":          ts.PILFactor,
+		"stop_loss":           ts.StopLoss,
+		"max_loss":            ts.MaxLoss,
+		"upnl_loss":           ts.UPNLLoss,
+		"pt_profit":           ts.PTProft,
+		"pt_loss":             ts.PTLoss,
+		"max_price":           ts.MaxPrice,
+		"min_price":           ts.MinPrice,
+		"opp_qty":             ts.OppQty,
+		"supp_tolerance":      float64(ts.SuppTolerance),
+		"ahead_percent":       ts.AheadPercent,
+		"ahead_size":          ts.AheadSize,
+		"szahead_nocxl":       float64(ts.SzAheadNoCxl),
+		"booksz_nocxl":        float64(ts.BookSzNoCxl),
+		"aggflat_booksize":    float64(ts.AggFlatBookSz),
+		"aggflat_bookfrac":    ts.AggFlatBookFr,
+		"alpha":               ts.Alpha,
+		"spread_ewa":          ts.SpreadEWA,
+		"avg_spread_away":     float64(ts.AvgSpreadAway),
+		"hedge_ratio":         ts.HedgeRatio,
+		"hedge_thres":         ts.HedgeThres,
+		"hedge_size_ratio":    ts.HedgeSzRatio,
+		"const":               ts.Const,
+		"price_ratio":         ts.PriceRatio,
+		"slop":                float64(ts.Slop),
+		"pause":               float64(ts.Pause),
+		"cancelreq_pause":     float64(ts.CancelReqPause),
+		"sqroff_time":         float64(ts.SqrOffTime),
+		"sqroff_agg":          float64(ts.SqrOffAgg),
+		"quote_skew":          ts.QuoteSkew,
+		"max_quote_spread":    float64(ts.MaxQuoteSpread),
+		"max_quote_level":     float64(ts.MaxQuoteLevel),
+		"quote_signal":        float64(ts.QuoteSignal),
+		"stat_duration_small": float64(ts.StatDurationSmall),
+		"stat_duration_long":  float64(ts.StatDurationLong),
+		"stat_trade_thresh":   ts.StatTradeThresh,
+		"stat_decay":          float64(ts.StatDecay),
+		"delta_hedge":         ts.DeltaHedge,
+		"target_delta":        ts.TargetDelta,
+		"max_delta_value":     ts.MaxDeltaValue,
+		"min_delta_value":     ts.MinDeltaValue,
+		"max_delta_change":    ts.MaxDeltaChange,
+		"vwap_ratio":          ts.VWAPRatio,
+		"vwap_count":          ts.VWAPCount,
+		"vwap_depth":          ts.VWAPDepth,
+		"bidask_ratio":        ts.BidAskRatio,
+		"supporting_orders":   float64(ts.SupportingOrders),
+		"tailing_orders":      float64(ts.TailingOrders),
+		"max_orders":          float64(ts.MaxOrders),
+		"pca_coeff1":          ts.PCACoeff1,
+		"pca_coeff2":          ts.PCACoeff2,
+		"pca_coeff3":          ts.PCACoeff3,
+		"min_extr_ind":        float64(ts.MinExtrInd),
+		"target_std_dev":      ts.TargetStdDev,
+		"price_cooloff":       float64(ts.PriceCooloff),
+		"sweep_place":         float64(ts.SweepPlace),
+		"sweep_close":         float64(ts.SweepClose),
+		"sweep_place_level":   float64(ts.SweepPlaceLevel),
+		"sweep_close_level":   float64(ts.SweepCloseLevel),
+		"tvar_key":            float64(ts.TVarKey),
+		"tcache_key":          float64(ts.TCacheKey),
+		"use_regime_thold":    boolToFloat(ts.UseRegimeThold),
+		"regime_states":       float64(ts.RegimeStates),
+		"regime_period":       float64(ts.RegimePeriod),
+		"regime_high_mult":    ts.RegimeHighMult,
+		"regime_high_prob":    ts.RegimeHighProb,
+		"hurst_period":        float64(ts.HurstPeriod),
+		"hurst_lag":           float64(ts.HurstLag),
+		"hurst_max":           ts.HurstMax,
+		"cusum_thresh":        ts.CusumThresh,
+		"cusum_drift":         ts.CusumDrift,
+		"cusum_hold":          float64(ts.CusumHold),
+		"spread_mode":         float64(ts.SpreadMode),
+		"spread_type":         float64(ts.SpreadType),
+		"kalman_q_alpha":      ts.KalmanQAlpha,
+		"kalman_q_beta":       ts.KalmanQBeta,
+		"kalman_r":            ts.KalmanR,
+		"hedge_max_slip":      float64(ts.HedgeMaxSlip),
+		"leg_timeout":         float64(ts.LegTimeout),
+		"iceberg_display":     float64(ts.IcebergDisplay),
+		"iceberg_variance":    ts.IcebergVariance,
+		"iceberg_delay_min":   float64(ts.IcebergDelayMin),
+		"iceberg_delay_max":   float64(ts.IcebergDelayMax),
+		"smart_quote":         boolToFloat(ts.SmartQuote),
+		"quote_queue_ahead":   ts.QuoteQueueAhead,
+		"quote_improve_imb":   ts.QuoteImproveImb,
+		"quote_backoff_imb":   ts.QuoteBackoffImb,
+		"auction_enable":      boolToFloat(ts.AuctionEnable),
+		"auction_place":       ts.AuctionPlace,
+		"auction_size":        float64(ts.AuctionSize),
+		"auction_max_size":    float64(ts.AuctionMaxSize),
+		"auction_slop":        float64(ts.AuctionSlop),
+		"auction_min_volume":  float64(ts.AuctionMinVolume),
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 // NewThresholdSet 创建带 C++ 默认值的 ThresholdSet
 // 参考: tbsrc/main/include/TradeBotUtils.h 构造函数 (lines 237-320)
 func NewThresholdSet() *ThresholdSet {
@@ -487,6 +748,24 @@ func NewThresholdSet() *ThresholdSet {
 		TVarKey:   -1,
 		TCacheKey: -1,
 
+		// 市场状态切换
+		RegimeStates:   2,
+		RegimePeriod:   100,
+		RegimeHighMult: 4,
+		RegimeHighProb: 0.7,
+		HurstPeriod:    200,
+		HurstLag:       5,
+		HurstMax:       0.55,
+		CusumThresh:    5,
+		CusumDrift:     0.5,
+		CusumHold:      50,
+
+		// 价差模式
+		SpreadMode:   0,
+		KalmanQAlpha: 1e-4,
+		KalmanQBeta:  1e-8,
+		KalmanR:      1,
+
 		// BID/ASK 独立大小
 		BidSize:    0,
 		BidMaxSize: 0,
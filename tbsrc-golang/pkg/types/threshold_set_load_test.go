@@ -172,6 +172,38 @@ func TestLoadFromMap_TVarKey(t *testing.T) {
 	}
 }
 
+func TestLoadFromMap_Regime(t *testing.T) {
+	ts := NewThresholdSet()
+	m := map[string]float64{
+		"use_regime_thold": 1,
+		"regime_states":    3,
+		"regime_high_prob": 0.8,
+		"hurst_max":        0.6,
+		"cusum_hold":       30,
+	}
+	ts.LoadFromMap(m)
+
+	if !ts.UseRegimeThold {
+		t.Error("UseRegimeThold should be true")
+	}
+	if ts.RegimeStates != 3 {
+		t.Errorf("RegimeStates = %d, want 3", ts.RegimeStates)
+	}
+	if ts.RegimeHighProb != 0.8 {
+		t.Errorf("RegimeHighProb = %f, want 0.8", ts.RegimeHighProb)
+	}
+	if ts.HurstMax != 0.6 {
+		t.Errorf("HurstMax = %f, want 0.6", ts.HurstMax)
+	}
+	if ts.CusumHold != 30 {
+		t.Errorf("CusumHold = %d, want 30", ts.CusumHold)
+	}
+	// 未配置的保持默认值
+	if ts.RegimePeriod != 100 {
+		t.Errorf("RegimePeriod = %d, want 100", ts.RegimePeriod)
+	}
+}
+
 func TestLoadFromMap_EmptyMap(t *testing.T) {
 	ts := NewThresholdSet()
 	ts.LoadFromMap(map[string]float64{})
@@ -195,3 +227,83 @@ func TestLoadFromMap_UnknownKeysIgnored(t *testing.T) {
 		t.Errorf("BeginPlace = %f, want 0.35", ts.BeginPlace)
 	}
 }
+
+func TestLoadFromMap_SpreadMode(t *testing.T) {
+	ts := NewThresholdSet()
+	if ts.SpreadMode != 0 || ts.KalmanR != 1 {
+		t.Errorf("defaults: SpreadMode = %d, KalmanR = %f, want 0, 1", ts.SpreadMode, ts.KalmanR)
+	}
+	ts.LoadFromMap(map[string]float64{
+		"spread_mode":   1,
+		"kalman_q_beta": 1e-7,
+		"kalman_r":      2,
+	})
+	if ts.SpreadMode != 1 {
+		t.Errorf("SpreadMode = %d, want 1", ts.SpreadMode)
+	}
+	if ts.KalmanQBeta != 1e-7 || ts.KalmanR != 2 {
+		t.Errorf("KalmanQBeta = %g, KalmanR = %f, want 1e-7, 2", ts.KalmanQBeta, ts.KalmanR)
+	}
+	if ts.KalmanQAlpha != 1e-4 {
+		t.Errorf("KalmanQAlpha = %g, want 1e-4", ts.KalmanQAlpha)
+	}
+}
+
+func TestLoadFromMap_SpreadType(t *testing.T) {
+	ts := NewThresholdSet()
+	if ts.SpreadType != 0 {
+		t.Errorf("default SpreadType = %d, want 0", ts.SpreadType)
+	}
+	ts.LoadFromMap(map[string]float64{"spread_type": 2})
+	if ts.SpreadType != 2 {
+		t.Errorf("SpreadType = %d, want 2", ts.SpreadType)
+	}
+}
+
+func TestLoadFromMap_SpreadExecution(t *testing.T) {
+	ts := NewThresholdSet()
+	ts.LoadFromMap(map[string]float64{"hedge_max_slip": 3, "leg_timeout": 1500})
+	if ts.HedgeMaxSlip != 3 || ts.LegTimeout != 1500 {
+		t.Errorf("HedgeMaxSlip = %d, LegTimeout = %d, want 3, 1500", ts.HedgeMaxSlip, ts.LegTimeout)
+	}
+}
+
+func TestLoadFromMap_Iceberg(t *testing.T) {
+	ts := NewThresholdSet()
+	ts.LoadFromMap(map[string]float64{"iceberg_display": 2, "iceberg_delay_max": 300, "smart_quote": 1, "quote_backoff_imb": 0.6})
+	if ts.IcebergDisplay != 2 || ts.IcebergDelayMax != 300 || !ts.SmartQuote || ts.QuoteBackoffImb != 0.6 {
+		t.Errorf("IcebergDisplay = %d, IcebergDelayMax = %d, SmartQuote = %v, QuoteBackoffImb = %f",
+			ts.IcebergDisplay, ts.IcebergDelayMax, ts.SmartQuote, ts.QuoteBackoffImb)
+	}
+}
+
+func TestLoadFromMap_Auction(t *testing.T) {
+	ts := NewThresholdSet()
+	ts.LoadFromMap(map[string]float64{"auction_enable": 1, "auction_place": 2.5, "auction_size": 3, "auction_min_volume": 50})
+	if !ts.AuctionEnable || ts.AuctionPlace != 2.5 || ts.AuctionSize != 3 || ts.AuctionMinVolume != 50 {
+		t.Errorf("AuctionEnable = %v, AuctionPlace = %f, AuctionSize = %d, AuctionMinVolume = %d",
+			ts.AuctionEnable, ts.AuctionPlace, ts.AuctionSize, ts.AuctionMinVolume)
+	}
+}
+
+// ToMap 与 LoadFromMap 键集合一致：每个键写入后都能读回（参数工作流依赖此往返）
+func TestToMap_RoundTrip(t *testing.T) {
+	keys := NewThresholdSet().ToMap()
+	for _, want := range []float64{1, 0} {
+		m := make(map[string]float64, len(keys))
+		for k := range keys {
+			m[k] = want
+		}
+		ts := NewThresholdSet()
+		ts.LoadFromMap(m)
+		got := ts.ToMap()
+		if len(got) != len(keys) {
+			t.Fatalf("ToMap has %d keys, want %d", len(got), len(keys))
+		}
+		for k, v := range got {
+			if v != want {
+				t.Errorf("%s = %v after LoadFromMap(%v)", k, v, want)
+			}
+		}
+	}
+}